@@ -265,14 +265,14 @@ func (b *SimulatedBackend) TransactionReceipt(ctx context.Context, txHash libcom
 	defer tx.Rollback()
 
 	// Retrieve the context of the receipt based on the transaction hash
-	blockNumber, _, err := rawdb.ReadTxLookupEntry(tx, txHash)
+	blockNumber, _, ok, err := b.BlockReader().TxnLookup(ctx, tx, txHash)
 	if err != nil {
 		return nil, err
 	}
-	if blockNumber == nil {
+	if !ok {
 		return nil, nil
 	}
-	block, err := b.BlockReader().BlockByNumber(b.m.Ctx, tx, *blockNumber)
+	block, err := b.BlockReader().BlockByNumber(b.m.Ctx, tx, blockNumber)
 	if err != nil {
 		return nil, err
 	}
@@ -308,31 +308,12 @@ func (b *SimulatedBackend) TransactionByHash(ctx context.Context, txHash libcomm
 	if txn != nil {
 		return txn, true, nil
 	}
-	blockNumber, _, ok, err := b.BlockReader().TxnLookup(ctx, tx, txHash)
-	if err != nil {
-		return nil, false, err
-	}
-	if !ok {
-		return nil, false, ethereum.NotFound
-	}
-	blockHash, ok, err := b.BlockReader().CanonicalHash(ctx, tx, blockNumber)
+	txn, _, _, ok, err := b.BlockReader().TxnByHash(ctx, tx, txHash)
 	if err != nil {
 		return nil, false, err
 	}
-	if !ok {
-		return nil, false, ethereum.NotFound
-	}
-	body, err := b.BlockReader().BodyWithTransactions(ctx, tx, blockHash, blockNumber)
-	if err != nil {
-		return nil, false, err
-	}
-	if body == nil {
-		return nil, false, ethereum.NotFound
-	}
-	for _, txn = range body.Transactions {
-		if txn.Hash() == txHash {
-			return txn, false, nil
-		}
+	if ok {
+		return txn, false, nil
 	}
 	return nil, false, ethereum.NotFound
 }
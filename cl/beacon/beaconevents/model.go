@@ -4,6 +4,7 @@ import (
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/utils"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
 )
 
@@ -33,9 +34,35 @@ type (
 	AttesterSlashingData      = cltypes.AttesterSlashing
 	BlsToExecutionChangesData = cltypes.SignedBLSToExecutionChange
 	ContributionAndProofData  = cltypes.SignedContributionAndProof
-	BlobSidecarData           = cltypes.BlobSidecar
 )
 
+type BlobSidecarData struct {
+	BlockRoot     common.Hash    `json:"block_root"`
+	Index         uint64         `json:"index,string"`
+	Slot          uint64         `json:"slot,string"`
+	KzgCommitment common.Bytes48 `json:"kzg_commitment"`
+	VersionedHash common.Hash    `json:"versioned_hash"`
+}
+
+// NewBlobSidecarData returns the blob_sidecar event of a sidecar, the blob itself is not part of it.
+func NewBlobSidecarData(sidecar *cltypes.BlobSidecar) (*BlobSidecarData, error) {
+	blockRoot, err := sidecar.SignedBlockHeader.Header.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	versionedHash, err := utils.KzgCommitmentToVersionedHash(sidecar.KzgCommitment)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobSidecarData{
+		BlockRoot:     blockRoot,
+		Index:         sidecar.Index,
+		Slot:          sidecar.SignedBlockHeader.Header.Slot,
+		KzgCommitment: sidecar.KzgCommitment,
+		VersionedHash: versionedHash,
+	}, nil
+}
+
 // State event topics
 const (
 	StateHead                        EventTopic = "head"
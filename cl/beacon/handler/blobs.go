@@ -18,6 +18,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -74,6 +75,16 @@ func (a *ApiHandler) GetEthV1BeaconBlobSidecars(w http.ResponseWriter, r *http.R
 	}
 	resp := solid.NewStaticListSSZ[*cltypes.BlobSidecar](696969, blobSidecarSSZLenght)
 	if !found {
+		// tell apart pruned sidecars from blocks which simply had none.
+		if earliestAvailableSlot := a.blobStoage.EarliestAvailableSlot(); *slot < earliestAvailableSlot {
+			commitmentsCount, err := a.blobStoage.KzgCommitmentsCount(ctx, blockRoot)
+			if err != nil {
+				return nil, err
+			}
+			if commitmentsCount > 0 {
+				return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("blob sidecars for slot %d were pruned, earliest available slot is %d", *slot, earliestAvailableSlot))
+			}
+		}
 		return beaconhttp.NewBeaconResponse(resp), nil
 	}
 	if len(strIdxs) == 0 {
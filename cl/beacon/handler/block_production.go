@@ -43,6 +43,7 @@ import (
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cl/abstract"
+	"github.com/erigontech/erigon/cl/beacon/beaconevents"
 	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
 	"github.com/erigontech/erigon/cl/beacon/builder"
 	"github.com/erigontech/erigon/cl/beacon/synced_data"
@@ -1168,6 +1169,13 @@ func (a *ApiHandler) storeBlockAndBlobs(
 	if err := a.blobStoage.WriteBlobSidecars(ctx, blockRoot, sidecars); err != nil {
 		return err
 	}
+	for _, sidecar := range sidecars {
+		blobSidecarEvent, err := beaconevents.NewBlobSidecarData(sidecar)
+		if err != nil {
+			return err
+		}
+		a.emitters.Operation().SendBlobSidecar(blobSidecarEvent)
+	}
 	if err := a.indiciesDB.Update(ctx, func(tx kv.RwTx) error {
 		if err := beacon_indicies.WriteHighestFinalized(tx, a.forkchoiceStore.FinalizedSlot()); err != nil {
 			return err
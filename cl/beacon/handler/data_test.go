@@ -17,6 +17,7 @@
 package handler
 
 import (
+	"context"
 	"embed"
 	"math"
 	"os"
@@ -27,12 +28,15 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cl/beacon/beacontest"
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/lightclient_utils"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/persistence/blob_storage"
 	"github.com/erigontech/erigon/cl/phase1/core/state"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice"
 )
@@ -52,6 +56,8 @@ type harnessConfig struct {
 	v         clparams.StateVersion
 	finalized bool
 	forkmode  int
+	// pruneBlobs swaps the blob storage for one which has already pruned the sidecars of the first block.
+	pruneBlobs bool
 }
 
 func defaultHarnessOpts(c harnessConfig) []beacontest.HarnessOption {
@@ -133,6 +139,24 @@ func defaultHarnessOpts(c harnessConfig) []beacontest.HarnessOption {
 	}
 	sm.OnHeadState(postState)
 
+	if c.pruneBlobs {
+		ctx := context.Background()
+		firstBlockRoot, err := blocks[0].Block.HashSSZ()
+		require.NoError(c.t, err)
+		blobStorage := blob_storage.NewBlobStore(memdb.NewTestDB(c.t, kv.ChainDB), afero.NewMemMapFs(),
+			handler.beaconChainCfg.MinEpochsForBlobsSidecarsRequest*handler.beaconChainCfg.SlotsPerEpoch, handler.beaconChainCfg, handler.ethClock)
+		require.NoError(c.t, blobStorage.WriteBlobSidecars(ctx, firstBlockRoot, []*cltypes.BlobSidecar{
+			{
+				Index:                    0,
+				Blob:                     cltypes.Blob{byte(1)},
+				SignedBlockHeader:        blocks[0].SignedBeaconBlockHeader(),
+				CommitmentInclusionProof: solid.NewHashVector(17),
+			},
+		}))
+		require.NoError(c.t, blobStorage.PruneUntil(blobStorage.EarliestAvailableSlot()))
+		handler.blobStoage = blobStorage
+	}
+
 	return []beacontest.HarnessOption{
 		beacontest.WithTesting(c.t),
 		beacontest.WithFilesystem("td", TestDatae),
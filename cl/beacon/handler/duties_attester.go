@@ -17,6 +17,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -28,6 +29,16 @@ import (
 	"github.com/erigontech/erigon/cl/phase1/core/state"
 )
 
+// dutiesCacheSize is how many epochs worth of duties we keep around.
+const dutiesCacheSize = 8
+
+// dutiesCacheKey identifies the duties of an epoch. The dependent root is part of the key
+// so that a reorg past the epoch boundary invalidates the cached entry.
+type dutiesCacheKey struct {
+	epoch         uint64
+	dependentRoot libcommon.Hash
+}
+
 type attesterDutyResponse struct {
 	Pubkey                  libcommon.Bytes48 `json:"pubkey"`
 	ValidatorIndex          uint64            `json:"validator_index,string"`
@@ -65,6 +76,14 @@ func (a *ApiHandler) getDependentRoot(epoch uint64, attester bool) (libcommon.Ha
 	})
 }
 
+// attesterCommittees is the committees layout of a whole epoch, which is what gets cached, as opposed to
+// the duties themselves which depend on the requested validator set.
+type attesterCommittees struct {
+	committeesPerSlot uint64
+	// committees are ordered by slot first and then by committee index.
+	committees [][]uint64
+}
+
 func (a *ApiHandler) getAttesterDuties(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
 	epoch, err := beaconhttp.EpochFromRequest(r)
 	if err != nil {
@@ -97,55 +116,76 @@ func (a *ApiHandler) getAttesterDuties(w http.ResponseWriter, r *http.Request) (
 		idxSet[int(idx)] = struct{}{}
 	}
 
-	resp := []attesterDutyResponse{}
+	cacheKey := dutiesCacheKey{epoch: epoch, dependentRoot: dependentRoot}
+	committees, ok := a.attesterCommitteesCache.Get(cacheKey)
+	if !ok {
+		finalized := a.forkchoiceStore.LowestAvailableSlot() > epoch*a.beaconChainCfg.SlotsPerEpoch
+		if finalized {
+			committees, err = a.readFinalizedAttesterCommittees(r.Context(), epoch)
+		} else {
+			committees, err = a.readHeadAttesterCommittees(epoch)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// an unknown dependent root cannot tell apart different forks, so only cache it when the data is final.
+		if finalized || dependentRoot != (libcommon.Hash{}) {
+			a.attesterCommitteesCache.Add(cacheKey, committees)
+		}
+	}
 
-	// get the duties
-	if a.forkchoiceStore.LowestAvailableSlot() <= epoch*a.beaconChainCfg.SlotsPerEpoch {
-		// non-finality case
-		if err := a.syncedData.ViewHeadState(func(s *state.CachingBeaconState) error {
-			if epoch > state.Epoch(s)+3 {
-				return beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("epoch %d is too far in the future", epoch))
+	resp := []attesterDutyResponse{}
+	startSlot := epoch * a.beaconChainCfg.SlotsPerEpoch
+	for i, idxs := range committees.committees {
+		for vIdx, idx := range idxs {
+			if _, ok := idxSet[int(idx)]; !ok {
+				continue
+			}
+			publicKey, err := a.syncedData.ValidatorPublicKeyByIndex(int(idx))
+			if err != nil {
+				return nil, err
 			}
+			resp = append(resp, attesterDutyResponse{
+				Pubkey:                  publicKey,
+				ValidatorIndex:          idx,
+				CommitteeIndex:          uint64(i) % committees.committeesPerSlot,
+				CommitteeLength:         uint64(len(idxs)),
+				ValidatorCommitteeIndex: uint64(vIdx),
+				CommitteesAtSlot:        committees.committeesPerSlot,
+				Slot:                    startSlot + uint64(i)/committees.committeesPerSlot,
+			})
+		}
+	}
+	return newBeaconResponse(resp).WithOptimistic(a.forkchoiceStore.IsHeadOptimistic()).With("dependent_root", dependentRoot), nil
+}
+
+// readHeadAttesterCommittees computes the committees of a non-finalized epoch from the head state.
+func (a *ApiHandler) readHeadAttesterCommittees(epoch uint64) (*attesterCommittees, error) {
+	committees := &attesterCommittees{}
+	return committees, a.syncedData.ViewHeadState(func(s *state.CachingBeaconState) error {
+		if epoch > state.Epoch(s)+3 {
+			return beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("epoch %d is too far in the future", epoch))
+		}
 
-			// get active validator indicies
-			committeeCount := s.CommitteeCount(epoch)
-			// now start obtaining the committees from the head state
-			for currSlot := epoch * a.beaconChainCfg.SlotsPerEpoch; currSlot < (epoch+1)*a.beaconChainCfg.SlotsPerEpoch; currSlot++ {
-				for committeeIndex := uint64(0); committeeIndex < committeeCount; committeeIndex++ {
-					idxs, err := s.GetBeaconCommitee(currSlot, committeeIndex)
-					if err != nil {
-						return err
-					}
-					for vIdx, idx := range idxs {
-						if _, ok := idxSet[int(idx)]; !ok {
-							continue
-						}
-						publicKey, err := s.ValidatorPublicKey(int(idx))
-						if err != nil {
-							return err
-						}
-						duty := attesterDutyResponse{
-							Pubkey:                  publicKey,
-							ValidatorIndex:          idx,
-							CommitteeIndex:          committeeIndex,
-							CommitteeLength:         uint64(len(idxs)),
-							ValidatorCommitteeIndex: uint64(vIdx),
-							CommitteesAtSlot:        committeeCount,
-							Slot:                    currSlot,
-						}
-						resp = append(resp, duty)
-					}
+		committees.committeesPerSlot = s.CommitteeCount(epoch)
+		committees.committees = make([][]uint64, 0, committees.committeesPerSlot*a.beaconChainCfg.SlotsPerEpoch)
+		// now start obtaining the committees from the head state
+		for currSlot := epoch * a.beaconChainCfg.SlotsPerEpoch; currSlot < (epoch+1)*a.beaconChainCfg.SlotsPerEpoch; currSlot++ {
+			for committeeIndex := uint64(0); committeeIndex < committees.committeesPerSlot; committeeIndex++ {
+				idxs, err := s.GetBeaconCommitee(currSlot, committeeIndex)
+				if err != nil {
+					return err
 				}
+				committees.committees = append(committees.committees, idxs)
 			}
-			return nil
-		}); err != nil {
-			return nil, err
 		}
+		return nil
+	})
+}
 
-		return newBeaconResponse(resp).WithOptimistic(a.forkchoiceStore.IsHeadOptimistic()).With("dependent_root", dependentRoot), nil
-	}
-
-	tx, err := a.indiciesDB.BeginRo(r.Context())
+// readFinalizedAttesterCommittees reconstructs the committees of a finalized epoch from the historical states.
+func (a *ApiHandler) readFinalizedAttesterCommittees(ctx context.Context, epoch uint64) (*attesterCommittees, error) {
+	tx, err := a.indiciesDB.BeginRo(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -185,6 +225,10 @@ func (a *ApiHandler) getAttesterDuties(w http.ResponseWriter, r *http.Request) (
 		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("could not read randao mix: %v", err))
 	}
 
+	committees := &attesterCommittees{
+		committeesPerSlot: committeesPerSlot,
+		committees:        make([][]uint64, 0, committeesPerSlot*a.beaconChainCfg.SlotsPerEpoch),
+	}
 	for currSlot := epoch * a.beaconChainCfg.SlotsPerEpoch; currSlot < (epoch+1)*a.beaconChainCfg.SlotsPerEpoch; currSlot++ {
 		for committeeIndex := uint64(0); committeeIndex < committeesPerSlot; committeeIndex++ {
 			index := (currSlot%a.beaconChainCfg.SlotsPerEpoch)*committeesPerSlot + committeeIndex
@@ -193,27 +237,8 @@ func (a *ApiHandler) getAttesterDuties(w http.ResponseWriter, r *http.Request) (
 			if err != nil {
 				return nil, err
 			}
-			for vIdx, idx := range idxs {
-				if _, ok := idxSet[int(idx)]; !ok {
-					continue
-				}
-
-				publicKey, err := a.syncedData.ValidatorPublicKeyByIndex(int(idx))
-				if err != nil {
-					return nil, err
-				}
-				duty := attesterDutyResponse{
-					Pubkey:                  publicKey,
-					ValidatorIndex:          idx,
-					CommitteeIndex:          committeeIndex,
-					CommitteeLength:         uint64(len(idxs)),
-					ValidatorCommitteeIndex: uint64(vIdx),
-					CommitteesAtSlot:        committeesPerSlot,
-					Slot:                    currSlot,
-				}
-				resp = append(resp, duty)
-			}
+			committees.committees = append(committees.committees, idxs)
 		}
 	}
-	return newBeaconResponse(resp).WithOptimistic(a.forkchoiceStore.IsHeadOptimistic()).With("dependent_root", dependentRoot), nil
+	return committees, nil
 }
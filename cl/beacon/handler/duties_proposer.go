@@ -48,7 +48,13 @@ func (a *ApiHandler) getDutiesProposer(w http.ResponseWriter, r *http.Request) (
 		return nil, err
 	}
 
-	if epoch < a.forkchoiceStore.FinalizedCheckpoint().Epoch {
+	cacheKey := dutiesCacheKey{epoch: epoch, dependentRoot: dependentRoot}
+	finalized := epoch < a.forkchoiceStore.FinalizedCheckpoint().Epoch
+	if duties, ok := a.proposerDutiesCache.Get(cacheKey); ok {
+		return a.proposerDutiesResponse(duties, epoch, dependentRoot, finalized), nil
+	}
+
+	if finalized {
 		tx, err := a.indiciesDB.BeginRo(r.Context())
 		if err != nil {
 			return nil, err
@@ -77,11 +83,8 @@ func (a *ApiHandler) getDutiesProposer(w http.ResponseWriter, r *http.Request) (
 				Slot:           epoch*a.beaconChainCfg.SlotsPerEpoch + uint64(i),
 			}
 		}
-		return newBeaconResponse(duties).
-			WithOptimistic(a.forkchoiceStore.IsHeadOptimistic()).
-			WithFinalized(true).
-			WithVersion(a.beaconChainCfg.GetCurrentStateVersion(epoch)).
-			With("dependent_root", dependentRoot), nil
+		a.proposerDutiesCache.Add(cacheKey, duties)
+		return a.proposerDutiesResponse(duties, epoch, dependentRoot, true), nil
 	}
 
 	expectedSlot := epoch * a.beaconChainCfg.SlotsPerEpoch
@@ -139,6 +142,20 @@ func (a *ApiHandler) getDutiesProposer(w http.ResponseWriter, r *http.Request) (
 	}); err != nil {
 		return nil, err
 	}
+	// an unknown dependent root cannot tell apart different forks, so we do not cache it.
+	if dependentRoot != (libcommon.Hash{}) {
+		a.proposerDutiesCache.Add(cacheKey, duties)
+	}
+	return a.proposerDutiesResponse(duties, epoch, dependentRoot, false), nil
+}
 
-	return newBeaconResponse(duties).WithFinalized(false).WithVersion(a.beaconChainCfg.GetCurrentStateVersion(epoch)).With("dependent_root", dependentRoot), nil
+func (a *ApiHandler) proposerDutiesResponse(duties []proposerDuties, epoch uint64, dependentRoot libcommon.Hash, finalized bool) *beaconhttp.BeaconResponse {
+	resp := newBeaconResponse(duties).
+		WithFinalized(finalized).
+		WithVersion(a.beaconChainCfg.GetCurrentStateVersion(epoch)).
+		With("dependent_root", dependentRoot)
+	if finalized {
+		resp = resp.WithOptimistic(a.forkchoiceStore.IsHeadOptimistic())
+	}
+	return resp
 }
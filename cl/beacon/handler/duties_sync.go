@@ -26,6 +26,7 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
 	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
 )
 
@@ -115,12 +116,12 @@ func (a *ApiHandler) getSyncDuties(w http.ResponseWriter, r *http.Request) (*bea
 			ValidatorIndex: idx,
 		}
 	}
+	committeeIndicies, err := a.syncCommitteeValidatorIndicies(syncCommittee)
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, err)
+	}
 	// Now we can iterate over the sync committee and fill the response
-	for idx, committeeParticipantPublicKey := range syncCommittee.GetCommittee() {
-		committeeParticipantIndex, _, err := a.syncedData.ValidatorIndexByPublicKey(committeeParticipantPublicKey)
-		if err != nil {
-			return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("could not find validator with public key %x: %s", committeeParticipantPublicKey, err))
-		}
+	for idx, committeeParticipantIndex := range committeeIndicies {
 		if _, ok := dutiesSet[committeeParticipantIndex]; !ok {
 			continue
 		}
@@ -142,3 +143,31 @@ func (a *ApiHandler) getSyncDuties(w http.ResponseWriter, r *http.Request) (*bea
 
 	return newBeaconResponse(duties).WithOptimistic(a.forkchoiceStore.IsHeadOptimistic()), nil
 }
+
+// syncCommitteeValidatorIndicies resolves the validator indicies of the sync committee members. The result only depends
+// on the committee itself, so it is cached by the committee root for the whole period.
+func (a *ApiHandler) syncCommitteeValidatorIndicies(syncCommittee *solid.SyncCommittee) ([]uint64, error) {
+	root, err := syncCommittee.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	if idxs, ok := a.syncCommitteeIndiciesCache.Get(root); ok {
+		return idxs, nil
+	}
+	committee := syncCommittee.GetCommittee()
+	idxs := make([]uint64, len(committee))
+	allFound := true
+	for i, publicKey := range committee {
+		idx, found, err := a.syncedData.ValidatorIndexByPublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not find validator with public key %x: %s", publicKey, err)
+		}
+		allFound = allFound && found
+		idxs[i] = idx
+	}
+	// a member we could not resolve may be known later on, so do not pin the partial result.
+	if allFound {
+		a.syncCommitteeIndiciesCache.Add(root, idxs)
+	}
+	return idxs, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+)
+
+func TestProposerDutiesCache(t *testing.T) {
+	_, blocks, _, _, postState, handler, _, sm, fcu, _ := setupTestingHandler(t, clparams.Phase0Version, log.Root(), true)
+
+	var err error
+	fcu.HeadVal, err = blocks[len(blocks)-1].Block.HashSSZ()
+	require.NoError(t, err)
+	fcu.HeadSlotVal = blocks[len(blocks)-1].Block.Slot
+	fcu.FinalizedCheckpointVal = solid.Checkpoint{Epoch: fcu.HeadSlotVal / 32, Root: fcu.HeadVal}
+	fcu.StateAtBlockRootVal[fcu.HeadVal] = postState
+	require.NoError(t, sm.OnHeadState(postState))
+
+	server := httptest.NewServer(handler.mux)
+	defer server.Close()
+
+	epoch := fcu.HeadSlotVal / 32
+	dependentRoot, err := handler.getDependentRoot(epoch, false)
+	require.NoError(t, err)
+	require.NotEqual(t, libcommon.Hash{}, dependentRoot)
+
+	get := func() (http.Header, string) {
+		resp, err := server.Client().Get(server.URL + "/eth/v1/validator/duties/proposer/" + strconv.FormatUint(epoch, 10))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Header.Del("Date")
+		return resp.Header, string(body)
+	}
+
+	// duties cached under another dependent root belong to another fork and must not be served.
+	handler.proposerDutiesCache.Add(dutiesCacheKey{epoch: epoch, dependentRoot: libcommon.Hash{1}}, []proposerDuties{{ValidatorIndex: 123456789}})
+	missHeader, missBody := get()
+	require.NotContains(t, missBody, "123456789")
+	require.Contains(t, missBody, dependentRoot.String())
+
+	hitHeader, hitBody := get()
+	require.Equal(t, missHeader, hitHeader)
+	require.Equal(t, missBody, hitBody)
+
+	// whatever is cached under the current dependent root is what gets served.
+	handler.proposerDutiesCache.Add(dutiesCacheKey{epoch: epoch, dependentRoot: dependentRoot}, []proposerDuties{{ValidatorIndex: 123456789}})
+	_, body := get()
+	require.Contains(t, body, "123456789")
+}
@@ -57,6 +57,10 @@ func (a *ApiHandler) EventSourceGetV1Events(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("Connection", "keep-alive")
 
 	topics := r.URL.Query()["topics"]
+	if len(topics) == 0 {
+		http.Error(w, "no topics", http.StatusBadRequest)
+		return
+	}
 	subscribeTopics := mapset.NewSet[event.EventTopic]()
 	for _, v := range topics {
 		topic := event.EventTopic(v)
@@ -74,6 +78,10 @@ func (a *ApiHandler) EventSourceGetV1Events(w http.ResponseWriter, r *http.Reque
 	defer opSub.Unsubscribe()
 	defer stateSub.Unsubscribe()
 
+	// send the headers right away, clients should not wait for the first event to know they are subscribed
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
 	ticker := time.NewTicker(time.Duration(a.beaconChainCfg.SecondsPerSlot) * time.Second)
 	defer ticker.Stop()
 
@@ -94,23 +102,23 @@ func (a *ApiHandler) EventSourceGetV1Events(w http.ResponseWriter, r *http.Reque
 				continue
 			}
 			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Event, string(buf)); err != nil {
-				log.Warn("failed to write event", "err", err)
-				continue
+				log.Debug("failed to write event", "err", err)
+				return
 			}
 			w.(http.Flusher).Flush()
 		case <-ticker.C:
 			// keep connection alive
 			if _, err := w.Write([]byte(":\n\n")); err != nil {
-				log.Warn("failed to write keep alive", "err", err)
-				continue
+				log.Debug("failed to write keep alive", "err", err)
+				return
 			}
 			w.(http.Flusher).Flush()
 		case err := <-stateSub.Err():
+			// the stream has started, so the status can not be changed anymore
 			log.Warn("event error", "err", err)
-			http.Error(w, fmt.Sprintf("event error %v", err), http.StatusInternalServerError)
+			return
 		case err := <-opSub.Err():
 			log.Warn("event error", "err", err)
-			http.Error(w, fmt.Sprintf("event error %v", err), http.StatusInternalServerError)
 			return
 		case <-r.Context().Done():
 			log.Info("Client disconnected from event stream")
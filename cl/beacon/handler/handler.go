@@ -99,6 +99,10 @@ type ApiHandler struct {
 	committeeSub                       *committee_subscription.CommitteeSubscribeMgmt
 	attestationProducer                attestation_producer.AttestationDataProducer
 	slotWaitedForAttestationProduction *lru.Cache[uint64, struct{}]
+	proposerDutiesCache                *lru.Cache[dutiesCacheKey, []proposerDuties]
+	attesterCommitteesCache            *lru.Cache[dutiesCacheKey, *attesterCommittees]
+	syncCommitteeIndiciesCache         *lru.Cache[common.Hash, []uint64]
+	validatorsIndexCache               *lru.Cache[common.Hash, *validatorsIndex]
 	aggregatePool                      aggregation.AggregationPool
 
 	// services
@@ -157,6 +161,22 @@ func NewApiHandler(
 	if err != nil {
 		panic(err)
 	}
+	proposerDutiesCache, err := lru.New[dutiesCacheKey, []proposerDuties]("proposerDuties", dutiesCacheSize)
+	if err != nil {
+		panic(err)
+	}
+	attesterCommitteesCache, err := lru.New[dutiesCacheKey, *attesterCommittees]("attesterCommittees", dutiesCacheSize)
+	if err != nil {
+		panic(err)
+	}
+	syncCommitteeIndiciesCache, err := lru.New[common.Hash, []uint64]("syncCommitteeIndicies", 4)
+	if err != nil {
+		panic(err)
+	}
+	validatorsIndexCache, err := lru.New[common.Hash, *validatorsIndex]("validatorsIndex", validatorsIndexCacheSize)
+	if err != nil {
+		panic(err)
+	}
 	return &ApiHandler{
 		logger:                             logger,
 		validatorParams:                    validatorParams,
@@ -172,6 +192,10 @@ func NewApiHandler(
 		stateReader:                        stateReader,
 		caplinStateSnapshots:               caplinStateSnapshots,
 		slotWaitedForAttestationProduction: slotWaitedForAttestationProduction,
+		proposerDutiesCache:                proposerDutiesCache,
+		attesterCommitteesCache:            attesterCommitteesCache,
+		syncCommitteeIndiciesCache:         syncCommitteeIndiciesCache,
+		validatorsIndexCache:               validatorsIndexCache,
 		randaoMixesPool: sync.Pool{New: func() interface{} {
 			return solid.NewHashVector(int(beaconChainConfig.EpochsPerHistoricalVector))
 		}},
@@ -239,6 +263,7 @@ func (a *ApiHandler) init() {
 
 			if a.routerCfg.Debug {
 				r.Get("/debug/fork_choice", a.GetEthV1DebugBeaconForkChoice)
+				r.Get("/debug/gossip_mesh", beaconhttp.HandleEndpointFunc(a.GetEthV1DebugGossipMesh))
 			}
 			if a.routerCfg.Config {
 				r.Route("/config", func(r chi.Router) {
@@ -268,6 +293,7 @@ func (a *ApiHandler) init() {
 						r.Get("/{block_id}/root", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconBlockRoot))
 					})
 					r.Get("/genesis", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconGenesis))
+					r.Get("/deposit_snapshot", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconDepositSnapshot))
 					r.Get("/blinded_blocks/{block_id}", beaconhttp.HandleEndpointFunc(a.GetEthV1BlindedBlock))
 					r.Route("/pool", func(r chi.Router) {
 						r.Get("/voluntary_exits", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconPoolVoluntaryExits))
@@ -63,6 +63,15 @@ func TestHarnessBellatrix(t *testing.T) {
 	)
 }
 
+func TestHarnessBellatrixPrunedBlobs(t *testing.T) {
+	beacontest.Execute(
+		append(
+			defaultHarnessOpts(harnessConfig{t: t, v: clparams.BellatrixVersion, finalized: true, pruneBlobs: true}),
+			beacontest.WithTestFromFs(Harnesses, "blob_sidecars_pruned"),
+		)...,
+	)
+}
+
 func TestHarnessCapella(t *testing.T) {
 	beacontest.Execute(
 		append(
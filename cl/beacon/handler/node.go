@@ -18,6 +18,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime"
@@ -25,6 +26,7 @@ import (
 
 	sentinel "github.com/erigontech/erigon-lib/gointerfaces/sentinelproto"
 	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/monitor"
 )
 
 /*
@@ -198,3 +200,12 @@ func (a *ApiHandler) GetEthV1NodeSyncing(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// GetEthV1DebugGossipMesh dumps the gossipsub mesh of every topic, it is not part of the standard beacon API.
+func (a *ApiHandler) GetEthV1DebugGossipMesh(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	meshState, ok := monitor.ReadGossipMeshState()
+	if !ok {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, errors.New("gossip mesh state is only available when the sentinel runs in-process"))
+	}
+	return newBeaconResponse(meshState), nil
+}
@@ -204,7 +204,10 @@ func (a *ApiHandler) PostEthV1BeaconRewardsSyncCommittees(w http.ResponseWriter,
 			return nil, beaconhttp.NewEndpointError(http.StatusNotFound, errors.New("non-finalized total active balance not found"))
 		}
 	}
-	committee := syncCommittee.GetCommittee()
+	committee, err := a.syncCommitteeValidatorIndicies(syncCommittee)
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("sync committee public key not found: %s", err))
+	}
 	rewards := make([]syncCommitteeReward, 0, len(committee))
 
 	syncAggregate := blk.Block.Body.SyncAggregate
@@ -217,11 +220,7 @@ func (a *ApiHandler) PostEthV1BeaconRewardsSyncCommittees(w http.ResponseWriter,
 	accumulatedRewards := map[uint64]int64{}
 	participantReward := int64(a.syncParticipantReward(totalActiveBalance))
 
-	for committeeIdx, v := range committee {
-		idx, _, err := a.syncedData.ValidatorIndexByPublicKey(v)
-		if err != nil {
-			return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("sync committee public key not found: %s", err))
-		}
+	for committeeIdx, idx := range committee {
 		if len(filterIndiciesSet) > 0 {
 			if _, ok := filterIndiciesSet[idx]; !ok {
 				continue
@@ -305,7 +305,12 @@ func (a *ApiHandler) writeValidatorsResponse(
 
 	if blockId.Head() { // Lets see if we point to head, if yes then we need to look at the head state we always keep.
 		if err := a.syncedData.ViewHeadState(func(s *state.CachingBeaconState) error {
-			responseValidators(w, filterIndicies, statusFilters, state.Epoch(s), s.Balances(), s.Validators(), false, isOptimistic)
+			headRoot, err := s.BlockRoot()
+			if err != nil {
+				return err
+			}
+			selected := a.selectValidators(headRoot, state.Epoch(s), s.Validators(), s.Balances(), filterIndicies, statusFilters)
+			responseValidators(w, selected, statusFilters, state.Epoch(s), s.Balances(), s.Validators(), false, isOptimistic)
 			return nil
 		}); err != nil {
 			http.Error(w, errors.New("node is not synced").Error(), http.StatusServiceUnavailable)
@@ -343,7 +348,8 @@ func (a *ApiHandler) writeValidatorsResponse(
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		responseValidators(w, filterIndicies, statusFilters, stateEpoch, balances, validatorSet, true, isOptimistic)
+		selected := a.selectValidators(blockRoot, stateEpoch, validatorSet, balances, filterIndicies, statusFilters)
+		responseValidators(w, selected, statusFilters, stateEpoch, balances, validatorSet, true, isOptimistic)
 		return
 	}
 	balances, err := a.forkchoiceStore.GetBalances(blockRoot)
@@ -364,7 +370,8 @@ func (a *ApiHandler) writeValidatorsResponse(
 		http.Error(w, errors.New("validators not found").Error(), http.StatusNotFound)
 		return
 	}
-	responseValidators(w, filterIndicies, statusFilters, stateEpoch, balances, validators, *slot <= a.forkchoiceStore.FinalizedSlot(), isOptimistic)
+	selected := a.selectValidators(blockRoot, stateEpoch, validators, balances, filterIndicies, statusFilters)
+	responseValidators(w, selected, statusFilters, stateEpoch, balances, validators, *slot <= a.forkchoiceStore.FinalizedSlot(), isOptimistic)
 }
 
 func parseQueryValidatorIndex(syncedData synced_data.SyncedData, id string) (uint64, error) {
@@ -619,7 +626,8 @@ func (d directString) MarshalJSON() ([]byte, error) {
 	return []byte(d), nil
 }
 
-func responseValidators(w http.ResponseWriter, filterIndicies []uint64, filterStatuses []validatorStatus, stateEpoch uint64, balances solid.Uint64ListSSZ, validators *solid.ValidatorSet, finalized bool, optimistic bool) {
+// responseValidators writes the selected validators (all of them if selected is nil), in ascending index order.
+func responseValidators(w http.ResponseWriter, selected []uint64, filterStatuses []validatorStatus, stateEpoch uint64, balances solid.Uint64ListSSZ, validators *solid.ValidatorSet, finalized bool, optimistic bool) {
 	// todo: refactor this function
 	b := stringsBuilderPool.Get().(*strings.Builder)
 	defer stringsBuilderPool.Put(b)
@@ -636,10 +644,7 @@ func responseValidators(w http.ResponseWriter, filterIndicies []uint64, filterSt
 	b.WriteString("[")
 	first := true
 	var err error
-	validators.Range(func(i int, v solid.Validator, l int) bool {
-		if len(filterIndicies) > 0 && !slices.Contains(filterIndicies, uint64(i)) {
-			return true
-		}
+	writeValidator := func(i int, v solid.Validator, _ int) bool {
 		// "{\"index\":\"%d\",\"status\":\"%s\",\"balance\":\"%d\",\"validator\":{\"pubkey\":\"0x%x\",\"withdrawal_credentials\":\"0x%x\",\"effective_balance\":\"%d\",\"slashed\":%t,\"activation_eligibility_epoch\":\"%d\",\"activation_epoch\":\"%d\",\"exit_epoch\":\"%d\",\"withdrawable_epoch\":\"%d\"}}"
 		status := validatorStatusFromValidator(v, stateEpoch, balances.Get(i))
 		if shouldStatusBeFiltered(status, filterStatuses) {
@@ -669,7 +674,16 @@ func responseValidators(w http.ResponseWriter, filterIndicies []uint64, filterSt
 		}
 
 		return true
-	})
+	}
+	if selected == nil {
+		validators.Range(writeValidator)
+	} else {
+		for _, i := range selected {
+			if !writeValidator(int(i), validators.Get(int(i)), 0) {
+				break
+			}
+		}
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
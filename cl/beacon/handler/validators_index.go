@@ -0,0 +1,81 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"slices"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+)
+
+const validatorsIndexCacheSize = 4
+
+// validatorsIndex buckets the validators of a state by status. It is built once per state, so that the queries filtered
+// by status do not go through the whole validator set.
+type validatorsIndex struct {
+	epoch   uint64
+	buckets [validatorWithdrawalDone + 1][]uint32 // status -> indicies of the validators, in ascending order
+}
+
+func newValidatorsIndex(epoch uint64, validators *solid.ValidatorSet, balances solid.Uint64ListSSZ) *validatorsIndex {
+	idx := &validatorsIndex{epoch: epoch}
+	validators.Range(func(i int, v solid.Validator, _ int) bool {
+		status := validatorStatusFromValidator(v, epoch, balances.Get(i))
+		idx.buckets[status] = append(idx.buckets[status], uint32(i))
+		return true
+	})
+	return idx
+}
+
+// withStatuses returns the indicies of the validators matching any of the statuses, in ascending order.
+func (v *validatorsIndex) withStatuses(statuses []validatorStatus) []uint64 {
+	out := []uint64{}
+	for status := validatorPendingInitialized; status <= validatorWithdrawalDone; status++ {
+		if shouldStatusBeFiltered(status, statuses) {
+			continue
+		}
+		for _, i := range v.buckets[status] {
+			out = append(out, uint64(i))
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// selectValidators returns the indicies of the validators to respond with, in ascending order, or nil for all of them.
+// The statuses of the validators selected by index are not checked, as there are few of them.
+func (a *ApiHandler) selectValidators(stateRoot common.Hash, epoch uint64, validators *solid.ValidatorSet, balances solid.Uint64ListSSZ, filterIndicies []uint64, filterStatuses []validatorStatus) []uint64 {
+	if len(filterIndicies) > 0 {
+		selected := slices.Clone(filterIndicies)
+		slices.Sort(selected)
+		selected = slices.Compact(selected)
+		for len(selected) > 0 && selected[len(selected)-1] >= uint64(validators.Length()) {
+			selected = selected[:len(selected)-1]
+		}
+		return selected
+	}
+	if len(filterStatuses) == 0 {
+		return nil
+	}
+	idx, ok := a.validatorsIndexCache.Get(stateRoot)
+	if !ok || idx.epoch != epoch { // the head state keeps its root across empty slots
+		idx = newValidatorsIndex(epoch, validators, balances)
+		a.validatorsIndexCache.Add(stateRoot, idx)
+	}
+	return idx.withStatuses(filterStatuses)
+}
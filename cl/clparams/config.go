@@ -44,11 +44,22 @@ type CaplinConfig struct {
 	Backfilling               bool
 	BlobBackfilling           bool
 	BlobPruningDisabled       bool
+	BlobsRetentionEpochs      uint64 // 0 means MIN_EPOCHS_FOR_BLOBS_SIDECARS_REQUEST
 	Archive                   bool
 	SnapshotGenerationEnabled bool
 	NetworkId                 NetworkType
+	// HistoricalStatesCacheSize is the number of reconstructed historical states kept around by the beacon API, 0 disables the cache.
+	HistoricalStatesCacheSize int
+	// HotStatesEpochs is the number of recent epochs whose forkchoice states are kept in memory, 0 keeps only the head state.
+	HotStatesEpochs uint64
+	// ColdStatesSlotFrequency is the number of slots between the forkchoice states compressed on disk, 0 means every 4 slots.
+	ColdStatesSlotFrequency uint64
 	// DisableCheckpointSync is optional and is used to disable checkpoint sync used by default in the node
 	DisabledCheckpointSync bool
+	// CheckpointSyncPolicy is how many checkpoint sync providers need to agree on the state before it is trusted.
+	CheckpointSyncPolicy CheckpointSyncPolicy
+	// CheckpointSyncStateRoot is optional and pins the root of the state served by checkpoint sync.
+	CheckpointSyncStateRoot libcommon.Hash
 	// CaplinMeVRelayUrl is optional and is used to connect to the external builder service.
 	// If it's set, the node will start in builder mode
 	MevRelayUrl string
@@ -60,22 +71,32 @@ type CaplinConfig struct {
 	CustomGenesisStatePath string
 
 	// Network stuff
-	CaplinDiscoveryAddr         string
-	CaplinDiscoveryPort         uint64
-	CaplinDiscoveryTCPPort      uint64
-	SentinelAddr                string
-	SentinelPort                uint64
-	SubscribeAllTopics          bool
-	MaxPeerCount                uint64
-	EnableUPnP                  bool
-	MaxInboundTrafficPerPeer    datasize.ByteSize
-	MaxOutboundTrafficPerPeer   datasize.ByteSize
-	AdptableTrafficRequirements bool
+	CaplinDiscoveryAddr    string
+	CaplinDiscoveryPort    uint64
+	CaplinDiscoveryTCPPort uint64
+	SentinelAddr           string
+	SentinelPort           uint64
+	SubscribeAllTopics     bool
+	// Attestation subnets subscribed to regardless of the validators duties, and how often they're re-picked
+	AttestationSubnetBackbone       uint64
+	AttestationSubnetRotationEpochs uint64
+	MaxPeerCount                    uint64
+	EnableUPnP                      bool
+	MaxInboundTrafficPerPeer        datasize.ByteSize
+	MaxOutboundTrafficPerPeer       datasize.ByteSize
+	AdptableTrafficRequirements     bool
 	// Erigon Sync
 	LoopBlockLimit uint64
 	// Beacon API router configuration
 	BeaconAPIRouter beacon_router_configuration.RouterConfiguration
 
+	// Embedded validator client, it runs if either keystores or a web3signer are configured
+	ValidatorKeystoresDir string
+	ValidatorPasswordFile string
+	Web3SignerUrl         string
+	ValidatorFeeRecipient libcommon.Address
+	ValidatorGraffiti     string
+
 	BootstrapNodes []string
 	StaticPeers    []string
 }
@@ -88,6 +109,10 @@ func (c CaplinConfig) HaveInvalidDevnetParams() bool {
 	return c.CustomConfigPath == "" || c.CustomGenesisStatePath == ""
 }
 
+func (c CaplinConfig) ValidatorClientEnabled() bool {
+	return c.ValidatorKeystoresDir != "" || c.Web3SignerUrl != ""
+}
+
 func (c CaplinConfig) RelayUrlExist() bool {
 	return c.MevRelayUrl != ""
 }
@@ -338,6 +363,28 @@ var CheckpointSyncEndpoints = map[NetworkType][]string{
 // ConfigurableCheckpointsURLs is customized by the user to specify the checkpoint sync endpoints.
 var ConfigurableCheckpointsURLs = []string{}
 
+// CheckpointSyncPolicy defines how the states served by the checkpoint sync endpoints are cross-verified.
+type CheckpointSyncPolicy string
+
+const (
+	// CheckpointSyncPolicyAny trusts the first endpoint that serves a state.
+	CheckpointSyncPolicyAny CheckpointSyncPolicy = "any"
+	// CheckpointSyncPolicyMajority requires more than half of the endpoints to serve the same state.
+	CheckpointSyncPolicyMajority CheckpointSyncPolicy = "majority"
+	// CheckpointSyncPolicyAll requires all of the endpoints to serve the same state.
+	CheckpointSyncPolicyAll CheckpointSyncPolicy = "all"
+)
+
+func ParseCheckpointSyncPolicy(s string) (CheckpointSyncPolicy, error) {
+	switch p := CheckpointSyncPolicy(s); p {
+	case CheckpointSyncPolicyAny, CheckpointSyncPolicyMajority, CheckpointSyncPolicyAll:
+		return p, nil
+	case "":
+		return CheckpointSyncPolicyAny, nil
+	}
+	return "", fmt.Errorf("unknown checkpoint sync policy %q, expected one of %s, %s or %s", s, CheckpointSyncPolicyAny, CheckpointSyncPolicyMajority, CheckpointSyncPolicyAll)
+}
+
 // MinEpochsForBlockRequests  equal to MIN_VALIDATOR_WITHDRAWABILITY_DELAY + CHURN_LIMIT_QUOTIENT / 2
 func (b *BeaconChainConfig) MinEpochsForBlockRequests() uint64 {
 	return b.MinValidatorWithdrawabilityDelay + (b.ChurnLimitQuotient)/2
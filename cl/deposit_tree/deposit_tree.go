@@ -0,0 +1,220 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package deposit_tree implements the deposit contract merkle tree with the finalization and snapshots of EIP-4881,
+// so that a node bootstrapped from a checkpoint does not need every deposit ever made to follow the deposit contract.
+package deposit_tree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+// DepositContractDepth is the depth of the deposit contract merkle tree, without the length mix-in.
+const DepositContractDepth = 32
+
+var ErrNotEnoughDeposits = errors.New("deposit tree: not enough deposits to finalize")
+
+// Snapshot is the EIP-4881 deposit tree snapshot, the finalized part of the tree.
+type Snapshot struct {
+	// Finalized are the roots of the largest finalized subtrees, from the leftmost one.
+	Finalized            []libcommon.Hash `json:"finalized"`
+	DepositRoot          libcommon.Hash   `json:"deposit_root"`
+	DepositCount         uint64           `json:"deposit_count,string"`
+	ExecutionBlockHash   libcommon.Hash   `json:"execution_block_hash"`
+	ExecutionBlockHeight uint64           `json:"execution_block_height,string"`
+}
+
+// CalculateRoot computes the deposit root, with the length mix-in, of the deposits in the snapshot.
+func (s *Snapshot) CalculateRoot() (libcommon.Hash, error) {
+	if s.DepositCount >= 1<<DepositContractDepth {
+		return libcommon.Hash{}, fmt.Errorf("deposit tree snapshot: too many deposits %d", s.DepositCount)
+	}
+	if len(s.Finalized) != bits.OnesCount64(s.DepositCount) {
+		return libcommon.Hash{}, fmt.Errorf("deposit tree snapshot: %d finalized roots for %d deposits", len(s.Finalized), s.DepositCount)
+	}
+	t := &DepositTree{finalized: s.Finalized, finalizedCount: s.DepositCount}
+	return t.Root(), nil
+}
+
+// DepositTree is the deposit contract merkle tree. Only the roots of the finalized subtrees are kept, together with
+// the deposits after them.
+type DepositTree struct {
+	finalized      []libcommon.Hash
+	finalizedCount uint64
+	leaves         []libcommon.Hash // deposits from finalizedCount onwards
+
+	// execution block of the last finalization, zero if the tree was never finalized
+	executionBlockHash   libcommon.Hash
+	executionBlockHeight uint64
+}
+
+// New creates an empty deposit tree.
+func New() *DepositTree {
+	return &DepositTree{}
+}
+
+// FromSnapshot creates the deposit tree from a snapshot, after checking its deposit root.
+func FromSnapshot(snapshot *Snapshot) (*DepositTree, error) {
+	root, err := snapshot.CalculateRoot()
+	if err != nil {
+		return nil, err
+	}
+	if root != snapshot.DepositRoot {
+		return nil, fmt.Errorf("deposit tree snapshot: deposit root mismatch, expected %x, got %x", snapshot.DepositRoot, root)
+	}
+	return &DepositTree{
+		finalized:            append([]libcommon.Hash{}, snapshot.Finalized...),
+		finalizedCount:       snapshot.DepositCount,
+		executionBlockHash:   snapshot.ExecutionBlockHash,
+		executionBlockHeight: snapshot.ExecutionBlockHeight,
+	}, nil
+}
+
+// DepositCount returns the number of deposits in the tree.
+func (t *DepositTree) DepositCount() uint64 {
+	return t.finalizedCount + uint64(len(t.leaves))
+}
+
+// FinalizedDepositCount returns the number of finalized deposits.
+func (t *DepositTree) FinalizedDepositCount() uint64 {
+	return t.finalizedCount
+}
+
+// PushLeaf appends the hash tree root of a deposit data to the tree.
+func (t *DepositTree) PushLeaf(leaf libcommon.Hash) error {
+	if t.DepositCount() >= 1<<DepositContractDepth-1 {
+		return errors.New("deposit tree: tree is full")
+	}
+	t.leaves = append(t.leaves, leaf)
+	return nil
+}
+
+// Root returns the deposit root of the tree, with the length mix-in, as found in the eth1 data.
+func (t *DepositTree) Root() libcommon.Hash {
+	return mixInLength(t.node(DepositContractDepth, 0, t.DepositCount()), t.DepositCount())
+}
+
+// Proof returns the merkle branch, with the length mix-in, of the deposit at the given index. Finalized deposits
+// cannot be proven anymore.
+func (t *DepositTree) Proof(index uint64) ([]libcommon.Hash, error) {
+	count := t.DepositCount()
+	if index < t.finalizedCount || index >= count {
+		return nil, fmt.Errorf("deposit tree: cannot prove deposit %d, finalized %d, count %d", index, t.finalizedCount, count)
+	}
+	branch := make([]libcommon.Hash, 0, DepositContractDepth+1)
+	for level := 0; level < DepositContractDepth; level++ {
+		branch = append(branch, t.node(level, (index>>level)^1, count))
+	}
+	var length libcommon.Hash
+	binary.LittleEndian.PutUint64(length[:], count)
+	return append(branch, length), nil
+}
+
+// Finalize prunes the deposits counted by the finalized eth1 data, which must match the tree, and records the
+// execution block it was taken from for the snapshot.
+func (t *DepositTree) Finalize(eth1Data *cltypes.Eth1Data, executionBlockHeight uint64) error {
+	count := eth1Data.DepositCount
+	if count < t.finalizedCount {
+		return fmt.Errorf("deposit tree: cannot finalize %d deposits, %d are already finalized", count, t.finalizedCount)
+	}
+	if count > t.DepositCount() {
+		return fmt.Errorf("%w: %d, got %d", ErrNotEnoughDeposits, count, t.DepositCount())
+	}
+	if root := mixInLength(t.node(DepositContractDepth, 0, count), count); root != eth1Data.Root {
+		return fmt.Errorf("deposit tree: deposit root mismatch at %d deposits, expected %x, got %x", count, eth1Data.Root, root)
+	}
+
+	finalized := make([]libcommon.Hash, 0, bits.OnesCount64(count))
+	var start uint64
+	for level := DepositContractDepth - 1; level >= 0; level-- {
+		if count&(1<<level) == 0 {
+			continue
+		}
+		finalized = append(finalized, t.node(level, start>>level, count))
+		start += 1 << level
+	}
+	t.leaves = t.leaves[count-t.finalizedCount:]
+	t.finalized, t.finalizedCount = finalized, count
+	t.executionBlockHash, t.executionBlockHeight = eth1Data.BlockHash, executionBlockHeight
+	return nil
+}
+
+// Snapshot returns the snapshot of the finalized part of the tree, or false if the tree was never finalized.
+func (t *DepositTree) Snapshot() (*Snapshot, bool) {
+	if t.executionBlockHash == (libcommon.Hash{}) {
+		return nil, false
+	}
+	return &Snapshot{
+		Finalized:            append([]libcommon.Hash{}, t.finalized...),
+		DepositRoot:          mixInLength(t.node(DepositContractDepth, 0, t.finalizedCount), t.finalizedCount),
+		DepositCount:         t.finalizedCount,
+		ExecutionBlockHash:   t.executionBlockHash,
+		ExecutionBlockHeight: t.executionBlockHeight,
+	}, true
+}
+
+// node computes the root of the subtree at the given level and index, made of the first count deposits.
+func (t *DepositTree) node(level int, index, count uint64) libcommon.Hash {
+	start := index << level
+	if start >= count {
+		return merkle_tree.ZeroHashes[level]
+	}
+	if start+1<<level <= t.finalizedCount {
+		if root, ok := t.finalizedNode(level, start); ok {
+			return root
+		}
+	}
+	if level == 0 {
+		// finalized leaves are never reached, their subtrees are all found above
+		return t.leaves[start-t.finalizedCount]
+	}
+	return utils.Sha256(t.node(level-1, 2*index, count).Bytes(), t.node(level-1, 2*index+1, count).Bytes())
+}
+
+// finalizedNode looks up the root of a finalized subtree. The finalized subtrees follow the set bits of the finalized
+// count, from the highest.
+func (t *DepositTree) finalizedNode(level int, start uint64) (libcommon.Hash, bool) {
+	var (
+		i        int
+		position uint64
+	)
+	for l := DepositContractDepth - 1; l >= 0; l-- {
+		if t.finalizedCount&(1<<l) == 0 {
+			continue
+		}
+		if l == level && position == start {
+			return t.finalized[i], true
+		}
+		position += 1 << l
+		i++
+	}
+	return libcommon.Hash{}, false
+}
+
+func mixInLength(root libcommon.Hash, length uint64) libcommon.Hash {
+	var encodedLength libcommon.Hash
+	binary.LittleEndian.PutUint64(encodedLength[:], length)
+	return utils.Sha256(root[:], encodedLength[:])
+}
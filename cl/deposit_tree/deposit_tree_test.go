@@ -0,0 +1,131 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package deposit_tree
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+func testLeaf(i int) libcommon.Hash {
+	return utils.Sha256([]byte{byte(i), byte(i >> 8)})
+}
+
+// naiveRoot hashes the whole tree level by level.
+func naiveRoot(leaves []libcommon.Hash) libcommon.Hash {
+	layer := append([]libcommon.Hash{}, leaves...)
+	for level := 0; level < DepositContractDepth; level++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, merkle_tree.ZeroHashes[level])
+		}
+		next := make([]libcommon.Hash, 0, len(layer)/2+1)
+		for i := 0; i < len(layer); i += 2 {
+			next = append(next, utils.Sha256(layer[i][:], layer[i+1][:]))
+		}
+		if len(next) == 0 {
+			next = append(next, merkle_tree.ZeroHashes[level+1])
+		}
+		layer = next
+	}
+	return mixInLength(layer[0], uint64(len(leaves)))
+}
+
+func TestDepositTreeRoot(t *testing.T) {
+	tree := New()
+	require.Equal(t, naiveRoot(nil), tree.Root())
+	var leaves []libcommon.Hash
+	for i := 0; i < 70; i++ {
+		leaves = append(leaves, testLeaf(i))
+		require.NoError(t, tree.PushLeaf(testLeaf(i)))
+		require.Equal(t, naiveRoot(leaves), tree.Root())
+	}
+}
+
+func TestDepositTreeFinalize(t *testing.T) {
+	tree := New()
+	var leaves []libcommon.Hash
+	for i := 0; i < 45; i++ {
+		leaves = append(leaves, testLeaf(i))
+		require.NoError(t, tree.PushLeaf(testLeaf(i)))
+	}
+	_, ok := tree.Snapshot()
+	require.False(t, ok)
+
+	require.ErrorIs(t, tree.Finalize(&cltypes.Eth1Data{DepositCount: 46}, 1), ErrNotEnoughDeposits)
+	require.Error(t, tree.Finalize(&cltypes.Eth1Data{DepositCount: 21, Root: libcommon.Hash{1}}, 1))
+
+	for _, count := range []int{21, 21, 32, 43} {
+		require.NoError(t, tree.Finalize(&cltypes.Eth1Data{DepositCount: uint64(count), Root: naiveRoot(leaves[:count]), BlockHash: libcommon.Hash{byte(count)}}, uint64(count)))
+		require.Equal(t, uint64(count), tree.FinalizedDepositCount())
+		require.Equal(t, naiveRoot(leaves), tree.Root())
+
+		// unfinalized deposits can still be proven
+		for i := count; i < len(leaves); i++ {
+			proof, err := tree.Proof(uint64(i))
+			require.NoError(t, err)
+			require.True(t, utils.IsValidMerkleBranch(leaves[i], proof, DepositContractDepth+1, uint64(i), tree.Root()))
+		}
+		_, err := tree.Proof(uint64(count - 1))
+		require.Error(t, err)
+	}
+	require.Error(t, tree.Finalize(&cltypes.Eth1Data{DepositCount: 42, Root: naiveRoot(leaves[:42])}, 1))
+}
+
+func TestDepositTreeSnapshot(t *testing.T) {
+	tree := New()
+	var leaves []libcommon.Hash
+	for i := 0; i < 27; i++ {
+		leaves = append(leaves, testLeaf(i))
+		require.NoError(t, tree.PushLeaf(testLeaf(i)))
+	}
+	eth1Data := &cltypes.Eth1Data{DepositCount: 23, Root: naiveRoot(leaves[:23]), BlockHash: libcommon.Hash{1}}
+	require.NoError(t, tree.Finalize(eth1Data, 100))
+	snapshot, ok := tree.Snapshot()
+	require.True(t, ok)
+	require.Len(t, snapshot.Finalized, 4) // 16 + 4 + 2 + 1
+	require.Equal(t, eth1Data.Root, snapshot.DepositRoot)
+	require.Equal(t, uint64(100), snapshot.ExecutionBlockHeight)
+
+	encoded, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	decoded := &Snapshot{}
+	require.NoError(t, json.Unmarshal(encoded, decoded))
+	require.Equal(t, snapshot, decoded)
+
+	restored, err := FromSnapshot(decoded)
+	require.NoError(t, err)
+	require.Equal(t, eth1Data.Root, restored.Root())
+	for _, leaf := range leaves[23:] {
+		require.NoError(t, restored.PushLeaf(leaf))
+	}
+	require.Equal(t, tree.Root(), restored.Root())
+
+	decoded.Finalized[0] = libcommon.Hash{1}
+	_, err = FromSnapshot(decoded)
+	require.Error(t, err)
+	decoded.Finalized = decoded.Finalized[1:]
+	_, err = FromSnapshot(decoded)
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+var (
+	gossipIWantReceived = metrics.GetOrCreateCounter("gossip_iwant_received")
+	gossipIWantSent     = metrics.GetOrCreateCounter("gossip_iwant_sent")
+
+	gossipMeshStateProvider atomic.Pointer[func() GossipMeshState]
+)
+
+// GossipTopicMetrics are the gossipsub mesh health metrics of a single topic.
+type GossipTopicMetrics struct {
+	MeshPeers     metrics.Gauge
+	Delivered     metrics.Counter
+	Duplicates    metrics.Counter
+	IHaveReceived metrics.Counter
+	IHaveSent     metrics.Counter
+}
+
+func NewGossipTopicMetrics(topic string) *GossipTopicMetrics {
+	return &GossipTopicMetrics{
+		MeshPeers:     metrics.GetOrCreateGauge(fmt.Sprintf(`gossip_mesh_peers{topic="%s"}`, topic)),
+		Delivered:     metrics.GetOrCreateCounter(fmt.Sprintf(`gossip_delivered{topic="%s"}`, topic)),
+		Duplicates:    metrics.GetOrCreateCounter(fmt.Sprintf(`gossip_duplicates{topic="%s"}`, topic)),
+		IHaveReceived: metrics.GetOrCreateCounter(fmt.Sprintf(`gossip_ihave_received{topic="%s"}`, topic)),
+		IHaveSent:     metrics.GetOrCreateCounter(fmt.Sprintf(`gossip_ihave_sent{topic="%s"}`, topic)),
+	}
+}
+
+// ObserveGossipIWant counts the IWANT message ids received and sent, those are not tied to a topic.
+func ObserveGossipIWant(received, sent int) {
+	gossipIWantReceived.AddInt(received)
+	gossipIWantSent.AddInt(sent)
+}
+
+// ObserveAttestationSubnetBackbone records whether an attestation subnet is in the long-lived backbone, so the message
+// rates of gossip_delivered{topic="beacon_attestation_N"} can be told apart between backbone and duty subnets.
+func ObserveAttestationSubnetBackbone(subnet uint64, backbone bool) {
+	gauge := metrics.GetOrCreateGauge(fmt.Sprintf(`attestation_subnet_backbone{subnet="%d"}`, subnet))
+	if backbone {
+		gauge.SetUint64(1)
+	} else {
+		gauge.SetUint64(0)
+	}
+}
+
+// GossipTopicMeshState is a snapshot of the gossipsub mesh of a single topic.
+type GossipTopicMeshState struct {
+	Topic         string   `json:"topic"`
+	MeshPeers     []string `json:"mesh_peers"`
+	Delivered     uint64   `json:"delivered,string"`
+	Duplicates    uint64   `json:"duplicates,string"`
+	DuplicateRate float64  `json:"duplicate_rate"`
+	IHaveReceived uint64   `json:"ihave_received,string"`
+	IHaveSent     uint64   `json:"ihave_sent,string"`
+}
+
+// GossipMeshState is a snapshot of the gossipsub mesh of all the joined topics.
+type GossipMeshState struct {
+	Topics        []GossipTopicMeshState `json:"topics"`
+	IWantReceived uint64                 `json:"iwant_received,string"`
+	IWantSent     uint64                 `json:"iwant_sent,string"`
+}
+
+// SetGossipMeshStateProvider registers the source of the gossip mesh state, which is the in-process sentinel.
+func SetGossipMeshStateProvider(fn func() GossipMeshState) {
+	gossipMeshStateProvider.Store(&fn)
+}
+
+// ReadGossipMeshState returns the gossip mesh state, false if no sentinel runs in this process.
+func ReadGossipMeshState() (GossipMeshState, bool) {
+	fn := gossipMeshStateProvider.Load()
+	if fn == nil {
+		return GossipMeshState{}, false
+	}
+	return (*fn)(), true
+}
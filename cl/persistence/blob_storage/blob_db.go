@@ -47,7 +47,10 @@ type BlobStorage interface {
 	ReadBlobSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) (out []*cltypes.BlobSidecar, found bool, err error)
 	WriteStream(w io.Writer, slot uint64, blockRoot libcommon.Hash, idx uint64) error // Used for P2P networking
 	KzgCommitmentsCount(ctx context.Context, blockRoot libcommon.Hash) (uint32, error)
-	Prune() error
+	// EarliestAvailableSlot returns the lowest slot whose sidecars are still retained (not pruned).
+	EarliestAvailableSlot() uint64
+	// PruneUntil removes the sidecars of all slots below the given one.
+	PruneUntil(slot uint64) error
 }
 
 type BlobStore struct {
@@ -150,21 +153,28 @@ func (bs *BlobStore) ReadBlobSidecars(ctx context.Context, slot uint64, blockRoo
 	return blobSidecars, true, nil
 }
 
-// Do a bit of pruning
-func (bs *BlobStore) Prune() error {
+// EarliestAvailableSlot returns the first slot which is not subject to pruning. pruning happens in
+// subdivisionSlot-sized chunks, so the result is always aligned to it.
+func (bs *BlobStore) EarliestAvailableSlot() uint64 {
 	if bs.slotsKept == math.MaxUint64 {
-		return nil
+		return 0
 	}
-
 	currentSlot := bs.ethClock.GetCurrentSlot()
-	currentSlot -= bs.slotsKept
-	currentSlot = (currentSlot / subdivisionSlot) * subdivisionSlot
+	if currentSlot < bs.slotsKept {
+		return 0
+	}
+	return ((currentSlot - bs.slotsKept) / subdivisionSlot) * subdivisionSlot
+}
+
+// PruneUntil deletes all the sidecars folders which only hold slots below the given one. The kzg commitments
+// indicies are kept, so that we can still tell apart blocks which had their blobs pruned from blocks with no blobs.
+func (bs *BlobStore) PruneUntil(slot uint64) error {
+	slot = (slot / subdivisionSlot) * subdivisionSlot
 	var startPrune uint64
-	if currentSlot >= 1_000_000 {
-		startPrune = currentSlot - 1_000_000
+	if slot >= 1_000_000 {
+		startPrune = slot - 1_000_000
 	}
-	// delete all the folders that are older than slotsKept
-	for i := startPrune; i < currentSlot; i += subdivisionSlot {
+	for i := startPrune; i < slot; i += subdivisionSlot {
 		bs.fs.RemoveAll(strconv.FormatUint(i/subdivisionSlot, 10))
 	}
 	return nil
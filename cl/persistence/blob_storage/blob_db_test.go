@@ -18,6 +18,7 @@ package blob_storage
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
@@ -26,8 +27,10 @@ import (
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func setupTestDB(t *testing.T) kv.RwDB {
@@ -66,3 +69,58 @@ func TestBlobDB(t *testing.T) {
 	require.Equal(t, s1.SignedBlockHeader, sidecars[0].SignedBlockHeader)
 	require.Equal(t, s2.SignedBlockHeader, sidecars[1].SignedBlockHeader)
 }
+
+func TestBlobDBEarliestAvailableSlot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	ethClock := eth_clock.NewMockEthereumClock(ctrl)
+	ethClock.EXPECT().GetCurrentSlot().Return(uint64(3*subdivisionSlot + 5)).AnyTimes()
+
+	bs := NewBlobStore(db, afero.NewMemMapFs(), subdivisionSlot, &clparams.MainnetBeaconConfig, ethClock)
+	require.Equal(t, uint64(2*subdivisionSlot), bs.EarliestAvailableSlot())
+
+	// not enough slots have passed yet to prune anything
+	bs = NewBlobStore(db, afero.NewMemMapFs(), 4*subdivisionSlot, &clparams.MainnetBeaconConfig, ethClock)
+	require.Equal(t, uint64(0), bs.EarliestAvailableSlot())
+
+	// archive mode keeps everything
+	bs = NewBlobStore(db, afero.NewMemMapFs(), math.MaxUint64, &clparams.MainnetBeaconConfig, ethClock)
+	require.Equal(t, uint64(0), bs.EarliestAvailableSlot())
+}
+
+func TestBlobDBPruneUntil(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	ethClock := eth_clock.NewMockEthereumClock(ctrl)
+	ethClock.EXPECT().GetCurrentSlot().Return(uint64(3*subdivisionSlot + 5)).AnyTimes()
+
+	oldSlot, newSlot := uint64(5), uint64(2*subdivisionSlot+1)
+	oldSidecar := cltypes.NewBlobSidecar(0, &cltypes.Blob{1}, libcommon.Bytes48{2}, libcommon.Bytes48{3}, &cltypes.SignedBeaconBlockHeader{Header: &cltypes.BeaconBlockHeader{Slot: oldSlot}}, solid.NewHashVector(cltypes.CommitmentBranchSize))
+	newSidecar := cltypes.NewBlobSidecar(0, &cltypes.Blob{3}, libcommon.Bytes48{5}, libcommon.Bytes48{9}, &cltypes.SignedBeaconBlockHeader{Header: &cltypes.BeaconBlockHeader{Slot: newSlot}}, solid.NewHashVector(cltypes.CommitmentBranchSize))
+
+	bs := NewBlobStore(db, afero.NewMemMapFs(), subdivisionSlot, &clparams.MainnetBeaconConfig, ethClock)
+	oldRoot, newRoot := libcommon.Hash{1}, libcommon.Hash{2}
+	require.NoError(t, bs.WriteBlobSidecars(context.Background(), oldRoot, []*cltypes.BlobSidecar{oldSidecar}))
+	require.NoError(t, bs.WriteBlobSidecars(context.Background(), newRoot, []*cltypes.BlobSidecar{newSidecar}))
+
+	// archive mode: nothing was frozen yet, so nothing can be pruned
+	require.Equal(t, uint64(0), PruneTarget(bs, func() uint64 { return 0 }))
+	require.Equal(t, bs.EarliestAvailableSlot(), PruneTarget(bs, nil))
+
+	require.NoError(t, bs.PruneUntil(PruneTarget(bs, nil)))
+	_, found, err := bs.ReadBlobSidecars(context.Background(), oldSlot, oldRoot)
+	require.NoError(t, err)
+	require.False(t, found)
+	// the commitments count is kept around after pruning
+	count, err := bs.KzgCommitmentsCount(context.Background(), oldRoot)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), count)
+
+	_, found, err = bs.ReadBlobSidecars(context.Background(), newSlot, newRoot)
+	require.NoError(t, err)
+	require.True(t, found)
+}
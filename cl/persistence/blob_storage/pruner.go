@@ -0,0 +1,54 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package blob_storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+// PruneTarget returns the slot below which sidecars can be removed from the store. In archive mode (frozenBlobs != nil)
+// we never go past what has already been frozen into the blob snapshots, the antiquary takes care of removing
+// those from the store once they are retired.
+func PruneTarget(storage BlobStorage, frozenBlobs func() uint64) uint64 {
+	pruneUntil := storage.EarliestAvailableSlot()
+	if frozenBlobs != nil {
+		pruneUntil = min(pruneUntil, frozenBlobs())
+	}
+	return pruneUntil
+}
+
+// RunPruner periodically prunes the sidecars which fell out of the retention window until the context is cancelled.
+func RunPruner(ctx context.Context, storage BlobStorage, frozenBlobs func() uint64, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pruneUntil := PruneTarget(storage, frozenBlobs)
+		if err := storage.PruneUntil(pruneUntil); err != nil {
+			logger.Warn("[Caplin] Failed to prune blob sidecars", "until", pruneUntil, "err", err)
+			continue
+		}
+		logger.Debug("[Caplin] Pruned blob sidecars", "until", pruneUntil)
+	}
+}
@@ -36,6 +36,7 @@ import (
 	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
 	"github.com/erigontech/erigon/cl/phase1/core/state"
 	"github.com/erigontech/erigon/cl/phase1/core/state/lru"
+	"github.com/erigontech/erigon/cl/transition"
 	"github.com/erigontech/erigon/turbo/snapshotsync"
 	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 	"github.com/klauspost/compress/zstd"
@@ -54,6 +55,11 @@ type HistoricalStatesReader struct {
 	syncedData     synced_data.SyncedData
 
 	shuffledIndiciesCache *lru.CacheWithTTL[uint64, []uint64]
+	// reconstructed states are expensive to rebuild, so we optionally keep the last few around.
+	statesCache *lru.Cache[uint64, *state.CachingBeaconState]
+	// statesCacheProgress is the processing progress the cached states were reconstructed at.
+	statesCacheProgress   uint64
+	statesCacheProgressMu sync.Mutex
 }
 
 func NewHistoricalStatesReader(
@@ -61,8 +67,18 @@ func NewHistoricalStatesReader(
 	blockReader freezeblocks.BeaconSnapshotReader,
 	validatorTable *state_accessors.StaticValidatorTable,
 	genesisState *state.CachingBeaconState, stateSn *snapshotsync.CaplinStateSnapshots,
-	syncedData synced_data.SyncedData) *HistoricalStatesReader {
+	syncedData synced_data.SyncedData,
+	statesCacheSize int) *HistoricalStatesReader {
 	shuffledIndiciesCache := lru.NewWithTTL[uint64, []uint64]("shuffledIndiciesCacheReader", 64, 2*time.Minute)
+	// a full mainnet state is a few hundred MBs, so the states cache is opt-in.
+	var statesCache *lru.Cache[uint64, *state.CachingBeaconState]
+	if statesCacheSize > 0 {
+		var err error
+		statesCache, err = lru.New[uint64, *state.CachingBeaconState]("historicalStatesCacheReader", statesCacheSize)
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	return &HistoricalStatesReader{
 		cfg:                   cfg,
@@ -71,27 +87,121 @@ func NewHistoricalStatesReader(
 		validatorTable:        validatorTable,
 		stateSn:               stateSn,
 		shuffledIndiciesCache: shuffledIndiciesCache,
+		statesCache:           statesCache,
 		syncedData:            syncedData,
 	}
 }
 
+// ReadHistoricalState materializes the canonical state at the given slot. If the states cache is enabled,
+// reconstructed states are cached and callers always receive their own copy so they are free to mutate it.
 func (r *HistoricalStatesReader) ReadHistoricalState(ctx context.Context, tx kv.Tx, slot uint64) (*state.CachingBeaconState, error) {
-	snapshotView := r.stateSn.View()
-	defer snapshotView.Close()
+	if r.statesCache == nil {
+		return r.readHistoricalState(ctx, tx, slot)
+	}
+	if err := r.purgeStatesCacheOnRewind(tx); err != nil {
+		return nil, err
+	}
+	if cached, ok := r.statesCache.Get(slot); ok {
+		return cached.Copy()
+	}
+	ret, err := r.replayFromCachedState(ctx, tx, slot)
+	if err != nil {
+		return nil, err
+	}
+	if ret == nil {
+		if ret, err = r.readHistoricalState(ctx, tx, slot); err != nil || ret == nil {
+			return ret, err
+		}
+	}
+	cached, err := ret.Copy()
+	if err != nil {
+		return nil, err
+	}
+	r.statesCache.Add(slot, cached)
+	return ret, nil
+}
 
-	kvGetter := state_accessors.GetValFnTxAndSnapshot(tx, snapshotView)
+// purgeStatesCacheOnRewind drops the cached states if the states processing went backwards, as the states
+// are then going to be reprocessed and the cached ones may no longer match what is on disk.
+func (r *HistoricalStatesReader) purgeStatesCacheOnRewind(tx kv.Tx) error {
+	progress, err := state_accessors.GetStateProcessingProgress(tx)
+	if err != nil {
+		return err
+	}
+	r.statesCacheProgressMu.Lock()
+	defer r.statesCacheProgressMu.Unlock()
+	if progress < r.statesCacheProgress {
+		r.statesCache.Purge()
+	}
+	r.statesCacheProgress = progress
+	return nil
+}
 
-	ret := state.New(r.cfg)
-	latestProcessedState, err := state_accessors.GetStateProcessingProgress(tx)
+// replayFromCachedState advances the nearest cached state at most an epoch behind the given slot by processing the
+// canonical blocks in between with the state transition, which is cheaper than reconstructing the state from the
+// diffs. It returns nil if there is no such state, or if there is no block at the slot (as readHistoricalState).
+func (r *HistoricalStatesReader) replayFromCachedState(ctx context.Context, tx kv.Tx, slot uint64) (*state.CachingBeaconState, error) {
+	var base *state.CachingBeaconState
+	for distance := uint64(1); base == nil && distance <= r.cfg.SlotsPerEpoch && distance <= slot; distance++ {
+		base, _ = r.statesCache.Get(slot - distance)
+	}
+	if base == nil {
+		return nil, nil
+	}
+	latestProcessedState, err := r.latestProcessedState(tx)
+	if err != nil || slot > latestProcessedState {
+		return nil, err
+	}
+	block, err := r.blockReader.ReadBlockBySlot(ctx, tx, slot)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret, err := base.Copy()
 	if err != nil {
 		return nil, err
 	}
+	for s := ret.Slot() + 1; s < slot; s++ {
+		skipped, err := r.blockReader.ReadBlockBySlot(ctx, tx, s)
+		if err != nil {
+			return nil, err
+		}
+		if skipped == nil { // empty slots are processed along with the next block
+			continue
+		}
+		if err := transition.TransitionState(ret, skipped, nil, false); err != nil {
+			return nil, fmt.Errorf("replaying block at slot %d: %w", s, err)
+		}
+	}
+	if err := transition.TransitionState(ret, block, nil, false); err != nil {
+		return nil, fmt.Errorf("replaying block at slot %d: %w", slot, err)
+	}
+	return ret, nil
+}
 
+// latestProcessedState is the highest slot the states can be read at, from either the db or the state snapshots.
+func (r *HistoricalStatesReader) latestProcessedState(tx kv.Tx) (uint64, error) {
+	latestProcessedState, err := state_accessors.GetStateProcessingProgress(tx)
+	if err != nil {
+		return 0, err
+	}
 	var blocksAvailableInSnapshots uint64
 	if r.stateSn != nil {
 		blocksAvailableInSnapshots = r.stateSn.BlocksAvailable()
 	}
-	latestProcessedState = max(latestProcessedState, blocksAvailableInSnapshots)
+	return max(latestProcessedState, blocksAvailableInSnapshots), nil
+}
+
+func (r *HistoricalStatesReader) readHistoricalState(ctx context.Context, tx kv.Tx, slot uint64) (*state.CachingBeaconState, error) {
+	snapshotView := r.stateSn.View()
+	defer snapshotView.Close()
+
+	kvGetter := state_accessors.GetValFnTxAndSnapshot(tx, snapshotView)
+
+	ret := state.New(r.cfg)
+	latestProcessedState, err := r.latestProcessedState(tx)
+	if err != nil {
+		return nil, err
+	}
 
 	// If this happens, we need to update our static tables
 	if slot > latestProcessedState || slot > r.validatorTable.Slot() {
@@ -54,7 +54,7 @@ func runTest(t *testing.T, blocks []*cltypes.SignedBeaconBlock, preState, postSt
 
 	vt = state_accessors.NewStaticValidatorTable()
 	require.NoError(t, state_accessors.ReadValidatorsTable(tx, vt))
-	hr := historical_states_reader.NewHistoricalStatesReader(&clparams.MainnetBeaconConfig, reader, vt, preState, nil, sn)
+	hr := historical_states_reader.NewHistoricalStatesReader(&clparams.MainnetBeaconConfig, reader, vt, preState, nil, sn, 0)
 	s, err := hr.ReadHistoricalState(ctx, tx, blocks[len(blocks)-1].Block.Slot)
 	require.NoError(t, err)
 
@@ -82,3 +82,92 @@ func TestStateAntiquaryBellatrix(t *testing.T) {
 	blocks, preState, postState := tests.GetBellatrixRandom()
 	runTest(t, blocks, preState, postState)
 }
+
+func TestHistoricalStatesCache(t *testing.T) {
+	_, preState, _ := tests.GetPhase0Random()
+	genesisState, err := preState.Copy()
+	require.NoError(t, err)
+	genesisState.SetSlot(0)
+
+	db := memdb.NewTestDB(t, kv.ChainDB)
+	ctx := context.Background()
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	vt := state_accessors.NewStaticValidatorTable()
+	sn := synced_data.NewSyncedDataManager(&clparams.MainnetBeaconConfig, true)
+	uncached := historical_states_reader.NewHistoricalStatesReader(&clparams.MainnetBeaconConfig, nil, vt, genesisState, nil, sn, 0)
+	cached := historical_states_reader.NewHistoricalStatesReader(&clparams.MainnetBeaconConfig, nil, vt, genesisState, nil, sn, 2)
+
+	readRoot := func(hr *historical_states_reader.HistoricalStatesReader) ([32]byte, *state.CachingBeaconState) {
+		s, err := hr.ReadHistoricalState(ctx, tx, 0)
+		require.NoError(t, err)
+		require.NotNil(t, s)
+		root, err := s.HashSSZ()
+		require.NoError(t, err)
+		return root, s
+	}
+	expectedRoot, _ := readRoot(uncached)
+
+	// the first read populates the cache, mutating what it returned must not leak into it.
+	root, s := readRoot(cached)
+	require.Equal(t, expectedRoot, root)
+	s.SetSlot(1234)
+	root, _ = readRoot(cached)
+	require.Equal(t, expectedRoot, root)
+
+	// cache hits do not reconstruct the state again.
+	genesisState.SetGenesisTime(genesisState.GenesisTime() + 1)
+	root, _ = readRoot(cached)
+	require.Equal(t, expectedRoot, root)
+	newRoot, _ := readRoot(uncached)
+	require.NotEqual(t, expectedRoot, newRoot)
+
+	// states processing going backwards drops the cached states.
+	require.NoError(t, state_accessors.SetStateProcessingProgress(tx, 10))
+	root, _ = readRoot(cached)
+	require.Equal(t, expectedRoot, root)
+	require.NoError(t, state_accessors.SetStateProcessingProgress(tx, 5))
+	root, _ = readRoot(cached)
+	require.Equal(t, newRoot, root)
+}
+
+func TestHistoricalStatesReplay(t *testing.T) {
+	blocks, preState, _ := tests.GetPhase0Random()
+	genesisState, err := preState.Copy()
+	require.NoError(t, err)
+
+	db := memdb.NewTestDB(t, kv.ChainDB)
+	reader := tests.LoadChain(blocks, preState, db, t)
+	ctx := context.Background()
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, state_accessors.SetStateProcessingProgress(tx, blocks[len(blocks)-1].Block.Slot))
+
+	vt := state_accessors.NewStaticValidatorTable()
+	vt.SetSlot(genesisState.Slot())
+	sn := synced_data.NewSyncedDataManager(&clparams.MainnetBeaconConfig, true)
+	hr := historical_states_reader.NewHistoricalStatesReader(&clparams.MainnetBeaconConfig, reader, vt, genesisState, nil, sn, 2)
+
+	// the state less than an epoch after a cached one is replayed from it through the state transition.
+	_, err = hr.ReadHistoricalState(ctx, tx, genesisState.Slot())
+	require.NoError(t, err)
+	require.Less(t, blocks[0].Block.Slot-genesisState.Slot(), clparams.MainnetBeaconConfig.SlotsPerEpoch)
+	s, err := hr.ReadHistoricalState(ctx, tx, blocks[0].Block.Slot)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	root, err := s.HashSSZ()
+	require.NoError(t, err)
+	require.Equal(t, blocks[0].Block.StateRoot, libcommon.Hash(root))
+
+	// no replay beyond the processed states.
+	require.NoError(t, state_accessors.SetStateProcessingProgress(tx, blocks[0].Block.Slot-1))
+	hr = historical_states_reader.NewHistoricalStatesReader(&clparams.MainnetBeaconConfig, reader, vt, genesisState, nil, sn, 2)
+	_, err = hr.ReadHistoricalState(ctx, tx, genesisState.Slot())
+	require.NoError(t, err)
+	s, err = hr.ReadHistoricalState(ctx, tx, blocks[0].Block.Slot)
+	require.NoError(t, err)
+	require.Nil(t, s)
+}
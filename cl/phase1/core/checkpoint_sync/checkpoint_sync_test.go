@@ -7,9 +7,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon/cl/antiquary/tests"
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
 	"github.com/erigontech/erigon/cl/utils"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -46,6 +48,57 @@ func TestRemoteCheckpointSync(t *testing.T) {
 	assert.Equal(t, haveRoot, wantRoot)
 }
 
+func TestRemoteCheckpointSyncCrossVerification(t *testing.T) {
+	_, preState, postState := tests.GetPhase0Random()
+	serve := func(st *state.CachingBeaconState) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enc, err := st.EncodeSSZ(nil)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("could not encode state: %s", err), http.StatusInternalServerError)
+				return
+			}
+			w.Write(enc)
+		}))
+	}
+	agreeing1, agreeing2, lagging := serve(postState), serve(postState), serve(preState)
+	defer agreeing1.Close()
+	defer agreeing2.Close()
+	defer lagging.Close()
+	clparams.ConfigurableCheckpointsURLs = []string{agreeing1.URL, agreeing2.URL, lagging.URL}
+	defer func() { clparams.ConfigurableCheckpointsURLs = []string{} }()
+
+	postRoot, err := postState.HashSSZ()
+	require.NoError(t, err)
+	preRoot, err := preState.HashSSZ()
+	require.NoError(t, err)
+
+	// 2 out of 3 endpoints agree
+	syncer := NewVerifiedRemoteCheckpointSync(&clparams.MainnetBeaconConfig, clparams.MainnetNetwork, clparams.CheckpointSyncPolicyMajority, libcommon.Hash{})
+	st, err := syncer.GetLatestBeaconState(context.Background())
+	require.NoError(t, err)
+	root, err := st.HashSSZ()
+	require.NoError(t, err)
+	assert.Equal(t, postRoot, root)
+
+	// the majority state does not match the pinned root
+	syncer = NewVerifiedRemoteCheckpointSync(&clparams.MainnetBeaconConfig, clparams.MainnetNetwork, clparams.CheckpointSyncPolicyMajority, preRoot)
+	_, err = syncer.GetLatestBeaconState(context.Background())
+	require.Error(t, err)
+
+	// not all endpoints agree
+	syncer = NewVerifiedRemoteCheckpointSync(&clparams.MainnetBeaconConfig, clparams.MainnetNetwork, clparams.CheckpointSyncPolicyAll, libcommon.Hash{})
+	_, err = syncer.GetLatestBeaconState(context.Background())
+	require.Error(t, err)
+
+	// with a pinned root, the first endpoint serving it is trusted
+	syncer = NewVerifiedRemoteCheckpointSync(&clparams.MainnetBeaconConfig, clparams.MainnetNetwork, clparams.CheckpointSyncPolicyAny, preRoot)
+	st, err = syncer.GetLatestBeaconState(context.Background())
+	require.NoError(t, err)
+	root, err = st.HashSSZ()
+	require.NoError(t, err)
+	assert.Equal(t, preRoot, root)
+}
+
 func TestLocalCheckpointSyncFromFile(t *testing.T) {
 	_, st, _ := tests.GetPhase0Random()
 	f := afero.NewMemMapFs()
@@ -0,0 +1,81 @@
+package checkpoint_sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/deposit_tree"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+)
+
+const (
+	checkpointStatePath  = "/eth/v2/debug/beacon/states/finalized"
+	depositSnapshotPath  = "/eth/v1/beacon/deposit_snapshot"
+	depositSnapshotLimit = 1 << 20
+)
+
+// FetchDepositTreeSnapshot fetches the EIP-4881 deposit snapshot from the checkpoint sync endpoints, so that the deposit
+// tree can be followed from the checkpoint state on. Only a snapshot covering the deposits of the state is accepted.
+func FetchDepositTreeSnapshot(ctx context.Context, net clparams.NetworkType, anchorState *state.CachingBeaconState) (*deposit_tree.Snapshot, error) {
+	err := errors.New("no uris for deposit snapshot")
+	for _, uri := range clparams.GetAllCheckpointSyncEndpoints(net) {
+		if !strings.HasSuffix(uri, checkpointStatePath) {
+			continue
+		}
+		uri = strings.TrimSuffix(uri, checkpointStatePath) + depositSnapshotPath
+		var snapshot *deposit_tree.Snapshot
+		snapshot, err = fetchDepositTreeSnapshot(ctx, uri)
+		if err == nil {
+			err = checkDepositTreeSnapshot(snapshot, anchorState)
+		}
+		if err == nil {
+			return snapshot, nil
+		}
+		log.Debug("[Checkpoint Sync] Failed to fetch deposit snapshot", "uri", uri, "err", err)
+	}
+	return nil, err
+}
+
+func fetchDepositTreeSnapshot(ctx context.Context, uri string) (*deposit_tree.Snapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deposit snapshot request failed, bad status code %d", resp.StatusCode)
+	}
+	var response struct {
+		Data *deposit_tree.Snapshot `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, depositSnapshotLimit)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("deposit snapshot decode failed %s", err)
+	}
+	if response.Data == nil {
+		return nil, errors.New("deposit snapshot missing from response")
+	}
+	return response.Data, nil
+}
+
+func checkDepositTreeSnapshot(snapshot *deposit_tree.Snapshot, anchorState *state.CachingBeaconState) error {
+	if snapshot.DepositCount < anchorState.Eth1DepositIndex() {
+		return fmt.Errorf("deposit snapshot is behind the checkpoint state, %d deposits, state deposit index %d", snapshot.DepositCount, anchorState.Eth1DepositIndex())
+	}
+	eth1Data := anchorState.Eth1Data()
+	if snapshot.DepositCount == eth1Data.DepositCount && snapshot.DepositRoot != eth1Data.Root {
+		return fmt.Errorf("deposit snapshot root %x does not match the checkpoint state deposit root %x", snapshot.DepositRoot, eth1Data.Root)
+	}
+	return nil
+}
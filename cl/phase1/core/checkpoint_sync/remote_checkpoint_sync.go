@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
+	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/phase1/core/state"
@@ -17,71 +19,160 @@ import (
 type RemoteCheckpointSync struct {
 	beaconConfig *clparams.BeaconChainConfig
 	net          clparams.NetworkType
+
+	policy            clparams.CheckpointSyncPolicy
+	expectedStateRoot libcommon.Hash // zero if not pinned
 }
 
 func NewRemoteCheckpointSync(beaconConfig *clparams.BeaconChainConfig, net clparams.NetworkType) CheckpointSyncer {
+	return NewVerifiedRemoteCheckpointSync(beaconConfig, net, clparams.CheckpointSyncPolicyAny, libcommon.Hash{})
+}
+
+// NewVerifiedRemoteCheckpointSync creates a CheckpointSyncer which only trusts a state once enough endpoints agree on it,
+// according to the given policy. If expectedStateRoot is not zero, only the state with that root is accepted.
+func NewVerifiedRemoteCheckpointSync(beaconConfig *clparams.BeaconChainConfig, net clparams.NetworkType, policy clparams.CheckpointSyncPolicy, expectedStateRoot libcommon.Hash) CheckpointSyncer {
 	return &RemoteCheckpointSync{
-		beaconConfig: beaconConfig,
-		net:          net,
+		beaconConfig:      beaconConfig,
+		net:               net,
+		policy:            policy,
+		expectedStateRoot: expectedStateRoot,
 	}
 }
 
-func (r *RemoteCheckpointSync) GetLatestBeaconState(ctx context.Context) (*state.CachingBeaconState, error) {
-	uris := clparams.GetAllCheckpointSyncEndpoints(r.net)
-	if len(uris) == 0 {
-		return nil, errors.New("no uris for checkpoint sync")
+func (r *RemoteCheckpointSync) fetchBeaconState(ctx context.Context, uri string) (*state.CachingBeaconState, error) {
+	log.Info("[Checkpoint Sync] Requesting beacon state", "uri", uri)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint sync request failed %s", err)
 	}
 
-	fetchBeaconState := func(uri string) (*state.CachingBeaconState, error) {
-		log.Info("[Checkpoint Sync] Requesting beacon state", "uri", uri)
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
-		if err != nil {
-			return nil, err
-		}
+	req.Header.Set("Accept", "application/octet-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("checkpoint sync failed, bad status code %d", resp.StatusCode)
+	}
+	marshaled, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint sync read failed %s", err)
+	}
 
-		req.Header.Set("Accept", "application/octet-stream")
-		if err != nil {
-			return nil, fmt.Errorf("checkpoint sync request failed %s", err)
-		}
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer func() {
-			err = resp.Body.Close()
-		}()
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("checkpoint sync failed, bad status code %d", resp.StatusCode)
-		}
-		marshaled, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("checkpoint sync read failed %s", err)
-		}
+	slot, err := utils.ExtractSlotFromSerializedBeaconState(marshaled)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint sync read failed %s", err)
+	}
 
-		slot, err := utils.ExtractSlotFromSerializedBeaconState(marshaled)
-		if err != nil {
-			return nil, fmt.Errorf("checkpoint sync read failed %s", err)
-		}
+	epoch := slot / r.beaconConfig.SlotsPerEpoch
+	beaconState := state.New(r.beaconConfig)
+	err = beaconState.DecodeSSZ(marshaled, int(r.beaconConfig.GetCurrentStateVersion(epoch)))
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint sync decode failed %s", err)
+	}
+	return beaconState, nil
+}
 
-		epoch := slot / r.beaconConfig.SlotsPerEpoch
-		beaconState := state.New(r.beaconConfig)
-		err = beaconState.DecodeSSZ(marshaled, int(r.beaconConfig.GetCurrentStateVersion(epoch)))
-		if err != nil {
-			return nil, fmt.Errorf("checkpoint sync decode failed %s", err)
-		}
-		return beaconState, nil
+func (r *RemoteCheckpointSync) GetLatestBeaconState(ctx context.Context) (*state.CachingBeaconState, error) {
+	uris := clparams.GetAllCheckpointSyncEndpoints(r.net)
+	if len(uris) == 0 {
+		return nil, errors.New("no uris for checkpoint sync")
+	}
+	if r.policy == clparams.CheckpointSyncPolicyMajority || r.policy == clparams.CheckpointSyncPolicyAll {
+		return r.getCrossVerifiedBeaconState(ctx, uris)
 	}
 
 	// Try all uris until one succeeds
 	var err error
 	var beaconState *state.CachingBeaconState
 	for _, uri := range uris {
-		beaconState, err = fetchBeaconState(uri)
+		beaconState, err = r.fetchBeaconState(ctx, uri)
+		if err == nil {
+			err = r.checkExpectedStateRoot(beaconState)
+		}
 		if err == nil {
 			return beaconState, nil
 		}
 		log.Warn("[Checkpoint Sync] Failed to fetch beacon state", "uri", uri, "err", err)
 	}
 	return nil, err
+}
 
+// getCrossVerifiedBeaconState fetches the state from all of the endpoints and only returns it if enough of them
+// served the very same state. Endpoints may lag behind each other by an epoch, in which case they do not agree.
+func (r *RemoteCheckpointSync) getCrossVerifiedBeaconState(ctx context.Context, uris []string) (*state.CachingBeaconState, error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		states  = make(map[libcommon.Hash]*state.CachingBeaconState)
+		votes   = make(map[libcommon.Hash]int)
+		lastErr error
+	)
+	for _, uri := range uris {
+		wg.Add(1)
+		go func(uri string) {
+			defer wg.Done()
+			beaconState, err := r.fetchBeaconState(ctx, uri)
+			var root libcommon.Hash
+			if err == nil {
+				root, err = beaconState.HashSSZ()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Warn("[Checkpoint Sync] Failed to fetch beacon state", "uri", uri, "err", err)
+				lastErr = err
+				return
+			}
+			log.Info("[Checkpoint Sync] Fetched beacon state", "uri", uri, "slot", beaconState.Slot(), "root", root)
+			if _, ok := states[root]; !ok {
+				states[root] = beaconState
+			}
+			votes[root]++
+		}(uri)
+	}
+	wg.Wait()
+
+	if len(votes) == 0 {
+		return nil, fmt.Errorf("checkpoint sync failed on all endpoints: %w", lastErr)
+	}
+	var (
+		bestRoot  libcommon.Hash
+		bestVotes int
+	)
+	for root, v := range votes {
+		if v > bestVotes {
+			bestRoot, bestVotes = root, v
+		}
+	}
+
+	required := len(uris)/2 + 1
+	if r.policy == clparams.CheckpointSyncPolicyAll {
+		required = len(uris)
+	}
+	if bestVotes < required {
+		return nil, fmt.Errorf("checkpoint sync endpoints do not agree on the state, policy %s requires %d out of %d endpoints but best state %x got %d", r.policy, required, len(uris), bestRoot, bestVotes)
+	}
+	if err := r.checkExpectedStateRoot(states[bestRoot]); err != nil {
+		return nil, err
+	}
+	log.Info("[Checkpoint Sync] Endpoints agree on beacon state", "root", bestRoot, "agreeing", bestVotes, "endpoints", len(uris))
+	return states[bestRoot], nil
+}
+
+func (r *RemoteCheckpointSync) checkExpectedStateRoot(beaconState *state.CachingBeaconState) error {
+	if r.expectedStateRoot == (libcommon.Hash{}) {
+		return nil
+	}
+	root, err := beaconState.HashSSZ()
+	if err != nil {
+		return err
+	}
+	if root != r.expectedStateRoot {
+		return fmt.Errorf("checkpoint sync state root mismatch, expected %x, got %x at slot %d", r.expectedStateRoot, root, beaconState.Slot())
+	}
+	return nil
 }
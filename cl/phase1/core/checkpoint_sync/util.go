@@ -17,7 +17,7 @@ func ReadOrFetchLatestBeaconState(ctx context.Context, dirs datadir.Dirs, beacon
 	remoteSync := !caplinConfig.DisabledCheckpointSync && !caplinConfig.IsDevnet()
 
 	if remoteSync {
-		syncer = NewRemoteCheckpointSync(beaconCfg, caplinConfig.NetworkId)
+		syncer = NewVerifiedRemoteCheckpointSync(beaconCfg, caplinConfig.NetworkId, caplinConfig.CheckpointSyncPolicy, caplinConfig.CheckpointSyncStateRoot)
 	} else {
 		aferoFs := afero.NewOsFs()
 
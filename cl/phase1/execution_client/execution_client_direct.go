@@ -27,13 +27,18 @@ import (
 	"github.com/erigontech/erigon-lib/common/hexutility"
 	execution "github.com/erigontech/erigon-lib/gointerfaces/executionproto"
 	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/phase1/execution_client/rpc_helper"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
 	"github.com/erigontech/erigon/turbo/execution/eth1/eth1_chain_reader"
 )
 
+// ExecutionClientDirect is the execution engine used when Caplin runs embedded in Erigon. Payloads and forkchoice updates
+// are handed to the in-process execution module as decoded blocks and hashes, skipping the JSON engine API and its HTTP hop.
 type ExecutionClientDirect struct {
 	chainRW eth1_chain_reader.ChainReaderWriterEth1
+	journal *engine_journal.Journal // nil if disabled
 }
 
 func NewExecutionClientDirect(chainRW eth1_chain_reader.ChainReaderWriterEth1) (*ExecutionClientDirect, error) {
@@ -42,6 +47,12 @@ func NewExecutionClientDirect(chainRW eth1_chain_reader.ChainReaderWriterEth1) (
 	}, nil
 }
 
+// SetJournal makes the client record the payloads and forkchoice updates it hands to the execution module into the
+// engine API journal, as the engine API calls they stand for, so they can be replayed as the ones of an external CL.
+func (cc *ExecutionClientDirect) SetJournal(journal *engine_journal.Journal) {
+	cc.journal = journal
+}
+
 func (cc *ExecutionClientDirect) NewPayload(
 	ctx context.Context,
 	payload *cltypes.Eth1Block,
@@ -52,7 +63,25 @@ func (cc *ExecutionClientDirect) NewPayload(
 	if payload == nil {
 		return PayloadStatusValidated, nil
 	}
+	status, err := cc.newPayload(ctx, payload, beaconParentRoot)
+	if cc.journal != nil {
+		method, args, reqErr := newPayloadRequest(payload, beaconParentRoot, versionedHashes, executionRequestsList)
+		if reqErr != nil {
+			return status, err
+		}
+		switch status {
+		case PayloadStatusNone:
+			cc.journal.Record(method, args, nil, err)
+		case PayloadStatusInvalidated:
+			cc.journal.Record(method, args, &engine_types.PayloadStatus{Status: engine_types.InvalidStatus, ValidationError: engine_types.NewStringifiedError(err)}, nil)
+		default:
+			cc.journal.Record(method, args, &engine_types.PayloadStatus{Status: engineStatusByPayloadStatus(status)}, nil)
+		}
+	}
+	return status, err
+}
 
+func (cc *ExecutionClientDirect) newPayload(ctx context.Context, payload *cltypes.Eth1Block, beaconParentRoot *libcommon.Hash) (PayloadStatus, error) {
 	header, err := payload.RlpHeader(beaconParentRoot)
 	if err != nil {
 		// invalid block
@@ -93,26 +122,65 @@ func (cc *ExecutionClientDirect) NewPayload(
 }
 
 func (cc *ExecutionClientDirect) ForkChoiceUpdate(ctx context.Context, finalized libcommon.Hash, head libcommon.Hash, attr *engine_types.PayloadAttributes) ([]byte, error) {
+	status, idBytes, err := cc.forkChoiceUpdate(ctx, finalized, head, attr)
+	if cc.journal != nil {
+		method, args := forkChoiceUpdateRequest(finalized, head, attr)
+		if status == execution.ExecutionStatus_InvalidForkchoice || status == execution.ExecutionStatus_BadBlock {
+			cc.journal.Record(method, args, &engine_types.ForkChoiceUpdatedResponse{
+				PayloadStatus: &engine_types.PayloadStatus{Status: engine_types.InvalidStatus, ValidationError: engine_types.NewStringifiedError(err)},
+			}, nil)
+		} else if err != nil {
+			cc.journal.Record(method, args, nil, err)
+		} else {
+			resp := &engine_types.ForkChoiceUpdatedResponse{PayloadStatus: &engine_types.PayloadStatus{Status: engine_types.ValidStatus}}
+			if status != execution.ExecutionStatus_Success {
+				resp.PayloadStatus.Status = engine_types.SyncingStatus
+			}
+			if idBytes != nil {
+				id := hexutility.Bytes(idBytes)
+				resp.PayloadId = &id
+			}
+			cc.journal.Record(method, args, resp, nil)
+		}
+	}
+	return idBytes, err
+}
+
+// forkChoiceUpdateRequest is the engine API method and arguments standing for a forkchoice update, the version
+// is the one the payload attributes (if any) require.
+func forkChoiceUpdateRequest(finalized libcommon.Hash, head libcommon.Hash, attr *engine_types.PayloadAttributes) (string, []interface{}) {
+	state := &engine_types.ForkChoiceState{HeadHash: head, SafeBlockHash: head, FinalizedBlockHash: finalized}
+	switch {
+	case attr != nil && attr.ParentBeaconBlockRoot != nil:
+		return rpc_helper.ForkChoiceUpdatedV3, []interface{}{state, attr}
+	case attr != nil && attr.Withdrawals != nil:
+		return rpc_helper.ForkChoiceUpdatedV2, []interface{}{state, attr}
+	default:
+		return rpc_helper.ForkChoiceUpdatedV1, []interface{}{state, attr}
+	}
+}
+
+func (cc *ExecutionClientDirect) forkChoiceUpdate(ctx context.Context, finalized libcommon.Hash, head libcommon.Hash, attr *engine_types.PayloadAttributes) (execution.ExecutionStatus, []byte, error) {
 	status, _, _, err := cc.chainRW.UpdateForkChoice(ctx, head, head, finalized)
 	if err != nil {
-		return nil, fmt.Errorf("execution Client RPC failed to retrieve ForkChoiceUpdate response, err: %w", err)
+		return status, nil, fmt.Errorf("execution Client RPC failed to retrieve ForkChoiceUpdate response, err: %w", err)
 	}
 	if status == execution.ExecutionStatus_InvalidForkchoice {
-		return nil, errors.New("forkchoice was invalid")
+		return status, nil, errors.New("forkchoice was invalid")
 	}
 	if status == execution.ExecutionStatus_BadBlock {
-		return nil, errors.New("bad block as forkchoice")
+		return status, nil, errors.New("bad block as forkchoice")
 	}
 	if attr == nil {
-		return nil, nil
+		return status, nil, nil
 	}
 	idBytes := make([]byte, 8)
 	id, err := cc.chainRW.AssembleBlock(head, attr)
 	if err != nil {
-		return nil, err
+		return status, nil, err
 	}
 	binary.LittleEndian.PutUint64(idBytes, id)
-	return idBytes, nil
+	return status, idBytes, nil
 }
 
 func (cc *ExecutionClientDirect) SupportInsertion() bool {
@@ -161,6 +229,10 @@ func (cc *ExecutionClientDirect) HasBlock(ctx context.Context, hash libcommon.Ha
 	return cc.chainRW.HasBlock(ctx, hash)
 }
 
+func (cc *ExecutionClientDirect) HeaderNumber(ctx context.Context, hash libcommon.Hash) (*uint64, error) {
+	return cc.chainRW.HeaderNumber(ctx, hash)
+}
+
 func (cc *ExecutionClientDirect) GetAssembledBlock(_ context.Context, idBytes []byte) (*cltypes.Eth1Block, *engine_types.BlobsBundleV1, *big.Int, error) {
 	return cc.chainRW.GetAssembledBlock(binary.LittleEndian.Uint64(idBytes))
 }
@@ -0,0 +1,87 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package execution_client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	execution "github.com/erigontech/erigon-lib/gointerfaces/executionproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
+	"github.com/erigontech/erigon/turbo/execution/eth1/eth1_chain_reader"
+)
+
+type forkChoiceExecutionModule struct {
+	execution.ExecutionClient
+	status execution.ExecutionStatus
+}
+
+func (m *forkChoiceExecutionModule) UpdateForkChoice(context.Context, *execution.ForkChoice, ...grpc.CallOption) (*execution.ForkChoiceReceipt, error) {
+	return &execution.ForkChoiceReceipt{Status: m.status, LatestValidHash: gointerfaces.ConvertHashToH256(libcommon.Hash{})}, nil
+}
+
+func TestExecutionClientDirectJournal(t *testing.T) {
+	dir := t.TempDir()
+	journal, err := engine_journal.NewJournal(engine_journal.Config{Dir: dir}, log.New())
+	require.NoError(t, err)
+
+	module := &forkChoiceExecutionModule{status: execution.ExecutionStatus_Success}
+	cc, err := NewExecutionClientDirect(eth1_chain_reader.NewChainReaderEth1(params.MainnetChainConfig, module, 1000))
+	require.NoError(t, err)
+	cc.SetJournal(journal)
+
+	ctx := context.Background()
+	_, err = cc.ForkChoiceUpdate(ctx, libcommon.Hash{1}, libcommon.Hash{2}, nil)
+	require.NoError(t, err)
+	module.status = execution.ExecutionStatus_BadBlock
+	_, err = cc.ForkChoiceUpdate(ctx, libcommon.Hash{1}, libcommon.Hash{3}, nil)
+	require.Error(t, err)
+	require.NoError(t, journal.Close())
+
+	// the calls are journaled as the engine API calls they stand for, so they replay against the engine API.
+	entries, err := engine_journal.ReadEntries(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	statuses := make([]engine_types.EngineStatus, len(entries))
+	for i, entry := range entries {
+		require.Equal(t, "engine_forkchoiceUpdatedV1", entry.Method)
+		require.Empty(t, entry.Error)
+		var state engine_types.ForkChoiceState
+		require.NoError(t, json.Unmarshal(entry.Params[0], &state))
+		require.Equal(t, libcommon.Hash{1}, state.FinalizedBlockHash)
+		var resp struct {
+			PayloadStatus struct {
+				Status engine_types.EngineStatus `json:"status"`
+			} `json:"payloadStatus"`
+		}
+		require.NoError(t, json.Unmarshal(entry.Result, &resp))
+		statuses[i] = resp.PayloadStatus.Status
+	}
+	require.Equal(t, []engine_types.EngineStatus{engine_types.ValidStatus, engine_types.InvalidStatus}, statuses)
+
+	method, _ := forkChoiceUpdateRequest(libcommon.Hash{}, libcommon.Hash{}, &engine_types.PayloadAttributes{ParentBeaconBlockRoot: &libcommon.Hash{}})
+	require.Equal(t, "engine_forkchoiceUpdatedV3", method)
+}
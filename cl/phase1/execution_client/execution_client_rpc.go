@@ -82,6 +82,31 @@ func (cc *ExecutionClientRpc) NewPayload(
 		return PayloadStatusValidated, nil
 	}
 
+	engineMethod, args, err := newPayloadRequest(payload, beaconParentRoot, versionedHashes, executionRequestsList)
+	if err != nil {
+		return PayloadStatusNone, err
+	}
+
+	payloadStatus := &engine_types.PayloadStatus{} // As it is done in the rpcdaemon
+	log.Debug("[ExecutionClientRpc] Calling EL", "method", engineMethod)
+	if err := cc.client.CallContext(ctx, &payloadStatus, engineMethod, args...); err != nil {
+		err = fmt.Errorf("execution Client RPC failed to retrieve the NewPayload status response, err: %w", err)
+		return PayloadStatusNone, err
+	}
+
+	if payloadStatus.Status == engine_types.AcceptedStatus {
+		log.Info("[ExecutionClientRpc] New block accepted")
+	}
+	return newPayloadStatusByEngineStatus(payloadStatus.Status), checkPayloadStatus(payloadStatus)
+}
+
+// newPayloadRequest is the engine API method and arguments the payload is sent to the execution layer with.
+func newPayloadRequest(
+	payload *cltypes.Eth1Block,
+	beaconParentRoot *libcommon.Hash,
+	versionedHashes []libcommon.Hash,
+	executionRequestsList []hexutility.Bytes,
+) (string, []interface{}, error) {
 	reversedBaseFeePerGas := libcommon.Copy(payload.BaseFeePerGas[:])
 	for i, j := 0, len(reversedBaseFeePerGas)-1; i < j; i, j = i+1, j-1 {
 		reversedBaseFeePerGas[i], reversedBaseFeePerGas[j] = reversedBaseFeePerGas[j], reversedBaseFeePerGas[i]
@@ -99,7 +124,7 @@ func (cc *ExecutionClientRpc) NewPayload(
 	case clparams.ElectraVersion:
 		engineMethod = rpc_helper.EngineNewPayloadV4
 	default:
-		return PayloadStatusNone, errors.New("invalid payload version")
+		return "", nil, errors.New("invalid payload version")
 	}
 
 	request := engine_types.ExecutionPayload{
@@ -134,8 +159,6 @@ func (cc *ExecutionClientRpc) NewPayload(
 		*request.ExcessBlobGas = hexutil.Uint64(payload.ExcessBlobGas)
 	}
 
-	payloadStatus := &engine_types.PayloadStatus{} // As it is done in the rpcdaemon
-	log.Debug("[ExecutionClientRpc] Calling EL", "method", engineMethod)
 	args := []interface{}{request}
 	if versionedHashes != nil {
 		args = append(args, versionedHashes, *beaconParentRoot)
@@ -143,15 +166,7 @@ func (cc *ExecutionClientRpc) NewPayload(
 	if executionRequestsList != nil {
 		args = append(args, executionRequestsList)
 	}
-	if err := cc.client.CallContext(ctx, &payloadStatus, engineMethod, args...); err != nil {
-		err = fmt.Errorf("execution Client RPC failed to retrieve the NewPayload status response, err: %w", err)
-		return PayloadStatusNone, err
-	}
-
-	if payloadStatus.Status == engine_types.AcceptedStatus {
-		log.Info("[ExecutionClientRpc] New block accepted")
-	}
-	return newPayloadStatusByEngineStatus(payloadStatus.Status), checkPayloadStatus(payloadStatus)
+	return engineMethod, args, nil
 }
 
 func (cc *ExecutionClientRpc) ForkChoiceUpdate(ctx context.Context, finalized libcommon.Hash, head libcommon.Hash, attributes *engine_types.PayloadAttributes) ([]byte, error) {
@@ -272,6 +287,21 @@ func (cc *ExecutionClientRpc) HasBlock(ctx context.Context, hash libcommon.Hash)
 	panic("unimplemented")
 }
 
+// HeaderNumber returns the number of the block with the given hash, or nil if the block is unknown
+func (cc *ExecutionClientRpc) HeaderNumber(ctx context.Context, hash libcommon.Hash) (*uint64, error) {
+	var header *struct {
+		Number hexutil.Uint64 `json:"number"`
+	}
+	if err := cc.client.CallContext(ctx, &header, rpc_helper.GetBlockByHash, hash, false); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, nil
+	}
+	number := uint64(header.Number)
+	return &number, nil
+}
+
 // Block production
 
 func (cc *ExecutionClientRpc) GetAssembledBlock(ctx context.Context, id []byte) (*cltypes.Eth1Block, *engine_types.BlobsBundleV1, *big.Int, error) {
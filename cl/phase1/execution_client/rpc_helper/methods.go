@@ -25,5 +25,11 @@ const ForkChoiceUpdatedV1 = "engine_forkchoiceUpdatedV1"
 const ForkChoiceUpdatedV2 = "engine_forkchoiceUpdatedV2"
 const ForkChoiceUpdatedV3 = "engine_forkchoiceUpdatedV3"
 
+const EngineGetPayloadV2 = "engine_getPayloadV2"
+const EngineGetPayloadV3 = "engine_getPayloadV3"
+const EngineGetPayloadV4 = "engine_getPayloadV4"
+
 const GetPayloadBodiesByHashV1 = "engine_getPayloadBodiesByHashV1"
 const GetPayloadBodiesByRangeV1 = "engine_getPayloadBodiesByRangeV1"
+
+const GetBlockByHash = "eth_getBlockByHash"
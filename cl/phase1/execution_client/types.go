@@ -38,3 +38,17 @@ func newPayloadStatusByEngineStatus(status engine_types.EngineStatus) PayloadSta
 	}
 	return PayloadStatusNone
 }
+
+// engineStatusByPayloadStatus is the engine API status standing for a payload status, the inverse of
+// newPayloadStatusByEngineStatus.
+func engineStatusByPayloadStatus(status PayloadStatus) engine_types.EngineStatus {
+	switch status {
+	case PayloadStatusNotValidated:
+		return engine_types.SyncingStatus
+	case PayloadStatusInvalidated:
+		return engine_types.InvalidStatus
+	case PayloadStatusValidated:
+		return engine_types.ValidStatus
+	}
+	return ""
+}
@@ -17,6 +17,7 @@
 package forkchoice
 
 import (
+	"github.com/erigontech/erigon/cl/beacon/beaconevents"
 	"github.com/erigontech/erigon/cl/cltypes"
 )
 
@@ -38,6 +39,11 @@ func (f *ForkChoiceStore) AddPreverifiedBlobSidecar(blobSidecar *cltypes.BlobSid
 		}
 	}
 	f.hotSidecars[blockRoot] = append(f.hotSidecars[blockRoot], blobSidecar)
+	blobSidecarEvent, err := beaconevents.NewBlobSidecarData(blobSidecar)
+	if err != nil {
+		return err
+	}
+	f.emitters.Operation().SendBlobSidecar(blobSidecarEvent)
 
 	blobsMaxAge := 4 // a slot can live for up to 4 slots in the pool of hot sidecars.
 	currentSlot := f.highestSeen.Load()
@@ -0,0 +1,101 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package forkchoice
+
+import (
+	"context"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/deposit_tree"
+)
+
+// InitDepositTree starts following the deposit contract from the given snapshot, which must cover at least the
+// deposits processed by the anchor state.
+func (f *ForkChoiceStore) InitDepositTree(snapshot *deposit_tree.Snapshot) error {
+	tree, err := deposit_tree.FromSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.depositTree = tree
+	return nil
+}
+
+// DepositTreeSnapshot returns the EIP-4881 snapshot of the finalized deposits, if the deposit tree is followed.
+func (f *ForkChoiceStore) DepositTreeSnapshot() (*deposit_tree.Snapshot, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.depositTree == nil {
+		return nil, false
+	}
+	return f.depositTree.Snapshot()
+}
+
+// addDepositsToTree appends the deposits of the block which are not in the tree yet. Deposits are the same on every
+// fork, so a deposit seen on any of them can be added. f.mu must be held.
+func (f *ForkChoiceStore) addDepositsToTree(block *cltypes.BeaconBlock, depositIndex uint64) {
+	if f.depositTree == nil || block.Body.Deposits.Len() == 0 {
+		return
+	}
+	// the state index is past the deposits of the block
+	index := depositIndex - uint64(block.Body.Deposits.Len())
+	block.Body.Deposits.Range(func(_ int, deposit *cltypes.Deposit, _ int) bool {
+		count := f.depositTree.DepositCount()
+		if index > count {
+			log.Warn("Deposit tree is missing deposits, no more deposit snapshots will be produced", "index", index, "count", count)
+			f.depositTree = nil
+			return false
+		}
+		if index == count {
+			leaf, err := deposit.Data.HashSSZ()
+			if err == nil {
+				err = f.depositTree.PushLeaf(leaf)
+			}
+			if err != nil {
+				log.Warn("Could not add deposit to the deposit tree", "index", index, "err", err)
+				f.depositTree = nil
+				return false
+			}
+		}
+		index++
+		return true
+	})
+}
+
+// finalizeDepositTree finalizes the deposits counted by the eth1 data of the finalized checkpoint. The snapshot
+// records the execution block of the eth1 data, so its number is asked to the execution engine. f.mu must be held.
+func (f *ForkChoiceStore) finalizeDepositTree(ctx context.Context) {
+	if f.depositTree == nil || f.engine == nil {
+		return
+	}
+	eth1Data, ok := f.eth1Datas.Get(f.finalizedCheckpoint.Load().(solid.Checkpoint).Root)
+	if !ok || eth1Data.DepositCount <= f.depositTree.FinalizedDepositCount() || eth1Data.DepositCount > f.depositTree.DepositCount() {
+		return
+	}
+	height, err := f.engine.HeaderNumber(ctx, eth1Data.BlockHash)
+	if err != nil || height == nil {
+		log.Debug("Could not find the execution block of the finalized eth1 data", "hash", eth1Data.BlockHash, "err", err)
+		return
+	}
+	if err := f.depositTree.Finalize(eth1Data, *height); err != nil {
+		log.Warn("Could not finalize the deposit tree, no more deposit snapshots will be produced", "err", err)
+		f.depositTree = nil
+	}
+}
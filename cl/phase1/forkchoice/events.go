@@ -0,0 +1,98 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package forkchoice
+
+import (
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/beacon/beaconevents"
+)
+
+// notifyHead emits the head event when the head changes, and the chain_reorg event if the previous head
+// is not an ancestor of the new one. f.mu must be held.
+func (f *ForkChoiceStore) notifyHead(headRoot libcommon.Hash, headSlot uint64) {
+	if headRoot == f.notifiedHeadHash {
+		return
+	}
+	oldHeadRoot, oldHeadSlot := f.notifiedHeadHash, f.notifiedHeadSlot
+	f.notifiedHeadHash, f.notifiedHeadSlot = headRoot, headSlot
+
+	header, ok := f.forkGraph.GetHeader(headRoot)
+	if !ok {
+		return
+	}
+	epoch := headSlot / f.beaconCfg.SlotsPerEpoch
+	previousEpoch := epoch
+	if previousEpoch > 0 {
+		previousEpoch--
+	}
+	executionOptimistic := f.optimisticStore.IsOptimistic(headRoot)
+	f.emitters.State().SendHead(&beaconevents.HeadData{
+		Slot:                      headSlot,
+		Block:                     headRoot,
+		State:                     header.Root,
+		EpochTransition:           headSlot%f.beaconCfg.SlotsPerEpoch == 0,
+		PreviousDutyDependentRoot: f.dependentRoot(headRoot, previousEpoch),
+		CurrentDutyDependentRoot:  f.dependentRoot(headRoot, epoch),
+		ExecutionOptimistic:       executionOptimistic,
+	})
+
+	if oldHeadRoot == (libcommon.Hash{}) || f.Ancestor(headRoot, oldHeadSlot) == oldHeadRoot {
+		return
+	}
+	oldHeader, ok := f.forkGraph.GetHeader(oldHeadRoot)
+	if !ok {
+		return
+	}
+	commonAncestorSlot, ok := f.commonAncestorSlot(oldHeadRoot, headRoot)
+	if !ok {
+		log.Debug("Common ancestor of reorged heads not found", "oldHead", oldHeadRoot, "newHead", headRoot)
+		return
+	}
+	f.emitters.State().SendChainReorg(&beaconevents.ChainReorgData{
+		Slot:                headSlot,
+		Depth:               oldHeadSlot - commonAncestorSlot,
+		OldHeadBlock:        oldHeadRoot,
+		NewHeadBlock:        headRoot,
+		OldHeadState:        oldHeader.Root,
+		NewHeadState:        header.Root,
+		Epoch:               epoch,
+		ExecutionOptimistic: executionOptimistic,
+	})
+}
+
+// dependentRoot returns the root of the last block before the start of the epoch, which the duties of the epoch depend on.
+func (f *ForkChoiceStore) dependentRoot(headRoot libcommon.Hash, epoch uint64) libcommon.Hash {
+	if epoch == 0 {
+		return f.Ancestor(headRoot, 0)
+	}
+	return f.Ancestor(headRoot, epoch*f.beaconCfg.SlotsPerEpoch-1)
+}
+
+// commonAncestorSlot walks back from a to the first block which is also an ancestor of b.
+func (f *ForkChoiceStore) commonAncestorSlot(a, b libcommon.Hash) (uint64, bool) {
+	for {
+		header, ok := f.forkGraph.GetHeader(a)
+		if !ok {
+			return 0, false
+		}
+		if f.Ancestor(b, header.Slot) == a {
+			return header.Slot, true
+		}
+		a = header.ParentRoot
+	}
+}
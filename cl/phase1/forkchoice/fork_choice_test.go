@@ -33,6 +33,7 @@ import (
 	"github.com/erigontech/erigon/cl/phase1/core/state"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice/fork_graph"
+	"github.com/erigontech/erigon/cl/phase1/forkchoice/harness"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice/public_keys_registry"
 	"github.com/erigontech/erigon/cl/pool"
 	"github.com/erigontech/erigon/cl/transition"
@@ -85,8 +86,11 @@ func TestForkChoiceBasic(t *testing.T) {
 	pool := pool.NewOperationsPool(&clparams.MainnetBeaconConfig)
 	emitters := beaconevents.NewEventEmitter()
 	validatorMonitor := monitor.NewValidatorMonitor(false, nil, nil, nil)
-	store, err := forkchoice.NewForkChoiceStore(nil, anchorState, nil, pool, fork_graph.NewForkGraphDisk(anchorState, afero.NewMemMapFs(), beacon_router_configuration.RouterConfiguration{}, emitters), emitters, sd, nil, validatorMonitor, public_keys_registry.NewInMemoryPublicKeysRegistry(), false)
+	store, err := forkchoice.NewForkChoiceStore(nil, anchorState, nil, pool, fork_graph.NewForkGraphDisk(anchorState, afero.NewMemMapFs(), beacon_router_configuration.RouterConfiguration{}, emitters, fork_graph.StateCachingPolicy{}), emitters, sd, nil, validatorMonitor, public_keys_registry.NewInMemoryPublicKeysRegistry(), false)
 	require.NoError(t, err)
+	events := make(chan *beaconevents.EventStream, 64)
+	sub := emitters.State().Subscribe(events)
+	defer sub.Unsubscribe()
 	// first steps
 	store.OnTick(0)
 	store.OnTick(12)
@@ -129,6 +133,21 @@ func TestForkChoiceBasic(t *testing.T) {
 	sd.OnHeadState(bs)
 
 	require.NoError(t, err)
+
+	// the head event is emitted once per new head
+	var heads []*beaconevents.HeadData
+	for len(events) > 0 {
+		if e := <-events; e.Event == beaconevents.StateHead {
+			heads = append(heads, e.Data.(*beaconevents.HeadData))
+		}
+	}
+	require.Len(t, heads, 2)
+	require.Equal(t, uint64(1), heads[0].Slot)
+	require.Equal(t, libcommon.HexToHash("0xc9bd7bcb6dfa49dc4e5a67ca75e89062c36b5c300bc25a1b31db4e1a89306071"), heads[0].Block)
+	require.Equal(t, uint64(3), heads[1].Slot)
+	require.Equal(t, headRoot, heads[1].Block)
+	require.False(t, heads[1].EpochTransition)
+	require.Equal(t, expectedCheckpoint.Root, heads[1].CurrentDutyDependentRoot)
 }
 
 func TestForkChoiceChainBellatrix(t *testing.T) {
@@ -151,7 +170,7 @@ func TestForkChoiceChainBellatrix(t *testing.T) {
 	sd := synced_data.NewSyncedDataManager(&clparams.MainnetBeaconConfig, true)
 	store, err := forkchoice.NewForkChoiceStore(nil, anchorState, nil, pool, fork_graph.NewForkGraphDisk(anchorState, afero.NewMemMapFs(), beacon_router_configuration.RouterConfiguration{
 		Beacon: true,
-	}, emitters), emitters, sd, nil, nil, public_keys_registry.NewInMemoryPublicKeysRegistry(), false)
+	}, emitters, fork_graph.StateCachingPolicy{}), emitters, sd, nil, nil, public_keys_registry.NewInMemoryPublicKeysRegistry(), false)
 	store.OnTick(2000)
 	require.NoError(t, err)
 	for _, block := range blocks {
@@ -181,3 +200,54 @@ func TestForkChoiceChainBellatrix(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, libcommon.Hash(bsRoot), libcommon.HexToHash("0x58a3f366bcefe6c30fb3a6506bed726f9a51bb272c77a8a3ed88c34435d44cb7"))
 }
+
+// same scenario as TestForkChoiceBasic, driven through the harness: the attestation for the late sibling outweighs the
+// proposer boost of the timely block.
+func TestForkChoiceHarnessProposerBoostReorg(t *testing.T) {
+	ctx := context.Background()
+	block0x3a, block0xc2, block0xd4 := cltypes.NewSignedBeaconBlock(&clparams.MainnetBeaconConfig, clparams.DenebVersion),
+		cltypes.NewSignedBeaconBlock(&clparams.MainnetBeaconConfig, clparams.DenebVersion),
+		cltypes.NewSignedBeaconBlock(&clparams.MainnetBeaconConfig, clparams.DenebVersion)
+	require.NoError(t, utils.DecodeSSZSnappy(block0x3a, block3aEncoded, int(clparams.AltairVersion)))
+	require.NoError(t, utils.DecodeSSZSnappy(block0xc2, blockc2Encoded, int(clparams.AltairVersion)))
+	require.NoError(t, utils.DecodeSSZSnappy(block0xd4, blockd4Encoded, int(clparams.AltairVersion)))
+	testAttestation := &solid.Attestation{}
+	require.NoError(t, utils.DecodeSSZSnappy(testAttestation, attestationEncoded, int(clparams.AltairVersion)))
+	anchorState := state.New(&clparams.MainnetBeaconConfig)
+	require.NoError(t, utils.DecodeSSZSnappy(anchorState, anchorStateEncoded, int(clparams.AltairVersion)))
+
+	h, err := harness.New(anchorState, harness.Options{})
+	require.NoError(t, err)
+	h.TickToSlot(1)
+	require.NoError(t, h.ApplyBlocks(ctx, block0x3a))
+	h.TickToSlot(3)
+	require.NoError(t, h.ApplyBlocks(ctx, block0xc2, block0xd4))
+
+	boosted := h.Store().ProposerBoostRoot()
+	c2Root, err := block0xc2.Block.HashSSZ()
+	require.NoError(t, err)
+	d4Root, err := block0xd4.Block.HashSSZ()
+	require.NoError(t, err)
+	require.Equal(t, libcommon.Hash(c2Root), boosted)
+	headRoot, headSlot, err := h.Head()
+	require.NoError(t, err)
+	require.Equal(t, libcommon.Hash(c2Root), headRoot)
+	require.Equal(t, uint64(3), headSlot)
+	boostedWeight, err := h.Weight(c2Root)
+	require.NoError(t, err)
+	siblingWeight, err := h.Weight(d4Root)
+	require.NoError(t, err)
+	require.Positive(t, boostedWeight)
+	require.Zero(t, siblingWeight)
+
+	require.NoError(t, h.ApplyAttestations(testAttestation))
+	headRoot, headSlot, err = h.Head()
+	require.NoError(t, err)
+	weights, err := h.Weights()
+	require.NoError(t, err)
+	require.Equal(t, libcommon.Hash(d4Root), testAttestation.Data.BeaconBlockRoot)
+	require.Equal(t, libcommon.Hash(d4Root), headRoot)
+	require.Equal(t, block0xd4.Block.Slot, headSlot)
+	require.Equal(t, boostedWeight, weights[c2Root])
+	require.Greater(t, weights[d4Root], weights[c2Root])
+}
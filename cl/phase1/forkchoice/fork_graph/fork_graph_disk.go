@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/golang/snappy"
 	"github.com/spf13/afero"
@@ -39,7 +40,21 @@ import (
 	"github.com/erigontech/erigon/cl/transition/impl/eth2"
 )
 
-const dumpSlotFrequency = 4
+const defaultDumpSlotFrequency = 4
+
+// StateCachingPolicy is how the fork graph keeps the states which it rebuilds the other ones from. A state is kept every
+// ColdSlotFrequency slots: the ones of the last HotEpochs epochs stay in memory, the older ones are read back from their
+// snappy compressed dumps on disk. The hot states take about HotEpochs*SLOTS_PER_EPOCH/ColdSlotFrequency states of memory,
+// while reorgs deeper than them replay up to ColdSlotFrequency-1 blocks on top of a decompressed cold state.
+type StateCachingPolicy struct {
+	HotEpochs         uint64 // 0 keeps only the head state in memory
+	ColdSlotFrequency uint64 // 0 means defaultDumpSlotFrequency
+}
+
+type hotState struct {
+	epoch uint64
+	state *state.CachingBeaconState
+}
 
 type syncCommittees struct {
 	currentSyncCommittee *solid.SyncCommittee
@@ -130,10 +145,15 @@ type forkGraphDisk struct {
 	emitter *beaconevents.EventEmitter
 
 	stateDumpLock sync.Mutex
+
+	dumpSlotFrequency uint64
+	hotEpochs         uint64
+	hotStates         map[libcommon.Hash]hotState
+	hotStatesLock     sync.Mutex
 }
 
 // Initialize fork graph with a new state
-func NewForkGraphDisk(anchorState *state.CachingBeaconState, aferoFs afero.Fs, rcfg beacon_router_configuration.RouterConfiguration, emitter *beaconevents.EventEmitter) ForkGraph {
+func NewForkGraphDisk(anchorState *state.CachingBeaconState, aferoFs afero.Fs, rcfg beacon_router_configuration.RouterConfiguration, emitter *beaconevents.EventEmitter, policy StateCachingPolicy) ForkGraph {
 	farthestExtendingPath := make(map[libcommon.Hash]bool)
 	anchorRoot, err := anchorState.BlockRoot()
 	if err != nil {
@@ -156,6 +176,13 @@ func NewForkGraphDisk(anchorState *state.CachingBeaconState, aferoFs afero.Fs, r
 		anchorSlot:  anchorState.Slot(),
 		rcfg:        rcfg,
 		emitter:     emitter,
+		// state caching
+		dumpSlotFrequency: policy.ColdSlotFrequency,
+		hotEpochs:         policy.HotEpochs,
+		hotStates:         make(map[libcommon.Hash]hotState),
+	}
+	if f.dumpSlotFrequency == 0 {
+		f.dumpSlotFrequency = defaultDumpSlotFrequency
 	}
 	f.lowestAvailableBlock.Store(anchorState.Slot())
 	f.headers.Store(libcommon.Hash(anchorRoot), &anchorHeader)
@@ -353,6 +380,7 @@ func (f *forkGraphDisk) getState(blockRoot libcommon.Hash, alwaysCopy bool, addC
 		}
 	}
 
+	start := time.Now()
 	// collect all blocks between greatest extending node path and block.
 	blocksInTheWay := []*cltypes.SignedBeaconBlock{}
 	// Use the parent root as a reverse iterator.
@@ -365,27 +393,39 @@ func (f *forkGraphDisk) getState(blockRoot libcommon.Hash, alwaysCopy bool, addC
 
 	// try and find the point of recconnection
 	for copyReferencedState == nil {
+		copyReferencedState, err = f.readHotState(currentIteratorRoot, outState)
+		if err != nil {
+			return nil, err
+		}
+		if copyReferencedState != nil {
+			hotStateHits.Inc()
+			break
+		}
 		block, isSegmentPresent := f.getBlock(currentIteratorRoot)
 		if !isSegmentPresent {
 			// check if it is in the header
 			bHeader, ok := f.GetHeader(currentIteratorRoot)
-			if ok && bHeader.Slot%dumpSlotFrequency == 0 {
+			if ok && bHeader.Slot%f.dumpSlotFrequency == 0 {
 				copyReferencedState, err = f.readBeaconStateFromDisk(currentIteratorRoot, outState)
 				if err != nil {
 					log.Trace("Could not retrieve state: Missing header", "missing", currentIteratorRoot, "err", err)
 					copyReferencedState = nil
+				} else {
+					coldStateHits.Inc()
 				}
 				continue
 			}
 			log.Trace("Could not retrieve state: Missing header", "missing", currentIteratorRoot)
+			stateMisses.Inc()
 			return nil, nil
 		}
-		if block.Block.Slot%dumpSlotFrequency == 0 {
+		if block.Block.Slot%f.dumpSlotFrequency == 0 {
 			copyReferencedState, err = f.readBeaconStateFromDisk(currentIteratorRoot, outState)
 			if err != nil {
 				log.Trace("Could not retrieve state: Missing header", "missing", currentIteratorRoot, "err", err)
 			}
 			if copyReferencedState != nil {
+				coldStateHits.Inc()
 				break
 			}
 		}
@@ -399,6 +439,8 @@ func (f *forkGraphDisk) getState(blockRoot libcommon.Hash, alwaysCopy bool, addC
 			return nil, err
 		}
 	}
+	stateRebuildBlocks.Set(float64(len(blocksInTheWay)))
+	stateRebuildTime.Set(float64(time.Since(start).Microseconds()) / 1000)
 	return copyReferencedState, nil
 }
 
@@ -463,6 +505,7 @@ func (f *forkGraphDisk) Prune(pruneSlot uint64) (err error) {
 		f.fs.Remove(getBeaconStateFilename(root))
 		f.fs.Remove(getBeaconStateCacheFilename(root))
 	}
+	f.pruneHotStates(oldRoots)
 	log.Debug("Pruned old blocks", "pruneSlot", pruneSlot)
 	return
 }
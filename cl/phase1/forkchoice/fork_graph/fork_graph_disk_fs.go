@@ -106,9 +106,12 @@ func (f *forkGraphDisk) readBeaconStateFromDisk(blockRoot libcommon.Hash, out *s
 	return
 }
 
-// dumpBeaconStateOnDisk dumps a beacon state on disk in ssz snappy format
+// dumpBeaconStateOnDisk dumps a beacon state on disk in ssz snappy format, and keeps a copy in memory if it is a recent one
 func (f *forkGraphDisk) DumpBeaconStateOnDisk(blockRoot libcommon.Hash, bs *state.CachingBeaconState, forced bool) (err error) {
-	if !forced && bs.Slot()%dumpSlotFrequency != 0 {
+	if !forced && bs.Slot()%f.dumpSlotFrequency != 0 {
+		return
+	}
+	if err = f.addHotState(blockRoot, bs); err != nil {
 		return
 	}
 	f.stateDumpLock.Lock()
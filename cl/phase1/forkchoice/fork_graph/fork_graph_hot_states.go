@@ -0,0 +1,90 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package fork_graph
+
+import (
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+)
+
+var (
+	// hotStateHits is the number of states rebuilt from a state in memory
+	hotStateHits = metrics.GetOrCreateCounter("caplin_fork_graph_hot_state_hits")
+	// coldStateHits is the number of states rebuilt from a state dumped on disk
+	coldStateHits = metrics.GetOrCreateCounter("caplin_fork_graph_cold_state_hits")
+	// stateMisses is the number of states which could not be rebuilt
+	stateMisses = metrics.GetOrCreateCounter("caplin_fork_graph_state_misses")
+	// hotStatesCount is the number of states kept in memory
+	hotStatesCount = metrics.GetOrCreateGauge("caplin_fork_graph_hot_states")
+	// stateRebuildTime is the time it took to rebuild the last state, in milliseconds
+	stateRebuildTime = metrics.GetOrCreateGauge("caplin_fork_graph_state_rebuild_time")
+	// stateRebuildBlocks is the number of blocks replayed to rebuild the last state
+	stateRebuildBlocks = metrics.GetOrCreateGauge("caplin_fork_graph_state_rebuild_blocks")
+)
+
+// readHotState copies the state of blockRoot into out, or into a new state if out is nil. It returns nil if the state
+// is not kept in memory.
+func (f *forkGraphDisk) readHotState(blockRoot libcommon.Hash, out *state.CachingBeaconState) (*state.CachingBeaconState, error) {
+	f.hotStatesLock.Lock()
+	defer f.hotStatesLock.Unlock()
+	hot, ok := f.hotStates[blockRoot]
+	if !ok {
+		return nil, nil
+	}
+	if out == nil {
+		return hot.state.Copy()
+	}
+	if err := hot.state.CopyInto(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// addHotState keeps a copy of bs in memory and evicts the states which are older than the hot epochs.
+func (f *forkGraphDisk) addHotState(blockRoot libcommon.Hash, bs *state.CachingBeaconState) error {
+	if f.hotEpochs == 0 {
+		return nil
+	}
+	epoch := state.Epoch(bs)
+	f.hotStatesLock.Lock()
+	defer f.hotStatesLock.Unlock()
+
+	for root, hot := range f.hotStates {
+		if hot.epoch+f.hotEpochs <= epoch {
+			delete(f.hotStates, root)
+		}
+	}
+	if _, ok := f.hotStates[blockRoot]; !ok {
+		copied, err := bs.Copy()
+		if err != nil {
+			return err
+		}
+		f.hotStates[blockRoot] = hotState{epoch: epoch, state: copied}
+	}
+	hotStatesCount.Set(float64(len(f.hotStates)))
+	return nil
+}
+
+func (f *forkGraphDisk) pruneHotStates(roots []libcommon.Hash) {
+	f.hotStatesLock.Lock()
+	defer f.hotStatesLock.Unlock()
+	for _, root := range roots {
+		delete(f.hotStates, root)
+	}
+	hotStatesCount.Set(float64(len(f.hotStates)))
+}
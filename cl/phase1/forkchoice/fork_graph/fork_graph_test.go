@@ -50,7 +50,7 @@ func TestForkGraphInDisk(t *testing.T) {
 	require.NoError(t, utils.DecodeSSZSnappy(blockC, block2, int(clparams.Phase0Version)))
 	require.NoError(t, utils.DecodeSSZSnappy(anchorState, anchor, int(clparams.Phase0Version)))
 	emitter := beaconevents.NewEventEmitter()
-	graph := NewForkGraphDisk(anchorState, afero.NewMemMapFs(), beacon_router_configuration.RouterConfiguration{}, emitter)
+	graph := NewForkGraphDisk(anchorState, afero.NewMemMapFs(), beacon_router_configuration.RouterConfiguration{}, emitter, StateCachingPolicy{})
 	_, status, err := graph.AddChainSegment(blockA, true, false)
 	require.NoError(t, err)
 	require.Equal(t, status, Success)
@@ -68,3 +68,40 @@ func TestForkGraphInDisk(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, status, PreValidated)
 }
+
+func TestForkGraphHotStates(t *testing.T) {
+	blockA := cltypes.NewSignedBeaconBlock(&clparams.MainnetBeaconConfig, clparams.DenebVersion)
+	anchorState := state.New(&clparams.MainnetBeaconConfig)
+	require.NoError(t, utils.DecodeSSZSnappy(blockA, block1, int(clparams.Phase0Version)))
+	require.NoError(t, utils.DecodeSSZSnappy(anchorState, anchor, int(clparams.Phase0Version)))
+	anchorRoot, err := anchorState.BlockRoot()
+	require.NoError(t, err)
+	anchorSlot := anchorState.Slot()
+
+	fs := afero.NewMemMapFs()
+	graph := NewForkGraphDisk(anchorState, fs, beacon_router_configuration.RouterConfiguration{}, beaconevents.NewEventEmitter(), StateCachingPolicy{HotEpochs: 1, ColdSlotFrequency: 1})
+	_, status, err := graph.AddChainSegment(blockA, true, false)
+	require.NoError(t, err)
+	require.Equal(t, Success, status)
+
+	// the anchor state is rebuilt from memory once its dump is gone
+	require.NoError(t, fs.Remove(getBeaconStateFilename(anchorRoot)))
+	require.NoError(t, fs.Remove(getBeaconStateCacheFilename(anchorRoot)))
+	hits := hotStateHits.GetValueUint64()
+	st, err := graph.GetState(anchorRoot, true)
+	require.NoError(t, err)
+	require.NotNil(t, st)
+	require.Equal(t, anchorSlot, st.Slot())
+	require.Equal(t, hits+1, hotStateHits.GetValueUint64())
+
+	// the copy in memory is not the one handed out
+	blockARoot, err := blockA.Block.HashSSZ()
+	require.NoError(t, err)
+	st, err = graph.GetState(blockARoot, true)
+	require.NoError(t, err)
+	require.NotNil(t, st)
+	require.Equal(t, blockA.Block.Slot, st.Slot())
+	st, err = graph.GetState(anchorRoot, true)
+	require.NoError(t, err)
+	require.Equal(t, anchorSlot, st.Slot())
+}
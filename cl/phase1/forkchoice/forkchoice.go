@@ -27,6 +27,7 @@ import (
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/deposit_tree"
 	"github.com/erigontech/erigon/cl/monitor"
 	"github.com/erigontech/erigon/cl/persistence/blob_storage"
 	"github.com/erigontech/erigon/cl/phase1/core/state"
@@ -87,9 +88,12 @@ type ForkChoiceStore struct {
 	unrealizedJustifiedCheckpoint atomic.Value
 	unrealizedFinalizedCheckpoint atomic.Value
 
-	proposerBoostRoot        atomic.Value
-	headHash                 libcommon.Hash
-	headSlot                 uint64
+	proposerBoostRoot atomic.Value
+	headHash          libcommon.Hash
+	headSlot          uint64
+	// last head announced to the event stream
+	notifiedHeadHash         libcommon.Hash
+	notifiedHeadSlot         uint64
 	genesisTime              uint64
 	genesisValidatorsRoot    libcommon.Hash
 	weights                  map[libcommon.Hash]uint64
@@ -121,6 +125,9 @@ type ForkChoiceStore struct {
 	randaoDeltas     *lru.Cache[libcommon.Hash, randaoDelta]       // small entry can be lots of elements.
 	// participation tracking
 	participation *lru.Cache[uint64, *solid.ParticipationBitList] // epoch -> [participation]
+	// deposit contract tree, only kept when bootstrapped from a deposit snapshot
+	depositTree *deposit_tree.DepositTree
+	eth1Datas   *lru.Cache[libcommon.Hash, *cltypes.Eth1Data]
 
 	mu sync.RWMutex
 
@@ -223,6 +230,12 @@ func NewForkChoiceStore(
 	if err != nil {
 		return nil, err
 	}
+
+	eth1Datas, err := lru.New[libcommon.Hash, *cltypes.Eth1Data](checkpointsPerCache)
+	if err != nil {
+		return nil, err
+	}
+	eth1Datas.Add(anchorRoot, anchorState.Eth1Data().Copy())
 	publicKeysRegistry.ResetAnchor(anchorState)
 	participation.Add(state.Epoch(anchorState.BeaconState), anchorState.CurrentEpochParticipation().Copy())
 
@@ -248,6 +261,7 @@ func NewForkChoiceStore(
 		headSet:                  headSet,
 		weights:                  make(map[libcommon.Hash]uint64),
 		participation:            participation,
+		eth1Datas:                eth1Datas,
 		emitters:                 emitters,
 		genesisTime:              anchorState.GenesisTime(),
 		syncedDataManager:        syncedDataManager,
@@ -480,6 +494,14 @@ func (f *ForkChoiceStore) ForkNodes() []ForkNode {
 	return forkNodes
 }
 
+// Weight returns the weight of a block as of the last head computation.
+func (f *ForkChoiceStore) Weight(blockRoot libcommon.Hash) (uint64, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	weight, ok := f.weights[blockRoot]
+	return weight, ok
+}
+
 func (f *ForkChoiceStore) Synced() bool {
 	return f.synced.Load()
 }
@@ -155,6 +155,7 @@ func (f *ForkChoiceStore) GetHead(auxilliaryState *state.CachingBeaconState) (li
 				return libcommon.Hash{}, 0, errors.New("no slot for head is stored")
 			}
 			f.headSlot = header.Slot
+			f.notifyHead(f.headHash, f.headSlot)
 			return f.headHash, f.headSlot, nil
 		}
 
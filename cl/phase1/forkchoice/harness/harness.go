@@ -0,0 +1,165 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package harness drives the production fork choice store without networking nor execution engine, so that the fork
+// choice spec tests and custom reorg scenarios can apply sequences of ticks, blocks and attestations to it and inspect
+// the head, the checkpoints and the weights of the forks after each of them.
+package harness
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/afero"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/beacon/beacon_router_configuration"
+	"github.com/erigontech/erigon/cl/beacon/beaconevents"
+	"github.com/erigontech/erigon/cl/beacon/synced_data"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/monitor"
+	"github.com/erigontech/erigon/cl/persistence/blob_storage"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+	"github.com/erigontech/erigon/cl/phase1/forkchoice"
+	"github.com/erigontech/erigon/cl/phase1/forkchoice/fork_graph"
+	"github.com/erigontech/erigon/cl/phase1/forkchoice/public_keys_registry"
+	"github.com/erigontech/erigon/cl/pool"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+)
+
+type Options struct {
+	// EthClock is optional, the store only needs it to validate gossiped objects
+	EthClock eth_clock.EthereumClock
+	// BlobStorage is optional, the data availability of the blocks is checked only if it is set
+	BlobStorage blob_storage.BlobStorage
+	// RouterConfiguration selects the data kept for the beacon API, none by default
+	RouterConfiguration beacon_router_configuration.RouterConfiguration
+}
+
+type Harness struct {
+	store       *forkchoice.ForkChoiceStore
+	emitters    *beaconevents.EventEmitter
+	syncedData  *synced_data.SyncedDataManager
+	genesisTime uint64
+	slotTime    uint64
+	checkDA     bool
+}
+
+// New creates a synced fork choice store anchored at anchorState, whose states are kept in memory.
+func New(anchorState *state.CachingBeaconState, opts Options) (*Harness, error) {
+	beaconCfg := anchorState.BeaconConfig()
+	emitters := beaconevents.NewEventEmitter()
+	syncedData := synced_data.NewSyncedDataManager(beaconCfg, true)
+	forkGraph := fork_graph.NewForkGraphDisk(anchorState, afero.NewMemMapFs(), opts.RouterConfiguration, emitters, fork_graph.StateCachingPolicy{})
+	store, err := forkchoice.NewForkChoiceStore(opts.EthClock, anchorState, nil, pool.NewOperationsPool(beaconCfg), forkGraph,
+		emitters, syncedData, opts.BlobStorage, monitor.NewValidatorMonitor(false, nil, nil, nil), public_keys_registry.NewInMemoryPublicKeysRegistry(), false)
+	if err != nil {
+		return nil, err
+	}
+	store.SetSynced(true)
+	return &Harness{
+		store:       store,
+		emitters:    emitters,
+		syncedData:  syncedData,
+		genesisTime: anchorState.GenesisTime(),
+		slotTime:    beaconCfg.SecondsPerSlot,
+		checkDA:     opts.BlobStorage != nil,
+	}, nil
+}
+
+// Store returns the fork choice store driven by the harness.
+func (h *Harness) Store() *forkchoice.ForkChoiceStore {
+	return h.store
+}
+
+func (h *Harness) Emitters() *beaconevents.EventEmitter {
+	return h.emitters
+}
+
+func (h *Harness) SyncedData() *synced_data.SyncedDataManager {
+	return h.syncedData
+}
+
+// Tick moves the store time, in seconds since the unix epoch like the spec tests ticks.
+func (h *Harness) Tick(time uint64) {
+	h.store.OnTick(time)
+}
+
+// TickToSlot moves the store time to the start of slot, the blocks of that slot applied next are timely and get the
+// proposer boost.
+func (h *Harness) TickToSlot(slot uint64) {
+	h.store.OnTick(h.genesisTime + slot*h.slotTime)
+}
+
+func (h *Harness) ApplyBlock(ctx context.Context, block *cltypes.SignedBeaconBlock) error {
+	return h.store.OnBlock(ctx, block, false, true, h.checkDA)
+}
+
+// ApplyBlocks applies the blocks in order and stops at the first one which is rejected.
+func (h *Harness) ApplyBlocks(ctx context.Context, blocks ...*cltypes.SignedBeaconBlock) error {
+	for i, block := range blocks {
+		if err := h.ApplyBlock(ctx, block); err != nil {
+			return fmt.Errorf("block %d, slot %d: %w", i, block.Block.Slot, err)
+		}
+	}
+	return nil
+}
+
+func (h *Harness) ApplyAttestation(attestation *solid.Attestation) error {
+	return h.store.OnAttestation(attestation, false, false)
+}
+
+// ApplyAttestations applies the attestations in order and stops at the first one which is rejected.
+func (h *Harness) ApplyAttestations(attestations ...*solid.Attestation) error {
+	for i, attestation := range attestations {
+		if err := h.ApplyAttestation(attestation); err != nil {
+			return fmt.Errorf("attestation %d, slot %d: %w", i, attestation.Data.Slot, err)
+		}
+	}
+	return nil
+}
+
+func (h *Harness) ApplyAttesterSlashing(attesterSlashing *cltypes.AttesterSlashing) error {
+	return h.store.OnAttesterSlashing(attesterSlashing, false)
+}
+
+// Head computes the head of the store.
+func (h *Harness) Head() (libcommon.Hash, uint64, error) {
+	return h.store.GetHead(nil)
+}
+
+// Weight returns the weight of a block, the votes of the blocks descending from it and the proposer boost included.
+func (h *Harness) Weight(blockRoot libcommon.Hash) (uint64, error) {
+	if _, _, err := h.store.GetHead(nil); err != nil {
+		return 0, err
+	}
+	weight, _ := h.store.Weight(blockRoot)
+	return weight, nil
+}
+
+// Weights returns the weights of all the blocks descending from the justified checkpoint.
+func (h *Harness) Weights() (map[libcommon.Hash]uint64, error) {
+	if _, _, err := h.store.GetHead(nil); err != nil {
+		return nil, err
+	}
+	nodes := h.store.ForkNodes()
+	weights := make(map[libcommon.Hash]uint64, len(nodes))
+	for _, node := range nodes {
+		weights[node.BlockRoot] = node.Weight
+	}
+	return weights, nil
+}
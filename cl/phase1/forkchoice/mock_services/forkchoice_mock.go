@@ -26,6 +26,7 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/deposit_tree"
 	"github.com/erigontech/erigon/cl/phase1/core/state"
 	"github.com/erigontech/erigon/cl/phase1/execution_client"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice"
@@ -64,6 +65,7 @@ type ForkChoiceStorageMock struct {
 	SyncContributionPool      sync_contribution_pool.SyncContributionPool
 	Headers                   map[common.Hash]*cltypes.BeaconBlockHeader
 	GetBeaconCommitteeMock    func(slot, committeeIndex uint64) ([]uint64, error)
+	DepositSnapshot           *deposit_tree.Snapshot
 
 	Pool pool.OperationsPool
 }
@@ -307,6 +309,10 @@ func (f *ForkChoiceStorageMock) GetHeader(
 	return f.Headers[blockRoot], f.Headers[blockRoot] != nil
 }
 
+func (f *ForkChoiceStorageMock) DepositTreeSnapshot() (*deposit_tree.Snapshot, bool) {
+	return f.DepositSnapshot, f.DepositSnapshot != nil
+}
+
 func (f *ForkChoiceStorageMock) GetBalances(blockRoot libcommon.Hash) (solid.Uint64ListSSZ, error) {
 	panic("implement me")
 }
@@ -350,6 +356,12 @@ func (f *ForkChoiceStorageMock) GetPublicKeyForValidator(
 func (f *ForkChoiceStorageMock) AddPreverifiedBlobSidecar(msg *cltypes.BlobSidecar) error {
 	return nil
 }
+
+func (f *ForkChoiceStorageMock) InitDepositTree(snapshot *deposit_tree.Snapshot) error {
+	f.DepositSnapshot = snapshot
+	return nil
+}
+
 func (f *ForkChoiceStorageMock) ValidateOnAttestation(attestation *solid.Attestation) error {
 	panic("implement me")
 }
@@ -299,6 +299,8 @@ func (f *ForkChoiceStore) OnBlock(ctx context.Context, block *cltypes.SignedBeac
 	})
 
 	f.totalActiveBalances.Add(blockRoot, lastProcessedState.GetTotalActiveBalance())
+	f.eth1Datas.Add(blockRoot, lastProcessedState.Eth1Data().Copy())
+	f.addDepositsToTree(block.Block, lastProcessedState.Eth1DepositIndex())
 	// Update checkpoints
 	f.updateCheckpoints(lastProcessedState.CurrentJustifiedCheckpoint(), lastProcessedState.FinalizedCheckpoint())
 	// First thing save previous values of the checkpoints (avoid memory copy of all states and ensure easy revert)
@@ -336,6 +338,7 @@ func (f *ForkChoiceStore) OnBlock(ctx context.Context, block *cltypes.SignedBeac
 	if blockEpoch < currentEpoch {
 		f.updateCheckpoints(lastProcessedState.CurrentJustifiedCheckpoint(), lastProcessedState.FinalizedCheckpoint())
 	}
+	f.finalizeDepositTree(ctx)
 	f.emitters.State().SendBlock(&beaconevents.BlockData{
 		Slot:                block.Block.Slot,
 		Block:               blockRoot,
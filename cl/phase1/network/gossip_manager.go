@@ -37,6 +37,7 @@ import (
 	"github.com/erigontech/erigon/cl/monitor"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice"
 	"github.com/erigontech/erigon/cl/phase1/network/services"
+	"github.com/erigontech/erigon/cl/sentinel/communication/ssz_snappy"
 	"github.com/erigontech/erigon/cl/utils/eth_clock"
 	"github.com/erigontech/erigon/cl/validator/committee_subscription"
 
@@ -66,6 +67,7 @@ type GossipManager struct {
 	blsToExecutionChangeService  services.BLSToExecutionChangeService
 	proposerSlashingService      services.ProposerSlashingService
 	attestationsLimiter          *timeBasedRateLimiter
+	encodingCache                *ssz_snappy.EncodingCache
 }
 
 func NewGossipReceiver(
@@ -85,6 +87,7 @@ func NewGossipReceiver(
 	voluntaryExitService services.VoluntaryExitService,
 	blsToExecutionChangeService services.BLSToExecutionChangeService,
 	proposerSlashingService services.ProposerSlashingService,
+	encodingCache *ssz_snappy.EncodingCache,
 ) *GossipManager {
 	return &GossipManager{
 		sentinel:                     s,
@@ -104,6 +107,7 @@ func NewGossipReceiver(
 		blsToExecutionChangeService:  blsToExecutionChangeService,
 		proposerSlashingService:      proposerSlashingService,
 		attestationsLimiter:          newTimeBasedRateLimiter(6*time.Second, 250),
+		encodingCache:                encodingCache,
 	}
 }
 
@@ -181,7 +185,16 @@ func (g *GossipManager) routeAndProcess(ctx context.Context, data *sentinel.Goss
 			return err
 		}
 		log.Debug("Received block via gossip", "slot", obj.Block.Slot)
-		return g.blockService.ProcessMessage(ctx, data.SubnetId, obj)
+		if err := g.blockService.ProcessMessage(ctx, data.SubnetId, obj); err != nil {
+			return err
+		}
+		// keep the received encoding to serve the block to the peers which request it
+		if g.encodingCache != nil {
+			if blockRoot, err := obj.Block.HashSSZ(); err == nil {
+				g.encodingCache.Add(blockRoot, version, data.Data)
+			}
+		}
+		return nil
 	case gossip.TopicNameSyncCommitteeContributionAndProof:
 		obj := &cltypes.SignedContributionAndProofWithGossipData{
 			GossipData:                 copyOfSentinelData(data),
@@ -284,11 +284,8 @@ func (s *attestationService) ProcessMessage(ctx context.Context, subnet *uint64,
 			start := time.Now()
 			defer monitor.ObserveAggregateAttestation(start)
 			err = s.committeeSubscribe.AggregateAttestation(attestation)
-			if errors.Is(err, aggregation.ErrIsSuperset) {
-				return
-			}
-
-			if err != nil {
+			// an attestation already covered by the aggregate is still a valid one, so it is announced anyway
+			if err != nil && !errors.Is(err, aggregation.ErrIsSuperset) {
 				log.Warn("could not check aggregate attestation", "err", err)
 				return
 			}
@@ -123,11 +123,8 @@ func (b *blobSidecarService) ProcessMessage(ctx context.Context, subnetId *uint6
 		return ErrInvalidSidecarSlot
 	}
 
-	if err := b.verifyAndStoreBlobSidecar(msg); err != nil {
-		return err
-	}
-	b.emitters.Operation().SendBlobSidecar(msg)
-	return nil
+	// the fork choice store emits the blob_sidecar event
+	return b.verifyAndStoreBlobSidecar(msg)
 }
 
 func (b *blobSidecarService) verifyAndStoreBlobSidecar(msg *cltypes.BlobSidecar) error {
@@ -22,8 +22,6 @@ func cleanupAndPruning(ctx context.Context, logger log.Logger, cfg *Cfg, args Ar
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-	return cfg.blobStore.Prune()
+	// blob sidecars are pruned in the background by blob_storage.RunPruner
+	return tx.Commit()
 }
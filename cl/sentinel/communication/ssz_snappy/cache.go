@@ -0,0 +1,78 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package ssz_snappy
+
+import (
+	"bytes"
+	"sync"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/phase1/core/state/lru"
+)
+
+type cachedEncoding struct {
+	version clparams.StateVersion
+
+	mu      sync.Mutex
+	ssz     []byte // the SSZ encoding as received, until it is first encoded
+	encoded []byte // the length of the packet and its snappy framed compression
+}
+
+// EncodingCache keeps the SSZ encodings of the objects received via gossip, keyed by their root, so that serving them
+// to peers does not re-serialize them. An encoding is compressed the first time it is served, and the compressed packet
+// is kept in place of it. A nil cache keeps nothing.
+type EncodingCache struct {
+	cache *lru.Cache[libcommon.Hash, *cachedEncoding]
+}
+
+func NewEncodingCache(size int) (*EncodingCache, error) {
+	cache, err := lru.New[libcommon.Hash, *cachedEncoding]("gossip_encodings", size)
+	if err != nil {
+		return nil, err
+	}
+	return &EncodingCache{cache: cache}, nil
+}
+
+// Add keeps the SSZ encoding of the object with the given root, the caller must not modify it afterwards.
+func (c *EncodingCache) Add(root libcommon.Hash, version clparams.StateVersion, ssz []byte) {
+	if c == nil {
+		return
+	}
+	c.cache.ContainsOrAdd(root, &cachedEncoding{version: version, ssz: ssz})
+}
+
+// Get returns the version and the packet of the object with the given root, to be written with WriteEncoded.
+func (c *EncodingCache) Get(root libcommon.Hash) (clparams.StateVersion, []byte, bool) {
+	if c == nil {
+		return 0, nil, false
+	}
+	e, ok := c.cache.Get(root)
+	if !ok {
+		return 0, nil, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.encoded == nil {
+		var buf bytes.Buffer
+		if err := writeSSZ(&buf, e.ssz); err != nil {
+			return 0, nil, false
+		}
+		e.encoded, e.ssz = buf.Bytes(), nil
+	}
+	return e.version, e.encoded, true
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package ssz_snappy_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/sentinel/communication/ssz_snappy"
+)
+
+func TestEncodingCache(t *testing.T) {
+	status := &cltypes.Status{ForkDigest: [4]byte{1, 2, 3, 4}, HeadSlot: 42, FinalizedEpoch: 1}
+	enc, err := status.EncodeSSZ(nil)
+	require.NoError(t, err)
+	root := libcommon.Hash{1}
+
+	var expected bytes.Buffer
+	require.NoError(t, ssz_snappy.EncodeAndWrite(&expected, status, 0))
+
+	c, err := ssz_snappy.NewEncodingCache(2)
+	require.NoError(t, err)
+	c.Add(root, clparams.DenebVersion, enc)
+	for i := 0; i < 2; i++ {
+		version, encoded, ok := c.Get(root)
+		require.True(t, ok)
+		require.Equal(t, clparams.DenebVersion, version)
+		var got bytes.Buffer
+		require.NoError(t, ssz_snappy.WriteEncoded(&got, encoded, 0))
+		require.Equal(t, expected.Bytes(), got.Bytes())
+
+		decoded := &cltypes.Status{}
+		require.NoError(t, ssz_snappy.DecodeAndReadNoForkDigest(bytes.NewReader(encoded), decoded, clparams.DenebVersion))
+		require.Equal(t, status, decoded)
+	}
+
+	_, _, ok := c.Get(libcommon.Hash{2})
+	require.False(t, ok)
+
+	var nilCache *ssz_snappy.EncodingCache
+	nilCache.Add(root, clparams.DenebVersion, enc)
+	_, _, ok = nilCache.Get(root)
+	require.False(t, ok)
+}
@@ -46,6 +46,11 @@ func EncodeAndWrite(w io.Writer, val ssz.Marshaler, prefix ...byte) error {
 	if err != nil {
 		return err
 	}
+	return writeSSZ(w, enc, prefix...)
+}
+
+// writeSSZ writes an SSZ encoding as the length of the packet followed by its snappy framed compression.
+func writeSSZ(w io.Writer, enc []byte, prefix ...byte) error {
 	// create prefix for length of packet
 	lengthBuf := make([]byte, 10)
 	vin := binary.PutUvarint(lengthBuf, uint64(len(enc)))
@@ -64,7 +69,18 @@ func EncodeAndWrite(w io.Writer, val ssz.Marshaler, prefix ...byte) error {
 		writerPool.Put(sw)
 	}()
 	// Marshall and snap it
-	_, err = sw.Write(enc)
+	_, err := sw.Write(enc)
+	return err
+}
+
+// WriteEncoded writes a packet encoded by an EncodingCache, as EncodeAndWrite would have written its object.
+func WriteEncoded(w io.Writer, encoded []byte, prefix ...byte) error {
+	if len(prefix) > 0 {
+		if _, err := w.Write(prefix); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(encoded)
 	return err
 }
 
@@ -33,6 +33,7 @@ import (
 
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/sentinel/communication/ssz_snappy"
 )
 
 type SentinelConfig struct {
@@ -57,8 +58,14 @@ type SentinelConfig struct {
 
 	EnableBlocks       bool
 	SubscribeAllTopics bool // Capture all topics
-	ActiveIndicies     uint64
-	MaxPeerCount       uint64
+	// AttestationSubnetBackbone is how many attestation subnets are subscribed to regardless of the validators duties
+	// (0 for the default), and AttestationSubnetRotationEpochs how often they're re-picked (0 for never).
+	AttestationSubnetBackbone       uint64
+	AttestationSubnetRotationEpochs uint64
+	ActiveIndicies                  uint64
+	MaxPeerCount                    uint64
+	// EncodingCache keeps the encodings of the blocks received via gossip, served to the peers
+	EncodingCache *ssz_snappy.EncodingCache
 }
 
 func convertToCryptoPrivkey(privkey *ecdsa.PrivateKey) (crypto.PrivKey, error) {
@@ -0,0 +1,214 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentinel
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/erigontech/erigon/cl/monitor"
+)
+
+// gossipMeshTracer keeps track of the gossipsub mesh and traffic of each topic, so that poor attestation
+// inclusion can be root-caused as a networking issue rather than a validator misconfiguration.
+type gossipMeshTracer struct {
+	mu            sync.Mutex
+	topics        map[string]*gossipTopicStats
+	iwantReceived uint64
+	iwantSent     uint64
+}
+
+type gossipTopicStats struct {
+	mesh          map[peer.ID]struct{}
+	delivered     uint64
+	duplicates    uint64
+	ihaveReceived uint64
+	ihaveSent     uint64
+	metrics       *monitor.GossipTopicMetrics
+}
+
+var _ pubsub.RawTracer = (*gossipMeshTracer)(nil)
+
+func newGossipMeshTracer() *gossipMeshTracer {
+	return &gossipMeshTracer{topics: make(map[string]*gossipTopicStats)}
+}
+
+// gossipTopicName extracts the topic name out of "/eth2/{fork_digest}/{name}/{encoding}".
+func gossipTopicName(topic string) string {
+	parts := strings.Split(topic, "/")
+	if len(parts) < 4 {
+		return topic
+	}
+	return parts[3]
+}
+
+// Join starts accounting a topic. Only the joined topics are accounted: the topics named by the peers in their
+// messages and control messages are arbitrary, accounting them would let any peer grow the stats and metrics.
+func (g *gossipMeshTracer) Join(topic string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.topics[topic]; !ok {
+		g.topics[topic] = &gossipTopicStats{
+			mesh:    make(map[peer.ID]struct{}),
+			metrics: monitor.NewGossipTopicMetrics(gossipTopicName(topic)),
+		}
+	}
+}
+
+func (g *gossipMeshTracer) Graft(p peer.ID, topic string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if stats, ok := g.topics[topic]; ok {
+		stats.mesh[p] = struct{}{}
+		stats.metrics.MeshPeers.SetInt(len(stats.mesh))
+	}
+}
+
+func (g *gossipMeshTracer) Prune(p peer.ID, topic string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if stats, ok := g.topics[topic]; ok {
+		delete(stats.mesh, p)
+		stats.metrics.MeshPeers.SetInt(len(stats.mesh))
+	}
+}
+
+func (g *gossipMeshTracer) RemovePeer(p peer.ID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, stats := range g.topics {
+		if _, ok := stats.mesh[p]; ok {
+			delete(stats.mesh, p)
+			stats.metrics.MeshPeers.SetInt(len(stats.mesh))
+		}
+	}
+}
+
+func (g *gossipMeshTracer) Leave(topic string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if stats, ok := g.topics[topic]; ok {
+		stats.metrics.MeshPeers.SetInt(0)
+		delete(g.topics, topic)
+	}
+}
+
+func (g *gossipMeshTracer) DeliverMessage(msg *pubsub.Message) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if stats, ok := g.topics[msg.GetTopic()]; ok {
+		stats.delivered++
+		stats.metrics.Delivered.Inc()
+	}
+}
+
+func (g *gossipMeshTracer) DuplicateMessage(msg *pubsub.Message) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if stats, ok := g.topics[msg.GetTopic()]; ok {
+		stats.duplicates++
+		stats.metrics.Duplicates.Inc()
+	}
+}
+
+func (g *gossipMeshTracer) RecvRPC(rpc *pubsub.RPC) {
+	g.observeControl(rpc, true)
+}
+
+func (g *gossipMeshTracer) SendRPC(rpc *pubsub.RPC, _ peer.ID) {
+	g.observeControl(rpc, false)
+}
+
+func (g *gossipMeshTracer) observeControl(rpc *pubsub.RPC, received bool) {
+	ctl := rpc.GetControl()
+	if ctl == nil {
+		return
+	}
+	var iwant int
+	for _, w := range ctl.GetIwant() {
+		iwant += len(w.GetMessageIDs())
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, h := range ctl.GetIhave() {
+		stats, ok := g.topics[h.GetTopicID()]
+		if !ok {
+			continue
+		}
+		n := uint64(len(h.GetMessageIDs()))
+		if received {
+			stats.ihaveReceived += n
+			stats.metrics.IHaveReceived.AddUint64(n)
+		} else {
+			stats.ihaveSent += n
+			stats.metrics.IHaveSent.AddUint64(n)
+		}
+	}
+	if received {
+		g.iwantReceived += uint64(iwant)
+		monitor.ObserveGossipIWant(iwant, 0)
+	} else {
+		g.iwantSent += uint64(iwant)
+		monitor.ObserveGossipIWant(0, iwant)
+	}
+}
+
+// MeshState returns a snapshot of the mesh of every joined topic, sorted by topic.
+func (g *gossipMeshTracer) MeshState() monitor.GossipMeshState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	state := monitor.GossipMeshState{
+		Topics:        make([]monitor.GossipTopicMeshState, 0, len(g.topics)),
+		IWantReceived: g.iwantReceived,
+		IWantSent:     g.iwantSent,
+	}
+	for topic, stats := range g.topics {
+		peers := make([]string, 0, len(stats.mesh))
+		for p := range stats.mesh {
+			peers = append(peers, p.String())
+		}
+		sort.Strings(peers)
+		var duplicateRate float64
+		if total := stats.delivered + stats.duplicates; total > 0 {
+			duplicateRate = float64(stats.duplicates) / float64(total)
+		}
+		state.Topics = append(state.Topics, monitor.GossipTopicMeshState{
+			Topic:         topic,
+			MeshPeers:     peers,
+			Delivered:     stats.delivered,
+			Duplicates:    stats.duplicates,
+			DuplicateRate: duplicateRate,
+			IHaveReceived: stats.ihaveReceived,
+			IHaveSent:     stats.ihaveSent,
+		})
+	}
+	sort.Slice(state.Topics, func(i, j int) bool { return state.Topics[i].Topic < state.Topics[j].Topic })
+	return state
+}
+
+func (g *gossipMeshTracer) AddPeer(peer.ID, protocol.ID)          {}
+func (g *gossipMeshTracer) ValidateMessage(*pubsub.Message)       {}
+func (g *gossipMeshTracer) RejectMessage(*pubsub.Message, string) {}
+func (g *gossipMeshTracer) ThrottlePeer(peer.ID)                  {}
+func (g *gossipMeshTracer) DropRPC(*pubsub.RPC, peer.ID)          {}
+func (g *gossipMeshTracer) UndeliverableMessage(*pubsub.Message)  {}
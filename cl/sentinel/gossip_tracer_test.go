@@ -0,0 +1,76 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentinel
+
+import (
+	"testing"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pubsubpb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGossipMeshTracer(t *testing.T) {
+	const topic = "/eth2/01020304/beacon_attestation_1/ssz_snappy"
+	tracer := newGossipMeshTracer()
+	tracer.Join(topic)
+
+	tracer.Graft(peer.ID("a"), topic)
+	tracer.Graft(peer.ID("b"), topic)
+	tracer.Prune(peer.ID("a"), topic)
+
+	topicStr := topic
+	msg := &pubsub.Message{Message: &pubsubpb.Message{Topic: &topicStr}}
+	tracer.DeliverMessage(msg)
+	tracer.DeliverMessage(msg)
+	tracer.DeliverMessage(msg)
+	tracer.DuplicateMessage(msg)
+
+	tracer.RecvRPC(&pubsub.RPC{RPC: pubsubpb.RPC{Control: &pubsubpb.ControlMessage{
+		Ihave: []*pubsubpb.ControlIHave{{TopicID: &topicStr, MessageIDs: []string{"1", "2"}}},
+		Iwant: []*pubsubpb.ControlIWant{{MessageIDs: []string{"3"}}},
+	}}})
+
+	state := tracer.MeshState()
+	require.Len(t, state.Topics, 1)
+	require.Equal(t, topic, state.Topics[0].Topic)
+	require.Equal(t, []string{peer.ID("b").String()}, state.Topics[0].MeshPeers)
+	require.Equal(t, uint64(3), state.Topics[0].Delivered)
+	require.Equal(t, uint64(1), state.Topics[0].Duplicates)
+	require.InDelta(t, 0.25, state.Topics[0].DuplicateRate, 1e-9)
+	require.Equal(t, uint64(2), state.Topics[0].IHaveReceived)
+	require.Equal(t, uint64(1), state.IWantReceived)
+
+	// a disconnected peer leaves every mesh
+	tracer.RemovePeer(peer.ID("b"))
+	require.Empty(t, tracer.MeshState().Topics[0].MeshPeers)
+
+	require.Equal(t, "beacon_attestation_1", gossipTopicName(topic))
+
+	// the topics we haven't joined are not accounted, whatever the peers send.
+	unjoined := "/eth2/01020304/made_up_topic/ssz_snappy"
+	tracer.Graft(peer.ID("c"), unjoined)
+	tracer.DeliverMessage(&pubsub.Message{Message: &pubsubpb.Message{Topic: &unjoined}})
+	tracer.RecvRPC(&pubsub.RPC{RPC: pubsubpb.RPC{Control: &pubsubpb.ControlMessage{
+		Ihave: []*pubsubpb.ControlIHave{{TopicID: &unjoined, MessageIDs: []string{"4"}}},
+	}}})
+	require.Len(t, tracer.MeshState().Topics, 1)
+
+	tracer.Leave(topic)
+	require.Empty(t, tracer.MeshState().Topics)
+}
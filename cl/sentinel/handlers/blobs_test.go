@@ -119,7 +119,7 @@ func TestBlobsByRangeHandler(t *testing.T) {
 		nil,
 		beaconCfg,
 		ethClock,
-		nil, &mock_services.ForkChoiceStorageMock{}, blobStorage, true,
+		nil, &mock_services.ForkChoiceStorageMock{}, blobStorage, nil, true,
 	)
 	c.Start()
 	req := &cltypes.BlobsByRangeRequest{
@@ -240,7 +240,7 @@ func TestBlobsByIdentifiersHandler(t *testing.T) {
 		nil,
 		beaconCfg,
 		ethClock,
-		nil, &mock_services.ForkChoiceStorageMock{}, blobStorage, true,
+		nil, &mock_services.ForkChoiceStorageMock{}, blobStorage, nil, true,
 	)
 	c.Start()
 	req := solid.NewStaticListSSZ[*cltypes.BlobIdentifier](40269, 40)
@@ -21,6 +21,7 @@ import (
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/persistence/beacon_indicies"
 	"github.com/erigontech/erigon/cl/sentinel/communication/ssz_snappy"
 	"github.com/erigontech/erigon/cl/utils"
 	"github.com/libp2p/go-libp2p/core/network"
@@ -45,6 +46,21 @@ func (c *ConsensusHandlers) beaconBlocksByRangeHandler(s network.Stream) error {
 
 	written := uint64(0)
 	for slot := req.StartSlot; slot < req.StartSlot+MaxRequestsBlocks; slot++ {
+		blockRoot, err := beacon_indicies.ReadCanonicalBlockRoot(tx, slot)
+		if err != nil {
+			return err
+		}
+		cached, err := c.writeCachedBlock(s, blockRoot)
+		if err != nil {
+			return err
+		}
+		if cached {
+			written++
+			if written >= req.Count {
+				break
+			}
+			continue
+		}
 		block, err := c.beaconDB.ReadBlockBySlot(c.ctx, tx, slot)
 		if err != nil {
 			return err
@@ -107,6 +123,10 @@ func (c *ConsensusHandlers) beaconBlocksByRootHandler(s network.Stream) error {
 
 	var block *cltypes.SignedBeaconBlock
 	req.Range(func(index int, blockRoot libcommon.Hash, length int) bool {
+		var cached bool
+		if cached, err = c.writeCachedBlock(s, blockRoot); err != nil || cached {
+			return err == nil
+		}
 		block, err = c.beaconDB.ReadBlockByRoot(c.ctx, tx, blockRoot)
 		if err != nil {
 			return false
@@ -139,6 +159,28 @@ func (c *ConsensusHandlers) beaconBlocksByRootHandler(s network.Stream) error {
 	return err
 }
 
+// writeCachedBlock writes the block from the encodings of the blocks received via gossip, if it is one of them.
+func (c *ConsensusHandlers) writeCachedBlock(s network.Stream, blockRoot libcommon.Hash) (bool, error) {
+	if blockRoot == (libcommon.Hash{}) {
+		return false, nil
+	}
+	version, encoded, ok := c.encodingCache.Get(blockRoot)
+	if !ok {
+		return false, nil
+	}
+	forkDigest, err := c.ethClock.ComputeForkDigestForVersion(utils.Uint32ToBytes4(c.beaconConfig.GetForkVersionByVersion(version)))
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.Write([]byte{0}); err != nil {
+		return false, err
+	}
+	if _, err := s.Write(forkDigest[:]); err != nil {
+		return false, err
+	}
+	return true, ssz_snappy.WriteEncoded(s, encoded)
+}
+
 type emptyString struct{}
 
 func (e *emptyString) EncodeSSZ(xs []byte) ([]byte, error) {
@@ -99,7 +99,7 @@ func TestPing(t *testing.T) {
 		testLocalNode(),
 		beaconCfg,
 		ethClock,
-		nil, f, nil, true,
+		nil, f, nil, nil, true,
 	)
 	c.Start()
 
@@ -153,7 +153,7 @@ func TestGoodbye(t *testing.T) {
 		testLocalNode(),
 		beaconCfg,
 		ethClock,
-		nil, f, nil, true,
+		nil, f, nil, nil, true,
 	)
 	c.Start()
 
@@ -214,7 +214,7 @@ func TestMetadataV2(t *testing.T) {
 		testLocalNode(),
 		beaconCfg,
 		ethClock,
-		nil, f, nil, true,
+		nil, f, nil, nil, true,
 	)
 	c.Start()
 
@@ -273,7 +273,7 @@ func TestMetadataV1(t *testing.T) {
 		testLocalNode(),
 		beaconCfg,
 		ethClock,
-		nil, f, nil, true,
+		nil, f, nil, nil, true,
 	)
 	c.Start()
 
@@ -338,7 +338,7 @@ func TestStatus(t *testing.T) {
 		testLocalNode(),
 		beaconCfg,
 		getEthClock(t),
-		hs, f, nil, true,
+		hs, f, nil, nil, true,
 	)
 	c.Start()
 
@@ -66,6 +66,7 @@ func (s *Sentinel) pubsubOptions() []pubsub.Option {
 		pubsub.WithValidateQueueSize(pubsubQueueSize),
 		pubsub.WithPeerScore(scoreParams, thresholds),
 		pubsub.WithGossipSubParams(pubsubGossipParam()),
+		pubsub.WithRawTracer(s.meshTracer),
 	}
 	return psOpts
 }
@@ -99,6 +99,7 @@ type Sentinel struct {
 
 	discoverConfig   discover.Config
 	pubsub           *pubsub.PubSub
+	meshTracer       *gossipMeshTracer
 	subManager       *GossipManager
 	metrics          bool
 	logger           log.Logger
@@ -184,7 +185,7 @@ func (s *Sentinel) createListener() (*discover.UDPv5, error) {
 	if err != nil {
 		return nil, err
 	}
-	handlers.NewConsensusHandlers(s.ctx, s.blockReader, s.indiciesDB, s.host, s.peers, s.cfg.NetworkConfig, localNode, s.cfg.BeaconConfig, s.ethClock, s.handshaker, s.forkChoiceReader, s.blobStorage, s.cfg.EnableBlocks).Start()
+	handlers.NewConsensusHandlers(s.ctx, s.blockReader, s.indiciesDB, s.host, s.peers, s.cfg.NetworkConfig, localNode, s.cfg.BeaconConfig, s.ethClock, s.handshaker, s.forkChoiceReader, s.blobStorage, s.cfg.EncodingCache, s.cfg.EnableBlocks).Start()
 
 	return net, err
 }
@@ -259,6 +260,7 @@ func New(
 	s.handshaker = handshake.New(ctx, s.ethClock, cfg.BeaconConfig, s.httpApi)
 
 	pubsub.TimeCacheDuration = 550 * gossipSubHeartbeatInterval
+	s.meshTracer = newGossipMeshTracer()
 	s.pubsub, err = pubsub.NewGossipSub(s.ctx, s.host, s.pubsubOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("[Sentinel] failed to subscribe to gossip err=%w", err)
@@ -267,6 +269,11 @@ func New(
 	return s, nil
 }
 
+// GossipMeshState returns a snapshot of the gossipsub mesh of every topic.
+func (s *Sentinel) GossipMeshState() monitor.GossipMeshState {
+	return s.meshTracer.MeshState()
+}
+
 func (s *Sentinel) observeBandwidth(ctx context.Context) {
 	ticker := time.NewTicker(200 * time.Millisecond)
 	for {
@@ -0,0 +1,103 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common/math"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/cl/gossip"
+	"github.com/erigontech/erigon/cl/monitor"
+	"github.com/erigontech/erigon/cl/sentinel"
+)
+
+// attestationBackbone keeps some attestation subnets subscribed to regardless of the committee duties of the
+// validators, which is the backbone the peers and aggregators rely on. They're picked at random and re-picked every
+// rotation period (never if 0), the previous ones stay subscribed for one more epoch so the backbone has no gaps.
+type attestationBackbone struct {
+	subs     []subnetSubscription
+	subnets  []uint64 // of subs
+	size     int
+	rotation time.Duration
+	overlap  time.Duration
+	current  map[int]struct{} // indexes in subs
+	logger   log.Logger
+}
+
+// subnetSubscription is the part of sentinel.GossipSubscription the backbone needs.
+type subnetSubscription interface {
+	OverwriteSubscriptionExpiry(expiry time.Time)
+}
+
+func newAttestationBackbone(topics []sentinel.GossipTopic, subs []subnetSubscription, size int, rotation, overlap time.Duration, logger log.Logger) *attestationBackbone {
+	b := &attestationBackbone{
+		subs:     subs,
+		subnets:  make([]uint64, len(subs)),
+		size:     min(size, len(subs)),
+		rotation: rotation,
+		overlap:  overlap,
+		current:  map[int]struct{}{},
+		logger:   logger,
+	}
+	for i, topic := range topics {
+		if _, err := fmt.Sscanf(topic.Name, gossip.TopicNamePrefixBeaconAttestation, &b.subnets[i]); err != nil {
+			logger.Warn("[Sentinel] unexpected attestation topic", "topic", topic.Name, "err", err)
+		}
+	}
+	return b
+}
+
+// pick subscribes to a new random backbone until the given time.
+func (b *attestationBackbone) pick(until time.Time) {
+	for i := range b.current {
+		monitor.ObserveAttestationSubnetBackbone(b.subnets[i], false)
+	}
+	clear(b.current)
+	picked := make([]uint64, 0, b.size)
+	for _, i := range rand.Perm(len(b.subs))[:b.size] {
+		b.subs[i].OverwriteSubscriptionExpiry(until)
+		b.current[i] = struct{}{}
+		monitor.ObserveAttestationSubnetBackbone(b.subnets[i], true)
+		picked = append(picked, b.subnets[i])
+	}
+	b.logger.Info("[Sentinel] Attestation subnets backbone", "subnets", picked, "until", until)
+}
+
+func (b *attestationBackbone) run(ctx context.Context) {
+	if b.rotation == 0 {
+		b.pick(time.Unix(0, math.MaxInt64)) // Listen forever.
+		return
+	}
+	b.pick(time.Now().Add(b.rotation + b.overlap))
+	go func() {
+		ticker := time.NewTicker(b.rotation)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.pick(time.Now().Add(b.rotation + b.overlap))
+			}
+		}
+	}()
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common/math"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+
+	"github.com/erigontech/erigon/cl/gossip"
+	"github.com/erigontech/erigon/cl/monitor"
+)
+
+type testSubnetSubscription struct {
+	mu     sync.Mutex
+	expiry time.Time
+	picks  int
+}
+
+func (s *testSubnetSubscription) OverwriteSubscriptionExpiry(expiry time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expiry.After(s.expiry) {
+		s.expiry = expiry
+	}
+	s.picks++
+}
+
+func (s *testSubnetSubscription) state() (time.Time, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expiry, s.picks
+}
+
+func newTestAttestationBackbone(subnets, size int, rotation time.Duration) (*attestationBackbone, []*testSubnetSubscription) {
+	topics := generateSubnetsTopics(gossip.TopicNamePrefixBeaconAttestation, subnets)
+	testSubs := make([]*testSubnetSubscription, subnets)
+	subs := make([]subnetSubscription, subnets)
+	for i := range testSubs {
+		testSubs[i] = &testSubnetSubscription{}
+		subs[i] = testSubs[i]
+	}
+	return newAttestationBackbone(topics, subs, size, rotation, rotation/2, log.New()), testSubs
+}
+
+func totalPicks(subs []*testSubnetSubscription) (total int) {
+	for _, sub := range subs {
+		_, picks := sub.state()
+		total += picks
+	}
+	return total
+}
+
+func TestAttestationBackboneSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		subnets int
+		size    int
+		want    int
+	}{
+		{"default", 64, AttestationSubnetSubscriptions, AttestationSubnetSubscriptions},
+		{"custom", 64, 8, 8},
+		{"more than the subnets", 4, 8, 4},
+		{"none", 64, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, subs := newTestAttestationBackbone(tt.subnets, tt.size, 0)
+			b.run(context.Background())
+			require.Len(t, b.current, tt.want)
+			forever := time.Unix(0, math.MaxInt64)
+			for i, sub := range subs {
+				expiry, _ := sub.state()
+				if _, ok := b.current[i]; ok {
+					require.Equal(t, forever, expiry)
+				} else {
+					require.True(t, expiry.IsZero())
+				}
+			}
+		})
+	}
+}
+
+func TestAttestationBackboneSubnets(t *testing.T) {
+	b, _ := newTestAttestationBackbone(64, 2, 0)
+	seen := map[uint64]struct{}{}
+	for _, subnet := range b.subnets {
+		seen[subnet] = struct{}{}
+	}
+	// the topics are shuffled, the subnets are parsed back from their names
+	require.Len(t, seen, 64)
+	for subnet := range seen {
+		require.Less(t, subnet, uint64(64))
+	}
+}
+
+func TestAttestationBackboneRotation(t *testing.T) {
+	const size = 2
+	rotation := 20 * time.Millisecond
+	b, subs := newTestAttestationBackbone(64, size, rotation)
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	b.run(ctx)
+	require.Equal(t, size, totalPicks(subs))
+	for _, sub := range subs {
+		// the picked subnets stay subscribed for the rotation period and the overlap
+		if expiry, picks := sub.state(); picks > 0 {
+			require.False(t, expiry.Before(start.Add(rotation+rotation/2)))
+		}
+	}
+
+	require.Eventually(t, func() bool { return totalPicks(subs) >= 3*size }, 5*time.Second, rotation/4)
+
+	// no more rotations once the sentinel is stopped
+	cancel()
+	time.Sleep(3 * rotation)
+	stopped := totalPicks(subs)
+	require.Zero(t, stopped%size)
+	time.Sleep(3 * rotation)
+	require.Equal(t, stopped, totalPicks(subs))
+}
+
+func TestAttestationBackboneMetric(t *testing.T) {
+	b, _ := newTestAttestationBackbone(64, 4, 0)
+	// the gauges are global, clear what the other tests left behind
+	for _, subnet := range b.subnets {
+		monitor.ObserveAttestationSubnetBackbone(subnet, false)
+	}
+	backbone := func() map[uint64]struct{} {
+		subnets := map[uint64]struct{}{}
+		for _, subnet := range b.subnets {
+			gauge := metrics.GetOrCreateGauge(fmt.Sprintf(`attestation_subnet_backbone{subnet="%d"}`, subnet))
+			if gauge.GetValueUint64() == 1 {
+				subnets[subnet] = struct{}{}
+			}
+		}
+		return subnets
+	}
+	current := func() map[uint64]struct{} {
+		subnets := map[uint64]struct{}{}
+		for i := range b.current {
+			subnets[b.subnets[i]] = struct{}{}
+		}
+		return subnets
+	}
+	for range 5 {
+		// the subnets left by a rotation are reset, only the current ones are marked
+		b.pick(time.Now().Add(time.Minute))
+		require.Len(t, b.current, 4)
+		require.Equal(t, current(), backbone())
+	}
+}
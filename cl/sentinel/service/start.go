@@ -35,6 +35,7 @@ import (
 
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/gossip"
+	"github.com/erigontech/erigon/cl/monitor"
 	"github.com/erigontech/erigon/cl/persistence/blob_storage"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice"
 	"github.com/erigontech/erigon/cl/sentinel"
@@ -42,6 +43,7 @@ import (
 	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 )
 
+// AttestationSubnetSubscriptions is the default size of the attestation subnets backbone (SUBNETS_PER_NODE).
 const AttestationSubnetSubscriptions = 2
 
 type ServerConfig struct {
@@ -81,6 +83,7 @@ func getExpirationForTopic(topic string, subscribeAll bool) time.Time {
 }
 
 func createSentinel(
+	ctx context.Context,
 	cfg *sentinel.SentinelConfig,
 	blockReader freezeblocks.BeaconSnapshotReader,
 	blobStorage blob_storage.BlobStorage,
@@ -89,7 +92,7 @@ func createSentinel(
 	ethClock eth_clock.EthereumClock,
 	logger log.Logger) (*sentinel.Sentinel, error) {
 	sent, err := sentinel.New(
-		context.Background(),
+		ctx,
 		cfg,
 		ethClock,
 		blockReader,
@@ -127,10 +130,6 @@ func createSentinel(
 		int(cfg.NetworkConfig.AttestationSubnetCount),
 	)
 
-	gossipTopics = append(
-		gossipTopics,
-		attestationSubnetTopics[AttestationSubnetSubscriptions:]...)
-
 	gossipTopics = append(
 		gossipTopics,
 		generateSubnetsTopics(
@@ -151,15 +150,35 @@ func createSentinel(
 		}
 	}
 
-	for k := 0; k < AttestationSubnetSubscriptions; k++ {
-		if err := sent.Unsubscribe(attestationSubnetTopics[k]); err != nil {
+	attestationSubnetSubs := make([]subnetSubscription, 0, len(attestationSubnetTopics))
+	subscribedAttestationTopics := make([]sentinel.GossipTopic, 0, len(attestationSubnetTopics))
+	for _, v := range attestationSubnetTopics {
+		if err := sent.Unsubscribe(v); err != nil {
 			logger.Error("[Sentinel] failed to start sentinel", "err", err)
 			continue
 		}
-		_, err := sent.SubscribeGossip(attestationSubnetTopics[k], time.Unix(0, math.MaxInt64)) // Listen forever.
+		sub, err := sent.SubscribeGossip(v, getExpirationForTopic(v.Name, cfg.SubscribeAllTopics))
 		if err != nil {
 			logger.Error("[Sentinel] failed to start sentinel", "err", err)
+			continue
+		}
+		subscribedAttestationTopics = append(subscribedAttestationTopics, v)
+		attestationSubnetSubs = append(attestationSubnetSubs, sub)
+	}
+	if !cfg.SubscribeAllTopics {
+		backboneSize := int(cfg.AttestationSubnetBackbone)
+		if backboneSize == 0 {
+			backboneSize = AttestationSubnetSubscriptions
 		}
+		epoch := time.Duration(cfg.BeaconConfig.SecondsPerSlot*cfg.BeaconConfig.SlotsPerEpoch) * time.Second
+		newAttestationBackbone(
+			subscribedAttestationTopics,
+			attestationSubnetSubs,
+			backboneSize,
+			time.Duration(cfg.AttestationSubnetRotationEpochs)*epoch,
+			epoch,
+			logger,
+		).run(ctx)
 	}
 	return sent, nil
 }
@@ -175,6 +194,7 @@ func StartSentinelService(
 	logger log.Logger) (sentinelrpc.SentinelClient, error) {
 	ctx := context.Background()
 	sent, err := createSentinel(
+		ctx,
 		cfg,
 		blockReader,
 		blobStorage,
@@ -191,6 +211,7 @@ func StartSentinelService(
 	if srvCfg.InitialStatus != nil {
 		sent.SetStatus(srvCfg.InitialStatus)
 	}
+	monitor.SetGossipMeshStateProvider(sent.GossipMeshState)
 	server := NewSentinelServer(ctx, sent, logger)
 	go StartServe(server, srvCfg, srvCfg.Creds)
 
@@ -29,19 +29,12 @@ import (
 	"github.com/spf13/afero"
 
 	"github.com/erigontech/erigon/cl/abstract"
-	"github.com/erigontech/erigon/cl/beacon/beacon_router_configuration"
-	"github.com/erigontech/erigon/cl/beacon/beaconevents"
-	"github.com/erigontech/erigon/cl/beacon/synced_data"
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/clparams/initial_state"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
-	"github.com/erigontech/erigon/cl/monitor"
 	"github.com/erigontech/erigon/cl/persistence/blob_storage"
-	"github.com/erigontech/erigon/cl/phase1/forkchoice"
-	"github.com/erigontech/erigon/cl/phase1/forkchoice/fork_graph"
-	"github.com/erigontech/erigon/cl/phase1/forkchoice/public_keys_registry"
+	"github.com/erigontech/erigon/cl/phase1/forkchoice/harness"
 	"github.com/erigontech/erigon/cl/phase1/network/services"
-	"github.com/erigontech/erigon/cl/pool"
 	"github.com/erigontech/erigon/cl/utils/eth_clock"
 
 	"github.com/stretchr/testify/assert"
@@ -201,18 +194,13 @@ func (b *ForkChoice) Run(t *testing.T, root fs.FS, c spectest.TestCase) (err err
 	genesisState, err := initial_state.GetGenesisState(clparams.MainnetNetwork)
 	require.NoError(t, err)
 
-	emitters := beaconevents.NewEventEmitter()
 	_, beaconConfig := clparams.GetConfigsByNetwork(clparams.MainnetNetwork)
 	ethClock := eth_clock.NewEthereumClock(genesisState.GenesisTime(), genesisState.GenesisValidatorsRoot(), beaconConfig)
 	blobStorage := blob_storage.NewBlobStore(memdb.New("/tmp", kv.ChainDB), afero.NewMemMapFs(), math.MaxUint64, &clparams.MainnetBeaconConfig, ethClock)
 
-	validatorMonitor := monitor.NewValidatorMonitor(false, nil, nil, nil)
-	forkStore, err := forkchoice.NewForkChoiceStore(
-		ethClock, anchorState, nil, pool.NewOperationsPool(&clparams.MainnetBeaconConfig),
-		fork_graph.NewForkGraphDisk(anchorState, afero.NewMemMapFs(), beacon_router_configuration.RouterConfiguration{}, emitters),
-		emitters, synced_data.NewSyncedDataManager(&clparams.MainnetBeaconConfig, true), blobStorage, validatorMonitor, public_keys_registry.NewInMemoryPublicKeysRegistry(), false)
+	h, err := harness.New(anchorState, harness.Options{EthClock: ethClock, BlobStorage: blobStorage})
 	require.NoError(t, err)
-	forkStore.SetSynced(true)
+	forkStore := h.Store()
 
 	var steps []ForkChoiceStep
 	err = spectest.ReadYml(root, "steps.yaml", &steps)
@@ -228,7 +216,7 @@ func (b *ForkChoice) Run(t *testing.T, root fs.FS, c spectest.TestCase) (err err
 			data := &cltypes.AttesterSlashing{}
 			err := spectest.ReadSsz(root, c.Version(), step.GetAttesterSlashing()+".ssz_snappy", data)
 			require.NoError(t, err, stepstr)
-			err = forkStore.OnAttesterSlashing(data, false)
+			err = h.ApplyAttesterSlashing(data)
 			if step.GetValid() {
 				require.NoError(t, err, stepstr)
 			} else {
@@ -251,7 +239,7 @@ func (b *ForkChoice) Run(t *testing.T, root fs.FS, c spectest.TestCase) (err err
 						continue
 					}
 				}
-				blobSidecarService := services.NewBlobSidecarService(ctx, &clparams.MainnetBeaconConfig, forkStore, nil, ethClock, emitters, true)
+				blobSidecarService := services.NewBlobSidecarService(ctx, &clparams.MainnetBeaconConfig, forkStore, nil, ethClock, h.Emitters(), true)
 
 				blobs.Range(func(index int, value *cltypes.Blob, length int) bool {
 					var proof libcommon.Bytes48
@@ -270,7 +258,7 @@ func (b *ForkChoice) Run(t *testing.T, root fs.FS, c spectest.TestCase) (err err
 
 			}
 
-			err = forkStore.OnBlock(ctx, blk, true, true, true)
+			err = h.ApplyBlock(ctx, blk)
 			if step.GetValid() {
 				require.NoError(t, err, stepstr)
 			} else {
@@ -280,14 +268,14 @@ func (b *ForkChoice) Run(t *testing.T, root fs.FS, c spectest.TestCase) (err err
 			att := &solid.Attestation{}
 			err := spectest.ReadSsz(root, c.Version(), step.GetAttestation()+".ssz_snappy", att)
 			require.NoError(t, err, stepstr)
-			err = forkStore.OnAttestation(att, false, false)
+			err = h.ApplyAttestation(att)
 			if step.GetValid() {
 				require.NoError(t, err, stepstr)
 			} else {
 				require.Error(t, err, stepstr)
 			}
 		case "on_tick":
-			forkStore.OnTick(uint64(step.GetTick()))
+			h.Tick(uint64(step.GetTick()))
 			//TODO: onTick needs to be able to return error
 		//	if step.GetValid() {
 		//		require.NoError(t, err)
@@ -296,7 +284,7 @@ func (b *ForkChoice) Run(t *testing.T, root fs.FS, c spectest.TestCase) (err err
 		//	}
 		case "checks":
 			chk := step.GetChecks()
-			doCheck(t, stepstr, forkStore, chk)
+			doCheck(t, stepstr, h, chk)
 		default:
 		}
 	}
@@ -304,9 +292,10 @@ func (b *ForkChoice) Run(t *testing.T, root fs.FS, c spectest.TestCase) (err err
 	return nil
 }
 
-func doCheck(t *testing.T, stepstr string, store *forkchoice.ForkChoiceStore, e *ForkChoiceChecks) {
+func doCheck(t *testing.T, stepstr string, h *harness.Harness, e *ForkChoiceChecks) {
+	store := h.Store()
 	if e.Head != nil {
-		root, v, err := store.GetHead(nil)
+		root, v, err := h.Head()
 		require.NoError(t, err, stepstr)
 		if e.Head.Root != nil {
 			assert.EqualValues(t, *e.Head.Root, root, stepstr)
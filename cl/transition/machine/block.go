@@ -334,9 +334,14 @@ func processBlsToExecutionChanges(impl BlockOperationProcessor, s abstract.Beaco
 }
 
 func maximumDeposits(s abstract.BeaconState) (maxDeposits uint64) {
-	maxDeposits = s.Eth1Data().DepositCount - s.Eth1DepositIndex()
-	if maxDeposits > s.BeaconConfig().MaxDeposits {
-		maxDeposits = s.BeaconConfig().MaxDeposits
+	eth1DepositIndexLimit := s.Eth1Data().DepositCount
+	// From Electra on, the former deposit mechanism is disabled once all the deposits prior to the first
+	// deposit request are processed (EIP-6110).
+	if s.Version() >= clparams.ElectraVersion {
+		eth1DepositIndexLimit = min(eth1DepositIndexLimit, s.GetDepositRequestsStartIndex())
 	}
-	return
+	if s.Eth1DepositIndex() >= eth1DepositIndexLimit {
+		return 0
+	}
+	return min(eth1DepositIndexLimit-s.Eth1DepositIndex(), s.BeaconConfig().MaxDeposits)
 }
@@ -0,0 +1,194 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package slashing_protection
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+const interchangeFormatVersion = "5"
+
+type interchangeMetadata struct {
+	InterchangeFormatVersion string         `json:"interchange_format_version"`
+	GenesisValidatorsRoot    libcommon.Hash `json:"genesis_validators_root"`
+}
+
+type interchangeBlock struct {
+	Slot        uint64          `json:"slot,string"`
+	SigningRoot *libcommon.Hash `json:"signing_root,omitempty"`
+}
+
+type interchangeAttestation struct {
+	SourceEpoch uint64          `json:"source_epoch,string"`
+	TargetEpoch uint64          `json:"target_epoch,string"`
+	SigningRoot *libcommon.Hash `json:"signing_root,omitempty"`
+}
+
+type interchangeValidator struct {
+	Pubkey             libcommon.Bytes48        `json:"pubkey"`
+	SignedBlocks       []interchangeBlock       `json:"signed_blocks"`
+	SignedAttestations []interchangeAttestation `json:"signed_attestations"`
+}
+
+// interchange is the EIP-3076 slashing protection interchange format.
+type interchange struct {
+	Metadata interchangeMetadata    `json:"metadata"`
+	Data     []interchangeValidator `json:"data"`
+}
+
+func signingRootOrZero(signingRoot *libcommon.Hash) libcommon.Hash {
+	if signingRoot == nil {
+		return libcommon.Hash{}
+	}
+	return *signingRoot
+}
+
+// Import merges a signing history in the interchange format into the database. Conflicting records for the
+// same slot or target epoch are kept with an unknown signing root, so that neither can be signed again.
+func Import(tx kv.RwTx, r io.Reader) error {
+	var in interchange
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return fmt.Errorf("slashing protection interchange: %w", err)
+	}
+	if in.Metadata.InterchangeFormatVersion != interchangeFormatVersion {
+		return fmt.Errorf("slashing protection interchange: unsupported format version %q", in.Metadata.InterchangeFormatVersion)
+	}
+	if err := CheckGenesisValidatorsRoot(tx, in.Metadata.GenesisValidatorsRoot); err != nil {
+		return err
+	}
+	for _, validator := range in.Data {
+		for _, block := range validator.SignedBlocks {
+			key := recordKey(validator.Pubkey, block.Slot)
+			signingRoot := signingRootOrZero(block.SigningRoot)
+			previous, err := tx.GetOne(kv.SlashingProtectionBlocks, key)
+			if err != nil {
+				return err
+			}
+			if len(previous) > 0 && !bytes.Equal(previous, signingRoot[:]) {
+				signingRoot = libcommon.Hash{}
+			}
+			if err := tx.Put(kv.SlashingProtectionBlocks, key, signingRoot[:]); err != nil {
+				return err
+			}
+		}
+		for _, attestation := range validator.SignedAttestations {
+			if attestation.SourceEpoch > attestation.TargetEpoch {
+				return fmt.Errorf("slashing protection interchange: %s: source epoch %d is after target epoch %d", validator.Pubkey, attestation.SourceEpoch, attestation.TargetEpoch)
+			}
+			key := recordKey(validator.Pubkey, attestation.TargetEpoch)
+			value := attestationValue(attestation.SourceEpoch, signingRootOrZero(attestation.SigningRoot))
+			previous, err := tx.GetOne(kv.SlashingProtectionAttestations, key)
+			if err != nil {
+				return err
+			}
+			if len(previous) > 0 && !bytes.Equal(previous, value) {
+				value = attestationValue(binary.BigEndian.Uint64(previous), libcommon.Hash{})
+			}
+			if err := tx.Put(kv.SlashingProtectionAttestations, key, value); err != nil {
+				return err
+			}
+			lowestSource, ok, err := readLowestSourceEpoch(tx, validator.Pubkey)
+			if err != nil {
+				return err
+			}
+			if !ok || attestation.SourceEpoch < lowestSource {
+				if err := writeLowestSourceEpoch(tx, validator.Pubkey, attestation.SourceEpoch); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Export writes the whole signing history in the interchange format.
+func Export(tx kv.Tx, w io.Writer) error {
+	genesisValidatorsRoot, err := tx.GetOne(kv.SlashingProtectionMetadata, kv.SlashingProtectionGenesisValidatorsRootKey)
+	if err != nil {
+		return err
+	}
+	if len(genesisValidatorsRoot) == 0 {
+		return errors.New("slashing protection: no signing history")
+	}
+
+	validators := make(map[libcommon.Bytes48]*interchangeValidator)
+	validator := func(k []byte) *interchangeValidator {
+		pubKey := libcommon.Bytes48(k[:length.Bytes48])
+		v, ok := validators[pubKey]
+		if !ok {
+			v = &interchangeValidator{
+				Pubkey:             pubKey,
+				SignedBlocks:       []interchangeBlock{},
+				SignedAttestations: []interchangeAttestation{},
+			}
+			validators[pubKey] = v
+		}
+		return v
+	}
+	if err := tx.ForEach(kv.SlashingProtectionBlocks, nil, func(k, v []byte) error {
+		block := interchangeBlock{Slot: binary.BigEndian.Uint64(k[length.Bytes48:])}
+		if signingRoot := libcommon.BytesToHash(v); signingRoot != (libcommon.Hash{}) {
+			block.SigningRoot = &signingRoot
+		}
+		entry := validator(k)
+		entry.SignedBlocks = append(entry.SignedBlocks, block)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := tx.ForEach(kv.SlashingProtectionAttestations, nil, func(k, v []byte) error {
+		attestation := interchangeAttestation{
+			SourceEpoch: binary.BigEndian.Uint64(v),
+			TargetEpoch: binary.BigEndian.Uint64(k[length.Bytes48:]),
+		}
+		if signingRoot := libcommon.BytesToHash(v[8:]); signingRoot != (libcommon.Hash{}) {
+			attestation.SigningRoot = &signingRoot
+		}
+		entry := validator(k)
+		entry.SignedAttestations = append(entry.SignedAttestations, attestation)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	out := interchange{
+		Metadata: interchangeMetadata{
+			InterchangeFormatVersion: interchangeFormatVersion,
+			GenesisValidatorsRoot:    libcommon.BytesToHash(genesisValidatorsRoot),
+		},
+		Data: make([]interchangeValidator, 0, len(validators)),
+	}
+	for _, v := range validators {
+		out.Data = append(out.Data, *v)
+	}
+	sort.Slice(out.Data, func(i, j int) bool {
+		return bytes.Compare(out.Data[i].Pubkey[:], out.Data[j].Pubkey[:]) < 0
+	})
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
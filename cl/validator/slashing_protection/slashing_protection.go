@@ -0,0 +1,174 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package slashing_protection keeps the signing history of validator keys in the Caplin database and refuses
+// to sign anything that could get them slashed, following EIP-3076. The history can be moved from and to other
+// clients with the EIP-3076 interchange format.
+package slashing_protection
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+var (
+	ErrSlashableBlock                = errors.New("slashing protection: block may be a double proposal or is older than the signing history")
+	ErrSlashableAttestation          = errors.New("slashing protection: attestation may be a double or surround vote or is older than the signing history")
+	ErrGenesisValidatorsRootMismatch = errors.New("slashing protection: genesis validators root does not match the signing history")
+)
+
+// a zero signing root means the root is unknown, it never matches another one.
+func isRepeat(signingRoot, previous libcommon.Hash) bool {
+	return signingRoot != (libcommon.Hash{}) && signingRoot == previous
+}
+
+func recordKey(pubKey libcommon.Bytes48, n uint64) []byte {
+	key := make([]byte, length.Bytes48+8)
+	copy(key, pubKey[:])
+	binary.BigEndian.PutUint64(key[length.Bytes48:], n)
+	return key
+}
+
+// CheckGenesisValidatorsRoot makes sure the signing history belongs to the chain with the given genesis validators root,
+// an empty history is bound to it.
+func CheckGenesisValidatorsRoot(tx kv.RwTx, genesisValidatorsRoot libcommon.Hash) error {
+	stored, err := tx.GetOne(kv.SlashingProtectionMetadata, kv.SlashingProtectionGenesisValidatorsRootKey)
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		return tx.Put(kv.SlashingProtectionMetadata, kv.SlashingProtectionGenesisValidatorsRootKey, genesisValidatorsRoot[:])
+	}
+	if !bytes.Equal(stored, genesisValidatorsRoot[:]) {
+		return fmt.Errorf("%w: %x, expected %x", ErrGenesisValidatorsRootMismatch, genesisValidatorsRoot, stored)
+	}
+	return nil
+}
+
+// CheckAndRecordBlock records that the block with the given signing root is about to be signed by the key, or returns
+// ErrSlashableBlock if it must not be. Signing the same block again is allowed.
+func CheckAndRecordBlock(tx kv.RwTx, pubKey libcommon.Bytes48, slot uint64, signingRoot libcommon.Hash) error {
+	key := recordKey(pubKey, slot)
+	previous, err := tx.GetOne(kv.SlashingProtectionBlocks, key)
+	if err != nil {
+		return err
+	}
+	if len(previous) > 0 {
+		if isRepeat(signingRoot, libcommon.BytesToHash(previous)) {
+			return nil
+		}
+		return ErrSlashableBlock
+	}
+	// nothing at or below the lowest slot of the history may be signed, as the history may have been pruned
+	c, err := tx.Cursor(kv.SlashingProtectionBlocks)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	first, _, err := c.Seek(pubKey[:])
+	if err != nil {
+		return err
+	}
+	if first != nil && bytes.HasPrefix(first, pubKey[:]) && slot <= binary.BigEndian.Uint64(first[length.Bytes48:]) {
+		return ErrSlashableBlock
+	}
+	return tx.Put(kv.SlashingProtectionBlocks, key, signingRoot[:])
+}
+
+// CheckAndRecordAttestation records that the attestation with the given signing root is about to be signed by the key,
+// or returns ErrSlashableAttestation if it must not be. Signing the same attestation again is allowed.
+func CheckAndRecordAttestation(tx kv.RwTx, pubKey libcommon.Bytes48, sourceEpoch, targetEpoch uint64, signingRoot libcommon.Hash) error {
+	if sourceEpoch > targetEpoch {
+		return ErrSlashableAttestation
+	}
+	key := recordKey(pubKey, targetEpoch)
+	previous, err := tx.GetOne(kv.SlashingProtectionAttestations, key)
+	if err != nil {
+		return err
+	}
+	if len(previous) > 0 {
+		if binary.BigEndian.Uint64(previous) == sourceEpoch && isRepeat(signingRoot, libcommon.BytesToHash(previous[8:])) {
+			return nil
+		}
+		// double vote
+		return ErrSlashableAttestation
+	}
+
+	lowestSource, ok, err := readLowestSourceEpoch(tx, pubKey)
+	if err != nil {
+		return err
+	}
+	if ok && sourceEpoch < lowestSource {
+		return ErrSlashableAttestation
+	}
+
+	c, err := tx.Cursor(kv.SlashingProtectionAttestations)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	first, _, err := c.Seek(pubKey[:])
+	if err != nil {
+		return err
+	}
+	if first != nil && bytes.HasPrefix(first, pubKey[:]) && targetEpoch <= binary.BigEndian.Uint64(first[length.Bytes48:]) {
+		return ErrSlashableAttestation
+	}
+	// only votes with a target after the new source can surround it or be surrounded by it
+	k, v, err := c.Seek(recordKey(pubKey, sourceEpoch+1))
+	for ; err == nil && k != nil && bytes.HasPrefix(k, pubKey[:]); k, v, err = c.Next() {
+		previousTarget, previousSource := binary.BigEndian.Uint64(k[length.Bytes48:]), binary.BigEndian.Uint64(v)
+		if (sourceEpoch < previousSource && previousTarget < targetEpoch) || (previousSource < sourceEpoch && targetEpoch < previousTarget) {
+			return ErrSlashableAttestation
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		if err := writeLowestSourceEpoch(tx, pubKey, sourceEpoch); err != nil {
+			return err
+		}
+	}
+	return tx.Put(kv.SlashingProtectionAttestations, key, attestationValue(sourceEpoch, signingRoot))
+}
+
+func attestationValue(sourceEpoch uint64, signingRoot libcommon.Hash) []byte {
+	v := make([]byte, 8+length.Hash)
+	binary.BigEndian.PutUint64(v, sourceEpoch)
+	copy(v[8:], signingRoot[:])
+	return v
+}
+
+// the lowest source epoch of the attestations of a key is kept in the metadata, keyed by the key itself.
+func readLowestSourceEpoch(tx kv.Tx, pubKey libcommon.Bytes48) (uint64, bool, error) {
+	v, err := tx.GetOne(kv.SlashingProtectionMetadata, pubKey[:])
+	if err != nil || len(v) == 0 {
+		return 0, false, err
+	}
+	return binary.BigEndian.Uint64(v), true, nil
+}
+
+func writeLowestSourceEpoch(tx kv.RwTx, pubKey libcommon.Bytes48, epoch uint64) error {
+	return tx.Put(kv.SlashingProtectionMetadata, pubKey[:], binary.BigEndian.AppendUint64(nil, epoch))
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package slashing_protection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+)
+
+func setupTestTx(t *testing.T) kv.RwTx {
+	db := memdb.NewTestDB(t, kv.CaplinDB)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(tx.Rollback)
+	return tx
+}
+
+func TestGenesisValidatorsRoot(t *testing.T) {
+	tx := setupTestTx(t)
+	require.NoError(t, CheckGenesisValidatorsRoot(tx, libcommon.Hash{1}))
+	require.NoError(t, CheckGenesisValidatorsRoot(tx, libcommon.Hash{1}))
+	require.ErrorIs(t, CheckGenesisValidatorsRoot(tx, libcommon.Hash{2}), ErrGenesisValidatorsRootMismatch)
+}
+
+func TestCheckAndRecordBlock(t *testing.T) {
+	tx := setupTestTx(t)
+	keyA, keyB := libcommon.Bytes48{1}, libcommon.Bytes48{2}
+
+	require.NoError(t, CheckAndRecordBlock(tx, keyA, 10, libcommon.Hash{1}))
+	// repeat signing
+	require.NoError(t, CheckAndRecordBlock(tx, keyA, 10, libcommon.Hash{1}))
+	// double proposal
+	require.ErrorIs(t, CheckAndRecordBlock(tx, keyA, 10, libcommon.Hash{2}), ErrSlashableBlock)
+	// below the lowest slot
+	require.ErrorIs(t, CheckAndRecordBlock(tx, keyA, 9, libcommon.Hash{3}), ErrSlashableBlock)
+	require.NoError(t, CheckAndRecordBlock(tx, keyA, 12, libcommon.Hash{3}))
+	require.NoError(t, CheckAndRecordBlock(tx, keyA, 11, libcommon.Hash{4}))
+	require.NoError(t, CheckAndRecordBlock(tx, keyB, 1, libcommon.Hash{5}))
+	// an unknown signing root is never a repeat
+	require.NoError(t, CheckAndRecordBlock(tx, keyB, 2, libcommon.Hash{}))
+	require.ErrorIs(t, CheckAndRecordBlock(tx, keyB, 2, libcommon.Hash{}), ErrSlashableBlock)
+}
+
+func TestCheckAndRecordAttestation(t *testing.T) {
+	tx := setupTestTx(t)
+	keyA, keyB := libcommon.Bytes48{1}, libcommon.Bytes48{2}
+
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, keyA, 5, 4, libcommon.Hash{1}), ErrSlashableAttestation)
+	require.NoError(t, CheckAndRecordAttestation(tx, keyA, 3, 4, libcommon.Hash{1}))
+	// repeat signing
+	require.NoError(t, CheckAndRecordAttestation(tx, keyA, 3, 4, libcommon.Hash{1}))
+	// double votes
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, keyA, 3, 4, libcommon.Hash{2}), ErrSlashableAttestation)
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, keyA, 2, 4, libcommon.Hash{1}), ErrSlashableAttestation)
+	// below the lowest source and target
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, keyA, 2, 5, libcommon.Hash{3}), ErrSlashableAttestation)
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, keyA, 3, 3, libcommon.Hash{3}), ErrSlashableAttestation)
+
+	require.NoError(t, CheckAndRecordAttestation(tx, keyA, 5, 8, libcommon.Hash{4}))
+	// surrounded by 5 -> 8
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, keyA, 6, 7, libcommon.Hash{5}), ErrSlashableAttestation)
+	// surrounding 5 -> 8
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, keyA, 4, 9, libcommon.Hash{5}), ErrSlashableAttestation)
+	require.NoError(t, CheckAndRecordAttestation(tx, keyA, 4, 6, libcommon.Hash{5}))
+	require.NoError(t, CheckAndRecordAttestation(tx, keyA, 8, 9, libcommon.Hash{6}))
+
+	// other keys are independent
+	require.NoError(t, CheckAndRecordAttestation(tx, keyB, 0, 1, libcommon.Hash{7}))
+}
+
+const testInterchange = `{
+  "metadata": {
+    "interchange_format_version": "5",
+    "genesis_validators_root": "0x04700007fabc8282644aed6d1c7c9e21d38a03a0c4ba193f3afe428824b3a673"
+  },
+  "data": [
+    {
+      "pubkey": "0xb845089a1457f811bfc000588fbb4e713669be8ce060ea6be3c6ece09afc3794106c91ca73acda5e5457122d58723bed",
+      "signed_blocks": [
+        {
+          "slot": "81952",
+          "signing_root": "0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b"
+        },
+        {
+          "slot": "81951"
+        }
+      ],
+      "signed_attestations": [
+        {
+          "source_epoch": "2290",
+          "target_epoch": "3007",
+          "signing_root": "0x587d6a4f59a58fe24f406e0502413e77fe1babddee641fda30034ed37ecc884d"
+        },
+        {
+          "source_epoch": "2290",
+          "target_epoch": "3008"
+        }
+      ]
+    }
+  ]
+}`
+
+func TestInterchange(t *testing.T) {
+	tx := setupTestTx(t)
+	require.NoError(t, Import(tx, strings.NewReader(testInterchange)))
+	// importing twice changes nothing
+	require.NoError(t, Import(tx, strings.NewReader(testInterchange)))
+
+	pubKey := libcommon.Bytes48(libcommon.FromHex("0xb845089a1457f811bfc000588fbb4e713669be8ce060ea6be3c6ece09afc3794106c91ca73acda5e5457122d58723bed"))
+	require.NoError(t, CheckAndRecordBlock(tx, pubKey, 81952, libcommon.HexToHash("0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b")))
+	require.ErrorIs(t, CheckAndRecordBlock(tx, pubKey, 81950, libcommon.Hash{1}), ErrSlashableBlock)
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, pubKey, 2289, 3009, libcommon.Hash{1}), ErrSlashableAttestation)
+	require.ErrorIs(t, CheckAndRecordAttestation(tx, pubKey, 2290, 3008, libcommon.Hash{1}), ErrSlashableAttestation)
+
+	var exported bytes.Buffer
+	require.NoError(t, Export(tx, &exported))
+	var expected, actual any
+	require.NoError(t, json.Unmarshal([]byte(testInterchange), &expected))
+	require.NoError(t, json.Unmarshal(exported.Bytes(), &actual))
+	// records are exported in slot and epoch order
+	expected.(map[string]any)["data"].([]any)[0].(map[string]any)["signed_blocks"] = []any{
+		map[string]any{"slot": "81951"},
+		map[string]any{"slot": "81952", "signing_root": "0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b"},
+	}
+	require.Equal(t, expected, actual)
+
+	// a history of another chain is refused
+	other := strings.Replace(testInterchange, "0x04700007", "0x14700007", 1)
+	require.ErrorIs(t, Import(tx, strings.NewReader(other)), ErrGenesisValidatorsRootMismatch)
+}
+
+func TestImportConflict(t *testing.T) {
+	tx := setupTestTx(t)
+	require.NoError(t, Import(tx, strings.NewReader(testInterchange)))
+	conflicting := strings.Replace(testInterchange, "0x4ff6f743", "0x5ff6f743", 1)
+	require.NoError(t, Import(tx, strings.NewReader(conflicting)))
+
+	pubKey := libcommon.Bytes48(libcommon.FromHex("0xb845089a1457f811bfc000588fbb4e713669be8ce060ea6be3c6ece09afc3794106c91ca73acda5e5457122d58723bed"))
+	require.ErrorIs(t, CheckAndRecordBlock(tx, pubKey, 81952, libcommon.HexToHash("0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b")), ErrSlashableBlock)
+	require.ErrorIs(t, CheckAndRecordBlock(tx, pubKey, 81952, libcommon.HexToHash("0x5ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b")), ErrSlashableBlock)
+}
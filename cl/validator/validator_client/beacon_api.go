@@ -0,0 +1,279 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+)
+
+// beaconApi talks to the beacon node through its http handler, without leaving the process.
+type beaconApi struct {
+	handler   http.Handler
+	beaconCfg *clparams.BeaconChainConfig
+}
+
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header { return r.header }
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+type apiResponse struct {
+	Data    json.RawMessage `json:"data"`
+	Version string          `json:"version"`
+}
+
+func (b *beaconApi) do(ctx context.Context, method, path string, header http.Header, in, out any) error {
+	var body io.Reader = http.NoBody
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp := &responseRecorder{header: http.Header{}}
+	b.handler.ServeHTTP(resp, req)
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+	if resp.status != http.StatusOK && resp.status != http.StatusAccepted {
+		return fmt.Errorf("%s %s: status %d: %s", method, req.URL.Path, resp.status, bytes.TrimSpace(resp.body.Bytes()))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.body.Bytes(), out)
+}
+
+func (b *beaconApi) get(ctx context.Context, path string, out any) error {
+	resp := apiResponse{}
+	if err := b.do(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+func (b *beaconApi) post(ctx context.Context, path string, in, out any) error {
+	if out == nil {
+		return b.do(ctx, http.MethodPost, path, nil, in, nil)
+	}
+	resp := apiResponse{}
+	if err := b.do(ctx, http.MethodPost, path, nil, in, &resp); err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+func indiciesToStrings(indicies []uint64) []string {
+	out := make([]string, len(indicies))
+	for i, idx := range indicies {
+		out[i] = strconv.FormatUint(idx, 10)
+	}
+	return out
+}
+
+type validatorEntry struct {
+	Index     uint64 `json:"index,string"`
+	Status    string `json:"status"`
+	Validator struct {
+		Pubkey libcommon.Bytes48 `json:"pubkey"`
+	} `json:"validator"`
+}
+
+func (b *beaconApi) validators(ctx context.Context, pubKeys []libcommon.Bytes48) ([]validatorEntry, error) {
+	ids := make([]string, len(pubKeys))
+	for i, pubKey := range pubKeys {
+		ids[i] = hexutility.Encode(pubKey[:])
+	}
+	var resp []validatorEntry
+	err := b.get(ctx, "/eth/v1/beacon/states/head/validators?id="+strings.Join(ids, ","), &resp)
+	return resp, err
+}
+
+type attesterDuty struct {
+	Pubkey                  libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex          uint64            `json:"validator_index,string"`
+	CommitteeIndex          uint64            `json:"committee_index,string"`
+	CommitteeLength         uint64            `json:"committee_length,string"`
+	ValidatorCommitteeIndex uint64            `json:"validator_committee_index,string"`
+	CommitteesAtSlot        uint64            `json:"committees_at_slot,string"`
+	Slot                    uint64            `json:"slot,string"`
+}
+
+func (b *beaconApi) attesterDuties(ctx context.Context, epoch uint64, indicies []uint64) ([]attesterDuty, error) {
+	var duties []attesterDuty
+	err := b.post(ctx, fmt.Sprintf("/eth/v1/validator/duties/attester/%d", epoch), indiciesToStrings(indicies), &duties)
+	return duties, err
+}
+
+type proposerDuty struct {
+	Pubkey         libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex uint64            `json:"validator_index,string"`
+	Slot           uint64            `json:"slot,string"`
+}
+
+func (b *beaconApi) proposerDuties(ctx context.Context, epoch uint64) ([]proposerDuty, error) {
+	var duties []proposerDuty
+	err := b.get(ctx, fmt.Sprintf("/eth/v1/validator/duties/proposer/%d", epoch), &duties)
+	return duties, err
+}
+
+type syncDuty struct {
+	Pubkey                         libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex                 uint64            `json:"validator_index,string"`
+	ValidatorSyncCommitteeIndicies []string          `json:"validator_sync_committee_indices"`
+}
+
+func (b *beaconApi) syncDuties(ctx context.Context, epoch uint64, indicies []uint64) ([]syncDuty, error) {
+	var duties []syncDuty
+	err := b.post(ctx, fmt.Sprintf("/eth/v1/validator/duties/sync/%d", epoch), indiciesToStrings(indicies), &duties)
+	return duties, err
+}
+
+type proposerPreparation struct {
+	ValidatorIndex uint64            `json:"validator_index,string"`
+	FeeRecipient   libcommon.Address `json:"fee_recipient"`
+}
+
+func (b *beaconApi) prepareBeaconProposer(ctx context.Context, preparations []proposerPreparation) error {
+	return b.post(ctx, "/eth/v1/validator/prepare_beacon_proposer", preparations, nil)
+}
+
+func (b *beaconApi) attestationData(ctx context.Context, slot, committeeIndex uint64) (*solid.AttestationData, error) {
+	data := &solid.AttestationData{}
+	err := b.get(ctx, fmt.Sprintf("/eth/v1/validator/attestation_data?slot=%d&committee_index=%d", slot, committeeIndex), data)
+	return data, err
+}
+
+func (b *beaconApi) submitAttestations(ctx context.Context, attestations []*solid.Attestation) error {
+	return b.post(ctx, "/eth/v1/beacon/pool/attestations", attestations, nil)
+}
+
+func (b *beaconApi) headBlockRoot(ctx context.Context) (libcommon.Hash, error) {
+	var resp struct {
+		Root libcommon.Hash `json:"root"`
+	}
+	err := b.get(ctx, "/eth/v1/beacon/blocks/head/root", &resp)
+	return resp.Root, err
+}
+
+func (b *beaconApi) submitSyncCommitteeMessages(ctx context.Context, msgs []*cltypes.SyncCommitteeMessage) error {
+	return b.post(ctx, "/eth/v1/beacon/pool/sync_committees", msgs, nil)
+}
+
+func (b *beaconApi) subscribeToBeaconCommittees(ctx context.Context, subscriptions []*cltypes.BeaconCommitteeSubscription) error {
+	return b.post(ctx, "/eth/v1/validator/beacon_committee_subscriptions", subscriptions, nil)
+}
+
+type syncCommitteeSubscription struct {
+	ValidatorIndex        uint64   `json:"validator_index,string"`
+	SyncCommitteeIndicies []string `json:"sync_committee_indices"`
+	UntilEpoch            uint64   `json:"until_epoch,string"`
+}
+
+func (b *beaconApi) subscribeToSyncCommittees(ctx context.Context, subscriptions []syncCommitteeSubscription) error {
+	return b.post(ctx, "/eth/v1/validator/sync_committee_subscriptions", subscriptions, nil)
+}
+
+func (b *beaconApi) aggregateAttestation(ctx context.Context, slot uint64, attestationDataRoot libcommon.Hash) (*solid.Attestation, error) {
+	aggregate := &solid.Attestation{}
+	err := b.get(ctx, fmt.Sprintf("/eth/v1/validator/aggregate_attestation?attestation_data_root=%s&slot=%d", attestationDataRoot.Hex(), slot), aggregate)
+	return aggregate, err
+}
+
+func (b *beaconApi) submitAggregateAndProofs(ctx context.Context, aggregates []*cltypes.SignedAggregateAndProof) error {
+	return b.post(ctx, "/eth/v1/validator/aggregate_and_proofs", aggregates, nil)
+}
+
+func (b *beaconApi) syncCommitteeContribution(ctx context.Context, slot, subcommitteeIndex uint64, beaconBlockRoot libcommon.Hash) (*cltypes.Contribution, error) {
+	contribution := &cltypes.Contribution{}
+	err := b.get(ctx, fmt.Sprintf("/eth/v1/validator/sync_committee_contribution?slot=%d&subcommittee_index=%d&beacon_block_root=%s", slot, subcommitteeIndex, beaconBlockRoot.Hex()), contribution)
+	return contribution, err
+}
+
+func (b *beaconApi) submitContributionAndProofs(ctx context.Context, contributions []*cltypes.SignedContributionAndProof) error {
+	return b.post(ctx, "/eth/v1/validator/contribution_and_proofs", contributions, nil)
+}
+
+// produceBlock asks the beacon node for an unsigned block, which is returned along its blobs.
+func (b *beaconApi) produceBlock(ctx context.Context, slot uint64, randaoReveal libcommon.Bytes96, graffiti libcommon.Hash) (*cltypes.DenebBeaconBlock, error) {
+	query := url.Values{}
+	query.Set("randao_reveal", hexutility.Encode(randaoReveal[:]))
+	query.Set("graffiti", graffiti.Hex())
+	resp := apiResponse{}
+	if err := b.do(ctx, http.MethodGet, fmt.Sprintf("/eth/v3/validator/blocks/%d?%s", slot, query.Encode()), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	version, err := clparams.StringToClVersion(resp.Version)
+	if err != nil {
+		return nil, err
+	}
+	block := cltypes.NewDenebBeaconBlock(b.beaconCfg, version)
+	if err := json.Unmarshal(resp.Data, block); err != nil {
+		return nil, err
+	}
+	block.Block.SetVersion(version)
+	return block, nil
+}
+
+func (b *beaconApi) publishBlock(ctx context.Context, block *cltypes.DenebSignedBeaconBlock) error {
+	header := http.Header{}
+	header.Set("Eth-Consensus-Version", clparams.ClVersionToString(block.SignedBlock.Version()))
+	return b.do(ctx, http.MethodPost, "/eth/v2/beacon/blocks", header, block, nil)
+}
@@ -0,0 +1,211 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package keystore decrypts EIP-2335 BLS keystores, the format produced by the staking deposit cli.
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+var ErrInvalidPassword = errors.New("keystore: invalid password")
+
+type Module struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+type Crypto struct {
+	Kdf      Module `json:"kdf"`
+	Checksum Module `json:"checksum"`
+	Cipher   Module `json:"cipher"`
+}
+
+// Keystore is the json representation of an EIP-2335 keystore
+type Keystore struct {
+	Crypto      Crypto `json:"crypto"`
+	Description string `json:"description"`
+	Pubkey      string `json:"pubkey"`
+	Path        string `json:"path"`
+	UUID        string `json:"uuid"`
+	Version     int    `json:"version"`
+}
+
+// Read parses the keystore at the given path, without decrypting it.
+func Read(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ks := &Keystore{}
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	if ks.Version != 4 {
+		return nil, fmt.Errorf("keystore %s: unsupported version %d", path, ks.Version)
+	}
+	return ks, nil
+}
+
+// ReadDir parses all the *.json keystores in a directory, sorted by file name.
+func ReadDir(dir string) ([]*Keystore, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	keystores := make([]*Keystore, 0, len(files))
+	for _, file := range files {
+		ks, err := Read(file)
+		if err != nil {
+			return nil, err
+		}
+		keystores = append(keystores, ks)
+	}
+	return keystores, nil
+}
+
+// Decrypt returns the secret key stored in the keystore.
+func (ks *Keystore) Decrypt(password string) ([]byte, error) {
+	decryptionKey, err := ks.deriveKey(normalizePassword(password))
+	if err != nil {
+		return nil, err
+	}
+	cipherMessage, err := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid cipher message: %w", err)
+	}
+	checksum, err := hex.DecodeString(ks.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid checksum message: %w", err)
+	}
+
+	if ks.Crypto.Checksum.Function != "sha256" {
+		return nil, fmt.Errorf("keystore: unsupported checksum function %q", ks.Crypto.Checksum.Function)
+	}
+	expected := sha256.Sum256(append(append([]byte{}, decryptionKey[16:32]...), cipherMessage...))
+	if !bytes.Equal(expected[:], checksum) {
+		return nil, ErrInvalidPassword
+	}
+
+	if ks.Crypto.Cipher.Function != "aes-128-ctr" {
+		return nil, fmt.Errorf("keystore: unsupported cipher function %q", ks.Crypto.Cipher.Function)
+	}
+	iv, err := hexParam(ks.Crypto.Cipher.Params, "iv")
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, len(cipherMessage))
+	cipher.NewCTR(block, iv).XORKeyStream(secret, cipherMessage)
+	return secret, nil
+}
+
+func (ks *Keystore) deriveKey(password []byte) ([]byte, error) {
+	params := ks.Crypto.Kdf.Params
+	salt, err := hexParam(params, "salt")
+	if err != nil {
+		return nil, err
+	}
+	dkLen, err := intParam(params, "dklen")
+	if err != nil {
+		return nil, err
+	}
+	if dkLen < 32 {
+		return nil, fmt.Errorf("keystore: dklen %d is too short", dkLen)
+	}
+
+	switch ks.Crypto.Kdf.Function {
+	case "scrypt":
+		n, err := intParam(params, "n")
+		if err != nil {
+			return nil, err
+		}
+		r, err := intParam(params, "r")
+		if err != nil {
+			return nil, err
+		}
+		p, err := intParam(params, "p")
+		if err != nil {
+			return nil, err
+		}
+		return scrypt.Key(password, salt, n, r, p, dkLen)
+	case "pbkdf2":
+		c, err := intParam(params, "c")
+		if err != nil {
+			return nil, err
+		}
+		if prf, _ := params["prf"].(string); prf != "hmac-sha256" {
+			return nil, fmt.Errorf("keystore: unsupported pbkdf2 prf %q", prf)
+		}
+		return pbkdf2.Key(password, salt, c, dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("keystore: unsupported kdf function %q", ks.Crypto.Kdf.Function)
+	}
+}
+
+// normalizePassword applies the EIP-2335 password rules: NFKD normalization, then control codes are stripped.
+func normalizePassword(password string) []byte {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(password) {
+		if unicode.IsControl(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return []byte(b.String())
+}
+
+func hexParam(params map[string]interface{}, name string) ([]byte, error) {
+	s, ok := params[name].(string)
+	if !ok {
+		return nil, fmt.Errorf("keystore: missing param %q", name)
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid param %q: %w", name, err)
+	}
+	return b, nil
+}
+
+func intParam(params map[string]interface{}, name string) (int, error) {
+	// json numbers are decoded as float64
+	f, ok := params[name].(float64)
+	if !ok || f <= 0 || f != float64(int(f)) {
+		return 0, fmt.Errorf("keystore: missing or invalid param %q", name)
+	}
+	return int(f), nil
+}
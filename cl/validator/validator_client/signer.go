@@ -0,0 +1,125 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Giulio2002/bls"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/validator/validator_client/keystore"
+)
+
+// Message types, as named by the web3signer eth2 signing API.
+const (
+	signBlock                = "BLOCK_V2"
+	signAttestation          = "ATTESTATION"
+	signRandaoReveal         = "RANDAO_REVEAL"
+	signSyncCommitteeMessage = "SYNC_COMMITTEE_MESSAGE"
+
+	signAggregationSlot             = "AGGREGATION_SLOT"
+	signAggregateAndProof           = "AGGREGATE_AND_PROOF"
+	signAggregateAndProofV2         = "AGGREGATE_AND_PROOF_V2"
+	signSyncCommitteeSelectionProof = "SYNC_COMMITTEE_SELECTION_PROOF"
+	signContributionAndProof        = "SYNC_COMMITTEE_CONTRIBUTION_AND_PROOF"
+)
+
+// SigningRequest is what a validator asks a Signer to sign. Besides the signing root it carries the signed
+// object itself, so that remote signers can run their own slashing protection.
+type SigningRequest struct {
+	Type                  string
+	Fork                  *cltypes.Fork
+	GenesisValidatorsRoot libcommon.Hash
+	SigningRoot           libcommon.Hash
+	// ObjectKey is the json key of Object in the web3signer request body
+	ObjectKey string
+	Object    any
+}
+
+// Signer holds the validator keys, either in-process or behind a remote signer.
+type Signer interface {
+	PublicKeys(ctx context.Context) ([]libcommon.Bytes48, error)
+	Sign(ctx context.Context, pubKey libcommon.Bytes48, req *SigningRequest) (libcommon.Bytes96, error)
+	// SignBatch signs reqs[i] with pubKeys[i]. A failed request does not fail the others, its error is
+	// returned at the same position.
+	SignBatch(ctx context.Context, pubKeys []libcommon.Bytes48, reqs []*SigningRequest) ([]libcommon.Bytes96, []error)
+}
+
+type localSigner struct {
+	keys    map[libcommon.Bytes48]*bls.PrivateKey
+	pubKeys []libcommon.Bytes48
+}
+
+// NewLocalSigner decrypts all the EIP-2335 keystores in keystoresDir with the password in passwordFile.
+func NewLocalSigner(keystoresDir, passwordFile string, logger log.Logger) (Signer, error) {
+	password, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystores password: %w", err)
+	}
+	keystores, err := keystore.ReadDir(keystoresDir)
+	if err != nil {
+		return nil, err
+	}
+	s := &localSigner{keys: make(map[libcommon.Bytes48]*bls.PrivateKey, len(keystores))}
+	for _, ks := range keystores {
+		secret, err := ks.Decrypt(strings.TrimRight(string(password), "\r\n"))
+		if err != nil {
+			return nil, fmt.Errorf("keystore %s: %w", ks.Pubkey, err)
+		}
+		privateKey, err := bls.NewPrivateKeyFromBytes(secret)
+		if err != nil {
+			return nil, fmt.Errorf("keystore %s: %w", ks.Pubkey, err)
+		}
+		var pubKey libcommon.Bytes48
+		copy(pubKey[:], bls.CompressPublicKey(privateKey.PublicKey()))
+		if _, ok := s.keys[pubKey]; ok {
+			continue
+		}
+		s.keys[pubKey] = privateKey
+		s.pubKeys = append(s.pubKeys, pubKey)
+	}
+	logger.Info("[Validator] Loaded keystores", "count", len(s.pubKeys), "dir", keystoresDir)
+	return s, nil
+}
+
+func (s *localSigner) PublicKeys(context.Context) ([]libcommon.Bytes48, error) {
+	return s.pubKeys, nil
+}
+
+func (s *localSigner) Sign(_ context.Context, pubKey libcommon.Bytes48, req *SigningRequest) (libcommon.Bytes96, error) {
+	privateKey, ok := s.keys[pubKey]
+	if !ok {
+		return libcommon.Bytes96{}, fmt.Errorf("unknown validator key %x", pubKey)
+	}
+	var signature libcommon.Bytes96
+	copy(signature[:], privateKey.Sign(req.SigningRoot[:]).Bytes())
+	return signature, nil
+}
+
+func (s *localSigner) SignBatch(ctx context.Context, pubKeys []libcommon.Bytes48, reqs []*SigningRequest) ([]libcommon.Bytes96, []error) {
+	signatures, errs := make([]libcommon.Bytes96, len(reqs)), make([]error, len(reqs))
+	for i, req := range reqs {
+		signatures[i], errs[i] = s.Sign(ctx, pubKeys[i], req)
+	}
+	return signatures, errs
+}
@@ -0,0 +1,772 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package validator_client is a validator client embedded in Caplin: it performs the attester, aggregator, proposer
+// and sync committee duties of its validators against the beacon API of the same process, so that solo stakers
+// do not have to run a separate one.
+package validator_client
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+	"github.com/erigontech/erigon/cl/utils"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+	"github.com/erigontech/erigon/cl/validator/slashing_protection"
+)
+
+type Config struct {
+	// KeystoresDir holds EIP-2335 keystores, all encrypted with the password in PasswordFile.
+	KeystoresDir string
+	PasswordFile string
+	// Web3SignerUrl is used instead of local keystores if set.
+	Web3SignerUrl string
+	FeeRecipient  libcommon.Address
+	Graffiti      string
+}
+
+type ValidatorClient struct {
+	api    *beaconApi
+	signer Signer
+	// db keeps the slashing protection history
+	db        kv.RwDB
+	beaconCfg *clparams.BeaconChainConfig
+	ethClock  eth_clock.EthereumClock
+	logger    log.Logger
+
+	feeRecipient libcommon.Address
+	graffiti     libcommon.Hash
+
+	// indicies of the validators which are known to the beacon state
+	indicies map[libcommon.Bytes48]uint64
+
+	dutiesEpoch      uint64
+	hasDuties        bool
+	attesterDuties   map[uint64][]attesterDuty
+	aggregatorDuties map[uint64][]aggregatorDuty
+	proposerDuties   map[uint64]proposerDuty
+	syncDuties       []syncDuty
+}
+
+// aggregatorDuty is the attester duty of a validator selected to aggregate the attestations of its committee.
+type aggregatorDuty struct {
+	attesterDuty
+	selectionProof libcommon.Bytes96
+}
+
+// New creates a validator client using the given beacon API handler, which must serve the beacon and validator endpoints.
+// The slashing protection history is kept in db.
+func New(ctx context.Context, cfg Config, handler http.Handler, db kv.RwDB, beaconCfg *clparams.BeaconChainConfig, ethClock eth_clock.EthereumClock, logger log.Logger) (*ValidatorClient, error) {
+	var (
+		signer Signer
+		err    error
+	)
+	if cfg.Web3SignerUrl != "" {
+		signer = NewWeb3Signer(cfg.Web3SignerUrl)
+	} else {
+		if signer, err = NewLocalSigner(cfg.KeystoresDir, cfg.PasswordFile, logger); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.Graffiti) > 32 {
+		return nil, fmt.Errorf("graffiti %q is longer than 32 bytes", cfg.Graffiti)
+	}
+	if err := db.Update(ctx, func(tx kv.RwTx) error {
+		return slashing_protection.CheckGenesisValidatorsRoot(tx, ethClock.GenesisValidatorsRoot())
+	}); err != nil {
+		return nil, err
+	}
+	v := &ValidatorClient{
+		api:          &beaconApi{handler: handler, beaconCfg: beaconCfg},
+		signer:       signer,
+		db:           db,
+		beaconCfg:    beaconCfg,
+		ethClock:     ethClock,
+		logger:       logger,
+		feeRecipient: cfg.FeeRecipient,
+		indicies:     make(map[libcommon.Bytes48]uint64),
+	}
+	copy(v.graffiti[:], cfg.Graffiti)
+	return v, nil
+}
+
+// Run performs the duties of every slot until the context is cancelled.
+func (v *ValidatorClient) Run(ctx context.Context) error {
+	slot := v.ethClock.GetCurrentSlot()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(v.ethClock.GetSlotTime(slot))):
+		}
+		epoch := slot / v.beaconCfg.SlotsPerEpoch
+		if !v.hasDuties || v.dutiesEpoch != epoch {
+			if err := v.updateDuties(ctx, epoch); err != nil {
+				v.logger.Debug("[Validator] Could not update duties", "epoch", epoch, "err", err)
+			}
+		}
+		if v.hasDuties && v.dutiesEpoch == epoch {
+			proposerDuty, isProposer := v.proposerDuties[slot]
+			go v.performSlotDuties(ctx, slot, proposerDuty, isProposer, v.attesterDuties[slot], v.aggregatorDuties[slot], v.syncDuties)
+		}
+		slot++
+		// do not try to catch up with the slots we missed
+		if current := v.ethClock.GetCurrentSlot(); current > slot {
+			slot = current
+		}
+	}
+}
+
+func (v *ValidatorClient) updateDuties(ctx context.Context, epoch uint64) error {
+	if err := v.updateIndicies(ctx); err != nil {
+		return err
+	}
+	if len(v.indicies) == 0 {
+		return errors.New("no active validators")
+	}
+	indicies := make([]uint64, 0, len(v.indicies))
+	preparations := make([]proposerPreparation, 0, len(v.indicies))
+	for _, idx := range v.indicies {
+		indicies = append(indicies, idx)
+		preparations = append(preparations, proposerPreparation{ValidatorIndex: idx, FeeRecipient: v.feeRecipient})
+	}
+	if err := v.api.prepareBeaconProposer(ctx, preparations); err != nil {
+		return err
+	}
+
+	attesterDuties, err := v.api.attesterDuties(ctx, epoch, indicies)
+	if err != nil {
+		return err
+	}
+	proposerDuties, err := v.api.proposerDuties(ctx, epoch)
+	if err != nil {
+		return err
+	}
+	var syncDuties []syncDuty
+	if v.ethClock.StateVersionByEpoch(epoch) >= clparams.AltairVersion {
+		if syncDuties, err = v.api.syncDuties(ctx, epoch, indicies); err != nil {
+			return err
+		}
+	}
+	aggregatorDuties, err := v.selectAggregators(ctx, attesterDuties)
+	if err != nil {
+		return err
+	}
+	if err := v.subscribe(ctx, epoch, attesterDuties, aggregatorDuties, syncDuties); err != nil {
+		return err
+	}
+
+	v.attesterDuties = make(map[uint64][]attesterDuty)
+	for _, duty := range attesterDuties {
+		v.attesterDuties[duty.Slot] = append(v.attesterDuties[duty.Slot], duty)
+	}
+	v.aggregatorDuties = make(map[uint64][]aggregatorDuty)
+	for _, duty := range aggregatorDuties {
+		v.aggregatorDuties[duty.Slot] = append(v.aggregatorDuties[duty.Slot], duty)
+	}
+	v.proposerDuties = make(map[uint64]proposerDuty)
+	for _, duty := range proposerDuties {
+		if idx, ok := v.indicies[duty.Pubkey]; ok && idx == duty.ValidatorIndex {
+			v.proposerDuties[duty.Slot] = duty
+		}
+	}
+	v.syncDuties = syncDuties
+	v.dutiesEpoch, v.hasDuties = epoch, true
+	v.logger.Debug("[Validator] Updated duties", "epoch", epoch, "validators", len(indicies),
+		"attestations", len(attesterDuties), "aggregations", len(aggregatorDuties), "proposals", len(v.proposerDuties), "syncCommittee", len(syncDuties))
+	return nil
+}
+
+// selectAggregators signs the selection proofs of the attester duties and returns the duties of the validators
+// selected to aggregate. A selection proof which could not be signed only loses its aggregation.
+func (v *ValidatorClient) selectAggregators(ctx context.Context, duties []attesterDuty) ([]aggregatorDuty, error) {
+	if len(duties) == 0 {
+		return nil, nil
+	}
+	pubKeys := make([]libcommon.Bytes48, len(duties))
+	reqs := make([]*SigningRequest, len(duties))
+	for i, duty := range duties {
+		var slotRoot libcommon.Hash
+		binary.LittleEndian.PutUint64(slotRoot[:], duty.Slot)
+		req, err := v.signingRequest(v.beaconCfg.DomainSelectionProof, duty.Slot/v.beaconCfg.SlotsPerEpoch, slotRoot, signAggregationSlot, "aggregation_slot",
+			map[string]string{"slot": strconv.FormatUint(duty.Slot, 10)})
+		if err != nil {
+			return nil, err
+		}
+		pubKeys[i], reqs[i] = duty.Pubkey, req
+	}
+	signatures, signErrs := v.signer.SignBatch(ctx, pubKeys, reqs)
+
+	var aggregators []aggregatorDuty
+	for i, duty := range duties {
+		if signErrs[i] != nil {
+			v.logger.Warn("[Validator] Failed to sign selection proof", "slot", duty.Slot, "validator", duty.ValidatorIndex, "err", signErrs[i])
+			continue
+		}
+		if state.IsAggregator(v.beaconCfg, duty.CommitteeLength, duty.CommitteeIndex, signatures[i]) {
+			aggregators = append(aggregators, aggregatorDuty{attesterDuty: duty, selectionProof: signatures[i]})
+		}
+	}
+	return aggregators, nil
+}
+
+// subscribe asks the beacon node to join the subnets of the committees of the epoch: aggregators need all the
+// attestations and sync committee messages of their subnets to aggregate them.
+func (v *ValidatorClient) subscribe(ctx context.Context, epoch uint64, attesterDuties []attesterDuty, aggregatorDuties []aggregatorDuty, syncDuties []syncDuty) error {
+	if len(attesterDuties) > 0 {
+		isAggregator := make(map[uint64]bool, len(aggregatorDuties))
+		for _, duty := range aggregatorDuties {
+			isAggregator[duty.ValidatorIndex] = true
+		}
+		subscriptions := make([]*cltypes.BeaconCommitteeSubscription, len(attesterDuties))
+		for i, duty := range attesterDuties {
+			subscriptions[i] = &cltypes.BeaconCommitteeSubscription{
+				ValidatorIndex:   duty.ValidatorIndex,
+				CommitteeIndex:   duty.CommitteeIndex,
+				CommitteesAtSlot: duty.CommitteesAtSlot,
+				Slot:             duty.Slot,
+				IsAggregator:     isAggregator[duty.ValidatorIndex],
+			}
+		}
+		if err := v.api.subscribeToBeaconCommittees(ctx, subscriptions); err != nil {
+			return err
+		}
+	}
+	if len(syncDuties) > 0 {
+		untilEpoch := (epoch/v.beaconCfg.EpochsPerSyncCommitteePeriod + 1) * v.beaconCfg.EpochsPerSyncCommitteePeriod
+		subscriptions := make([]syncCommitteeSubscription, len(syncDuties))
+		for i, duty := range syncDuties {
+			subscriptions[i] = syncCommitteeSubscription{ValidatorIndex: duty.ValidatorIndex, SyncCommitteeIndicies: duty.ValidatorSyncCommitteeIndicies, UntilEpoch: untilEpoch}
+		}
+		if err := v.api.subscribeToSyncCommittees(ctx, subscriptions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateIndicies looks up the indicies of the validators which are not in the beacon state yet.
+func (v *ValidatorClient) updateIndicies(ctx context.Context) error {
+	pubKeys, err := v.signer.PublicKeys(ctx)
+	if err != nil {
+		return err
+	}
+	unknown := make([]libcommon.Bytes48, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		if _, ok := v.indicies[pubKey]; !ok {
+			unknown = append(unknown, pubKey)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	validators, err := v.api.validators(ctx, unknown)
+	if err != nil {
+		return err
+	}
+	for _, validator := range validators {
+		v.indicies[validator.Validator.Pubkey] = validator.Index
+		v.logger.Info("[Validator] Found validator", "index", validator.Index, "status", validator.Status)
+	}
+	return nil
+}
+
+func (v *ValidatorClient) performSlotDuties(ctx context.Context, slot uint64, proposerDuty proposerDuty, isProposer bool, attesterDuties []attesterDuty, aggregatorDuties []aggregatorDuty, syncDuties []syncDuty) {
+	if isProposer {
+		if err := v.propose(ctx, proposerDuty); err != nil {
+			v.logger.Warn("[Validator] Failed to propose block", "slot", slot, "validator", proposerDuty.ValidatorIndex, "err", err)
+		}
+	}
+	if len(attesterDuties) == 0 && len(syncDuties) == 0 {
+		return
+	}
+	// attestations and sync committee messages are due a third into the slot
+	attestationTime := v.ethClock.GetSlotTime(slot).Add(time.Duration(v.beaconCfg.SecondsPerSlot) * time.Second / 3)
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(time.Until(attestationTime)):
+	}
+	var (
+		attestationData map[uint64]*solid.AttestationData
+		syncRoot        libcommon.Hash
+		err             error
+	)
+	if len(attesterDuties) > 0 {
+		if attestationData, err = v.attest(ctx, slot, attesterDuties); err != nil {
+			v.logger.Warn("[Validator] Failed to attest", "slot", slot, "err", err)
+		}
+	}
+	if len(syncDuties) > 0 {
+		if syncRoot, err = v.signSyncCommitteeMessages(ctx, slot, syncDuties); err != nil {
+			v.logger.Warn("[Validator] Failed to send sync committee messages", "slot", slot, "err", err)
+			syncDuties = nil
+		}
+	}
+	if len(attestationData) == 0 {
+		aggregatorDuties = nil
+	}
+	if len(aggregatorDuties) == 0 && len(syncDuties) == 0 {
+		return
+	}
+	// aggregates are due two thirds into the slot
+	aggregationTime := v.ethClock.GetSlotTime(slot).Add(2 * time.Duration(v.beaconCfg.SecondsPerSlot) * time.Second / 3)
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(time.Until(aggregationTime)):
+	}
+	if len(aggregatorDuties) > 0 {
+		if err := v.aggregateAttestations(ctx, slot, attestationData, aggregatorDuties); err != nil {
+			v.logger.Warn("[Validator] Failed to aggregate attestations", "slot", slot, "err", err)
+		}
+	}
+	if len(syncDuties) > 0 {
+		if err := v.aggregateSyncCommitteeMessages(ctx, slot, syncRoot, syncDuties); err != nil {
+			v.logger.Warn("[Validator] Failed to send sync committee contributions", "slot", slot, "err", err)
+		}
+	}
+}
+
+func (v *ValidatorClient) forkAtEpoch(epoch uint64) *cltypes.Fork {
+	version := v.ethClock.StateVersionByEpoch(epoch)
+	previous := version
+	if version > clparams.Phase0Version {
+		previous = version - 1
+	}
+	return &cltypes.Fork{
+		PreviousVersion: utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(previous)),
+		CurrentVersion:  utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(version)),
+		Epoch:           v.beaconCfg.GetForkEpochByVersion(version),
+	}
+}
+
+// signingRequest returns the request to sign the root of an object in the domain of the given epoch.
+func (v *ValidatorClient) signingRequest(domainType libcommon.Bytes4, epoch uint64, objectRoot libcommon.Hash, signType, objectKey string, object any) (*SigningRequest, error) {
+	forkInfo := v.forkAtEpoch(epoch)
+	genesisValidatorsRoot := v.ethClock.GenesisValidatorsRoot()
+	domain, err := fork.ComputeDomain(domainType[:], forkInfo.CurrentVersion, genesisValidatorsRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningRequest{
+		Type:                  signType,
+		Fork:                  forkInfo,
+		GenesisValidatorsRoot: genesisValidatorsRoot,
+		SigningRoot:           utils.Sha256(objectRoot[:], domain),
+		ObjectKey:             objectKey,
+		Object:                object,
+	}, nil
+}
+
+func (v *ValidatorClient) sign(ctx context.Context, pubKey libcommon.Bytes48, domainType libcommon.Bytes4, epoch uint64, objectRoot libcommon.Hash, signType, objectKey string, object any) (libcommon.Bytes96, error) {
+	req, err := v.signingRequest(domainType, epoch, objectRoot, signType, objectKey, object)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	return v.signer.Sign(ctx, pubKey, req)
+}
+
+func (v *ValidatorClient) propose(ctx context.Context, duty proposerDuty) error {
+	epoch := duty.Slot / v.beaconCfg.SlotsPerEpoch
+	var epochRoot libcommon.Hash
+	binary.LittleEndian.PutUint64(epochRoot[:], epoch)
+	randaoReveal, err := v.sign(ctx, duty.Pubkey, v.beaconCfg.DomainRandao, epoch, epochRoot, signRandaoReveal, "randao_reveal",
+		map[string]string{"epoch": strconv.FormatUint(epoch, 10)})
+	if err != nil {
+		return err
+	}
+
+	block, err := v.api.produceBlock(ctx, duty.Slot, randaoReveal, v.graffiti)
+	if err != nil {
+		return err
+	}
+	if block.Block.Slot != duty.Slot || block.Block.ProposerIndex != duty.ValidatorIndex {
+		return fmt.Errorf("beacon node produced a block for slot %d and proposer %d", block.Block.Slot, block.Block.ProposerIndex)
+	}
+	blockRoot, err := block.Block.HashSSZ()
+	if err != nil {
+		return err
+	}
+	bodyRoot, err := block.Block.Body.HashSSZ()
+	if err != nil {
+		return err
+	}
+
+	req, err := v.signingRequest(v.beaconCfg.DomainBeaconProposer, epoch, blockRoot, signBlock, "beacon_block", map[string]any{
+		"version": strings.ToUpper(block.Version().String()),
+		"block_header": &cltypes.BeaconBlockHeader{
+			Slot:          block.Block.Slot,
+			ProposerIndex: block.Block.ProposerIndex,
+			ParentRoot:    block.Block.ParentRoot,
+			Root:          block.Block.StateRoot,
+			BodyRoot:      bodyRoot,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := v.db.Update(ctx, func(tx kv.RwTx) error {
+		return slashing_protection.CheckAndRecordBlock(tx, duty.Pubkey, duty.Slot, req.SigningRoot)
+	}); err != nil {
+		return err
+	}
+	signature, err := v.signer.Sign(ctx, duty.Pubkey, req)
+	if err != nil {
+		return err
+	}
+
+	if err := v.api.publishBlock(ctx, &cltypes.DenebSignedBeaconBlock{
+		SignedBlock: &cltypes.SignedBeaconBlock{Block: block.Block, Signature: signature},
+		KZGProofs:   block.KZGProofs,
+		Blobs:       block.Blobs,
+	}); err != nil {
+		return err
+	}
+	v.logger.Info("[Validator] Proposed block", "slot", duty.Slot, "validator", duty.ValidatorIndex, "root", libcommon.Hash(blockRoot))
+	return nil
+}
+
+// aggregationBits returns the bitlist of a committee of the given length, with only the bit at position set.
+func aggregationBits(committeeLength, position uint64, capacity int) *solid.BitList {
+	bits := make([]byte, committeeLength/8+1)
+	bits[position/8] |= 1 << (position % 8)
+	// length delimiter
+	bits[committeeLength/8] |= 1 << (committeeLength % 8)
+	return solid.BitlistFromBytes(bits, capacity)
+}
+
+// attest publishes the attestations of the duties and returns the attested data by committee index.
+func (v *ValidatorClient) attest(ctx context.Context, slot uint64, duties []attesterDuty) (map[uint64]*solid.AttestationData, error) {
+	attestationData := make(map[uint64]*solid.AttestationData)
+	attestationReqs := make(map[uint64]*SigningRequest)
+	for _, duty := range duties {
+		if _, ok := attestationData[duty.CommitteeIndex]; ok {
+			continue
+		}
+		data, err := v.api.attestationData(ctx, slot, duty.CommitteeIndex)
+		if err != nil {
+			return nil, err
+		}
+		dataRoot, err := data.HashSSZ()
+		if err != nil {
+			return nil, err
+		}
+		req, err := v.signingRequest(v.beaconCfg.DomainBeaconAttester, data.Target.Epoch, dataRoot, signAttestation, "attestation", data)
+		if err != nil {
+			return nil, err
+		}
+		attestationData[duty.CommitteeIndex], attestationReqs[duty.CommitteeIndex] = data, req
+	}
+
+	// slashing protection is checked for the whole batch in one transaction before anything is sent to the signer
+	protectionErrs := make([]error, len(duties))
+	if err := v.db.Update(ctx, func(tx kv.RwTx) error {
+		for i, duty := range duties {
+			data := attestationData[duty.CommitteeIndex]
+			err := slashing_protection.CheckAndRecordAttestation(tx, duty.Pubkey, data.Source.Epoch, data.Target.Epoch, attestationReqs[duty.CommitteeIndex].SigningRoot)
+			if errors.Is(err, slashing_protection.ErrSlashableAttestation) {
+				protectionErrs[i] = err
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	signedDuties := make([]attesterDuty, 0, len(duties))
+	pubKeys := make([]libcommon.Bytes48, 0, len(duties))
+	reqs := make([]*SigningRequest, 0, len(duties))
+	for i, duty := range duties {
+		if protectionErrs[i] != nil {
+			v.logger.Warn("[Validator] Refusing to attest", "slot", slot, "validator", duty.ValidatorIndex, "err", protectionErrs[i])
+			continue
+		}
+		signedDuties = append(signedDuties, duty)
+		pubKeys = append(pubKeys, duty.Pubkey)
+		reqs = append(reqs, attestationReqs[duty.CommitteeIndex])
+	}
+	if len(reqs) == 0 {
+		return attestationData, nil
+	}
+	signatures, signErrs := v.signer.SignBatch(ctx, pubKeys, reqs)
+
+	isElectra := v.ethClock.StateVersionByEpoch(slot/v.beaconCfg.SlotsPerEpoch) >= clparams.ElectraVersion
+	attestations := make([]*solid.Attestation, 0, len(reqs))
+	for i, duty := range signedDuties {
+		if signErrs[i] != nil {
+			v.logger.Warn("[Validator] Failed to sign attestation", "slot", slot, "validator", duty.ValidatorIndex, "err", signErrs[i])
+			continue
+		}
+		attestation := &solid.Attestation{
+			Data:      attestationData[duty.CommitteeIndex],
+			Signature: signatures[i],
+		}
+		if isElectra {
+			attestation.AggregationBits = aggregationBits(duty.CommitteeLength, duty.ValidatorCommitteeIndex, int(v.beaconCfg.MaxValidatorsPerCommittee*v.beaconCfg.MaxCommitteesPerSlot))
+			attestation.CommitteeBits = solid.NewBitVector(int(v.beaconCfg.MaxCommitteesPerSlot))
+			if err := attestation.CommitteeBits.SetBitAt(int(duty.CommitteeIndex), true); err != nil {
+				return nil, err
+			}
+		} else {
+			attestation.AggregationBits = aggregationBits(duty.CommitteeLength, duty.ValidatorCommitteeIndex, int(v.beaconCfg.MaxValidatorsPerCommittee))
+		}
+		attestations = append(attestations, attestation)
+	}
+	if len(attestations) == 0 {
+		return attestationData, nil
+	}
+	if err := v.api.submitAttestations(ctx, attestations); err != nil {
+		return nil, err
+	}
+	v.logger.Debug("[Validator] Published attestations", "slot", slot, "count", len(attestations))
+	return attestationData, nil
+}
+
+// signSyncCommitteeMessages publishes the sync committee messages of the duties and returns the signed head block root.
+func (v *ValidatorClient) signSyncCommitteeMessages(ctx context.Context, slot uint64, duties []syncDuty) (libcommon.Hash, error) {
+	root, err := v.api.headBlockRoot(ctx)
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	req, err := v.signingRequest(v.beaconCfg.DomainSyncCommittee, epoch, root, signSyncCommitteeMessage, "sync_committee_message",
+		map[string]any{"beacon_block_root": root, "slot": strconv.FormatUint(slot, 10)})
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	// all the members sign the same message
+	pubKeys := make([]libcommon.Bytes48, len(duties))
+	reqs := make([]*SigningRequest, len(duties))
+	for i, duty := range duties {
+		pubKeys[i], reqs[i] = duty.Pubkey, req
+	}
+	signatures, signErrs := v.signer.SignBatch(ctx, pubKeys, reqs)
+
+	msgs := make([]*cltypes.SyncCommitteeMessage, 0, len(duties))
+	for i, duty := range duties {
+		if signErrs[i] != nil {
+			v.logger.Warn("[Validator] Failed to sign sync committee message", "slot", slot, "validator", duty.ValidatorIndex, "err", signErrs[i])
+			continue
+		}
+		msgs = append(msgs, &cltypes.SyncCommitteeMessage{
+			Slot:            slot,
+			BeaconBlockRoot: root,
+			ValidatorIndex:  duty.ValidatorIndex,
+			Signature:       signatures[i],
+		})
+	}
+	if len(msgs) == 0 {
+		return root, nil
+	}
+	if err := v.api.submitSyncCommitteeMessages(ctx, msgs); err != nil {
+		return libcommon.Hash{}, err
+	}
+	v.logger.Debug("[Validator] Published sync committee messages", "slot", slot, "count", len(msgs))
+	return root, nil
+}
+
+// aggregateAttestations publishes the aggregates of the attested data of the committees the validators aggregate.
+func (v *ValidatorClient) aggregateAttestations(ctx context.Context, slot uint64, attestationData map[uint64]*solid.AttestationData, duties []aggregatorDuty) error {
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	aggregates := make(map[uint64]*solid.Attestation)
+	aggregatorIndicies := make([]uint64, 0, len(duties))
+	pubKeys := make([]libcommon.Bytes48, 0, len(duties))
+	reqs := make([]*SigningRequest, 0, len(duties))
+	msgs := make([]*cltypes.AggregateAndProof, 0, len(duties))
+	for _, duty := range duties {
+		data, ok := attestationData[duty.CommitteeIndex]
+		if !ok {
+			continue
+		}
+		aggregate, ok := aggregates[duty.CommitteeIndex]
+		if !ok {
+			dataRoot, err := data.HashSSZ()
+			if err != nil {
+				return err
+			}
+			if aggregate, err = v.api.aggregateAttestation(ctx, slot, dataRoot); err != nil {
+				return err
+			}
+			aggregates[duty.CommitteeIndex] = aggregate
+		}
+		msg := &cltypes.AggregateAndProof{AggregatorIndex: duty.ValidatorIndex, Aggregate: aggregate, SelectionProof: duty.selectionProof}
+		msgRoot, err := msg.HashSSZ()
+		if err != nil {
+			return err
+		}
+		signType, object := signAggregateAndProof, any(msg)
+		if version := v.ethClock.StateVersionByEpoch(epoch); version >= clparams.ElectraVersion {
+			signType, object = signAggregateAndProofV2, map[string]any{"version": strings.ToUpper(version.String()), "data": msg}
+		}
+		req, err := v.signingRequest(v.beaconCfg.DomainAggregateAndProof, epoch, msgRoot, signType, "aggregate_and_proof", object)
+		if err != nil {
+			return err
+		}
+		aggregatorIndicies = append(aggregatorIndicies, duty.ValidatorIndex)
+		pubKeys = append(pubKeys, duty.Pubkey)
+		reqs = append(reqs, req)
+		msgs = append(msgs, msg)
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	signatures, signErrs := v.signer.SignBatch(ctx, pubKeys, reqs)
+
+	signed := make([]*cltypes.SignedAggregateAndProof, 0, len(msgs))
+	for i, msg := range msgs {
+		if signErrs[i] != nil {
+			v.logger.Warn("[Validator] Failed to sign aggregate and proof", "slot", slot, "validator", aggregatorIndicies[i], "err", signErrs[i])
+			continue
+		}
+		signed = append(signed, &cltypes.SignedAggregateAndProof{Message: msg, Signature: signatures[i]})
+	}
+	if len(signed) == 0 {
+		return nil
+	}
+	if err := v.api.submitAggregateAndProofs(ctx, signed); err != nil {
+		return err
+	}
+	v.logger.Debug("[Validator] Published aggregates", "slot", slot, "count", len(signed))
+	return nil
+}
+
+// isSyncCommitteeAggregator reports whether the selection proof selects its validator to aggregate the messages of a sync subcommittee.
+func isSyncCommitteeAggregator(beaconCfg *clparams.BeaconChainConfig, selectionProof libcommon.Bytes96) bool {
+	modulo := max(1, beaconCfg.SyncCommitteeSize/beaconCfg.SyncCommitteeSubnetCount/beaconCfg.TargetAggregatorsPerSyncSubcommittee)
+	hash := utils.Sha256(selectionProof[:])
+	return binary.LittleEndian.Uint64(hash[:8])%modulo == 0
+}
+
+// aggregateSyncCommitteeMessages publishes the contributions of the sync subcommittees the validators are selected to aggregate.
+func (v *ValidatorClient) aggregateSyncCommitteeMessages(ctx context.Context, slot uint64, root libcommon.Hash, duties []syncDuty) error {
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	subcommitteeSize := v.beaconCfg.SyncCommitteeSize / v.beaconCfg.SyncCommitteeSubnetCount
+
+	// a validator may be in the committee several times, possibly in the same subcommittee
+	type selection struct {
+		duty              syncDuty
+		subcommitteeIndex uint64
+	}
+	var selections []selection
+	var pubKeys []libcommon.Bytes48
+	var reqs []*SigningRequest
+	for _, duty := range duties {
+		seen := make(map[uint64]bool)
+		for _, committeeIndex := range duty.ValidatorSyncCommitteeIndicies {
+			idx, err := strconv.ParseUint(committeeIndex, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid sync committee index %q: %w", committeeIndex, err)
+			}
+			subcommitteeIndex := idx / subcommitteeSize
+			if seen[subcommitteeIndex] {
+				continue
+			}
+			seen[subcommitteeIndex] = true
+			data := &cltypes.SyncAggregatorSelectionData{Slot: slot, SubcommitteeIndex: subcommitteeIndex}
+			dataRoot, err := data.HashSSZ()
+			if err != nil {
+				return err
+			}
+			req, err := v.signingRequest(v.beaconCfg.DomainSyncCommitteeSelectionProof, epoch, dataRoot, signSyncCommitteeSelectionProof, "sync_aggregator_selection_data", data)
+			if err != nil {
+				return err
+			}
+			selections = append(selections, selection{duty: duty, subcommitteeIndex: subcommitteeIndex})
+			pubKeys = append(pubKeys, duty.Pubkey)
+			reqs = append(reqs, req)
+		}
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	selectionProofs, signErrs := v.signer.SignBatch(ctx, pubKeys, reqs)
+
+	contributions := make(map[uint64]*cltypes.Contribution)
+	var msgs []*cltypes.ContributionAndProof
+	pubKeys, reqs = pubKeys[:0], reqs[:0]
+	for i, sel := range selections {
+		if signErrs[i] != nil {
+			v.logger.Warn("[Validator] Failed to sign sync committee selection proof", "slot", slot, "validator", sel.duty.ValidatorIndex, "err", signErrs[i])
+			continue
+		}
+		if !isSyncCommitteeAggregator(v.beaconCfg, selectionProofs[i]) {
+			continue
+		}
+		contribution, ok := contributions[sel.subcommitteeIndex]
+		if !ok {
+			var err error
+			if contribution, err = v.api.syncCommitteeContribution(ctx, slot, sel.subcommitteeIndex, root); err != nil {
+				return err
+			}
+			contributions[sel.subcommitteeIndex] = contribution
+		}
+		if !slices.ContainsFunc(contribution.AggregationBits, func(b byte) bool { return b != 0 }) {
+			continue // no messages of the subcommittee to aggregate
+		}
+		msg := &cltypes.ContributionAndProof{AggregatorIndex: sel.duty.ValidatorIndex, Contribution: contribution, SelectionProof: selectionProofs[i]}
+		msgRoot, err := msg.HashSSZ()
+		if err != nil {
+			return err
+		}
+		req, err := v.signingRequest(v.beaconCfg.DomainContributionAndProof, epoch, msgRoot, signContributionAndProof, "contribution_and_proof", msg)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+		pubKeys = append(pubKeys, sel.duty.Pubkey)
+		reqs = append(reqs, req)
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	signatures, signErrs := v.signer.SignBatch(ctx, pubKeys, reqs)
+
+	signed := make([]*cltypes.SignedContributionAndProof, 0, len(msgs))
+	for i, msg := range msgs {
+		if signErrs[i] != nil {
+			v.logger.Warn("[Validator] Failed to sign contribution and proof", "slot", slot, "validator", msg.AggregatorIndex, "err", signErrs[i])
+			continue
+		}
+		signed = append(signed, &cltypes.SignedContributionAndProof{Message: msg, Signature: signatures[i]})
+	}
+	if len(signed) == 0 {
+		return nil
+	}
+	if err := v.api.submitContributionAndProofs(ctx, signed); err != nil {
+		return err
+	}
+	v.logger.Debug("[Validator] Published sync committee contributions", "slot", slot, "count", len(signed))
+	return nil
+}
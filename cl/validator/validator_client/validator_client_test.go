@@ -0,0 +1,355 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/antiquary/tests"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/utils"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+)
+
+func TestAggregationBits(t *testing.T) {
+	for _, tc := range []struct {
+		committeeLength, position uint64
+	}{{1, 0}, {8, 7}, {8, 0}, {130, 129}, {130, 64}} {
+		bits := aggregationBits(tc.committeeLength, tc.position, 2048)
+		require.Equal(t, int(tc.committeeLength), bits.Bits())
+		for i := uint64(0); i < tc.committeeLength; i++ {
+			require.Equal(t, i == tc.position, bits.GetBitAt(int(i)), "committee %d, bit %d", tc.committeeLength, i)
+		}
+	}
+}
+
+// mockBeaconApi serves canned responses of GET requests by path and records the bodies of POST requests.
+// A response may be a func(*http.Request) any to depend on the query.
+type mockBeaconApi struct {
+	mu        sync.Mutex
+	responses map[string]any
+	versions  map[string]string
+	posted    map[string][][]byte
+	headers   map[string]http.Header
+	queries   map[string]string
+}
+
+func newMockBeaconApi() *mockBeaconApi {
+	return &mockBeaconApi{
+		responses: make(map[string]any),
+		versions:  make(map[string]string),
+		posted:    make(map[string][][]byte),
+		headers:   make(map[string]http.Header),
+		queries:   make(map[string]string),
+	}
+}
+
+func (m *mockBeaconApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.headers[r.URL.Path] = r.Header.Clone()
+	m.queries[r.URL.Path] = r.URL.RawQuery
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.posted[r.URL.Path] = append(m.posted[r.URL.Path], body)
+		if _, ok := m.responses[r.URL.Path]; !ok {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	data, ok := m.responses[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if f, ok := data.(func(*http.Request) any); ok {
+		data = f(r)
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"data": data, "version": m.versions[r.URL.Path]}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (m *mockBeaconApi) postedTo(t *testing.T, path string, out any) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	require.Len(t, m.posted[path], 1, path)
+	require.NoError(t, json.Unmarshal(m.posted[path][0], out))
+}
+
+type testValidator struct {
+	pubKey     libcommon.Bytes48
+	privateKey *bls.PrivateKey
+	index      uint64
+}
+
+var testGenesisValidatorsRoot = libcommon.Hash{0xaa}
+
+// newTestValidatorClient returns a client of validators with indicies 0..n-1, in-process keys and the beacon API mocked by api.
+// Every validator is selected to aggregate, slots started a minute ago so that no duty waits for its time.
+func newTestValidatorClient(t *testing.T, api *mockBeaconApi, n int) (*ValidatorClient, []testValidator) {
+	t.Helper()
+	beaconCfg := clparams.MainnetBeaconConfig
+	beaconCfg.TargetAggregatorsPerCommittee = 1 << 20
+	beaconCfg.TargetAggregatorsPerSyncSubcommittee = 1 << 20
+
+	ethClock := eth_clock.NewMockEthereumClock(gomock.NewController(t))
+	ethClock.EXPECT().GenesisValidatorsRoot().Return(testGenesisValidatorsRoot).AnyTimes()
+	ethClock.EXPECT().StateVersionByEpoch(gomock.Any()).Return(clparams.DenebVersion).AnyTimes()
+	ethClock.EXPECT().GetSlotTime(gomock.Any()).Return(time.Now().Add(-time.Minute)).AnyTimes()
+
+	signer := &localSigner{keys: make(map[libcommon.Bytes48]*bls.PrivateKey)}
+	validators := make([]testValidator, n)
+	var validatorEntries []validatorEntry
+	for i := range validators {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		var pubKey libcommon.Bytes48
+		copy(pubKey[:], bls.CompressPublicKey(privateKey.PublicKey()))
+		signer.keys[pubKey], signer.pubKeys = privateKey, append(signer.pubKeys, pubKey)
+		validators[i] = testValidator{pubKey: pubKey, privateKey: privateKey, index: uint64(i)}
+
+		entry := validatorEntry{Index: uint64(i), Status: "active_ongoing"}
+		entry.Validator.Pubkey = pubKey
+		validatorEntries = append(validatorEntries, entry)
+	}
+	api.responses["/eth/v1/beacon/states/head/validators"] = validatorEntries
+	api.responses["/eth/v1/validator/duties/proposer/1"] = []proposerDuty{}
+	api.responses["/eth/v1/validator/duties/attester/1"] = []attesterDuty{}
+	api.responses["/eth/v1/validator/duties/sync/1"] = []syncDuty{}
+
+	return &ValidatorClient{
+		api:       &beaconApi{handler: api, beaconCfg: &beaconCfg},
+		signer:    signer,
+		db:        memdb.NewTestDB(t, kv.CaplinDB),
+		beaconCfg: &beaconCfg,
+		ethClock:  ethClock,
+		logger:    log.New(),
+		indicies:  make(map[libcommon.Bytes48]uint64),
+	}, validators
+}
+
+// performTestSlotDuties updates the duties of the epoch of slot and performs the duties of slot
+func performTestSlotDuties(t *testing.T, v *ValidatorClient, slot uint64) {
+	t.Helper()
+	require.NoError(t, v.updateDuties(context.Background(), slot/v.beaconCfg.SlotsPerEpoch))
+	proposerDuty, isProposer := v.proposerDuties[slot]
+	v.performSlotDuties(context.Background(), slot, proposerDuty, isProposer, v.attesterDuties[slot], v.aggregatorDuties[slot], v.syncDuties)
+}
+
+// requireSigned checks that signature is the signature of validator over objectRoot in the domain of domainType.
+func requireSigned(t *testing.T, v *ValidatorClient, validator testValidator, domainType libcommon.Bytes4, objectRoot [32]byte, signature libcommon.Bytes96) {
+	t.Helper()
+	forkVersion := utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(clparams.DenebVersion))
+	domain, err := fork.ComputeDomain(domainType[:], forkVersion, testGenesisValidatorsRoot)
+	require.NoError(t, err)
+	signingRoot := utils.Sha256(objectRoot[:], domain)
+	ok, err := bls.Verify(signature[:], signingRoot[:], validator.pubKey[:])
+	require.NoError(t, err)
+	require.True(t, ok, "invalid signature of validator %d", validator.index)
+}
+
+func TestValidatorClient_Attest(t *testing.T) {
+	api := newMockBeaconApi()
+	v, validators := newTestValidatorClient(t, api, 2)
+	slot := uint64(40)
+
+	api.responses["/eth/v1/validator/duties/attester/1"] = []attesterDuty{
+		{Pubkey: validators[0].pubKey, ValidatorIndex: 0, CommitteeIndex: 3, CommitteeLength: 10, ValidatorCommitteeIndex: 2, CommitteesAtSlot: 4, Slot: slot},
+		{Pubkey: validators[1].pubKey, ValidatorIndex: 1, CommitteeIndex: 3, CommitteeLength: 10, ValidatorCommitteeIndex: 7, CommitteesAtSlot: 4, Slot: slot},
+	}
+	data := &solid.AttestationData{
+		Slot:            slot,
+		CommitteeIndex:  3,
+		BeaconBlockRoot: libcommon.Hash{1},
+		Source:          solid.Checkpoint{Epoch: 0, Root: libcommon.Hash{2}},
+		Target:          solid.Checkpoint{Epoch: 1, Root: libcommon.Hash{3}},
+	}
+	dataRoot, err := data.HashSSZ()
+	require.NoError(t, err)
+	api.responses["/eth/v1/validator/attestation_data"] = data
+	aggregate := &solid.Attestation{
+		AggregationBits: aggregationBits(10, 2, int(v.beaconCfg.MaxValidatorsPerCommittee)),
+		Data:            data,
+		Signature:       libcommon.Bytes96{4},
+	}
+	api.responses["/eth/v1/validator/aggregate_attestation"] = aggregate
+
+	performTestSlotDuties(t, v, slot)
+
+	var subscriptions []*cltypes.BeaconCommitteeSubscription
+	api.postedTo(t, "/eth/v1/validator/beacon_committee_subscriptions", &subscriptions)
+	require.Equal(t, []*cltypes.BeaconCommitteeSubscription{
+		{ValidatorIndex: 0, CommitteeIndex: 3, CommitteesAtSlot: 4, Slot: slot, IsAggregator: true},
+		{ValidatorIndex: 1, CommitteeIndex: 3, CommitteesAtSlot: 4, Slot: slot, IsAggregator: true},
+	}, subscriptions)
+
+	var attestations []*solid.Attestation
+	api.postedTo(t, "/eth/v1/beacon/pool/attestations", &attestations)
+	require.Len(t, attestations, 2)
+	for i, attestation := range attestations {
+		require.Equal(t, dataRoot, mustHashSSZ(t, attestation.Data))
+		require.Equal(t, 10, attestation.AggregationBits.Bits())
+		require.True(t, attestation.AggregationBits.GetBitAt(int([]uint64{2, 7}[i])))
+		requireSigned(t, v, validators[i], v.beaconCfg.DomainBeaconAttester, dataRoot, attestation.Signature)
+	}
+
+	require.Equal(t, "attestation_data_root="+libcommon.Hash(dataRoot).Hex()+"&slot="+strconv.FormatUint(slot, 10), api.queries["/eth/v1/validator/aggregate_attestation"])
+	var aggregates []*cltypes.SignedAggregateAndProof
+	api.postedTo(t, "/eth/v1/validator/aggregate_and_proofs", &aggregates)
+	require.Len(t, aggregates, 2)
+	for i, signed := range aggregates {
+		require.Equal(t, uint64(i), signed.Message.AggregatorIndex)
+		require.Equal(t, mustHashSSZ(t, aggregate), mustHashSSZ(t, signed.Message.Aggregate))
+		var slotRoot [32]byte
+		slotRoot[0] = byte(slot)
+		requireSigned(t, v, validators[i], v.beaconCfg.DomainSelectionProof, slotRoot, signed.Message.SelectionProof)
+		requireSigned(t, v, validators[i], v.beaconCfg.DomainAggregateAndProof, mustHashSSZ(t, signed.Message), signed.Signature)
+	}
+
+	// the same target with another data is slashable: the validators refuse to attest again
+	data.BeaconBlockRoot = libcommon.Hash{5}
+	api.posted = make(map[string][][]byte)
+	performTestSlotDuties(t, v, slot)
+	require.Empty(t, api.posted["/eth/v1/beacon/pool/attestations"])
+}
+
+func TestValidatorClient_Propose(t *testing.T) {
+	api := newMockBeaconApi()
+	v, validators := newTestValidatorClient(t, api, 2)
+	slot := uint64(41)
+
+	api.responses["/eth/v1/validator/duties/proposer/1"] = []proposerDuty{
+		{Pubkey: validators[0].pubKey, ValidatorIndex: 0, Slot: slot - 1},
+		{Pubkey: validators[1].pubKey, ValidatorIndex: 1, Slot: slot},
+		// not ours
+		{Pubkey: libcommon.Bytes48{1}, ValidatorIndex: 7, Slot: slot + 1},
+	}
+	blocks, _, _ := tests.GetCapellaRandom()
+	block := cltypes.NewDenebBeaconBlock(v.beaconCfg, clparams.CapellaVersion)
+	block.Block = blocks[0].Block
+	block.Block.Slot, block.Block.ProposerIndex = slot, 1
+	api.responses["/eth/v3/validator/blocks/"+strconv.FormatUint(slot, 10)] = block
+	api.versions["/eth/v3/validator/blocks/"+strconv.FormatUint(slot, 10)] = "capella"
+
+	performTestSlotDuties(t, v, slot)
+	require.Len(t, v.proposerDuties, 2)
+
+	var preparations []proposerPreparation
+	api.postedTo(t, "/eth/v1/validator/prepare_beacon_proposer", &preparations)
+	require.Len(t, preparations, 2)
+
+	var published struct {
+		SignedBlock struct {
+			Signature libcommon.Bytes96 `json:"signature"`
+		} `json:"signed_block"`
+	}
+	api.postedTo(t, "/eth/v2/beacon/blocks", &published)
+	require.Equal(t, "capella", api.headers["/eth/v2/beacon/blocks"].Get("Eth-Consensus-Version"))
+	requireSigned(t, v, validators[1], v.beaconCfg.DomainBeaconProposer, mustHashSSZ(t, block.Block), published.SignedBlock.Signature)
+
+	// the randao reveal is the signature of the epoch
+	query := api.queries["/eth/v3/validator/blocks/"+strconv.FormatUint(slot, 10)]
+	randaoReveal := libcommon.Bytes96{}
+	require.NoError(t, randaoReveal.UnmarshalText([]byte(query[len("graffiti=")+66+len("&randao_reveal="):])))
+	var epochRoot [32]byte
+	epochRoot[0] = byte(slot / v.beaconCfg.SlotsPerEpoch)
+	requireSigned(t, v, validators[1], v.beaconCfg.DomainRandao, epochRoot, randaoReveal)
+}
+
+func TestValidatorClient_SyncCommittee(t *testing.T) {
+	api := newMockBeaconApi()
+	v, validators := newTestValidatorClient(t, api, 2)
+	slot := uint64(42)
+	subcommitteeSize := v.beaconCfg.SyncCommitteeSize / v.beaconCfg.SyncCommitteeSubnetCount
+
+	api.responses["/eth/v1/validator/duties/sync/1"] = []syncDuty{
+		// both in the subcommittee 0 and one of them in the subcommittee 1 as well
+		{Pubkey: validators[0].pubKey, ValidatorIndex: 0, ValidatorSyncCommitteeIndicies: []string{"5", strconv.FormatUint(subcommitteeSize+1, 10), "6"}},
+		{Pubkey: validators[1].pubKey, ValidatorIndex: 1, ValidatorSyncCommitteeIndicies: []string{"7"}},
+	}
+	headRoot := libcommon.Hash{9}
+	api.responses["/eth/v1/beacon/blocks/head/root"] = map[string]any{"root": headRoot}
+	aggregationBits := make([]byte, cltypes.SyncCommitteeAggregationBitsSize)
+	aggregationBits[0] = 1
+	api.responses["/eth/v1/validator/sync_committee_contribution"] = func(r *http.Request) any {
+		subcommitteeIndex, err := strconv.ParseUint(r.URL.Query().Get("subcommittee_index"), 10, 64)
+		require.NoError(t, err)
+		require.Equal(t, headRoot.Hex(), r.URL.Query().Get("beacon_block_root"))
+		return &cltypes.Contribution{Slot: slot, BeaconBlockRoot: headRoot, SubcommitteeIndex: subcommitteeIndex, AggregationBits: aggregationBits, Signature: libcommon.Bytes96{3}}
+	}
+
+	performTestSlotDuties(t, v, slot)
+
+	var subscriptions []syncCommitteeSubscription
+	api.postedTo(t, "/eth/v1/validator/sync_committee_subscriptions", &subscriptions)
+	require.Len(t, subscriptions, 2)
+	require.Equal(t, v.beaconCfg.EpochsPerSyncCommitteePeriod, subscriptions[0].UntilEpoch)
+
+	var msgs []*cltypes.SyncCommitteeMessage
+	api.postedTo(t, "/eth/v1/beacon/pool/sync_committees", &msgs)
+	require.Len(t, msgs, 2)
+	for i, msg := range msgs {
+		require.Equal(t, headRoot, msg.BeaconBlockRoot)
+		requireSigned(t, v, validators[i], v.beaconCfg.DomainSyncCommittee, headRoot, msg.Signature)
+	}
+
+	// one contribution per validator and subcommittee
+	var contributions []*cltypes.SignedContributionAndProof
+	api.postedTo(t, "/eth/v1/validator/contribution_and_proofs", &contributions)
+	require.Len(t, contributions, 3)
+	subcommittees := map[uint64][]uint64{}
+	for _, signed := range contributions {
+		msg := signed.Message
+		validator := validators[msg.AggregatorIndex]
+		subcommittees[msg.AggregatorIndex] = append(subcommittees[msg.AggregatorIndex], msg.Contribution.SubcommitteeIndex)
+		selectionData := &cltypes.SyncAggregatorSelectionData{Slot: slot, SubcommitteeIndex: msg.Contribution.SubcommitteeIndex}
+		requireSigned(t, v, validator, v.beaconCfg.DomainSyncCommitteeSelectionProof, mustHashSSZ(t, selectionData), msg.SelectionProof)
+		requireSigned(t, v, validator, v.beaconCfg.DomainContributionAndProof, mustHashSSZ(t, msg), signed.Signature)
+	}
+	require.Equal(t, map[uint64][]uint64{0: {0, 1}, 1: {0}}, subcommittees)
+}
+
+func mustHashSSZ(t *testing.T, obj interface{ HashSSZ() ([32]byte, error) }) [32]byte {
+	t.Helper()
+	root, err := obj.HashSSZ()
+	require.NoError(t, err)
+	return root
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/cl/cltypes"
+)
+
+const (
+	web3SignerTimeout = 10 * time.Second
+	// web3SignerMaxConcurrentRequests bounds how many requests of a batch are in flight at once,
+	// the web3signer API has no batch endpoint so a batch is sent as parallel requests over kept-alive connections.
+	web3SignerMaxConcurrentRequests = 16
+)
+
+// ErrWeb3SignerSlashingProtection is returned when the remote signer refuses to sign because of its own slashing protection.
+var ErrWeb3SignerSlashingProtection = errors.New("web3signer: signing refused by slashing protection")
+
+var (
+	web3SignerBatchLatency = metrics.GetOrCreateSummary("web3signer_batch_latency_seconds")
+	web3SignerBatchSize    = metrics.GetOrCreateGauge("web3signer_batch_size")
+)
+
+func web3SignerLatency(signType string) metrics.Summary {
+	return metrics.GetOrCreateSummary(fmt.Sprintf(`web3signer_sign_latency_seconds{type="%s"}`, signType))
+}
+
+func web3SignerErrors(signType string) metrics.Counter {
+	return metrics.GetOrCreateCounter(fmt.Sprintf(`web3signer_sign_errors{type="%s"}`, signType))
+}
+
+type web3Signer struct {
+	url    string
+	client *http.Client
+}
+
+// NewWeb3Signer returns a Signer backed by a remote signer implementing the web3signer eth2 API.
+func NewWeb3Signer(url string) Signer {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = web3SignerMaxConcurrentRequests
+	return &web3Signer{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: web3SignerTimeout, Transport: transport},
+	}
+}
+
+func (s *web3Signer) PublicKeys(ctx context.Context) ([]libcommon.Bytes48, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/api/v1/eth2/publicKeys", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	var pubKeys []libcommon.Bytes48
+	if err := s.do(req, &pubKeys); err != nil {
+		return nil, err
+	}
+	return pubKeys, nil
+}
+
+type web3SignerForkInfo struct {
+	Fork                  *cltypes.Fork  `json:"fork"`
+	GenesisValidatorsRoot libcommon.Hash `json:"genesis_validators_root"`
+}
+
+func (s *web3Signer) Sign(ctx context.Context, pubKey libcommon.Bytes48, signingReq *SigningRequest) (libcommon.Bytes96, error) {
+	start := time.Now()
+	signature, err := s.sign(ctx, pubKey, signingReq)
+	if err != nil {
+		web3SignerErrors(signingReq.Type).Inc()
+		return signature, err
+	}
+	web3SignerLatency(signingReq.Type).ObserveDuration(start)
+	return signature, nil
+}
+
+func (s *web3Signer) SignBatch(ctx context.Context, pubKeys []libcommon.Bytes48, reqs []*SigningRequest) ([]libcommon.Bytes96, []error) {
+	defer web3SignerBatchLatency.ObserveDuration(time.Now())
+	web3SignerBatchSize.SetUint64(uint64(len(reqs)))
+
+	signatures, errs := make([]libcommon.Bytes96, len(reqs)), make([]error, len(reqs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, web3SignerMaxConcurrentRequests)
+	for i := range reqs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			signatures[i], errs[i] = s.Sign(ctx, pubKeys[i], reqs[i])
+		}(i)
+	}
+	wg.Wait()
+	return signatures, errs
+}
+
+func (s *web3Signer) sign(ctx context.Context, pubKey libcommon.Bytes48, signingReq *SigningRequest) (libcommon.Bytes96, error) {
+	body := map[string]any{
+		"type": signingReq.Type,
+		"fork_info": web3SignerForkInfo{
+			Fork:                  signingReq.Fork,
+			GenesisValidatorsRoot: signingReq.GenesisValidatorsRoot,
+		},
+		"signingRoot":        signingReq.SigningRoot,
+		signingReq.ObjectKey: signingReq.Object,
+	}
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/api/v1/eth2/sign/"+hexutility.Encode(pubKey[:]), bytes.NewReader(encoded))
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	var resp struct {
+		Signature libcommon.Bytes96 `json:"signature"`
+	}
+	if err := s.do(req, &resp); err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	return resp.Signature, nil
+}
+
+func (s *web3Signer) do(req *http.Request, out any) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return ErrWeb3SignerSlashingProtection
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("web3signer: %s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeb3SignerBatch(t *testing.T) {
+	refusedKey := libcommon.Bytes48{0xff}
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		pubKey := strings.TrimPrefix(r.URL.Path, "/api/v1/eth2/sign/")
+		if pubKey == hexutility.Encode(refusedKey[:]) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var body struct {
+			Type     string `json:"type"`
+			ForkInfo struct {
+				Fork *cltypes.Fork `json:"fork"`
+			} `json:"fork_info"`
+			SigningRoot libcommon.Hash   `json:"signingRoot"`
+			Attestation *json.RawMessage `json:"attestation"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, signAttestation, body.Type)
+		require.NotNil(t, body.ForkInfo.Fork)
+		require.NotNil(t, body.Attestation)
+		// echo the signing root, so that the caller can match responses with requests
+		var signature libcommon.Bytes96
+		copy(signature[:], body.SigningRoot[:])
+		json.NewEncoder(w).Encode(map[string]any{"signature": signature})
+	}))
+	defer server.Close()
+
+	signer := NewWeb3Signer(server.URL + "/")
+	n := 3 * web3SignerMaxConcurrentRequests
+	pubKeys := make([]libcommon.Bytes48, n)
+	reqs := make([]*SigningRequest, n)
+	for i := range reqs {
+		pubKeys[i] = libcommon.Bytes48{byte(i)}
+		reqs[i] = &SigningRequest{
+			Type:        signAttestation,
+			Fork:        &cltypes.Fork{},
+			SigningRoot: libcommon.Hash{byte(i), 1},
+			ObjectKey:   "attestation",
+			Object:      map[string]string{"slot": "1"},
+		}
+	}
+	pubKeys[n-1] = refusedKey
+
+	signatures, errs := signer.SignBatch(context.Background(), pubKeys, reqs)
+	for i := 0; i < n-1; i++ {
+		require.NoError(t, errs[i])
+		require.Equal(t, reqs[i].SigningRoot[:], signatures[i][:32])
+	}
+	require.ErrorIs(t, errs[n-1], ErrWeb3SignerSlashingProtection)
+	require.LessOrEqual(t, int(maxInFlight.Load()), web3SignerMaxConcurrentRequests)
+}
@@ -60,6 +60,7 @@ import (
 	"github.com/erigontech/erigon/cl/phase1/stages"
 	"github.com/erigontech/erigon/cl/rpc"
 	"github.com/erigontech/erigon/cl/utils/eth_clock"
+	"github.com/erigontech/erigon/cl/validator/slashing_protection"
 	"github.com/erigontech/erigon/cmd/caplin/caplin1"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/eth/ethconfig/estimate"
@@ -83,6 +84,8 @@ var CLI struct {
 	CheckBlobsSnapshotsCount  CheckBlobsSnapshotsCount  `cmd:"" help:"check blobs snapshots count"`
 	DumpBlobsSnapshotsToStore DumpBlobsSnapshotsToStore `cmd:"" help:"dump blobs snapshots to store"`
 	DumpStateSnapshots        DumpStateSnapshots        `cmd:"" help:"dump state snapshots"`
+	ImportSlashingProtection  ImportSlashingProtection  `cmd:"" help:"import validator slashing protection history (EIP-3076 interchange format)"`
+	ExportSlashingProtection  ExportSlashingProtection  `cmd:"" help:"export validator slashing protection history (EIP-3076 interchange format)"`
 }
 
 type chainCfg struct {
@@ -609,7 +612,7 @@ func (r *RetrieveHistoricalState) Run(ctx *Context) error {
 	sn.OnHeadState(bs)
 
 	r.withPPROF.withProfile()
-	hr := historical_states_reader.NewHistoricalStatesReader(beaconConfig, snr, vt, gSpot, stateSn, sn)
+	hr := historical_states_reader.NewHistoricalStatesReader(beaconConfig, snr, vt, gSpot, stateSn, sn, 0)
 	start := time.Now()
 	haveState, err := hr.ReadHistoricalState(ctx, tx, r.CompareSlot)
 	if err != nil {
@@ -1269,3 +1272,73 @@ func (c *DumpStateSnapshots) Run(ctx *Context) error {
 
 	return nil
 }
+
+type ImportSlashingProtection struct {
+	chainCfg
+	outputFolder
+
+	File string `name:"file" help:"interchange file to import" required:""`
+}
+
+func (c *ImportSlashingProtection) Run(ctx *Context) error {
+	beaconConfig, err := c.configs()
+	if err != nil {
+		return err
+	}
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StderrHandler))
+
+	f, err := os.Open(c.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dirs := datadir.New(c.Datadir)
+	db, _, err := caplin1.OpenCaplinDatabase(ctx, beaconConfig, nil, dirs.CaplinIndexing, dirs.CaplinBlobs, nil, false, 0)
+	if err != nil {
+		return err
+	}
+	if err := db.Update(ctx, func(tx kv.RwTx) error {
+		return slashing_protection.Import(tx, f)
+	}); err != nil {
+		return err
+	}
+	log.Info("Imported slashing protection history", "file", c.File)
+	return nil
+}
+
+type ExportSlashingProtection struct {
+	chainCfg
+	outputFolder
+
+	File string `name:"file" help:"interchange file to write" required:""`
+}
+
+func (c *ExportSlashingProtection) Run(ctx *Context) error {
+	beaconConfig, err := c.configs()
+	if err != nil {
+		return err
+	}
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StderrHandler))
+
+	dirs := datadir.New(c.Datadir)
+	db, _, err := caplin1.OpenCaplinDatabase(ctx, beaconConfig, nil, dirs.CaplinIndexing, dirs.CaplinBlobs, nil, false, 0)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(c.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := db.View(ctx, func(tx kv.Tx) error {
+		return slashing_protection.Export(tx, f)
+	}); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	log.Info("Exported slashing protection history", "file", c.File)
+	return nil
+}
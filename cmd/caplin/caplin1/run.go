@@ -35,6 +35,7 @@ import (
 	"github.com/erigontech/erigon/cl/aggregation"
 	"github.com/erigontech/erigon/cl/antiquary"
 	"github.com/erigontech/erigon/cl/beacon"
+	"github.com/erigontech/erigon/cl/beacon/beacon_router_configuration"
 	"github.com/erigontech/erigon/cl/beacon/beaconevents"
 	"github.com/erigontech/erigon/cl/beacon/handler"
 	"github.com/erigontech/erigon/cl/beacon/synced_data"
@@ -43,11 +44,13 @@ import (
 	"github.com/erigontech/erigon/cl/monitor"
 	"github.com/erigontech/erigon/cl/rpc"
 	"github.com/erigontech/erigon/cl/sentinel"
+	"github.com/erigontech/erigon/cl/sentinel/communication/ssz_snappy"
 	"github.com/erigontech/erigon/cl/sentinel/service"
 	"github.com/erigontech/erigon/cl/utils/eth_clock"
 	"github.com/erigontech/erigon/cl/validator/attestation_producer"
 	"github.com/erigontech/erigon/cl/validator/committee_subscription"
 	"github.com/erigontech/erigon/cl/validator/sync_contribution_pool"
+	"github.com/erigontech/erigon/cl/validator/validator_client"
 	"github.com/erigontech/erigon/cl/validator/validator_params"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/params"
@@ -79,6 +82,7 @@ import (
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/mdbx"
 	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/deposit_tree"
 )
 
 func OpenCaplinDatabase(ctx context.Context,
@@ -211,10 +215,22 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	if err != nil {
 		return err
 	}
+	// follow the deposit contract from the checkpoint on, no deposit snapshot is served otherwise
+	var depositSnapshot *deposit_tree.Snapshot
+	if !config.DisabledCheckpointSync && !config.IsDevnet() {
+		depositSnapshot, err = checkpoint_sync.FetchDepositTreeSnapshot(ctx, config.NetworkId, state)
+		if err != nil {
+			log.Warn("[Checkpoint Sync] Could not fetch the deposit snapshot", "err", err)
+		}
+	}
 	ethClock := eth_clock.NewEthereumClock(state.GenesisTime(), state.GenesisValidatorsRoot(), beaconConfig)
 
-	pruneBlobDistance := uint64(128600)
-	if config.BlobBackfilling || config.BlobPruningDisabled {
+	if config.BlobsRetentionEpochs != 0 && config.BlobsRetentionEpochs < beaconConfig.MinEpochsForBlobsSidecarsRequest {
+		log.Warn("[Caplin] Blobs retention is below MIN_EPOCHS_FOR_BLOBS_SIDECARS_REQUEST, using the spec minimum instead",
+			"requested", config.BlobsRetentionEpochs, "minimum", beaconConfig.MinEpochsForBlobsSidecarsRequest)
+	}
+	pruneBlobDistance := max(config.BlobsRetentionEpochs, beaconConfig.MinEpochsForBlobsSidecarsRequest) * beaconConfig.SlotsPerEpoch
+	if config.BlobPruningDisabled {
 		pruneBlobDistance = math.MaxUint64
 	}
 
@@ -276,12 +292,20 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	pksRegistry := public_keys_registry.NewHeadViewPublicKeysRegistry(syncedDataManager)
 
 	forkChoice, err := forkchoice.NewForkChoiceStore(
-		ethClock, state, engine, pool, fork_graph.NewForkGraphDisk(state, fcuFs, config.BeaconAPIRouter, emitters),
+		ethClock, state, engine, pool, fork_graph.NewForkGraphDisk(state, fcuFs, config.BeaconAPIRouter, emitters, fork_graph.StateCachingPolicy{
+			HotEpochs:         config.HotStatesEpochs,
+			ColdSlotFrequency: config.ColdStatesSlotFrequency,
+		}),
 		emitters, syncedDataManager, blobStorage, validatorMonitor, pksRegistry, doLMDSampling)
 	if err != nil {
 		logger.Error("Could not create forkchoice", "err", err)
 		return err
 	}
+	if depositSnapshot != nil {
+		if err := forkChoice.InitDepositTree(depositSnapshot); err != nil {
+			logger.Warn("Could not load the deposit snapshot", "err", err)
+		}
+	}
 	bls.SetEnabledCaching(true)
 
 	forkDigest, err := ethClock.CurrentForkDigest()
@@ -290,21 +314,29 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	}
 	activeIndicies := state.GetActiveValidatorsIndices(state.Slot() / beaconConfig.SlotsPerEpoch)
 
+	// the encodings of the last blocks received via gossip, served to the peers without re-encoding them
+	encodingCache, err := ssz_snappy.NewEncodingCache(int(beaconConfig.SlotsPerEpoch) * 2)
+	if err != nil {
+		return err
+	}
 	sentinel, err := service.StartSentinelService(&sentinel.SentinelConfig{
-		IpAddr:                       config.CaplinDiscoveryAddr,
-		Port:                         int(config.CaplinDiscoveryPort),
-		TCPPort:                      uint(config.CaplinDiscoveryTCPPort),
-		EnableUPnP:                   config.EnableUPnP,
-		MaxInboundTrafficPerPeer:     config.MaxInboundTrafficPerPeer,
-		MaxOutboundTrafficPerPeer:    config.MaxOutboundTrafficPerPeer,
-		AdaptableTrafficRequirements: config.AdptableTrafficRequirements,
-		SubscribeAllTopics:           config.SubscribeAllTopics,
-		NetworkConfig:                networkConfig,
-		BeaconConfig:                 beaconConfig,
-		TmpDir:                       dirs.Tmp,
-		EnableBlocks:                 true,
-		ActiveIndicies:               uint64(len(activeIndicies)),
-		MaxPeerCount:                 config.MaxPeerCount,
+		IpAddr:                          config.CaplinDiscoveryAddr,
+		Port:                            int(config.CaplinDiscoveryPort),
+		TCPPort:                         uint(config.CaplinDiscoveryTCPPort),
+		EnableUPnP:                      config.EnableUPnP,
+		MaxInboundTrafficPerPeer:        config.MaxInboundTrafficPerPeer,
+		MaxOutboundTrafficPerPeer:       config.MaxOutboundTrafficPerPeer,
+		AdaptableTrafficRequirements:    config.AdptableTrafficRequirements,
+		SubscribeAllTopics:              config.SubscribeAllTopics,
+		AttestationSubnetBackbone:       config.AttestationSubnetBackbone,
+		AttestationSubnetRotationEpochs: config.AttestationSubnetRotationEpochs,
+		NetworkConfig:                   networkConfig,
+		BeaconConfig:                    beaconConfig,
+		TmpDir:                          dirs.Tmp,
+		EnableBlocks:                    true,
+		ActiveIndicies:                  uint64(len(activeIndicies)),
+		MaxPeerCount:                    config.MaxPeerCount,
+		EncodingCache:                   encodingCache,
 	}, rcsn, blobStorage, indexDB, &service.ServerConfig{
 		Network: "tcp",
 		Addr:    fmt.Sprintf("%s:%d", config.SentinelAddr, config.SentinelPort),
@@ -341,7 +373,7 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	// Create the gossip manager
 	gossipManager := network.NewGossipReceiver(sentinel, forkChoice, beaconConfig, networkConfig, ethClock, emitters, committeeSub,
 		blockService, blobService, syncCommitteeMessagesService, syncContributionService, aggregateAndProofService,
-		attestationService, voluntaryExitService, blsToExecutionChangeService, proposerSlashingService)
+		attestationService, voluntaryExitService, blsToExecutionChangeService, proposerSlashingService, encodingCache)
 	{ // start ticking forkChoice
 		go func() {
 			tickInterval := time.NewTicker(2 * time.Millisecond)
@@ -400,6 +432,13 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	if err := stateSnapshots.OpenFolder(); err != nil {
 		return err
 	}
+	// In archive mode sidecars are kept until they make it into the blob snapshots.
+	var frozenBlobs func() uint64
+	if config.BlobBackfilling {
+		frozenBlobs = csn.FrozenBlobs
+	}
+	go blob_storage.RunPruner(ctx, blobStorage, frozenBlobs, time.Duration(beaconConfig.SecondsPerSlot*beaconConfig.SlotsPerEpoch)*time.Second, logger)
+
 	antiq := antiquary.NewAntiquary(ctx, blobStorage, genesisState, vTables, beaconConfig, dirs, snDownloader, indexDB, stateSnapshots, csn, rcsn, syncedDataManager, logger, states, backfilling, blobBackfilling, config.SnapshotGenerationEnabled, snBuildSema)
 	// Create the antiquary
 	go func() {
@@ -412,10 +451,10 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 		return err
 	}
 
-	statesReader := historical_states_reader.NewHistoricalStatesReader(beaconConfig, rcsn, vTables, genesisState, stateSnapshots, syncedDataManager)
+	statesReader := historical_states_reader.NewHistoricalStatesReader(beaconConfig, rcsn, vTables, genesisState, stateSnapshots, syncedDataManager, config.HistoricalStatesCacheSize)
 	validatorParameters := validator_params.NewValidatorParams()
-	if config.BeaconAPIRouter.Active {
-		apiHandler := handler.NewApiHandler(
+	newApiHandler := func(routerCfg *beacon_router_configuration.RouterConfiguration) *handler.ApiHandler {
+		return handler.NewApiHandler(
 			logger,
 			networkConfig,
 			ethClock,
@@ -428,7 +467,7 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 			statesReader,
 			sentinel,
 			params.GitTag,
-			&config.BeaconAPIRouter,
+			routerCfg,
 			emitters,
 			blobStorage,
 			csn,
@@ -450,11 +489,36 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 			stateSnapshots,
 			true,
 		)
+	}
+	if config.BeaconAPIRouter.Active {
+		apiHandler := newApiHandler(&config.BeaconAPIRouter)
 		go beacon.ListenAndServe(&beacon.LayeredBeaconHandler{
 			ArchiveApi: apiHandler,
 		}, config.BeaconAPIRouter)
 		log.Info("Beacon API started", "addr", config.BeaconAPIRouter.Address)
 	}
+	if config.ValidatorClientEnabled() {
+		// the validator client gets its own handler, so it works whatever endpoints are exposed.
+		// It does not use the builder: blocks are built by the local execution client.
+		validatorRouterCfg := config.BeaconAPIRouter
+		validatorRouterCfg.Beacon, validatorRouterCfg.Validator, validatorRouterCfg.Builder = true, true, false
+		vc, err := validator_client.New(ctx, validator_client.Config{
+			KeystoresDir:  config.ValidatorKeystoresDir,
+			PasswordFile:  config.ValidatorPasswordFile,
+			Web3SignerUrl: config.Web3SignerUrl,
+			FeeRecipient:  config.ValidatorFeeRecipient,
+			Graffiti:      config.ValidatorGraffiti,
+		}, newApiHandler(&validatorRouterCfg), indexDB, beaconConfig, ethClock, logger)
+		if err != nil {
+			return fmt.Errorf("failed to start validator client: %w", err)
+		}
+		go func() {
+			if err := vc.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("[Validator] Validator client stopped", "err", err)
+			}
+		}()
+		logger.Info("[Validator] Validator client started")
+	}
 
 	stageCfg := stages.ClStagesCfg(
 		beaconRpc,
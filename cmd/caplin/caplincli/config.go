@@ -37,22 +37,24 @@ import (
 type CaplinCliCfg struct {
 	*sentinelcli.SentinelCliCfg
 
-	Chaindata             string        `json:"chaindata"`
-	ErigonPrivateApi      string        `json:"erigon_private_api"`
-	AllowedEndpoints      []string      `json:"endpoints"`
-	BeaconApiReadTimeout  time.Duration `json:"beacon_api_read_timeout"`
-	BeaconApiWriteTimeout time.Duration `json:"beacon_api_write_timeout"`
-	BeaconAddr            string        `json:"beacon_addr"`
-	BeaconProtocol        string        `json:"beacon_protocol"`
-	DataDir               string        `json:"data_dir"`
-	RunEngineAPI          bool          `json:"run_engine_api"`
-	EngineAPIAddr         string        `json:"engine_api_addr"`
-	EngineAPIPort         int           `json:"engine_api_port"`
-	MevRelayUrl           string        `json:"mev_relay_url"`
-	CustomConfig          string        `json:"custom_config"`
-	CustomGenesisState    string        `json:"custom_genesis_state"`
-	MaxPeerCount          uint64        `json:"max_peer_count"`
-	JwtSecret             []byte
+	Chaindata                       string        `json:"chaindata"`
+	ErigonPrivateApi                string        `json:"erigon_private_api"`
+	AllowedEndpoints                []string      `json:"endpoints"`
+	BeaconApiReadTimeout            time.Duration `json:"beacon_api_read_timeout"`
+	BeaconApiWriteTimeout           time.Duration `json:"beacon_api_write_timeout"`
+	BeaconAddr                      string        `json:"beacon_addr"`
+	BeaconProtocol                  string        `json:"beacon_protocol"`
+	DataDir                         string        `json:"data_dir"`
+	RunEngineAPI                    bool          `json:"run_engine_api"`
+	EngineAPIAddr                   string        `json:"engine_api_addr"`
+	EngineAPIPort                   int           `json:"engine_api_port"`
+	MevRelayUrl                     string        `json:"mev_relay_url"`
+	CustomConfig                    string        `json:"custom_config"`
+	CustomGenesisState              string        `json:"custom_genesis_state"`
+	MaxPeerCount                    uint64        `json:"max_peer_count"`
+	AttestationSubnetBackbone       uint64        `json:"attestation_subnet_backbone"`
+	AttestationSubnetRotationEpochs uint64        `json:"attestation_subnet_rotation_epochs"`
+	JwtSecret                       []byte
 
 	AllowedMethods   []string `json:"allowed_methods"`
 	AllowedOrigins   []string `json:"allowed_origins"`
@@ -75,6 +77,8 @@ func SetupCaplinCli(ctx *cli.Context) (cfg *CaplinCliCfg, err error) {
 	cfg.BeaconApiReadTimeout = time.Duration(ctx.Uint64(caplinflags.BeaconApiReadTimeout.Name)) * time.Second
 	cfg.BeaconApiWriteTimeout = time.Duration(ctx.Uint(caplinflags.BeaconApiWriteTimeout.Name)) * time.Second
 	cfg.MaxPeerCount = ctx.Uint64(utils.CaplinMaxPeerCount.Name)
+	cfg.AttestationSubnetBackbone = ctx.Uint64(utils.CaplinAttestationSubnetBackboneFlag.Name)
+	cfg.AttestationSubnetRotationEpochs = ctx.Uint64(utils.CaplinAttestationSubnetRotationEpochsFlag.Name)
 	cfg.BeaconAddr = fmt.Sprintf("%s:%d", ctx.String(caplinflags.BeaconApiAddr.Name), ctx.Int(caplinflags.BeaconApiPort.Name))
 	cfg.AllowCredentials = ctx.Bool(utils.BeaconApiAllowCredentialsFlag.Name)
 	cfg.AllowedMethods = ctx.StringSlice(utils.BeaconApiAllowMethodsFlag.Name)
@@ -42,6 +42,13 @@ var CliFlags = []cli.Flag{
 	&utils.BeaconApiAllowOriginsFlag,
 	&utils.CaplinCheckpointSyncUrlFlag,
 	&utils.CaplinMaxPeerCount,
+	&utils.CaplinAttestationSubnetBackboneFlag,
+	&utils.CaplinAttestationSubnetRotationEpochsFlag,
+	&utils.CaplinValidatorKeystoresFlag,
+	&utils.CaplinValidatorPasswordFileFlag,
+	&utils.CaplinValidatorWeb3SignerUrlFlag,
+	&utils.CaplinValidatorFeeRecipientFlag,
+	&utils.CaplinValidatorGraffitiFlag,
 }
 
 var (
@@ -105,17 +105,22 @@ func runCaplinNode(cliCtx *cli.Context) error {
 
 	blockSnapBuildSema := semaphore.NewWeighted(int64(dbg.BuildSnapshotAllowance))
 
-	return caplin1.RunCaplinService(ctx, executionEngine, clparams.CaplinConfig{
-		CaplinDiscoveryAddr:       cfg.Addr,
-		CaplinDiscoveryPort:       uint64(cfg.Port),
-		CaplinDiscoveryTCPPort:    uint64(cfg.ServerTcpPort),
-		BeaconAPIRouter:           rcfg,
-		NetworkId:                 networkId,
-		MevRelayUrl:               cfg.MevRelayUrl,
-		CustomConfigPath:          cfg.CustomConfig,
-		CustomGenesisStatePath:    cfg.CustomGenesisState,
-		MaxPeerCount:              cfg.MaxPeerCount,
-		MaxInboundTrafficPerPeer:  datasize.MB,
-		MaxOutboundTrafficPerPeer: datasize.MB,
-	}, cfg.Dirs, nil, nil, nil, blockSnapBuildSema)
+	caplinConfig := clparams.CaplinConfig{
+		CaplinDiscoveryAddr:             cfg.Addr,
+		CaplinDiscoveryPort:             uint64(cfg.Port),
+		CaplinDiscoveryTCPPort:          uint64(cfg.ServerTcpPort),
+		BeaconAPIRouter:                 rcfg,
+		NetworkId:                       networkId,
+		MevRelayUrl:                     cfg.MevRelayUrl,
+		CustomConfigPath:                cfg.CustomConfig,
+		CustomGenesisStatePath:          cfg.CustomGenesisState,
+		MaxPeerCount:                    cfg.MaxPeerCount,
+		AttestationSubnetBackbone:       cfg.AttestationSubnetBackbone,
+		AttestationSubnetRotationEpochs: cfg.AttestationSubnetRotationEpochs,
+		MaxInboundTrafficPerPeer:        datasize.MB,
+		MaxOutboundTrafficPerPeer:       datasize.MB,
+	}
+	utils.SetCaplinValidatorConfig(cliCtx, &caplinConfig)
+
+	return caplin1.RunCaplinService(ctx, executionEngine, caplinConfig, cfg.Dirs, nil, nil, nil, blockSnapBuildSema)
 }
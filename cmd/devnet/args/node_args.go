@@ -40,6 +40,7 @@ type NodeArgs struct {
 	BuildDir                  string `arg:"positional" default:"./build/bin/devnet" json:"builddir"`
 	DataDir                   string `arg:"--datadir" default:"./dev" json:"datadir"`
 	Chain                     string `arg:"--chain" default:"dev" json:"chain"`
+	ChainConfig               string `arg:"--chain.config" json:"chain.config,omitempty"`
 	Port                      int    `arg:"--port" json:"port,omitempty"`
 	AllowedPorts              string `arg:"--p2p.allowed-ports" json:"p2p.allowed-ports,omitempty"`
 	NAT                       string `arg:"--nat" default:"none" json:"nat"`
@@ -84,6 +85,7 @@ func (node *NodeArgs) Configure(base NodeArgs, nodeNumber int) error {
 	node.LogDirPrefix = node.Name
 
 	node.Chain = base.Chain
+	node.ChainConfig = base.ChainConfig
 
 	node.StaticPeers = base.StaticPeers
 
@@ -127,16 +129,27 @@ func (node *NodeArgs) GetName() string {
 
 func (node *NodeArgs) ChainID() *big.Int {
 	config := params.ChainConfigByChainName(node.Chain)
+	if node.Chain == networkname.Custom && node.ChainConfig != "" {
+		config, _ = params.ReadChainSpecFile(node.ChainConfig)
+	}
 	if config == nil {
 		return nil
 	}
 	return config.ChainID
 }
 
+func (node *NodeArgs) GetDataDir() string {
+	return node.DataDir
+}
+
 func (node *NodeArgs) GetHttpPort() int {
 	return node.HttpPort
 }
 
+func (node *NodeArgs) GetAuthRpcPort() int {
+	return node.AuthRpcPort
+}
+
 func (node *NodeArgs) GetEnodeURL() string {
 	port := node.Port
 	return enode.NewV4(&node.NodeKey.PublicKey, net.ParseIP("127.0.0.1"), port, port).URLv4()
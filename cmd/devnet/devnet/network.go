@@ -43,6 +43,7 @@ import (
 type Network struct {
 	DataDir            string
 	Chain              string
+	ChainSpec          string // path of the chain spec of networkname.Custom
 	Logger             log.Logger
 	BasePort           int
 	BasePrivateApiAddr string
@@ -84,6 +85,7 @@ func (nw *Network) Start(ctx context.Context) error {
 	baseNode := devnet_args.NodeArgs{
 		DataDir:        nw.DataDir,
 		Chain:          nw.Chain,
+		ChainConfig:    nw.ChainSpec,
 		Port:           nw.BasePort,
 		HttpPort:       nw.BaseRPCPort,
 		PrivateApiAddr: nw.BasePrivateApiAddr,
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
+	"path/filepath"
 	"sync"
 
 	"github.com/c2h5oh/datasize"
@@ -75,6 +76,20 @@ func HTTPHost(n Node) string {
 	return ""
 }
 
+// EngineAPI returns the engine API port of the node and the path of the JWT secret authenticating its callers
+func EngineAPI(n Node) (port int, jwtSecretPath string) {
+	if n, ok := n.(*devnetNode); ok {
+		if nodeArgs, ok := n.nodeArgs.(interface {
+			GetDataDir() string
+			GetAuthRpcPort() int
+		}); ok {
+			return nodeArgs.GetAuthRpcPort(), filepath.Join(nodeArgs.GetDataDir(), "jwt.hex")
+		}
+	}
+
+	return 0, ""
+}
+
 type devnetNode struct {
 	sync.Mutex
 	requests.RequestGenerator
@@ -418,24 +418,27 @@ func initDevnet(ctx *cli.Context, logger log.Logger) (devnet.Devnet, error) {
 		}
 	}
 
-	switch chainName {
-	case networkname.BorDevnet:
-		if ctx.Bool(WithoutHeimdallFlag.Name) {
-			return networks.NewBorDevnetWithoutHeimdall(dataDir, baseRpcHost, baseRpcPort, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
-		} else if ctx.Bool(LocalHeimdallFlag.Name) {
-			heimdallURL := ctx.String(HeimdallURLFlag.Name)
-			sprintSize := uint64(ctx.Int(BorSprintSizeFlag.Name))
-			return networks.NewBorDevnetWithLocalHeimdall(dataDir, baseRpcHost, baseRpcPort, heimdallURL, sprintSize, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
-		} else {
-			return networks.NewBorDevnetWithRemoteHeimdall(dataDir, baseRpcHost, baseRpcPort, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
-		}
-
-	case networkname.Dev:
-		return networks.NewDevDevnet(dataDir, baseRpcHost, baseRpcPort, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
-
-	default:
-		return nil, fmt.Errorf("unknown network: '%s'", chainName)
-	}
+	heimdallMode := networks.RemoteHeimdall
+	if ctx.Bool(WithoutHeimdallFlag.Name) {
+		heimdallMode = networks.NoHeimdall
+	} else if ctx.Bool(LocalHeimdallFlag.Name) {
+		heimdallMode = networks.LocalHeimdall
+	}
+
+	return networks.New(networks.Config{
+		Chain:           chainName,
+		DataDir:         dataDir,
+		BaseRpcHost:     baseRpcHost,
+		BaseRpcPort:     baseRpcPort,
+		ProducerCount:   producerCount,
+		GasLimit:        gasLimit,
+		Heimdall:        heimdallMode,
+		HeimdallURL:     ctx.String(HeimdallURLFlag.Name),
+		SprintSize:      uint64(ctx.Int(BorSprintSizeFlag.Name)),
+		Logger:          logger,
+		ConsoleLogLevel: consoleLogLevel,
+		DirLogLevel:     dirLogLevel,
+	})
 }
 
 func initDevnetMetrics(ctx *cli.Context, network devnet.Devnet) error {
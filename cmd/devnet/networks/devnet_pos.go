@@ -0,0 +1,106 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package networks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/devnet/accounts"
+	"github.com/erigontech/erigon/cmd/devnet/args"
+	"github.com/erigontech/erigon/cmd/devnet/devnet"
+	"github.com/erigontech/erigon/cmd/devnet/services/clmock"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/params"
+)
+
+// NewPosDevnet creates a proof-of-stake devnet: its nodes run a custom chain with all the protocol
+// changes active from genesis (params.AllProtocolChanges) and the CL mock drives them through the
+// engine API, producing a block every blockTime.
+func NewPosDevnet(
+	dataDir string,
+	baseRpcHost string,
+	baseRpcPort int,
+	nodeCount int,
+	gasLimit uint64,
+	blockTime time.Duration,
+	logger log.Logger,
+	consoleLogLevel log.Lvl,
+	dirLogLevel log.Lvl,
+) (devnet.Devnet, error) {
+	chainConfig := *params.AllProtocolChanges
+	chainConfig.ChainName = networkname.Custom
+
+	spec, err := json.Marshal(&chainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+
+	chainSpec := filepath.Join(dataDir, "chainspec.json")
+
+	if err := os.WriteFile(chainSpec, spec, 0644); err != nil {
+		return nil, err
+	}
+
+	var nodes []devnet.Node
+
+	if nodeCount == 0 {
+		nodeCount++
+	}
+
+	for i := 0; i < nodeCount; i++ {
+		nodes = append(nodes, &args.BlockConsumer{
+			NodeArgs: args.NodeArgs{
+				ConsoleVerbosity: strconv.Itoa(int(consoleLogLevel)),
+				DirVerbosity:     strconv.Itoa(int(dirLogLevel)),
+			},
+			HttpApi:     "admin,eth,erigon,web3,net,debug,trace,txpool,parity,ots",
+			TorrentPort: strconv.Itoa(42070 + i),
+		})
+	}
+
+	feeRecipient := accounts.NewAccount("cl-mock-fee-recipient")
+
+	network := devnet.Network{
+		DataDir:            dataDir,
+		Chain:              networkname.Custom,
+		ChainSpec:          chainSpec,
+		Logger:             logger,
+		BasePrivateApiAddr: "localhost:10090",
+		BaseRPCHost:        baseRpcHost,
+		BaseRPCPort:        baseRpcPort,
+		Genesis: &types.Genesis{
+			GasLimit: gasLimit,
+		},
+		Services: []devnet.Service{
+			clmock.NewClMock(&chainConfig, blockTime, feeRecipient.Address, logger),
+		},
+		MaxNumberOfEmptyBlockChecks: 30,
+		Nodes:                       nodes,
+	}
+
+	return devnet.Devnet{&network}, nil
+}
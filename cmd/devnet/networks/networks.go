@@ -0,0 +1,111 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package networks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/devnet/devnet"
+	"github.com/erigontech/erigon/cmd/devnet/services"
+	"github.com/erigontech/erigon/cmd/devnet/services/polygon"
+)
+
+type HeimdallMode int
+
+const (
+	LocalHeimdall  HeimdallMode = iota // heimdall mock running in the same process
+	RemoteHeimdall                     // heimdall the nodes are pointed to with default flags
+	NoHeimdall                         // bor nodes run with --bor.withoutheimdall
+)
+
+// Config describes an in-process devnet: all its nodes and services (faucet, heimdall mock, CL mock)
+// run as goroutines of the calling process, so e.g. integration tests can set up multi-node
+// scenarios programmatically.
+type Config struct {
+	Chain           string        // networkname.Dev or networkname.BorDevnet
+	ClMock          bool          // dev only, a proof-of-stake chain driven by the CL mock instead of clique, see NewPosDevnet
+	BlockTime       time.Duration // CL mock only, clmock.DefaultPeriod if 0
+	DataDir         string
+	BaseRpcHost     string
+	BaseRpcPort     int
+	ProducerCount   int
+	GasLimit        uint64
+	Heimdall        HeimdallMode // bor only
+	HeimdallURL     string       // address of the local heimdall mock, polygon.HeimdallURLDefault if empty
+	SprintSize      uint64       // bor only, 0 - sprint of bor-devnet chain spec
+	Logger          log.Logger
+	ConsoleLogLevel log.Lvl
+	DirLogLevel     log.Lvl
+}
+
+// New creates the devnet described by the config, without starting it
+func New(cfg Config) (devnet.Devnet, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = log.New()
+	}
+	if cfg.BaseRpcHost == "" {
+		cfg.BaseRpcHost = "localhost"
+	}
+
+	switch cfg.Chain {
+	case networkname.BorDevnet:
+		switch cfg.Heimdall {
+		case NoHeimdall:
+			return NewBorDevnetWithoutHeimdall(cfg.DataDir, cfg.BaseRpcHost, cfg.BaseRpcPort, cfg.GasLimit, cfg.Logger, cfg.ConsoleLogLevel, cfg.DirLogLevel), nil
+		case RemoteHeimdall:
+			return NewBorDevnetWithRemoteHeimdall(cfg.DataDir, cfg.BaseRpcHost, cfg.BaseRpcPort, cfg.ProducerCount, cfg.GasLimit, cfg.Logger, cfg.ConsoleLogLevel, cfg.DirLogLevel), nil
+		default:
+			heimdallURL := cfg.HeimdallURL
+			if heimdallURL == "" {
+				heimdallURL = polygon.HeimdallURLDefault
+			}
+			return NewBorDevnetWithLocalHeimdall(cfg.DataDir, cfg.BaseRpcHost, cfg.BaseRpcPort, heimdallURL, cfg.SprintSize, cfg.ProducerCount, cfg.GasLimit, cfg.Logger, cfg.ConsoleLogLevel, cfg.DirLogLevel), nil
+		}
+
+	case networkname.Dev:
+		if cfg.ClMock {
+			return NewPosDevnet(cfg.DataDir, cfg.BaseRpcHost, cfg.BaseRpcPort, cfg.ProducerCount, cfg.GasLimit, cfg.BlockTime, cfg.Logger, cfg.ConsoleLogLevel, cfg.DirLogLevel)
+		}
+		return NewDevDevnet(cfg.DataDir, cfg.BaseRpcHost, cfg.BaseRpcPort, cfg.ProducerCount, cfg.GasLimit, cfg.Logger, cfg.ConsoleLogLevel, cfg.DirLogLevel), nil
+
+	default:
+		return nil, fmt.Errorf("unknown network: '%s'", cfg.Chain)
+	}
+}
+
+// Start creates the devnet described by the config and starts its services and nodes.
+// The returned stop function stops all of them and drops the subscriptions made through the devnet context.
+func Start(cfg Config) (devnet.Context, func(), error) {
+	network, err := New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	runCtx, err := network.Start(cfg.Logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("devnet start failed: %w", err)
+	}
+
+	stop := func() {
+		network.Stop()
+		services.UnsubscribeAll()
+	}
+	return runCtx, stop, nil
+}
@@ -0,0 +1,344 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package clmock
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	libkzg "github.com/erigontech/erigon-lib/crypto/kzg"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/cl/phase1/execution_client/rpc_helper"
+	"github.com/erigontech/erigon/cmd/devnet/devnet"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
+)
+
+const (
+	DefaultPeriod = 5 * time.Second
+
+	// time given to the payload builder between the forkchoice update starting it and the payload request
+	buildTime = 500 * time.Millisecond
+)
+
+type engineClient struct {
+	node   devnet.Node
+	client *rpc.Client
+}
+
+// ClMock is a devnet service standing in for the consensus layer of proof-of-stake devnets:
+// every period it has the first node build a block through the engine API and makes all
+// nodes import it and move their forkchoice to it.
+type ClMock struct {
+	sync.Mutex
+	chainConfig  *chain.Config
+	period       time.Duration
+	feeRecipient libcommon.Address
+	logger       log.Logger
+	jwtSecrets   map[string][]byte
+	clients      []*engineClient
+	head         libcommon.Hash
+	headTime     uint64
+	cancelFunc   context.CancelFunc
+}
+
+func NewClMock(chainConfig *chain.Config, period time.Duration, feeRecipient libcommon.Address, logger log.Logger) *ClMock {
+	if period <= 0 {
+		period = DefaultPeriod
+	}
+
+	return &ClMock{
+		chainConfig:  chainConfig,
+		period:       period,
+		feeRecipient: feeRecipient,
+		logger:       logger,
+		jwtSecrets:   map[string][]byte{},
+	}
+}
+
+func (c *ClMock) Start(ctx context.Context) error {
+	c.Lock()
+	if c.cancelFunc != nil {
+		c.Unlock()
+		return nil
+	}
+	ctx, c.cancelFunc = context.WithCancel(ctx)
+	c.Unlock()
+
+	go c.run(ctx)
+	return nil
+}
+
+func (c *ClMock) Stop() {
+	var cancel context.CancelFunc
+
+	c.Lock()
+	if c.cancelFunc != nil {
+		cancel = c.cancelFunc
+		c.cancelFunc = nil
+	}
+	for _, client := range c.clients {
+		client.client.Close()
+	}
+	c.clients = nil
+	c.head = libcommon.Hash{}
+	c.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// NodeCreated writes the JWT secret of the node into its datadir, the node picks it up instead of generating one
+func (c *ClMock) NodeCreated(ctx context.Context, node devnet.Node) {
+	_, jwtSecretPath := devnet.EngineAPI(node)
+	if jwtSecretPath == "" {
+		return
+	}
+
+	jwtSecret := make([]byte, 32)
+	if _, err := rand.Read(jwtSecret); err != nil {
+		c.logger.Error("CL mock: failed to generate JWT secret", "node", node.GetName(), "err", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(jwtSecretPath), 0755); err != nil {
+		c.logger.Error("CL mock: failed to create datadir", "node", node.GetName(), "err", err)
+		return
+	}
+
+	if err := os.WriteFile(jwtSecretPath, []byte(hexutility.Encode(jwtSecret)), 0600); err != nil {
+		c.logger.Error("CL mock: failed to write JWT secret", "node", node.GetName(), "err", err)
+		return
+	}
+
+	c.Lock()
+	c.jwtSecrets[node.GetName()] = jwtSecret
+	c.Unlock()
+}
+
+func (c *ClMock) NodeStarted(ctx context.Context, node devnet.Node) {
+	port, _ := devnet.EngineAPI(node)
+
+	c.Lock()
+	defer c.Unlock()
+
+	jwtSecret, ok := c.jwtSecrets[node.GetName()]
+	if !ok {
+		return
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second, Transport: rpc_helper.NewJWTRoundTripper(jwtSecret)}
+	client, err := rpc.DialHTTPWithClient(fmt.Sprintf("http://localhost:%d", port), httpClient, c.logger)
+	if err != nil {
+		c.logger.Error("CL mock: failed to dial engine API", "node", node.GetName(), "err", err)
+		return
+	}
+
+	c.clients = append(c.clients, &engineClient{node: node, client: client})
+}
+
+func (c *ClMock) run(ctx context.Context) {
+	ticker := time.NewTicker(c.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.produceBlock(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				c.logger.Warn("CL mock: block production failed", "err", err)
+			}
+		}
+	}
+}
+
+// produceBlock builds a block on top of the current head with the first node, then imports it into all nodes
+func (c *ClMock) produceBlock(ctx context.Context) error {
+	c.Lock()
+	clients, parent, parentTime := c.clients, c.head, c.headTime
+	c.Unlock()
+
+	if len(clients) == 0 {
+		return nil
+	}
+
+	builder := clients[0]
+
+	if parent == (libcommon.Hash{}) {
+		block, err := builder.node.GetBlockByNumber(ctx, rpc.LatestBlockNumber, false)
+		if err != nil {
+			return fmt.Errorf("latest block of %s: %w", builder.node.GetName(), err)
+		}
+		parent, parentTime = block.Hash, block.Time
+	}
+
+	timestamp := uint64(time.Now().Unix())
+	if timestamp <= parentTime {
+		timestamp = parentTime + 1
+	}
+
+	version := engineVersion(c.chainConfig, timestamp)
+
+	attributes := &engine_types.PayloadAttributes{
+		Timestamp:             hexutil.Uint64(timestamp),
+		SuggestedFeeRecipient: c.feeRecipient,
+	}
+	if _, err := rand.Read(attributes.PrevRandao[:]); err != nil {
+		return err
+	}
+	if version >= 2 {
+		attributes.Withdrawals = []*types.Withdrawal{}
+	}
+	if version >= 3 {
+		attributes.ParentBeaconBlockRoot = &libcommon.Hash{}
+	}
+
+	forkchoice := &engine_types.ForkChoiceState{HeadHash: parent, SafeBlockHash: parent, FinalizedBlockHash: parent}
+
+	var fcuResponse engine_types.ForkChoiceUpdatedResponse
+	if err := builder.client.CallContext(ctx, &fcuResponse, forkchoiceUpdatedMethod(version), forkchoice, attributes); err != nil {
+		return fmt.Errorf("forkchoice update of %s: %w", builder.node.GetName(), err)
+	}
+	if fcuResponse.PayloadId == nil {
+		return fmt.Errorf("%s started no payload: %v", builder.node.GetName(), payloadStatus(fcuResponse.PayloadStatus))
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(buildTime):
+	}
+
+	var payload engine_types.GetPayloadResponse
+	if err := builder.client.CallContext(ctx, &payload, getPayloadMethod(version), fcuResponse.PayloadId); err != nil {
+		return fmt.Errorf("payload of %s: %w", builder.node.GetName(), err)
+	}
+
+	newPayloadArgs := []interface{}{payload.ExecutionPayload}
+	if version >= 3 {
+		versionedHashes := []libcommon.Hash{}
+		if payload.BlobsBundle != nil {
+			for _, commitment := range payload.BlobsBundle.Commitments {
+				var kzgCommitment gokzg4844.KZGCommitment
+				copy(kzgCommitment[:], commitment)
+				versionedHashes = append(versionedHashes, libcommon.Hash(libkzg.KZGToVersionedHash(kzgCommitment)))
+			}
+		}
+		newPayloadArgs = append(newPayloadArgs, versionedHashes, attributes.ParentBeaconBlockRoot)
+	}
+	if version >= 4 {
+		executionRequests := payload.ExecutionRequests
+		if executionRequests == nil {
+			executionRequests = []hexutility.Bytes{}
+		}
+		newPayloadArgs = append(newPayloadArgs, executionRequests)
+	}
+
+	head := payload.ExecutionPayload.BlockHash
+	forkchoice = &engine_types.ForkChoiceState{HeadHash: head, SafeBlockHash: head, FinalizedBlockHash: head}
+
+	for _, client := range clients {
+		var status engine_types.PayloadStatus
+		if err := client.client.CallContext(ctx, &status, newPayloadMethod(version), newPayloadArgs...); err != nil {
+			return fmt.Errorf("new payload of %s: %w", client.node.GetName(), err)
+		}
+		if status.Status != engine_types.ValidStatus {
+			return fmt.Errorf("%s did not accept block %d: %v", client.node.GetName(), payload.ExecutionPayload.BlockNumber, payloadStatus(&status))
+		}
+
+		if err := client.client.CallContext(ctx, &fcuResponse, forkchoiceUpdatedMethod(version), forkchoice, nil); err != nil {
+			return fmt.Errorf("forkchoice update of %s: %w", client.node.GetName(), err)
+		}
+	}
+
+	c.Lock()
+	c.head, c.headTime = head, uint64(payload.ExecutionPayload.Timestamp)
+	c.Unlock()
+
+	c.logger.Debug("CL mock: produced block", "number", uint64(payload.ExecutionPayload.BlockNumber), "hash", head)
+	return nil
+}
+
+// engineVersion is the version of the engine API methods for blocks with the given timestamp
+func engineVersion(chainConfig *chain.Config, timestamp uint64) int {
+	switch {
+	case chainConfig.IsPrague(timestamp):
+		return 4
+	case chainConfig.IsCancun(timestamp):
+		return 3
+	default:
+		return 2
+	}
+}
+
+func forkchoiceUpdatedMethod(version int) string {
+	switch version {
+	case 2:
+		return rpc_helper.ForkChoiceUpdatedV2
+	default:
+		return rpc_helper.ForkChoiceUpdatedV3
+	}
+}
+
+func getPayloadMethod(version int) string {
+	switch version {
+	case 2:
+		return rpc_helper.EngineGetPayloadV2
+	case 3:
+		return rpc_helper.EngineGetPayloadV3
+	default:
+		return rpc_helper.EngineGetPayloadV4
+	}
+}
+
+func newPayloadMethod(version int) string {
+	switch version {
+	case 2:
+		return rpc_helper.EngineNewPayloadV2
+	case 3:
+		return rpc_helper.EngineNewPayloadV3
+	default:
+		return rpc_helper.EngineNewPayloadV4
+	}
+}
+
+func payloadStatus(status *engine_types.PayloadStatus) string {
+	if status == nil {
+		return "no status"
+	}
+	if status.ValidationError != nil {
+		return fmt.Sprintf("%s: %s", status.Status, status.ValidationError.Error())
+	}
+	return string(status.Status)
+}
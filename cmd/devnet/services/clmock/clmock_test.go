@@ -0,0 +1,53 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package clmock
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon/params"
+)
+
+func TestEngineVersion(t *testing.T) {
+	chainConfig := *params.AllProtocolChanges
+	chainConfig.ShanghaiTime = big.NewInt(0)
+	chainConfig.CancunTime = big.NewInt(10)
+	chainConfig.PragueTime = big.NewInt(20)
+
+	testCases := []struct {
+		timestamp  uint64
+		version    int
+		forkchoice string
+		getPayload string
+		newPayload string
+	}{
+		{5, 2, "engine_forkchoiceUpdatedV2", "engine_getPayloadV2", "engine_newPayloadV2"},
+		{10, 3, "engine_forkchoiceUpdatedV3", "engine_getPayloadV3", "engine_newPayloadV3"},
+		{25, 4, "engine_forkchoiceUpdatedV3", "engine_getPayloadV4", "engine_newPayloadV4"},
+	}
+
+	for _, testCase := range testCases {
+		version := engineVersion(&chainConfig, testCase.timestamp)
+		require.Equal(t, testCase.version, version)
+		require.Equal(t, testCase.forkchoice, forkchoiceUpdatedMethod(version))
+		require.Equal(t, testCase.getPayload, getPayloadMethod(version))
+		require.Equal(t, testCase.newPayload, newPayloadMethod(version))
+	}
+}
@@ -130,6 +130,9 @@ func (hs heimdallStore) Spans() heimdall.EntityStore[*heimdall.Span] {
 func (heimdallStore) SpanBlockProducerSelections() heimdall.EntityStore[*heimdall.SpanBlockProducerSelection] {
 	return nil
 }
+func (heimdallStore) SprintBlockProducerSelections() heimdall.EntityStore[*heimdall.SprintBlockProducerSelection] {
+	return nil
+}
 func (heimdallStore) Prepare(ctx context.Context) error {
 	return nil
 }
@@ -28,36 +28,9 @@ import (
 	"github.com/erigontech/erigon-lib/chain/networkname"
 	"github.com/erigontech/erigon/cmd/devnet/devnet"
 	"github.com/erigontech/erigon/cmd/devnet/networks"
-	"github.com/erigontech/erigon/cmd/devnet/services"
-	"github.com/erigontech/erigon/cmd/devnet/services/polygon"
 	"github.com/erigontech/erigon/turbo/debug"
 )
 
-func initDevnet(chainName string, dataDir string, producerCount int, gasLimit uint64, logger log.Logger, consoleLogLevel log.Lvl, dirLogLevel log.Lvl) (devnet.Devnet, error) {
-	const baseRpcHost = "localhost"
-	const baseRpcPort = 9545
-
-	switch chainName {
-	case networkname.BorDevnet:
-		heimdallURL := polygon.HeimdallURLDefault
-		const sprintSize uint64 = 0
-		return networks.NewBorDevnetWithLocalHeimdall(dataDir, baseRpcHost, baseRpcPort, heimdallURL, sprintSize, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
-
-	case networkname.Dev:
-		return networks.NewDevDevnet(dataDir, baseRpcHost, baseRpcPort, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
-
-	case "":
-		envChainName, _ := os.LookupEnv("DEVNET_CHAIN")
-		if envChainName == "" {
-			envChainName = networkname.Dev
-		}
-		return initDevnet(envChainName, dataDir, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel)
-
-	default:
-		return nil, fmt.Errorf("unknown network: '%s'", chainName)
-	}
-}
-
 func ContextStart(t *testing.T, chainName string) (devnet.Context, error) {
 	//goland:noinspection GoBoolExpressions
 	if runtime.GOOS == "windows" {
@@ -75,23 +48,47 @@ func ContextStart(t *testing.T, chainName string) (devnet.Context, error) {
 
 	producerCount, _ := strconv.ParseUint(envProducerCount, 10, 64)
 
-	// TODO get log levels from env
-	var dirLogLevel log.Lvl = log.LvlTrace
-	var consoleLogLevel log.Lvl = log.LvlCrit
+	if chainName == "" {
+		chainName, _ = os.LookupEnv("DEVNET_CHAIN")
+		if chainName == "" {
+			chainName = networkname.Dev
+		}
+	}
 
-	var network devnet.Devnet
-	network, err := initDevnet(chainName, dataDir, int(producerCount), 0, logger, consoleLogLevel, dirLogLevel)
+	clMock, _ := strconv.ParseBool(os.Getenv("DEVNET_CL_MOCK"))
+
+	runCtx, stop, err := networks.Start(networks.Config{
+		Chain:           chainName,
+		ClMock:          clMock,
+		DataDir:         dataDir,
+		BaseRpcPort:     9545,
+		ProducerCount:   int(producerCount),
+		Logger:          logger,
+		ConsoleLogLevel: logLevelFromEnv("DEVNET_CONSOLE_LOG_LEVEL", log.LvlCrit),
+		DirLogLevel:     logLevelFromEnv("DEVNET_DIR_LOG_LEVEL", log.LvlTrace),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("ContextStart initDevnet failed: %w", err)
+		return nil, fmt.Errorf("ContextStart: %w", err)
 	}
+	t.Cleanup(stop)
 
-	runCtx, err := network.Start(logger)
-	if err != nil {
-		return nil, fmt.Errorf("ContextStart devnet start failed: %w", err)
+	return runCtx, nil
+}
+
+// logLevelFromEnv parses the log level in the env variable, either numeric or a level name (e.g. "info")
+func logLevelFromEnv(key string, defaultLevel log.Lvl) log.Lvl {
+	lvlVal, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultLevel
 	}
 
-	t.Cleanup(services.UnsubscribeAll)
-	t.Cleanup(network.Stop)
+	if i, err := strconv.Atoi(lvlVal); err == nil {
+		return log.Lvl(i)
+	}
 
-	return runCtx, nil
+	if lvl, err := log.LvlFromString(lvlVal); err == nil {
+		return lvl
+	}
+
+	return defaultLevel
 }
@@ -690,17 +690,6 @@ func chainConfig(name string) error {
 	return nil
 }
 
-func keybytesToHex(str []byte) []byte {
-	l := len(str)*2 + 1
-	var nibbles = make([]byte, l)
-	for i, b := range str {
-		nibbles[i*2] = b / 16
-		nibbles[i*2+1] = b % 16
-	}
-	nibbles[l-1] = 16
-	return nibbles
-}
-
 func iterate(filename string, prefix string) error {
 	pBytes := libcommon.FromHex(prefix)
 	efFilename := filename + ".ef"
@@ -0,0 +1,233 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/membatchwithdb"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	libstate "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/consensuschain"
+	"github.com/erigontech/erigon/eth/stagedsync"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var bisectReference string
+
+var cmdBisectRoot = &cobra.Command{
+	Use:   "bisect_root",
+	Short: "Re-execute block transaction by transaction and print the state root after each of them, to find the one which makes the root diverge",
+	Long: `Re-executes --block on top of the state of its parent (the execution stage is unwound in memory, nothing is committed)
+and computes the commitment root after each transaction with the commitment of the in-memory state, so the roots cost
+an incremental trie update each, not a rebuild of the trie. With --reference (a JSON array of the expected roots after each transaction,
+for example the result of "debug_intermediateRoots" of another client) reports the first transaction whose root differs.`,
+	Example: "go run ./cmd/integration bisect_root --datadir=... --block=N --reference=roots.json",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := bisectRoot(ctx, db, dirs, block, bisectReference, logger); err != nil {
+			logger.Error("bisect_root", "block", block, "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	withDataDir(cmdBisectRoot)
+	withBlock(cmdBisectRoot)
+	withHeimdall(cmdBisectRoot)
+	cmdBisectRoot.Flags().StringVar(&bisectReference, "reference", "", "JSON file with the expected state roots after each transaction of the block")
+	rootCmd.AddCommand(cmdBisectRoot)
+}
+
+func bisectRoot(ctx context.Context, db kv.TemporalRwDB, dirs datadir.Dirs, blockNum uint64, referenceFile string, logger log.Logger) error {
+	if blockNum == 0 {
+		return errors.New("--block must be greater than 0")
+	}
+	var reference []common.Hash
+	if referenceFile != "" {
+		data, err := os.ReadFile(referenceFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &reference); err != nil {
+			return fmt.Errorf("parsing %s: %w", referenceFile, err)
+		}
+	}
+
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	chainConfig := fromdb.ChainConfig(db)
+	engine, _ := initConsensusEngine(ctx, chainConfig, dirs.DataDir, db, br, logger)
+
+	b, err := br.BlockByNumber(ctx, tx, blockNum)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return fmt.Errorf("block %d not found", blockNum)
+	}
+	header := b.HeaderNoCopy()
+	if len(reference) > 0 && len(reference) != b.Transactions().Len() {
+		return fmt.Errorf("reference has %d roots, block %d has %d transactions", len(reference), blockNum, b.Transactions().Len())
+	}
+
+	execProgress, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return err
+	}
+	if execProgress+1 < blockNum {
+		return fmt.Errorf("state of block %d is not available: execution stage is at %d", blockNum-1, execProgress)
+	}
+
+	// all the changes (unwind, execution, commitment) stay in memory and are thrown away
+	batch := membatchwithdb.NewMemoryBatch(tx, dirs.Tmp, logger)
+	defer batch.Rollback()
+	if execProgress >= blockNum {
+		cfg := stagedsync.StageWitnessCfg(false, 0, chainConfig, engine, br, dirs)
+		if err := stagedsync.RewindStagesForWitness(batch, blockNum, execProgress, &cfg, false, ctx, logger); err != nil {
+			return fmt.Errorf("unwinding to block %d: %w", blockNum-1, err)
+		}
+	}
+
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, br))
+	minTxNum, err := txNumsReader.Min(batch, blockNum)
+	if err != nil {
+		return err
+	}
+
+	// the roots are computed by the commitment of these domains, not by a separate HexPatriciaHashed: it starts from the
+	// branches of the parent state and writes the branches it updates after every transaction into the in-memory batch,
+	// which is thrown away
+	domains, err := libstate.NewSharedDomains(batch, logger)
+	if err != nil {
+		return err
+	}
+	defer domains.Close()
+	domains.SetBlockNum(blockNum)
+	stateReader := state.NewReaderV3(domains)
+	stateWriter := state.NewWriterV4(domains)
+
+	parentRoot, err := domains.ComputeCommitment(ctx, false, blockNum-1, "bisect_root")
+	if err != nil {
+		return err
+	}
+	if parent, err := br.HeaderByNumber(ctx, tx, blockNum-1); err != nil {
+		return err
+	} else if parent != nil && common.BytesToHash(parentRoot) != parent.Root {
+		return fmt.Errorf("root of the state before block %d is %x, expected %x from the header of block %d", blockNum, parentRoot, parent.Root, blockNum-1)
+	}
+
+	chainReader := consensuschain.NewReader(chainConfig, batch, br, logger)
+	getHashFn := core.GetHashFn(header, func(hash common.Hash, number uint64) *types.Header {
+		h, _ := br.Header(ctx, batch, hash, number)
+		return h
+	})
+	ibs := state.New(stateReader)
+
+	domains.SetTxNum(minTxNum)
+	if err := core.InitializeBlockExecution(engine, chainReader, header, chainConfig, ibs, stateWriter, logger, nil); err != nil {
+		return err
+	}
+
+	var usedGas, usedBlobGas uint64
+	gp := new(core.GasPool).AddGas(header.GasLimit).AddBlobGas(chainConfig.GetMaxBlobGasPerBlock())
+	receipts := make(types.Receipts, 0, b.Transactions().Len())
+	divergedAt := -1
+	for i, txn := range b.Transactions() {
+		domains.SetTxNum(minTxNum + 1 + uint64(i)) // +1 for system txn in the beginning of block
+		ibs.SetTxContext(i)
+		receipt, _, err := core.ApplyTransaction(chainConfig, getHashFn, engine, nil, gp, ibs, stateWriter, header, txn, &usedGas, &usedBlobGas, vm.Config{})
+		if err != nil {
+			return fmt.Errorf("applying txn %d %x: %w", i, txn.Hash(), err)
+		}
+		receipts = append(receipts, receipt)
+
+		root, err := domains.ComputeCommitment(ctx, false, blockNum, "bisect_root")
+		if err != nil {
+			return err
+		}
+		line := fmt.Sprintf("txn %d %x root=%x gasUsed=%d status=%d", i, txn.Hash(), root, receipt.GasUsed, receipt.Status)
+		if len(reference) > 0 {
+			if common.BytesToHash(root) == reference[i] {
+				line += " ok"
+			} else {
+				line += fmt.Sprintf(" MISMATCH expected=%x", reference[i])
+				if divergedAt < 0 {
+					divergedAt = i
+				}
+			}
+		}
+		fmt.Println(line)
+	}
+
+	domains.SetTxNum(minTxNum + 1 + uint64(b.Transactions().Len()))
+	if _, _, _, _, err := core.FinalizeBlockExecution(engine, stateReader, header, b.Transactions(), b.Uncles(), stateWriter, chainConfig, ibs, receipts, b.Withdrawals(), chainReader, false, logger); err != nil {
+		return fmt.Errorf("finalizing block: %w", err)
+	}
+	root, err := domains.ComputeCommitment(ctx, false, blockNum, "bisect_root")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("block %d root=%x header.Root=%x gasUsed=%d header.GasUsed=%d\n", blockNum, root, header.Root, usedGas, header.GasUsed)
+
+	if divergedAt >= 0 {
+		logger.Warn("State root diverged", "block", blockNum, "txIndex", divergedAt, "txn", b.Transactions()[divergedAt].Hash())
+	} else if common.BytesToHash(root) != header.Root {
+		logger.Warn("State root doesn't match the header, the transactions roots match", "block", blockNum)
+	} else {
+		logger.Info("State root matches the header", "block", blockNum)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/eth/ethconfig/estimate"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var (
+	accessorsDomains []string
+	accessorsRebuild bool
+	accessorsForce   bool
+	accessorsWorkers int
+)
+
+var cmdCheckAccessors = &cobra.Command{
+	Use:     "check_accessors",
+	Short:   "Verify the .bt and .kvei accessors of domain files against their .kv files, optionally rebuild the broken ones",
+	Example: "go run ./cmd/integration check_accessors --datadir=... --domain=accounts,storage --rebuild",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		domains := make([]kv.Domain, 0, len(accessorsDomains))
+		for _, s := range accessorsDomains {
+			d, err := kv.String2Domain(s)
+			if err != nil {
+				logger.Error("Unknown domain", "domain", s, "error", err)
+				return
+			}
+			domains = append(domains, d)
+		}
+
+		dirs := datadir.New(datadirCli)
+		if accessorsRebuild || accessorsForce {
+			// the accessors are replaced under the open files, no node may be using the datadir meanwhile
+			_, lock, err := dirs.MustFlock()
+			if err != nil {
+				logger.Error("Rebuilding accessors needs the datadir not to be in use", "error", err)
+				return
+			}
+			defer lock.Unlock()
+		}
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+		sn, bsn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+		defer sn.Close()
+		defer bsn.Close()
+		defer agg.Close()
+
+		mismatches, err := agg.CheckBtreeAccessors(ctx, domains, accessorsRebuild, accessorsForce, accessorsWorkers)
+		for _, m := range mismatches {
+			fmt.Println(m.String())
+		}
+		if err != nil {
+			logger.Error("Checking accessors", "error", err)
+			os.Exit(1)
+		}
+		broken := 0
+		for _, m := range mismatches {
+			if !m.Rebuilt {
+				broken++
+			}
+		}
+		logger.Info("Checked accessors", "mismatches", len(mismatches), "broken", broken)
+		if broken > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	withDataDir(cmdCheckAccessors)
+	cmdCheckAccessors.Flags().StringSliceVar(&accessorsDomains, "domain", nil, "domains to check: accounts, storage, code, commitment, receipt (default all)")
+	cmdCheckAccessors.Flags().BoolVar(&accessorsRebuild, "rebuild", false, "rebuild the accessors which don't match their .kv files")
+	cmdCheckAccessors.Flags().BoolVar(&accessorsForce, "force", false, "rebuild all the accessors")
+	cmdCheckAccessors.Flags().IntVar(&accessorsWorkers, "workers", estimate.IndexSnapshot.Workers(), "files checked in parallel")
+	rootCmd.AddCommand(cmdCheckAccessors)
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var (
+	dumpStateOutput string
+	dumpStateVerify bool
+)
+
+var cmdDumpState = &cobra.Command{
+	Use:   "dump_state",
+	Short: "Dump the complete state as of a block, one JSON account per line, and verify it against the state root of the block",
+	Long: `Streams the accounts of the state as of the end of --block from the domains, ordered by address, each of them with its code
+and its storage ordered by key. The first line is the block number and its state root. With --verify the state root of the dump
+is computed and compared with the root of the block.`,
+	Example: "go run ./cmd/integration dump_state --datadir=... --block=N --output=state.jsonl --verify",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := dumpState(ctx, db, dirs, block, dumpStateOutput, dumpStateVerify, logger); err != nil {
+			logger.Error("dump_state", "block", block, "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	withDataDir(cmdDumpState)
+	withBlock(cmdDumpState)
+	cmdDumpState.Flags().StringVar(&dumpStateOutput, "output", "", "file to write the dump to, stdout if not set")
+	cmdDumpState.Flags().BoolVar(&dumpStateVerify, "verify", false, "compute the state root of the dump and compare it with the root of the block")
+	rootCmd.AddCommand(cmdDumpState)
+}
+
+func dumpState(ctx context.Context, db kv.TemporalRwDB, dirs datadir.Dirs, blockNum uint64, output string, verify bool, logger log.Logger) error {
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	header, err := br.HeaderByNumber(ctx, tx, blockNum)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		return fmt.Errorf("block %d not found", blockNum)
+	}
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, br))
+	dumper, err := state.NewStateDumper(tx, txNumsReader, blockNum)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(struct {
+		BlockNumber hexutil.Uint64 `json:"blockNumber"`
+		Root        common.Hash    `json:"root"`
+	}{hexutil.Uint64(blockNum), header.Root}); err != nil {
+		return err
+	}
+
+	var hasher *state.StateRootHasher
+	if verify {
+		hasher = state.NewStateRootHasher(dirs.Tmp, logger)
+		defer hasher.Close()
+	}
+	var accounts int
+	if _, err := dumper.Walk(nil, 0, false, func(account *state.DumpedAccount) error {
+		accounts++
+		if hasher != nil {
+			if err := hasher.Add(account); err != nil {
+				return err
+			}
+		}
+		return enc.Encode(account)
+	}); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	logger.Info("Dumped the state", "block", blockNum, "accounts", accounts)
+
+	if hasher == nil {
+		return nil
+	}
+	root, err := hasher.Root()
+	if err != nil {
+		return err
+	}
+	if root != header.Root {
+		return fmt.Errorf("state root of the dump is %x, expected %x from the header of block %d", root, header.Root, blockNum)
+	}
+	logger.Info("State root of the dump matches the header", "block", blockNum, "root", root)
+	return nil
+}
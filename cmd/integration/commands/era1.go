@@ -0,0 +1,405 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	chain2 "github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/core/rawdb"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/jsonrpc/receipts"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/era1"
+)
+
+var (
+	era1Dir       string
+	era1Network   string
+	era1FromEpoch uint64
+	era1ToEpoch   uint64
+)
+
+var cmdImportEra1 = &cobra.Command{
+	Use:   "import_era1",
+	Short: "Import the pre-merge blocks, receipts and total difficulties of era1 files, verified against their headers and the known chain",
+	Long: `Imports the era1 files of --era1.dir in epoch order. Every file is verified on its own (parent hashes, transactions, uncles
+and receipts roots, total difficulties and accumulator root) and against the chain of the node: blocks already known (in the
+database or in the snapshots) must have the same hash, and the other blocks must extend the headers. Receipts go to the legacy
+receipt tables, from which the custom trace stage fills the receipts domain without re-executing the blocks.`,
+	Example: "go run ./cmd/integration import_era1 --datadir=... --era1.dir=...",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := importEra1(ctx, db, era1Dir, era1Network, logger); err != nil {
+			logger.Error("import_era1", "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cmdExportEra1 = &cobra.Command{
+	Use:   "export_era1",
+	Short: "Export the pre-merge blocks of the node, from the database or the snapshots, to era1 files",
+	Long: `Writes one era1 file per epoch of 8192 blocks from --era1.from to --era1.to (excluded), stopping at the merge or at the
+end of the chain, the last file then holding fewer blocks. Receipts are read from the legacy receipt tables if there, and
+re-generated from the state history otherwise; the total difficulties are re-computed from the headers if not in the
+database. Every block is verified against its header before being written.`,
+	Example: "go run ./cmd/integration export_era1 --datadir=... --era1.dir=... --era1.to=10",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := exportEra1(ctx, db, dirs, era1Dir, era1Network, era1FromEpoch, era1ToEpoch, logger); err != nil {
+			logger.Error("export_era1", "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{cmdImportEra1, cmdExportEra1} {
+		withDataDir(cmd)
+		withHeimdall(cmd)
+		cmd.Flags().StringVar(&era1Dir, "era1.dir", "", "directory of the era1 files")
+		must(cmd.MarkFlagRequired("era1.dir"))
+		cmd.Flags().StringVar(&era1Network, "era1.network", "", "network name of the era1 files, the chain name of the node by default")
+		rootCmd.AddCommand(cmd)
+	}
+	cmdExportEra1.Flags().Uint64Var(&era1FromEpoch, "era1.from", 0, "first epoch to export")
+	cmdExportEra1.Flags().Uint64Var(&era1ToEpoch, "era1.to", 0, "epoch to stop the export at, excluded")
+	must(cmdExportEra1.MarkFlagRequired("era1.to"))
+}
+
+func importEra1(ctx context.Context, db kv.TemporalRwDB, dir, network string, logger log.Logger) error {
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	if network == "" {
+		network = fromdb.ChainConfig(db).ChainName
+	}
+	files, err := era1.Files(dir, network)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no %s era1 files in %s", network, dir)
+	}
+	for _, path := range files {
+		if err := importEra1File(ctx, db, br, path, logger); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+func importEra1File(ctx context.Context, db kv.RwDB, br services.FullBlockReader, path string, logger log.Logger) error {
+	e, err := era1.Open(path)
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the file must continue the known chain
+	var parentHash common.Hash
+	var parentTd *big.Int
+	if e.Start() == 0 {
+		parentTd = new(big.Int)
+	} else {
+		var ok bool
+		parentHash, ok, err = br.CanonicalHash(ctx, tx, e.Start()-1)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("parent block %d of the file is unknown, the era1 files must be imported in order", e.Start()-1)
+		}
+		// not in the database for blocks in the snapshots, only the total difficulties within the file are checked then
+		if parentTd, err = rawdb.ReadTd(tx, parentHash, e.Start()-1); err != nil {
+			return err
+		}
+	}
+	if _, _, err := e.Verify(parentHash, parentTd); err != nil {
+		return err
+	}
+
+	headersProgress, err := stages.GetStageProgress(tx, stages.Headers)
+	if err != nil {
+		return err
+	}
+	bodiesProgress, err := stages.GetStageProgress(tx, stages.Bodies)
+	if err != nil {
+		return err
+	}
+	var imported uint64
+	for number := e.Start(); number < e.Start()+e.Count(); number++ {
+		block, blockReceipts, td, err := e.Block(number)
+		if err != nil {
+			return err
+		}
+		canonical, ok, err := br.CanonicalHash(ctx, tx, number)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if canonical != block.Hash() {
+				return fmt.Errorf("block %d: hash %x, the canonical chain has %x", number, block.Hash(), canonical)
+			}
+			continue
+		}
+		if number != headersProgress+1 {
+			return fmt.Errorf("block %d does not extend the headers, at %d", number, headersProgress)
+		}
+		if bodiesProgress != headersProgress {
+			return fmt.Errorf("bodies at %d are behind the headers at %d, the bodies stage must be completed first", bodiesProgress, headersProgress)
+		}
+		bodiesProgress = number
+		if err := writeEra1Block(tx, block, blockReceipts, td); err != nil {
+			return err
+		}
+		headersProgress = number
+		imported++
+	}
+	if imported > 0 {
+		for _, stage := range []stages.SyncStage{stages.Headers, stages.BlockHashes, stages.Bodies} {
+			if err := stages.SaveStageProgress(tx, stage, headersProgress); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	logger.Info("Imported era1 file", "file", filepath.Base(path), "from", e.Start(), "blocks", e.Count(), "new", imported)
+	return nil
+}
+
+func writeEra1Block(tx kv.RwTx, block *types.Block, blockReceipts types.Receipts, td *big.Int) error {
+	hash, number := block.Hash(), block.NumberU64()
+	if err := rawdb.WriteHeader(tx, block.HeaderNoCopy()); err != nil {
+		return err
+	}
+	if err := rawdb.WriteTd(tx, hash, number, td); err != nil {
+		return err
+	}
+	if err := rawdb.WriteCanonicalHash(tx, hash, number); err != nil {
+		return err
+	}
+	if err := rawdb.WriteHeadHeaderHash(tx, hash); err != nil {
+		return err
+	}
+	// Check existence before write - because WriteRawBody isn't idempotent (it allocates new sequence range for transactions on every call)
+	ok, err := rawdb.WriteRawBodyIfNotExists(tx, hash, number, block.RawBody())
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := rawdb.AppendCanonicalTxNums(tx, number); err != nil {
+			return err
+		}
+	}
+	return rawdb.WriteReceipts(tx, number, blockReceipts)
+}
+
+func exportEra1(ctx context.Context, db kv.TemporalRwDB, dirs datadir.Dirs, dir, network string, fromEpoch, toEpoch uint64, logger log.Logger) error {
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	chainConfig := fromdb.ChainConfig(db)
+	if network == "" {
+		network = chainConfig.ChainName
+	}
+	engine, _ := initConsensusEngine(ctx, chainConfig, dirs.DataDir, db, br, logger)
+	generator := receipts.NewGenerator(br, engine)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	td, err := era1ParentTd(ctx, tx, br, fromEpoch*era1.MaxBlocks)
+	if err != nil {
+		return err
+	}
+	for epoch := fromEpoch; epoch < toEpoch; epoch++ {
+		var full bool
+		full, td, err = exportEra1Epoch(ctx, tx, br, generator, chainConfig, dir, network, epoch, td, logger)
+		if err != nil {
+			return fmt.Errorf("epoch %d: %w", epoch, err)
+		}
+		if !full {
+			break
+		}
+	}
+	return nil
+}
+
+// era1ParentTd returns the total difficulty of the parent of the block, re-computed from the headers if not in the
+// database
+func era1ParentTd(ctx context.Context, tx kv.Tx, br services.FullBlockReader, number uint64) (*big.Int, error) {
+	if number == 0 {
+		return new(big.Int), nil
+	}
+	header, err := br.HeaderByNumber(ctx, tx, number-1)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header %d not found", number-1)
+	}
+	td, err := rawdb.ReadTd(tx, header.Hash(), number-1)
+	if err != nil || td != nil {
+		return td, err
+	}
+	td = new(big.Int)
+	for n := uint64(0); n < number; n++ {
+		header, err := br.HeaderByNumber(ctx, tx, n)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("header %d not found", n)
+		}
+		td.Add(td, header.Difficulty)
+	}
+	return td, nil
+}
+
+// exportEra1Epoch writes the era1 file of the epoch and returns the total difficulty of its last block. The file holds
+// fewer blocks if the merge or the end of the chain is reached, in which case full is false.
+func exportEra1Epoch(ctx context.Context, tx kv.TemporalTx, br services.FullBlockReader, generator *receipts.Generator, chainConfig *chain2.Config,
+	dir, network string, epoch uint64, td *big.Int, logger log.Logger) (full bool, _ *big.Int, err error) {
+	f, err := os.CreateTemp(dir, "*.era1.tmp")
+	if err != nil {
+		return false, nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	builder := era1.NewBuilder(f)
+	td = new(big.Int).Set(td)
+	start, count := epoch*era1.MaxBlocks, uint64(0)
+	for ; count < era1.MaxBlocks; count++ {
+		block, err := br.BlockByNumber(ctx, tx, start+count)
+		if err != nil {
+			return false, nil, err
+		}
+		if block == nil || block.Difficulty().Sign() == 0 {
+			break
+		}
+		blockReceipts, err := era1Receipts(ctx, tx, generator, chainConfig, block)
+		if err != nil {
+			return false, nil, err
+		}
+		if err := era1.VerifyBlock(block, blockReceipts); err != nil {
+			return false, nil, err
+		}
+		td.Add(td, block.Difficulty())
+		if err := builder.Add(block, blockReceipts, td); err != nil {
+			return false, nil, err
+		}
+	}
+	if count == 0 {
+		return false, td, nil
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		return false, nil, err
+	}
+	if err := f.Sync(); err != nil {
+		return false, nil, err
+	}
+	name := era1.Filename(network, epoch, root)
+	if err := os.Rename(f.Name(), filepath.Join(dir, name)); err != nil {
+		return false, nil, err
+	}
+	logger.Info("Exported era1 file", "file", name, "from", start, "blocks", count)
+	return count == era1.MaxBlocks, td, nil
+}
+
+// era1Receipts reads the receipts of the block from the legacy receipt tables, or re-generates them from the state
+// history
+func era1Receipts(ctx context.Context, tx kv.TemporalTx, generator *receipts.Generator, chainConfig *chain2.Config, block *types.Block) (types.Receipts, error) {
+	if len(block.Transactions()) == 0 {
+		return types.Receipts{}, nil
+	}
+	if legacy := rawdb.ReadRawReceipts(tx, block.NumberU64()); len(legacy) == len(block.Transactions()) {
+		// the legacy tables keep neither the type nor the bloom of the receipts
+		for i, txn := range block.Transactions() {
+			legacy[i].Type = txn.Type()
+			legacy[i].Bloom = types.CreateBloom(types.Receipts{legacy[i]})
+		}
+		if types.DeriveSha(legacy) == block.ReceiptHash() {
+			return legacy, nil
+		}
+	}
+	blockReceipts, err := generator.GetReceipts(ctx, chainConfig, tx, block)
+	if err != nil {
+		return nil, err
+	}
+	if len(blockReceipts) != len(block.Transactions()) {
+		return nil, errors.New("receipts generation is incomplete")
+	}
+	return blockReceipts, nil
+}
@@ -0,0 +1,282 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/membatchwithdb"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	libstate "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/cmd/state/exec3"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/stagedsync"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var (
+	replayFile string
+	replayFrom uint64
+	replayTo   uint64
+)
+
+var cmdExecRecord = &cobra.Command{
+	Use:     "exec_record",
+	Short:   "Write the blocks [--replay.from, --replay.to) with their senders to a replay file, to execute them later with exec_replay",
+	Example: "go run ./cmd/integration exec_record --datadir=... --replay.from=N --replay.to=M --replay.file=blocks.replay",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := execRecord(ctx, db, replayFile, replayFrom, replayTo, logger); err != nil {
+			logger.Error("exec_record", "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cmdExecReplay = &cobra.Command{
+	Use:   "exec_replay",
+	Short: "Execute the blocks of a replay file on top of the state of the node and report the execution speed",
+	Long: `Builds the tasks of the Execution stage for every block of --replay.file and executes them one after another, checking
+the gas used and the receipts of every block. The state must be at the block preceding the first block of the file or later:
+the execution stage is unwound in memory if needed, and nothing is committed, so the same datadir can be used for any number
+of runs. Only the block hashes for BLOCKHASH come from the file, not from the node.`,
+	Example: "go run ./cmd/integration exec_replay --datadir=... --replay.file=blocks.replay",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := execReplay(ctx, db, dirs, replayFile, logger); err != nil {
+			logger.Error("exec_replay", "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{cmdExecRecord, cmdExecReplay} {
+		withDataDir(cmd)
+		cmd.Flags().StringVar(&replayFile, "replay.file", "", "path of the replay file")
+		must(cmd.MarkFlagRequired("replay.file"))
+		rootCmd.AddCommand(cmd)
+	}
+	withHeimdall(cmdExecReplay)
+	cmdExecRecord.Flags().Uint64Var(&replayFrom, "replay.from", 0, "first block to record")
+	cmdExecRecord.Flags().Uint64Var(&replayTo, "replay.to", 0, "block to stop the recording at, excluded")
+	must(cmdExecRecord.MarkFlagRequired("replay.from"))
+	must(cmdExecRecord.MarkFlagRequired("replay.to"))
+}
+
+func execRecord(ctx context.Context, db kv.TemporalRwDB, path string, from, to uint64, logger log.Logger) (err error) {
+	if from == 0 || to <= from {
+		return fmt.Errorf("invalid block range [%d, %d)", from, to)
+	}
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+	chainConfig := fromdb.ChainConfig(db)
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ancestorHashes := make([]common.Hash, 0, 256)
+	for n := from - min(from, 256); n < from; n++ {
+		hash, ok, err := br.CanonicalHash(ctx, tx, n)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("no canonical hash of block %d", n)
+		}
+		ancestorHashes = append(ancestorHashes, hash)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	bw := bufio.NewWriter(f)
+	w, err := exec3.NewReplayWriter(bw, chainConfig.ChainName, from, ancestorHashes)
+	if err != nil {
+		return err
+	}
+
+	var txs int
+	for blockNum := from; blockNum < to; blockNum++ {
+		hash, ok, err := br.CanonicalHash(ctx, tx, blockNum)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			logger.Info("[replay] end of the chain", "block", blockNum)
+			break
+		}
+		block, senders, err := br.BlockWithSenders(ctx, tx, hash, blockNum)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return fmt.Errorf("block %d not found", blockNum)
+		}
+		if len(senders) == len(block.Transactions()) {
+			block.SendersToTxs(senders)
+		}
+		signer := types.MakeSigner(chainConfig, blockNum, block.Time())
+		for i, txn := range block.Transactions() {
+			if _, ok := txn.GetSender(); ok {
+				continue
+			}
+			if _, err := txn.Sender(*signer); err != nil {
+				return fmt.Errorf("block %d, txn %d: %w", blockNum, i, err)
+			}
+		}
+		if err := w.Append(block); err != nil {
+			return err
+		}
+		txs += len(block.Transactions())
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	logger.Info("[replay] recorded", "file", path, "from", from, "to", to, "txs", txs)
+	return nil
+}
+
+func execReplay(ctx context.Context, db kv.TemporalRwDB, dirs datadir.Dirs, path string, logger log.Logger) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := exec3.NewReplayReader(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	firstBlock := r.Header.FirstBlock
+	if firstBlock == 0 {
+		return errors.New("replay of the genesis block is not supported")
+	}
+
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	chainConfig := fromdb.ChainConfig(db)
+	if r.Header.ChainName != chainConfig.ChainName {
+		return fmt.Errorf("replay file of chain %s, node of chain %s", r.Header.ChainName, chainConfig.ChainName)
+	}
+	engine, _ := initConsensusEngine(ctx, chainConfig, dirs.DataDir, db, br, logger)
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	execProgress, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return err
+	}
+	if execProgress+1 < firstBlock {
+		return fmt.Errorf("state of block %d is not available: execution stage is at %d", firstBlock-1, execProgress)
+	}
+
+	// all the changes (unwind and execution) stay in memory and are thrown away
+	batch := membatchwithdb.NewMemoryBatch(tx, dirs.Tmp, logger)
+	defer batch.Rollback()
+	if execProgress >= firstBlock {
+		cfg := stagedsync.StageWitnessCfg(false, 0, chainConfig, engine, br, dirs)
+		if err := stagedsync.RewindStagesForWitness(batch, firstBlock, execProgress, &cfg, false, ctx, logger); err != nil {
+			return fmt.Errorf("unwinding to block %d: %w", firstBlock-1, err)
+		}
+	}
+
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, br))
+	lastTxNum, err := txNumsReader.Max(batch, firstBlock-1)
+	if err != nil {
+		return err
+	}
+
+	domains, err := libstate.NewSharedDomains(batch, logger)
+	if err != nil {
+		return err
+	}
+	defer domains.Close()
+	rs := state.NewStateV3(domains, logger)
+
+	worker := exec3.NewWorker(&sync.RWMutex{}, logger, ctx, false /* background */, db, nil, br, chainConfig, nil, nil, engine, dirs, false /* isMining */)
+	worker.ResetState(rs, nil)
+	worker.ResetTx(batch)
+
+	stats, err := exec3.Replay(ctx, r, worker, rs, batch, chainConfig, engine, lastTxNum+1, logger)
+	if err != nil {
+		return err
+	}
+	logger.Info("[replay] done", "from", firstBlock, "to", stats.LastBlock, "blocks", stats.Blocks, "txs", stats.Txs,
+		"took", stats.Took, "Mgas/s", fmt.Sprintf("%.1f", float64(stats.Gas)/1e6/stats.Took.Seconds()),
+		"blk/s", fmt.Sprintf("%.1f", float64(stats.Blocks)/stats.Took.Seconds()))
+	return nil
+}
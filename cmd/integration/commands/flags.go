@@ -76,6 +76,7 @@ func withMining(cmd *cobra.Command) {
 	cmd.Flags().String("miner.etherbase", "0", "Public address for block mining rewards (default = first account")
 	cmd.Flags().String("miner.extradata", "", "Block extra data set by the miner (default = client version)")
 	cmd.Flags().Duration("miner.recommit", ethconfig.Defaults.Miner.Recommit, "Time interval to recreate the block being mined")
+	cmd.Flags().Duration("miner.buildlead", ethconfig.Defaults.Miner.BuildLead, "Bor: how long before the producer slot of the node to start building the block")
 	cmd.Flags().Bool("miner.noverify", false, "Disable remote sealing verification")
 }
 
@@ -0,0 +1,92 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/phase1/execution_client/rpc_helper"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
+)
+
+var (
+	engineJournalDir string
+	engineURL        string
+	engineJWTPath    string
+)
+
+var cmdReplayEngine = &cobra.Command{
+	Use:   "replay_engine",
+	Short: "Replay an engine API journal (recorded with --engine.journal.dir) against the engine API of a fresh node and report the calls with a different outcome",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		entries, err := engine_journal.ReadEntries(engineJournalDir)
+		if err != nil {
+			logger.Error("Reading engine journal", "error", err)
+			return
+		}
+		data, err := os.ReadFile(engineJWTPath)
+		if err != nil {
+			logger.Error("Reading jwt secret", "error", err)
+			return
+		}
+		jwtSecret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(jwtSecret) != 32 {
+			logger.Error("Invalid jwt secret", "path", engineJWTPath, "length", len(jwtSecret))
+			return
+		}
+		client, err := rpc.DialHTTPWithClient(engineURL, &http.Client{Transport: rpc_helper.NewJWTRoundTripper(jwtSecret)}, logger)
+		if err != nil {
+			logger.Error("Connecting to engine API", "error", err)
+			return
+		}
+		defer client.Close()
+
+		logger.Info("Replaying engine journal", "entries", len(entries), "engine", engineURL)
+		mismatches, err := engine_journal.Replay(ctx, client, entries, logger)
+		if err != nil {
+			logger.Error("Replaying engine journal", "error", err)
+			return
+		}
+		for _, mismatch := range mismatches {
+			fmt.Println(mismatch)
+		}
+		if len(mismatches) > 0 {
+			logger.Error("Engine journal replay diverged", "mismatches", len(mismatches))
+			os.Exit(1)
+		}
+		logger.Info("Engine journal replayed without mismatches")
+	},
+}
+
+func init() {
+	cmdReplayEngine.Flags().StringVar(&engineJournalDir, "journal.dir", "", "directory of the engine API journal to replay")
+	must(cmdReplayEngine.MarkFlagRequired("journal.dir"))
+	cmdReplayEngine.Flags().StringVar(&engineURL, "engine.url", "http://localhost:8551", "authenticated engine API endpoint of the node to replay against")
+	cmdReplayEngine.Flags().StringVar(&engineJWTPath, "authrpc.jwtsecret", "jwt.hex", "path to the jwt secret of the engine API")
+	rootCmd.AddCommand(cmdReplayEngine)
+}
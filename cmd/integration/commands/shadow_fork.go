@@ -0,0 +1,144 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var (
+	shadowForkChainID uint64
+	shadowForkOutput  string
+)
+
+var cmdShadowForkGenesis = &cobra.Command{
+	Use:   "shadow_fork_genesis",
+	Short: "Write the genesis of a new chain starting from the state of a block, to spin shadow forks and test networks from it",
+	Long: `Writes a genesis JSON whose alloc is the complete state as of the end of --block (balances, nonces, code and storage)
+and whose chain config is the one of the datadir with --chain.id: the forks active at the block are active since the genesis,
+the later block forks are moved back by the number of the block. The state of the consensus engine (bor spans, aura validator
+contracts configured by block) is not carried over. The genesis is loaded into memory whole by init, so this is meant for
+the state of devnets and smaller test networks, not for mainnet-sized states.`,
+	Example: "go run ./cmd/integration shadow_fork_genesis --datadir=... --block=N --chain.id=1337 --output=genesis.json",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := shadowForkGenesis(ctx, db, block, shadowForkChainID, shadowForkOutput, logger); err != nil {
+			logger.Error("shadow_fork_genesis", "block", block, "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	withDataDir(cmdShadowForkGenesis)
+	withBlock(cmdShadowForkGenesis)
+	cmdShadowForkGenesis.Flags().Uint64Var(&shadowForkChainID, "chain.id", 0, "chain id of the new chain")
+	must(cmdShadowForkGenesis.MarkFlagRequired("chain.id"))
+	cmdShadowForkGenesis.Flags().StringVar(&shadowForkOutput, "output", "", "file to write the genesis to, stdout if not set")
+	rootCmd.AddCommand(cmdShadowForkGenesis)
+}
+
+func shadowForkGenesis(ctx context.Context, db kv.TemporalRwDB, blockNum, chainID uint64, output string, logger log.Logger) error {
+	chainConfig := fromdb.ChainConfig(db)
+	if chainConfig.ChainID != nil && chainConfig.ChainID.Uint64() == chainID {
+		return errors.New("--chain.id must differ from the chain id of the datadir")
+	}
+
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	header, err := br.HeaderByNumber(ctx, tx, blockNum)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		return fmt.Errorf("block %d not found", blockNum)
+	}
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, br))
+	dumper, err := state.NewStateDumper(tx, txNumsReader, blockNum)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	var accounts int
+	genesis := core.ShadowForkGenesis(chainConfig, header, new(big.Int).SetUint64(chainID))
+	if err := core.WriteGenesisJSON(bw, genesis, func(add func(common.Address, *types.GenesisAccount) error) error {
+		_, err := dumper.Walk(nil, 0, false, func(account *state.DumpedAccount) error {
+			accounts++
+			return add(account.Address, account.GenesisAccount())
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	logger.Info("Wrote the shadow fork genesis", "block", blockNum, "root", header.Root, "chainId", chainID, "accounts", accounts)
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/membatchwithdb"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/stagedsync"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var stateRootCheckTo uint64
+
+var cmdStateRootCheck = &cobra.Command{
+	Use:   "state_root_check",
+	Short: "Execute a range of blocks in dry-run mode and compare the computed state roots with the roots of their headers",
+	Long: `Executes the blocks [--block, --to] on top of the state of block --block-1 (the execution stage is unwound in memory if it is
+further) and computes the state root after each block. The execution and the commitment go into a throwaway overlay, nothing is
+written to the database. Stops at the first block whose root differs from its header. Useful to validate snapshots, or a change
+of the execution code, against the known roots.`,
+	Example: "go run ./cmd/integration state_root_check --datadir=... --block=N --to=M",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		dirs := datadir.New(datadirCli)
+		db, err := openDB(dbCfg(kv.ChainDB, dirs.Chaindata), true, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		mismatch, err := stateRootCheck(ctx, db, dirs, block, stateRootCheckTo, logger)
+		if err != nil {
+			logger.Error("state_root_check", "block", block, "to", stateRootCheckTo, "error", err)
+			os.Exit(1)
+		}
+		if mismatch > 0 {
+			logger.Warn("State root doesn't match the header", "block", mismatch)
+			os.Exit(1)
+		}
+		logger.Info("State roots match the headers", "from", block, "to", max(block, stateRootCheckTo))
+	},
+}
+
+func init() {
+	withDataDir(cmdStateRootCheck)
+	withBlock(cmdStateRootCheck)
+	withHeimdall(cmdStateRootCheck)
+	cmdStateRootCheck.Flags().Uint64Var(&stateRootCheckTo, "to", 0, "last block of the range to check, --block if not set")
+	rootCmd.AddCommand(cmdStateRootCheck)
+}
+
+// stateRootCheck returns the first block of [from, to] whose computed state root differs from its header, 0 if none
+func stateRootCheck(ctx context.Context, db kv.TemporalRwDB, dirs datadir.Dirs, from, to uint64, logger log.Logger) (uint64, error) {
+	if from == 0 {
+		return 0, errors.New("--block must be greater than 0")
+	}
+	to = max(from, to)
+
+	sn, borSn, agg, _, _, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	chainConfig := fromdb.ChainConfig(db)
+	engine, _ := initConsensusEngine(ctx, chainConfig, dirs.DataDir, db, br, logger)
+
+	execProgress, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return 0, err
+	}
+	if execProgress+1 < from {
+		return 0, fmt.Errorf("state of block %d is not available: execution stage is at %d", from-1, execProgress)
+	}
+
+	// the unwind stays in memory and is thrown away, as everything the dry run executes
+	batch := membatchwithdb.NewMemoryBatch(tx, dirs.Tmp, logger)
+	defer batch.Rollback()
+	if execProgress >= from {
+		cfg := stagedsync.StageWitnessCfg(false, 0, chainConfig, engine, br, dirs)
+		if err := stagedsync.RewindStagesForWitness(batch, from, execProgress, &cfg, false, ctx, logger); err != nil {
+			return 0, fmt.Errorf("unwinding to block %d: %w", from-1, err)
+		}
+	}
+
+	cfg := stagedsync.StageExecuteBlocksCfg(db, fromdb.PruneMode(db), 0, chainConfig, engine, &vm.Config{}, nil,
+		/*stateStream=*/ false,
+		/*badBlockHalt=*/ true,
+		dirs, br, nil, nil, syncCfg, nil, nil)
+	return stagedsync.ExecDryRun(ctx, batch, cfg, from, to, func(header *types.Header, root common.Hash) {
+		status := "ok"
+		if root != header.Root {
+			status = fmt.Sprintf("MISMATCH expected=%x", header.Root)
+		}
+		fmt.Printf("block %d root=%x %s\n", header.Number.Uint64(), root, status)
+	}, logger)
+}
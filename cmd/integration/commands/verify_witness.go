@@ -0,0 +1,90 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/ethconsensusconfig"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var (
+	witnessBlockPath string
+	witnessPath      string
+)
+
+var cmdVerifyWitness = &cobra.Command{
+	Use:   "verify_witness",
+	Short: "Execute a block on top of an execution witness (as returned by debug_executionWitness), without any database, and check its post-state root",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		chainConfig := params.ChainConfigByChainName(chain)
+		if chainConfig == nil {
+			logger.Error("Unknown chain", "chain", chain)
+			return
+		}
+		data, err := os.ReadFile(witnessBlockPath)
+		if err != nil {
+			logger.Error("Reading block", "error", err)
+			return
+		}
+		block := new(types.Block)
+		if err := rlp.DecodeBytes(common.FromHex(strings.TrimSpace(string(data))), block); err != nil {
+			logger.Error("Decoding block", "error", err)
+			return
+		}
+		data, err = os.ReadFile(witnessPath)
+		if err != nil {
+			logger.Error("Reading witness", "error", err)
+			return
+		}
+		witness := new(types.ExecutionWitness)
+		if err := json.Unmarshal(data, witness); err != nil {
+			logger.Error("Decoding witness", "error", err)
+			return
+		}
+
+		engine := ethconsensusconfig.CreateConsensusEngineBareBones(ctx, chainConfig, logger)
+		res, err := core.ExecuteBlockWithWitness(chainConfig, engine, block, witness, logger)
+		if err != nil {
+			logger.Error("Stateless verification failed", "block", block.NumberU64(), "hash", block.Hash(), "error", err)
+			os.Exit(1)
+		}
+		logger.Info("Stateless verification succeeded", "block", block.NumberU64(), "hash", block.Hash(), "root", res.StateRoot, "gasUsed", uint64(res.GasUsed))
+	},
+}
+
+func init() {
+	withChain(cmdVerifyWitness)
+	cmdVerifyWitness.Flags().StringVar(&witnessBlockPath, "block", "", "file with the hex encoded rlp of the block (as returned by debug_getRawBlock)")
+	must(cmdVerifyWitness.MarkFlagRequired("block"))
+	cmdVerifyWitness.Flags().StringVar(&witnessPath, "witness", "", "json file with the execution witness of the block (as returned by debug_executionWitness)")
+	must(cmdVerifyWitness.MarkFlagRequired("witness"))
+	rootCmd.AddCommand(cmdVerifyWitness)
+}
@@ -134,6 +134,7 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().StringVar(&cfg.TxPoolApiAddr, "txpool.api.addr", "", "txpool api network address, for example: 127.0.0.1:9090 (default: use value of --private.api.addr)")
 
 	rootCmd.PersistentFlags().StringVar(&stateCacheStr, "state.cache", "0MB", "Amount of data to store in StateCache (enabled if no --datadir set). Set 0 to disable StateCache. Defaults to 0MB RAM")
+	rootCmd.PersistentFlags().Uint64Var(&cfg.StateCache.KeepViews, "state.cache.views", kvcache.DefaultCoherentConfig.KeepViews, "Amount of recent blocks the StateCache keeps a view of. Requests on older blocks read the db directly")
 	rootCmd.PersistentFlags().BoolVar(&cfg.GRPCServerEnabled, "grpc", false, "Enable GRPC server")
 	rootCmd.PersistentFlags().StringVar(&cfg.GRPCListenAddress, "grpc.addr", nodecfg.DefaultGRPCHost, "GRPC server listening interface")
 	rootCmd.PersistentFlags().IntVar(&cfg.GRPCPort, "grpc.port", nodecfg.DefaultGRPCPort, "GRPC server listening port")
@@ -179,6 +180,8 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxTopics, "rpc.subscription.filters.maxtopics", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxTopics, "Maximum number of topics per subscription to filter logs by.")
 	rootCmd.PersistentFlags().IntVar(&cfg.BatchLimit, utils.RpcBatchLimit.Name, utils.RpcBatchLimit.Value, utils.RpcBatchLimit.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.ReturnDataLimit, utils.RpcReturnDataLimit.Name, utils.RpcReturnDataLimit.Value, utils.RpcReturnDataLimit.Usage)
+	rootCmd.PersistentFlags().IntVar(&cfg.ResponseMaxBytes, utils.RpcResponseMaxBytes.Name, utils.RpcResponseMaxBytes.Value, utils.RpcResponseMaxBytes.Usage)
+	rootCmd.PersistentFlags().DurationVar(&cfg.HeavyTimeout, utils.RpcHeavyTimeout.Name, utils.RpcHeavyTimeout.Value, utils.RpcHeavyTimeout.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.AllowUnprotectedTxs, utils.AllowUnprotectedTxs.Name, utils.AllowUnprotectedTxs.Value, utils.AllowUnprotectedTxs.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.MaxGetProofRewindBlockCount, utils.RpcMaxGetProofRewindBlockCount.Name, utils.RpcMaxGetProofRewindBlockCount.Value, utils.RpcMaxGetProofRewindBlockCount.Usage)
 	rootCmd.PersistentFlags().Uint64Var(&cfg.OtsMaxPageSize, utils.OtsSearchMaxCapFlag.Name, utils.OtsSearchMaxCapFlag.Value, utils.OtsSearchMaxCapFlag.Usage)
@@ -203,6 +206,9 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 		if err != nil {
 			return fmt.Errorf("state.cache value of %v is not valid", stateCacheStr)
 		}
+		if cfg.StateCache.KeepViews == 0 {
+			return errors.New("state.cache.views must be positive")
+		}
 
 		cfg.WithDatadir = cfg.DataDir != ""
 		if cfg.WithDatadir {
@@ -675,6 +681,7 @@ func startRegularRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []r
 	srv.SetAllowList(allowListForRPC)
 
 	srv.SetBatchLimit(cfg.BatchLimit)
+	srv.SetHeavyMethodLimits(rpc.HeavyMethodLimits{MaxResponseBytes: cfg.ResponseMaxBytes, Timeout: cfg.HeavyTimeout})
 
 	defer srv.Stop()
 
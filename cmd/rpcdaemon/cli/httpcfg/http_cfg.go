@@ -95,12 +95,14 @@ type HttpCfg struct {
 	EvmCallTimeout            time.Duration
 	OverlayGetLogsTimeout     time.Duration
 	OverlayReplayBlockTimeout time.Duration
+	HeavyTimeout              time.Duration // Deadline of the requests to rpccfg.HeavyMethods
 
 	LogDirVerbosity string
 	LogDirPath      string
 
 	BatchLimit                  int  // Maximum number of requests in a batch
 	ReturnDataLimit             int  // Maximum number of bytes returned from calls (like eth_call)
+	ResponseMaxBytes            int  // Maximum size of the response of rpccfg.HeavyMethods
 	AllowUnprotectedTxs         bool // Whether to allow non EIP-155 protected transactions  txs over RPC
 	MaxGetProofRewindBlockCount int  //Max GetProof rewind block count
 	// Ots API
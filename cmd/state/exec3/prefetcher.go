@@ -0,0 +1,197 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package exec3
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"golang.org/x/sync/errgroup"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/txnprovider"
+)
+
+const (
+	prefetchInterval = 500 * time.Millisecond
+	prefetchTxns     = 1_000 // number of best pending transactions warmed per round
+)
+
+// Prefetcher warms the state which the next blocks are likely to read: the accounts, code and storage
+// referenced by the best pending transactions of the txpool (senders, recipients and access lists).
+// It runs while the node waits for the next block and is paused during block execution, so that
+// it only competes with execution for idle IO.
+type Prefetcher struct {
+	db      kv.TemporalRoDB
+	txns    txnprovider.TxnProvider
+	workers int
+	logger  log.Logger
+
+	paused  atomic.Int32
+	trigger chan struct{}
+	warmed  map[libcommon.Hash]struct{} // transactions warmed by the last complete round, only used by Run
+}
+
+type prefetchHint struct {
+	code bool // the account is called, its code will be read
+	keys map[libcommon.Hash]struct{}
+}
+
+func NewPrefetcher(db kv.TemporalRoDB, txns txnprovider.TxnProvider, workers int, logger log.Logger) *Prefetcher {
+	return &Prefetcher{
+		db:      db,
+		txns:    txns,
+		workers: max(workers, 1),
+		logger:  logger,
+		trigger: make(chan struct{}, 1),
+		warmed:  map[libcommon.Hash]struct{}{},
+	}
+}
+
+// Pause stops warming until the returned resume func is called, e.g. for the duration of block execution.
+// A new round starts on resume since the executed block changes the pending transactions.
+func (p *Prefetcher) Pause() (resume func()) {
+	p.paused.Add(1)
+	return func() {
+		if p.paused.Add(-1) == 0 {
+			select {
+			case p.trigger <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (p *Prefetcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(prefetchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-p.trigger:
+		}
+		if p.paused.Load() > 0 {
+			continue
+		}
+		if err := p.prefetch(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			p.logger.Debug("[prefetch] round failed", "err", err)
+		}
+	}
+}
+
+func (p *Prefetcher) prefetch(ctx context.Context) error {
+	// each round looks at the current best transactions, so it doesn't share the filter of the yielded ones
+	txns, err := p.txns.ProvideTxns(ctx, txnprovider.WithAmount(prefetchTxns), txnprovider.WithTxnIdsFilter(mapset.NewSet[[32]byte]()))
+	if err != nil {
+		return err
+	}
+
+	warmed := make(map[libcommon.Hash]struct{}, len(txns))
+	hints := map[libcommon.Address]*prefetchHint{}
+	hint := func(addr libcommon.Address) *prefetchHint {
+		h, ok := hints[addr]
+		if !ok {
+			h = &prefetchHint{keys: map[libcommon.Hash]struct{}{}}
+			hints[addr] = h
+		}
+		return h
+	}
+	for _, txn := range txns {
+		txnHash := txn.Hash()
+		warmed[txnHash] = struct{}{}
+		if _, ok := p.warmed[txnHash]; ok {
+			continue
+		}
+		if sender, ok := txn.GetSender(); ok {
+			hint(sender)
+		}
+		if to := txn.GetTo(); to != nil {
+			hint(*to).code = true
+		}
+		for _, tuple := range txn.GetAccessList() {
+			h := hint(tuple.Address)
+			for _, key := range tuple.StorageKeys {
+				h.keys[key] = struct{}{}
+			}
+		}
+	}
+	if len(hints) == 0 {
+		p.warmed = warmed
+		return nil
+	}
+
+	addrs := make(chan libcommon.Address, len(hints))
+	for addr := range hints {
+		addrs <- addr
+	}
+	close(addrs)
+
+	var interrupted atomic.Bool
+	g, gctx := errgroup.WithContext(ctx)
+	for i := 0; i < p.workers; i++ {
+		g.Go(func() error {
+			tx, err := p.db.BeginTemporalRo(gctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+			reader := state.NewReaderV3(tx)
+			for addr := range addrs {
+				if p.paused.Load() > 0 {
+					interrupted.Store(true)
+					return nil
+				}
+				if err := gctx.Err(); err != nil {
+					return err
+				}
+				h := hints[addr]
+				if _, err := reader.ReadAccountData(addr); err != nil {
+					return err
+				}
+				if h.code {
+					if _, err := reader.ReadAccountCode(addr, 0); err != nil {
+						return err
+					}
+				}
+				for key := range h.keys {
+					if _, err := reader.ReadAccountStorage(addr, 0, &key); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if !interrupted.Load() {
+		p.warmed = warmed
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package exec3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/txnprovider"
+)
+
+// testTxnProvider yields its transactions like the txpool: the ones in the filter are skipped, the yielded ones are
+// added to it.
+type testTxnProvider struct {
+	txns  []types.Transaction
+	calls int
+}
+
+func (p *testTxnProvider) ProvideTxns(_ context.Context, opts ...txnprovider.ProvideOption) ([]types.Transaction, error) {
+	provideOpts := txnprovider.ApplyProvideOptions(opts...)
+	p.calls++
+	var txns []types.Transaction
+	for _, txn := range p.txns {
+		if len(txns) == provideOpts.Amount || provideOpts.TxnIdsFilter.Contains(txn.Hash()) {
+			continue
+		}
+		provideOpts.TxnIdsFilter.Add(txn.Hash())
+		txns = append(txns, txn)
+	}
+	return txns, nil
+}
+
+func newPrefetchTestTxn(nonce uint64, to libcommon.Address) types.Transaction {
+	txn := &types.AccessListTx{
+		LegacyTx: types.LegacyTx{
+			CommonTx: types.CommonTx{Nonce: nonce, Gas: 50_000, To: &to, Value: uint256.NewInt(0)},
+			GasPrice: uint256.NewInt(1),
+		},
+		ChainID:    uint256.NewInt(1),
+		AccessList: types.AccessList{{Address: libcommon.Address{9}, StorageKeys: []libcommon.Hash{{1}}}},
+	}
+	txn.SetSender(libcommon.Address{2})
+	return txn
+}
+
+func TestPrefetcherRounds(t *testing.T) {
+	db, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	txns := &testTxnProvider{txns: []types.Transaction{
+		newPrefetchTestTxn(0, libcommon.Address{3}),
+		newPrefetchTestTxn(1, libcommon.Address{4}),
+	}}
+	p := NewPrefetcher(db, txns, 2, log.New())
+	ctx := context.Background()
+	warmed := func() map[libcommon.Hash]struct{} {
+		hashes := map[libcommon.Hash]struct{}{}
+		for _, txn := range txns.txns {
+			hashes[txn.Hash()] = struct{}{}
+		}
+		return hashes
+	}
+
+	require.NoError(t, p.prefetch(ctx))
+	require.Equal(t, warmed(), p.warmed)
+	// every round gets the best transactions again, the yielded ones aren't filtered out across rounds
+	require.NoError(t, p.prefetch(ctx))
+	require.Equal(t, warmed(), p.warmed)
+	require.Equal(t, 2, txns.calls)
+
+	// a round interrupted by a pause doesn't count its transactions as warmed
+	before := warmed()
+	txns.txns = append(txns.txns, newPrefetchTestTxn(2, libcommon.Address{5}))
+	resume := p.Pause()
+	require.NoError(t, p.prefetch(ctx))
+	require.Equal(t, before, p.warmed)
+
+	// resuming starts a new round
+	resume()
+	select {
+	case <-p.trigger:
+	default:
+		t.Fatal("no round triggered on resume")
+	}
+	require.NoError(t, p.prefetch(ctx))
+	require.Equal(t, warmed(), p.warmed)
+}
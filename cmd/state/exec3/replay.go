@@ -0,0 +1,254 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package exec3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// Replay file: blocks with their senders, enough to build the tasks of the Execution stage (BlockTxTasks) for them
+// without a node.
+//
+//	magic | zstd( rlp(ReplayHeader) | rlp(block_1) rlp(senders_1) | ... | rlp(block_n) rlp(senders_n) )
+//
+// Blocks are consecutive. Executing them needs the state right before the first block.
+var replayFileMagic = []byte("erigon-exec-replay")
+
+const ReplayFileVersion = 1
+
+type ReplayHeader struct {
+	Version    uint64
+	ChainName  string
+	FirstBlock uint64
+	// hashes of up to 256 blocks preceding the first one (for BLOCKHASH), oldest first
+	AncestorHashes []libcommon.Hash
+}
+
+type ReplayWriter struct {
+	zw *zstd.Encoder
+}
+
+func NewReplayWriter(w io.Writer, chainName string, firstBlock uint64, ancestorHashes []libcommon.Hash) (*ReplayWriter, error) {
+	if _, err := w.Write(replayFileMagic); err != nil {
+		return nil, err
+	}
+	zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestorHashes) > 256 {
+		ancestorHashes = ancestorHashes[len(ancestorHashes)-256:]
+	}
+	header := ReplayHeader{Version: ReplayFileVersion, ChainName: chainName, FirstBlock: firstBlock, AncestorHashes: ancestorHashes}
+	if err := rlp.Encode(zw, &header); err != nil {
+		return nil, err
+	}
+	return &ReplayWriter{zw: zw}, nil
+}
+
+// Append adds the block, its transactions must have the senders
+func (w *ReplayWriter) Append(block *types.Block) error {
+	senders := block.Body().SendersFromTxs()
+	for i, sender := range senders {
+		if sender == (libcommon.Address{}) {
+			return fmt.Errorf("block %d: no sender of txn %d", block.NumberU64(), i)
+		}
+	}
+	if err := rlp.Encode(w.zw, block); err != nil {
+		return err
+	}
+	return rlp.Encode(w.zw, senders)
+}
+
+func (w *ReplayWriter) Close() error {
+	return w.zw.Close()
+}
+
+type ReplayReader struct {
+	Header ReplayHeader
+
+	zr     *zstd.Decoder
+	s      *rlp.Stream
+	next   uint64 // number of the block expected next, 0 - not known yet
+	hashes map[uint64]libcommon.Hash
+}
+
+func NewReplayReader(r io.Reader) (*ReplayReader, error) {
+	magic := make([]byte, len(replayFileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("reading magic: %w", err)
+	}
+	if !bytes.Equal(magic, replayFileMagic) {
+		return nil, errors.New("not a replay file")
+	}
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	rr := &ReplayReader{zr: zr, s: rlp.NewStream(zr, 0), hashes: map[uint64]libcommon.Hash{}}
+	if err := rr.s.Decode(&rr.Header); err != nil {
+		zr.Close()
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if rr.Header.Version != ReplayFileVersion {
+		zr.Close()
+		return nil, fmt.Errorf("unsupported replay file version %d", rr.Header.Version)
+	}
+	return rr, nil
+}
+
+// Next returns the next block with the senders set, io.EOF after the last one
+func (r *ReplayReader) Next() (*types.Block, error) {
+	block := new(types.Block)
+	if err := r.s.Decode(block); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading block: %w", err)
+	}
+	var senders []libcommon.Address
+	if err := r.s.Decode(&senders); err != nil {
+		return nil, fmt.Errorf("reading senders of block %d: %w", block.NumberU64(), err)
+	}
+	if len(senders) != len(block.Transactions()) {
+		return nil, fmt.Errorf("block %d: %d senders for %d txs", block.NumberU64(), len(senders), len(block.Transactions()))
+	}
+	block.SendersToTxs(senders)
+
+	blockNum := block.NumberU64()
+	if r.next == 0 {
+		if blockNum != r.Header.FirstBlock {
+			return nil, fmt.Errorf("expected block %d, got %d", r.Header.FirstBlock, blockNum)
+		}
+		if blockNum < uint64(len(r.Header.AncestorHashes)) {
+			return nil, fmt.Errorf("block %d: %d ancestor hashes", blockNum, len(r.Header.AncestorHashes))
+		}
+		for i, hash := range r.Header.AncestorHashes {
+			r.hashes[blockNum-uint64(len(r.Header.AncestorHashes)-i)] = hash
+		}
+	} else if blockNum != r.next {
+		return nil, fmt.Errorf("expected block %d, got %d", r.next, blockNum)
+	}
+	r.hashes[blockNum] = block.Hash()
+	if blockNum >= 257 {
+		delete(r.hashes, blockNum-257)
+	}
+	r.next = blockNum + 1
+	return block, nil
+}
+
+// GetHash returns the hash of one of the 256 blocks preceding the last one returned by Next, same as BLOCKHASH
+func (r *ReplayReader) GetHash(n uint64) libcommon.Hash {
+	if n+1 >= r.next || n+257 < r.next {
+		return libcommon.Hash{}
+	}
+	return r.hashes[n]
+}
+
+func (r *ReplayReader) Close() {
+	r.zr.Close()
+}
+
+type ReplayStats struct {
+	Blocks    uint64
+	Txs       uint64
+	Gas       uint64
+	Took      time.Duration
+	LastBlock uint64
+}
+
+// Replay executes the blocks of the replay file one task after another, the way the serial executor does it, and
+// checks the gas used and the receipts of every block. rs must have the state right before the first block, its
+// first txNum is firstTxNum. Replay only writes to the domains of rs, which the caller is expected to drop.
+func Replay(ctx context.Context, r *ReplayReader, worker *Worker, rs *state.StateV3, tx kv.Tx, chainConfig *chain.Config, engine consensus.EngineReader, firstTxNum uint64, logger log.Logger) (stats ReplayStats, err error) {
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	start := time.Now()
+	txNum := firstTxNum
+	for {
+		block, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, err
+		}
+		tasks, err := BlockTxTasks(chainConfig, engine, nil /* author */, block, txNum, r.GetHash)
+		if err != nil {
+			return stats, err
+		}
+
+		rs.Domains().SetBlockNum(block.NumberU64())
+		var usedGas, blobGasUsed uint64
+		for _, txTask := range tasks {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+			worker.RunTxTaskNoLock(txTask, false /* isMining */)
+			if txTask.Error != nil {
+				return stats, fmt.Errorf("%w: block %d, txnIdx=%d, %v", consensus.ErrInvalidBlock, txTask.BlockNum, txTask.TxIndex, txTask.Error)
+			}
+			usedGas += txTask.UsedGas
+			if txTask.Tx != nil {
+				blobGasUsed += txTask.Tx.GetBlobGas()
+			}
+			txTask.CreateReceipt(tx)
+			if txTask.Final && txTask.BlockNum > 0 {
+				checkReceipts := chainConfig.IsByzantium(txTask.BlockNum)
+				if err := core.BlockPostValidation(usedGas, blobGasUsed, checkReceipts, txTask.BlockReceipts, txTask.Header, false /* isMining */); err != nil {
+					return stats, fmt.Errorf("%w: block %d: %v", consensus.ErrInvalidBlock, txTask.BlockNum, err)
+				}
+			}
+			if err := rs.ApplyState4(ctx, txTask); err != nil {
+				return stats, err
+			}
+		}
+
+		txNum += uint64(len(tasks))
+		stats.Blocks++
+		stats.Txs += uint64(len(block.Transactions()))
+		stats.Gas += usedGas
+		stats.LastBlock = block.NumberU64()
+
+		select {
+		case <-logEvery.C:
+			logger.Info("[replay] executing", "block", stats.LastBlock, "blocks", stats.Blocks, "txs", stats.Txs,
+				"Mgas/s", fmt.Sprintf("%.1f", float64(stats.Gas)/1e6/time.Since(start).Seconds()))
+		default:
+		}
+	}
+	stats.Took = time.Since(start)
+	return stats, nil
+}
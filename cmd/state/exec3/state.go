@@ -18,6 +18,7 @@ package exec3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -39,12 +40,17 @@ import (
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/core/vm/evmtypes"
+	bortypes "github.com/erigontech/erigon/polygon/bor/types"
 	"github.com/erigontech/erigon/turbo/services"
 	"github.com/erigontech/erigon/turbo/shards"
 )
 
 var noop = state.NewNoopWriter()
 
+// ErrBlockEndDeferred is the result of a block end task given to a background worker: the block end finalizes the
+// block over the receipts of all its transactions, so it's executed by the apply loop in order, once they are created.
+var ErrBlockEndDeferred = errors.New("block end is executed in order")
+
 type Worker struct {
 	lock        sync.Locker
 	logger      log.Logger
@@ -236,31 +242,11 @@ func (rw *Worker) RunTxTaskNoLock(txTask *state.TxTask, isMining bool) {
 		if txTask.BlockNum == 0 {
 			break
 		}
-
-		//fmt.Printf("txNum=%d, blockNum=%d, finalisation of the block\n", txTask.TxNum, txTask.BlockNum)
-		// End of block transaction in a block
-		syscall := func(contract libcommon.Address, data []byte) ([]byte, error) {
-			return core.SysCallContract(contract, data, rw.chainConfig, ibs, header, rw.engine, false /* constCall */)
-		}
-
-		if isMining {
-			_, txTask.Txs, txTask.BlockReceipts, _, err = rw.engine.FinalizeAndAssemble(rw.chainConfig, types.CopyHeader(header), ibs, txTask.Txs, txTask.Uncles, txTask.BlockReceipts, txTask.Withdrawals, rw.chain, syscall, nil, rw.logger)
-		} else {
-			_, _, _, err = rw.engine.Finalize(rw.chainConfig, types.CopyHeader(header), ibs, txTask.Txs, txTask.Uncles, txTask.BlockReceipts, txTask.Withdrawals, rw.chain, syscall, rw.logger)
-		}
-		if err != nil {
-			txTask.Error = err
-		} else {
-			//incorrect unwind to block 2
-			//if err := ibs.CommitBlock(rules, rw.stateWriter); err != nil {
-			//	txTask.Error = err
-			//}
-			txTask.TraceTos = map[libcommon.Address]struct{}{}
-			txTask.TraceTos[txTask.Coinbase] = struct{}{}
-			for _, uncle := range txTask.Uncles {
-				txTask.TraceTos[uncle.Coinbase] = struct{}{}
-			}
+		if rw.background {
+			txTask.Error = ErrBlockEndDeferred
+			break
 		}
+		txTask.Error = rw.finalizeBlock(txTask, ibs, isMining)
 	default:
 		rw.taskGasPool.Reset(txTask.Tx.GetGas(), rw.chainConfig.GetMaxBlobGasPerBlock())
 		rw.callTracer.Reset()
@@ -278,7 +264,7 @@ func (rw *Worker) RunTxTaskNoLock(txTask *state.TxTask, isMining bool) {
 		rw.evm.ResetBetweenBlocks(txTask.EvmBlockContext, core.NewEVMTxContext(msg), ibs, rw.vmCfg, rules)
 
 		// MA applytx
-		applyRes, err := core.ApplyMessage(rw.evm, msg, rw.taskGasPool, true /* refunds */, false /* gasBailout */)
+		applyRes, err := core.ApplyTransactionMessage(rw.evm, txTask.Tx, msg, rw.taskGasPool, true /* refunds */, false /* gasBailout */)
 		if err != nil {
 			txTask.Error = err
 		} else {
@@ -308,6 +294,49 @@ func (rw *Worker) RunTxTaskNoLock(txTask *state.TxTask, isMining bool) {
 	}
 }
 
+// finalizeBlock runs the end of the block as a task of its own: block rewards, withdrawals and system contract calls
+// (on bor the state sync among them). Its logs, traces and write set are the results of the task - executors apply
+// them the same way as those of a transaction, so serial and parallel execution produce the same receipts and traces.
+func (rw *Worker) finalizeBlock(txTask *state.TxTask, ibs *state.IntraBlockState, isMining bool) (err error) {
+	header := txTask.Header
+	txTask.TraceFroms = map[libcommon.Address]struct{}{}
+	txTask.TraceTos = map[libcommon.Address]struct{}{}
+	// on bor the system calls of the block end are the state sync transaction: its logs and traces are
+	// results of the task - same as for regular transactions
+	isBor := rw.chainConfig.Bor != nil
+	if isBor {
+		ibs.SetTxContext(len(txTask.Txs))
+	}
+	syscall := func(contract libcommon.Address, data []byte) ([]byte, error) {
+		if isBor {
+			txTask.TraceFroms[state.SystemAddress] = struct{}{}
+			txTask.TraceTos[contract] = struct{}{}
+		}
+		return core.SysCallContract(contract, data, rw.chainConfig, ibs, header, rw.engine, false /* constCall */)
+	}
+
+	if isMining {
+		_, txTask.Txs, txTask.BlockReceipts, _, err = rw.engine.FinalizeAndAssemble(rw.chainConfig, types.CopyHeader(header), ibs, txTask.Txs, txTask.Uncles, txTask.BlockReceipts, txTask.Withdrawals, rw.chain, syscall, nil, rw.logger)
+	} else {
+		_, _, _, err = rw.engine.Finalize(rw.chainConfig, types.CopyHeader(header), ibs, txTask.Txs, txTask.Uncles, txTask.BlockReceipts, txTask.Withdrawals, rw.chain, syscall, rw.logger)
+	}
+	if err != nil {
+		return err
+	}
+	//incorrect unwind to block 2
+	//if err := ibs.CommitBlock(rules, rw.stateWriter); err != nil {
+	//	txTask.Error = err
+	//}
+	txTask.TraceTos[txTask.Coinbase] = struct{}{}
+	for _, uncle := range txTask.Uncles {
+		txTask.TraceTos[uncle.Coinbase] = struct{}{}
+	}
+	if isBor {
+		txTask.Logs = ibs.GetLogs(len(txTask.Txs), bortypes.ComputeBorTxHash(txTask.BlockNum, txTask.BlockHash), txTask.BlockNum, txTask.BlockHash)
+	}
+	return nil
+}
+
 func NewWorkersPool(lock sync.Locker, accumulator *shards.Accumulator, logger log.Logger, ctx context.Context, background bool, chainDb kv.RoDB, rs *state.StateV3, in *state.QueueWithRetry, blockReader services.FullBlockReader, chainConfig *chain.Config, genesis *types.Genesis, engine consensus.Engine, workerCount int, dirs datadir.Dirs, isMining bool) (reconWorkers []*Worker, applyWorker *Worker, rws *state.ResultsQueue, clear func(), wait func()) {
 	reconWorkers = make([]*Worker, workerCount)
 
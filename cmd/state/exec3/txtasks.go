@@ -0,0 +1,73 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package exec3
+
+import (
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// BlockTxTasks returns the tasks executing the block, the first one has firstTxNum: the block's system txn (TxIndex -1),
+// one task per txn and the final system txn (finalization, rewards, withdrawals). The block's txns must have the senders.
+func BlockTxTasks(chainConfig *chain.Config, engine consensus.EngineReader, author *libcommon.Address, block *types.Block, firstTxNum uint64, getHashFn func(n uint64) libcommon.Hash) ([]*state.TxTask, error) {
+	header := block.HeaderNoCopy()
+	blockNum := block.NumberU64()
+	txs := block.Transactions()
+	rules := chainConfig.Rules(blockNum, block.Time())
+	skipAnalysis := core.SkipAnalysis(chainConfig, blockNum)
+	signer := *types.MakeSigner(chainConfig, blockNum, header.Time)
+	blockContext := core.NewEVMBlockContext(header, getHashFn, engine, author, chainConfig)
+	blockReceipts := make(types.Receipts, len(txs))
+
+	tasks := make([]*state.TxTask, 0, len(txs)+2)
+	for txIndex := -1; txIndex <= len(txs); txIndex++ {
+		txTask := &state.TxTask{
+			BlockNum:        blockNum,
+			Header:          header,
+			Coinbase:        block.Coinbase(),
+			Uncles:          block.Uncles(),
+			Rules:           rules,
+			Txs:             txs,
+			TxNum:           firstTxNum + uint64(txIndex+1),
+			TxIndex:         txIndex,
+			BlockHash:       block.Hash(),
+			SkipAnalysis:    skipAnalysis,
+			Final:           txIndex == len(txs),
+			GetHashFn:       getHashFn,
+			EvmBlockContext: blockContext,
+			Withdrawals:     block.Withdrawals(),
+			BlockReceipts:   blockReceipts,
+			Config:          chainConfig,
+		}
+		if txIndex >= 0 && txIndex < len(txs) {
+			var err error
+			txTask.Tx = txs[txIndex]
+			txTask.TxAsMessage, err = txTask.Tx.AsMessage(signer, header.BaseFee, txTask.Rules)
+			if err != nil {
+				return nil, fmt.Errorf("block %d, txn %d: %w", blockNum, txIndex, err)
+			}
+		}
+		tasks = append(tasks, txTask)
+	}
+	return tasks, nil
+}
@@ -19,8 +19,8 @@ package verify
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
-	"math/big"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -30,6 +30,7 @@ import (
 	datadir2 "github.com/erigontech/erigon-lib/common/datadir"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/mdbx"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cmd/hack/tool"
 	"github.com/erigontech/erigon/core/rawdb/blockio"
@@ -72,8 +73,9 @@ func ValidateTxLookups(chaindata string, logger log.Logger) error {
 	iterations := 0
 	var interrupt bool
 	// Validation Process
-	blockBytes := big.NewInt(0)
 	ctx := context.Background()
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, br))
+	expected := make([]byte, 8)
 	for !interrupt {
 		if err := libcommon.Stopped(quitCh); err != nil {
 			return err
@@ -95,21 +97,24 @@ func ValidateTxLookups(chaindata string, logger log.Logger) error {
 			logger.Error("Empty body", "blocknum", blockNum)
 			break
 		}
-		blockBytes.SetUint64(blockNum)
-		bn := blockBytes.Bytes()
+		firstTxNumInBlock, err := txNumsReader.Min(tx, blockNum)
+		if err != nil {
+			return err
+		}
 
-		for _, txn := range body.Transactions {
+		for i, txn := range body.Transactions {
+			binary.BigEndian.PutUint64(expected, firstTxNumInBlock+1+uint64(i))
 			val, err := tx.GetOne(kv.TxLookup, txn.Hash().Bytes())
 			iterations++
 			if iterations%100000 == 0 {
 				logger.Info("Validated", "entries", iterations, "number", blockNum)
 
 			}
-			if !bytes.Equal(val, bn) {
+			if !bytes.Equal(val, expected) {
 				if err != nil {
 					panic(err)
 				}
-				panic(fmt.Sprintf("Validation process failed(%d). Expected %b, got %b", iterations, bn, val))
+				panic(fmt.Sprintf("Validation process failed(%d). Expected %x, got %x", iterations, expected, val))
 			}
 		}
 		blockNum++
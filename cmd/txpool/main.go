@@ -74,6 +74,10 @@ var (
 
 	noTxGossip bool
 
+	broadcastMinTip      uint64
+	broadcastBandwidth   string
+	newPeerAnnounceLimit int
+
 	mdbxWriteMap bool
 
 	commitEvery time.Duration
@@ -103,6 +107,9 @@ func init() {
 	rootCmd.PersistentFlags().Uint64Var(&blobPriceBump, "txpool.blobpricebump", txpoolcfg.DefaultConfig.BlobPriceBump, "Price bump percentage to replace an existing blob (type-3) transaction")
 	rootCmd.PersistentFlags().DurationVar(&commitEvery, utils.TxPoolCommitEveryFlag.Name, utils.TxPoolCommitEveryFlag.Value, utils.TxPoolCommitEveryFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&noTxGossip, utils.TxPoolGossipDisableFlag.Name, utils.TxPoolGossipDisableFlag.Value, utils.TxPoolGossipDisableFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&broadcastMinTip, utils.TxPoolBroadcastMinTipFlag.Name, utils.TxPoolBroadcastMinTipFlag.Value, utils.TxPoolBroadcastMinTipFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&broadcastBandwidth, utils.TxPoolBroadcastBandwidthFlag.Name, utils.TxPoolBroadcastBandwidthFlag.Value, utils.TxPoolBroadcastBandwidthFlag.Usage)
+	rootCmd.PersistentFlags().IntVar(&newPeerAnnounceLimit, utils.TxPoolNewPeerAnnounceLimitFlag.Name, utils.TxPoolNewPeerAnnounceLimitFlag.Value, utils.TxPoolNewPeerAnnounceLimitFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&mdbxWriteMap, utils.DbWriteMapFlag.Name, utils.DbWriteMapFlag.Value, utils.DbWriteMapFlag.Usage)
 	rootCmd.Flags().StringSliceVar(&traceSenders, utils.TxPoolTraceSendersFlag.Name, []string{}, utils.TxPoolTraceSendersFlag.Usage)
 }
@@ -172,6 +179,11 @@ func doTxpool(ctx context.Context, logger log.Logger) error {
 	cfg.PriceBump = priceBump
 	cfg.BlobPriceBump = blobPriceBump
 	cfg.NoGossip = noTxGossip
+	cfg.BroadcastMinTip = broadcastMinTip
+	if err := cfg.BroadcastBandwidth.UnmarshalText([]byte(broadcastBandwidth)); err != nil {
+		return fmt.Errorf("failed to parse --%s: %w", utils.TxPoolBroadcastBandwidthFlag.Name, err)
+	}
+	cfg.NewPeerAnnounceLimit = newPeerAnnounceLimit
 	cfg.MdbxWriteMap = mdbxWriteMap
 
 	cacheConfig := kvcache.DefaultCoherentConfig
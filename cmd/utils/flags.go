@@ -44,8 +44,10 @@ import (
 	"github.com/erigontech/erigon-lib/common/paths"
 	"github.com/erigontech/erigon-lib/crypto"
 	libkzg "github.com/erigontech/erigon-lib/crypto/kzg"
+	diaglib "github.com/erigontech/erigon-lib/diagnostics"
 	"github.com/erigontech/erigon-lib/direct"
 	downloadercfg2 "github.com/erigontech/erigon-lib/downloader/downloadercfg"
+	"github.com/erigontech/erigon-lib/kv/kvcache"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cmd/downloader/downloadernat"
@@ -62,7 +64,9 @@ import (
 	"github.com/erigontech/erigon/params"
 	"github.com/erigontech/erigon/polygon/heimdall"
 	"github.com/erigontech/erigon/rpc/rpccfg"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
 	"github.com/erigontech/erigon/turbo/logging"
+	"github.com/erigontech/erigon/txnprovider/bundle"
 	"github.com/erigontech/erigon/txnprovider/shutter"
 	"github.com/erigontech/erigon/txnprovider/txpool/txpoolcfg"
 )
@@ -101,9 +105,13 @@ var (
 	}
 	ChainFlag = cli.StringFlag{
 		Name:  "chain",
-		Usage: "name of the network to join",
+		Usage: "name of the network to join (custom - devnet with the chain spec of --chain.config)",
 		Value: networkname.Mainnet,
 	}
+	ChainConfigFlag = cli.StringFlag{
+		Name:  "chain.config",
+		Usage: "Path to the JSON chain spec (forks, bor params, clique signers, extraEips) of --chain=custom devnet, same format as params/chainspecs",
+	}
 	IdentityFlag = cli.StringFlag{
 		Name:  "identity",
 		Usage: "Custom node name",
@@ -143,6 +151,20 @@ var (
 		Name:  "externalcl",
 		Usage: "Enables the external consensus layer",
 	}
+	EngineJournalDirFlag = cli.StringFlag{
+		Name:  "engine.journal.dir",
+		Usage: "Record all engine_newPayload and engine_forkchoiceUpdated requests and responses into a rotating journal in this directory, it can be replayed with `integration replay_engine` (disabled if empty)",
+	}
+	EngineJournalMaxFileSizeFlag = cli.Int64Flag{
+		Name:  "engine.journal.maxsize",
+		Usage: "Size in MB after which the engine API journal rotates to a new file",
+		Value: engine_journal.DefaultConfig.MaxFileSize / 1024 / 1024,
+	}
+	EngineJournalMaxFilesFlag = cli.IntFlag{
+		Name:  "engine.journal.maxfiles",
+		Usage: "Number of engine API journal files kept, older files are removed",
+		Value: engine_journal.DefaultConfig.MaxFiles,
+	}
 	// Transaction pool settings
 	TxPoolDisableFlag = cli.BoolFlag{
 		Name:  "txpool.disable",
@@ -184,6 +206,26 @@ var (
 		Usage: "Total limit of number of all blobs in txs within the txpool",
 		Value: txpoolcfg.DefaultConfig.TotalBlobPoolLimit,
 	}
+	TxPoolMaxAAValidationGasFlag = cli.Uint64Flag{
+		Name:  "txpool.aa.maxvalidationgas",
+		Usage: "Max gas of the validation frames of RIP-7560 account abstraction transactions (only accepted when the chain config allows them)",
+		Value: txpoolcfg.DefaultConfig.MaxAAValidationGas,
+	}
+	TxPoolBroadcastMinTipFlag = cli.Uint64Flag{
+		Name:  "txpool.broadcast.mintip",
+		Usage: "Remote transactions with a lower effective tip (wei) are only announced to the peers, not broadcast in full",
+		Value: txpoolcfg.DefaultConfig.BroadcastMinTip,
+	}
+	TxPoolBroadcastBandwidthFlag = cli.StringFlag{
+		Name:  "txpool.broadcast.bandwidth",
+		Usage: "Per second budget for broadcasting remote transactions in full, counting every peer they're sent to. Past it they're only announced. 0 - unlimited",
+		Value: txpoolcfg.DefaultConfig.BroadcastBandwidth.String(),
+	}
+	TxPoolNewPeerAnnounceLimitFlag = cli.IntFlag{
+		Name:  "txpool.announce.newpeer.limit",
+		Usage: "Max number of transactions announced to a newly connected peer, local ones first. 0 - unlimited",
+		Value: txpoolcfg.DefaultConfig.NewPeerAnnounceLimit,
+	}
 	TxPoolGlobalSlotsFlag = cli.IntFlag{
 		Name:  "txpool.globalslots",
 		Usage: "Maximum number of executable transaction slots for all accounts",
@@ -251,6 +293,11 @@ var (
 		Usage: "Time interval to recreate the block being mined",
 		Value: ethconfig.Defaults.Miner.Recommit,
 	}
+	MinerBuildLeadFlag = cli.DurationFlag{
+		Name:  "miner.buildlead",
+		Usage: "Bor: how long before the producer slot of the node to start building the block",
+		Value: ethconfig.Defaults.Miner.BuildLead,
+	}
 	MinerNoVerfiyFlag = cli.BoolFlag{
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
@@ -381,6 +428,16 @@ var (
 		Usage: "Maximum number of bytes returned from eth_call or similar invocations",
 		Value: 100_000,
 	}
+	RpcResponseMaxBytes = cli.IntFlag{
+		Name:  "rpc.response.maxbytes",
+		Usage: "Maximum size in bytes of the response of the heavy methods, larger responses are replaced by an error. 0 - unlimited. Methods: " + strings.Join(rpccfg.HeavyMethods, ","),
+		Value: 0,
+	}
+	RpcHeavyTimeout = cli.DurationFlag{
+		Name:  "rpc.heavy.timeout",
+		Usage: "Deadline of the requests to the heavy methods (see --rpc.response.maxbytes): 30s, 5m. 0 - none",
+		Value: 0,
+	}
 	HTTPTraceFlag = cli.BoolFlag{
 		Name:  "http.trace",
 		Usage: "Print all HTTP requests to logs with INFO level",
@@ -504,6 +561,11 @@ var (
 		Value: "0MB",
 		Usage: "Amount of data to store in StateCache (enabled if no --datadir set). Set 0 to disable StateCache. Defaults to 0MB",
 	}
+	StateCacheViewsFlag = cli.Uint64Flag{
+		Name:  "state.cache.views",
+		Value: kvcache.DefaultCoherentConfig.KeepViews,
+		Usage: "Amount of recent blocks the StateCache keeps a view of. Requests on older blocks read the db directly",
+	}
 
 	// Network Settings
 	MaxPeersFlag = cli.IntFlag{
@@ -711,7 +773,7 @@ var (
 	}
 	DownloaderVerifyFlag = cli.BoolFlag{
 		Name:  "downloader.verify",
-		Usage: "Verify snapshots on startup. It will not report problems found, but re-download broken pieces.",
+		Usage: "Verify snapshots on startup. It will not report problems found, but re-download broken pieces. The files verified before and not modified since are skipped",
 	}
 	DisableIPV6 = cli.BoolFlag{
 		Name:  "downloader.disable.ipv6",
@@ -800,6 +862,12 @@ var (
 		Value: false,
 	}
 
+	BorUnsafeUnwindFlag = cli.BoolFlag{
+		Name:  "bor.unsafe-unwind",
+		Usage: "Allow unwinding behind the latest verified milestone, milestones are final so such an unwind is refused by default",
+		Value: false,
+	}
+
 	PolygonSyncFlag = cli.BoolFlag{
 		Name:  "polygon.sync",
 		Usage: "Enabling syncing using the new polygon sync component",
@@ -856,11 +924,31 @@ var (
 		Usage: "checkpoint sync endpoint",
 		Value: cli.NewStringSlice(),
 	}
+	CaplinCheckpointSyncPolicyFlag = cli.StringFlag{
+		Name:  "caplin.checkpoint-sync.policy",
+		Usage: "how many checkpoint sync endpoints must serve the same state before it is trusted: any, majority or all",
+		Value: string(clparams.CheckpointSyncPolicyAny),
+	}
+	CaplinCheckpointSyncStateRootFlag = cli.StringFlag{
+		Name:  "caplin.checkpoint-sync.state-root",
+		Usage: "expected root of the state served by checkpoint sync, states with a different root are rejected",
+		Value: "",
+	}
 	CaplinSubscribeAllTopicsFlag = cli.BoolFlag{
 		Name:  "caplin.subscribe-all-topics",
 		Usage: "Subscribe to all gossip topics",
 		Value: false,
 	}
+	CaplinAttestationSubnetBackboneFlag = cli.Uint64Flag{
+		Name:  "caplin.attestation-subnets.backbone",
+		Usage: "Number of attestation subnets to stay subscribed to regardless of the validators duties",
+		Value: 2,
+	}
+	CaplinAttestationSubnetRotationEpochsFlag = cli.Uint64Flag{
+		Name:  "caplin.attestation-subnets.rotation-epochs",
+		Usage: "Re-pick the attestation subnets backbone every this many epochs (0 to never rotate)",
+		Value: 0,
+	}
 	CaplinMevRelayUrl = cli.StringFlag{
 		Name:  "caplin.mev-relay-url",
 		Usage: "MEV relay endpoint. Caplin runs in builder mode if this is set",
@@ -871,6 +959,31 @@ var (
 		Usage: "Enable caplin validator monitoring metrics",
 		Value: false,
 	}
+	CaplinValidatorKeystoresFlag = cli.StringFlag{
+		Name:  "caplin.validator.keystores",
+		Usage: "Directory of EIP-2335 keystores. If set, Caplin runs an embedded validator client for them",
+		Value: "",
+	}
+	CaplinValidatorPasswordFileFlag = cli.StringFlag{
+		Name:  "caplin.validator.password-file",
+		Usage: "File containing the password of the keystores in --caplin.validator.keystores",
+		Value: "",
+	}
+	CaplinValidatorWeb3SignerUrlFlag = cli.StringFlag{
+		Name:  "caplin.validator.web3signer-url",
+		Usage: "Web3signer endpoint. If set, Caplin runs an embedded validator client for the keys it holds",
+		Value: "",
+	}
+	CaplinValidatorFeeRecipientFlag = cli.StringFlag{
+		Name:  "caplin.validator.fee-recipient",
+		Usage: "Fee recipient of the blocks proposed by the embedded validator client",
+		Value: "",
+	}
+	CaplinValidatorGraffitiFlag = cli.StringFlag{
+		Name:  "caplin.validator.graffiti",
+		Usage: "Graffiti of the blocks proposed by the embedded validator client, at most 32 bytes",
+		Value: "",
+	}
 	CaplinMaxPeerCount = cli.Uint64Flag{
 		Name:  "caplin.max-peer-count",
 		Usage: "Max number of peers to connect",
@@ -1021,6 +1134,26 @@ var (
 		Usage: "disable blob pruning in caplin",
 		Value: false,
 	}
+	CaplinBlobsRetentionEpochsFlag = cli.Uint64Flag{
+		Name:  "caplin.blobs-retention-epochs",
+		Usage: "number of epochs to keep blob sidecars for before pruning them, cannot be lower than MIN_EPOCHS_FOR_BLOBS_SIDECARS_REQUEST (4096 on mainnet)",
+		Value: 0,
+	}
+	CaplinHistoricalStatesCacheSizeFlag = cli.IntFlag{
+		Name:  "caplin.historical-states-cache-size",
+		Usage: "number of reconstructed historical states to keep in memory for the beacon API, 0 disables the cache (a mainnet state takes a few hundred MBs)",
+		Value: 0,
+	}
+	CaplinHotStatesEpochsFlag = cli.Uint64Flag{
+		Name:  "caplin.hot-states-epochs",
+		Usage: "number of recent epochs whose forkchoice states are kept in memory to handle reorgs quickly, 0 keeps only the head state (a mainnet state takes a few hundred MBs)",
+		Value: 0,
+	}
+	CaplinColdStatesSlotFrequencyFlag = cli.Uint64Flag{
+		Name:  "caplin.cold-states-slot-frequency",
+		Usage: "number of slots between the forkchoice states compressed on disk, lower values use more disk and make reorgs faster",
+		Value: 4,
+	}
 	CaplinDisableCheckpointSyncFlag = cli.BoolFlag{
 		Name:  "caplin.checkpoint-sync.disable",
 		Usage: "disable checkpoint sync in caplin",
@@ -1081,6 +1214,21 @@ var (
 		Usage: "Enable speed test",
 		Value: false,
 	}
+	DiagFlightRecorderFlag = cli.BoolFlag{
+		Name:  "diagnostics.flightrecorder",
+		Usage: "Keep the recent runtime trace, goroutine, heap and block profiles in memory and dump them when a stage or a RW tx stalls",
+		Value: false,
+	}
+	DiagFlightRecorderWindowFlag = cli.DurationFlag{
+		Name:  "diagnostics.flightrecorder.window",
+		Usage: "How much of the recent profiling history the flight recorder keeps",
+		Value: diaglib.DefaultFlightRecorderConfig.Window,
+	}
+	DiagFlightRecorderStallFlag = cli.DurationFlag{
+		Name:  "diagnostics.flightrecorder.stall",
+		Usage: "A stage not progressing or a RW tx held for longer is considered stalled by the flight recorder",
+		Value: diaglib.DefaultFlightRecorderConfig.StallTimeout,
+	}
 	ChaosMonkeyFlag = cli.BoolFlag{
 		Name:  "chaos.monkey",
 		Usage: "Enable 'chaos monkey' to generate spontaneous network/consensus/etc failures. Use ONLY for testing",
@@ -1094,9 +1242,18 @@ var (
 		Name:  "shutter.keyper.bootnodes",
 		Usage: "Use to override the default keyper bootnodes (defaults to using the bootnodes from the embedded config)",
 	}
+	BundleEnabled = cli.BoolFlag{
+		Name:  "bundle",
+		Usage: "Enable the bundle pool: block builder plugins can submit ordered transaction bundles via the 'bundle' RPC namespace, which are put at the top of built payloads (defaults to false)",
+	}
+	BundleMaxBundles = cli.IntFlag{
+		Name:  "bundle.max",
+		Usage: "Maximum number of pending bundles",
+		Value: bundle.DefaultConfig.MaxBundles,
+	}
 )
 
-var MetricFlags = []cli.Flag{&MetricsEnabledFlag, &MetricsHTTPFlag, &MetricsPortFlag, &DiagDisabledFlag, &DiagEndpointAddrFlag, &DiagEndpointPortFlag, &DiagSpeedTestFlag}
+var MetricFlags = []cli.Flag{&MetricsEnabledFlag, &MetricsHTTPFlag, &MetricsPortFlag, &DiagDisabledFlag, &DiagEndpointAddrFlag, &DiagEndpointPortFlag, &DiagSpeedTestFlag, &DiagFlightRecorderFlag, &DiagFlightRecorderWindowFlag, &DiagFlightRecorderStallFlag}
 
 var DiagnosticsFlags = []cli.Flag{&DiagnosticsURLFlag, &DiagnosticsURLFlag, &DiagnosticsSessionsFlag}
 
@@ -1363,7 +1520,7 @@ func setEtherbase(ctx *cli.Context, cfg *ethconfig.Config) {
 		}
 	}
 
-	if chainName := ctx.String(ChainFlag.Name); chainName == networkname.Dev || chainName == networkname.BorDevnet {
+	if chainName := ctx.String(ChainFlag.Name); chainName == networkname.Dev || chainName == networkname.BorDevnet || chainName == networkname.Custom {
 		if etherbase == "" {
 			cfg.Miner.Etherbase = core.DevnetEtherbase
 		}
@@ -1544,6 +1701,20 @@ func setTxPool(ctx *cli.Context, dbDir string, fullCfg *ethconfig.Config) {
 	if ctx.IsSet(TxPoolTotalBlobPoolLimit.Name) {
 		cfg.TotalBlobPoolLimit = ctx.Uint64(TxPoolTotalBlobPoolLimit.Name)
 	}
+	if ctx.IsSet(TxPoolMaxAAValidationGasFlag.Name) {
+		cfg.MaxAAValidationGas = ctx.Uint64(TxPoolMaxAAValidationGasFlag.Name)
+	}
+	if ctx.IsSet(TxPoolBroadcastMinTipFlag.Name) {
+		cfg.BroadcastMinTip = ctx.Uint64(TxPoolBroadcastMinTipFlag.Name)
+	}
+	if ctx.IsSet(TxPoolBroadcastBandwidthFlag.Name) {
+		if err := cfg.BroadcastBandwidth.UnmarshalText([]byte(ctx.String(TxPoolBroadcastBandwidthFlag.Name))); err != nil {
+			Fatalf("Option %s: %v", TxPoolBroadcastBandwidthFlag.Name, err)
+		}
+	}
+	if ctx.IsSet(TxPoolNewPeerAnnounceLimitFlag.Name) {
+		cfg.NewPeerAnnounceLimit = ctx.Int(TxPoolNewPeerAnnounceLimitFlag.Name)
+	}
 	if ctx.IsSet(TxPoolGlobalSlotsFlag.Name) {
 		cfg.PendingSubPoolLimit = ctx.Int(TxPoolGlobalSlotsFlag.Name)
 	}
@@ -1591,6 +1762,13 @@ func setShutter(ctx *cli.Context, chainName string, cfg *ethconfig.Config) {
 	cfg.Shutter = config
 }
 
+func setBundle(ctx *cli.Context, cfg *ethconfig.Config) {
+	cfg.Bundle.Enabled = ctx.Bool(BundleEnabled.Name)
+	if ctx.IsSet(BundleMaxBundles.Name) {
+		cfg.Bundle.MaxBundles = ctx.Int(BundleMaxBundles.Name)
+	}
+}
+
 func setEthash(ctx *cli.Context, datadir string, cfg *ethconfig.Config) {
 	if ctx.IsSet(EthashDatasetDirFlag.Name) {
 		cfg.Ethash.DatasetDir = ctx.String(EthashDatasetDirFlag.Name)
@@ -1643,6 +1821,10 @@ func SetupMinerCobra(cmd *cobra.Command, cfg *params.MiningConfig) {
 	if err != nil {
 		panic(err)
 	}
+	cfg.BuildLead, err = flags.GetDuration(MinerBuildLeadFlag.Name)
+	if err != nil {
+		panic(err)
+	}
 	cfg.Noverify, err = flags.GetBool(MinerNoVerfiyFlag.Name)
 	if err != nil {
 		panic(err)
@@ -1678,6 +1860,7 @@ func setBorConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 	cfg.WithoutHeimdall = ctx.Bool(WithoutHeimdallFlag.Name)
 	cfg.WithHeimdallMilestones = ctx.Bool(WithHeimdallMilestones.Name)
 	cfg.WithHeimdallWaypointRecording = ctx.Bool(WithHeimdallWaypoints.Name)
+	cfg.BorUnsafeUnwind = ctx.Bool(BorUnsafeUnwindFlag.Name)
 	cfg.PolygonSync = ctx.Bool(PolygonSyncFlag.Name)
 	cfg.PolygonSyncStage = ctx.Bool(PolygonSyncStageFlag.Name)
 	heimdall.RecordWayPoints(
@@ -1706,6 +1889,9 @@ func setMiner(ctx *cli.Context, cfg *params.MiningConfig) {
 	if ctx.IsSet(MinerRecommitIntervalFlag.Name) {
 		cfg.Recommit = ctx.Duration(MinerRecommitIntervalFlag.Name)
 	}
+	if ctx.IsSet(MinerBuildLeadFlag.Name) {
+		cfg.BuildLead = ctx.Duration(MinerBuildLeadFlag.Name)
+	}
 	if ctx.IsSet(MinerNoVerfiyFlag.Name) {
 		cfg.Noverify = ctx.Bool(MinerNoVerfiyFlag.Name)
 	}
@@ -1758,6 +1944,10 @@ func setCaplin(ctx *cli.Context, cfg *ethconfig.Config) {
 	// More granularity here.
 	cfg.CaplinConfig.BlobBackfilling = ctx.Bool(CaplinBlobBackfillingFlag.Name)
 	cfg.CaplinConfig.BlobPruningDisabled = ctx.Bool(CaplinDisableBlobPruningFlag.Name)
+	cfg.CaplinConfig.BlobsRetentionEpochs = ctx.Uint64(CaplinBlobsRetentionEpochsFlag.Name)
+	cfg.CaplinConfig.HistoricalStatesCacheSize = ctx.Int(CaplinHistoricalStatesCacheSizeFlag.Name)
+	cfg.CaplinConfig.HotStatesEpochs = ctx.Uint64(CaplinHotStatesEpochsFlag.Name)
+	cfg.CaplinConfig.ColdStatesSlotFrequency = ctx.Uint64(CaplinColdStatesSlotFrequencyFlag.Name)
 	cfg.CaplinConfig.DisabledCheckpointSync = ctx.Bool(CaplinDisableCheckpointSyncFlag.Name)
 	cfg.CaplinConfig.Archive = ctx.Bool(CaplinArchiveFlag.Name)
 	cfg.CaplinConfig.MevRelayUrl = ctx.String(CaplinMevRelayUrl.Name)
@@ -1765,8 +1955,44 @@ func setCaplin(ctx *cli.Context, cfg *ethconfig.Config) {
 	if checkpointUrls := ctx.StringSlice(CaplinCheckpointSyncUrlFlag.Name); len(checkpointUrls) > 0 {
 		clparams.ConfigurableCheckpointsURLs = checkpointUrls
 	}
+	var err error
+	cfg.CaplinConfig.CheckpointSyncPolicy, err = clparams.ParseCheckpointSyncPolicy(ctx.String(CaplinCheckpointSyncPolicyFlag.Name))
+	if err != nil {
+		Fatalf("Option %s: %v", CaplinCheckpointSyncPolicyFlag.Name, err)
+	}
+	if stateRoot := ctx.String(CaplinCheckpointSyncStateRootFlag.Name); stateRoot != "" {
+		if err := cfg.CaplinConfig.CheckpointSyncStateRoot.UnmarshalText([]byte(stateRoot)); err != nil {
+			Fatalf("Option %s: invalid state root %q: %v", CaplinCheckpointSyncStateRootFlag.Name, stateRoot, err)
+		}
+	}
 	cfg.CaplinConfig.CustomConfigPath = ctx.String(CaplinCustomConfigFlag.Name)
 	cfg.CaplinConfig.CustomGenesisStatePath = ctx.String(CaplinCustomGenesisFlag.Name)
+	SetCaplinValidatorConfig(ctx, &cfg.CaplinConfig)
+}
+
+// SetCaplinValidatorConfig applies the embedded validator client flags.
+func SetCaplinValidatorConfig(ctx *cli.Context, cfg *clparams.CaplinConfig) {
+	cfg.ValidatorKeystoresDir = ctx.String(CaplinValidatorKeystoresFlag.Name)
+	cfg.ValidatorPasswordFile = ctx.String(CaplinValidatorPasswordFileFlag.Name)
+	cfg.Web3SignerUrl = ctx.String(CaplinValidatorWeb3SignerUrlFlag.Name)
+	cfg.ValidatorGraffiti = ctx.String(CaplinValidatorGraffitiFlag.Name)
+	if !cfg.ValidatorClientEnabled() {
+		return
+	}
+	if cfg.ValidatorKeystoresDir != "" && cfg.Web3SignerUrl != "" {
+		Fatalf("Option %s cannot be used with %s", CaplinValidatorKeystoresFlag.Name, CaplinValidatorWeb3SignerUrlFlag.Name)
+	}
+	if cfg.ValidatorKeystoresDir != "" && cfg.ValidatorPasswordFile == "" {
+		Fatalf("Option %s requires %s", CaplinValidatorKeystoresFlag.Name, CaplinValidatorPasswordFileFlag.Name)
+	}
+	feeRecipient := ctx.String(CaplinValidatorFeeRecipientFlag.Name)
+	if !libcommon.IsHexAddress(feeRecipient) {
+		Fatalf("Option %s: a valid fee recipient address is required to run the validator client", CaplinValidatorFeeRecipientFlag.Name)
+	}
+	cfg.ValidatorFeeRecipient = libcommon.HexToAddress(feeRecipient)
+	if len(cfg.ValidatorGraffiti) > 32 {
+		Fatalf("Option %s: graffiti is longer than 32 bytes", CaplinValidatorGraffitiFlag.Name)
+	}
 }
 
 func setSilkworm(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1843,6 +2069,8 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	cfg.CaplinConfig.AdptableTrafficRequirements = ctx.Bool(CaplinAdaptableTrafficRequirementsFlag.Name)
 
 	cfg.CaplinConfig.SubscribeAllTopics = ctx.Bool(CaplinSubscribeAllTopicsFlag.Name)
+	cfg.CaplinConfig.AttestationSubnetBackbone = ctx.Uint64(CaplinAttestationSubnetBackboneFlag.Name)
+	cfg.CaplinConfig.AttestationSubnetRotationEpochs = ctx.Uint64(CaplinAttestationSubnetRotationEpochsFlag.Name)
 	cfg.CaplinConfig.MaxPeerCount = ctx.Uint64(CaplinMaxPeerCount.Name)
 
 	cfg.CaplinConfig.SentinelAddr = ctx.String(SentinelAddrFlag.Name)
@@ -1916,6 +2144,7 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 
 	setTxPool(ctx, nodeConfig.Dirs.TxPool, cfg)
 	setShutter(ctx, chain, cfg)
+	setBundle(ctx, cfg)
 
 	setEthash(ctx, nodeConfig.Dirs.DataDir, cfg)
 	setClique(ctx, &cfg.Clique, nodeConfig.Dirs.DataDir)
@@ -1981,6 +2210,25 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 		if !ctx.IsSet(MinerGasPriceFlag.Name) {
 			cfg.Miner.GasPrice = big.NewInt(1)
 		}
+	case networkname.Custom:
+		if !ctx.IsSet(ChainConfigFlag.Name) {
+			Fatalf("Please specify the chain spec of the custom chain using --%s", ChainConfigFlag.Name)
+		}
+		spec, err := params.ReadChainSpecFile(ctx.String(ChainConfigFlag.Name))
+		if err != nil {
+			Fatalf("%s", err)
+		}
+		if spec.ChainName == "" {
+			spec.ChainName = networkname.Custom
+		}
+		if err = core.ValidateChainSpec(spec); err != nil {
+			Fatalf("Invalid chain spec %s: %s", ctx.String(ChainConfigFlag.Name), err)
+		}
+		if !ctx.IsSet(NetworkIdFlag.Name) {
+			cfg.NetworkID = spec.ChainID.Uint64()
+		}
+		cfg.Genesis = core.CustomGenesisBlock(spec)
+		logger.Info("Using custom chain spec", "file", ctx.String(ChainConfigFlag.Name), "chainId", spec.ChainID, "extraEips", spec.ExtraEips)
 	}
 
 	if ctx.IsSet(OverridePragueFlag.Name) {
@@ -1991,6 +2239,9 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	if clparams.EmbeddedSupported(cfg.NetworkID) || cfg.CaplinConfig.IsDevnet() {
 		cfg.InternalCL = !ctx.Bool(ExternalConsensusFlag.Name)
 	}
+	cfg.EngineJournal.Dir = ctx.String(EngineJournalDirFlag.Name)
+	cfg.EngineJournal.MaxFileSize = ctx.Int64(EngineJournalMaxFileSizeFlag.Name) * 1024 * 1024
+	cfg.EngineJournal.MaxFiles = ctx.Int(EngineJournalMaxFilesFlag.Name)
 
 	if ctx.IsSet(TrustedSetupFile.Name) {
 		libkzg.SetTrustedSetupFilePath(ctx.String(TrustedSetupFile.Name))
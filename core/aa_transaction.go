@@ -0,0 +1,310 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+	"github.com/erigontech/erigon/params"
+)
+
+// ErrAAValidation is returned when one of the validation frames of an account abstraction transaction
+// fails. Such a transaction is invalid and can't be included in a block.
+var ErrAAValidation = errors.New("account abstraction validation failed")
+
+// RIP-7560 frames: the sender and the paymaster are called by the entry point with the version of the
+// transaction format, its hash and its canonical encoding.
+var (
+	aaValidateTransactionSelector = crypto.Keccak256([]byte("validateTransaction(uint256,bytes32,bytes)"))[:4]
+	aaValidatePaymasterSelector   = crypto.Keccak256([]byte("validatePaymasterTransaction(uint256,bytes32,bytes)"))[:4]
+	aaPostPaymasterSelector       = crypto.Keccak256([]byte("postPaymasterTransaction(bool,uint256,bytes)"))[:4]
+)
+
+const aaTransactionVersion = 0
+
+// ApplyTransactionMessage applies msg, the message of txn, running the RIP-7560 frames instead when txn is an
+// account abstraction transaction.
+func ApplyTransactionMessage(evm *vm.EVM, txn types.Transaction, msg Message, gp *GasPool, refunds bool, gasBailout bool) (*evmtypes.ExecutionResult, error) {
+	if aaTxn, ok := txn.(*types.AccountAbstractionTransaction); ok {
+		return ApplyAATransaction(evm, aaTxn, gp)
+	}
+	return ApplyMessage(evm, msg, gp, refunds, gasBailout)
+}
+
+// ApplyAATransaction applies a RIP-7560 transaction. The validation phase (nonce, gas pre-charge, deployment,
+// account and paymaster validation) has to succeed for the transaction to be valid; the execution phase
+// (the sender call followed by the paymaster post-op) may fail like the call of a regular transaction.
+func ApplyAATransaction(evm *vm.EVM, txn *types.AccountAbstractionTransaction, gp *GasPool) (*evmtypes.ExecutionResult, error) {
+	rules := evm.ChainRules()
+	if !rules.IsAA {
+		return nil, errors.New("account abstraction transactions are not enabled")
+	}
+	if txn.NonceKey != nil && !txn.NonceKey.IsZero() {
+		return nil, fmt.Errorf("%w: RIP-7712 nonce keys are not supported", ErrAAValidation)
+	}
+	if txn.SenderAddress == nil {
+		return nil, fmt.Errorf("%w: no sender", ErrAAValidation)
+	}
+	ibs := evm.IntraBlockState()
+	sender := *txn.SenderAddress
+	payer := sender
+	if txn.Paymaster != nil {
+		payer = *txn.Paymaster
+	}
+	coinbase := evm.Context.Coinbase
+	entryPoint := vm.AccountRef(params.AAEntryPointAddress)
+
+	gasPrice, effectiveTip := new(uint256.Int).Set(txn.FeeCap), new(uint256.Int).Set(txn.Tip)
+	if baseFee := evm.Context.BaseFee; baseFee != nil {
+		if txn.FeeCap.Lt(baseFee) {
+			return nil, fmt.Errorf("%w: address %v, feeCap: %s baseFee: %s", ErrFeeCapTooLow, sender.Hex(), txn.FeeCap, baseFee)
+		}
+		effectiveTip = txn.GetEffectiveGasTip(baseFee)
+		gasPrice.Add(baseFee, effectiveTip)
+	}
+
+	nonce, err := ibs.GetNonce(sender)
+	if err != nil {
+		return nil, err
+	}
+	if nonce < txn.Nonce {
+		return nil, fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooHigh, sender.Hex(), txn.Nonce, nonce)
+	} else if nonce > txn.Nonce {
+		return nil, fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooLow, sender.Hex(), txn.Nonce, nonce)
+	}
+
+	totalGas, overflow := txn.TotalGas()
+	if overflow {
+		return nil, fmt.Errorf("%w: address %v, gas limits of the frames", ErrGasUintOverflow, sender.Hex())
+	}
+	if err := gp.SubGas(totalGas); err != nil {
+		return nil, err
+	}
+	builderFee := new(uint256.Int)
+	if txn.BuilderFee != nil {
+		builderFee.Set(txn.BuilderFee)
+	}
+	preCharge, overflow := new(uint256.Int).MulOverflow(uint256.NewInt(totalGas), gasPrice)
+	if overflow {
+		return nil, fmt.Errorf("%w: address %v", ErrInsufficientFunds, payer.Hex())
+	}
+	if _, overflow = preCharge.AddOverflow(preCharge, builderFee); overflow {
+		return nil, fmt.Errorf("%w: address %v", ErrInsufficientFunds, payer.Hex())
+	}
+	balance, err := ibs.GetBalance(payer)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Lt(preCharge) {
+		return nil, fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, payer.Hex(), balance, preCharge)
+	}
+	if err := ibs.SubBalance(payer, preCharge, tracing.BalanceDecreaseGasBuy); err != nil {
+		return nil, err
+	}
+	if err := ibs.SetNonce(sender, nonce+1); err != nil {
+		return nil, err
+	}
+	if err := ibs.Prepare(rules, sender, coinbase, &sender, vm.ActivePrecompiles(rules), txn.AccessList, nil); err != nil {
+		return nil, err
+	}
+
+	// Validation phase
+	gasUsed := txn.IntrinsicGas()
+	validationGas := txn.ValidationGasLimit
+	if txn.Deployer != nil {
+		if codeSize, err := ibs.GetCodeSize(sender); err != nil {
+			return nil, err
+		} else if codeSize != 0 {
+			return nil, fmt.Errorf("%w: sender %v already deployed", ErrAAValidation, sender.Hex())
+		}
+		_, left, err := evm.Call(vm.AccountRef(params.AASenderCreatorAddress), *txn.Deployer, txn.DeployerData, validationGas, new(uint256.Int), false /* bailout */)
+		if err != nil {
+			return nil, fmt.Errorf("%w: deployment: %w", ErrAAValidation, err)
+		}
+		validationGas = left
+	}
+	if codeSize, err := ibs.GetCodeSize(sender); err != nil {
+		return nil, err
+	} else if codeSize == 0 {
+		return nil, fmt.Errorf("%w: sender %v not deployed", ErrAAValidation, sender.Hex())
+	}
+
+	var encoded bytes.Buffer
+	if err := txn.MarshalBinary(&encoded); err != nil {
+		return nil, err
+	}
+	txHash := txn.SigningHash(evm.ChainConfig().ChainID)
+	ret, left, err := evm.Call(entryPoint, sender, aaFrameInput(aaValidateTransactionSelector, txHash, encoded.Bytes()), validationGas, new(uint256.Int), false /* bailout */)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sender: %w", ErrAAValidation, err)
+	}
+	if err := checkAAValidity(ret, aaValidateTransactionSelector, evm.Context.Time); err != nil {
+		return nil, fmt.Errorf("%w: sender: %w", ErrAAValidation, err)
+	}
+	gasUsed += txn.ValidationGasLimit - left
+
+	var paymasterContext []byte
+	if txn.Paymaster != nil {
+		ret, left, err = evm.Call(entryPoint, *txn.Paymaster, aaFrameInput(aaValidatePaymasterSelector, txHash, encoded.Bytes()), txn.PaymasterValidationGasLimit, new(uint256.Int), false /* bailout */)
+		if err != nil {
+			return nil, fmt.Errorf("%w: paymaster: %w", ErrAAValidation, err)
+		}
+		if err := checkAAValidity(ret, aaValidatePaymasterSelector, evm.Context.Time); err != nil {
+			return nil, fmt.Errorf("%w: paymaster: %w", ErrAAValidation, err)
+		}
+		if paymasterContext, err = abiDynamicBytes(ret, 32); err != nil {
+			return nil, fmt.Errorf("%w: paymaster context: %w", ErrAAValidation, err)
+		}
+		gasUsed += txn.PaymasterValidationGasLimit - left
+	}
+
+	// Execution phase
+	snapshot := ibs.Snapshot()
+	ret, left, vmerr := evm.Call(entryPoint, sender, txn.ExecutionData, txn.GasLimit, new(uint256.Int), false /* bailout */)
+	gasUsed += txn.GasLimit - left
+	if len(paymasterContext) > 0 {
+		actualGasCost := new(uint256.Int).Mul(uint256.NewInt(gasUsed), gasPrice)
+		_, left, err = evm.Call(entryPoint, *txn.Paymaster, aaPostOpInput(vmerr == nil, actualGasCost, paymasterContext), txn.PostOpGasLimit, new(uint256.Int), false /* bailout */)
+		gasUsed += txn.PostOpGasLimit - left
+		if err != nil {
+			// a failed post-op reverts the execution frame as well
+			ibs.RevertToSnapshot(snapshot)
+			vmerr = err
+		}
+	}
+
+	// the frames can't use more than their limits, the clamp keeps a misbehaving frame from underflowing the refund
+	gasUsed = min(gasUsed, totalGas)
+	refund := gasUsed / params.RefundQuotientEIP3529
+	if refund > ibs.GetRefund() {
+		refund = ibs.GetRefund()
+	}
+	gasUsed -= refund
+	remaining := totalGas - gasUsed
+	if err := ibs.AddBalance(payer, new(uint256.Int).Mul(uint256.NewInt(remaining), gasPrice), tracing.BalanceIncreaseGasReturn); err != nil {
+		return nil, err
+	}
+	gp.AddGas(remaining)
+
+	tipped := new(uint256.Int).Mul(uint256.NewInt(gasUsed), effectiveTip)
+	tipped.Add(tipped, builderFee)
+	if err := ibs.AddBalance(coinbase, tipped, tracing.BalanceIncreaseRewardTransactionFee); err != nil {
+		return nil, err
+	}
+	if burntContractAddress := evm.ChainConfig().GetBurntContract(evm.Context.BlockNumber); burntContractAddress != nil && evm.Context.BaseFee != nil {
+		burnAmount := new(uint256.Int).Mul(uint256.NewInt(gasUsed), evm.Context.BaseFee)
+		if err := ibs.AddBalance(*burntContractAddress, burnAmount, tracing.BalanceChangeUnspecified); err != nil {
+			return nil, err
+		}
+	}
+
+	return &evmtypes.ExecutionResult{
+		UsedGas:    gasUsed,
+		Err:        vmerr,
+		Reverted:   vmerr == vm.ErrExecutionReverted,
+		ReturnData: ret,
+		FeeTipped:  tipped,
+	}, nil
+}
+
+// checkAAValidity checks the validity data returned by a validation frame: the frame selector as a magic
+// value followed by the uint48 validUntil and validAfter timestamps (a zero validUntil never expires).
+func checkAAValidity(ret []byte, magic []byte, time uint64) error {
+	if len(ret) < 32 {
+		return fmt.Errorf("short validity data: %d bytes", len(ret))
+	}
+	if !bytes.Equal(ret[:4], magic) {
+		return fmt.Errorf("wrong magic value %x", ret[:4])
+	}
+	var buf [8]byte
+	copy(buf[2:], ret[4:10])
+	validUntil := binary.BigEndian.Uint64(buf[:])
+	copy(buf[2:], ret[10:16])
+	validAfter := binary.BigEndian.Uint64(buf[:])
+	if validUntil != 0 && time > validUntil {
+		return fmt.Errorf("expired at %d", validUntil)
+	}
+	if time < validAfter {
+		return fmt.Errorf("not valid before %d", validAfter)
+	}
+	return nil
+}
+
+// aaFrameInput is the abi encoded input of the validation frames: (uint256 version, bytes32 txHash, bytes transaction).
+func aaFrameInput(selector []byte, txHash libcommon.Hash, transaction []byte) []byte {
+	input := append([]byte{}, selector...)
+	input = append(input, abiWord(uint256.NewInt(aaTransactionVersion))...)
+	input = append(input, txHash[:]...)
+	input = append(input, abiWord(uint256.NewInt(3*32))...)
+	return append(input, abiBytes(transaction)...)
+}
+
+// aaPostOpInput is the abi encoded input of the paymaster post-op frame: (bool success, uint256 actualGasCost, bytes context).
+func aaPostOpInput(success bool, actualGasCost *uint256.Int, context []byte) []byte {
+	input := append([]byte{}, aaPostPaymasterSelector...)
+	successWord := new(uint256.Int)
+	if success {
+		successWord.SetOne()
+	}
+	input = append(input, abiWord(successWord)...)
+	input = append(input, abiWord(actualGasCost)...)
+	input = append(input, abiWord(uint256.NewInt(3*32))...)
+	return append(input, abiBytes(context)...)
+}
+
+func abiWord(v *uint256.Int) []byte {
+	word := v.Bytes32()
+	return word[:]
+}
+
+// abiBytes encodes the length and the right-padded content of a dynamic bytes value.
+func abiBytes(b []byte) []byte {
+	out := abiWord(uint256.NewInt(uint64(len(b))))
+	out = append(out, b...)
+	if pad := len(b) % 32; pad != 0 {
+		out = append(out, make([]byte, 32-pad)...)
+	}
+	return out
+}
+
+// abiDynamicBytes decodes the dynamic bytes value whose offset is stored at position pos of the abi encoded ret.
+func abiDynamicBytes(ret []byte, pos int) ([]byte, error) {
+	if len(ret) < pos+32 {
+		return nil, errors.New("missing offset")
+	}
+	offset := new(uint256.Int).SetBytes(ret[pos : pos+32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(ret))-32 {
+		return nil, fmt.Errorf("offset %s out of bounds", offset)
+	}
+	start := offset.Uint64() + 32
+	length := new(uint256.Int).SetBytes(ret[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(ret))-start {
+		return nil, fmt.Errorf("length %s out of bounds", length)
+	}
+	return ret[start : start+length.Uint64()], nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/common/math"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	state2 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+	"github.com/erigontech/erigon/params"
+)
+
+var (
+	aaSender   = libcommon.HexToAddress("0xaa01")
+	aaCoinbase = libcommon.HexToAddress("0xc0")
+	// returns the validateTransaction magic value with no validity window
+	aaValidSenderCode = append(append([]byte{byte(vm.PUSH4)}, crypto.Keccak256([]byte("validateTransaction(uint256,bytes32,bytes)"))[:4]...),
+		byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN))
+	// returns 32 zero bytes, which isn't the magic value
+	aaInvalidSenderCode = []byte{byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN)}
+)
+
+func newAATestEVM(t *testing.T, senderCode []byte) *vm.EVM {
+	db, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	tx, err := db.BeginTemporalRw(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(tx.Rollback)
+	domains, err := state2.NewSharedDomains(tx, log.New())
+	require.NoError(t, err)
+	t.Cleanup(domains.Close)
+
+	chainConfig := *params.AllProtocolChanges
+	chainConfig.AllowAA = true
+	ibs := state.New(state.NewReaderV3(domains))
+	require.NoError(t, ibs.SetCode(aaSender, senderCode))
+	require.NoError(t, ibs.AddBalance(aaSender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified))
+	require.NoError(t, ibs.FinalizeTx(chainConfig.Rules(1, 0), state.NewNoopWriter()))
+
+	header := &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(10), Difficulty: big.NewInt(0), GasLimit: 30_000_000}
+	blockContext := core.NewEVMBlockContext(header, nil, nil, &aaCoinbase, &chainConfig)
+	return vm.NewEVM(blockContext, evmtypes.TxContext{}, ibs, &chainConfig, vm.Config{})
+}
+
+func newAATestTxn() *types.AccountAbstractionTransaction {
+	sender := aaSender
+	return &types.AccountAbstractionTransaction{
+		ChainID:            uint256.NewInt(1337),
+		SenderAddress:      &sender,
+		ExecutionData:      []byte{1, 2, 3},
+		Tip:                uint256.NewInt(5),
+		FeeCap:             uint256.NewInt(30),
+		ValidationGasLimit: 100_000,
+		GasLimit:           100_000,
+	}
+}
+
+func TestApplyAATransactionGasOverflow(t *testing.T) {
+	evm := newAATestEVM(t, aaValidSenderCode)
+	txn := newAATestTxn()
+	txn.GasLimit = math.MaxUint64 - 1
+	_, overflow := txn.TotalGas()
+	require.True(t, overflow)
+	require.Equal(t, uint64(math.MaxUint64), txn.GetGas())
+
+	gp := new(core.GasPool).AddGas(math.MaxUint64)
+	_, err := core.ApplyAATransaction(evm, txn, gp)
+	require.ErrorIs(t, err, core.ErrGasUintOverflow)
+	// nothing is charged
+	require.Equal(t, uint64(math.MaxUint64), gp.Gas())
+	balance, err := evm.IntraBlockState().GetBalance(aaSender)
+	require.NoError(t, err)
+	require.Equal(t, uint256.NewInt(params.Ether), balance)
+}
+
+func TestApplyAATransactionValidationFailure(t *testing.T) {
+	evm := newAATestEVM(t, aaInvalidSenderCode)
+	_, err := core.ApplyAATransaction(evm, newAATestTxn(), new(core.GasPool).AddGas(30_000_000))
+	require.ErrorIs(t, err, core.ErrAAValidation)
+}
+
+func TestApplyAATransactionRefund(t *testing.T) {
+	evm := newAATestEVM(t, aaValidSenderCode)
+	ibs := evm.IntraBlockState()
+	txn := newAATestTxn()
+	const poolGas = 30_000_000
+	gp := new(core.GasPool).AddGas(poolGas)
+
+	result, err := core.ApplyAATransaction(evm, txn, gp)
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+	require.Greater(t, result.UsedGas, txn.IntrinsicGas())
+	require.Less(t, result.UsedGas, txn.GetGas())
+
+	// the unused gas goes back to the block and to the payer, at base fee 10 plus tip 5
+	require.Equal(t, uint64(poolGas)-result.UsedGas, gp.Gas())
+	balance, err := ibs.GetBalance(aaSender)
+	require.NoError(t, err)
+	spent := new(uint256.Int).Sub(uint256.NewInt(params.Ether), balance)
+	require.Equal(t, uint256.NewInt(result.UsedGas*15), spent)
+	tipped, err := ibs.GetBalance(aaCoinbase)
+	require.NoError(t, err)
+	require.Equal(t, uint256.NewInt(result.UsedGas*5), tipped)
+	require.Equal(t, tipped, result.FeeTipped)
+	nonce, err := ibs.GetNonce(aaSender)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), nonce)
+}
@@ -17,6 +17,7 @@
 package core_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"math/big"
@@ -27,6 +28,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/chain/networkname"
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/datadir"
@@ -35,6 +37,7 @@ import (
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
@@ -181,3 +184,110 @@ func TestDecodeBalance0(t *testing.T) {
 	require.NoError(t, err)
 	_ = genesisData
 }
+
+func TestCustomChainSpec(t *testing.T) {
+	t.Parallel()
+	spec := `{
+		"chainName": "my-devnet",
+		"chainId": 1337,
+		"consensus": "ethash",
+		"homesteadBlock": 0,
+		"eip150Block": 0,
+		"eip155Block": 0,
+		"byzantiumBlock": 0,
+		"constantinopleBlock": 0,
+		"petersburgBlock": 0,
+		"istanbulBlock": 0,
+		"berlinBlock": 0,
+		"londonBlock": 0,
+		"extraEips": [3855],
+		"ethash": {}
+	}`
+	path := t.TempDir() + "/spec.json"
+	require.NoError(t, os.WriteFile(path, []byte(spec), 0o600))
+
+	config, err := params.ReadChainSpecFile(path)
+	require.NoError(t, err)
+	require.NoError(t, core.ValidateChainSpec(config))
+	require.Equal(t, []int{3855}, config.ExtraEips)
+
+	genesis := core.CustomGenesisBlock(config)
+	db, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	storedConfig, _, err := core.WriteGenesisBlock(tx, genesis, nil, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.Equal(t, config.ChainID, storedConfig.ChainID)
+
+	config.ExtraEips = []int{1}
+	require.Error(t, core.ValidateChainSpec(config))
+	config.ExtraEips = nil
+	config.ByzantiumBlock = big.NewInt(10) // after constantinople
+	require.Error(t, core.ValidateChainSpec(config))
+	config.ChainID = nil
+	require.Error(t, core.ValidateChainSpec(config))
+}
+
+func TestCustomCliqueGenesis(t *testing.T) {
+	t.Parallel()
+	signer1, signer2 := libcommon.HexToAddress("0x1"), libcommon.HexToAddress("0x2")
+	config := &chain.Config{
+		ChainID:   big.NewInt(1337),
+		Consensus: chain.CliqueConsensus,
+		Clique:    &chain.CliqueConfig{Period: 5, Epoch: 30000},
+	}
+	require.Error(t, core.ValidateChainSpec(config))
+
+	config.Clique.Signers = []libcommon.Address{signer1, signer2}
+	require.NoError(t, core.ValidateChainSpec(config))
+	genesis := core.CustomGenesisBlock(config)
+	// the signers come from the spec only, all the nodes of the devnet get the same genesis
+	extra := append(append(append(make([]byte, 32), signer1[:]...), signer2[:]...), make([]byte, crypto.SignatureLength)...)
+	require.Equal(t, extra, genesis.ExtraData)
+}
+
+func TestShadowForkGenesis(t *testing.T) {
+	require := require.New(t)
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+
+	const blockNum = 7
+	header, err := m.BlockReader.HeaderByNumber(m.Ctx, tx, blockNum)
+	require.NoError(err)
+	dumper, err := state.NewStateDumper(tx, rawdbv3.TxNums, blockNum)
+	require.NoError(err)
+
+	var buf bytes.Buffer
+	shadowFork := core.ShadowForkGenesis(m.ChainConfig, header, big.NewInt(1337))
+	err = core.WriteGenesisJSON(&buf, shadowFork, func(add func(libcommon.Address, *types.GenesisAccount) error) error {
+		_, err := dumper.Walk(nil, 0, false, func(account *state.DumpedAccount) error {
+			return add(account.Address, account.GenesisAccount())
+		})
+		return err
+	})
+	require.NoError(err)
+
+	var genesis types.Genesis
+	require.NoError(json.Unmarshal(buf.Bytes(), &genesis))
+	require.Equal(uint64(1337), genesis.Config.ChainID.Uint64())
+	require.Equal(header.GasLimit, genesis.GasLimit)
+	require.NotEmpty(genesis.Alloc)
+	block, _, err := core.GenesisToBlock(&genesis, datadir.New(t.TempDir()), log.New())
+	require.NoError(err)
+	require.Equal(header.Root, block.Root()) // balances, code and storage are preserved
+
+	mainnetHeader := &types.Header{Number: big.NewInt(15_000_000), Difficulty: big.NewInt(1), GasLimit: 30_000_000}
+	config := core.ShadowForkGenesis(params.MainnetChainConfig, mainnetHeader, big.NewInt(1337)).Config
+	require.Equal(uint64(0), config.LondonBlock.Uint64())
+	require.Equal(params.MainnetChainConfig.GrayGlacierBlock.Uint64()-15_000_000, config.GrayGlacierBlock.Uint64())
+	require.Equal(params.MainnetChainConfig.TerminalTotalDifficulty, config.TerminalTotalDifficulty)
+	require.Equal(params.MainnetChainConfig.ShanghaiTime, config.ShanghaiTime)
+	require.Equal(uint64(1), params.MainnetChainConfig.ChainID.Uint64())
+	mainnetHeader.Difficulty = big.NewInt(0)
+	config = core.ShadowForkGenesis(params.MainnetChainConfig, mainnetHeader, big.NewInt(1337)).Config
+	require.Equal(uint64(0), config.TerminalTotalDifficulty.Uint64())
+	require.True(config.TerminalTotalDifficultyPassed)
+}
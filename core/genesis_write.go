@@ -50,6 +50,7 @@ import (
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/tracing"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/params"
 )
 
@@ -420,6 +421,54 @@ func DeveloperGenesisBlock(period uint64, faucet libcommon.Address) *types.Genes
 	}
 }
 
+// CustomGenesisBlock returns the genesis of a custom devnet which chain spec is supplied with --chain.config.
+// Like the dev chain genesis it pre-funds the precompiles only, bor devnets also get the system contracts of
+// bor-devnet. A clique devnet is sealed by the signers of the spec, so all its nodes share the genesis.
+func CustomGenesisBlock(config *chain.Config) *types.Genesis {
+	genesis := &types.Genesis{
+		Config:     config,
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1),
+		Alloc:      readPrealloc("allocs/dev.json"),
+	}
+	if config.Clique != nil {
+		extra := make([]byte, 32)
+		for _, signer := range config.Clique.Signers {
+			extra = append(extra, signer[:]...)
+		}
+		genesis.ExtraData = append(extra, make([]byte, crypto.SignatureLength)...)
+	}
+	if config.Bor != nil {
+		genesis.Alloc = readPrealloc("allocs/bor_devnet.json")
+	}
+	return genesis
+}
+
+// ValidateChainSpec checks a chain spec supplied by the user (e.g. of a custom devnet) before the node starts
+func ValidateChainSpec(config *chain.Config) error {
+	if config.ChainID == nil || config.ChainID.Sign() <= 0 {
+		return errors.New("chainId must be positive")
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	if config.Consensus == chain.BorConsensus && config.Bor == nil {
+		return errors.New("bor consensus requires 'bor' config")
+	}
+	if config.Consensus == chain.CliqueConsensus && config.Clique == nil {
+		return errors.New("clique consensus requires 'clique' config")
+	}
+	if config.Clique != nil && len(config.Clique.Signers) == 0 {
+		return errors.New("clique config requires the initial 'signers'")
+	}
+	for _, eip := range config.ExtraEips {
+		if !vm.ValidEip(eip) {
+			return fmt.Errorf("EIP-%d can't be activated, supported: %v", eip, vm.ActivateableEips())
+		}
+	}
+	return nil
+}
+
 // ToBlock creates the genesis block and writes state of a genesis specification
 // to the given database (or discards it if nil).
 func GenesisToBlock(g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
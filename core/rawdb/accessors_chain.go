@@ -1355,6 +1355,27 @@ func ReadVerkleNode(tx kv.RwTx, root common.Hash) (verkle.VerkleNode, error) {
 	}
 	return verkle.ParseNode(encoded, 0, root[:])
 }
+
+// ReadWithdrawalsIndexFromBlock - the first block whose withdrawals are in kv.WithdrawalAddrIdx of the db: the blocks
+// before it were executed by a version without the index
+func ReadWithdrawalsIndexFromBlock(tx kv.Getter) (uint64, error) {
+	v, err := tx.GetOne(kv.DatabaseInfo, kv.WithdrawalsIndexFromBlockKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return 0, nil
+	}
+	if len(v) != 8 {
+		return 0, fmt.Errorf("incorrect length of withdrawals index start: %d", len(v))
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func WriteWithdrawalsIndexFromBlock(tx kv.Putter, blockNum uint64) error {
+	return tx.Put(kv.DatabaseInfo, kv.WithdrawalsIndexFromBlockKey, hexutility.EncodeTs(blockNum))
+}
+
 func WriteDBSchemaVersion(tx kv.RwTx) error {
 	var version [12]byte
 	binary.BigEndian.PutUint32(version[:], kv.DBSchemaVersion.Major)
@@ -36,30 +36,26 @@ type TxLookupEntry struct {
 	Index      uint64
 }
 
-// ReadTxLookupEntry retrieves the positional metadata associated with a transaction
-// hash to allow retrieving the transaction or receipt by hash.
-func ReadTxLookupEntry(db kv.Getter, txnHash libcommon.Hash) (blockNumber *uint64, txNum *uint64, err error) {
+// ReadTxLookupEntry retrieves the txNum of the transaction with the given hash. The block is resolved from the txNum
+// by the reader: entries don't need to be rewritten when the chain is unwound and re-executed, they just don't resolve
+// to the transaction anymore.
+func ReadTxLookupEntry(db kv.Getter, txnHash libcommon.Hash) (txNum uint64, ok bool, err error) {
 	data, err := db.GetOne(kv.TxLookup, txnHash.Bytes())
 	if err != nil {
-		return nil, nil, err
+		return 0, false, err
 	}
-	if len(data) != 16 {
-		return nil, nil, nil
+	if len(data) != 8 {
+		return 0, false, nil
 	}
-	numberBlockNum := binary.BigEndian.Uint64(data[:8])
-	numberTxNum := binary.BigEndian.Uint64(data[8:]) + 1
-
-	return &numberBlockNum, &numberTxNum, nil
+	return binary.BigEndian.Uint64(data), true, nil
 }
 
-// WriteTxLookupEntries stores a positional metadata for every transaction from
-// a block, enabling hash based transaction and receipt lookups.
-func WriteTxLookupEntries(db kv.Putter, block *types.Block, txNum uint64) {
-	data := make([]byte, 16)
+// WriteTxLookupEntries stores the txNum of every transaction from a block, enabling hash based transaction and
+// receipt lookups. firstTxNumInBlock is the txNum of the system transaction opening the block.
+func WriteTxLookupEntries(db kv.Putter, block *types.Block, firstTxNumInBlock uint64) {
+	data := make([]byte, 8)
 	for i, txn := range block.Transactions() {
-		binary.BigEndian.PutUint64(data[:8], block.NumberU64())
-		binary.BigEndian.PutUint64(data[8:], txNum+uint64(i)+1)
-
+		binary.BigEndian.PutUint64(data, firstTxNumInBlock+1+uint64(i))
 		if err := db.Put(kv.TxLookup, txn.Hash().Bytes(), data); err != nil {
 			log.Crit("Failed to store transaction lookup entry", "err", err)
 		}
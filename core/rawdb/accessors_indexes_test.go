@@ -30,7 +30,6 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
-	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/turbo/services"
@@ -68,7 +67,8 @@ func TestLookupStorage(t *testing.T) {
 			tx3 := types.NewTransaction(3, libcommon.BytesToAddress([]byte{0x33}), uint256.NewInt(333), 3333, uint256.NewInt(33333), []byte{0x33, 0x33, 0x33})
 			txs := []types.Transaction{tx1, tx2, tx3}
 
-			block := types.NewBlock(&types.Header{Number: big.NewInt(314)}, txs, nil, nil, nil)
+			// the block follows genesis: the lookup resolves the block from the txNum
+			block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, txs, nil, nil, nil)
 
 			// Check that no transactions entries are in a pristine database
 			for i, txn := range txs {
@@ -91,6 +91,11 @@ func TestLookupStorage(t *testing.T) {
 				t.Fatal(err)
 			}
 
+			// +2 - the system transactions opening and closing the block
+			if err := rawdbv3.TxNums.Append(tx, block.NumberU64(), txNumMin+uint64(len(txs))+1); err != nil {
+				t.Fatal(err)
+			}
+
 			tc.writeTxLookupEntries(tx, block, txNumMin)
 
 			for i, txn := range txs {
@@ -103,7 +108,7 @@ func TestLookupStorage(t *testing.T) {
 					if txn.Hash() != txn2.Hash() {
 						t.Fatalf("txn #%d [%x]: transaction mismatch: have %v, want %v", i, txn.Hash(), txn, txn2)
 					}
-					if txNum != txNumMin+uint64(i)+2 {
+					if txNum != txNumMin+uint64(i)+1 {
 						t.Fatalf("txn #%d [%x]: txnum mismatch: have %d, want %d", i, txn.Hash(), txNum, txNumMin+uint64(i)+1)
 					}
 				}
@@ -124,40 +129,21 @@ func TestLookupStorage(t *testing.T) {
 // ReadTransactionByHash retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func readTransactionByHash(db kv.Tx, hash libcommon.Hash, br services.FullBlockReader) (txn types.Transaction, blockHash libcommon.Hash, blockNumber uint64, txNum uint64, txIndex uint64, err error) {
-	blockNumberPtr, txNumPtr, err := rawdb.ReadTxLookupEntry(db, hash)
-	if err != nil {
+	txn, blockNumber, txNum, ok, err := br.TxnByHash(context.Background(), db, hash)
+	if err != nil || !ok {
 		return nil, libcommon.Hash{}, 0, 0, 0, err
 	}
-	if blockNumberPtr == nil {
-		return nil, libcommon.Hash{}, 0, 0, 0, nil
-	}
-	blockNumber = *blockNumberPtr
-	if txNumPtr == nil {
-		return nil, libcommon.Hash{}, 0, 0, 0, nil
-	}
-	txNum = *txNumPtr
-	blockHash, ok, err := br.CanonicalHash(context.Background(), db, blockNumber)
+	blockHash, ok, err = br.CanonicalHash(context.Background(), db, blockNumber)
 	if err != nil {
 		return nil, libcommon.Hash{}, 0, 0, 0, err
 	}
 	if !ok || blockHash == (libcommon.Hash{}) {
 		return nil, libcommon.Hash{}, 0, 0, 0, nil
 	}
-	body, _ := br.BodyWithTransactions(context.Background(), db, blockHash, blockNumber)
-	if body == nil {
-		log.Error("Transaction referenced missing", "number", blockNumber, "hash", blockHash)
-		return nil, libcommon.Hash{}, 0, 0, 0, nil
-	}
-	senders, err1 := rawdb.ReadSenders(db, blockHash, blockNumber)
-	if err1 != nil {
-		return nil, libcommon.Hash{}, 0, 0, 0, err1
-	}
-	body.SendersToTxs(senders)
-	for txInd, txnValue := range body.Transactions {
-		if txnValue.Hash() == hash {
-			return txnValue, blockHash, blockNumber, txNum, uint64(txInd), nil
-		}
+	txNumMin, err := rawdbv3.TxNums.Min(db, blockNumber)
+	if err != nil {
+		return nil, libcommon.Hash{}, 0, 0, 0, err
 	}
-	log.Error("Transaction not found", "number", blockNumber, "hash", blockHash, "txhash", hash)
-	return nil, libcommon.Hash{}, 0, 0, 0, nil
+	// -1 because block has system-txn in the beginning of block
+	return txn, blockHash, blockNumber, txNum, txNum - txNumMin - 1, nil
 }
@@ -2,6 +2,7 @@ package rawtemporaldb
 
 import (
 	"encoding/binary"
+	"math/big"
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/kv"
@@ -12,6 +13,7 @@ var (
 	CumulativeGasUsedInBlockKey     = []byte{0x0}
 	CumulativeBlobGasUsedInBlockKey = []byte{0x1}
 	FirstLogIndexKey                = []byte{0x2}
+	BlobGasPriceKey                 = []byte{0x3} // written by blob transactions only
 )
 
 // `ReadReceipt` does fill `rawLogs` calulated fields. but we don't need it anymore.
@@ -29,7 +31,15 @@ func ReceiptAsOfWithApply(tx kv.TemporalTx, txNum uint64, rawLogs types.Logs, tx
 		CumulativeGasUsed:        cumulativeGasUsedBeforeTxn,
 		FirstLogIndexWithinBlock: firstLogIndexWithinBlock,
 	}
-	_ = cumulativeBlobGasUsed
+	if txn.Type() == types.BlobTxType {
+		blobGasUsed, blobGasPrice, ok, err := BlobGasAsOf(tx, txNum, cumulativeBlobGasUsed)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			r.BlobGasUsed, r.BlobGasPrice = blobGasUsed, blobGasPrice
+		}
+	}
 
 	if err := r.DeriveFieldsV3ForSingleReceipt(txnIdx, blockHash, blockNum, txn, cumulativeGasUsedBeforeTxn); err != nil {
 		return nil, err
@@ -71,6 +81,23 @@ func ReceiptAsOf(tx kv.TemporalTx, txNum uint64) (cumGasUsed uint64, cumBlobGasu
 	return
 }
 
+// BlobGasAsOf returns the blob gas used by the blob transaction txNum and the blob gas price it paid, as written by the
+// execution. cumBlobGasUsed is the blob gas used in the block up to and including the transaction. ok is false if the
+// transaction was executed by a version which didn't write the blob gas price.
+func BlobGasAsOf(tx kv.TemporalTx, txNum uint64, cumBlobGasUsed uint64) (blobGasUsed uint64, blobGasPrice *big.Int, ok bool, err error) {
+	v, ok, err := tx.GetAsOf(kv.ReceiptDomain, BlobGasPriceKey, txNum+1)
+	if err != nil || !ok || len(v) == 0 {
+		return 0, nil, false, err
+	}
+	blobGasPrice = new(big.Int).SetBytes(v)
+
+	_, prevCumBlobGasUsed, _, err := ReceiptAsOf(tx, txNum)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	return cumBlobGasUsed - prevCumBlobGasUsed, blobGasPrice, true, nil
+}
+
 func AppendReceipt(ttx kv.TemporalPutDel, receipt *types.Receipt, cumBlobGasUsed uint64) error {
 	var cumGasUsedInBlock uint64
 	var firstLogIndexWithinBlock uint32
@@ -102,6 +129,12 @@ func AppendReceipt(ttx kv.TemporalPutDel, receipt *types.Receipt, cumBlobGasUsed
 			return err
 		}
 	}
+
+	if receipt != nil && receipt.BlobGasPrice != nil {
+		if err := ttx.DomainPut(kv.ReceiptDomain, BlobGasPriceKey, nil, receipt.BlobGasPrice.Bytes(), nil, 0); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
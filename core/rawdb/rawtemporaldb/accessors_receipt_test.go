@@ -2,6 +2,7 @@ package rawtemporaldb
 
 import (
 	"context"
+	"math/big"
 	"testing"
 
 	"github.com/erigontech/erigon-lib/common/datadir"
@@ -109,3 +110,45 @@ func TestAppendReceipt(t *testing.T) {
 	// reader
 
 }
+
+func TestBlobGasAsOf(t *testing.T) {
+	dirs, require := datadir.New(t.TempDir()), require.New(t)
+	db, _ := temporaltest.NewTestDB(t, dirs)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(err)
+	defer tx.Rollback()
+
+	ttx := tx.(kv.TemporalTx)
+	doms, err := state.NewSharedDomains(ttx, log.New())
+	require.NoError(err)
+	defer doms.Close()
+	doms.SetTx(ttx)
+
+	doms.SetTxNum(0) // block1, system txn
+	require.NoError(AppendReceipt(doms, nil, 0))
+	doms.SetTxNum(1) // block1, blob txn
+	require.NoError(AppendReceipt(doms, &types.Receipt{CumulativeGasUsed: 21000, BlobGasUsed: 131072, BlobGasPrice: big.NewInt(7)}, 131072))
+	doms.SetTxNum(2) // block1, legacy txn
+	require.NoError(AppendReceipt(doms, &types.Receipt{CumulativeGasUsed: 42000}, 131072))
+	doms.SetTxNum(3) // block1, blob txn
+	require.NoError(AppendReceipt(doms, &types.Receipt{CumulativeGasUsed: 63000, BlobGasUsed: 262144, BlobGasPrice: big.NewInt(9)}, 393216))
+
+	require.NoError(doms.Flush(context.Background(), tx))
+
+	blobGasUsed, blobGasPrice, ok, err := BlobGasAsOf(ttx, 1, 131072)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(uint64(131072), blobGasUsed)
+	require.Equal(big.NewInt(7), blobGasPrice)
+
+	blobGasUsed, blobGasPrice, ok, err = BlobGasAsOf(ttx, 3, 393216)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(uint64(262144), blobGasUsed)
+	require.Equal(big.NewInt(9), blobGasPrice)
+
+	// nothing written before the first blob txn
+	_, _, ok, err = BlobGasAsOf(ttx, 0, 0)
+	require.NoError(err)
+	require.False(ok)
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// ShadowForkGenesis returns the genesis, without the alloc, of a new chain with chainID starting from the state of the
+// block of the header: a shadow fork. The chain config is a copy of config where the block forks active at the block are
+// active since the genesis and the later ones are moved back by the number of the block, the time forks stay as they
+// are. The genesis has the timestamp, the gas limit and the fee parameters of the block.
+func ShadowForkGenesis(config *chain.Config, header *types.Header, chainID *big.Int) *types.Genesis {
+	n := header.Number.Uint64()
+	shift := func(fork *big.Int) *big.Int {
+		if fork == nil {
+			return nil
+		}
+		if fork.Uint64() <= n {
+			return big.NewInt(0)
+		}
+		return new(big.Int).SetUint64(fork.Uint64() - n)
+	}
+
+	c := *config
+	c.ChainID = new(big.Int).Set(chainID)
+	c.HomesteadBlock = shift(c.HomesteadBlock)
+	c.DAOForkBlock = shift(c.DAOForkBlock)
+	c.TangerineWhistleBlock = shift(c.TangerineWhistleBlock)
+	c.SpuriousDragonBlock = shift(c.SpuriousDragonBlock)
+	c.ByzantiumBlock = shift(c.ByzantiumBlock)
+	c.ConstantinopleBlock = shift(c.ConstantinopleBlock)
+	c.PetersburgBlock = shift(c.PetersburgBlock)
+	c.IstanbulBlock = shift(c.IstanbulBlock)
+	c.MuirGlacierBlock = shift(c.MuirGlacierBlock)
+	c.BerlinBlock = shift(c.BerlinBlock)
+	c.LondonBlock = shift(c.LondonBlock)
+	c.ArrowGlacierBlock = shift(c.ArrowGlacierBlock)
+	c.GrayGlacierBlock = shift(c.GrayGlacierBlock)
+	c.MergeNetsplitBlock = shift(c.MergeNetsplitBlock)
+	if c.TerminalTotalDifficulty != nil && header.Difficulty.Sign() == 0 {
+		// the block is after the merge, so is the whole new chain
+		c.TerminalTotalDifficulty = big.NewInt(0)
+		c.TerminalTotalDifficultyPassed = true
+	}
+
+	return &types.Genesis{
+		Config:        &c,
+		Timestamp:     header.Time,
+		ExtraData:     libcommon.Copy(header.Extra),
+		GasLimit:      header.GasLimit,
+		Difficulty:    new(big.Int).Set(header.Difficulty),
+		Mixhash:       header.MixDigest,
+		Coinbase:      header.Coinbase,
+		BaseFee:       header.BaseFee,
+		ExcessBlobGas: header.ExcessBlobGas,
+	}
+}
+
+// WriteGenesisJSON writes the genesis as JSON, with the alloc of the accounts walk adds instead of g.Alloc: the state
+// of a shadow fork is too big to hold it in memory. Only one account is held at a time, with all its storage. The output
+// is still one JSON document which genesis loading reads into memory whole, so it is usable for the state of devnets and
+// smaller test networks - at mainnet state size it is hundreds of GB that no node can init from.
+func WriteGenesisJSON(w io.Writer, g *types.Genesis, walk func(add func(libcommon.Address, *types.GenesisAccount) error) error) error {
+	withoutAlloc := *g
+	withoutAlloc.Alloc = types.GenesisAlloc{}
+	data, err := json.Marshal(withoutAlloc)
+	if err != nil {
+		return err
+	}
+	emptyAlloc := []byte(`"alloc":{}`)
+	i := bytes.Index(data, emptyAlloc)
+	if i < 0 {
+		return errors.New("no alloc in the genesis JSON")
+	}
+	// everything up to the opening brace of the alloc, then the accounts, then the rest
+	if _, err := w.Write(data[:i+len(emptyAlloc)-1]); err != nil {
+		return err
+	}
+	first := true
+	if err := walk(func(addr libcommon.Address, account *types.GenesisAccount) error {
+		value, err := json.Marshal(account)
+		if err != nil {
+			return err
+		}
+		sep := ","
+		if first {
+			sep, first = "", false
+		}
+		_, err = fmt.Fprintf(w, "%s\"%x\":%s", sep, addr, value)
+		return err
+	}); err != nil {
+		return err
+	}
+	_, err = w.Write(data[i+len(emptyAlloc)-1:])
+	return err
+}
@@ -34,6 +34,8 @@ import (
 	"github.com/erigontech/erigon-lib/types/accounts"
 )
 
+// Dumper is the legacy state dump: it can't resume in the middle of the storage of an account, and doesn't return a
+// root to verify the dump against. StateDumper streams the state of any size in bounded pages.
 type Dumper struct {
 	blockNumber  uint64
 	tx           kv.TemporalTx
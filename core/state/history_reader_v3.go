@@ -19,6 +19,10 @@ package state
 import (
 	"errors"
 	"fmt"
+	"math"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/kv"
@@ -28,11 +32,73 @@ import (
 
 var PrunedError = errors.New("old data not available due to pruning")
 
+// HistoryReaderCacheBudget bounds the bytes of keys and values a HistoryReaderCache keeps, the least recently
+// used ones are evicted beyond it
+const HistoryReaderCacheBudget = 64 * 1024 * 1024
+
+// HistoryReaderCache keeps the values read as of one txNum, so the readers serving one RPC request at
+// that txNum don't read the same account, storage or code from the history files again.
+type HistoryReaderCache struct {
+	txNum  uint64
+	budget int
+	mu     sync.Mutex
+	size   int                            // bytes of the keys and values in vals
+	vals   *simplelru.LRU[string, []byte] // domain-prefixed keys to values copied out of the db, nil when the key didn't exist
+}
+
+func NewHistoryReaderCache(txNum uint64) *HistoryReaderCache {
+	return newHistoryReaderCache(txNum, HistoryReaderCacheBudget)
+}
+
+func newHistoryReaderCache(txNum uint64, budget int) *HistoryReaderCache {
+	c := &HistoryReaderCache{txNum: txNum, budget: budget}
+	// the budget bounds the cache, not the number of entries
+	c.vals, _ = simplelru.NewLRU[string, []byte](math.MaxInt, func(k string, v []byte) { c.size -= len(k) + len(v) })
+	return c
+}
+
+func (c *HistoryReaderCache) TxNum() uint64 { return c.txNum }
+
+// Size returns the bytes of the keys and values kept
+func (c *HistoryReaderCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func historyReaderCacheKey(name kv.Domain, k []byte) string {
+	return string(append([]byte{byte(name)}, k...))
+}
+
+func (c *HistoryReaderCache) get(name kv.Domain, k []byte) (v []byte, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.vals.Get(historyReaderCacheKey(name, k))
+}
+
+func (c *HistoryReaderCache) put(name kv.Domain, k, v []byte) {
+	key := historyReaderCacheKey(name, k)
+	if len(key)+len(v) > c.budget {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.vals.Contains(key) {
+		return
+	}
+	c.vals.Add(key, common.Copy(v))
+	c.size += len(key) + len(v)
+	for c.size > c.budget {
+		c.vals.RemoveOldest()
+	}
+}
+
 // HistoryReaderV3 Implements StateReader and StateWriter
 type HistoryReaderV3 struct {
 	txNum uint64
 	trace bool
 	ttx   kv.TemporalTx
+	cache *HistoryReaderCache
 }
 
 func NewHistoryReaderV3() *HistoryReaderV3 {
@@ -43,10 +109,40 @@ func (hr *HistoryReaderV3) String() string {
 	return fmt.Sprintf("txNum:%d", hr.txNum)
 }
 func (hr *HistoryReaderV3) SetTx(tx kv.TemporalTx) { hr.ttx = tx }
-func (hr *HistoryReaderV3) SetTxNum(txNum uint64)  { hr.txNum = txNum }
 func (hr *HistoryReaderV3) GetTxNum() uint64       { return hr.txNum }
 func (hr *HistoryReaderV3) SetTrace(trace bool)    { hr.trace = trace }
 
+// SetTxNum drops the cache if it was filled as of another txNum.
+func (hr *HistoryReaderV3) SetTxNum(txNum uint64) {
+	hr.txNum = txNum
+	if hr.cache != nil && hr.cache.txNum != txNum {
+		hr.cache = nil
+	}
+}
+
+// SetCache pins the reader to the txNum of the cache and makes it share the values read with other readers of the cache.
+func (hr *HistoryReaderV3) SetCache(cache *HistoryReaderCache) {
+	hr.cache = cache
+	hr.txNum = cache.txNum
+}
+
+func (hr *HistoryReaderV3) getAsOf(name kv.Domain, k []byte) (v []byte, ok bool, err error) {
+	if hr.cache == nil {
+		return hr.ttx.GetAsOf(name, k, hr.txNum)
+	}
+	if v, ok = hr.cache.get(name, k); ok {
+		return v, v != nil, nil
+	}
+	if v, ok, err = hr.ttx.GetAsOf(name, k, hr.txNum); err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		v = nil
+	}
+	hr.cache.put(name, k, v)
+	return v, ok, nil
+}
+
 // Gets the txNum where Account, Storage and Code history begins.
 // If the node is an archive node all history will be available therefore
 // the result will be 0.
@@ -72,7 +168,7 @@ func (hr *HistoryReaderV3) ResetReadSet()                     {}
 func (hr *HistoryReaderV3) DiscardReadList()                  {}
 
 func (hr *HistoryReaderV3) ReadAccountData(address common.Address) (*accounts.Account, error) {
-	enc, ok, err := hr.ttx.GetAsOf(kv.AccountsDomain, address[:], hr.txNum)
+	enc, ok, err := hr.getAsOf(kv.AccountsDomain, address[:])
 	if err != nil || !ok || len(enc) == 0 {
 		if hr.trace {
 			fmt.Printf("ReadAccountData [%x] => []\n", address)
@@ -97,7 +193,7 @@ func (hr *HistoryReaderV3) ReadAccountDataForDebug(address common.Address) (*acc
 
 func (hr *HistoryReaderV3) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
 	k := append(address[:], key.Bytes()...)
-	enc, _, err := hr.ttx.GetAsOf(kv.StorageDomain, k, hr.txNum)
+	enc, _, err := hr.getAsOf(kv.StorageDomain, k)
 	if hr.trace {
 		fmt.Printf("ReadAccountStorage [%x] [%x] => [%x]\n", address, *key, enc)
 	}
@@ -107,7 +203,7 @@ func (hr *HistoryReaderV3) ReadAccountStorage(address common.Address, incarnatio
 func (hr *HistoryReaderV3) ReadAccountCode(address common.Address, incarnation uint64) ([]byte, error) {
 	//  must pass key2=Nil here: because Erigon4 does concatinate key1+key2 under the hood
 	//code, _, err := hr.ttx.GetAsOf(kv.CodeDomain, address.Bytes(), codeHash.Bytes(), hr.txNum)
-	code, _, err := hr.ttx.GetAsOf(kv.CodeDomain, address[:], hr.txNum)
+	code, _, err := hr.getAsOf(kv.CodeDomain, address[:])
 	if hr.trace {
 		fmt.Printf("ReadAccountCode [%x] => [%x]\n", address, code)
 	}
@@ -115,12 +211,12 @@ func (hr *HistoryReaderV3) ReadAccountCode(address common.Address, incarnation u
 }
 
 func (hr *HistoryReaderV3) ReadAccountCodeSize(address common.Address, incarnation uint64) (int, error) {
-	enc, _, err := hr.ttx.GetAsOf(kv.CodeDomain, address[:], hr.txNum)
+	enc, _, err := hr.getAsOf(kv.CodeDomain, address[:])
 	return len(enc), err
 }
 
 func (hr *HistoryReaderV3) ReadAccountIncarnation(address common.Address) (uint64, error) {
-	enc, ok, err := hr.ttx.GetAsOf(kv.AccountsDomain, address.Bytes(), hr.txNum)
+	enc, ok, err := hr.getAsOf(kv.AccountsDomain, address.Bytes())
 	if err != nil || !ok || len(enc) == 0 {
 		if hr.trace {
 			fmt.Printf("ReadAccountIncarnation [%x] => [0]\n", address)
@@ -0,0 +1,61 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+func TestHistoryReaderCacheBudget(t *testing.T) {
+	c := newHistoryReaderCache(1, 100)
+
+	// 1 byte of domain, 20 of key, 20 of value
+	for i := byte(0); i < 4; i++ {
+		c.put(kv.AccountsDomain, make20(i), make20(i))
+	}
+	require.Equal(t, 82, c.Size()) // the first two were evicted
+
+	_, ok := c.get(kv.AccountsDomain, make20(0))
+	require.False(t, ok)
+	v, ok := c.get(kv.AccountsDomain, make20(3))
+	require.True(t, ok)
+	require.Equal(t, make20(3), v)
+
+	// the same key in another domain is another entry, reading 3 made 2 the least recently used
+	c.put(kv.CodeDomain, make20(3), nil)
+	_, ok = c.get(kv.AccountsDomain, make20(2))
+	require.False(t, ok)
+	v, ok = c.get(kv.CodeDomain, make20(3))
+	require.True(t, ok)
+	require.Nil(t, v)
+	require.LessOrEqual(t, c.Size(), 100)
+
+	// values bigger than the budget are not kept
+	c.put(kv.CodeDomain, make20(4), make([]byte, 100))
+	_, ok = c.get(kv.CodeDomain, make20(4))
+	require.False(t, ok)
+}
+
+func make20(b byte) []byte {
+	k := make([]byte, 20)
+	k[19] = b
+	return k
+}
@@ -23,6 +23,8 @@ package state
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 
 	"github.com/holiman/uint256"
@@ -117,6 +119,45 @@ func New(stateReader StateReader) *IntraBlockState {
 	}
 }
 
+// Fork returns a copy-on-write copy of the state, e.g. to execute several candidate payloads or simulations
+// on top of the same pre-state without reading it again from the state reader, which is shared by the forks.
+// Account objects are shared between sdb and the fork until either of them accesses them, at which point
+// that side makes its own copy. Both sdb and the fork may be used afterwards (but not concurrently).
+// It must be called between transactions: the journal isn't forked.
+func (sdb *IntraBlockState) Fork() *IntraBlockState {
+	if sdb.journal.length() > 0 {
+		panic(fmt.Errorf("IntraBlockState.Fork called in the middle of a transaction, journal length %d", sdb.journal.length()))
+	}
+	for _, obj := range sdb.stateObjects {
+		obj.shared = true
+	}
+	fork := &IntraBlockState{
+		stateReader:       sdb.stateReader,
+		stateObjects:      maps.Clone(sdb.stateObjects),
+		stateObjectsDirty: maps.Clone(sdb.stateObjectsDirty),
+		nilAccounts:       maps.Clone(sdb.nilAccounts),
+		savedErr:          sdb.savedErr,
+		refund:            sdb.refund,
+		txIndex:           sdb.txIndex,
+		logs:              make([]types.Logs, len(sdb.logs)),
+		logSize:           sdb.logSize,
+		accessList:        sdb.accessList.Copy(),
+		transientStorage:  sdb.transientStorage.Copy(),
+		journal:           newJournal(),
+		trace:             sdb.trace,
+		tracingHooks:      sdb.tracingHooks,
+		balanceInc:        make(map[libcommon.Address]*BalanceIncrease, len(sdb.balanceInc)),
+	}
+	for i, logs := range sdb.logs {
+		fork.logs[i] = slices.Clone(logs)
+	}
+	for addr, bi := range sdb.balanceInc {
+		cpy := *bi
+		fork.balanceInc[addr] = &cpy
+	}
+	return fork
+}
+
 func (sdb *IntraBlockState) SetHooks(hooks *tracing.Hooks) {
 	sdb.tracingHooks = hooks
 }
@@ -677,7 +718,7 @@ func (sdb *IntraBlockState) GetTransientState(addr libcommon.Address, key libcom
 func (sdb *IntraBlockState) getStateObject(addr libcommon.Address) (stateObject *stateObject, err error) {
 	// Prefer 'live' objects.
 	if obj := sdb.stateObjects[addr]; obj != nil {
-		return obj, nil
+		return sdb.ownStateObject(addr, obj), nil
 	}
 
 	// Load the object from the database.
@@ -706,6 +747,16 @@ func (sdb *IntraBlockState) getStateObject(addr libcommon.Address) (stateObject
 	return obj, nil
 }
 
+// ownStateObject replaces an object shared with other forks by a private copy, so that it can be modified.
+func (sdb *IntraBlockState) ownStateObject(addr libcommon.Address, obj *stateObject) *stateObject {
+	if !obj.shared {
+		return obj
+	}
+	obj = obj.deepCopy(sdb)
+	sdb.stateObjects[addr] = obj
+	return obj
+}
+
 func (sdb *IntraBlockState) setStateObject(addr libcommon.Address, object *stateObject) {
 	if bi, ok := sdb.balanceInc[addr]; ok && !bi.transferred {
 		object.data.Balance.Add(&object.data.Balance, &bi.increase)
@@ -891,7 +942,9 @@ func (sdb *IntraBlockState) FinalizeTx(chainRules *chain.Rules, stateWriter Stat
 	}
 	for addr := range sdb.journal.dirties {
 		so, exist := sdb.stateObjects[addr]
-		if !exist {
+		if exist {
+			so = sdb.ownStateObject(addr, so)
+		} else {
 			// ripeMD is 'touched' at block 1714175, in txn 0x1237f737031e40bcde4a8b7e717b2d15e3ecadfe49bb1bbc71ee9deb09c6fcf2
 			// That txn goes out of gas, and although the notion of 'touched' does not exist there, the
 			// touch-event will still be recorded in the journal. Since ripeMD is a special snowflake,
@@ -957,6 +1010,7 @@ func (sdb *IntraBlockState) MakeWriteSet(chainRules *chain.Rules, stateWriter St
 		sdb.stateObjectsDirty[addr] = struct{}{}
 	}
 	for addr, stateObject := range sdb.stateObjects {
+		stateObject = sdb.ownStateObject(addr, stateObject)
 		_, isDirty := sdb.stateObjectsDirty[addr]
 		if err := updateAccount(chainRules.IsSpuriousDragon, chainRules.IsAura, stateWriter, addr, stateObject, isDirty, sdb.tracingHooks); err != nil {
 			return err
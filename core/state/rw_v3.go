@@ -67,6 +67,7 @@ func NewStateV3(domains *libstate.SharedDomains, logger log.Logger) *StateV3 {
 
 func (rs *StateV3) ReTry(txTask *TxTask, in *QueueWithRetry) {
 	txTask.Reset()
+	txTask.Incarnation++
 	in.ReTry(txTask)
 }
 func (rs *StateV3) AddWork(ctx context.Context, txTask *TxTask, in *QueueWithRetry) {
@@ -232,6 +233,20 @@ func (rs *StateV3) ApplyLogsAndTraces4(txTask *TxTask, domains *libstate.SharedD
 			}
 		}
 	}
+
+	if txTask.Final {
+		// withdrawals are processed by the block end: indexed at its txNum, once per recipient
+		withdrawalTos := make(map[common.Address]struct{}, len(txTask.Withdrawals))
+		for _, w := range txTask.Withdrawals {
+			if _, ok := withdrawalTos[w.Address]; ok {
+				continue
+			}
+			withdrawalTos[w.Address] = struct{}{}
+			if err := domains.IndexAdd(kv.TblWithdrawalAddressIdx, w.Address[:]); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,64 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/stream"
+	"github.com/erigontech/erigon-lib/log/v3"
+	stateLib "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/core/types"
+)
+
+func TestApplyLogsAndTracesIndexesWithdrawals(t *testing.T) {
+	t.Parallel()
+	_, tx, _ := NewTestTemporalDb(t)
+
+	domains, err := stateLib.NewSharedDomains(tx, log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+	rs := NewStateV3(domains, log.New())
+
+	addr1, addr2 := common.Address{1}, common.Address{2}
+	tasks := []*TxTask{
+		{TxNum: 3, BlockNum: 1, Final: true, Withdrawals: types.Withdrawals{{Index: 0, Address: addr1}, {Index: 1, Address: addr1}}},
+		// not the block end: the withdrawals are not processed yet
+		{TxNum: 5, BlockNum: 2, Withdrawals: types.Withdrawals{{Index: 2, Address: addr2}}},
+		{TxNum: 6, BlockNum: 2, Final: true, Withdrawals: types.Withdrawals{{Index: 2, Address: addr2}}},
+		{TxNum: 9, BlockNum: 3, Final: true, Withdrawals: types.Withdrawals{{Index: 3, Address: addr2}, {Index: 4, Address: addr1}}},
+	}
+	for _, txTask := range tasks {
+		domains.SetTxNum(txTask.TxNum)
+		require.NoError(t, rs.ApplyLogsAndTraces4(txTask, domains))
+	}
+	require.NoError(t, domains.Flush(context.Background(), tx))
+
+	for addr, expected := range map[common.Address][]uint64{addr1: {3, 9}, addr2: {6, 9}} {
+		it, err := tx.IndexRange(kv.WithdrawalAddrIdx, addr[:], 0, -1, order.Asc, kv.Unlim)
+		require.NoError(t, err)
+		txNums, err := stream.ToArrayU64(it)
+		require.NoError(t, err)
+		require.Equal(t, expected, txNums)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/common/nibbles"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/etl"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlphacks"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// StateDumper reads the complete state as of the end of a block from the domains, in a deterministic order: the
+// accounts by address, each of them followed by its storage slots by key. Unlike Dumper, it resumes in the middle
+// of the storage of an account, so that the pages of the dump are bounded whatever the size of the contracts.
+type StateDumper struct {
+	tx    kv.TemporalTx
+	txNum uint64
+}
+
+// DumpedAccount is an account of the state dump. An account whose storage doesn't fit into a page is dumped again
+// in the next page, with the rest of its storage.
+type DumpedAccount struct {
+	Address  common.Address   `json:"address"`
+	Balance  hexutil.Big      `json:"balance"`
+	Nonce    hexutil.Uint64   `json:"nonce"`
+	CodeHash common.Hash      `json:"codeHash"`
+	Code     hexutility.Bytes `json:"code,omitempty"`
+	Storage  []DumpedSlot     `json:"storage,omitempty"`
+}
+
+type DumpedSlot struct {
+	Key   common.Hash      `json:"key"`
+	Value hexutility.Bytes `json:"value"`
+}
+
+// GenesisAccount returns the account as an account of a genesis alloc
+func (a *DumpedAccount) GenesisAccount() *types.GenesisAccount {
+	account := &types.GenesisAccount{
+		Balance: a.Balance.ToInt(),
+		Nonce:   uint64(a.Nonce),
+		Code:    a.Code,
+	}
+	if len(a.Storage) > 0 {
+		account.Storage = make(map[common.Hash]common.Hash, len(a.Storage))
+		for _, slot := range a.Storage {
+			account.Storage[slot.Key] = common.BytesToHash(slot.Value)
+		}
+	}
+	return account
+}
+
+func NewStateDumper(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, blockNum uint64) (*StateDumper, error) {
+	txNum, err := txNumsReader.Min(tx, blockNum+1)
+	if err != nil {
+		return nil, err
+	}
+	return &StateDumper{tx: tx, txNum: txNum}, nil
+}
+
+// Walk calls onAccount with the accounts from the cursor start on: an address, or an address followed by the storage
+// key to resume its storage from, nil for the beginning of the state. Stops after limit accounts and storage slots
+// (unlimited if limit is 0) and returns the cursor of the rest of the state, nil if the whole state is dumped.
+func (d *StateDumper) Walk(start []byte, limit int, excludeCode bool, onAccount func(*DumpedAccount) error) ([]byte, error) {
+	if len(start) != 0 && len(start) != length.Addr && len(start) != length.Addr+length.Hash {
+		return nil, fmt.Errorf("invalid state dump cursor %x", start)
+	}
+	var startSlot []byte
+	if len(start) > length.Addr {
+		start, startSlot = start[:length.Addr], start[length.Addr:]
+	}
+
+	it, err := d.tx.RangeAsOf(kv.AccountsDomain, start, nil, d.txNum, order.Asc, kv.Unlim) //unlim because need skip empty vals
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var items int
+	var acc accounts.Account
+	for it.HasNext() {
+		k, v, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(v) == 0 {
+			continue
+		}
+		resumed := startSlot != nil && bytes.Equal(k, start)
+		if !resumed {
+			if limit > 0 && items >= limit {
+				return common.Copy(k), nil
+			}
+			items++
+		}
+		if err := accounts.DeserialiseV3(&acc, v); err != nil {
+			return nil, fmt.Errorf("decoding %x for %x: %w", v, k, err)
+		}
+		account := &DumpedAccount{
+			Address:  common.BytesToAddress(k),
+			Balance:  hexutil.Big(*acc.Balance.ToBig()),
+			Nonce:    hexutil.Uint64(acc.Nonce),
+			CodeHash: acc.CodeHash,
+		}
+		if !excludeCode && acc.CodeHash != trie.EmptyCodeHash {
+			if account.Code, _, err = d.tx.GetAsOf(kv.CodeDomain, k, d.txNum); err != nil {
+				return nil, err
+			}
+		}
+
+		from := k
+		if resumed {
+			from = append(common.Copy(k), startSlot...)
+		}
+		to, _ := kv.NextSubtree(k)
+		next, err := d.walkStorage(account, from, to, limit, &items)
+		if err != nil {
+			return nil, err
+		}
+		if err := onAccount(account); err != nil {
+			return nil, err
+		}
+		if next != nil {
+			return next, nil
+		}
+	}
+	return nil, nil
+}
+
+// walkStorage appends the storage slots [from, to) to the account, returns the cursor of the first slot beyond the limit
+func (d *StateDumper) walkStorage(account *DumpedAccount, from, to []byte, limit int, items *int) ([]byte, error) {
+	it, err := d.tx.RangeAsOf(kv.StorageDomain, from, to, d.txNum, order.Asc, kv.Unlim) //unlim because need skip empty vals
+	if err != nil {
+		return nil, fmt.Errorf("walking over storage for %x: %w", account.Address, err)
+	}
+	defer it.Close()
+	for it.HasNext() {
+		k, v, err := it.Next()
+		if err != nil {
+			return nil, fmt.Errorf("walking over storage for %x: %w", account.Address, err)
+		}
+		if len(v) == 0 {
+			continue // Skip deleted entries
+		}
+		if limit > 0 && *items >= limit {
+			return common.Copy(k), nil
+		}
+		*items++
+		account.Storage = append(account.Storage, DumpedSlot{Key: common.BytesToHash(k[length.Addr:]), Value: common.Copy(v)})
+	}
+	return nil, nil
+}
+
+// StateRootHasher computes the state root of a state dump, to verify it against the root of the block. The accounts
+// must be added in the order of the dump, the pieces of an account split across pages one after another. The account
+// leaves are sorted by their hashed keys in tmpdir, the storage of an account is hashed in memory.
+type StateRootHasher struct {
+	leaves  *etl.Collector
+	current *DumpedAccount
+	storage *trie.Trie
+}
+
+func NewStateRootHasher(tmpdir string, logger log.Logger) *StateRootHasher {
+	leaves := etl.NewCollector("StateRootHasher", tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize/2), logger)
+	leaves.LogLvl(log.LvlDebug)
+	return &StateRootHasher{leaves: leaves}
+}
+
+// Add adds the account, or the rest of the storage of the account added last, to the state
+func (h *StateRootHasher) Add(account *DumpedAccount) error {
+	if h.current == nil || h.current.Address != account.Address {
+		if err := h.flushAccount(); err != nil {
+			return err
+		}
+		h.current, h.storage = account, trie.New(common.Hash{})
+	}
+	for _, slot := range account.Storage {
+		h.storage.Update(crypto.Keccak256(slot.Key[:]), common.Copy(bytes.TrimLeft(slot.Value, "\x00")))
+	}
+	return nil
+}
+
+// flushAccount collects the leaf of the account added last, with the root of its storage
+func (h *StateRootHasher) flushAccount() error {
+	if h.current == nil {
+		return nil
+	}
+	acc := accounts.Account{
+		Nonce:    uint64(h.current.Nonce),
+		Root:     h.storage.Hash(),
+		CodeHash: h.current.CodeHash,
+	}
+	acc.Balance.SetFromBig(h.current.Balance.ToInt())
+	key := crypto.Keccak256(h.current.Address[:])
+	h.current, h.storage = nil, nil
+	return h.leaves.Collect(key, acc.RLP())
+}
+
+// Root returns the root of the state added so far. The hasher can't be used after it.
+func (h *StateRootHasher) Root() (common.Hash, error) {
+	if err := h.flushAccount(); err != nil {
+		return common.Hash{}, err
+	}
+	hb := trie.NewHashBuilder(false)
+	var groups, branches, hashes []uint16
+	var leafData trie.GenStructStepLeafData
+	var curr, succ, value []byte
+	// the hash builder needs the key of the next leaf to build the current one, leaves are built one step behind
+	step := func(next []byte) error {
+		curr, succ = append(curr[:0], succ...), succ[:0]
+		if next != nil {
+			succ = append(succ, nibbles.KeybytesToHex(next)...)
+		}
+		if len(curr) == 0 {
+			return nil
+		}
+		leafData.Value = rlphacks.RlpEncodedBytes(value)
+		var err error
+		groups, branches, hashes, err = trie.GenStructStep(func(_ []byte) bool { return false }, curr, succ, hb, nil /* hashCollector */, &leafData, groups, branches, hashes, false)
+		return err
+	}
+	var empty = true
+	if err := h.leaves.Load(nil, "", func(k, v []byte, _ etl.CurrentTableReader, _ etl.LoadNextFunc) error {
+		empty = false
+		if err := step(k); err != nil {
+			return err
+		}
+		value = append(value[:0], v...)
+		return nil
+	}, etl.TransformArgs{}); err != nil {
+		return common.Hash{}, err
+	}
+	if empty {
+		return trie.EmptyRoot, nil
+	}
+	if err := step(nil); err != nil {
+		return common.Hash{}, err
+	}
+	return hb.RootHash()
+}
+
+func (h *StateRootHasher) Close() { h.leaves.Close() }
@@ -89,6 +89,7 @@ type stateObject struct {
 	deleted         bool // true if account was deleted during the lifetime of this object
 	newlyCreated    bool // true if this object was created in the current transaction
 	createdContract bool // true if this object represents a newly created contract
+	shared          bool // true if this object is shared between forks of the IntraBlockState, it must be copied before being modified
 }
 
 // empty returns whether the account is considered empty.
@@ -120,6 +121,27 @@ func newObject(db *IntraBlockState, address libcommon.Address, data, original *a
 	return &so
 }
 
+// deepCopy returns an independent copy of so bound to db.
+func (so *stateObject) deepCopy(db *IntraBlockState) *stateObject {
+	cpy := &stateObject{
+		address:            so.address,
+		db:                 db,
+		code:               so.code,
+		originStorage:      so.originStorage.Copy(),
+		blockOriginStorage: so.blockOriginStorage.Copy(),
+		dirtyStorage:       so.dirtyStorage.Copy(),
+		fakeStorage:        so.fakeStorage.Copy(),
+		dirtyCode:          so.dirtyCode,
+		selfdestructed:     so.selfdestructed,
+		deleted:            so.deleted,
+		newlyCreated:       so.newlyCreated,
+		createdContract:    so.createdContract,
+	}
+	cpy.data.Copy(&so.data)
+	cpy.original.Copy(&so.original)
+	return cpy
+}
+
 // EncodeRLP implements rlp.Encoder.
 func (so *stateObject) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, so.data)
@@ -484,3 +484,53 @@ func TestDump(t *testing.T) {
 		t.Fatalf("dump mismatch:\ngot: %s\nwant: %s\n", got, want)
 	}
 }
+
+func TestFork(t *testing.T) {
+	t.Parallel()
+	_, tx, _ := NewTestTemporalDb(t)
+
+	domains, err := stateLib.NewSharedDomains(tx, log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+
+	addr := common.Address{0x01}
+	key := common.Hash{0x02}
+
+	state := New(NewReaderV3(domains))
+	require.NoError(t, state.AddBalance(addr, uint256.NewInt(10), tracing.BalanceChangeUnspecified))
+	require.NoError(t, state.SetState(addr, &key, *uint256.NewInt(1)))
+	state.SoftFinalise()
+
+	fork := state.Fork()
+	require.NoError(t, fork.AddBalance(addr, uint256.NewInt(5), tracing.BalanceChangeUnspecified))
+	require.NoError(t, fork.SetState(addr, &key, *uint256.NewInt(2)))
+	require.NoError(t, state.SetNonce(addr, 7))
+
+	balance, err := state.GetBalance(addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), balance.Uint64())
+	balance, err = fork.GetBalance(addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(15), balance.Uint64())
+
+	var value uint256.Int
+	require.NoError(t, state.GetState(addr, &key, &value))
+	require.Equal(t, uint64(1), value.Uint64())
+	require.NoError(t, fork.GetState(addr, &key, &value))
+	require.Equal(t, uint64(2), value.Uint64())
+
+	nonce, err := fork.GetNonce(addr)
+	require.NoError(t, err)
+	require.Zero(t, nonce)
+
+	// reverting the fork doesn't affect the original state
+	snapshot := fork.Snapshot()
+	require.NoError(t, fork.SetNonce(addr, 3))
+	fork.RevertToSnapshot(snapshot)
+	nonce, err = fork.GetNonce(addr)
+	require.NoError(t, err)
+	require.Zero(t, nonce)
+	nonce, err = state.GetNonce(addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), nonce)
+}
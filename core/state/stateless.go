@@ -67,6 +67,11 @@ func NewStateless(stateRoot common.Hash, blockWitness *trie.Witness, blockNr uin
 			return nil, fmt.Errorf("state root mistmatch when creating Stateless2, got %x, expected %x", t.Hash(), stateRoot)
 		}
 	}
+	return NewStatelessFromTrie(t, blockNr, trace), nil
+}
+
+// NewStatelessFromTrie creates a new instance of Stateless on top of an already constructed state trie
+func NewStatelessFromTrie(t *trie.Trie, blockNr uint64, trace bool) *Stateless {
 	return &Stateless{
 		t:              t,
 		codeUpdates:    make(map[common.Hash][]byte),
@@ -76,7 +81,7 @@ func NewStateless(stateRoot common.Hash, blockWitness *trie.Witness, blockNr uin
 		created:        make(map[common.Hash]struct{}),
 		blockNr:        blockNr,
 		trace:          trace,
-	}, nil
+	}
 }
 
 // SetBlockNr changes the block number associated with this
@@ -49,3 +49,12 @@ func (t transientStorage) Get(addr libcommon.Address, key libcommon.Hash) uint25
 	}
 	return val[key]
 }
+
+// Copy does a deep copy of the transientStorage
+func (t transientStorage) Copy() transientStorage {
+	storage := make(transientStorage, len(t))
+	for key, value := range t {
+		storage[key] = value.Copy()
+	}
+	return storage
+}
@@ -77,6 +77,13 @@ type TxTask struct {
 
 	UsedGas uint64
 
+	// Incarnation is amount of re-executions of the task caused by conflicts
+	Incarnation int
+	// queuedAt is the amount of tasks dispatched by QueueWithRetry when the task waiting for re-execution was queued
+	queuedAt uint64
+	// retryIdx is the index of the task in the retryQueue, -1 once popped
+	retryIdx int
+
 	// BlockReceipts is used only by Gnosis:
 	//  - it does store `proof, err := rlp.EncodeToBytes(ValidatorSetProof{Header: header, Receipts: r})`
 	//  - and later read it by filter: len(l.Topics) == 2 && l.Address == s.contractAddress && l.Topics[0] == EVENT_NAME_HASH && l.Topics[1] == header.ParentHash
@@ -91,6 +98,9 @@ func (t *TxTask) Sender() *libcommon.Address {
 	if t.sender != nil {
 		return t.sender
 	}
+	if t.Tx == nil { // the block init and end have no sender
+		return nil
+	}
 	if sender, ok := t.Tx.GetSender(); ok {
 		t.sender = &sender
 		return t.sender
@@ -154,6 +164,10 @@ func (t *TxTask) createReceipt(cumulativeGasUsed uint64) *types.Receipt {
 		l.BlockNumber = blockNum
 		l.BlockHash = receipt.BlockHash
 	}
+	if t.Tx.Type() == types.BlobTxType {
+		receipt.BlobGasUsed = t.Tx.GetBlobGas()
+		receipt.BlobGasPrice = t.EvmBlockContext.BlobBaseFee.ToBig()
+	}
 	if t.Failed {
 		receipt.Status = types.ReceiptStatusFailed
 	} else {
@@ -206,16 +220,97 @@ func (h *TxTaskQueue) Pop() interface{} {
 	return x
 }
 
+// RetryAgingThreshold - tasks re-executed at least this amount of times go before all other tasks.
+// Otherwise task which conflicts again and again may wait behind stream of lower TxNum's.
+const RetryAgingThreshold = 4
+
+// RetryAgingDispatches - task waiting for re-execution while this amount of other tasks (retried or new) were
+// dispatched goes before all other tasks, the longest waiting first.
+const RetryAgingDispatches = 64
+
+// retryQueue non-thread-safe priority-queue of tasks to re-execute: aged tasks first, then by TxNum
+type retryQueue []*TxTask
+
+func (h retryQueue) Len() int {
+	return len(h)
+}
+func (h retryQueue) Less(i, j int) bool {
+	iAged, jAged := h[i].Incarnation >= RetryAgingThreshold, h[j].Incarnation >= RetryAgingThreshold
+	if iAged != jAged {
+		return iAged
+	}
+	return h[i].TxNum < h[j].TxNum
+}
+
+func (h retryQueue) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].retryIdx, h[j].retryIdx = i, j
+}
+
+func (h *retryQueue) Push(a interface{}) {
+	t := a.(*TxTask)
+	t.retryIdx = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *retryQueue) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	x.retryIdx = -1
+	return x
+}
+
+// retryWait is a task queued for re-execution and the amount of tasks dispatched when it was queued
+type retryWait struct {
+	task     *TxTask
+	queuedAt uint64
+}
+
+// retryWaits non-thread-safe priority-queue of the tasks waiting for re-execution: the longest waiting first. The
+// entries of the tasks popped from the retryQueue meanwhile are left in it and skipped.
+type retryWaits []retryWait
+
+func (h retryWaits) Len() int {
+	return len(h)
+}
+func (h retryWaits) Less(i, j int) bool {
+	return h[i].queuedAt < h[j].queuedAt
+}
+
+func (h retryWaits) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *retryWaits) Push(a interface{}) {
+	*h = append(*h, a.(retryWait))
+}
+
+func (h *retryWaits) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = retryWait{}
+	*h = old[:n-1]
+	return x
+}
+
 // QueueWithRetry is trhead-safe priority-queue of tasks - which attempt to minimize conflict-rate (retry-rate).
 // Tasks may conflict and return to queue for re-try/re-exec.
 // Tasks added by method `ReTry` have higher priority than tasks added by `Add`.
+// Tasks re-executed RetryAgingThreshold times, or waiting while RetryAgingDispatches tasks were dispatched, have higher
+// priority than all other tasks - to guarantee forward progress.
 // Method `Add` expecting already-ordered (by priority) tasks - doesn't do any additional sorting of new tasks.
 type QueueWithRetry struct {
 	closed      bool
 	newTasks    chan *TxTask
-	retires     TxTaskQueue
+	retires     retryQueue
+	waits       retryWaits // the tasks of retires by the time they wait, guarded by retiresLock
 	retiresLock sync.Mutex
 	capacity    int
+	dispatched  uint64 // amount of tasks returned by Next, guarded by retiresLock
 }
 
 func NewQueueWithRetry(capacity int) *QueueWithRetry {
@@ -255,7 +350,7 @@ func (q *QueueWithRetry) Add(ctx context.Context, t *TxTask) {
 // No limit on amount of txs added by this method.
 func (q *QueueWithRetry) ReTry(t *TxTask) {
 	q.retiresLock.Lock()
-	heap.Push(&q.retires, t)
+	q.pushRetry(t)
 	q.retiresLock.Unlock()
 	if q.closed {
 		return
@@ -282,7 +377,7 @@ func (q *QueueWithRetry) popWait(ctx context.Context) (task *TxTask, ok bool) {
 			if !ok {
 				q.retiresLock.Lock()
 				if q.retires.Len() > 0 {
-					task = heap.Pop(&q.retires).(*TxTask)
+					task = q.popRetry()
 				}
 				q.retiresLock.Unlock()
 				return task, task != nil
@@ -290,10 +385,10 @@ func (q *QueueWithRetry) popWait(ctx context.Context) (task *TxTask, ok bool) {
 
 			q.retiresLock.Lock()
 			if inTask != nil {
-				heap.Push(&q.retires, inTask)
+				q.pushRetry(inTask)
 			}
 			if q.retires.Len() > 0 {
-				task = heap.Pop(&q.retires).(*TxTask)
+				task = q.popRetry()
 			}
 			q.retiresLock.Unlock()
 			if task != nil {
@@ -308,7 +403,7 @@ func (q *QueueWithRetry) popNoWait() (task *TxTask, ok bool) {
 	q.retiresLock.Lock()
 	has := q.retires.Len() > 0
 	if has { // means have conflicts to re-exec: it has higher priority than new tasks
-		task = heap.Pop(&q.retires).(*TxTask)
+		task = q.popRetry()
 	}
 	q.retiresLock.Unlock()
 
@@ -328,9 +423,39 @@ func (q *QueueWithRetry) popNoWait() (task *TxTask, ok bool) {
 			return nil, false
 		}
 	}
+	q.retiresLock.Lock()
+	q.dispatched++
+	q.retiresLock.Unlock()
 	return task, task != nil
 }
 
+// pushRetry must be called under retiresLock
+func (q *QueueWithRetry) pushRetry(t *TxTask) {
+	t.queuedAt = q.dispatched
+	heap.Push(&q.retires, t)
+	heap.Push(&q.waits, retryWait{task: t, queuedAt: t.queuedAt})
+}
+
+// popRetry must be called under retiresLock on non-empty retires. The heap orders tasks by TxNum, but the task which
+// waited for RetryAgingDispatches dispatches - of new tasks too - goes first: a conflicting task with high TxNum
+// can't be passed over forever.
+func (q *QueueWithRetry) popRetry() *TxTask {
+	q.dispatched++
+	for q.waits.Len() > 0 {
+		w := q.waits[0]
+		if idx := w.task.retryIdx; idx < 0 || idx >= q.retires.Len() || q.retires[idx] != w.task || w.task.queuedAt != w.queuedAt {
+			heap.Pop(&q.waits) // already popped from retires
+			continue
+		}
+		if q.dispatched-w.queuedAt <= RetryAgingDispatches {
+			break
+		}
+		heap.Pop(&q.waits)
+		return heap.Remove(&q.retires, w.task.retryIdx).(*TxTask)
+	}
+	return heap.Pop(&q.retires).(*TxTask)
+}
+
 // Close safe to call multiple times
 func (q *QueueWithRetry) Close() {
 	if q.closed {
@@ -0,0 +1,117 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueWithRetryAging(t *testing.T) {
+	ctx := context.Background()
+	q := NewQueueWithRetry(16)
+	defer q.Close()
+
+	starving := &TxTask{TxNum: 10}
+	for txNum := uint64(11); txNum < 14; txNum++ {
+		q.Add(ctx, &TxTask{TxNum: txNum})
+	}
+
+	// conflicting task waits behind lower TxNum's until it's aged
+	for incarnation := 1; incarnation <= RetryAgingThreshold; incarnation++ {
+		starving.Incarnation = incarnation
+		q.ReTry(&TxTask{TxNum: uint64(incarnation)})
+		q.ReTry(starving)
+
+		task, ok := q.Next(ctx)
+		require.True(t, ok)
+		if incarnation < RetryAgingThreshold {
+			require.Equal(t, uint64(incarnation), task.TxNum)
+			task, ok = q.Next(ctx)
+			require.True(t, ok)
+			require.Equal(t, starving, task)
+			continue
+		}
+		require.Equal(t, starving, task)
+		task, ok = q.Next(ctx)
+		require.True(t, ok)
+		require.Equal(t, uint64(incarnation), task.TxNum)
+	}
+
+	// retries have higher priority than new tasks, and new tasks keep their order
+	for txNum := uint64(11); txNum < 14; txNum++ {
+		task, ok := q.Next(ctx)
+		require.True(t, ok)
+		require.Equal(t, txNum, task.TxNum)
+	}
+}
+
+func TestQueueWithRetryStarvation(t *testing.T) {
+	ctx := context.Background()
+	q := NewQueueWithRetry(16)
+	defer q.Close()
+
+	// conflicting task with high TxNum, then a stream of conflicting tasks with lower TxNum's - none of them re-executed
+	// enough times to be aged by incarnation
+	starving := &TxTask{TxNum: 1_000, Incarnation: 1}
+	q.ReTry(starving)
+	for n := 0; n <= RetryAgingDispatches; n++ {
+		q.ReTry(&TxTask{TxNum: uint64(n), Incarnation: 1})
+		task, ok := q.Next(ctx)
+		require.True(t, ok)
+		if n < RetryAgingDispatches {
+			require.Equal(t, uint64(n), task.TxNum)
+			continue
+		}
+		require.Equal(t, starving, task)
+	}
+	task, ok := q.Next(ctx)
+	require.True(t, ok)
+	require.Equal(t, uint64(RetryAgingDispatches), task.TxNum)
+}
+
+func TestQueueWithRetryRequeued(t *testing.T) {
+	ctx := context.Background()
+	q := NewQueueWithRetry(16)
+	defer q.Close()
+
+	// the task conflicts again right after its re-execution: it waits from the time it was queued again
+	starving := &TxTask{TxNum: 1_000, Incarnation: 1}
+	q.ReTry(starving)
+	task, ok := q.Next(ctx)
+	require.True(t, ok)
+	require.Equal(t, starving, task)
+	starving.Incarnation++
+	q.ReTry(starving)
+
+	for n := 0; n <= RetryAgingDispatches; n++ {
+		q.ReTry(&TxTask{TxNum: uint64(n), Incarnation: 1})
+		task, ok := q.Next(ctx)
+		require.True(t, ok)
+		if n < RetryAgingDispatches {
+			require.Equal(t, uint64(n), task.TxNum)
+			continue
+		}
+		require.Equal(t, starving, task)
+	}
+	task, ok = q.Next(ctx)
+	require.True(t, ok)
+	require.Equal(t, uint64(RetryAgingDispatches), task.TxNum)
+	require.Zero(t, q.RetriesLen())
+}
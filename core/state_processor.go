@@ -60,7 +60,7 @@ func applyTransaction(config *chain.Config, engine consensus.EngineReader, gp *G
 
 	// Update the evm with the new transaction context.
 	evm.Reset(txContext, ibs)
-	result, err := ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */)
+	result, err := ApplyTransactionMessage(evm, txn, msg, gp, true /* refunds */, false /* gasBailout */)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -86,6 +86,10 @@ func applyTransaction(config *chain.Config, engine consensus.EngineReader, gp *G
 		}
 		receipt.TxHash = txn.Hash()
 		receipt.GasUsed = result.UsedGas
+		if txn.Type() == types.BlobTxType {
+			receipt.BlobGasUsed = txn.GetBlobGas()
+			receipt.BlobGasPrice = evm.Context.BlobBaseFee.ToBig()
+		}
 		// if the transaction created a contract, store the creation address in the receipt.
 		if msg.To() == nil {
 			receipt.ContractAddress = crypto.CreateAddress(evm.Origin, txn.GetNonce())
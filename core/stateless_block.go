@@ -0,0 +1,125 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+)
+
+// ExecuteBlockWithWitness executes the block on top of the state carried by the witness, without any database,
+// and checks that the resulting state root matches the one in the block header.
+func ExecuteBlockWithWitness(chainConfig *chain.Config, engine consensus.Engine, block *types.Block, witness *types.ExecutionWitness, logger log.Logger) (*EphemeralExecResult, error) {
+	headers, err := witness.DecodeHeaders()
+	if err != nil {
+		return nil, err
+	}
+	parent := headers[0]
+	if parent.Hash() != block.ParentHash() {
+		return nil, fmt.Errorf("first witness header %x is not the parent %x of block %d", parent.Hash(), block.ParentHash(), block.NumberU64())
+	}
+
+	nodes := make([][]byte, len(witness.State))
+	for i, n := range witness.State {
+		nodes[i] = n
+	}
+	codes := make([][]byte, len(witness.Codes))
+	for i, c := range witness.Codes {
+		codes[i] = c
+	}
+	t, err := trie.BuildTrieFromNodes(parent.Root, nodes, codes)
+	if err != nil {
+		return nil, fmt.Errorf("building state trie from witness: %w", err)
+	}
+
+	chainReader := newWitnessChainReader(chainConfig, headers)
+	statelessIbs := state.NewStatelessFromTrie(t, parent.Number.Uint64(), false /* trace */)
+	execResult, err := ExecuteBlockEphemerally(chainConfig, &vm.Config{}, chainReader.blockHash, engine, block, statelessIbs, statelessIbs, chainReader, nil, logger)
+	if err != nil {
+		return nil, err
+	}
+	if root := statelessIbs.Finalize(); root != block.Root() {
+		return nil, fmt.Errorf("state root computed from witness: %x, in header: %x", root, block.Root())
+	}
+	execResult.StateRoot = block.Root()
+	return execResult, nil
+}
+
+// witnessChainReader serves the headers carried by an execution witness.
+type witnessChainReader struct {
+	config   *chain.Config
+	current  *types.Header
+	byHash   map[libcommon.Hash]*types.Header
+	byNumber map[uint64]*types.Header
+}
+
+func newWitnessChainReader(config *chain.Config, headers []*types.Header) *witnessChainReader {
+	cr := &witnessChainReader{
+		config:   config,
+		current:  headers[0],
+		byHash:   make(map[libcommon.Hash]*types.Header, len(headers)),
+		byNumber: make(map[uint64]*types.Header, len(headers)),
+	}
+	for _, h := range headers {
+		cr.byHash[h.Hash()] = h
+		cr.byNumber[h.Number.Uint64()] = h
+	}
+	return cr
+}
+
+func (cr *witnessChainReader) blockHash(n uint64) libcommon.Hash {
+	if h, ok := cr.byNumber[n]; ok {
+		return h.Hash()
+	}
+	return libcommon.Hash{}
+}
+
+func (cr *witnessChainReader) Config() *chain.Config                    { return cr.config }
+func (cr *witnessChainReader) CurrentHeader() *types.Header             { return cr.current }
+func (cr *witnessChainReader) CurrentFinalizedHeader() *types.Header    { return nil }
+func (cr *witnessChainReader) CurrentSafeHeader() *types.Header         { return nil }
+func (cr *witnessChainReader) GetHeaderByNumber(n uint64) *types.Header { return cr.byNumber[n] }
+func (cr *witnessChainReader) GetHeaderByHash(hash libcommon.Hash) *types.Header {
+	return cr.byHash[hash]
+}
+func (cr *witnessChainReader) GetHeader(hash libcommon.Hash, number uint64) *types.Header {
+	if h, ok := cr.byHash[hash]; ok && h.Number.Uint64() == number {
+		return h
+	}
+	return nil
+}
+func (cr *witnessChainReader) GetBlock(hash libcommon.Hash, number uint64) *types.Block { return nil }
+func (cr *witnessChainReader) HasBlock(hash libcommon.Hash, number uint64) bool         { return false }
+func (cr *witnessChainReader) GetTd(hash libcommon.Hash, number uint64) *big.Int        { return nil }
+func (cr *witnessChainReader) FrozenBlocks() uint64                                     { return 0 }
+func (cr *witnessChainReader) FrozenBorBlocks() uint64                                  { return 0 }
+func (cr *witnessChainReader) BorEventsByBlock(hash libcommon.Hash, number uint64) []rlp.RawValue {
+	return nil
+}
+func (cr *witnessChainReader) BorStartEventId(hash libcommon.Hash, number uint64) uint64 {
+	return 0
+}
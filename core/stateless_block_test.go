@@ -0,0 +1,116 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/consensus/ethash"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/params"
+)
+
+func TestExecuteBlockWithWitness(t *testing.T) {
+	config := params.TestChainConfig
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := libcommon.Address{0xaa}
+	coinbase := libcommon.Address{0xbb}
+
+	setAccount := func(tr *trie.Trie, addr libcommon.Address, nonce uint64, balance *uint256.Int) {
+		acc := accounts.NewAccount()
+		acc.Nonce = nonce
+		acc.Balance = *balance
+		tr.UpdateAccount(crypto.Keccak256(addr[:]), &acc)
+	}
+
+	pre := trie.New(trie.EmptyRoot)
+	setAccount(pre, sender, 0, uint256.NewInt(1e18))
+	setAccount(pre, coinbase, 0, uint256.NewInt(1))
+	// accounts untouched by the block stay out of the witness
+	for i := byte(1); i <= 16; i++ {
+		setAccount(pre, libcommon.Address{i}, 1, uint256.NewInt(uint64(i)))
+	}
+
+	grandParent := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1), GasLimit: 8_000_000}
+	parent := &types.Header{ParentHash: grandParent.Hash(), Number: big.NewInt(1), Difficulty: big.NewInt(1), GasLimit: 8_000_000, Root: pre.Hash()}
+
+	value, gasPrice := uint256.NewInt(1000), uint256.NewInt(10)
+	txn, err := types.SignTx(types.NewTransaction(0, recipient, value, 21000, gasPrice, nil), *types.LatestSignerForChainID(config.ChainID), key)
+	require.NoError(t, err)
+
+	post := trie.New(trie.EmptyRoot)
+	fee := new(uint256.Int).Mul(gasPrice, uint256.NewInt(21000))
+	senderBalance := uint256.NewInt(1e18)
+	senderBalance.Sub(senderBalance, value)
+	senderBalance.Sub(senderBalance, fee)
+	setAccount(post, sender, 1, senderBalance)
+	setAccount(post, recipient, 0, value)
+	header := &types.Header{ParentHash: parent.Hash(), Coinbase: coinbase, Number: big.NewInt(2), Difficulty: big.NewInt(1), GasLimit: 8_000_000, GasUsed: 21000}
+	reward, _ := ethash.AccumulateRewards(config, header, nil)
+	coinbaseBalance := new(uint256.Int).Add(uint256.NewInt(1), fee)
+	setAccount(post, coinbase, 0, coinbaseBalance.Add(coinbaseBalance, &reward))
+	for i := byte(1); i <= 16; i++ {
+		setAccount(post, libcommon.Address{i}, 1, uint256.NewInt(uint64(i)))
+	}
+	header.Root = post.Hash()
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}}
+	block := types.NewBlock(header, []types.Transaction{txn}, nil, []*types.Receipt{receipt}, nil)
+
+	witness := &types.ExecutionWitness{}
+	for _, addr := range []libcommon.Address{sender, recipient, coinbase} {
+		proof, err := pre.Prove(crypto.Keccak256(addr[:]), 0, false)
+		require.NoError(t, err)
+		for _, node := range proof {
+			witness.State = append(witness.State, node)
+		}
+	}
+	for _, h := range []*types.Header{parent, grandParent} {
+		enc, err := rlp.EncodeToBytes(h)
+		require.NoError(t, err)
+		witness.Headers = append(witness.Headers, enc)
+	}
+
+	res, err := core.ExecuteBlockWithWitness(config, ethash.NewFaker(), block, witness, log.New())
+	require.NoError(t, err)
+	require.Equal(t, header.Root, res.StateRoot)
+	require.Equal(t, block.ReceiptHash(), res.ReceiptRoot)
+
+	// a block claiming a different post state is rejected
+	badHeader := types.CopyHeader(header)
+	badHeader.Root = pre.Hash()
+	badBlock := types.NewBlock(badHeader, []types.Transaction{txn}, nil, []*types.Receipt{receipt}, nil)
+	_, err = core.ExecuteBlockWithWitness(config, ethash.NewFaker(), badBlock, witness, log.New())
+	require.ErrorContains(t, err, "state root computed from witness")
+
+	// headers have to start at the parent of the block
+	reordered := &types.ExecutionWitness{State: witness.State, Headers: []hexutility.Bytes{witness.Headers[1], witness.Headers[0]}}
+	_, err = core.ExecuteBlockWithWitness(config, ethash.NewFaker(), block, reordered, log.New())
+	require.Error(t, err)
+}
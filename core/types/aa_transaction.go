@@ -0,0 +1,449 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/math"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/params"
+)
+
+// AccountAbstractionTransaction is a RIP-7560 native account abstraction transaction.
+// It carries no signature: the sender contract validates the transaction itself in the validation
+// phase, optionally with a paymaster paying for the gas and a deployer creating the sender first.
+// Non-zero (RIP-7712) nonce keys and EIP-7702 authorizations are not supported.
+type AccountAbstractionTransaction struct {
+	TransactionMisc
+
+	ChainID                     *uint256.Int
+	NonceKey                    *uint256.Int
+	Nonce                       uint64
+	SenderAddress               *libcommon.Address
+	SenderValidationData        []byte
+	Deployer                    *libcommon.Address
+	DeployerData                []byte
+	Paymaster                   *libcommon.Address
+	PaymasterData               []byte
+	ExecutionData               []byte
+	BuilderFee                  *uint256.Int
+	Tip                         *uint256.Int
+	FeeCap                      *uint256.Int
+	ValidationGasLimit          uint64
+	PaymasterValidationGasLimit uint64
+	PostOpGasLimit              uint64
+	GasLimit                    uint64
+	AccessList                  AccessList
+}
+
+func (tx *AccountAbstractionTransaction) Type() byte { return AccountAbstractionTxType }
+
+func (tx *AccountAbstractionTransaction) Unwrap() Transaction { return tx }
+
+func (tx *AccountAbstractionTransaction) GetChainID() *uint256.Int { return tx.ChainID }
+func (tx *AccountAbstractionTransaction) GetNonce() uint64         { return tx.Nonce }
+func (tx *AccountAbstractionTransaction) GetPrice() *uint256.Int   { return tx.Tip }
+func (tx *AccountAbstractionTransaction) GetTip() *uint256.Int     { return tx.Tip }
+func (tx *AccountAbstractionTransaction) GetFeeCap() *uint256.Int  { return tx.FeeCap }
+func (tx *AccountAbstractionTransaction) GetBlobHashes() []libcommon.Hash {
+	return []libcommon.Hash{}
+}
+func (tx *AccountAbstractionTransaction) GetBlobGas() uint64          { return 0 }
+func (tx *AccountAbstractionTransaction) GetValue() *uint256.Int      { return uint256.NewInt(0) }
+func (tx *AccountAbstractionTransaction) GetTo() *libcommon.Address   { return tx.SenderAddress }
+func (tx *AccountAbstractionTransaction) GetData() []byte             { return tx.ExecutionData }
+func (tx *AccountAbstractionTransaction) GetAccessList() AccessList   { return tx.AccessList }
+func (tx *AccountAbstractionTransaction) Protected() bool             { return true }
+func (tx *AccountAbstractionTransaction) IsContractDeploy() bool      { return false }
+func (tx *AccountAbstractionTransaction) SetSender(libcommon.Address) {}
+func (tx *AccountAbstractionTransaction) cachedSender() (libcommon.Address, bool) {
+	return tx.GetSender()
+}
+
+func (tx *AccountAbstractionTransaction) GetEffectiveGasTip(baseFee *uint256.Int) *uint256.Int {
+	if baseFee == nil {
+		return tx.GetTip()
+	}
+	// return 0 because effectiveFee cant be < 0
+	if tx.FeeCap.Lt(baseFee) {
+		return uint256.NewInt(0)
+	}
+	effectiveFee := new(uint256.Int).Sub(tx.FeeCap, baseFee)
+	if tx.Tip.Lt(effectiveFee) {
+		return tx.Tip
+	}
+	return effectiveFee
+}
+
+// IntrinsicGas is the gas charged before any frame runs: the base cost, the access list and the calldata of all the frames.
+func (tx *AccountAbstractionTransaction) IntrinsicGas() uint64 {
+	gas := params.TxAAGas
+	gas += uint64(len(tx.AccessList)) * params.TxAccessListAddressGas
+	gas += uint64(tx.AccessList.StorageKeys()) * params.TxAccessListStorageKeyGas
+	for _, data := range [][]byte{tx.SenderValidationData, tx.DeployerData, tx.PaymasterData, tx.ExecutionData} {
+		for _, b := range data {
+			if b == 0 {
+				gas += params.TxDataZeroGas
+			} else {
+				gas += params.TxDataNonZeroGasEIP2028
+			}
+		}
+	}
+	return gas
+}
+
+// TotalGas returns the total gas limit of the transaction: its intrinsic gas and the limits of all the frames.
+// overflow is true if the sum doesn't fit into an uint64, such a transaction is invalid.
+func (tx *AccountAbstractionTransaction) TotalGas() (gas uint64, overflow bool) {
+	gas = tx.IntrinsicGas()
+	for _, limit := range []uint64{tx.ValidationGasLimit, tx.PaymasterValidationGasLimit, tx.GasLimit, tx.PostOpGasLimit} {
+		if gas, overflow = math.SafeAdd(gas, limit); overflow {
+			return 0, true
+		}
+	}
+	return gas, false
+}
+
+// GetGas returns the total gas limit of the transaction, math.MaxUint64 if it overflows so it never fits into a block.
+func (tx *AccountAbstractionTransaction) GetGas() uint64 {
+	gas, overflow := tx.TotalGas()
+	if overflow {
+		return math.MaxUint64
+	}
+	return gas
+}
+
+func (tx *AccountAbstractionTransaction) GetSender() (libcommon.Address, bool) {
+	if tx.SenderAddress == nil {
+		return libcommon.Address{}, false
+	}
+	return *tx.SenderAddress, true
+}
+
+// Sender returns the sender address carried by the transaction, there is no signature to recover it from.
+func (tx *AccountAbstractionTransaction) Sender(Signer) (libcommon.Address, error) {
+	if tx.SenderAddress == nil {
+		return libcommon.Address{}, errors.New("account abstraction transaction without sender")
+	}
+	return *tx.SenderAddress, nil
+}
+
+func (tx *AccountAbstractionTransaction) RawSignatureValues() (*uint256.Int, *uint256.Int, *uint256.Int) {
+	return new(uint256.Int), new(uint256.Int), new(uint256.Int)
+}
+
+func (tx *AccountAbstractionTransaction) WithSignature(Signer, []byte) (Transaction, error) {
+	return nil, errors.New("account abstraction transactions are not signed")
+}
+
+// AsMessage returns the message of the execution frame. The validation frames and the gas payment
+// are handled by the account abstraction processor, the message is only used for the EVM context.
+func (tx *AccountAbstractionTransaction) AsMessage(s Signer, baseFee *big.Int, rules *chain.Rules) (Message, error) {
+	if !rules.IsAA {
+		return Message{}, errors.New("account abstraction transactions are not enabled")
+	}
+	if tx.SenderAddress == nil {
+		return Message{}, errors.New("account abstraction transaction without sender")
+	}
+	msg := Message{
+		nonce:      tx.Nonce,
+		gasLimit:   tx.GetGas(),
+		gasPrice:   *tx.FeeCap,
+		tip:        *tx.Tip,
+		feeCap:     *tx.FeeCap,
+		to:         tx.SenderAddress,
+		from:       *tx.SenderAddress,
+		data:       tx.ExecutionData,
+		accessList: tx.AccessList,
+		checkNonce: true,
+	}
+	if baseFee != nil {
+		overflow := msg.gasPrice.SetFromBig(baseFee)
+		if overflow {
+			return msg, errors.New("gasPrice higher than 2^256-1")
+		}
+		msg.gasPrice.Add(&msg.gasPrice, tx.Tip)
+		if msg.gasPrice.Gt(tx.FeeCap) {
+			msg.gasPrice.Set(tx.FeeCap)
+		}
+	}
+	return msg, nil
+}
+
+// uint256OrZero lets the optional NonceKey and BuilderFee be left unset.
+func uint256OrZero(i *uint256.Int) *uint256.Int {
+	if i == nil {
+		return new(uint256.Int)
+	}
+	return i
+}
+
+func (tx *AccountAbstractionTransaction) payloadSize() (payloadSize, accessListLen int) {
+	payloadSize += 1 + rlp.Uint256LenExcludingHead(tx.ChainID)
+	payloadSize += 1 + rlp.Uint256LenExcludingHead(uint256OrZero(tx.NonceKey))
+	payloadSize += 1 + rlp.IntLenExcludingHead(tx.Nonce)
+	payloadSize += 1 + 20 // SenderAddress
+	payloadSize += rlp.StringLen(tx.SenderValidationData)
+	payloadSize++
+	if tx.Deployer != nil {
+		payloadSize += 20
+	}
+	payloadSize += rlp.StringLen(tx.DeployerData)
+	payloadSize++
+	if tx.Paymaster != nil {
+		payloadSize += 20
+	}
+	payloadSize += rlp.StringLen(tx.PaymasterData)
+	payloadSize += rlp.StringLen(tx.ExecutionData)
+	payloadSize += 1 + rlp.Uint256LenExcludingHead(uint256OrZero(tx.BuilderFee))
+	payloadSize += 1 + rlp.Uint256LenExcludingHead(tx.Tip)
+	payloadSize += 1 + rlp.Uint256LenExcludingHead(tx.FeeCap)
+	payloadSize += 1 + rlp.IntLenExcludingHead(tx.ValidationGasLimit)
+	payloadSize += 1 + rlp.IntLenExcludingHead(tx.PaymasterValidationGasLimit)
+	payloadSize += 1 + rlp.IntLenExcludingHead(tx.PostOpGasLimit)
+	payloadSize += 1 + rlp.IntLenExcludingHead(tx.GasLimit)
+	accessListLen = accessListSize(tx.AccessList)
+	payloadSize += rlp.ListPrefixLen(accessListLen) + accessListLen
+	return payloadSize, accessListLen
+}
+
+func (tx *AccountAbstractionTransaction) EncodingSize() int {
+	payloadSize, _ := tx.payloadSize()
+	// Add envelope size and type size
+	return 1 + rlp.ListPrefixLen(payloadSize) + payloadSize
+}
+
+func (tx *AccountAbstractionTransaction) encodePayload(w io.Writer, b []byte, payloadSize, accessListLen int) error {
+	if tx.SenderAddress == nil {
+		return errors.New("account abstraction transaction without sender")
+	}
+	if err := rlp.EncodeStructSizePrefix(payloadSize, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeUint256(tx.ChainID, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeUint256(tx.NonceKey, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeInt(tx.Nonce, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeOptionalAddress(tx.SenderAddress, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeString(tx.SenderValidationData, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeOptionalAddress(tx.Deployer, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeString(tx.DeployerData, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeOptionalAddress(tx.Paymaster, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeString(tx.PaymasterData, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeString(tx.ExecutionData, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeUint256(tx.BuilderFee, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeUint256(tx.Tip, w, b); err != nil {
+		return err
+	}
+	if err := rlp.EncodeUint256(tx.FeeCap, w, b); err != nil {
+		return err
+	}
+	for _, gas := range []uint64{tx.ValidationGasLimit, tx.PaymasterValidationGasLimit, tx.PostOpGasLimit, tx.GasLimit} {
+		if err := rlp.EncodeInt(gas, w, b); err != nil {
+			return err
+		}
+	}
+	if err := rlp.EncodeStructSizePrefix(accessListLen, w, b); err != nil {
+		return err
+	}
+	return encodeAccessList(tx.AccessList, w, b)
+}
+
+func (tx *AccountAbstractionTransaction) EncodeRLP(w io.Writer) error {
+	payloadSize, accessListLen := tx.payloadSize()
+	envelopSize := 1 + rlp.ListPrefixLen(payloadSize) + payloadSize
+	b := newEncodingBuf()
+	defer pooledBuf.Put(b)
+	// encode envelope size
+	if err := rlp.EncodeStringSizePrefix(envelopSize, w, b[:]); err != nil {
+		return err
+	}
+	// encode TxType
+	b[0] = AccountAbstractionTxType
+	if _, err := w.Write(b[:1]); err != nil {
+		return err
+	}
+	return tx.encodePayload(w, b[:], payloadSize, accessListLen)
+}
+
+func (tx *AccountAbstractionTransaction) MarshalBinary(w io.Writer) error {
+	payloadSize, accessListLen := tx.payloadSize()
+	b := newEncodingBuf()
+	defer pooledBuf.Put(b)
+	// encode TxType
+	b[0] = AccountAbstractionTxType
+	if _, err := w.Write(b[:1]); err != nil {
+		return err
+	}
+	return tx.encodePayload(w, b[:], payloadSize, accessListLen)
+}
+
+func decodeOptionalAddress(s *rlp.Stream, name string) (*libcommon.Address, error) {
+	b, err := s.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	switch len(b) {
+	case 0:
+		return nil, nil
+	case 20:
+		addr := libcommon.BytesToAddress(b)
+		return &addr, nil
+	default:
+		return nil, fmt.Errorf("wrong size for %s: %d", name, len(b))
+	}
+}
+
+func (tx *AccountAbstractionTransaction) DecodeRLP(s *rlp.Stream) error {
+	_, err := s.List()
+	if err != nil {
+		return err
+	}
+	var b []byte
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.ChainID = new(uint256.Int).SetBytes(b)
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.NonceKey = new(uint256.Int).SetBytes(b)
+	if tx.Nonce, err = s.Uint(); err != nil {
+		return err
+	}
+	if tx.SenderAddress, err = decodeOptionalAddress(s, "SenderAddress"); err != nil {
+		return err
+	}
+	if tx.SenderAddress == nil {
+		return errors.New("account abstraction transaction without sender")
+	}
+	if tx.SenderValidationData, err = s.Bytes(); err != nil {
+		return err
+	}
+	if tx.Deployer, err = decodeOptionalAddress(s, "Deployer"); err != nil {
+		return err
+	}
+	if tx.DeployerData, err = s.Bytes(); err != nil {
+		return err
+	}
+	if tx.Paymaster, err = decodeOptionalAddress(s, "Paymaster"); err != nil {
+		return err
+	}
+	if tx.PaymasterData, err = s.Bytes(); err != nil {
+		return err
+	}
+	if tx.ExecutionData, err = s.Bytes(); err != nil {
+		return err
+	}
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.BuilderFee = new(uint256.Int).SetBytes(b)
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.Tip = new(uint256.Int).SetBytes(b)
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.FeeCap = new(uint256.Int).SetBytes(b)
+	if tx.ValidationGasLimit, err = s.Uint(); err != nil {
+		return err
+	}
+	if tx.PaymasterValidationGasLimit, err = s.Uint(); err != nil {
+		return err
+	}
+	if tx.PostOpGasLimit, err = s.Uint(); err != nil {
+		return err
+	}
+	if tx.GasLimit, err = s.Uint(); err != nil {
+		return err
+	}
+	// decode AccessList
+	tx.AccessList = AccessList{}
+	if err = decodeAccessList(&tx.AccessList, s); err != nil {
+		return err
+	}
+	return s.ListEnd()
+}
+
+func (tx *AccountAbstractionTransaction) fields(chainID interface{}) []interface{} {
+	return []interface{}{
+		chainID,
+		tx.NonceKey,
+		tx.Nonce,
+		tx.SenderAddress,
+		tx.SenderValidationData,
+		tx.Deployer,
+		tx.DeployerData,
+		tx.Paymaster,
+		tx.PaymasterData,
+		tx.ExecutionData,
+		tx.BuilderFee,
+		tx.Tip,
+		tx.FeeCap,
+		tx.ValidationGasLimit,
+		tx.PaymasterValidationGasLimit,
+		tx.PostOpGasLimit,
+		tx.GasLimit,
+		tx.AccessList,
+	}
+}
+
+func (tx *AccountAbstractionTransaction) Hash() libcommon.Hash {
+	if hash := tx.hash.Load(); hash != nil {
+		return *hash
+	}
+	hash := prefixedRlpHash(AccountAbstractionTxType, tx.fields(tx.ChainID))
+	tx.hash.Store(&hash)
+	return hash
+}
+
+// SigningHash is the hash passed to the validation frames. Since the transaction carries no
+// signature it only differs from Hash by the chain id.
+func (tx *AccountAbstractionTransaction) SigningHash(chainID *big.Int) libcommon.Hash {
+	return prefixedRlpHash(AccountAbstractionTxType, tx.fields(chainID))
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/rlp"
+)
+
+// ExecutionWitness is everything needed to execute a block statelessly on top of the state root of its parent.
+type ExecutionWitness struct {
+	// State are the rlp encoded nodes of the account and storage tries on the paths to the accessed keys.
+	State []hexutility.Bytes `json:"state"`
+	// Codes are the bytecodes of the accessed contracts.
+	Codes []hexutility.Bytes `json:"codes"`
+	// Keys are the preimages of the accessed trie keys: account addresses and storage slots.
+	Keys []hexutility.Bytes `json:"keys"`
+	// Headers are the rlp encoded headers from the parent down to the oldest one accessed by BLOCKHASH.
+	Headers []hexutility.Bytes `json:"headers"`
+}
+
+// DecodeHeaders decodes the witness headers and checks that they form a chain going backwards from the first one.
+func (w *ExecutionWitness) DecodeHeaders() ([]*Header, error) {
+	if len(w.Headers) == 0 {
+		return nil, errors.New("witness has no headers")
+	}
+	headers := make([]*Header, len(w.Headers))
+	for i, enc := range w.Headers {
+		h := new(Header)
+		if err := rlp.DecodeBytes(enc, h); err != nil {
+			return nil, fmt.Errorf("decoding witness header %d: %w", i, err)
+		}
+		if i > 0 && headers[i-1].ParentHash != h.Hash() {
+			return nil, fmt.Errorf("witness header %d (%d) is not the parent of header %d", i, h.Number.Uint64(), i-1)
+		}
+		headers[i] = h
+	}
+	return headers, nil
+}
@@ -68,6 +68,8 @@ type Receipt struct {
 	TxHash          libcommon.Hash    `json:"transactionHash" gencodec:"required"`
 	ContractAddress libcommon.Address `json:"contractAddress"`
 	GasUsed         uint64            `json:"gasUsed" gencodec:"required"`
+	BlobGasUsed     uint64            `json:"blobGasUsed,omitempty"`
+	BlobGasPrice    *big.Int          `json:"blobGasPrice,omitempty"` // blob base fee of the block, only for blob transactions
 
 	// Inclusion information: These fields provide information about the inclusion of the
 	// transaction corresponding to this receipt.
@@ -84,6 +86,8 @@ type receiptMarshaling struct {
 	Status            hexutil.Uint64
 	CumulativeGasUsed hexutil.Uint64
 	GasUsed           hexutil.Uint64
+	BlobGasUsed       hexutil.Uint64
+	BlobGasPrice      *hexutil.Big
 	BlockNumber       *hexutil.Big
 	TransactionIndex  hexutil.Uint
 }
@@ -292,7 +296,7 @@ func (r *Receipt) DecodeRLP(s *rlp.Stream) error {
 		}
 		r.Type = b[0]
 		switch r.Type {
-		case AccessListTxType, DynamicFeeTxType, BlobTxType, SetCodeTxType:
+		case AccessListTxType, DynamicFeeTxType, BlobTxType, SetCodeTxType, AccountAbstractionTxType:
 			if err := r.decodePayload(s); err != nil {
 				return err
 			}
@@ -337,7 +341,7 @@ func (r *Receipt) Copy() *Receipt {
 	if r == nil {
 		return nil
 	}
-	return &Receipt{
+	cpy := &Receipt{
 		Type:              r.Type,
 		PostState:         slices.Clone(r.PostState),
 		Status:            r.Status,
@@ -347,10 +351,15 @@ func (r *Receipt) Copy() *Receipt {
 		TxHash:            libcommon.BytesToHash(r.TxHash.Bytes()),
 		ContractAddress:   libcommon.BytesToAddress(r.ContractAddress.Bytes()),
 		GasUsed:           r.GasUsed,
+		BlobGasUsed:       r.BlobGasUsed,
 		BlockHash:         libcommon.BytesToHash(r.BlockHash.Bytes()),
 		BlockNumber:       big.NewInt(0).Set(r.BlockNumber),
 		TransactionIndex:  r.TransactionIndex,
 	}
+	if r.BlobGasPrice != nil {
+		cpy.BlobGasPrice = new(big.Int).Set(r.BlobGasPrice)
+	}
+	return cpy
 }
 
 type ReceiptsForStorage []*ReceiptForStorage
@@ -443,6 +452,11 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 		if err := rlp.Encode(w, data); err != nil {
 			panic(err)
 		}
+	case AccountAbstractionTxType:
+		w.WriteByte(AccountAbstractionTxType)
+		if err := rlp.Encode(w, data); err != nil {
+			panic(err)
+		}
 	default:
 		// For unsupported types, write nothing. Since this is for
 		// DeriveSha, the error will be caught matching the derived hash
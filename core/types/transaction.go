@@ -29,6 +29,7 @@ import (
 
 	"github.com/holiman/uint256"
 	"github.com/protolambda/ztyp/codec"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/erigontech/erigon-lib/chain"
 	libcommon "github.com/erigontech/erigon-lib/common"
@@ -53,6 +54,7 @@ const (
 	DynamicFeeTxType
 	BlobTxType
 	SetCodeTxType
+	AccountAbstractionTxType
 )
 
 // Transaction is an Ethereum transaction.
@@ -196,6 +198,8 @@ func UnmarshalTransactionFromBinary(data []byte, blobTxnsAreWrappedWithBlobs boo
 		}
 	case SetCodeTxType:
 		t = &SetCodeTransaction{}
+	case AccountAbstractionTxType:
+		t = &AccountAbstractionTransaction{}
 	default:
 		if data[0] >= 0x80 {
 			// txn is type legacy which is RLP encoded
@@ -269,6 +273,32 @@ func DecodeTransactions(txs [][]byte) ([]Transaction, error) {
 	return result, nil
 }
 
+// DecodeTransactionsParallel decodes the transactions (in the DecodeTransaction format) across up to workers goroutines,
+// keeping their order.
+func DecodeTransactionsParallel(txs [][]byte, workers int) ([]Transaction, error) {
+	result := make([]Transaction, len(txs))
+	if workers < 1 {
+		workers = 1
+	}
+	chunk := (len(txs) + workers - 1) / workers
+	var g errgroup.Group
+	for from := 0; from < len(txs); from += chunk {
+		to := min(from+chunk, len(txs))
+		g.Go(func() (err error) {
+			for i := from; i < to; i++ {
+				if result[i], err = DecodeTransaction(txs[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func TypedTransactionMarshalledAsRlpString(data []byte) bool {
 	// Unless it's a single byte, serialized RLP strings have their first byte in the [0x80, 0xc0) range
 	return len(data) > 0 && 0x80 <= data[0] && data[0] < 0xc0
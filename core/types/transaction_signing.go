@@ -300,6 +300,12 @@ func (sg Signer) SenderWithContext(context *secp256k1.Context, txn Transaction)
 		// id, add 27 to become equivalent to unprotected Homestead signatures.
 		V.Add(&t.V, u256.Num27)
 		R, S = &t.R, &t.S
+	case *AccountAbstractionTransaction:
+		// RIP-7560 transactions are not signed, the sender contract validates them during execution
+		if t.ChainID != nil && !t.ChainID.Eq(&sg.chainID) {
+			return libcommon.Address{}, ErrInvalidChainId
+		}
+		return t.Sender(sg)
 	default:
 		return libcommon.Address{}, ErrTxTypeNotSupported
 	}
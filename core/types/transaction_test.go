@@ -34,6 +34,7 @@ import (
 
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/fixedgas"
@@ -797,3 +798,111 @@ func TestTrailingBytes(t *testing.T) {
 		panic("Malicious transaction has not errored!") // @audit this panic is occurs
 	}
 }
+
+func TestAccountAbstractionTxEncodeDecode(t *testing.T) {
+	sender := libcommon.HexToAddress("0x1000000000000000000000000000000000000001")
+	paymaster := libcommon.HexToAddress("0x2000000000000000000000000000000000000002")
+	for _, txn := range []*AccountAbstractionTransaction{
+		{
+			ChainID:            uint256.NewInt(1),
+			NonceKey:           uint256.NewInt(0),
+			Nonce:              3,
+			SenderAddress:      &sender,
+			ExecutionData:      []byte{0x01, 0x02},
+			BuilderFee:         uint256.NewInt(0),
+			Tip:                uint256.NewInt(1),
+			FeeCap:             uint256.NewInt(10),
+			ValidationGasLimit: 100_000,
+			GasLimit:           50_000,
+		},
+		{
+			ChainID:                     uint256.NewInt(1),
+			NonceKey:                    uint256.NewInt(0),
+			Nonce:                       7,
+			SenderAddress:               &sender,
+			SenderValidationData:        []byte{0xaa},
+			Paymaster:                   &paymaster,
+			PaymasterData:               []byte{0xbb, 0xcc},
+			ExecutionData:               []byte{0x00, 0x01},
+			BuilderFee:                  uint256.NewInt(5),
+			Tip:                         uint256.NewInt(2),
+			FeeCap:                      uint256.NewInt(20),
+			ValidationGasLimit:          100_000,
+			PaymasterValidationGasLimit: 60_000,
+			PostOpGasLimit:              20_000,
+			GasLimit:                    50_000,
+			AccessList:                  AccessList{{Address: paymaster, StorageKeys: []libcommon.Hash{{0x01}}}},
+		},
+	} {
+		var encBuf bytes.Buffer
+		if err := txn.MarshalBinary(&encBuf); err != nil {
+			t.Fatal(err)
+		}
+		enc := encBuf.Bytes()
+		if enc[0] != AccountAbstractionTxType {
+			t.Fatalf("wrong type prefix %d", enc[0])
+		}
+		decoded, err := UnmarshalTransactionFromBinary(enc, false /* blobTxnsAreWrappedWithBlobs */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Hash() != txn.Hash() {
+			t.Fatalf("hash mismatch: %x != %x", decoded.Hash(), txn.Hash())
+		}
+		var reenc bytes.Buffer
+		if err := decoded.MarshalBinary(&reenc); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(enc, reenc.Bytes()) {
+			t.Fatalf("re-encoding mismatch: %x != %x", enc, reenc.Bytes())
+		}
+		if *decoded.GetTo() != sender {
+			t.Fatalf("wrong sender %x", decoded.GetTo())
+		}
+		if decoded.GetGas() != txn.GetGas() {
+			t.Fatalf("gas mismatch: %d != %d", decoded.GetGas(), txn.GetGas())
+		}
+
+		// block body (RLP stream) encoding
+		var buf bytes.Buffer
+		if err := txn.EncodeRLP(&buf); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err = DecodeRLPTransaction(rlp.NewStream(bytes.NewReader(buf.Bytes()), 0), false /* blobTxnsAreWrappedWithBlobs */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Hash() != txn.Hash() {
+			t.Fatalf("stream hash mismatch: %x != %x", decoded.Hash(), txn.Hash())
+		}
+	}
+}
+
+func TestDecodeTransactionsParallel(t *testing.T) {
+	t.Parallel()
+	recipient := libcommon.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87")
+	encoded := make([][]byte, 0, 100)
+	hashes := make([]libcommon.Hash, 0, 100)
+	for i := uint64(0); i < 100; i++ {
+		var txn Transaction = &LegacyTx{CommonTx: CommonTx{Nonce: i, To: &recipient, Gas: 1, Value: u256.Num1}, GasPrice: u256.Num2}
+		if i%2 == 1 {
+			txn = &DynamicFeeTransaction{CommonTx: CommonTx{Nonce: i, To: &recipient, Gas: 1, Value: u256.Num1}, ChainID: uint256.NewInt(1), Tip: u256.Num1, FeeCap: u256.Num2}
+		}
+		var buf bytes.Buffer
+		require.NoError(t, txn.MarshalBinary(&buf))
+		encoded = append(encoded, buf.Bytes())
+		hashes = append(hashes, txn.Hash())
+	}
+	for _, workers := range []int{0, 1, 3, 8, 200} {
+		txs, err := DecodeTransactionsParallel(encoded, workers)
+		require.NoError(t, err)
+		require.Len(t, txs, len(encoded))
+		for i, txn := range txs {
+			require.Equal(t, hashes[i], txn.Hash(), "workers %d, txn %d", workers, i)
+		}
+	}
+
+	encoded[57] = []byte{0xc0}
+	_, err := DecodeTransactionsParallel(encoded, 4)
+	require.Error(t, err)
+}
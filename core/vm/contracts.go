@@ -301,7 +301,8 @@ func (c *dataCopy) RequiredGas(input []byte) uint64 {
 	return uint64(len(input)+31)/32*params.IdentityPerWordGas + params.IdentityBaseGas
 }
 func (c *dataCopy) Run(in []byte) ([]byte, error) {
-	return in, nil
+	// the input is a slice of the memory of the caller, the output becomes its return data buffer
+	return libcommon.CopyBytes(in), nil
 }
 
 // bigModExp implements a native big integer exponential modular operation.
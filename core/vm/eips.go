@@ -20,7 +20,6 @@
 package vm
 
 import (
-	"fmt"
 	"sort"
 	"strconv"
 
@@ -48,19 +47,6 @@ var activators = map[int]func(*JumpTable){
 	1153: enable1153,
 }
 
-// EnableEIP enables the given EIP on the config.
-// This operation writes in-place, and callers need to ensure that the globally
-// defined jump tables are not polluted.
-func EnableEIP(eipNum int, jt *JumpTable) error {
-	enablerFn, ok := activators[eipNum]
-	if !ok {
-		return fmt.Errorf("undefined eip %d", eipNum)
-	}
-	enablerFn(jt)
-	validateAndFillMaxStack(jt)
-	return nil
-}
-
 func ValidEip(eipNum int) bool {
 	_, ok := activators[eipNum]
 	return ok
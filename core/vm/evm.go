@@ -109,6 +109,10 @@ func NewEVM(blockCtx evmtypes.BlockContext, txCtx evmtypes.TxContext, state evmt
 			blockCtx.BaseFee = new(uint256.Int)
 		}
 	}
+	if len(chainConfig.ExtraEips) > 0 {
+		// the chain's EIPs go first, don't modify the caller's slice
+		vmConfig.ExtraEips = append(append(make([]int, 0, len(chainConfig.ExtraEips)+len(vmConfig.ExtraEips)), chainConfig.ExtraEips...), vmConfig.ExtraEips...)
+	}
 	evm := &EVM{
 		Context:         blockCtx,
 		TxContext:       txCtx,
@@ -425,3 +425,47 @@ func trace(isEVMSlice []bool, readOnlySlice []*readOnlyState) string {
 	}
 	return res
 }
+
+func TestInterpreterReturnDoesNotAliasMemory(t *testing.T) {
+	t.Parallel()
+	env := NewEVM(evmtypes.BlockContext{}, evmtypes.TxContext{}, &dummyStatedb{}, params.TestChainConfig, Config{})
+	returnByte := func(b byte) []byte {
+		contract := NewContract(&dummyContractRef{}, libcommon.Address{}, new(uint256.Int), 100_000, false, NewJumpDestCache())
+		contract.Code = []byte{
+			byte(PUSH1), b, byte(PUSH1), 0, byte(MSTORE),
+			byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN),
+		}
+		ret, err := env.interpreter.Run(contract, nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+
+	first := returnByte(0xaa)
+	// the memory of the first call is back in the pool and most likely used by the second one
+	second := returnByte(0xbb)
+	if first[31] != 0xaa || second[31] != 0xbb {
+		t.Fatalf("returned data overwritten: %x %x", first, second)
+	}
+}
+
+func TestChainExtraEips(t *testing.T) {
+	t.Parallel()
+	config := *params.TestChainConfig // before shanghai, no PUSH0
+	config.ExtraEips = []int{3855}
+	env := NewEVM(evmtypes.BlockContext{}, evmtypes.TxContext{}, &dummyStatedb{}, &config, Config{})
+
+	contract := NewContract(&dummyContractRef{}, libcommon.Address{}, new(uint256.Int), 100_000, false, NewJumpDestCache())
+	contract.Code = []byte{byte(PUSH0), byte(PUSH0), byte(RETURN)}
+	if _, err := env.interpreter.Run(contract, nil, false); err != nil {
+		t.Fatalf("EIP-3855 of the chain spec not activated: %v", err)
+	}
+
+	env = NewEVM(evmtypes.BlockContext{}, evmtypes.TxContext{}, &dummyStatedb{}, params.TestChainConfig, Config{})
+	contract = NewContract(&dummyContractRef{}, libcommon.Address{}, new(uint256.Int), 100_000, false, NewJumpDestCache())
+	contract.Code = []byte{byte(PUSH0), byte(PUSH0), byte(RETURN)}
+	if _, err := env.interpreter.Run(contract, nil, false); err == nil {
+		t.Fatal("PUSH0 without EIP-3855")
+	}
+}
@@ -66,6 +66,7 @@ type TxContext struct {
 // message no matter the execution itself is successful or not.
 type ExecutionResult struct {
 	UsedGas             uint64 // Total used gas but include the refunded gas
+	RefundedGas         uint64 // Total gas refunded after execution
 	Err                 error  // Any error encountered during the execution(listed in core/vm/errors.go)
 	Reverted            bool   // Whether the execution was aborted by `REVERT`
 	ReturnData          []byte // Returned data from evm(function result or data supplied with revert opcode)
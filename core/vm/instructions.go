@@ -756,6 +756,8 @@ func opCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byt
 		gas += params.CallStipend
 	}
 
+	// ret is not copied: it is never a slice of a memory, neither of the callee (the interpreter copies the returned
+	// data out of it) nor of this call (precompiles return new slices), so it can be the return data buffer as is
 	ret, returnGas, err := interpreter.evm.Call(scope.Contract, toAddr, args, gas, &value, false /* bailout */)
 
 	if err != nil {
@@ -765,7 +767,6 @@ func opCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byt
 	}
 	stack.Push(&temp)
 	if err == nil || err == ErrExecutionReverted {
-		ret = libcommon.CopyBytes(ret)
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 
@@ -799,7 +800,6 @@ func opCallCode(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([
 	}
 	stack.Push(&temp)
 	if err == nil || err == ErrExecutionReverted {
-		ret = libcommon.CopyBytes(ret)
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 
@@ -829,7 +829,6 @@ func opDelegateCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext
 	}
 	stack.Push(&temp)
 	if err == nil || err == ErrExecutionReverted {
-		ret = libcommon.CopyBytes(ret)
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 
@@ -859,7 +858,6 @@ func opStaticCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext)
 	}
 	stack.Push(&temp)
 	if err == nil || err == ErrExecutionReverted {
-		ret = libcommon.CopyBytes(ret)
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 
@@ -907,8 +905,14 @@ func opSelfdestruct(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext
 			interpreter.cfg.Tracer.CaptureExit([]byte{}, 0, nil)
 		}
 	}
+	burnt := *balance
 	interpreter.evm.IntraBlockState().AddBalance(beneficiaryAddr, balance, tracing.BalanceIncreaseSelfdestruct)
 	interpreter.evm.IntraBlockState().Selfdestruct(callerAddr)
+	if interpreter.cfg.Debug && beneficiaryAddr == callerAddr && !burnt.IsZero() {
+		if tracer, ok := interpreter.cfg.Tracer.(SelfdestructLogger); ok {
+			tracer.OnBalanceBurn(callerAddr, &burnt)
+		}
+	}
 	return nil, errStopToken
 }
 
@@ -931,11 +935,34 @@ func opSelfdestruct6780(pc *uint64, interpreter *EVMInterpreter, scope *ScopeCon
 		if interpreter.cfg.Debug {
 			interpreter.cfg.Tracer.CaptureEnter(SELFDESTRUCT, callerAddr, beneficiaryAddr, false /* precompile */, false /* create */, []byte{}, 0, &balance, nil /* code */)
 			interpreter.cfg.Tracer.CaptureExit([]byte{}, 0, nil)
+			if err := captureSelfdestruct6780(interpreter, callerAddr, beneficiaryAddr, &balance); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return nil, errStopToken
 }
 
+// captureSelfdestruct6780 reports to the tracer the SELFDESTRUCT which did not delete the account (not created in
+// this transaction), or the balance destroyed by the one which did
+func captureSelfdestruct6780(interpreter *EVMInterpreter, callerAddr, beneficiaryAddr libcommon.Address, balance *uint256.Int) error {
+	tracer, ok := interpreter.cfg.Tracer.(SelfdestructLogger)
+	if !ok {
+		return nil
+	}
+	deleted, err := interpreter.evm.IntraBlockState().HasSelfdestructed(callerAddr)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !deleted:
+		tracer.OnSelfdestructCancelled(callerAddr, beneficiaryAddr, balance)
+	case beneficiaryAddr == callerAddr && !balance.IsZero():
+		tracer.OnBalanceBurn(callerAddr, balance)
+	}
+	return nil
+}
+
 // following functions are used by the instruction jump  table
 
 // make log instruction function
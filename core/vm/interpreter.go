@@ -22,7 +22,6 @@ package vm
 import (
 	"fmt"
 	"hash"
-	"sync"
 
 	"github.com/erigontech/erigon-lib/log/v3"
 
@@ -51,12 +50,6 @@ type Config struct {
 
 }
 
-var pool = sync.Pool{
-	New: func() any {
-		return NewMemory()
-	},
-}
-
 func (vmConfig *Config) HasEip3860(rules *chain.Rules) bool {
 	for _, eip := range vmConfig.ExtraEips {
 		if eip == 3860 {
@@ -116,57 +109,19 @@ type VM struct {
 	returnData []byte // Last CALL's return data for subsequent reuse
 }
 
-func copyJumpTable(jt *JumpTable) *JumpTable {
-	var copy JumpTable
-	for i, op := range jt {
-		if op != nil {
-			opCopy := *op
-			copy[i] = &opCopy
-		}
-	}
-	return &copy
-}
-
 // NewEVMInterpreter returns a new instance of the Interpreter.
 func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
-	var jt *JumpTable
-	switch {
-	case evm.ChainRules().IsPrague:
-		jt = &pragueInstructionSet
-	case evm.ChainRules().IsCancun:
-		jt = &cancunInstructionSet
-	case evm.ChainRules().IsNapoli:
-		jt = &napoliInstructionSet
-	case evm.ChainRules().IsShanghai:
-		jt = &shanghaiInstructionSet
-	case evm.ChainRules().IsLondon:
-		jt = &londonInstructionSet
-	case evm.ChainRules().IsBerlin:
-		jt = &berlinInstructionSet
-	case evm.ChainRules().IsIstanbul:
-		jt = &istanbulInstructionSet
-	case evm.ChainRules().IsConstantinople:
-		jt = &constantinopleInstructionSet
-	case evm.ChainRules().IsByzantium:
-		jt = &byzantiumInstructionSet
-	case evm.ChainRules().IsSpuriousDragon:
-		jt = &spuriousDragonInstructionSet
-	case evm.ChainRules().IsTangerineWhistle:
-		jt = &tangerineWhistleInstructionSet
-	case evm.ChainRules().IsHomestead:
-		jt = &homesteadInstructionSet
-	default:
-		jt = &frontierInstructionSet
-	}
 	if len(cfg.ExtraEips) > 0 {
-		jt = copyJumpTable(jt)
-		for i, eip := range cfg.ExtraEips {
-			if err := EnableEIP(eip, jt); err != nil {
-				// Disable it, so caller can check if it's activated or not
-				cfg.ExtraEips = append(cfg.ExtraEips[:i], cfg.ExtraEips[i+1:]...)
-				log.Error("EIP activation failed", "eip", eip, "err", err)
+		// Disable the undefined EIPs, so caller can check if they are activated or not
+		eips := make([]int, 0, len(cfg.ExtraEips))
+		for _, eip := range cfg.ExtraEips {
+			if !ValidEip(eip) {
+				log.Error("EIP activation failed", "eip", eip, "err", fmt.Errorf("undefined eip %d", eip))
+				continue
 			}
+			eips = append(eips, eip)
 		}
+		cfg.ExtraEips = eips
 	}
 
 	return &EVMInterpreter{
@@ -174,7 +129,7 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 			evm: evm,
 			cfg: cfg,
 		},
-		jt: jt,
+		jt: jumpTableForRules(evm.ChainRules(), cfg.ExtraEips),
 	}
 }
 
@@ -196,7 +151,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 
 	var (
 		op          OpCode // current opcode
-		mem         = pool.Get().(*Memory)
+		mem         = getMemory()
 		locStack    = stack.New()
 		callContext = &ScopeContext{
 			Memory:   mem,
@@ -216,8 +171,6 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		res     []byte // result of the opcode execution function
 	)
 
-	mem.Reset()
-
 	contract.Input = input
 
 	// Make sure the readOnly is only set if we aren't in readOnly yet.
@@ -238,7 +191,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 			}
 		}
 		// this function must execute _after_: the `CaptureState` needs the stacks before
-		pool.Put(mem)
+		putMemory(mem)
 		stack.ReturnNormalStack(locStack)
 		if restoreReadonly {
 			in.readOnly = false
@@ -328,6 +281,8 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		err = nil // clear stop token error
 	}
 
+	// res points into mem, which goes back to the pool: this is the only copy of the returned data, the callers
+	// (and the return data buffer of the calling frame) own ret
 	ret = append(ret, res...)
 	return
 }
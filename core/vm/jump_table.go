@@ -21,6 +21,9 @@ package vm
 
 import (
 	"fmt"
+	"sync"
+
+	"github.com/erigontech/erigon-lib/chain"
 
 	"github.com/erigontech/erigon/core/vm/stack"
 	"github.com/erigontech/erigon/params"
@@ -72,6 +75,101 @@ var (
 // JumpTable contains the EVM opcodes supported at a given fork.
 type JumpTable [256]*operation
 
+// forkJumpTable returns the instruction set of the fork of the rules
+func forkJumpTable(rules *chain.Rules) *JumpTable {
+	switch {
+	case rules.IsPrague:
+		return &pragueInstructionSet
+	case rules.IsCancun:
+		return &cancunInstructionSet
+	case rules.IsNapoli:
+		return &napoliInstructionSet
+	case rules.IsShanghai:
+		return &shanghaiInstructionSet
+	case rules.IsLondon:
+		return &londonInstructionSet
+	case rules.IsBerlin:
+		return &berlinInstructionSet
+	case rules.IsIstanbul:
+		return &istanbulInstructionSet
+	case rules.IsConstantinople:
+		return &constantinopleInstructionSet
+	case rules.IsByzantium:
+		return &byzantiumInstructionSet
+	case rules.IsSpuriousDragon:
+		return &spuriousDragonInstructionSet
+	case rules.IsTangerineWhistle:
+		return &tangerineWhistleInstructionSet
+	case rules.IsHomestead:
+		return &homesteadInstructionSet
+	default:
+		return &frontierInstructionSet
+	}
+}
+
+type jumpTableKey struct {
+	fork *JumpTable
+	eips string
+}
+
+// jumpTables - the jump tables of the forks with extra EIPs, by jumpTableKey
+var jumpTables sync.Map
+
+// jumpTableForRules returns the jump table of the fork of the rules with the extra EIPs enabled, in this order. The
+// EIPs must be valid (see ValidEip). A table is built once per fork and list of EIPs, and shared by all the
+// interpreters: it must not be modified.
+func jumpTableForRules(rules *chain.Rules, extraEips []int) *JumpTable {
+	fork := forkJumpTable(rules)
+	if len(extraEips) == 0 {
+		return fork
+	}
+	key := jumpTableKey{fork: fork, eips: fmt.Sprint(extraEips)}
+	if jt, ok := jumpTables.Load(key); ok {
+		return jt.(*JumpTable)
+	}
+	b := newJumpTableBuilder(fork)
+	for _, eip := range extraEips {
+		if err := b.enableEIP(eip); err != nil {
+			panic(err)
+		}
+	}
+	jt, _ := jumpTables.LoadOrStore(key, b.build())
+	return jt.(*JumpTable)
+}
+
+// jumpTableBuilder makes a new jump table out of an existing one. The activators of the EIPs modify the operations
+// of the table they are given, so the builder works on a deep copy and the tables of the forks are never modified.
+type jumpTableBuilder struct {
+	jt JumpTable
+}
+
+func newJumpTableBuilder(base *JumpTable) *jumpTableBuilder {
+	b := &jumpTableBuilder{}
+	for i, op := range base {
+		if op != nil {
+			opCopy := *op
+			b.jt[i] = &opCopy
+		}
+	}
+	return b
+}
+
+// enableEIP applies the EIP to the table being built
+func (b *jumpTableBuilder) enableEIP(eipNum int) error {
+	enablerFn, ok := activators[eipNum]
+	if !ok {
+		return fmt.Errorf("undefined eip %d", eipNum)
+	}
+	enablerFn(&b.jt)
+	return nil
+}
+
+// build returns the table, the builder must not be used after that
+func (b *jumpTableBuilder) build() *JumpTable {
+	validateAndFillMaxStack(&b.jt)
+	return &b.jt
+}
+
 func validateAndFillMaxStack(jt *JumpTable) {
 	for i, op := range jt {
 		if op == nil {
@@ -0,0 +1,59 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon/params"
+)
+
+func TestJumpTableForRules(t *testing.T) {
+	t.Parallel()
+	istanbul := params.MainnetChainConfig.Rules(9_069_000, 0)
+	require.True(t, istanbul.IsIstanbul && !istanbul.IsBerlin)
+
+	require.Same(t, &istanbulInstructionSet, jumpTableForRules(istanbul, nil))
+
+	// the tables with extra EIPs are built once, concurrently or not
+	tables := make([]*JumpTable, 8)
+	var wg sync.WaitGroup
+	for i := range tables {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tables[i] = jumpTableForRules(istanbul, []int{2929, 3855})
+		}()
+	}
+	wg.Wait()
+	for _, jt := range tables {
+		require.Same(t, tables[0], jt)
+	}
+	require.NotSame(t, tables[0], jumpTableForRules(istanbul, []int{3855, 2929}))
+
+	jt := tables[0]
+	require.Equal(t, uint64(0), jt[SLOAD].constantGas)
+	require.Equal(t, GasQuickStep, jt[PUSH0].constantGas)
+
+	// the table of the fork is not modified by the EIPs
+	require.Equal(t, params.SloadGasEIP2200, istanbulInstructionSet[SLOAD].constantGas)
+	require.Equal(t, uint64(0), istanbulInstructionSet[PUSH0].constantGas)
+	require.NotSame(t, istanbulInstructionSet[SLOAD], jt[SLOAD])
+}
@@ -47,6 +47,18 @@ type EVMLogger interface {
 	CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error)
 }
 
+// SelfdestructLogger is an optional extension of EVMLogger for the SELFDESTRUCT cases which are not an account
+// deletion with a balance transfer. Since EIP-6780 the account is only deleted if it was created in the same
+// transaction, and a deleted account which is its own beneficiary loses its balance. Both callbacks follow the
+// CaptureEnter/CaptureExit of the SELFDESTRUCT frame.
+type SelfdestructLogger interface {
+	// OnSelfdestructCancelled is called when the account is not deleted: the value is moved to the beneficiary
+	// (nowhere if it is the account itself) and the account keeps its code and storage.
+	OnSelfdestructCancelled(addr libcommon.Address, beneficiary libcommon.Address, value *uint256.Int)
+	// OnBalanceBurn is called when the balance of a deleted account is destroyed instead of moved.
+	OnBalanceBurn(addr libcommon.Address, amount *uint256.Int)
+}
+
 // FlushableTracer is a Tracer extension whose accumulated traces has to be
 // flushed once the tracing is completed.
 type FlushableTracer interface {
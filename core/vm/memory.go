@@ -20,6 +20,8 @@
 package vm
 
 import (
+	"sync"
+
 	"github.com/holiman/uint256"
 )
 
@@ -36,6 +38,34 @@ func NewMemory() *Memory {
 	}
 }
 
+// maxPooledMemoryCap - memories which grew bigger are not put back to the pool: a few calls expanding the memory to
+// megabytes would keep all of it allocated for the rest of the execution otherwise
+const maxPooledMemoryCap = 1 << 20
+
+// memoryPool recycles the memories of finished calls. A memory, and every slice of it obtained by GetPtr, is only
+// valid until the call it was given to returns: whatever outlives the call (returned data, return data of the
+// caller) must be copied out of it before putMemory.
+var memoryPool = sync.Pool{
+	New: func() any {
+		return NewMemory()
+	},
+}
+
+// getMemory returns an empty memory from the pool
+func getMemory() *Memory {
+	m := memoryPool.Get().(*Memory)
+	m.Reset()
+	return m
+}
+
+// putMemory puts the memory back to the pool, it must not be used after that
+func putMemory(m *Memory) {
+	if cap(m.store) > maxPooledMemoryCap {
+		return
+	}
+	memoryPool.Put(m)
+}
+
 // Set sets offset + size to value
 func (m *Memory) Set(offset, size uint64, value []byte) {
 	// It's possible the offset is greater than 0 and size equals 0. This is because
@@ -59,24 +89,23 @@ func (m *Memory) Set32(offset uint64, val *uint256.Int) {
 		panic("invalid memory: store empty")
 	}
 	// Zero the memory area
-	copy(m.store[offset:offset+32], zeroes)
+	clear(m.store[offset : offset+32])
 	val.WriteToSlice(m.store[offset : offset+32])
 }
 
-// zeroes - pre-allocated zeroes for Resize()
-var zeroes = make([]byte, 4*4096)
-
-// Resize resizes the memory to size
+// Resize resizes the memory to size. The capacity left by the previous user of a pooled memory is reused, and
+// zeroed, before the store is grown.
 func (m *Memory) Resize(size uint64) {
-	l := int(size) - m.Len()
-	if l <= 0 {
+	l := m.Len()
+	if int(size) <= l {
 		return
 	}
-	if l >= len(zeroes) {
-		m.store = append(m.store, make([]byte, l)...)
+	if int(size) <= cap(m.store) {
+		m.store = m.store[:size]
+		clear(m.store[l:])
 		return
 	}
-	m.store = append(m.store, zeroes[:l]...)
+	m.store = append(m.store, make([]byte, int(size)-l)...)
 }
 
 func (m *Memory) Reset() {
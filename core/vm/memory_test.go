@@ -84,3 +84,17 @@ func TestMemoryCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryResizeZeroesReusedStore(t *testing.T) {
+	t.Parallel()
+	m := NewMemory()
+	m.Resize(64)
+	m.Set(0, 64, bytes.Repeat([]byte{0xff}, 64))
+	m.Reset()
+
+	m.Resize(32)
+	m.Resize(96)
+	if !bytes.Equal(m.Data(), make([]byte, 96)) {
+		t.Fatalf("memory not zeroed after reuse: %x", m.Data())
+	}
+}
@@ -743,3 +743,19 @@ func TestEip2929Cases(t *testing.T) {
 			"account (cheap)", code)
 	}
 }
+
+// The return data of a call to the identity precompile stays the same when the memory it was called with changes
+func TestReturnDataOfIdentityPrecompile(t *testing.T) {
+	t.Parallel()
+	ret, _, err := Execute([]byte{
+		byte(vm.PUSH1), 0xaa, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		// STATICCALL(gas, 0x04, argsOffset=0, argsSize=32, retOffset=0, retSize=0)
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.PUSH1), 4, byte(vm.GAS), byte(vm.STATICCALL), byte(vm.POP),
+		byte(vm.PUSH1), 0xbb, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		// RETURNDATACOPY(destOffset=32, offset=0, size=32)
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.PUSH1), 32, byte(vm.RETURNDATACOPY),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 32, byte(vm.RETURN),
+	}, nil, nil, t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, uint64(0xaa), new(big.Int).SetBytes(ret).Uint64())
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"fmt"
+	"net/http"
+
+	diaglib "github.com/erigontech/erigon-lib/diagnostics"
+)
+
+func SetupBadBlocksAccess(metricsMux *http.ServeMux, diag *diaglib.DiagnosticClient) {
+	if metricsMux == nil {
+		return
+	}
+
+	metricsMux.HandleFunc("/bad-blocks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		diag.BadBlocksJson(w)
+	})
+
+	// ?hash=<block hash>&file=<artifact file name>
+	metricsMux.HandleFunc("/bad-blocks/artifact", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		path, err := diag.BadBlockArtifactPath(query.Get("hash"), query.Get("file"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", query.Get("file")))
+		http.ServeFile(w, r, path)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erigontech/erigon/turbo/node"
+)
+
+const defaultBlockPropagationPeers = 20
+
+func SetupBlockPropagationAccess(metricsMux *http.ServeMux, node *node.ErigonNode) {
+	if metricsMux == nil {
+		return
+	}
+
+	metricsMux.HandleFunc("/block-propagation", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		limit := defaultBlockPropagationPeers
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			var err error
+			if limit, err = strconv.Atoi(limitStr); err != nil || limit < 0 {
+				http.Error(w, "invalid limit: "+limitStr, http.StatusBadRequest)
+				return
+			}
+		}
+
+		json.NewEncoder(w).Encode(node.Backend().BlockPropagation(limit))
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	diaglib "github.com/erigontech/erigon-lib/diagnostics"
+)
+
+func SetupFlightRecorderAccess(metricsMux *http.ServeMux, diag *diaglib.DiagnosticClient) {
+	if metricsMux == nil {
+		return
+	}
+
+	metricsMux.HandleFunc("/flight-recorder/dumps", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		diag.FlightRecorderDumpsJson(w)
+	})
+
+	// ?dump=<id>&segment=<idx>&profile=trace|goroutine|heap|block
+	metricsMux.HandleFunc("/flight-recorder/profile", func(w http.ResponseWriter, r *http.Request) {
+		writeFlightRecorderProfile(w, r, diag)
+	})
+
+	metricsMux.HandleFunc("/flight-recorder/capture", func(w http.ResponseWriter, r *http.Request) {
+		reason := r.URL.Query().Get("reason")
+		if reason == "" {
+			reason = "requested"
+		}
+		if err := diag.FlightRecorderCapture(reason); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+func writeFlightRecorderProfile(w http.ResponseWriter, r *http.Request, diag *diaglib.DiagnosticClient) {
+	query := r.URL.Query()
+	dumpID, err := strconv.Atoi(query.Get("dump"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid dump id: %v", err), http.StatusBadRequest)
+		return
+	}
+	segment, err := strconv.Atoi(query.Get("segment"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid segment: %v", err), http.StatusBadRequest)
+		return
+	}
+	profile := query.Get("profile")
+
+	data, err := diag.FlightRecorderProfile(dumpID, segment, profile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	contentType := "application/profile"
+	if profile == diaglib.ProfileTrace {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%d-%d", profile, dumpID, segment)))
+	w.Write(data)
+}
@@ -39,6 +39,9 @@ var (
 	pprofPortFlag           = "pprof.port"
 	pprofAddrFlag           = "pprof.addr"
 	diagnoticsSpeedTestFlag = "diagnostics.speedtest"
+	flightRecorderFlag      = "diagnostics.flightrecorder"
+	flightRecorderWinFlag   = "diagnostics.flightrecorder.window"
+	flightRecorderStallFlag = "diagnostics.flightrecorder.stall"
 	webSeedsFlag            = "webseed"
 	chainFlag               = "chain"
 )
@@ -78,6 +81,12 @@ func Setup(ctx *cli.Context, node *node.ErigonNode, metricsMux *http.ServeMux, p
 	speedTest := ctx.Bool(diagnoticsSpeedTestFlag)
 	diagnostic, err := diaglib.NewDiagnosticClient(ctx.Context, diagMux, node.Backend().DataDir(), speedTest, webseedsList)
 	if err == nil {
+		if ctx.Bool(flightRecorderFlag) {
+			cfg := diaglib.DefaultFlightRecorderConfig
+			cfg.Window = ctx.Duration(flightRecorderWinFlag)
+			cfg.StallTimeout = ctx.Duration(flightRecorderStallFlag)
+			diagnostic.EnableFlightRecorder(cfg)
+		}
 		diagnostic.Setup()
 		SetupEndpoints(ctx, node, diagMux, diagnostic)
 	} else {
@@ -141,10 +150,15 @@ func SetupEndpoints(ctx *cli.Context, node *node.ErigonNode, diagMux *http.Serve
 	SetupNodeInfoAccess(diagMux, node)
 	SetupPeersAccess(ctx, diagMux, node, diagnostic)
 	SetupBootnodesAccess(diagMux, node)
+	SetupBlockPropagationAccess(diagMux, node)
 	SetupStagesAccess(diagMux, diagnostic)
 	SetupMemAccess(diagMux)
 	SetupHeadersAccess(diagMux, diagnostic)
 	SetupBodiesAccess(diagMux, diagnostic)
+	SetupReorgsAccess(diagMux, diagnostic)
+	SetupHeimdallAccess(diagMux, diagnostic)
+	SetupBadBlocksAccess(diagMux, diagnostic)
 	SetupSysInfoAccess(diagMux, diagnostic)
 	SetupProfileAccess(diagMux, diagnostic)
+	SetupFlightRecorderAccess(diagMux, diagnostic)
 }
@@ -51,6 +51,11 @@ func SetupStagesAccess(metricsMux *http.ServeMux, diag *diaglib.DiagnosticClient
 		w.Header().Set("Content-Type", "application/json")
 		writeSyncStages(w, diag)
 	})
+
+	metricsMux.HandleFunc("/sync-progress", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		writeSyncProgress(w, diag)
+	})
 }
 
 func writeNetworkSpeed(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
@@ -72,3 +77,7 @@ func writeFilesList(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
 func writeSyncStages(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
 	diag.SyncStagesJson(w)
 }
+
+func writeSyncProgress(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
+	diag.SyncProgressJson(w)
+}
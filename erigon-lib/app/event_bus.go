@@ -0,0 +1,95 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"reflect"
+	"sync"
+)
+
+// EventBus - in-process publish/subscribe of typed events. Thread-safe.
+// Each subscriber gets the events of the type it subscribed to through its own buffered channel, a slow subscriber
+// drops its oldest events instead of blocking the publishers.
+type EventBus struct {
+	subs   map[reflect.Type]map[uint64]any // event type -> subscription id -> chan E
+	nextID uint64
+	lock   sync.Mutex
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: map[reflect.Type]map[uint64]any{}}
+}
+
+var defaultBus = NewEventBus()
+
+// DefaultEventBus carries the node lifecycle events, see events.go
+func DefaultEventBus() *EventBus { return defaultBus }
+
+// Subscribe to the events of type E. Call the returned function to unsubscribe, it closes the channel.
+func Subscribe[E any](bus *EventBus, buffer int) (<-chan E, func()) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	typ := reflect.TypeFor[E]()
+	ch := make(chan E, max(buffer, 1))
+	bus.nextID++
+	id := bus.nextID
+	if bus.subs[typ] == nil {
+		bus.subs[typ] = map[uint64]any{}
+	}
+	bus.subs[typ][id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			bus.lock.Lock()
+			defer bus.lock.Unlock()
+			delete(bus.subs[typ], id)
+			close(ch)
+		})
+	}
+}
+
+// Publish the event to all subscribers of its type without waiting for them to process it
+func Publish[E any](bus *EventBus, event E) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	for _, sub := range bus.subs[reflect.TypeFor[E]()] {
+		ch := sub.(chan E)
+		for {
+			select {
+			case ch <- event:
+			default:
+				// drop the oldest event of the slow subscriber
+				select {
+				case <-ch:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+// HasSubscribers - if anyone is subscribed to the events of type E, allows to skip building expensive events
+func HasSubscribers[E any](bus *EventBus) bool {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	return len(bus.subs[reflect.TypeFor[E]()]) > 0
+}
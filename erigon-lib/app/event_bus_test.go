@@ -0,0 +1,88 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBusTyped(t *testing.T) {
+	bus := NewEventBus()
+	require.False(t, HasSubscribers[PeerConnected](bus))
+
+	connected, unsubscribeConnected := Subscribe[PeerConnected](bus, 4)
+	defer unsubscribeConnected()
+	disconnected, unsubscribeDisconnected := Subscribe[PeerDisconnected](bus, 4)
+	require.True(t, HasSubscribers[PeerConnected](bus))
+
+	Publish(bus, PeerConnected{PeerID: [64]byte{1}})
+	Publish(bus, PeerDisconnected{PeerID: [64]byte{2}})
+	Publish(bus, SnapshotBuilt{}) // nobody is subscribed
+
+	require.Equal(t, PeerConnected{PeerID: [64]byte{1}}, <-connected)
+	require.Equal(t, PeerDisconnected{PeerID: [64]byte{2}}, <-disconnected)
+	require.Empty(t, connected)
+
+	unsubscribeDisconnected()
+	unsubscribeDisconnected()
+	_, ok := <-disconnected
+	require.False(t, ok)
+	require.False(t, HasSubscribers[PeerDisconnected](bus))
+	Publish(bus, PeerDisconnected{})
+}
+
+func TestEventBusStageEventsOrder(t *testing.T) {
+	bus := NewEventBus()
+	events, unsubscribe := Subscribe[StageEvent](bus, 16)
+	defer unsubscribe()
+	require.False(t, HasSubscribers[StageStarted](bus))
+
+	published := []StageEvent{
+		StageStarted{Stage: "Execution"},
+		StageFinished{Stage: "Execution", BlockNum: 10},
+		StageStarted{Stage: "Execution", Unwind: true},
+		StageUnwound{Stage: "Execution", From: 10, To: 5},
+		StageStarted{Stage: "Senders"},
+		StageFinished{Stage: "Senders", BlockNum: 10},
+	}
+	for _, e := range published {
+		Publish(bus, e)
+	}
+	for _, e := range published {
+		require.Equal(t, e, <-events)
+	}
+}
+
+func TestEventBusSlowSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	fast, unsubscribeFast := Subscribe[uint64](bus, 16)
+	defer unsubscribeFast()
+	slow, unsubscribeSlow := Subscribe[uint64](bus, 2)
+	defer unsubscribeSlow()
+
+	for i := uint64(0); i < 10; i++ {
+		Publish(bus, i)
+	}
+	// the slow subscriber keeps the newest events, others get all of them
+	require.Equal(t, uint64(8), <-slow)
+	require.Equal(t, uint64(9), <-slow)
+	for i := uint64(0); i < 10; i++ {
+		require.Equal(t, i, <-fast)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"time"
+
+	"github.com/erigontech/erigon-lib/common"
+)
+
+// Node lifecycle events, published on the DefaultEventBus
+
+// StageEvent - StageStarted, StageFinished or StageUnwound. The stage events are published as StageEvent: one
+// subscription receives all of them in the order the staged sync went through the stages.
+type StageEvent interface {
+	stageEvent()
+}
+
+func (StageStarted) stageEvent()  {}
+func (StageFinished) stageEvent() {}
+func (StageUnwound) stageEvent()  {}
+
+// StageStarted - the staged sync started to run the stage forward or to unwind it
+type StageStarted struct {
+	Stage  string
+	Unwind bool
+}
+
+// StageFinished - the staged sync ran the stage forward up to the block, or failed with the error
+type StageFinished struct {
+	Stage    string
+	BlockNum uint64
+	Took     time.Duration
+	Err      error
+}
+
+// StageUnwound - the stage was unwound from the block to the block
+type StageUnwound struct {
+	Stage string
+	From  uint64
+	To    uint64
+	Took  time.Duration
+	Err   error
+}
+
+// SnapshotBuilt - new snapshot files were produced by this node
+type SnapshotBuilt struct {
+	Files []string
+}
+
+type PeerConnected struct {
+	PeerID [64]byte
+}
+
+type PeerDisconnected struct {
+	PeerID [64]byte
+}
+
+// MilestoneReceived - a new bor milestone was fetched from heimdall
+type MilestoneReceived struct {
+	ID         uint64
+	StartBlock uint64
+	EndBlock   uint64
+	RootHash   common.Hash
+}
+
+// Publish the lifecycle event on the DefaultEventBus
+func PublishEvent[E any](event E) {
+	Publish(defaultBus, event)
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+var (
+	snapshotFilesBuilt  = metrics.GetOrCreateCounter("snapshot_files_built")
+	sentryPeers         = metrics.GetOrCreateGauge("sentry_peers_connected")
+	milestoneEndBlock   = metrics.GetOrCreateGauge("heimdall_milestone_end_block")
+	stageTookMetricName = `sync_stage_took_seconds{stage="%s"}`
+	stageErrMetricName  = `sync_stage_errors{stage="%s"}`
+	unwindsMetricName   = `sync_stage_unwinds{stage="%s"}`
+)
+
+// RunEventMetrics updates the metrics derived from the lifecycle events published on the bus until the context is done
+func RunEventMetrics(ctx context.Context, bus *EventBus) {
+	stageEvents, unsubscribeStageEvents := Subscribe[StageEvent](bus, 128)
+	defer unsubscribeStageEvents()
+	snapshotBuilt, unsubscribeSnapshotBuilt := Subscribe[SnapshotBuilt](bus, 16)
+	defer unsubscribeSnapshotBuilt()
+	peerConnected, unsubscribePeerConnected := Subscribe[PeerConnected](bus, 256)
+	defer unsubscribePeerConnected()
+	peerDisconnected, unsubscribePeerDisconnected := Subscribe[PeerDisconnected](bus, 256)
+	defer unsubscribePeerDisconnected()
+	milestoneReceived, unsubscribeMilestoneReceived := Subscribe[MilestoneReceived](bus, 16)
+	defer unsubscribeMilestoneReceived()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-stageEvents:
+			switch e := e.(type) {
+			case StageFinished:
+				if e.Err != nil {
+					metrics.GetOrCreateCounter(fmt.Sprintf(stageErrMetricName, e.Stage)).Inc()
+					continue
+				}
+				metrics.GetOrCreateGauge(fmt.Sprintf(stageTookMetricName, e.Stage)).Set(e.Took.Seconds())
+			case StageUnwound:
+				metrics.GetOrCreateCounter(fmt.Sprintf(unwindsMetricName, e.Stage)).Inc()
+			}
+		case e := <-snapshotBuilt:
+			snapshotFilesBuilt.AddInt(len(e.Files))
+		case <-peerConnected:
+			sentryPeers.Inc()
+		case <-peerDisconnected:
+			sentryPeers.Dec()
+		case e := <-milestoneReceived:
+			milestoneEndBlock.SetUint64(e.EndBlock)
+		}
+	}
+}
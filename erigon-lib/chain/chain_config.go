@@ -85,6 +85,13 @@ type Config struct {
 	// See also EIP-6110: Supply validator deposits on chain
 	DepositContract common.Address `json:"depositContractAddress,omitempty"`
 
+	// (Optional) RIP-7560: accept native account abstraction transactions (experimental, for AA testnets)
+	AllowAA bool `json:"allowAA,omitempty"`
+
+	// (Optional) EIPs activated from genesis on top of the scheduled forks (custom devnets only).
+	// See vm.ActivateableEips for the supported ones.
+	ExtraEips []int `json:"extraEips,omitempty"`
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -475,6 +482,8 @@ func (c *EthashConfig) String() string {
 type CliqueConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
 	Epoch  uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
+
+	Signers []common.Address `json:"signers,omitempty"` // Initial signers of a custom devnet, put into its genesis extradata
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -516,6 +525,7 @@ type Rules struct {
 	IsCancun, IsNapoli                                bool
 	IsPrague, IsOsaka                                 bool
 	IsAura                                            bool
+	IsAA                                              bool
 }
 
 // Rules ensures c's ChainID is not nil and returns a new Rules instance
@@ -542,6 +552,7 @@ func (c *Config) Rules(num uint64, time uint64) *Rules {
 		IsPrague:           c.IsPrague(time),
 		IsOsaka:            c.IsOsaka(time),
 		IsAura:             c.Aura != nil,
+		IsAA:               c.AllowAA,
 	}
 }
 
@@ -28,6 +28,7 @@ const (
 	BorE2ETestChain2Val = "bor-e2e-test-2Val"
 	Chiado              = "chiado"
 	Test                = "test"
+	Custom              = "custom" // chain spec is supplied with --chain.config
 )
 
 var All = []string{
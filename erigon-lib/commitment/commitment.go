@@ -1131,19 +1131,20 @@ func (t *Updates) HashSort(ctx context.Context, fn func(hk, pk []byte, update *U
 
 		t.initCollector()
 	case ModeUpdate:
+		var err error
 		t.tree.Ascend(func(item *KeyUpdate) bool {
-			select {
-			case <-ctx.Done():
+			if err = ctx.Err(); err != nil {
 				return false
-			default:
 			}
-
-			if err := fn(item.hashedKey, toBytesZeroCopy(item.plainKey), item.update); err != nil {
+			if err = fn(item.hashedKey, toBytesZeroCopy(item.plainKey), item.update); err != nil {
 				return false
 			}
 			return true
 		})
 		t.tree.Clear(true)
+		if err != nil {
+			return err
+		}
 	default:
 		return nil
 	}
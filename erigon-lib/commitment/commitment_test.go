@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/rand"
 	"sort"
@@ -324,6 +325,37 @@ func TestNewUpdates(t *testing.T) {
 
 }
 
+func TestUpdates_HashSortInterrupted(t *testing.T) {
+	t.Parallel()
+
+	ut := NewUpdates(ModeUpdate, t.TempDir(), keyHasherNoop)
+	for i := byte(0); i < 4; i++ {
+		ut.TouchPlainKey(string([]byte{i, 1, 2, 3}), []byte("value"), ut.TouchStorage)
+	}
+
+	errStop := errors.New("stop")
+	visited := 0
+	err := ut.HashSort(context.Background(), func(hk, pk []byte, upd *Update) error {
+		visited++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 1, visited)
+
+	for i := byte(0); i < 4; i++ {
+		ut.TouchPlainKey(string([]byte{i, 1, 2, 3}), []byte("value"), ut.TouchStorage)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	visited = 0
+	err = ut.HashSort(ctx, func(hk, pk []byte, upd *Update) error {
+		visited++
+		cancel()
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, visited)
+}
+
 func TestUpdates_TouchPlainKey(t *testing.T) {
 	t.Parallel()
 
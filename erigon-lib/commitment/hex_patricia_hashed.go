@@ -33,6 +33,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/etl"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/trie"
@@ -45,7 +46,6 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon-lib/common/length"
-	ecrypto "github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/rlp"
 	"golang.org/x/crypto/sha3"
 )
@@ -87,6 +87,7 @@ type HexPatriciaHashed struct {
 	hashAuxBuffer [128]byte     // buffer to compute cell hash or write hash-related things
 	auxBuffer     *bytes.Buffer // auxiliary buffer used during branch updates encoding
 	branchEncoder *BranchEncoder
+	warmup        *warmer // reads ahead of the fold/unfold loop in Process, if set
 
 	depthsToTxNum [129]uint64 // endTxNum of file with branch data for that depth
 	hadToLoadL    map[uint64]skipStat
@@ -619,7 +620,7 @@ func (hph *HexPatriciaHashed) accountLeafHashWithKey(buf, key []byte, val rlp.Rl
 	var compactLen int
 	var ni int
 	var compact0 byte
-	if hasTerm(key) {
+	if nibbles.HasTerm(key) {
 		compactLen = (len(key)-1)/2 + 1
 		if len(key)&1 == 0 {
 			compact0 = 48 + key[0] // Odd (1<<4) + first nibble
@@ -646,7 +647,7 @@ func (hph *HexPatriciaHashed) extensionHash(key []byte, hash []byte) ([length.Ha
 	var compactLen int
 	var ni int
 	var compact0 byte
-	if hasTerm(key) {
+	if nibbles.HasTerm(key) {
 		compactLen = (len(key)-1)/2 + 1
 		if len(key)&1 == 0 {
 			compact0 = 0x30 + key[0] // Odd: (3<<4) + first nibble
@@ -774,7 +775,9 @@ func (hph *HexPatriciaHashed) computeCellHashWithStorage(cell *cell, depth int,
 					return nil, storageRootHashIsSet, nil, err
 				}
 				cell.setFromUpdate(update)
-				fmt.Printf("Storage %x was not loaded\n", cell.storageAddr[:cell.storageAddrLen])
+				if hph.trace {
+					fmt.Printf("Storage %x was not loaded\n", cell.storageAddr[:cell.storageAddrLen])
+				}
 			}
 			if singleton {
 				if hph.trace {
@@ -1170,7 +1173,7 @@ func (hph *HexPatriciaHashed) createAccountNode(c *cell, row int, hashedKey []by
 	account.Root = accountUpdate.Storage
 	account.CodeHash = accountUpdate.CodeHash
 
-	addrHash, err := compactKey(hashedKey[:64])
+	addrHash, err := nibbles.ToBytes(hashedKey[:64])
 	if err != nil {
 		return nil, err
 	}
@@ -1329,7 +1332,7 @@ func (hph *HexPatriciaHashed) ToTrie(hashedKey []byte, codeReads map[libcommon.H
 
 // unfoldBranchNode returns true if unfolding has been done
 func (hph *HexPatriciaHashed) unfoldBranchNode(row, depth int, deleted bool) (bool, error) {
-	key := hexToCompact(hph.currentKey[:hph.currentKeyLen])
+	key := nibbles.HexToCompact(hph.currentKey[:hph.currentKeyLen])
 	branchData, fileEndTxNum, err := hph.ctx.Branch(key)
 	if err != nil {
 		return false, err
@@ -1348,7 +1351,7 @@ func (hph *HexPatriciaHashed) unfoldBranchNode(row, depth int, deleted bool) (bo
 	}
 	if len(branchData) == 0 {
 		log.Warn("got empty branch data during unfold", "key", hex.EncodeToString(key), "row", row, "depth", depth, "deleted", deleted)
-		return false, fmt.Errorf("empty branch data read during unfold, prefix %x", hexToCompact(hph.currentKey[:hph.currentKeyLen]))
+		return false, fmt.Errorf("empty branch data read during unfold, prefix %x", nibbles.HexToCompact(hph.currentKey[:hph.currentKeyLen]))
 	}
 	hph.branchBefore[row] = true
 	bitmap := binary.BigEndian.Uint16(branchData[0:])
@@ -1596,7 +1599,7 @@ func (hph *HexPatriciaHashed) fold() (err error) {
 	}
 
 	depth := hph.depths[row]
-	updateKey := hexToCompact(hph.currentKey[:updateKeyLen])
+	updateKey := nibbles.HexToCompact(hph.currentKey[:updateKeyLen])
 	partsCount := bits.OnesCount16(hph.afterMap[row])
 	defer func() { hph.depthsToTxNum[depth] = 0 }()
 
@@ -1879,50 +1882,59 @@ func (hph *HexPatriciaHashed) RootHash() ([]byte, error) {
 	return rootHash[1:], nil // first byte is 128+hash_len=160
 }
 
+// WitnessStats reports the progress of GenerateWitness. It is returned along with the error when the generation
+// fails or is interrupted, so callers can tell how far it got.
+type WitnessStats struct {
+	Keys      uint64 // number of keys the witness was requested for
+	Processed uint64 // number of keys whose witness trie was built
+	Accounts  uint64
+	Storage   uint64
+	Took      time.Duration
+}
+
 // Generate the block witness. This works by loading each key from the list of updates (they are not really updates since we won't modify the trie,
 // but currently need to be defined like that for the fold/unfold algorithm) into the grid and traversing the grid to convert it into `trie.Trie`.
 // All the individual tries are combined to create the final witness trie.
 // Because the grid is lacking information about the code in smart contract accounts which is also part of the witness, we need to provide that as an input parameter to this function (`codeReads`)
-func (hph *HexPatriciaHashed) GenerateWitness(ctx context.Context, updates *Updates, codeReads map[libcommon.Hash]witnesstypes.CodeWithHash, expectedRootHash []byte, logPrefix string) (witnessTrie *trie.Trie, rootHash []byte, err error) {
+// Cancellation of ctx is checked between keys.
+func (hph *HexPatriciaHashed) GenerateWitness(ctx context.Context, updates *Updates, codeReads map[libcommon.Hash]witnesstypes.CodeWithHash, expectedRootHash []byte, logPrefix string, logger log.Logger) (witnessTrie *trie.Trie, rootHash []byte, stats WitnessStats, err error) {
 	var (
-		m  runtime.MemStats
-		ki uint64
-
-		updatesCount = updates.Size()
-		logEvery     = time.NewTicker(20 * time.Second)
+		m        runtime.MemStats
+		start    = time.Now()
+		logEvery = time.NewTicker(20 * time.Second)
 	)
 	defer logEvery.Stop()
-	var tries []*trie.Trie = make([]*trie.Trie, 0, len(updates.keys)) // slice of tries, i.e the witness for each key, these will be all merged into single trie
+	defer func() { stats.Took = time.Since(start) }()
+
+	stats.Keys = updates.Size()
+	var tries []*trie.Trie = make([]*trie.Trie, 0, stats.Keys) // slice of tries, i.e the witness for each key, these will be all merged into single trie
 	err = updates.HashSort(ctx, func(hashedKey, plainKey []byte, stateUpdate *Update) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		select {
 		case <-logEvery.C:
 			dbg.ReadMemStats(&m)
-			log.Info(fmt.Sprintf("[%s][agg] computing trie", logPrefix),
-				"progress", fmt.Sprintf("%s/%s", common.PrettyCounter(ki), common.PrettyCounter(updatesCount)),
+			logger.Info(fmt.Sprintf("[%s][agg] computing witness", logPrefix),
+				"progress", fmt.Sprintf("%s/%s", common.PrettyCounter(stats.Processed), common.PrettyCounter(stats.Keys)),
 				"alloc", common.ByteCount(m.Alloc), "sys", common.ByteCount(m.Sys))
-
 		default:
 		}
 
-		var tr *trie.Trie
-		var computedRootHash []byte
-
-		fmt.Printf("\n%d/%d) plainKey [%x] hashedKey [%x] currentKey [%x]\n", ki+1, updatesCount, plainKey, hashedKey, hph.currentKey[:hph.currentKeyLen])
-
-		if len(plainKey) == 20 { // account
-			account, err := hph.ctx.Account(plainKey)
-			if err != nil {
+		if hph.trace {
+			logger.Trace(fmt.Sprintf("[%s] witness key", logPrefix), "n", stats.Processed+1, "of", stats.Keys,
+				"plainKey", hex.EncodeToString(plainKey), "hashedKey", hex.EncodeToString(hashedKey), "currentKey", hex.EncodeToString(hph.currentKey[:hph.currentKeyLen]))
+		}
+		if len(plainKey) == hph.accountKeyLen {
+			if _, err := hph.ctx.Account(plainKey); err != nil {
 				return fmt.Errorf("account with plainkey=%x not found: %w", plainKey, err)
-			} else {
-				addrHash := ecrypto.Keccak256(plainKey)
-				fmt.Printf("account with plainKey=%x, addrHash=%x FOUND = %v\n", plainKey, addrHash, account)
 			}
+			stats.Accounts++
 		} else {
-			storage, err := hph.ctx.Storage(plainKey)
-			if err != nil {
+			if _, err := hph.ctx.Storage(plainKey); err != nil {
 				return fmt.Errorf("storage with plainkey=%x not found: %w", plainKey, err)
 			}
-			fmt.Printf("storage found = %v\n", storage.Storage)
+			stats.Storage++
 		}
 
 		// Keep folding until the currentKey is the prefix of the key we modify
@@ -1937,60 +1949,56 @@ func (hph *HexPatriciaHashed) GenerateWitness(ctx context.Context, updates *Upda
 				return fmt.Errorf("unfold: %w", err)
 			}
 		}
-		hph.PrintGrid()
+		if hph.trace {
+			hph.PrintGrid()
+		}
 
 		// convert grid to trie.Trie
-		tr, err = hph.ToTrie(hashedKey, codeReads) // build witness trie for this key, based on the current state of the grid
+		tr, err := hph.ToTrie(hashedKey, codeReads) // build witness trie for this key, based on the current state of the grid
 		if err != nil {
 			return err
 		}
-		computedRootHash = tr.Root()
-		fmt.Printf("computedRootHash = %x\n", computedRootHash)
-
-		if !bytes.Equal(computedRootHash, expectedRootHash) {
-			err = fmt.Errorf("root hash mismatch computedRootHash(%x)!=expectedRootHash(%x)", computedRootHash, expectedRootHash)
-			return err
+		if computedRootHash := tr.Root(); !bytes.Equal(computedRootHash, expectedRootHash) {
+			return fmt.Errorf("root hash mismatch computedRootHash(%x)!=expectedRootHash(%x)", computedRootHash, expectedRootHash)
 		}
 
 		tries = append(tries, tr)
-		ki++
+		stats.Processed++
 		return nil
 	})
-
 	if err != nil {
-		return nil, nil, fmt.Errorf("hash sort failed: %w", err)
+		return nil, nil, stats, fmt.Errorf("hash sort failed: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, stats, err
 	}
 
 	// Folding everything up to the root
 	for hph.activeRows > 0 {
 		if err := hph.fold(); err != nil {
-			return nil, nil, fmt.Errorf("final fold: %w", err)
+			return nil, nil, stats, fmt.Errorf("final fold: %w", err)
 		}
 	}
 
 	rootHash, err = hph.RootHash()
 	if err != nil {
-		return nil, nil, fmt.Errorf("root hash evaluation failed: %w", err)
+		return nil, nil, stats, fmt.Errorf("root hash evaluation failed: %w", err)
 	}
 	if hph.trace {
-		fmt.Printf("root hash %x updates %d\n", rootHash, updatesCount)
+		logger.Trace(fmt.Sprintf("[%s] witness root hash", logPrefix), "hash", hex.EncodeToString(rootHash), "keys", stats.Keys)
 	}
 
 	// merge all individual tries
 	witnessTrie, err = trie.MergeTries(tries)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, stats, err
 	}
 
-	witnessTrieRootHash := witnessTrie.Root()
-
-	fmt.Printf("mergedTrieRootHash = %x\n", witnessTrieRootHash)
-
-	if !bytes.Equal(witnessTrieRootHash, expectedRootHash) {
-		return nil, nil, fmt.Errorf("root hash mismatch witnessTrieRootHash(%x)!=expectedRootHash(%x)", witnessTrieRootHash, expectedRootHash)
+	if witnessTrieRootHash := witnessTrie.Root(); !bytes.Equal(witnessTrieRootHash, expectedRootHash) {
+		return nil, nil, stats, fmt.Errorf("root hash mismatch witnessTrieRootHash(%x)!=expectedRootHash(%x)", witnessTrieRootHash, expectedRootHash)
 	}
 
-	return witnessTrie, rootHash, nil
+	return witnessTrie, rootHash, stats, nil
 }
 
 func (hph *HexPatriciaHashed) Process(ctx context.Context, updates *Updates, logPrefix string) (rootHash []byte, err error) {
@@ -2006,7 +2014,7 @@ func (hph *HexPatriciaHashed) Process(ctx context.Context, updates *Updates, log
 	defer logEvery.Stop()
 	//hph.trace = true
 
-	err = updates.HashSort(ctx, func(hashedKey, plainKey []byte, stateUpdate *Update) error {
+	processKey := func(hashedKey, plainKey []byte, stateUpdate *Update) error {
 		select {
 		case <-logEvery.C:
 			dbg.ReadMemStats(&m)
@@ -2059,7 +2067,12 @@ func (hph *HexPatriciaHashed) Process(ctx context.Context, updates *Updates, log
 		mxTrieProcessedKeys.Inc()
 		ki++
 		return nil
-	})
+	}
+	if hph.warmup != nil {
+		err = hph.processWithWarmup(ctx, updates, processKey)
+	} else {
+		err = updates.HashSort(ctx, processKey)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("hash sort failed: %w", err)
 	}
@@ -2119,6 +2132,16 @@ func (hph *HexPatriciaHashed) Process(ctx context.Context, updates *Updates, log
 
 func (hph *HexPatriciaHashed) SetTrace(trace bool) { hph.trace = trace }
 
+// SetWarmup makes Process read the branches, accounts and storage of the updated keys ahead of the fold/unfold loop,
+// using the given number of workers with a context each. A nil factory disables the warm-up.
+func (hph *HexPatriciaHashed) SetWarmup(factory WarmupContextFactory, workers int, logger log.Logger) {
+	if factory == nil || workers <= 0 {
+		hph.warmup = nil
+		return
+	}
+	hph.warmup = &warmer{factory: factory, workers: workers, accountKeyLen: hph.accountKeyLen, logger: logger}
+}
+
 func (hph *HexPatriciaHashed) Variant() TrieVariant { return VariantHexPatriciaTrie }
 
 // Reset allows HexPatriciaHashed instance to be reused for the new commitment calculation
@@ -2530,76 +2553,11 @@ func HexTrieStateToString(enc []byte) (string, error) {
 	return sb.String(), nil
 }
 
-func hexToCompact(key []byte) []byte {
-	zeroByte, keyPos, keyLen := makeCompactZeroByte(key)
-	bufLen := keyLen/2 + 1 // always > 0
-	buf := make([]byte, bufLen)
-	buf[0] = zeroByte
-	return decodeKey(key[keyPos:], buf)
-}
-
-func makeCompactZeroByte(key []byte) (compactZeroByte byte, keyPos, keyLen int) {
-	keyLen = len(key)
-	if hasTerm(key) {
-		keyLen--
-		compactZeroByte = 0x20
-	}
-	var firstNibble byte
-	if len(key) > 0 {
-		firstNibble = key[0]
-	}
-	if keyLen&1 == 1 {
-		compactZeroByte |= 0x10 | firstNibble // Odd: (1<<4) + first nibble
-		keyPos++
-	}
-
-	return
-}
-
-func decodeKey(key, buf []byte) []byte {
-	keyLen := len(key)
-	if hasTerm(key) {
-		keyLen--
-	}
-	for keyIndex, bufIndex := 0, 1; keyIndex < keyLen; keyIndex, bufIndex = keyIndex+2, bufIndex+1 {
-		if keyIndex == keyLen-1 {
-			buf[bufIndex] = buf[bufIndex] & 0x0f
-		} else {
-			buf[bufIndex] = key[keyIndex+1]
-		}
-		buf[bufIndex] |= key[keyIndex] << 4
-	}
-	return buf
-}
-
+// CompactedKeyToHex translates from COMPACT to HEX encoding.
+//
+// Deprecated: use nibbles.CompactToHex.
 func CompactedKeyToHex(compact []byte) []byte {
-	if len(compact) == 0 {
-		return compact
-	}
-	base := keybytesToHexNibbles(compact)
-	// delete terminator flag
-	if base[0] < 2 {
-		base = base[:len(base)-1]
-	}
-	// apply odd flag
-	chop := 2 - base[0]&1
-	return base[chop:]
-}
-
-func keybytesToHexNibbles(str []byte) []byte {
-	l := len(str)*2 + 1
-	var nibbles = make([]byte, l)
-	for i, b := range str {
-		nibbles[i*2] = b / 16
-		nibbles[i*2+1] = b % 16
-	}
-	nibbles[l-1] = 16
-	return nibbles
-}
-
-// hasTerm returns whether a hex key has the terminator flag.
-func hasTerm(s []byte) bool {
-	return len(s) > 0 && s[len(s)-1] == 16
+	return nibbles.CompactToHex(compact)
 }
 
 func commonPrefixLen(b1, b2 []byte) int {
@@ -2632,45 +2590,7 @@ func (hph *HexPatriciaHashed) HashAndNibblizeKey(key []byte) []byte {
 		hph.keccak.Read(hashedKey[length.Hash:])
 	}
 
-	nibblized := make([]byte, len(hashedKey)*2)
-	for i, b := range hashedKey {
-		nibblized[i*2] = (b >> 4) & 0xf
-		nibblized[i*2+1] = b & 0xf
-	}
-	return nibblized
-}
-
-func nibblize(key []byte) []byte { // nolint:unused
-	nibblized := make([]byte, len(key)*2)
-	for i, b := range key {
-		nibblized[i*2] = (b >> 4) & 0xf
-		nibblized[i*2+1] = b & 0xf
-	}
-	return nibblized
-}
-
-// compactKey takes a slice of nibbles and compacts them into the original byte slice.
-// It returns an error if the input contains invalid nibbles (values > 0xF).
-func compactKey(nibbles []byte) ([]byte, error) {
-	// If the number of nibbles is odd, you might decide to handle it differently.
-	// For this example, we'll return an error.
-	if len(nibbles)%2 != 0 {
-		return nil, errors.New("nibbles slice has an odd length")
-	}
-
-	key := make([]byte, len(nibbles)/2)
-	for i := 0; i < len(key); i++ {
-		highNibble := nibbles[i*2]
-		lowNibble := nibbles[i*2+1]
-
-		// Validate that each nibble is indeed a nibble
-		if highNibble > 0xF || lowNibble > 0xF {
-			return nil, fmt.Errorf("invalid nibble at position %d or %d: 0x%X, 0x%X", i*2, i*2+1, highNibble, lowNibble)
-		}
-
-		key[i] = (highNibble << 4) | (lowNibble & 0x0F)
-	}
-	return key, nil
+	return nibbles.FromBytes(hashedKey)
 }
 
 func (hph *HexPatriciaHashed) Grid() [128][16]cell {
@@ -19,6 +19,7 @@ package commitment
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
 	"sort"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/log/v3"
 )
 
 func Test_HexPatriciaHashed_ResetThenSingularUpdates(t *testing.T) {
@@ -1338,3 +1340,59 @@ func Test_HexPatriciaHashed_ProcessWithDozensOfStorageKeys(t *testing.T) {
 	}
 	require.EqualValues(t, rBatch, rSeq, "sequential and batch root should match")
 }
+
+func Test_HexPatriciaHashed_ProcessWithWarmup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	msOne := NewMockState(t)
+	msTwo := NewMockState(t)
+
+	rnd := rand.New(rand.NewSource(42))
+	builder := NewUpdateBuilder()
+	addrs := make([]string, 0, 64)
+	for i := 0; i < 64; i++ {
+		addr := make([]byte, length.Addr)
+		rnd.Read(addr)
+		addrs = append(addrs, hex.EncodeToString(addr))
+		builder.Balance(addrs[i], uint64(i+1))
+		if i%4 == 0 {
+			builder.Storage(addrs[i], fmt.Sprintf("%02x", i), fmt.Sprintf("%04x", i))
+		}
+	}
+	plainKeys, updates := builder.Build()
+
+	trieOne := NewHexPatriciaHashed(length.Addr, msOne, msOne.TempDir())
+	trieTwo := NewHexPatriciaHashed(length.Addr, msTwo, msTwo.TempDir())
+
+	require.NoError(t, msOne.applyPlainUpdates(plainKeys, updates))
+	require.NoError(t, msTwo.applyPlainUpdates(plainKeys, updates))
+
+	rootOne, err := trieOne.Process(ctx, WrapKeyUpdates(t, ModeDirect, trieOne.HashAndNibblizeKey, plainKeys, updates), "")
+	require.NoError(t, err)
+	rootTwo, err := trieTwo.Process(ctx, WrapKeyUpdates(t, ModeDirect, trieTwo.HashAndNibblizeKey, plainKeys, updates), "")
+	require.NoError(t, err)
+	require.EqualValues(t, rootOne, rootTwo)
+
+	// second block modifies half of the accounts and adds some storage, so branches are read and written back
+	builder = NewUpdateBuilder()
+	for i := 0; i < len(addrs); i += 2 {
+		builder.Nonce(addrs[i], uint64(i+7))
+		builder.Storage(addrs[i], fmt.Sprintf("%02x", i+1), fmt.Sprintf("%04x", i*3))
+	}
+	plainKeys, updates = builder.Build()
+
+	require.NoError(t, msOne.applyPlainUpdates(plainKeys, updates))
+	require.NoError(t, msTwo.applyPlainUpdates(plainKeys, updates))
+
+	// workers read from a copy of the state taken before Process, as the trie writes branches into msTwo
+	snapshot := msTwo.clone()
+	trieTwo.SetWarmup(func() (PatriciaContext, func(), error) { return snapshot, func() {}, nil }, 4, log.New())
+
+	rootOne, err = trieOne.Process(ctx, WrapKeyUpdates(t, ModeDirect, trieOne.HashAndNibblizeKey, plainKeys, updates), "")
+	require.NoError(t, err)
+	rootTwo, err = trieTwo.Process(ctx, WrapKeyUpdates(t, ModeDirect, trieTwo.HashAndNibblizeKey, plainKeys, updates), "")
+	require.NoError(t, err)
+	require.EqualValues(t, rootOne, rootTwo, "root with warm-up should match root without it")
+	require.Equal(t, msOne.cm, msTwo.cm, "branches with warm-up should match branches without it")
+}
@@ -154,6 +154,18 @@ func (ms *MockState) applyPlainUpdates(plainKeys [][]byte, updates []Update) err
 	return nil
 }
 
+// clone returns a copy of the state and commitment which isn't affected by further writes to ms
+func (ms *MockState) clone() *MockState {
+	cp := NewMockState(ms.t)
+	for k, v := range ms.sm {
+		cp.sm[k] = common.Copy(v)
+	}
+	for k, v := range ms.cm {
+		cp.cm[k] = common.Copy(v)
+	}
+	return cp
+}
+
 func (ms *MockState) applyBranchNodeUpdates(updates map[string]BranchData) {
 	for key, update := range updates {
 		if pre, ok := ms.cm[key]; ok {
@@ -0,0 +1,291 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commitment
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/maphash"
+	"math/bits"
+	"sync"
+
+	"github.com/elastic/go-freelru"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/nibbles"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const (
+	warmupLookahead        = 256    // number of sorted keys the warm-up runs ahead of the fold/unfold loop
+	warmupBranchCacheLimit = 16_384 // number of branches kept for the fold/unfold loop
+)
+
+// WarmupContextFactory creates a PatriciaContext for a warm-up worker along with a func releasing it.
+// Each worker reads from its own context, so the returned contexts must be safe to use concurrently with each
+// other and must read the state the trie context had before Process. Branches written by the fold/unfold loop
+// are never served from the warm-up cache, so the worker contexts don't have to observe them.
+type WarmupContextFactory func() (PatriciaContext, func(), error)
+
+type warmupKey struct {
+	hashedKey []byte
+	plainKey  []byte
+	update    *Update
+}
+
+type warmedBranch struct {
+	data []byte
+	step uint64
+}
+
+// warmedContext serves the reads issued ahead of time by the warm-up workers and falls back
+// to the trie context for everything which hasn't been warmed (yet).
+type warmedContext struct {
+	PatriciaContext
+
+	branches *freelru.ShardedLRU[string, warmedBranch]
+	mu       sync.Mutex
+	plain    map[string]*Update  // accounts and storage of the keys being processed, taken once
+	written  map[string]struct{} // prefixes of the branches put by the fold/unfold loop, never warmed again
+}
+
+var warmupSeed = maphash.MakeSeed()
+
+func newWarmedContext(ctx PatriciaContext) *warmedContext {
+	branches, err := freelru.NewSharded[string, warmedBranch](warmupBranchCacheLimit, func(k string) uint32 {
+		return uint32(maphash.String(warmupSeed, k))
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &warmedContext{PatriciaContext: ctx, branches: branches, plain: map[string]*Update{}, written: map[string]struct{}{}}
+}
+
+func (wc *warmedContext) Branch(prefix []byte) ([]byte, uint64, error) {
+	if b, ok := wc.branches.Get(toStringZeroCopy(prefix)); ok {
+		return b.data, b.step, nil
+	}
+	return wc.PatriciaContext.Branch(prefix)
+}
+
+func (wc *warmedContext) PutBranch(prefix []byte, data []byte, prevData []byte, prevStep uint64) error {
+	wc.mu.Lock()
+	wc.written[string(prefix)] = struct{}{}
+	wc.branches.Remove(toStringZeroCopy(prefix))
+	wc.mu.Unlock()
+	return wc.PatriciaContext.PutBranch(prefix, data, prevData, prevStep)
+}
+
+// putBranch caches a branch read by a warm-up worker unless the fold/unfold loop has written it in the meantime.
+func (wc *warmedContext) putBranch(prefix []byte, b warmedBranch) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if _, ok := wc.written[toStringZeroCopy(prefix)]; ok {
+		return
+	}
+	wc.branches.Add(string(prefix), b)
+}
+
+func (wc *warmedContext) Account(plainKey []byte) (*Update, error) {
+	if u := wc.take(plainKey); u != nil {
+		return u, nil
+	}
+	return wc.PatriciaContext.Account(plainKey)
+}
+
+func (wc *warmedContext) Storage(plainKey []byte) (*Update, error) {
+	if u := wc.take(plainKey); u != nil {
+		return u, nil
+	}
+	return wc.PatriciaContext.Storage(plainKey)
+}
+
+func (wc *warmedContext) take(plainKey []byte) *Update {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	u, ok := wc.plain[toStringZeroCopy(plainKey)]
+	if ok {
+		delete(wc.plain, toStringZeroCopy(plainKey))
+	}
+	return u
+}
+
+func (wc *warmedContext) putPlain(plainKey []byte, u *Update) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.plain[string(plainKey)] = u
+}
+
+// warmer reads the branches along the path of the keys queued for the fold/unfold loop,
+// as well as their accounts and storage when the updates don't carry them.
+type warmer struct {
+	factory       WarmupContextFactory
+	workers       int
+	accountKeyLen int
+	logger        log.Logger
+
+	cache *warmedContext
+	keys  chan warmupKey
+	wg    sync.WaitGroup
+}
+
+func (w *warmer) start(ctx context.Context) {
+	w.keys = make(chan warmupKey, warmupLookahead)
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			pc, release, err := w.factory()
+			if err != nil {
+				w.logger.Debug("[commitment] warm-up worker failed to start", "err", err)
+				for range w.keys { //nolint:revive
+				}
+				return
+			}
+			defer release()
+			var c cell
+			for key := range w.keys {
+				if ctx.Err() != nil {
+					continue
+				}
+				w.warm(pc, &c, key)
+			}
+		}()
+	}
+}
+
+// stop waits for the workers to finish, the trie context is written to once they are done.
+func (w *warmer) stop() {
+	close(w.keys)
+	w.wg.Wait()
+}
+
+// warm errors are not reported: the fold/unfold loop reads whatever is missing from the trie context and fails there.
+func (w *warmer) warm(pc PatriciaContext, c *cell, key warmupKey) {
+	if key.update == nil {
+		var u *Update
+		var err error
+		if len(key.plainKey) == w.accountKeyLen {
+			u, err = pc.Account(key.plainKey)
+		} else {
+			u, err = pc.Storage(key.plainKey)
+		}
+		if err == nil {
+			w.cache.putPlain(key.plainKey, u)
+		}
+	}
+
+	// follow the branches the unfolding of hashedKey goes through: the child branch of a cell
+	// is keyed by the prefix up to the cell nibble plus its extension
+	hashedKey := key.hashedKey
+	for depth := 0; depth < len(hashedKey); {
+		prefix := nibbles.HexToCompact(hashedKey[:depth])
+		b, ok := w.cache.branches.Get(toStringZeroCopy(prefix))
+		if !ok {
+			data, step, err := pc.Branch(prefix)
+			if err != nil {
+				return
+			}
+			b = warmedBranch{data: common.Copy(data), step: step}
+			w.cache.putBranch(prefix, b)
+		}
+		if len(b.data) < 4 { // touch map and bitmap
+			return
+		}
+		data := b.data[2:]
+		bitmap, nibble := binary.BigEndian.Uint16(data), int(hashedKey[depth])
+		if bitmap&(uint16(1)<<nibble) == 0 {
+			return
+		}
+		pos := 2
+		for bitset := bitmap; bitset != 0; {
+			bit := bitset & -bitset
+			if pos >= len(data) {
+				return
+			}
+			fieldBits := cellFields(data[pos])
+			var err error
+			if pos, err = c.fillFromFields(data, pos+1, fieldBits); err != nil {
+				return
+			}
+			if bits.TrailingZeros16(bit) == nibble {
+				break
+			}
+			bitset ^= bit
+		}
+		switch {
+		case c.extLen > 0:
+			depth += 1 + c.extLen
+		case c.accountAddrLen > 0:
+			if len(hashedKey) <= 64 {
+				return
+			}
+			depth = 64 // storage of the account
+		case c.storageAddrLen > 0:
+			return
+		case c.hashLen > 0:
+			depth++
+		default:
+			return
+		}
+	}
+}
+
+// processWithWarmup feeds the sorted updates to fn once the warm-up workers had a head start of warmupLookahead keys.
+func (hph *HexPatriciaHashed) processWithWarmup(ctx context.Context, updates *Updates, fn func(hashedKey, plainKey []byte, update *Update) error) error {
+	w := hph.warmup
+	w.cache = newWarmedContext(hph.ctx)
+	hph.ctx = w.cache
+	w.start(ctx)
+	defer func() {
+		w.stop()
+		hph.ctx = w.cache.PatriciaContext
+		w.cache = nil
+	}()
+
+	var (
+		queue   [warmupLookahead]warmupKey
+		head, n int
+	)
+	next := func() error {
+		key := queue[head]
+		queue[head] = warmupKey{}
+		head, n = (head+1)%len(queue), n-1
+		return fn(key.hashedKey, key.plainKey, key.update)
+	}
+	err := updates.HashSort(ctx, func(hashedKey, plainKey []byte, update *Update) error {
+		if n == len(queue) {
+			if err := next(); err != nil {
+				return err
+			}
+		}
+		key := warmupKey{hashedKey: common.Copy(hashedKey), plainKey: common.Copy(plainKey), update: update}
+		queue[(head+n)%len(queue)] = key
+		n++
+		w.keys <- key
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for n > 0 {
+		if err := next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -40,4 +40,7 @@ const (
 	// EIP-7702: set code tx
 	PerEmptyAccountCost = 25000
 	PerAuthBaseCost     = 12500
+
+	// RIP-7560: native account abstraction
+	TxAAGas uint64 = 15000 // Base intrinsic gas of an account abstraction transaction
 )
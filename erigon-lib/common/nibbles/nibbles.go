@@ -0,0 +1,218 @@
+// Copyright 2014 The go-ethereum Authors
+// (original work)
+// Copyright 2024 The Erigon Authors
+// (modifications)
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package nibbles converts trie keys between their encodings. It is shared by commitment,
+// trie and witness code, so every one of them agrees on the terminator and the compact flags.
+//
+// Trie keys are dealt with in three distinct encodings:
+//
+// KEYBYTES encoding contains the actual key and nothing else. This encoding is the
+// input to most API functions. It is a packed encoding of hex sequences
+// with 2 nibbles per byte.
+//
+// HEX encoding contains one byte for each nibble of the key and an optional trailing
+// 'terminator' byte of value 0x10 which indicates whether or not the node at the key
+// contains a value. Hex key encoding is used for nodes loaded in memory because it's
+// convenient to access.
+//
+// COMPACT encoding is defined by the Ethereum Yellow Paper (it's called "hex prefix
+// encoding" there) and contains the bytes of the key and a flag. The high nibble of the
+// first byte contains the flag; the lowest bit encoding the oddness of the length and
+// the second-lowest encoding whether the node at the key is a value node. The low nibble
+// of the first byte is zero in the case of an even number of nibbles and the first nibble
+// in the case of an odd number. All remaining nibbles (now an even number) fit properly
+// into the remaining bytes. Compact encoding is used for nodes stored on disk.
+package nibbles
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Terminator is the trailing HEX nibble marking a key which ends at a value node.
+const Terminator = 16
+
+const (
+	compactOddFlag  = 0x10
+	compactTermFlag = 0x20
+)
+
+// HasTerm returns whether a hex key has the terminator flag.
+func HasTerm(s []byte) bool {
+	return len(s) > 0 && s[len(s)-1] == Terminator
+}
+
+// FromBytes splits every byte of key into two nibbles, high nibble first. No terminator is appended.
+func FromBytes(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2)
+	for i, b := range key {
+		nibbles[i*2] = b >> 4
+		nibbles[i*2+1] = b & 0xf
+	}
+	return nibbles
+}
+
+// ToBytes packs nibbles back into the bytes they were split from by FromBytes.
+// It fails on odd number of nibbles, on terminator and on values which aren't nibbles.
+func ToBytes(nibbles []byte) ([]byte, error) {
+	if len(nibbles)%2 != 0 {
+		return nil, errors.New("nibbles slice has an odd length")
+	}
+	key := make([]byte, len(nibbles)/2)
+	for i := range key {
+		hi, lo := nibbles[i*2], nibbles[i*2+1]
+		if hi > 0xf || lo > 0xf {
+			return nil, fmt.Errorf("invalid nibble at position %d or %d: 0x%X, 0x%X", i*2, i*2+1, hi, lo)
+		}
+		key[i] = hi<<4 | lo
+	}
+	return key, nil
+}
+
+// KeybytesToHex translates from KEYBYTES to HEX encoding, the result is terminated.
+func KeybytesToHex(str []byte) []byte {
+	l := len(str)*2 + 1
+	var nibbles = make([]byte, l)
+	for i, b := range str {
+		nibbles[i*2] = b / 16
+		nibbles[i*2+1] = b % 16
+	}
+	nibbles[l-1] = Terminator
+	return nibbles
+}
+
+// HexToKeybytes turns hex nibbles into key bytes.
+// This can only be used for keys of even length.
+func HexToKeybytes(hex []byte) []byte {
+	if HasTerm(hex) {
+		hex = hex[:len(hex)-1]
+	}
+	if len(hex)&1 != 0 {
+		panic("can't convert hex key of odd length")
+	}
+	key := make([]byte, len(hex)/2)
+	decodeNibbles(hex, key)
+	return key
+}
+
+// HexToCompact translates from HEX to COMPACT encoding. The result is never empty.
+func HexToCompact(hex []byte) []byte {
+	var flags byte
+	if HasTerm(hex) {
+		flags = compactTermFlag
+		hex = hex[:len(hex)-1]
+	}
+	buf := make([]byte, len(hex)/2+1)
+	buf[0] = flags
+	if len(hex)&1 == 1 {
+		buf[0] |= compactOddFlag
+		buf[0] |= hex[0] // first nibble is contained in the first byte
+		hex = hex[1:]
+	}
+	decodeNibbles(hex, buf[1:])
+	return buf
+}
+
+// CompactToHex translates from COMPACT to HEX encoding, the terminator is kept if the compact key has its flag set.
+func CompactToHex(compact []byte) []byte {
+	if len(compact) == 0 {
+		return compact
+	}
+	base := KeybytesToHex(compact)
+	// delete terminator flag
+	if base[0] < 2 {
+		base = base[:len(base)-1]
+	}
+	// apply odd flag
+	chop := 2 - base[0]&1
+	return base[chop:]
+}
+
+// HexToWitnessKey translates from HEX to the key encoding of the block witness. Its first byte
+// holds the oddness of the length in bit 0 and the terminator in bit 1, followed by the packed
+// nibbles with the odd one in the high half of the last byte. Keys shorter than two nibbles are
+// written as they are.
+func HexToWitnessKey(nibbles []byte) []byte {
+	if len(nibbles) < 1 {
+		return []byte{}
+	}
+	if len(nibbles) < 2 {
+		return nibbles
+	}
+	hasTerminator := false
+	if HasTerm(nibbles) {
+		nibbles = nibbles[:len(nibbles)-1]
+		hasTerminator = true
+	}
+
+	result := make([]byte, len(nibbles)/2+len(nibbles)%2+1)
+	result[0] = byte(len(nibbles) % 2) // parity bit
+	for i, ni := 1, 0; i < len(result); i++ {
+		result[i] = nibbles[ni] * 16
+		ni++
+		if ni < len(nibbles) {
+			result[i] += nibbles[ni]
+			ni++
+		}
+	}
+	if hasTerminator {
+		result[0] |= 1 << 1
+	}
+	return result
+}
+
+// WitnessKeyToHex translates from the key encoding of the block witness to HEX, see HexToWitnessKey.
+func WitnessKeyToHex(b []byte) []byte {
+	if len(b) < 1 {
+		return []byte{}
+	}
+	if len(b) < 2 {
+		return b
+	}
+
+	hasTerminator := b[0]&(1<<1) != 0
+	nibbles := make([]byte, (len(b)-1)*2-int(b[0]&1))
+	for i, ni := 1, 0; i < len(b); i++ {
+		nibbles[ni] = b[i] / 16
+		ni++
+		if ni < len(nibbles) {
+			nibbles[ni] = b[i] % 16
+			ni++
+		}
+	}
+	if hasTerminator {
+		return append(nibbles, Terminator)
+	}
+	return nibbles
+}
+
+func decodeNibbles(nibbles []byte, bytes []byte) {
+	if HasTerm(nibbles) {
+		nibbles = nibbles[:len(nibbles)-1]
+	}
+
+	nl := len(nibbles)
+	for bi, ni := 0, 0; ni < nl; bi, ni = bi+1, ni+2 {
+		if ni == nl-1 {
+			bytes[bi] = (bytes[bi] &^ 0xf0) | nibbles[ni]<<4
+		} else {
+			bytes[bi] = nibbles[ni]<<4 | nibbles[ni+1]
+		}
+	}
+}
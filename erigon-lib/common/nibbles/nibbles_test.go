@@ -0,0 +1,218 @@
+// Copyright 2014 The go-ethereum Authors
+// (original work)
+// Copyright 2024 The Erigon Authors
+// (modifications)
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package nibbles
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexCompact(t *testing.T) {
+	tests := []struct{ hex, compact []byte }{
+		// empty keys, with and without terminator.
+		{hex: []byte{}, compact: []byte{0x00}},
+		{hex: []byte{16}, compact: []byte{0x20}},
+		// odd length, no terminator
+		{hex: []byte{1, 2, 3, 4, 5}, compact: []byte{0x11, 0x23, 0x45}},
+		// even length, no terminator
+		{hex: []byte{0, 1, 2, 3, 4, 5}, compact: []byte{0x00, 0x01, 0x23, 0x45}},
+		// odd length, terminator
+		{hex: []byte{15, 1, 12, 11, 8, 16 /*term*/}, compact: []byte{0x3f, 0x1c, 0xb8}},
+		// even length, terminator
+		{hex: []byte{0, 15, 1, 12, 11, 8, 16 /*term*/}, compact: []byte{0x20, 0x0f, 0x1c, 0xb8}},
+	}
+	for _, test := range tests {
+		if c := HexToCompact(test.hex); !bytes.Equal(c, test.compact) {
+			t.Errorf("HexToCompact(%x) -> %x, want %x", test.hex, c, test.compact)
+		}
+		if h := CompactToHex(test.compact); !bytes.Equal(h, test.hex) {
+			t.Errorf("CompactToHex(%x) -> %x, want %x", test.compact, h, test.hex)
+		}
+	}
+}
+
+func TestHexKeybytes(t *testing.T) {
+	tests := []struct{ key, hexIn, hexOut []byte }{
+		{key: []byte{}, hexIn: []byte{16}, hexOut: []byte{16}},
+		{key: []byte{}, hexIn: []byte{}, hexOut: []byte{16}},
+		{
+			key:    []byte{0x12, 0x34, 0x56},
+			hexIn:  []byte{1, 2, 3, 4, 5, 6, 16},
+			hexOut: []byte{1, 2, 3, 4, 5, 6, 16},
+		},
+		{
+			key:    []byte{0x12, 0x34, 0x5},
+			hexIn:  []byte{1, 2, 3, 4, 0, 5, 16},
+			hexOut: []byte{1, 2, 3, 4, 0, 5, 16},
+		},
+		{
+			key:    []byte{0x12, 0x34, 0x56},
+			hexIn:  []byte{1, 2, 3, 4, 5, 6},
+			hexOut: []byte{1, 2, 3, 4, 5, 6, 16},
+		},
+	}
+	for _, test := range tests {
+		if h := KeybytesToHex(test.key); !bytes.Equal(h, test.hexOut) {
+			t.Errorf("KeybytesToHex(%x) -> %x, want %x", test.key, h, test.hexOut)
+		}
+		if k := HexToKeybytes(test.hexIn); !bytes.Equal(k, test.key) {
+			t.Errorf("HexToKeybytes(%x) -> %x, want %x", test.hexIn, k, test.key)
+		}
+	}
+	require.Panics(t, func() { HexToKeybytes([]byte{1, 2, 3}) })
+}
+
+func TestBytes(t *testing.T) {
+	require.Equal(t, []byte{0, 0, 1, 0xf, 0xa, 0x5}, FromBytes([]byte{0x00, 0x1f, 0xa5}))
+	require.Empty(t, FromBytes(nil))
+
+	key, err := ToBytes([]byte{0, 0, 1, 0xf, 0xa, 0x5})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x00, 0x1f, 0xa5}, key)
+
+	_, err = ToBytes([]byte{1, 2, 3})
+	require.Error(t, err)
+	_, err = ToBytes([]byte{1, 2, 3, 16})
+	require.Error(t, err)
+}
+
+func TestWitnessKey(t *testing.T) {
+	for _, key := range [][]byte{
+		{1, 16},
+		{1, 2, 3, 4, 5},
+		{1, 2, 3, 4, 5, 6},
+		{},
+		{3, 9, 1},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 15, 15, 15, 15, 15},
+		{1},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 15, 15, 15, 15, 16},
+	} {
+		if h := WitnessKeyToHex(HexToWitnessKey(key)); !bytes.Equal(key, h) {
+			t.Errorf("wrong deserialization, expected %x got %x", key, h)
+		}
+	}
+	require.Equal(t, []byte{0b11, 0x12, 0x30}, HexToWitnessKey([]byte{1, 2, 3, 16}))
+	require.Equal(t, []byte{0b00, 0x12}, HexToWitnessKey([]byte{1, 2}))
+}
+
+// hexFromFuzz turns arbitrary fuzzer input into a valid HEX key
+func hexFromFuzz(data []byte, term bool) []byte {
+	hex := make([]byte, len(data), len(data)+1)
+	for i, b := range data {
+		hex[i] = b & 0xf
+	}
+	if term {
+		hex = append(hex, Terminator)
+	}
+	return hex
+}
+
+func TestRoundTripExhaustive(t *testing.T) {
+	// every HEX key of up to 4 nibbles, with and without terminator
+	for l := 0; l <= 4; l++ {
+		total := 1 << (4 * l)
+		for v := 0; v < total; v++ {
+			data := make([]byte, l)
+			for i := range data {
+				data[i] = byte(v >> (4 * i))
+			}
+			for _, term := range []bool{false, true} {
+				hex := hexFromFuzz(data, term)
+				if h := CompactToHex(HexToCompact(hex)); !bytes.Equal(h, hex) {
+					t.Fatalf("compact round trip of %x -> %x", hex, h)
+				}
+				if h := WitnessKeyToHex(HexToWitnessKey(hex)); !bytes.Equal(h, hex) {
+					t.Fatalf("witness round trip of %x -> %x", hex, h)
+				}
+			}
+		}
+	}
+	for v := 0; v < 1<<16; v++ {
+		key := []byte{byte(v >> 8), byte(v)}
+		if k := HexToKeybytes(KeybytesToHex(key)); !bytes.Equal(k, key) {
+			t.Fatalf("keybytes round trip of %x -> %x", key, k)
+		}
+		if k, err := ToBytes(FromBytes(key)); err != nil || !bytes.Equal(k, key) {
+			t.Fatalf("bytes round trip of %x -> %x (%v)", key, k, err)
+		}
+	}
+}
+
+func FuzzHexCompact(f *testing.F) {
+	f.Add([]byte{}, false)
+	f.Add([]byte{1, 2, 3, 4, 5}, true)
+	f.Add([]byte{0, 15, 1, 12, 11, 8}, true)
+	f.Fuzz(func(t *testing.T, data []byte, term bool) {
+		hex := hexFromFuzz(data, term)
+		compact := HexToCompact(hex)
+		require.Equal(t, hex, CompactToHex(compact))
+		require.Equal(t, term, compact[0]&compactTermFlag != 0)
+		require.Equal(t, len(data)%2 == 1, compact[0]&compactOddFlag != 0)
+		require.Equal(t, hex, WitnessKeyToHex(HexToWitnessKey(hex)))
+	})
+}
+
+func FuzzKeybytes(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x12, 0x34, 0x56})
+	f.Fuzz(func(t *testing.T, key []byte) {
+		hex := KeybytesToHex(key)
+		require.True(t, HasTerm(hex))
+		require.Equal(t, key, HexToKeybytes(hex))
+		require.Equal(t, hex[:len(hex)-1], FromBytes(key))
+
+		back, err := ToBytes(FromBytes(key))
+		require.NoError(t, err)
+		require.Equal(t, key, back)
+
+		// KEYBYTES is the even, terminated case of COMPACT with the flag byte stripped
+		require.Equal(t, key, HexToCompact(hex)[1:])
+	})
+}
+
+func BenchmarkHexToCompact(b *testing.B) {
+	testBytes := []byte{0, 15, 1, 12, 11, 8, 16 /*term*/}
+	for i := 0; i < b.N; i++ {
+		HexToCompact(testBytes)
+	}
+}
+
+func BenchmarkCompactToHex(b *testing.B) {
+	testBytes := []byte{0, 15, 1, 12, 11, 8, 16 /*term*/}
+	for i := 0; i < b.N; i++ {
+		CompactToHex(testBytes)
+	}
+}
+
+func BenchmarkKeybytesToHex(b *testing.B) {
+	testBytes := []byte{7, 6, 6, 5, 7, 2, 6, 2, 16}
+	for i := 0; i < b.N; i++ {
+		KeybytesToHex(testBytes)
+	}
+}
+
+func BenchmarkHexToKeybytes(b *testing.B) {
+	testBytes := []byte{7, 6, 6, 5, 7, 2, 6, 2, 16}
+	for i := 0; i < b.N; i++ {
+		HexToKeybytes(testBytes)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const badBlocksLimit = 100 // number of the latest quarantined bad blocks kept in memory
+
+func (d *DiagnosticClient) setupBadBlocksDiagnostics(rootCtx context.Context) {
+	d.runBadBlocksListener(rootCtx)
+}
+
+func (d *DiagnosticClient) runBadBlocksListener(rootCtx context.Context) {
+	go func() {
+		ctx, ch, closeChannel := Context[BadBlockQuarantined](rootCtx, 1)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(BadBlockQuarantined{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.badBlocksMutex.Lock()
+				d.badBlocks = append(d.badBlocks, info)
+				if len(d.badBlocks) > badBlocksLimit {
+					d.badBlocks = d.badBlocks[len(d.badBlocks)-badBlocksLimit:]
+				}
+				d.badBlocksMutex.Unlock()
+			}
+		}
+	}()
+}
+
+func (d *DiagnosticClient) BadBlocksJson(w io.Writer) {
+	d.badBlocksMutex.Lock()
+	defer d.badBlocksMutex.Unlock()
+	if err := json.NewEncoder(w).Encode(d.badBlocks); err != nil {
+		log.Debug("[diagnostics] BadBlocksJson", "err", err)
+	}
+}
+
+// BadBlockArtifactPath returns the path of an artifact file of a quarantined bad block, only the files listed in its
+// quarantine event are served
+func (d *DiagnosticClient) BadBlockArtifactPath(hash, file string) (string, error) {
+	d.badBlocksMutex.Lock()
+	defer d.badBlocksMutex.Unlock()
+	for i := len(d.badBlocks) - 1; i >= 0; i-- {
+		badBlock := d.badBlocks[i]
+		if badBlock.Hash != hash {
+			continue
+		}
+		if !slices.Contains(badBlock.Files, file) {
+			return "", fmt.Errorf("artifact %s not found for bad block %s", file, hash)
+		}
+		return filepath.Join(badBlock.Dir, file), nil
+	}
+	return "", fmt.Errorf("bad block %s not found", hash)
+}
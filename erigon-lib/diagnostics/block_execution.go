@@ -73,6 +73,7 @@ func (d *DiagnosticClient) runBlockExecutionListener(rootCtx context.Context) {
 				return
 			case info := <-ch:
 				d.BlockExecution.SetData(info)
+				d.flightRecorder.Progress()
 				if d.syncStats.SyncFinished {
 					return
 				}
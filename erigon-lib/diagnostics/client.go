@@ -55,6 +55,15 @@ type DiagnosticClient struct {
 	networkSpeed        NetworkSpeedTestResult
 	networkSpeedMutex   sync.Mutex
 	webseedsList        []string
+	deepReorgs          []DeepReorgAlert
+	deepReorgsMutex     sync.Mutex
+	heimdallFailures    []HeimdallFetchFailure
+	heimdallMutex       sync.Mutex
+	badBlocks           []BadBlockQuarantined
+	badBlocksMutex      sync.Mutex
+	syncProgress        SyncProgress
+	syncProgressMutex   sync.Mutex
+	flightRecorder      *FlightRecorder
 }
 
 func NewDiagnosticClient(ctx context.Context, metricsMux *http.ServeMux, dataDirPath string, speedTest bool, webseedsList []string) (*DiagnosticClient, error) {
@@ -111,6 +120,7 @@ func (d *DiagnosticClient) Setup() {
 
 	d.setupSnapshotDiagnostics(rootCtx)
 	d.setupStagesDiagnostics(rootCtx)
+	d.setupStagesProgressDiagnostics(rootCtx)
 	d.setupSysInfoDiagnostics()
 	d.setupNetworkDiagnostics(rootCtx)
 	d.setupBlockExecutionDiagnostics(rootCtx)
@@ -118,6 +128,10 @@ func (d *DiagnosticClient) Setup() {
 	d.setupBodiesDiagnostics(rootCtx)
 	d.setupResourcesUsageDiagnostics(rootCtx)
 	d.setupSpeedtestDiagnostics(rootCtx)
+	d.setupReorgsDiagnostics(rootCtx)
+	d.setupHeimdallDiagnostics(rootCtx)
+	d.setupBadBlocksDiagnostics(rootCtx)
+	d.setupFlightRecorderDiagnostics(rootCtx)
 	d.runSaveProcess(rootCtx)
 
 	//d.logDiagMsgs()
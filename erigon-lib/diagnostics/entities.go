@@ -272,6 +272,37 @@ type MemoryStats struct {
 	StageIndex  CurrentSyncStagesIdxs `json:"stageIndex"`
 }
 
+// DeepReorgAlert is sent when the chain is asked to unwind behind a point which is considered final,
+// e.g. the end of the latest verified milestone on Polygon.
+type DeepReorgAlert struct {
+	Timestamp    time.Time `json:"timestamp"`
+	UnwindPoint  uint64    `json:"unwindPoint"`
+	FinalizedNum uint64    `json:"finalizedNum"`
+	WaypointId   uint64    `json:"waypointId"`
+	Allowed      bool      `json:"allowed"`
+}
+
+// HeimdallFetchFailure is sent when an attempt of a request to heimdall fails, including the retried ones.
+type HeimdallFetchFailure struct {
+	Timestamp time.Time `json:"timestamp"`
+	Endpoint  string    `json:"endpoint"`
+	Class     string    `json:"class"`
+	Attempt   int       `json:"attempt"`
+	Error     string    `json:"error"`
+}
+
+// BadBlockQuarantined is sent when the execution finds a bad block and captures its artifacts into the quarantine
+// directory.
+type BadBlockQuarantined struct {
+	Timestamp time.Time `json:"timestamp"`
+	Number    uint64    `json:"number"`
+	Hash      string    `json:"hash"`
+	Reason    string    `json:"reason"`
+	Error     string    `json:"error"`
+	Dir       string    `json:"dir"`
+	Files     []string  `json:"files"`
+}
+
 type NetworkSpeedTestResult struct {
 	Latency       time.Duration `json:"latency"`
 	DownloadSpeed float64       `json:"downloadSpeed"`
@@ -346,3 +377,15 @@ func (ti HeadersProcessedUpdate) Type() Type {
 func (ti SnapshotFillDBStageUpdate) Type() Type {
 	return TypeOf(ti)
 }
+
+func (ti DeepReorgAlert) Type() Type {
+	return TypeOf(ti)
+}
+
+func (ti HeimdallFetchFailure) Type() Type {
+	return TypeOf(ti)
+}
+
+func (ti BadBlockQuarantined) Type() Type {
+	return TypeOf(ti)
+}
@@ -0,0 +1,373 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/app"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/mdbx"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+type FlightRecorderConfig struct {
+	Window       time.Duration // how much of the recent history is kept in memory
+	Segment      time.Duration // length of a single capture, the recorder keeps Window/Segment of them
+	StallTimeout time.Duration // a stage running without progress or a RW tx held for longer is a stall
+	MaxDumps     int           // oldest dumps are dropped
+}
+
+var DefaultFlightRecorderConfig = FlightRecorderConfig{
+	Window:       time.Minute,
+	Segment:      10 * time.Second,
+	StallTimeout: 5 * time.Minute,
+	MaxDumps:     4,
+}
+
+const (
+	ProfileTrace     = "trace"
+	ProfileGoroutine = "goroutine"
+	ProfileHeap      = "heap"
+	ProfileBlock     = "block"
+
+	// sample on average one blocking event per millisecond spent blocked
+	flightRecorderBlockProfileRate = int(time.Millisecond)
+)
+
+var flightRecorderProfiles = []string{ProfileGoroutine, ProfileHeap, ProfileBlock}
+
+type flightRecorderSegment struct {
+	start    time.Time
+	end      time.Time
+	profiles map[string][]byte
+}
+
+type FlightRecorderSegmentInfo struct {
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Profiles map[string]int `json:"profiles"` // profile name -> size in bytes
+}
+
+type FlightRecorderDump struct {
+	ID       int                         `json:"id"`
+	Time     time.Time                   `json:"time"`
+	Reason   string                      `json:"reason"`
+	Segments []FlightRecorderSegmentInfo `json:"segments"`
+
+	segments []flightRecorderSegment
+}
+
+// FlightRecorder continuously keeps the last Window of runtime traces and goroutine, heap and block profiles in
+// memory and freezes them into a dump when a stall is detected or a capture is requested.
+type FlightRecorder struct {
+	cfg      FlightRecorderConfig
+	rwTxHeld func() (kv.Label, time.Duration)
+
+	mu           sync.Mutex
+	ring         []flightRecorderSegment
+	dumps        []FlightRecorderDump
+	nextDumpID   int
+	stage        string
+	lastProgress time.Time
+	stageStalled bool
+	rwTxStalled  bool
+
+	captureCh chan string
+}
+
+func NewFlightRecorder(cfg FlightRecorderConfig) *FlightRecorder {
+	return &FlightRecorder{
+		cfg:       cfg,
+		rwTxHeld:  mdbx.LongestRwTx,
+		captureCh: make(chan string, 1),
+	}
+}
+
+func (r *FlightRecorder) ringSize() int {
+	if r.cfg.Segment <= 0 || r.cfg.Window < r.cfg.Segment {
+		return 1
+	}
+	return int(r.cfg.Window / r.cfg.Segment)
+}
+
+func (r *FlightRecorder) checkInterval() time.Duration {
+	return min(time.Second, r.cfg.StallTimeout/4)
+}
+
+// StageStarted records a stage starting to run, a stage which runs without any progress for longer than the stall
+// timeout is stalled
+func (r *FlightRecorder) StageStarted(stage string) {
+	r.setStage(stage)
+}
+
+// StageStopped records the running stage finishing or failing
+func (r *FlightRecorder) StageStopped() {
+	r.setStage("")
+}
+
+func (r *FlightRecorder) setStage(stage string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage = stage
+	r.lastProgress = time.Now()
+	r.stageStalled = false
+}
+
+// Progress records that the running stage made progress
+func (r *FlightRecorder) Progress() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastProgress = time.Now()
+	r.stageStalled = false
+}
+
+// Capture requests a dump of the recorded history, it's taken once the current segment is closed
+func (r *FlightRecorder) Capture(reason string) {
+	select {
+	case r.captureCh <- reason:
+	default:
+		// a capture is already pending
+	}
+}
+
+func (r *FlightRecorder) Run(ctx context.Context) {
+	runtime.SetBlockProfileRate(flightRecorderBlockProfileRate)
+	defer runtime.SetBlockProfileRate(0)
+
+	check := time.NewTicker(r.checkInterval())
+	defer check.Stop()
+
+	for {
+		seg := flightRecorderSegment{start: time.Now(), profiles: map[string][]byte{}}
+		var traceBuf bytes.Buffer
+		// fails if the trace is already being collected by someone else, e.g. through the pprof endpoint
+		tracing := trace.Start(&traceBuf) == nil
+
+		reason := r.waitSegment(ctx, check)
+
+		if tracing {
+			trace.Stop()
+			seg.profiles[ProfileTrace] = traceBuf.Bytes()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		seg.end = time.Now()
+		for _, name := range flightRecorderProfiles {
+			var buf bytes.Buffer
+			if err := pprof.Lookup(name).WriteTo(&buf, 0); err != nil {
+				log.Debug("[diagnostics] flight recorder", "profile", name, "err", err)
+				continue
+			}
+			seg.profiles[name] = buf.Bytes()
+		}
+
+		r.push(seg)
+		if reason != "" {
+			r.dump(reason)
+		}
+	}
+}
+
+// waitSegment waits for the end of the current segment and returns the reason to dump the history if a stall is
+// detected or a capture is requested in the meantime
+func (r *FlightRecorder) waitSegment(ctx context.Context, check *time.Ticker) string {
+	timer := time.NewTimer(r.cfg.Segment)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-timer.C:
+			return ""
+		case reason := <-r.captureCh:
+			return reason
+		case now := <-check.C:
+			if reason := r.checkStall(now); reason != "" {
+				return reason
+			}
+		}
+	}
+}
+
+// checkStall returns the reason of a stall, only once per stall
+func (r *FlightRecorder) checkStall(now time.Time) string {
+	label, held := r.rwTxHeld()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reasons []string
+	if r.stage != "" && now.Sub(r.lastProgress) > r.cfg.StallTimeout {
+		if !r.stageStalled {
+			r.stageStalled = true
+			reasons = append(reasons, fmt.Sprintf("stage %s not progressing for %s", r.stage, now.Sub(r.lastProgress).Round(time.Second)))
+		}
+	} else {
+		r.stageStalled = false
+	}
+	if held > r.cfg.StallTimeout {
+		if !r.rwTxStalled {
+			r.rwTxStalled = true
+			reasons = append(reasons, fmt.Sprintf("%s RW tx held for %s", label, held.Round(time.Second)))
+		}
+	} else {
+		r.rwTxStalled = false
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (r *FlightRecorder) push(seg flightRecorderSegment) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ring = append(r.ring, seg)
+	if size := r.ringSize(); len(r.ring) > size {
+		r.ring = append(r.ring[:0:0], r.ring[len(r.ring)-size:]...)
+	}
+}
+
+func (r *FlightRecorder) dump(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dump := FlightRecorderDump{
+		ID:       r.nextDumpID,
+		Time:     time.Now(),
+		Reason:   reason,
+		segments: append([]flightRecorderSegment(nil), r.ring...),
+	}
+	r.nextDumpID++
+	for _, seg := range dump.segments {
+		info := FlightRecorderSegmentInfo{Start: seg.start, End: seg.end, Profiles: map[string]int{}}
+		for name, data := range seg.profiles {
+			info.Profiles[name] = len(data)
+		}
+		dump.Segments = append(dump.Segments, info)
+	}
+
+	r.dumps = append(r.dumps, dump)
+	if len(r.dumps) > r.cfg.MaxDumps {
+		r.dumps = append(r.dumps[:0:0], r.dumps[len(r.dumps)-r.cfg.MaxDumps:]...)
+	}
+	log.Warn("[diagnostics] flight recorder dump", "id", dump.ID, "reason", reason, "segments", len(dump.segments))
+}
+
+func (r *FlightRecorder) Dumps() []FlightRecorderDump {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]FlightRecorderDump(nil), r.dumps...)
+}
+
+// Profile returns the profile of the given segment of the dump, segments are ordered from the oldest one
+func (r *FlightRecorder) Profile(dumpID, segment int, name string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, dump := range r.dumps {
+		if dump.ID != dumpID {
+			continue
+		}
+		if segment < 0 || segment >= len(dump.segments) {
+			return nil, fmt.Errorf("dump %d has %d segments", dumpID, len(dump.segments))
+		}
+		data, ok := dump.segments[segment].profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("profile %s not found in segment %d of dump %d", name, segment, dumpID)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("dump %d not found", dumpID)
+}
+
+var ErrFlightRecorderDisabled = errors.New("flight recorder is disabled")
+
+// EnableFlightRecorder turns on the flight recorder mode, must be called before Setup
+func (d *DiagnosticClient) EnableFlightRecorder(cfg FlightRecorderConfig) {
+	d.flightRecorder = NewFlightRecorder(cfg)
+}
+
+func (d *DiagnosticClient) setupFlightRecorderDiagnostics(rootCtx context.Context) {
+	if d.flightRecorder == nil {
+		return
+	}
+	go d.flightRecorder.Run(rootCtx)
+	d.runStageEventsListener(rootCtx)
+}
+
+func (d *DiagnosticClient) runStageEventsListener(rootCtx context.Context) {
+	go func() {
+		// one channel: a stage stopping must not be seen before it started
+		events, unsubscribe := app.Subscribe[app.StageEvent](app.DefaultEventBus(), 16)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case e := <-events:
+				switch e := e.(type) {
+				case app.StageStarted:
+					d.flightRecorder.StageStarted(e.Stage)
+				case app.StageFinished, app.StageUnwound:
+					d.flightRecorder.StageStopped()
+				}
+			}
+		}
+	}()
+}
+
+func (d *DiagnosticClient) FlightRecorderDumpsJson(w io.Writer) {
+	var dumps []FlightRecorderDump
+	if d.flightRecorder != nil {
+		dumps = d.flightRecorder.Dumps()
+	}
+	if err := json.NewEncoder(w).Encode(dumps); err != nil {
+		log.Debug("[diagnostics] FlightRecorderDumpsJson", "err", err)
+	}
+}
+
+func (d *DiagnosticClient) FlightRecorderProfile(dumpID, segment int, name string) ([]byte, error) {
+	if d.flightRecorder == nil {
+		return nil, ErrFlightRecorderDisabled
+	}
+	return d.flightRecorder.Profile(dumpID, segment, name)
+}
+
+func (d *DiagnosticClient) FlightRecorderCapture(reason string) error {
+	if d.flightRecorder == nil {
+		return ErrFlightRecorderDisabled
+	}
+	d.flightRecorder.Capture(reason)
+	return nil
+}
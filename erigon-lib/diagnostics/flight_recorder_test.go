@@ -0,0 +1,101 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/diagnostics"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+)
+
+var flightRecorderTestConfig = diagnostics.FlightRecorderConfig{
+	Window:       100 * time.Millisecond,
+	Segment:      50 * time.Millisecond,
+	StallTimeout: 200 * time.Millisecond,
+	MaxDumps:     2,
+}
+
+func waitDumps(t *testing.T, r *diagnostics.FlightRecorder, n int) []diagnostics.FlightRecorderDump {
+	t.Helper()
+	require.Eventually(t, func() bool { return len(r.Dumps()) >= n }, 10*time.Second, 10*time.Millisecond)
+	return r.Dumps()
+}
+
+func TestFlightRecorderStageStall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := diagnostics.NewFlightRecorder(flightRecorderTestConfig)
+	go r.Run(ctx)
+
+	r.StageStarted("Execution")
+	dumps := waitDumps(t, r, 1)
+	require.Contains(t, dumps[0].Reason, "stage Execution not progressing")
+	require.NotEmpty(t, dumps[0].Segments)
+	require.LessOrEqual(t, len(dumps[0].Segments), 2)
+
+	goroutines, err := r.Profile(dumps[0].ID, 0, diagnostics.ProfileGoroutine)
+	require.NoError(t, err)
+	require.NotEmpty(t, goroutines)
+	_, err = r.Profile(dumps[0].ID, 0, "unknown")
+	require.Error(t, err)
+	_, err = r.Profile(dumps[0].ID+1, 0, diagnostics.ProfileGoroutine)
+	require.Error(t, err)
+
+	// a stall is dumped once, a finished stage can't stall
+	r.StageStopped()
+	time.Sleep(3 * flightRecorderTestConfig.StallTimeout)
+	require.Len(t, r.Dumps(), 1)
+}
+
+func TestFlightRecorderRwTxStall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := diagnostics.NewFlightRecorder(flightRecorderTestConfig)
+	go r.Run(ctx)
+
+	db := memdb.NewTestDB(t, kv.ChainDB)
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	dumps := waitDumps(t, r, 1)
+	require.Contains(t, dumps[0].Reason, "RW tx held for")
+}
+
+func TestFlightRecorderCapture(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := diagnostics.NewFlightRecorder(flightRecorderTestConfig)
+	go r.Run(ctx)
+
+	for i := 0; i < 3; i++ {
+		r.Capture("requested")
+		waitDumps(t, r, min(i+1, flightRecorderTestConfig.MaxDumps))
+		time.Sleep(flightRecorderTestConfig.Segment)
+	}
+	// the oldest dumps are dropped
+	require.Eventually(t, func() bool {
+		dumps := r.Dumps()
+		return len(dumps) == 2 && dumps[0].ID > 0
+	}, 10*time.Second, 10*time.Millisecond)
+}
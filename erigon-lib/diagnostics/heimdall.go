@@ -0,0 +1,65 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const heimdallFailuresLimit = 100 // number of the latest failed heimdall requests kept in memory
+
+func (d *DiagnosticClient) setupHeimdallDiagnostics(rootCtx context.Context) {
+	d.runHeimdallFetchFailureListener(rootCtx)
+}
+
+func (d *DiagnosticClient) runHeimdallFetchFailureListener(rootCtx context.Context) {
+	go func() {
+		ctx, ch, closeChannel := Context[HeimdallFetchFailure](rootCtx, 16)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(HeimdallFetchFailure{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.AddHeimdallFetchFailure(info)
+			}
+		}
+	}()
+}
+
+func (d *DiagnosticClient) AddHeimdallFetchFailure(info HeimdallFetchFailure) {
+	d.heimdallMutex.Lock()
+	defer d.heimdallMutex.Unlock()
+	d.heimdallFailures = append(d.heimdallFailures, info)
+	if len(d.heimdallFailures) > heimdallFailuresLimit {
+		d.heimdallFailures = d.heimdallFailures[len(d.heimdallFailures)-heimdallFailuresLimit:]
+	}
+}
+
+func (d *DiagnosticClient) HeimdallFetchFailuresJson(w io.Writer) {
+	d.heimdallMutex.Lock()
+	defer d.heimdallMutex.Unlock()
+	if err := json.NewEncoder(w).Encode(d.heimdallFailures); err != nil {
+		log.Debug("[diagnostics] HeimdallFetchFailuresJson", "err", err)
+	}
+}
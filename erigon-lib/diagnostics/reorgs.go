@@ -0,0 +1,61 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const deepReorgsLimit = 100 // number of the latest deep reorg alerts kept in memory
+
+func (d *DiagnosticClient) setupReorgsDiagnostics(rootCtx context.Context) {
+	d.runDeepReorgListener(rootCtx)
+}
+
+func (d *DiagnosticClient) runDeepReorgListener(rootCtx context.Context) {
+	go func() {
+		ctx, ch, closeChannel := Context[DeepReorgAlert](rootCtx, 1)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(DeepReorgAlert{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.deepReorgsMutex.Lock()
+				d.deepReorgs = append(d.deepReorgs, info)
+				if len(d.deepReorgs) > deepReorgsLimit {
+					d.deepReorgs = d.deepReorgs[len(d.deepReorgs)-deepReorgsLimit:]
+				}
+				d.deepReorgsMutex.Unlock()
+			}
+		}
+	}()
+}
+
+func (d *DiagnosticClient) DeepReorgsJson(w io.Writer) {
+	d.deepReorgsMutex.Lock()
+	defer d.deepReorgsMutex.Unlock()
+	if err := json.NewEncoder(w).Encode(d.deepReorgs); err != nil {
+		log.Debug("[diagnostics] DeepReorgsJson", "err", err)
+	}
+}
@@ -34,6 +34,7 @@ func (d *DiagnosticClient) runSnapshotListener(rootCtx context.Context) {
 				return
 			case info := <-ch:
 				d.SetSnapshotDownloadInfo(info)
+				d.flightRecorder.Progress()
 				d.UpdateSnapshotStageStats(CalculateSyncStageStats(info), "Downloading snapshots")
 
 				if info.DownloadFinished {
@@ -154,6 +154,7 @@ func (d *DiagnosticClient) runCurrentSyncSubStageListener(rootCtx context.Contex
 				return
 			case info := <-ch:
 				d.SetCurrentSyncSubStage(info)
+				d.flightRecorder.Progress()
 			}
 		}
 	}()
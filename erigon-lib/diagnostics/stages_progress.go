@@ -0,0 +1,177 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+// throughputWindow - the time constant of the exponentially weighted moving averages of the stages throughput: older
+// updates weigh less than e^-1 after it
+const throughputWindow = time.Minute
+
+// SyncStageProgressUpdate - progress of a stage, sent when the stage starts to run and while it runs. Txs and Bytes are
+// counters which only grow within a run of the stage: the throughput is computed from their deltas.
+type SyncStageProgressUpdate struct {
+	Stage  string
+	Time   time.Time
+	Block  uint64 // the block the stage is at
+	Target uint64 // the block the stage runs to, 0 - not known yet
+	Txs    uint64
+	Bytes  uint64
+	Start  bool // the first update of a run of the stage
+}
+
+func (ti SyncStageProgressUpdate) Type() Type {
+	return TypeOf(ti)
+}
+
+// SyncStageThroughput - progress of a stage and its throughput, averaged over its recent progress updates
+type SyncStageThroughput struct {
+	Stage        string    `json:"stage"`
+	Block        uint64    `json:"block"`
+	Target       uint64    `json:"target"`
+	Progress     string    `json:"progress"`
+	BlocksPerSec float64   `json:"blocksPerSec"`
+	TxsPerSec    float64   `json:"txsPerSec"`
+	BytesPerSec  float64   `json:"bytesPerSec"`
+	ETA          string    `json:"eta"`
+	ETASeconds   uint64    `json:"etaSeconds"`
+	Updated      time.Time `json:"updated"`
+
+	prev SyncStageProgressUpdate // the previous update of the run, zero if there was none
+}
+
+type SyncProgress struct {
+	CurrentStage string                `json:"currentStage"`
+	Stages       []SyncStageThroughput `json:"stages"`
+}
+
+func (d *DiagnosticClient) setupStagesProgressDiagnostics(rootCtx context.Context) {
+	d.runStagesProgressListener(rootCtx)
+}
+
+func (d *DiagnosticClient) runStagesProgressListener(rootCtx context.Context) {
+	go func() {
+		// a stage run sends its first and last updates back to back at the chain tip
+		ctx, ch, closeChannel := Context[SyncStageProgressUpdate](rootCtx, 16)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(SyncStageProgressUpdate{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.UpdateStageProgress(info)
+			}
+		}
+	}()
+}
+
+func (d *DiagnosticClient) UpdateStageProgress(u SyncStageProgressUpdate) {
+	d.syncProgressMutex.Lock()
+	defer d.syncProgressMutex.Unlock()
+
+	idx := -1
+	for i := range d.syncProgress.Stages {
+		if d.syncProgress.Stages[i].Stage == u.Stage {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		d.syncProgress.Stages = append(d.syncProgress.Stages, SyncStageThroughput{Stage: u.Stage})
+		idx = len(d.syncProgress.Stages) - 1
+	}
+	d.syncProgress.CurrentStage = u.Stage
+
+	stage := &d.syncProgress.Stages[idx]
+	stage.update(u)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range d.syncStages {
+		if d.syncStages[i].ID == u.Stage {
+			d.syncStages[i].Stats.Progress = stage.Progress
+			d.syncStages[i].Stats.TimeLeft = stage.ETA
+		}
+	}
+}
+
+func (t *SyncStageThroughput) update(u SyncStageProgressUpdate) {
+	// the counters restart with every run of the stage and the block goes back on unwinds
+	if prev := t.prev; !u.Start && !prev.Time.IsZero() && u.Time.After(prev.Time) && u.Block >= prev.Block {
+		dt := u.Time.Sub(prev.Time).Seconds()
+		alpha := 1 - math.Exp(-dt/throughputWindow.Seconds())
+		if t.Updated.IsZero() || (t.BlocksPerSec == 0 && t.TxsPerSec == 0 && t.BytesPerSec == 0) {
+			alpha = 1
+		}
+		t.BlocksPerSec = ewma(t.BlocksPerSec, float64(u.Block-prev.Block)/dt, alpha)
+		if u.Txs >= prev.Txs {
+			t.TxsPerSec = ewma(t.TxsPerSec, float64(u.Txs-prev.Txs)/dt, alpha)
+		}
+		if u.Bytes >= prev.Bytes {
+			t.BytesPerSec = ewma(t.BytesPerSec, float64(u.Bytes-prev.Bytes)/dt, alpha)
+		}
+		t.Updated = u.Time
+	}
+	t.prev = u
+
+	t.Block = u.Block
+	if u.Target > 0 {
+		t.Target = u.Target
+	}
+	t.Progress, t.ETA, t.ETASeconds = "", "", 0
+	if t.Target == 0 {
+		return
+	}
+	if t.Block >= t.Target {
+		t.Progress, t.ETA = "100%", "0s"
+		return
+	}
+	t.Progress = fmt.Sprintf("%d%%", t.Block*100/t.Target)
+	if t.BlocksPerSec > 0 {
+		t.ETASeconds = uint64(float64(t.Target-t.Block) / t.BlocksPerSec)
+		t.ETA = (time.Duration(t.ETASeconds) * time.Second).String()
+	}
+}
+
+func ewma(avg, sample, alpha float64) float64 {
+	return alpha*sample + (1-alpha)*avg
+}
+
+func (d *DiagnosticClient) SyncProgress() SyncProgress {
+	d.syncProgressMutex.Lock()
+	defer d.syncProgressMutex.Unlock()
+	p := SyncProgress{CurrentStage: d.syncProgress.CurrentStage, Stages: make([]SyncStageThroughput, len(d.syncProgress.Stages))}
+	copy(p.Stages, d.syncProgress.Stages)
+	return p
+}
+
+func (d *DiagnosticClient) SyncProgressJson(w io.Writer) {
+	if err := json.NewEncoder(w).Encode(d.SyncProgress()); err != nil {
+		log.Debug("[diagnostics] SyncProgressJson", "err", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erigontech/erigon-lib/diagnostics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateStageProgress(t *testing.T) {
+	d, err := NewTestDiagnosticClient()
+	require.NoError(t, err)
+
+	start := time.Unix(1700000000, 0)
+	d.UpdateStageProgress(diagnostics.SyncStageProgressUpdate{Stage: "Execution", Time: start, Block: 1000, Target: 3000, Start: true})
+
+	p := d.SyncProgress()
+	require.Equal(t, "Execution", p.CurrentStage)
+	require.Len(t, p.Stages, 1)
+	require.Equal(t, "33%", p.Stages[0].Progress)
+	require.Equal(t, "", p.Stages[0].ETA)
+
+	// the first sample sets the rates
+	d.UpdateStageProgress(diagnostics.SyncStageProgressUpdate{Stage: "Execution", Time: start.Add(10 * time.Second), Block: 2000, Txs: 50_000})
+	p = d.SyncProgress()
+	require.Equal(t, 100.0, p.Stages[0].BlocksPerSec)
+	require.Equal(t, 5000.0, p.Stages[0].TxsPerSec)
+	require.Equal(t, uint64(3000), p.Stages[0].Target)
+	require.Equal(t, "66%", p.Stages[0].Progress)
+	require.Equal(t, uint64(10), p.Stages[0].ETASeconds)
+	require.Equal(t, "10s", p.Stages[0].ETA)
+
+	// the next ones are averaged
+	d.UpdateStageProgress(diagnostics.SyncStageProgressUpdate{Stage: "Execution", Time: start.Add(20 * time.Second), Block: 2500, Txs: 60_000})
+	p = d.SyncProgress()
+	require.Greater(t, p.Stages[0].BlocksPerSec, 50.0)
+	require.Less(t, p.Stages[0].BlocksPerSec, 100.0)
+
+	// an unwind is not a sample
+	rate := p.Stages[0].BlocksPerSec
+	d.UpdateStageProgress(diagnostics.SyncStageProgressUpdate{Stage: "Execution", Time: start.Add(30 * time.Second), Block: 2400, Txs: 61_000})
+	p = d.SyncProgress()
+	require.Equal(t, rate, p.Stages[0].BlocksPerSec)
+	require.Equal(t, uint64(2400), p.Stages[0].Block)
+
+	// the counters of a new run are not compared with the previous run ones
+	d.UpdateStageProgress(diagnostics.SyncStageProgressUpdate{Stage: "Execution", Time: start.Add(40 * time.Second), Block: 3000, Target: 3000, Start: true})
+	p = d.SyncProgress()
+	require.Equal(t, rate, p.Stages[0].BlocksPerSec)
+	require.Equal(t, "100%", p.Stages[0].Progress)
+	require.Equal(t, "0s", p.Stages[0].ETA)
+
+	d.UpdateStageProgress(diagnostics.SyncStageProgressUpdate{Stage: "TxLookup", Time: start.Add(41 * time.Second), Block: 0, Target: 3000, Start: true})
+	p = d.SyncProgress()
+	require.Equal(t, "TxLookup", p.CurrentStage)
+	require.Len(t, p.Stages, 2)
+	require.Equal(t, "0%", p.Stages[1].Progress)
+}
@@ -1250,7 +1250,9 @@ func (d *Downloader) mainLoop(silent bool) error {
 					delete(waiting, t.Name())
 					d.torrentDownload(t, downloadComplete)
 				case len(t.WebseedPeerConns()) > 0:
-					if d.webDownloadClient != nil {
+					// the web download replaces the whole file, while the webseed peers of the torrent download
+					// only the missing pieces of a partially valid file - e.g. the one which failed verification
+					if d.webDownloadClient != nil && !d.hasVerifiedPieces(t) {
 						var peerUrls []*url.URL
 
 						for _, peer := range t.WebseedPeerConns() {
@@ -2414,8 +2416,11 @@ func getPeersRatesForlogs(peersOfThisFile []*torrent.PeerConn, fName string) ([]
 	return rates, peers
 }
 
+// VerifyData - checks the pieces of the files on disk against their torrents. The files verified by a previous run and
+// not modified since are skipped, unless they are in whiteList. The pieces which fail are marked incomplete: the file is
+// repaired by downloading only them again.
 func (d *Downloader) VerifyData(ctx context.Context, whiteList []string, failFast bool) error {
-	total := 0
+	total, skipped := 0, 0
 	allTorrents := d.torrentClient.Torrents()
 	toVerify := make([]*torrent.Torrent, 0, len(allTorrents))
 	for _, t := range allTorrents {
@@ -2443,12 +2448,15 @@ func (d *Downloader) VerifyData(ctx context.Context, whiteList []string, failFas
 			if !exactOrPartialMatch {
 				continue
 			}
+		} else if d.checkVerified(t) {
+			skipped++
+			continue
 		}
 		toVerify = append(toVerify, t)
 		total += t.NumPieces()
 	}
-	d.logger.Info("[snapshots] Verify start")
-	defer d.logger.Info("[snapshots] Verify done", "files", len(toVerify), "whiteList", whiteList)
+	d.logger.Info("[snapshots] Verify start", "skipped_verified", skipped)
+	defer d.logger.Info("[snapshots] Verify done", "files", len(toVerify), "skipped_verified", skipped, "whiteList", whiteList)
 
 	completedPieces, completedFiles := &atomic.Uint64{}, &atomic.Uint64{}
 
@@ -2482,18 +2490,26 @@ func (d *Downloader) VerifyData(ctx context.Context, whiteList []string, failFas
 		g.Go(func() error {
 			defer completedFiles.Add(1)
 			if failFast {
-				return VerifyFileFailFast(context, t, d.SnapDir(), completedPieces)
+				if err := VerifyFileFailFast(context, t, d.SnapDir(), completedPieces); err != nil {
+					return err
+				}
+				return d.markVerified(context, t)
 			}
 
 			err := ScheduleVerifyFile(context, t, completedPieces)
 
 			if err != nil || !t.Complete.Bool() {
+				if err == nil {
+					d.logger.Warn("[snapshots] Verify failed, the failed pieces will be downloaded again", "file", t.Name(),
+						"missing", common.ByteCount(uint64(t.BytesMissing())))
+				}
 				if err := d.db.Update(context, torrentInfoReset(t.Name(), t.InfoHash().Bytes(), 0)); err != nil {
 					return fmt.Errorf("verify data: %s: reset failed: %w", t.Name(), err)
 				}
+				return err
 			}
 
-			return err
+			return d.markVerified(context, t)
 		})
 	}
 
@@ -2504,6 +2520,34 @@ func (d *Downloader) VerifyData(ctx context.Context, whiteList []string, failFas
 	return nil
 }
 
+func (d *Downloader) checkVerified(t *torrent.Torrent) (verified bool) {
+	_ = d.db.View(d.ctx, func(tx kv.Tx) error {
+		verified = downloaderrawdb.CheckFileVerified(tx, t.Name(), d.SnapDir(), t.InfoHash().Bytes())
+		return nil
+	})
+	return verified
+}
+
+func (d *Downloader) markVerified(ctx context.Context, t *torrent.Torrent) error {
+	fi, err := os.Stat(filepath.Join(d.SnapDir(), t.Name()))
+	if err != nil {
+		return err
+	}
+	if err := d.db.Update(ctx, torrentInfoVerified(t.Name(), t.InfoHash().Bytes(), fi.ModTime())); err != nil {
+		return fmt.Errorf("verify data: %s: %w", t.Name(), err)
+	}
+	return nil
+}
+
+// hasVerifiedPieces - the file on disk has pieces matching the torrent: only the missing ones need to be downloaded
+func (d *Downloader) hasVerifiedPieces(t *torrent.Torrent) bool {
+	if t.Info() == nil || t.BytesCompleted() == 0 {
+		return false
+	}
+	exists, err := dir.FileExist(filepath.Join(d.SnapDir(), t.Name()))
+	return err == nil && exists
+}
+
 // AddNewSeedableFile decides what we do depending on whether we have the .seg file or the .torrent file
 // have .torrent no .seg => get .seg file from .torrent
 // have .seg no .torrent => get .torrent from .seg
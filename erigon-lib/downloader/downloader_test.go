@@ -18,11 +18,14 @@ package downloader
 
 import (
 	"context"
+	"crypto/rand"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 
 	lg "github.com/anacrolix/log"
+	"github.com/anacrolix/torrent"
 	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-lib/common/datadir"
@@ -100,3 +103,38 @@ func TestVerifyData(t *testing.T) {
 	err = d.VerifyData(d.ctx, nil, false)
 	require.NoError(err)
 }
+
+func TestVerifyDataRecordsVerifiedFiles(t *testing.T) {
+	require := require.New(t)
+	dirs := datadir.New(t.TempDir())
+	cfg, err := downloadercfg2.New(context.Background(), dirs, "", lg.Info, 0, 0, 0, 0, 0, nil, nil, "testnet", false, false)
+	require.NoError(err)
+	d, err := New(context.Background(), cfg, log.New(), log.LvlInfo, true)
+	require.NoError(err)
+	defer d.Close()
+
+	name := "v1-000000-000500-headers.seg"
+	data := make([]byte, 3*downloadercfg2.DefaultPieceSize)
+	_, _ = rand.Read(data)
+	require.NoError(os.WriteFile(filepath.Join(dirs.Snap, name), data, 0644))
+	require.NoError(d.AddNewSeedableFile(d.ctx, name))
+	var tt *torrent.Torrent
+	for _, it := range d.torrentClient.Torrents() {
+		if it.Name() == name {
+			tt = it
+		}
+	}
+	require.NotNil(tt)
+
+	require.NoError(d.VerifyData(d.ctx, nil, false))
+	require.True(d.checkVerified(tt))
+
+	// corrupt one piece: the file is verified again, only the corrupted piece is missing
+	data[downloadercfg2.DefaultPieceSize+1]++
+	require.NoError(os.WriteFile(filepath.Join(dirs.Snap, name), data, 0644))
+	require.False(d.checkVerified(tt))
+	require.NoError(d.VerifyData(d.ctx, nil, false))
+	require.False(d.checkVerified(tt))
+	require.Equal(int64(downloadercfg2.DefaultPieceSize), tt.BytesMissing())
+	require.True(d.hasVerifiedPieces(tt))
+}
@@ -1,6 +1,7 @@
 package downloaderrawdb
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -15,6 +16,8 @@ type TorrentInfo struct {
 	Length    *int64     `json:"length,omitempty"`
 	Created   *time.Time `json:"created,omitempty"`
 	Completed *time.Time `json:"completed,omitempty"`
+	// Verified - the modification time of the file when all its pieces were last verified
+	Verified *time.Time `json:"verified,omitempty"`
 }
 
 func ReadTorrentInfo(downloaderDBTx kv.Tx, name string) (*TorrentInfo, error) {
@@ -71,3 +74,19 @@ func CheckFileComplete(tx kv.Tx, name string, snapDir string) (bool, int64, *tim
 	}
 	return false, 0, nil
 }
+
+// CheckFileVerified - the file of the torrent infoHash was verified and is not modified since
+func CheckFileVerified(tx kv.Tx, name string, snapDir string, infoHash []byte) bool {
+	info, err := ReadTorrentInfo(tx, name)
+	if err != nil || info.Verified == nil || !bytes.Equal(info.Hash, infoHash) {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(snapDir, name))
+	if err != nil {
+		return false
+	}
+	if info.Length != nil && fi.Size() != *info.Length {
+		return false
+	}
+	return fi.ModTime().Equal(*info.Verified)
+}
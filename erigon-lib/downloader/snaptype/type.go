@@ -17,6 +17,7 @@
 package snaptype
 
 import (
+	"cmp"
 	"context"
 	"encoding/binary"
 	"errors"
@@ -24,6 +25,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -233,6 +235,16 @@ func RegisterType(enum Enum, name string, versions Versions, rangeExtractor Rang
 	return t
 }
 
+// RegisteredTypes returns the registered snapshot types, ordered by enum
+func RegisteredTypes() []Type {
+	types := make([]Type, 0, len(registeredTypes))
+	for _, t := range registeredTypes {
+		types = append(types, t)
+	}
+	slices.SortFunc(types, func(a, b Type) int { return cmp.Compare(a.Enum(), b.Enum()) })
+	return types
+}
+
 func (s snapType) Enum() Enum {
 	return s.enum
 }
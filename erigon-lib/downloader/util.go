@@ -20,7 +20,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha1"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -446,6 +445,27 @@ func torrentInfoReset(fileName string, infoHash []byte, length int64) func(tx kv
 	}
 }
 
+// torrentInfoVerified - records that the file, with the modification time verifiedAt, matches the torrent infoHash
+func torrentInfoVerified(fileName string, infoHash []byte, verifiedAt time.Time) func(tx kv.RwTx) error {
+	return func(tx kv.RwTx) error {
+		info, err := downloaderrawdb.ReadTorrentInfo(tx, fileName)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(info.Hash, infoHash) {
+			now := time.Now()
+			info.Name = fileName
+			info.Hash = infoHash
+			info.Created = &now
+			info.Completed = nil
+		}
+
+		info.Verified = &verifiedAt
+		return downloaderrawdb.WriteTorrentInfo(tx, info)
+	}
+}
+
 func savePeerID(db kv.RwDB, peerID torrent.PeerID) error {
 	return db.Update(context.Background(), func(tx kv.RwTx) error {
 		return tx.Put(kv.BittorrentInfo, []byte(kv.BittorrentPeerID), peerID[:])
@@ -491,17 +511,10 @@ func ScheduleVerifyFile(ctx context.Context, t *torrent.Torrent, completePieces
 			cancel()
 			return wg.Wait()
 		case change := <-pieceChanges.Values:
-			if !change.Ok {
-				var err error
-
-				if change.Err != nil {
-					err = change.Err
-				} else {
-					err = errors.New("unexpected piece change error")
-				}
-
+			// a piece which doesn't match its hash is not an error: it stays incomplete, to be downloaded again
+			if change.Err != nil {
 				cancel()
-				return fmt.Errorf("piece %s:%d verify failed: %w", t.Name(), change.Index, err)
+				return fmt.Errorf("piece %s:%d verify failed: %w", t.Name(), change.Index, change.Err)
 			}
 
 			if !(change.Checking || change.Hashing || change.QueuedForHash || change.Marking) {
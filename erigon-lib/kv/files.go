@@ -44,4 +44,6 @@ const (
 	FileLogTopicsIdx  = "logtopics"
 	FileTracesFromIdx = "tracesfrom"
 	FileTracesToIdx   = "tracesto"
+
+	FileWithdrawalAddressIdx = "withdrawaladdrs"
 )
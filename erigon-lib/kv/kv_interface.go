@@ -471,6 +471,8 @@ type TemporalTx interface {
 
 	// return the earliest known txnum in history of a given domain
 	HistoryStartFrom(domainName Domain) uint64
+	// return the earliest txnum covered by a standalone inverted index
+	IndexStartFrom(name InvertedIdx) uint64
 
 	// DomainGetAsOf - state as of given `ts`
 	// Example: GetAsOf(Account, key, txNum) - retuns account's value before `txNum` transaction changed it
@@ -479,6 +481,10 @@ type TemporalTx interface {
 	GetAsOf(name Domain, k []byte, ts uint64) (v []byte, ok bool, err error)
 	RangeAsOf(name Domain, fromKey, toKey []byte, ts uint64, asc order.By, limit int) (it stream.KV, err error)
 
+	// DomainRange - keys of domain in [fromKey, toKey) with their values as of given `ts`,
+	// `ts == math.MaxUint64` means latest state (without history lookup). Only order.Asc is supported.
+	DomainRange(name Domain, fromKey, toKey []byte, ts uint64, asc order.By, limit int) (it stream.KV, err error)
+
 	// IndexRange - return iterator over range of inverted index for given key `k`
 	// Asc semantic:  [from, to) AND from > to
 	// Desc semantic: [from, to) AND from < to
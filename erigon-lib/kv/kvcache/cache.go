@@ -108,6 +108,7 @@ type Coherent struct {
 	evict                metrics.Gauge
 	latestStateView      *CoherentRoot
 	codeMiss             metrics.Counter
+	codeStale            metrics.Counter
 	timeout              metrics.Counter
 	hits                 metrics.Counter
 	codeHits             metrics.Counter
@@ -115,6 +116,7 @@ type Coherent struct {
 	stateEvict           *ThreadSafeEvictionList
 	codeEvict            *ThreadSafeEvictionList
 	miss                 metrics.Counter
+	stale                metrics.Counter // reads of views already evicted
 	cfg                  CoherentConfig
 	latestStateVersionID uint64
 	lock                 sync.Mutex
@@ -192,12 +194,14 @@ func New(cfg CoherentConfig) *Coherent {
 		hasher:       sha3.NewLegacyKeccak256(),
 		cfg:          cfg,
 		miss:         metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
+		stale:        metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="stale",name="%s"}`, cfg.MetricsLabel)),
 		hits:         metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
 		timeout:      metrics.GetOrCreateCounter(fmt.Sprintf(`cache_timeout_total{name="%s"}`, cfg.MetricsLabel)),
 		keys:         metrics.GetOrCreateGauge(fmt.Sprintf(`cache_keys_total{name="%s"}`, cfg.MetricsLabel)),
 		evict:        metrics.GetOrCreateGauge(fmt.Sprintf(`cache_list_total{name="%s"}`, cfg.MetricsLabel)),
 		codeMiss:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
 		codeHits:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
+		codeStale:    metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="stale",name="%s"}`, cfg.MetricsLabel)),
 		codeKeys:     metrics.GetOrCreateGauge(fmt.Sprintf(`cache_code_keys_total{name="%s"}`, cfg.MetricsLabel)),
 		codeEvictLen: metrics.GetOrCreateGauge(fmt.Sprintf(`cache_code_list_total{name="%s"}`, cfg.MetricsLabel)),
 	}
@@ -370,6 +374,8 @@ func (c *Coherent) View(ctx context.Context, tx kv.Tx) (CacheView, error) {
 	return &CoherentView{stateVersionID: id, tx: tx, cache: c}, nil
 }
 
+// getFromCache returns a nil root if the view was already evicted: requests lasting longer than KeepViews blocks then
+// read from their db transaction, without caching.
 func (c *Coherent) getFromCache(k []byte, id uint64, code bool) (*Element, *CoherentRoot, error) {
 	// using the full lock here rather than RLock as RLock causes a lot of calls to runtime.usleep degrading
 	// performance under load
@@ -378,7 +384,7 @@ func (c *Coherent) getFromCache(k []byte, id uint64, code bool) (*Element, *Cohe
 
 	r, ok := c.roots[id]
 	if !ok {
-		return nil, r, fmt.Errorf("too old ViewID: %d, latestStateVersionID=%d", id, c.latestStateVersionID)
+		return nil, nil, nil
 	}
 	isLatest := c.latestStateVersionID == id
 
@@ -404,7 +410,11 @@ func (c *Coherent) Get(k []byte, tx kv.Tx, id uint64) (v []byte, err error) {
 		c.hits.Inc()
 		return it.V, nil
 	}
-	c.miss.Inc()
+	if r == nil {
+		c.stale.Inc()
+	} else {
+		c.miss.Inc()
+	}
 
 	if c.cfg.StateV3 {
 		if len(k) == 20 {
@@ -418,7 +428,7 @@ func (c *Coherent) Get(k []byte, tx kv.Tx, id uint64) (v []byte, err error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(v) == 0 {
+	if len(v) == 0 || r == nil {
 		return v, nil
 	}
 	//fmt.Printf("from db: %#x,%x\n", k, v)
@@ -440,7 +450,11 @@ func (c *Coherent) GetCode(k []byte, tx kv.Tx, id uint64) (v []byte, err error)
 		c.codeHits.Inc()
 		return it.V, nil
 	}
-	c.codeMiss.Inc()
+	if r == nil {
+		c.codeStale.Inc()
+	} else {
+		c.codeMiss.Inc()
+	}
 
 	if c.cfg.StateV3 {
 		v, _, err = tx.(kv.TemporalTx).GetLatest(kv.CodeDomain, k)
@@ -450,6 +464,9 @@ func (c *Coherent) GetCode(k []byte, tx kv.Tx, id uint64) (v []byte, err error)
 	if err != nil {
 		return nil, err
 	}
+	if r == nil {
+		return v, nil
+	}
 	//fmt.Printf("from db: %#x,%x\n", k, v)
 
 	c.lock.Lock()
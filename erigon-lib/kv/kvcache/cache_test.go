@@ -404,3 +404,39 @@ func TestCode(t *testing.T) {
 		return nil
 	})
 }
+
+func TestGetEvictedView(t *testing.T) {
+	require, ctx := require.New(t), context.Background()
+	cfg := DefaultCoherentConfig
+	cfg.NewBlockWait = 0
+	c := New(cfg)
+	db, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	k1 := [20]byte{1}
+	account1Enc := types.EncodeAccountBytesV3(1, uint256.NewInt(11), make([]byte, 32), 2)
+
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		d, err := state.NewSharedDomains(tx, log.New())
+		if err != nil {
+			return err
+		}
+		defer d.Close()
+		if err := d.DomainPut(kv.AccountsDomain, k1[:], nil, account1Enc, nil, 0); err != nil {
+			return err
+		}
+		return d.Flush(ctx, tx)
+	}))
+
+	for id := uint64(1); id <= cfg.KeepViews+2; id++ {
+		c.advanceRoot(id) // until the view 1 is evicted
+	}
+	_, ok := c.roots[1]
+	require.False(ok)
+
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		v, err := c.Get(k1[:], tx, 1) // a request started before the eviction reads from its tx
+		require.NoError(err)
+		require.Equal(account1Enc, v)
+		return nil
+	}))
+	require.Zero(c.Len())
+}
@@ -615,6 +615,7 @@ func (db *MdbxKV) beginRw(ctx context.Context, flags uint) (txn kv.RwTx, err err
 		return nil, fmt.Errorf("%w, lable: %s, trace: %s", err, db.opts.label, stack2.Trace().String())
 	}
 
+	rwTxsBegin.Store(db, time.Now())
 	return &MdbxTx{
 		db:  db,
 		tx:  tx,
@@ -623,6 +624,22 @@ func (db *MdbxKV) beginRw(ctx context.Context, flags uint) (txn kv.RwTx, err err
 	}, nil
 }
 
+// rwTxsBegin - *MdbxKV -> time.Time, start of the open RW transaction of the db (mdbx allows only one)
+var rwTxsBegin sync.Map
+
+// LongestRwTx returns the label of the db whose open RW transaction is the oldest one and for how long it's held,
+// zero duration if no RW transaction is open
+func LongestRwTx() (label kv.Label, held time.Duration) {
+	now := time.Now()
+	rwTxsBegin.Range(func(key, value any) bool {
+		if d := now.Sub(value.(time.Time)); d > held {
+			label, held = key.(*MdbxKV).opts.label, d
+		}
+		return true
+	})
+	return label, held
+}
+
 type MdbxTx struct {
 	tx               *mdbx.Txn
 	id               uint64 // set only if TRACE_TX=true
@@ -870,6 +887,7 @@ func (tx *MdbxTx) Commit() error {
 		if tx.readOnly {
 			tx.db.roTxsLimiter.Release(1)
 		} else {
+			rwTxsBegin.Delete(tx.db)
 			runtime.UnlockOSThread()
 		}
 		tx.db.leakDetector.Del(tx.id)
@@ -921,6 +939,7 @@ func (tx *MdbxTx) Rollback() {
 		if tx.readOnly {
 			tx.db.roTxsLimiter.Release(1)
 		} else {
+			rwTxsBegin.Delete(tx.db)
 			runtime.UnlockOSThread()
 		}
 		tx.db.leakDetector.Del(tx.id)
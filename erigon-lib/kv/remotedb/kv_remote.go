@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"runtime"
 	"unsafe"
 
@@ -633,6 +634,11 @@ func (tx *tx) HistoryStartFrom(name kv.Domain) uint64 {
 	return 0
 }
 
+func (tx *tx) IndexStartFrom(name kv.InvertedIdx) uint64 {
+	// TODO: not yet implemented, return 0 for now
+	return 0
+}
+
 func (tx *tx) GetAsOf(name kv.Domain, k []byte, ts uint64) (v []byte, ok bool, err error) {
 	reply, err := tx.db.remoteKV.GetLatest(tx.ctx, &remote.GetLatestReq{TxId: tx.id, Table: name.String(), K: k, Ts: ts})
 	if err != nil {
@@ -658,6 +664,15 @@ func (tx *tx) RangeAsOf(name kv.Domain, fromKey, toKey []byte, ts uint64, asc or
 		return reply.Keys, reply.Values, reply.NextPageToken, nil
 	}), nil
 }
+func (tx *tx) DomainRange(name kv.Domain, fromKey, toKey []byte, ts uint64, asc order.By, limit int) (it stream.KV, err error) {
+	return stream.PaginateKV(func(pageToken string) (keys, vals [][]byte, nextPageToken string, err error) {
+		reply, err := tx.db.remoteKV.RangeAsOf(tx.ctx, &remote.RangeAsOfReq{TxId: tx.id, Table: name.String(), FromKey: fromKey, ToKey: toKey, Ts: ts, Latest: ts == math.MaxUint64, OrderAscend: bool(asc), Limit: int64(limit), PageToken: pageToken})
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return reply.Keys, reply.Values, reply.NextPageToken, nil
+	}), nil
+}
 func (tx *tx) HistorySeek(name kv.Domain, k []byte, ts uint64) (v []byte, ok bool, err error) {
 	reply, err := tx.db.remoteKV.HistorySeek(tx.ctx, &remote.HistorySeekReq{TxId: tx.id, Table: name.String(), K: k, Ts: ts})
 	if err != nil {
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -63,7 +64,8 @@ const MaxTxTTL = 60 * time.Second
 // 6.0.0 - Blocks now have system-txs - in the begin/end of block
 // 6.1.0 - Add methods Range, IndexRange, HistorySeek, HistoryRange
 // 6.2.0 - Add HistoryFiles to reply of Snapshots() method
-var KvServiceAPIVersion = &types.VersionReply{Major: 7, Minor: 0, Patch: 0}
+// 7.1.0 - RangeAsOf supports `latest` (DomainRange), pagination of RangeAsOf and IndexRange doesn't repeat the last item of a page
+var KvServiceAPIVersion = &types.VersionReply{Major: 7, Minor: 1, Patch: 0}
 
 type KvServer struct {
 	remote.UnimplementedKVServer // must be embedded to have forward compatible implementations.
@@ -611,7 +613,11 @@ func (s *KvServer) IndexRange(_ context.Context, req *remote.IndexRangeReq) (*re
 			limit--
 
 			if len(reply.Timestamps) == int(req.PageSize) && it.HasNext() {
-				reply.NextPageToken, err = marshalPagination(&remote.IndexPagination{NextTimeStamp: int64(v), Limit: int64(limit)})
+				next := int64(v) + 1 // `from` is inclusive
+				if !req.OrderAscend {
+					next = int64(v) - 1
+				}
+				reply.NextPageToken, err = marshalPagination(&remote.IndexPagination{NextTimeStamp: next, Limit: int64(limit)})
 				if err != nil {
 					return err
 				}
@@ -682,7 +688,11 @@ func (s *KvServer) RangeAsOf(_ context.Context, req *remote.RangeAsOfReq) (*remo
 		if !ok {
 			return errors.New("server DB doesn't implement kv.Temporal interface")
 		}
-		it, err := ttx.RangeAsOf(domainName, fromKey, toKey, req.Ts, order.By(req.OrderAscend), limit)
+		ts := req.Ts
+		if req.Latest {
+			ts = math.MaxUint64
+		}
+		it, err := ttx.DomainRange(domainName, fromKey, toKey, ts, order.By(req.OrderAscend), limit)
 		if err != nil {
 			return err
 		}
@@ -699,7 +709,8 @@ func (s *KvServer) RangeAsOf(_ context.Context, req *remote.RangeAsOfReq) (*remo
 			limit--
 
 			if len(reply.Keys) == int(req.PageSize) && it.HasNext() {
-				reply.NextPageToken, err = marshalPagination(&remote.PairsPagination{NextKey: k, Limit: int64(limit)})
+				// `fromKey` is inclusive: next page starts from the smallest key after `k`
+				reply.NextPageToken, err = marshalPagination(&remote.PairsPagination{NextKey: append(bytesCopy(key), 0), Limit: int64(limit)})
 				if err != nil {
 					return err
 				}
@@ -21,13 +21,20 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/common/length"
+	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon-lib/types"
 )
 
 func TestKvServer_renew(t *testing.T) {
@@ -99,6 +106,76 @@ func TestKvServer_renew(t *testing.T) {
 	require.NoError(g.Wait())
 }
 
+func TestKvServer_DomainRange(t *testing.T) {
+	//goland:noinspection GoBoolExpressions
+	if runtime.GOOS == "windows" {
+		t.Skip("fix me on win please")
+	}
+
+	require, ctx := require.New(t), context.Background()
+	db, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	key := func(i byte) []byte {
+		k := make([]byte, length.Addr)
+		k[0] = i
+		return k
+	}
+	account := func(txNum uint64, i byte) []byte {
+		return types.EncodeAccountBytesV3(txNum*10+uint64(i), uint256.NewInt(0), nil, 0)
+	}
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		domains, err := state.NewSharedDomains(tx, log.New())
+		if err != nil {
+			return err
+		}
+		defer domains.Close()
+		for txNum := uint64(1); txNum <= 2; txNum++ {
+			domains.SetTxNum(txNum)
+			for i := byte(0); i < 5; i++ {
+				var prev []byte
+				if txNum > 1 {
+					prev = account(txNum-1, i)
+				}
+				if err := domains.DomainPut(kv.AccountsDomain, key(i), nil, account(txNum, i), prev, 0); err != nil {
+					return err
+				}
+			}
+		}
+		return domains.Flush(ctx, tx)
+	}))
+
+	s := NewKvServer(ctx, db, nil, nil, nil, log.New())
+	id, err := s.begin(ctx)
+	require.NoError(err)
+	defer s.rollback(id)
+
+	rangeAsOf := func(req *remote.RangeAsOfReq) (keys, vals [][]byte) {
+		req.TxId, req.Table, req.OrderAscend, req.Limit, req.PageSize = id, kv.AccountsDomain.String(), true, -1, 2
+		for {
+			reply, err := s.RangeAsOf(ctx, req)
+			require.NoError(err)
+			keys, vals = append(keys, reply.Keys...), append(vals, reply.Values...)
+			if reply.NextPageToken == "" {
+				return keys, vals
+			}
+			req.PageToken = reply.NextPageToken
+		}
+	}
+
+	keys, vals := rangeAsOf(&remote.RangeAsOfReq{Latest: true})
+	require.Len(keys, 5)
+	for i := range keys {
+		require.Equal(key(byte(i)), keys[i])
+		require.Equal(account(2, byte(i)), vals[i])
+	}
+
+	keys, vals = rangeAsOf(&remote.RangeAsOfReq{FromKey: key(1), ToKey: key(4), Ts: 2})
+	require.Len(keys, 3)
+	for i := range keys {
+		require.Equal(key(byte(i+1)), keys[i])
+		require.Equal(account(1, byte(i+1)), vals[i])
+	}
+}
+
 func TestKVServerSnapshotsReturnsSnapshots(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -126,7 +126,11 @@ const (
 	CallFromIndex = "CallFromIndex"
 	CallToIndex   = "CallToIndex"
 
-	TxLookup = "BlockTransactionLookup" // hash -> transaction/receipt lookup metadata
+	TxLookup = "BlockTransactionLookup" // hash -> txNum of the transaction
+
+	// TxSenderIdx - optional index of the transactions by sender, written by the TxSenderIndex stage. It is DupSort-ed table
+	// sender address -> 8-byte BE txNum
+	TxSenderIdx = "TxSenderIdx"
 
 	ConfigTable = "Config" // config prefix for the db
 
@@ -167,18 +171,19 @@ const (
 	PendingEpoch = "DevPendingEpoch" // block_num_u64+block_hash->transition_proof
 
 	// BOR
-	BorFinality             = "BorFinality"
-	BorTxLookup             = "BlockBorTransactionLookup" // transaction_hash -> block_num_u64
-	BorSeparate             = "BorSeparate"               // persisted snapshots of the Validator Sets, with their proposer priorities
-	BorEvents               = "BorEvents"                 // event_id -> event_payload
-	BorEventNums            = "BorEventNums"              // block_num -> event_id (last event_id in that block)
-	BorEventProcessedBlocks = "BorEventProcessedBlocks"   // block_num -> block_time, tracks processed blocks in the bridge, used for unwinds and restarts, gets pruned
-	BorSpans                = "BorSpans"                  // span_id -> span (in JSON encoding)
-	BorMilestones           = "BorMilestones"             // milestone_id -> milestone (in JSON encoding)
-	BorMilestoneEnds        = "BorMilestoneEnds"          // start block_num -> milestone_id (first block of milestone)
-	BorCheckpoints          = "BorCheckpoints"            // checkpoint_id -> checkpoint (in JSON encoding)
-	BorCheckpointEnds       = "BorCheckpointEnds"         // start block_num -> checkpoint_id (first block of checkpoint)
-	BorProducerSelections   = "BorProducerSelections"     // span_id -> span selection with accumulated proposer priorities (in JSON encoding)
+	BorFinality                 = "BorFinality"
+	BorTxLookup                 = "BlockBorTransactionLookup"   // transaction_hash -> block_num_u64
+	BorSeparate                 = "BorSeparate"                 // persisted snapshots of the Validator Sets, with their proposer priorities
+	BorEvents                   = "BorEvents"                   // event_id -> event_payload
+	BorEventNums                = "BorEventNums"                // block_num -> event_id (last event_id in that block)
+	BorEventProcessedBlocks     = "BorEventProcessedBlocks"     // block_num -> block_time, tracks processed blocks in the bridge, used for unwinds and restarts, gets pruned
+	BorSpans                    = "BorSpans"                    // span_id -> span (in JSON encoding)
+	BorMilestones               = "BorMilestones"               // milestone_id -> milestone (in JSON encoding)
+	BorMilestoneEnds            = "BorMilestoneEnds"            // start block_num -> milestone_id (first block of milestone)
+	BorCheckpoints              = "BorCheckpoints"              // checkpoint_id -> checkpoint (in JSON encoding)
+	BorCheckpointEnds           = "BorCheckpointEnds"           // start block_num -> checkpoint_id (first block of checkpoint)
+	BorProducerSelections       = "BorProducerSelections"       // span_id -> span selection with accumulated proposer priorities (in JSON encoding)
+	BorSprintProducerSelections = "BorSprintProducerSelections" // sprint start block_num -> sprint selection with the proposer priorities incremented up to the sprint (in JSON encoding)
 
 	// Downloader
 	BittorrentCompletion = "BittorrentCompletion"
@@ -222,6 +227,9 @@ const (
 	TblTracesToKeys   = "TracesToKeys"
 	TblTracesToIdx    = "TracesToIdx"
 
+	TblWithdrawalAddressKeys = "WithdrawalAddressKeys"
+	TblWithdrawalAddressIdx  = "WithdrawalAddressIdx"
+
 	// Prune progress of execution: tableName -> [8bytes of invStep]latest pruned key
 	// Could use table constants `Tbl{Account,Storage,Code,Commitment}Keys` for domains
 	// corresponding history tables `Tbl{Account,Storage,Code,Commitment}HistoryKeys` for history
@@ -299,6 +307,14 @@ const (
 
 	StatesProcessingProgress = "StatesProcessingProgress"
 
+	// Slashing protection of the embedded validator client (EIP-3076)
+	// [pubkey + slot] => [signing root]
+	SlashingProtectionBlocks = "SlashingProtectionBlocks"
+	// [pubkey + target epoch] => [source epoch + signing root]
+	SlashingProtectionAttestations = "SlashingProtectionAttestations"
+	// genesis validators root, [pubkey] => [lowest source epoch]
+	SlashingProtectionMetadata = "SlashingProtectionMetadata"
+
 	//Diagnostics tables
 	DiagSystemInfo = "DiagSystemInfo"
 	DiagSyncStages = "DiagSyncStages"
@@ -310,9 +326,14 @@ var (
 	PruneHistory   = []byte("pruneHistory")
 	PruneBlocks    = []byte("pruneBlocks")
 
+	PruneHistoryStart = []byte("pruneHistoryStart") // txNum the history starts from, set by the prune command
+
 	DBSchemaVersionKey = []byte("dbVersion")
 	GenesisKey         = []byte("genesis")
 
+	// WithdrawalsIndexFromBlockKey - first block executed with the withdrawals index, absent if all blocks were
+	WithdrawalsIndexFromBlockKey = []byte("WithdrawalsIndexFromBlock")
+
 	BittorrentPeerID = "peerID"
 
 	PlainStateVersion = []byte("PlainStateVersion")
@@ -321,6 +342,8 @@ var (
 
 	StatesProcessingKey          = []byte("StatesProcessing")
 	MinimumPrunableStepDomainKey = []byte("MinimumPrunableStepDomainKey")
+
+	SlashingProtectionGenesisValidatorsRootKey = []byte("genesisValidatorsRoot")
 )
 
 // ChaindataTables - list of all buckets. App will panic if some bucket is not in this list.
@@ -336,6 +359,7 @@ var ChaindataTables = []string{
 	BlockBody,
 	Receipts,
 	TxLookup,
+	TxSenderIdx,
 	ConfigTable,
 	DatabaseInfo,
 	IncarnationMap,
@@ -375,6 +399,7 @@ var ChaindataTables = []string{
 	BorCheckpoints,
 	BorCheckpointEnds,
 	BorProducerSelections,
+	BorSprintProducerSelections,
 	TblAccountVals,
 	TblAccountHistoryKeys,
 	TblAccountHistoryVals,
@@ -409,6 +434,8 @@ var ChaindataTables = []string{
 	TblTracesFromIdx,
 	TblTracesToKeys,
 	TblTracesToIdx,
+	TblWithdrawalAddressKeys,
+	TblWithdrawalAddressIdx,
 
 	TblPruningProgress,
 
@@ -453,6 +480,10 @@ var ChaindataTables = []string{
 	ActiveValidatorIndicies,
 	EffectiveBalancesDump,
 	BalancesDump,
+	// Slashing protection
+	SlashingProtectionBlocks,
+	SlashingProtectionAttestations,
+	SlashingProtectionMetadata,
 	AccountChangeSetDeprecated,
 	StorageChangeSetDeprecated,
 	HashedAccountsDeprecated,
@@ -552,6 +583,7 @@ var ChaindataTablesCfg = TableCfg{
 		DupToLen:                  28,
 	},
 	CallTraceSet: {Flags: DupSort},
+	TxSenderIdx:  {Flags: DupSort},
 
 	TblAccountVals:           {Flags: DupSort},
 	TblAccountHistoryKeys:    {Flags: DupSort},
@@ -579,6 +611,8 @@ var ChaindataTablesCfg = TableCfg{
 	TblTracesFromIdx:         {Flags: DupSort},
 	TblTracesToKeys:          {Flags: DupSort},
 	TblTracesToIdx:           {Flags: DupSort},
+	TblWithdrawalAddressKeys: {Flags: DupSort},
+	TblWithdrawalAddressIdx:  {Flags: DupSort},
 }
 
 var AuRaTablesCfg = TableCfg{
@@ -587,17 +621,18 @@ var AuRaTablesCfg = TableCfg{
 }
 
 var BorTablesCfg = TableCfg{
-	BorFinality:             {Flags: DupSort},
-	BorTxLookup:             {Flags: DupSort},
-	BorEvents:               {Flags: DupSort},
-	BorEventNums:            {Flags: DupSort},
-	BorEventProcessedBlocks: {Flags: DupSort},
-	BorSpans:                {Flags: DupSort},
-	BorCheckpoints:          {Flags: DupSort},
-	BorCheckpointEnds:       {Flags: DupSort},
-	BorMilestones:           {Flags: DupSort},
-	BorMilestoneEnds:        {Flags: DupSort},
-	BorProducerSelections:   {Flags: DupSort},
+	BorFinality:                 {Flags: DupSort},
+	BorTxLookup:                 {Flags: DupSort},
+	BorEvents:                   {Flags: DupSort},
+	BorEventNums:                {Flags: DupSort},
+	BorEventProcessedBlocks:     {Flags: DupSort},
+	BorSpans:                    {Flags: DupSort},
+	BorCheckpoints:              {Flags: DupSort},
+	BorCheckpointEnds:           {Flags: DupSort},
+	BorMilestones:               {Flags: DupSort},
+	BorMilestoneEnds:            {Flags: DupSort},
+	BorProducerSelections:       {Flags: DupSort},
+	BorSprintProducerSelections: {Flags: DupSort},
 }
 
 var TxpoolTablesCfg = TableCfg{}
@@ -745,12 +780,15 @@ const (
 	LogAddrIdx    InvertedIdx = "LogAddrIdx"
 	TracesFromIdx InvertedIdx = "TracesFromIdx"
 	TracesToIdx   InvertedIdx = "TracesToIdx"
-
-	LogAddrIdxPos    InvertedIdxPos = 0
-	LogTopicIdxPos   InvertedIdxPos = 1
-	TracesFromIdxPos InvertedIdxPos = 2
-	TracesToIdxPos   InvertedIdxPos = 3
-	StandaloneIdxLen InvertedIdxPos = 4
+	// WithdrawalAddrIdx - recipient address => txNums of the final system txs of the blocks withdrawing to it
+	WithdrawalAddrIdx InvertedIdx = "WithdrawalAddrIdx"
+
+	LogAddrIdxPos        InvertedIdxPos = 0
+	LogTopicIdxPos       InvertedIdxPos = 1
+	TracesFromIdxPos     InvertedIdxPos = 2
+	TracesToIdxPos       InvertedIdxPos = 3
+	WithdrawalAddrIdxPos InvertedIdxPos = 4
+	StandaloneIdxLen     InvertedIdxPos = 5
 )
 
 const (
@@ -768,6 +806,8 @@ func (iip InvertedIdxPos) String() string {
 		return "traceFrom"
 	case TracesToIdxPos:
 		return "traceTo"
+	case WithdrawalAddrIdxPos:
+		return "withdrawalAddr"
 	default:
 		return "unknown inverted index"
 	}
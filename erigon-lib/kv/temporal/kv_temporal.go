@@ -18,6 +18,8 @@ package temporal
 
 import (
 	"context"
+	"errors"
+	"math"
 
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/mdbx"
@@ -205,6 +207,10 @@ func (tx *Tx) HistoryStartFrom(name kv.Domain) uint64 {
 	return tx.filesTx.HistoryStartFrom(name)
 }
 
+func (tx *Tx) IndexStartFrom(name kv.InvertedIdx) uint64 {
+	return tx.filesTx.IndexStartFrom(name)
+}
+
 func (tx *Tx) RangeAsOf(name kv.Domain, fromKey, toKey []byte, asOfTs uint64, asc order.By, limit int) (stream.KV, error) {
 	it, err := tx.filesTx.RangeAsOf(tx.ctx, tx.MdbxTx, name, fromKey, toKey, asOfTs, asc, limit)
 	if err != nil {
@@ -214,6 +220,21 @@ func (tx *Tx) RangeAsOf(name kv.Domain, fromKey, toKey []byte, asOfTs uint64, as
 	return it, nil
 }
 
+func (tx *Tx) DomainRange(name kv.Domain, fromKey, toKey []byte, ts uint64, asc order.By, limit int) (stream.KV, error) {
+	if !asc {
+		return nil, errors.New("DomainRange: only order.Asc is supported")
+	}
+	if ts != math.MaxUint64 {
+		return tx.RangeAsOf(name, fromKey, toKey, ts, asc, limit)
+	}
+	it, err := tx.filesTx.RangeLatest(tx.MdbxTx, name, fromKey, toKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	tx.resourcesToClose = append(tx.resourcesToClose, it)
+	return it, nil
+}
+
 func (tx *Tx) GetLatest(name kv.Domain, k []byte) (v []byte, step uint64, err error) {
 	v, step, ok, err := tx.filesTx.GetLatest(name, k, tx.MdbxTx)
 	if err != nil {
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The verbosity of the handlers created by DynamicLvlFilterHandler can be changed at runtime: for all records
+// by SetLvl, and for the records of a target (subsystem) by SetTargetLvl.
+//
+// The target of a record is the tag its message starts with, lower-cased: "bor" for "[bor] ...". Stages are tagged
+// with their position, "[4/12 Execution] ..." is of the "execution" target as well.
+var (
+	dynamicHandlers   []*dynamicLvlHandler
+	dynamicHandlersMu sync.Mutex
+
+	targetLvls   atomic.Pointer[map[string]Lvl] // copy on write
+	targetLvlsMu sync.Mutex
+)
+
+type dynamicLvlHandler struct {
+	maxLvl atomic.Int64
+	h      Handler
+}
+
+func (d *dynamicLvlHandler) Log(r *Record) error {
+	lvl := Lvl(d.maxLvl.Load())
+	if targets := targetLvls.Load(); targets != nil {
+		if targetLvl, ok := recordTargetLvl(r.Msg, *targets); ok {
+			lvl = targetLvl
+		}
+	}
+	if r.Lvl > lvl {
+		return nil
+	}
+	return d.h.Log(r)
+}
+
+// DynamicLvlFilterHandler returns a Handler like LvlFilterHandler, but the level can be changed at runtime by
+// SetLvl and overridden for the records of specific targets by SetTargetLvl.
+func DynamicLvlFilterHandler(maxLvl Lvl, h Handler) Handler {
+	d := &dynamicLvlHandler{h: h}
+	d.maxLvl.Store(int64(maxLvl))
+
+	dynamicHandlersMu.Lock()
+	defer dynamicHandlersMu.Unlock()
+	dynamicHandlers = append(dynamicHandlers, d)
+	return d
+}
+
+// SetLvl sets the level of all the handlers created by DynamicLvlFilterHandler
+func SetLvl(lvl Lvl) {
+	dynamicHandlersMu.Lock()
+	defer dynamicHandlersMu.Unlock()
+	for _, d := range dynamicHandlers {
+		d.maxLvl.Store(int64(lvl))
+	}
+}
+
+// SetTargetLvl sets the level of the records of the target, whatever is the level of the handler
+func SetTargetLvl(target string, lvl Lvl) {
+	updateTargetLvls(func(targets map[string]Lvl) {
+		targets[strings.ToLower(target)] = lvl
+	})
+}
+
+// ResetTargetLvl makes the records of the target filtered by the level of the handler again
+func ResetTargetLvl(target string) {
+	updateTargetLvls(func(targets map[string]Lvl) {
+		delete(targets, strings.ToLower(target))
+	})
+}
+
+// TargetLvls returns the targets which have their own level
+func TargetLvls() map[string]Lvl {
+	if targets := targetLvls.Load(); targets != nil {
+		return maps.Clone(*targets)
+	}
+	return map[string]Lvl{}
+}
+
+func updateTargetLvls(update func(targets map[string]Lvl)) {
+	targetLvlsMu.Lock()
+	defer targetLvlsMu.Unlock()
+	targets := TargetLvls()
+	update(targets)
+	if len(targets) == 0 {
+		targetLvls.Store(nil)
+		return
+	}
+	targetLvls.Store(&targets)
+}
+
+func recordTargetLvl(msg string, targets map[string]Lvl) (Lvl, bool) {
+	if len(msg) == 0 || msg[0] != '[' {
+		return 0, false
+	}
+	end := strings.IndexByte(msg, ']')
+	if end < 0 {
+		return 0, false
+	}
+	tag := strings.ToLower(msg[1:end])
+	if lvl, ok := targets[tag]; ok {
+		return lvl, true
+	}
+	if i := strings.LastIndexByte(tag, ' '); i >= 0 {
+		lvl, ok := targets[tag[i+1:]]
+		return lvl, ok
+	}
+	return 0, false
+}
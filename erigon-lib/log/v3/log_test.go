@@ -271,6 +271,48 @@ func TestLvlFilterHandler(t *testing.T) {
 	}
 }
 
+// not parallel: the levels are global
+func TestDynamicLvlFilterHandler(t *testing.T) {
+	l := New()
+	h, r := testHandler()
+	l.SetHandler(DynamicLvlFilterHandler(LvlInfo, h))
+	defer SetLvl(LvlInfo)
+	defer ResetTargetLvl("bor")
+	defer ResetTargetLvl("execution")
+
+	expect := func(msg string, logged bool) {
+		t.Helper()
+		*r = Record{}
+		l.Debug(msg)
+		if logged && r.Msg != msg {
+			t.Fatalf("Expected record %q to be logged", msg)
+		}
+		if !logged && r.Msg != "" {
+			t.Fatalf("Expected record %q to be filtered, but got %q", msg, r.Msg)
+		}
+	}
+
+	expect("[bor] debug'd", false)
+	SetTargetLvl("Bor", LvlDebug)
+	expect("[bor] debug'd", true)
+	expect("[commitment] debug'd", false)
+	expect("debug'd", false)
+
+	SetTargetLvl("execution", LvlDebug)
+	expect("[4/12 Execution] debug'd", true)
+	if lvls := TargetLvls(); len(lvls) != 2 || lvls["bor"] != LvlDebug {
+		t.Fatalf("Unexpected target levels %v", lvls)
+	}
+
+	ResetTargetLvl("bor")
+	expect("[bor] debug'd", false)
+
+	SetLvl(LvlDebug)
+	expect("debug'd", true)
+	SetTargetLvl("bor", LvlError)
+	expect("[bor] debug'd", false)
+}
+
 func TestNetHandler(t *testing.T) {
 	t.Skip()
 	t.Parallel()
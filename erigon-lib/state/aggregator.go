@@ -70,6 +70,7 @@ type Aggregator struct {
 	mergeWorkers           int // usually 1
 
 	commitmentValuesTransform bool // enables squeezing commitment values in CommitmentDomain
+	commitmentWarmup          bool // read the trie ahead of the commitment computation, see SetCommitmentWarmup
 
 	// To keep DB small - need move data to small files ASAP.
 	// It means goroutine which creating small files - can't be locked by merge or indexing.
@@ -93,6 +94,8 @@ type Aggregator struct {
 	ctxAutoIncrement atomic.Uint64
 
 	produce bool
+
+	filesEvents filesEvents // latest builds and merges of the files, see FilesEvents
 }
 
 const AggregatorSqueezeCommitmentValues = true
@@ -128,12 +131,22 @@ func domainIntegrityCheck(name kv.Domain, dirs datadir.Dirs, fromStep, toStep ui
 
 var dbgCommBtIndex = dbg.EnvBool("AGG_COMMITMENT_BT", false)
 
+// dbgKVExistence enables .kvei existence filters of domains: GetLatest skips the .kv files not containing the key
+// instead of probing their index. Disabling it saves ram and disk of archive nodes with few misses.
+var dbgKVExistence = dbg.EnvBool("AGG_KV_EXISTENCE", true)
+
 func init() {
 	if dbgCommBtIndex {
 		cfg := Schema[kv.CommitmentDomain]
 		cfg.indexList = withBTree | withExistence
 		Schema[kv.CommitmentDomain] = cfg
 	}
+	if !dbgKVExistence {
+		for name, cfg := range Schema {
+			cfg.indexList &^= withExistence
+			Schema[name] = cfg
+		}
+	}
 }
 
 var Schema = map[kv.Domain]domainCfg{
@@ -301,6 +314,9 @@ func NewAggregator(ctx context.Context, dirs datadir.Dirs, aggregationStep uint6
 	if err := a.registerII(kv.TracesToIdxPos, salt, dirs, aggregationStep, kv.FileTracesToIdx, kv.TblTracesToKeys, kv.TblTracesToIdx, logger); err != nil {
 		return nil, err
 	}
+	if err := a.registerII(kv.WithdrawalAddrIdxPos, salt, dirs, aggregationStep, kv.FileWithdrawalAddressIdx, kv.TblWithdrawalAddressKeys, kv.TblWithdrawalAddressIdx, logger); err != nil {
+		return nil, err
+	}
 	a.KeepRecentTxnsOfHistoriesWithDisabledSnapshots(100_000) // ~1k blocks of history
 	a.recalcVisibleFiles(a.DirtyFilesEndTxNumMinimax())
 
@@ -699,8 +715,10 @@ func (a *Aggregator) buildFiles(ctx context.Context, step uint64) error {
 		}
 
 		a.wg.Add(1)
-		g.Go(func() error {
+		g.Go(func() (err error) {
 			defer a.wg.Done()
+			event := FilesEvent{Kind: FilesEventBuild, Name: d.filenameBase, FromStep: step, ToStep: step + 1, StartedAt: time.Now()}
+			defer func() { a.addFilesEvent(event, err) }()
 
 			var collation Collation
 			if err := a.db.View(ctx, func(tx kv.Tx) (err error) {
@@ -713,12 +731,17 @@ func (a *Aggregator) buildFiles(ctx context.Context, step uint64) error {
 			collations = append(collations, collation)
 			collListMu.Unlock()
 
+			event.InputKeys, event.OutputKeys = uint64(collation.historyCount), uint64(collation.valuesCount)
+			if collation.historyCount == 0 { // history is disabled
+				event.InputKeys = event.OutputKeys
+			}
 			sf, err := d.buildFiles(ctx, step, collation, a.ps)
 			collation.Close()
 			if err != nil {
 				sf.CleanupOnError()
 				return err
 			}
+			event.OutputSize = decompressorsSize(sf.valuesDecomp, sf.historyDecomp, sf.efHistoryDecomp)
 
 			dd, err := kv.String2Domain(d.filenameBase)
 			if err != nil {
@@ -741,11 +764,13 @@ func (a *Aggregator) buildFiles(ctx context.Context, step uint64) error {
 		}
 
 		a.wg.Add(1)
-		g.Go(func() error {
+		g.Go(func() (err error) {
 			defer a.wg.Done()
+			event := FilesEvent{Kind: FilesEventBuild, Name: ii.filenameBase, FromStep: step, ToStep: step + 1, StartedAt: time.Now()}
+			defer func() { a.addFilesEvent(event, err) }()
 
 			var collation InvertedIndexCollation
-			err := a.db.View(ctx, func(tx kv.Tx) (err error) {
+			err = a.db.View(ctx, func(tx kv.Tx) (err error) {
 				collation, err = ii.collate(ctx, step, tx)
 				return err
 			})
@@ -757,6 +782,8 @@ func (a *Aggregator) buildFiles(ctx context.Context, step uint64) error {
 				sf.CleanupOnError()
 				return err
 			}
+			event.OutputSize, event.OutputKeys = decompressorsSize(sf.decomp), decompressorKeys(sf.decomp)
+			event.InputKeys = event.OutputKeys
 
 			switch ii.keysTable {
 			case kv.TblLogTopicsKeys:
@@ -767,6 +794,8 @@ func (a *Aggregator) buildFiles(ctx context.Context, step uint64) error {
 				static.ivfs[kv.TracesFromIdxPos] = sf
 			case kv.TblTracesToKeys:
 				static.ivfs[kv.TracesToIdxPos] = sf
+			case kv.TblWithdrawalAddressKeys:
+				static.ivfs[kv.WithdrawalAddrIdxPos] = sf
 			default:
 				panic("unknown index " + ii.keysTable)
 			}
@@ -1604,6 +1633,21 @@ func (ac *AggregatorRoTx) mergeFiles(ctx context.Context, files SelectedStaticFi
 		}
 
 		g.Go(func() (err error) {
+			event := ac.a.newMergeEvent(ac.d[id].d.filenameBase, r.domain[id].mergeRange())
+			values, history := filesItemsDecompressors(files.d[id]), filesItemsDecompressors(files.dIdx[id], files.dHist[id])
+			event.InputSize = decompressorsSize(values...) + decompressorsSize(history...)
+			defer func() {
+				if err == nil {
+					event.OutputSize = filesItemsSize(mf.d[id], mf.dIdx[id], mf.dHist[id])
+					if r.domain[id].values.needMerge {
+						event.InputKeys, event.OutputKeys = decompressorKeys(values...), filesItemsKeys(mf.d[id])
+					} else {
+						event.InputKeys, event.OutputKeys = decompressorKeys(filesItemsDecompressors(files.dIdx[id])...), filesItemsKeys(mf.dIdx[id])
+					}
+				}
+				ac.a.addFilesEvent(event, err)
+			}()
+
 			var vt valueTransformer
 			if ac.a.commitmentValuesTransform && kid == kv.CommitmentDomain {
 				ac.RestrictSubsetFileDeletions(true)
@@ -1636,8 +1680,17 @@ func (ac *AggregatorRoTx) mergeFiles(ctx context.Context, files SelectedStaticFi
 		}
 		id := id
 		rng := rng
-		g.Go(func() error {
-			var err error
+		g.Go(func() (err error) {
+			event := ac.a.newMergeEvent(ac.iis[id].ii.filenameBase, rng)
+			in := filesItemsDecompressors(files.ii[id])
+			event.InputSize, event.InputKeys = decompressorsSize(in...), decompressorKeys(in...)
+			defer func() {
+				if err == nil {
+					event.OutputSize, event.OutputKeys = filesItemsSize(mf.iis[id]), filesItemsKeys(mf.iis[id])
+				}
+				ac.a.addFilesEvent(event, err)
+			}()
+
 			mf.iis[id], err = ac.iis[id].mergeFiles(ctx, files.ii[id], rng.from, rng.to, ac.a.ps)
 			return err
 		})
@@ -1704,6 +1757,12 @@ func (a *Aggregator) SetProduceMod(produce bool) {
 	a.produce = produce
 }
 
+// SetCommitmentWarmup makes the commitment computation of the SharedDomains created from now on read the branches,
+// accounts and storage of the updated keys ahead of the trie, see commitment.HexPatriciaHashed.SetWarmup
+func (a *Aggregator) SetCommitmentWarmup(enabled bool) {
+	a.commitmentWarmup = enabled
+}
+
 // Returns channel which is closed when aggregation is done
 func (a *Aggregator) BuildFilesInBackground(txNum uint64) chan struct{} {
 	fin := make(chan struct{})
@@ -1801,6 +1860,30 @@ func (ac *AggregatorRoTx) HistoryStartFrom(domainName kv.Domain) uint64 {
 	return ac.d[domainName].HistoryStartFrom()
 }
 
+// Returns the first txNum covered by a standalone inverted index: the start of its first file. An index without files
+// covers only what follows the state files - they were built before the index existed.
+func (ac *AggregatorRoTx) IndexStartFrom(name kv.InvertedIdx) uint64 {
+	var iit *InvertedIndexRoTx
+	switch name {
+	case kv.LogTopicIdx:
+		iit = ac.iis[kv.LogTopicIdxPos]
+	case kv.LogAddrIdx:
+		iit = ac.iis[kv.LogAddrIdxPos]
+	case kv.TracesFromIdx:
+		iit = ac.iis[kv.TracesFromIdxPos]
+	case kv.TracesToIdx:
+		iit = ac.iis[kv.TracesToIdxPos]
+	case kv.WithdrawalAddrIdx:
+		iit = ac.iis[kv.WithdrawalAddrIdxPos]
+	default:
+		return 0
+	}
+	if len(iit.files) == 0 {
+		return ac.TxNumsInFiles(kv.StateDomains...)
+	}
+	return iit.files[0].startTxNum
+}
+
 func (ac *AggregatorRoTx) IndexRange(name kv.InvertedIdx, k []byte, fromTs, toTs int, asc order.By, limit int, tx kv.Tx) (timestamps stream.U64, err error) {
 	switch name {
 	case kv.AccountsHistoryIdx:
@@ -1823,6 +1906,8 @@ func (ac *AggregatorRoTx) IndexRange(name kv.InvertedIdx, k []byte, fromTs, toTs
 		return ac.iis[kv.TracesFromIdxPos].IdxRange(k, fromTs, toTs, asc, limit, tx)
 	case kv.TracesToIdx:
 		return ac.iis[kv.TracesToIdxPos].IdxRange(k, fromTs, toTs, asc, limit, tx)
+	case kv.WithdrawalAddrIdx:
+		return ac.iis[kv.WithdrawalAddrIdxPos].IdxRange(k, fromTs, toTs, asc, limit, tx)
 	default:
 		return nil, fmt.Errorf("unexpected history name: %s", name)
 	}
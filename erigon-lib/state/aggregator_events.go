@@ -0,0 +1,179 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon-lib/seg"
+)
+
+const (
+	FilesEventBuild = "build"
+	FilesEventMerge = "merge"
+)
+
+// filesEventsLimit is the number of the latest events kept by the Aggregator
+const filesEventsLimit = 512
+
+// FilesEvent describes one build or merge of the files of a domain or an inverted index.
+type FilesEvent struct {
+	Kind      string        `json:"kind"` // FilesEventBuild or FilesEventMerge
+	Name      string        `json:"name"` // domain or inverted index
+	FromStep  uint64        `json:"fromStep"`
+	ToStep    uint64        `json:"toStep"`
+	StartedAt time.Time     `json:"startedAt"`
+	Took      time.Duration `json:"took"`
+
+	// InputSize is the size of the merged files, builds read their input from the db and leave it 0
+	InputSize  uint64 `json:"inputSize"`
+	OutputSize uint64 `json:"outputSize"`
+	// InputKeys of a build are the updates of the step, of a merge the keys of the merged files
+	InputKeys  uint64 `json:"inputKeys"`
+	OutputKeys uint64 `json:"outputKeys"`
+	// DedupRatio is the share of the input keys which didn't make it into the output
+	DedupRatio float64 `json:"dedupRatio"`
+	Err        string  `json:"error,omitempty"`
+}
+
+func (e FilesEvent) String() string {
+	return fmt.Sprintf("%s %s %d-%d took=%s in=%d/%dkeys out=%d/%dkeys dedup=%.2f",
+		e.Kind, e.Name, e.FromStep, e.ToStep, e.Took, e.InputSize, e.InputKeys, e.OutputSize, e.OutputKeys, e.DedupRatio)
+}
+
+// filesEvents is a ring of the latest FilesEvent
+type filesEvents struct {
+	mu     sync.Mutex
+	events []FilesEvent
+	next   int
+}
+
+func (fe *filesEvents) add(e FilesEvent) {
+	if e.InputKeys > 0 && e.OutputKeys <= e.InputKeys {
+		e.DedupRatio = 1 - float64(e.OutputKeys)/float64(e.InputKeys)
+	}
+	observeFilesEvent(e)
+
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	if len(fe.events) < filesEventsLimit {
+		fe.events = append(fe.events, e)
+		return
+	}
+	fe.events[fe.next] = e
+	fe.next = (fe.next + 1) % filesEventsLimit
+}
+
+// list returns the events oldest first
+func (fe *filesEvents) list() []FilesEvent {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	res := make([]FilesEvent, 0, len(fe.events))
+	res = append(res, fe.events[fe.next:]...)
+	return append(res, fe.events[:fe.next]...)
+}
+
+func observeFilesEvent(e FilesEvent) {
+	if e.Err != "" {
+		metrics.GetOrCreateCounter(fmt.Sprintf(`domain_files_failed{op="%s",name="%s"}`, e.Kind, e.Name)).Inc()
+		return
+	}
+	metrics.GetOrCreateSummary(fmt.Sprintf(`domain_files_took{op="%s",name="%s"}`, e.Kind, e.Name)).Observe(e.Took.Seconds())
+	metrics.GetOrCreateCounter(fmt.Sprintf(`domain_files_size{op="%s",name="%s",io="in"}`, e.Kind, e.Name)).AddUint64(e.InputSize)
+	metrics.GetOrCreateCounter(fmt.Sprintf(`domain_files_size{op="%s",name="%s",io="out"}`, e.Kind, e.Name)).AddUint64(e.OutputSize)
+	metrics.GetOrCreateCounter(fmt.Sprintf(`domain_files_keys{op="%s",name="%s",io="in"}`, e.Kind, e.Name)).AddUint64(e.InputKeys)
+	metrics.GetOrCreateCounter(fmt.Sprintf(`domain_files_keys{op="%s",name="%s",io="out"}`, e.Kind, e.Name)).AddUint64(e.OutputKeys)
+}
+
+// FilesEvents returns the latest builds and merges of the files, oldest first.
+func (a *Aggregator) FilesEvents() []FilesEvent { return a.filesEvents.list() }
+
+func (a *Aggregator) addFilesEvent(e FilesEvent, err error) {
+	e.Took = time.Since(e.StartedAt)
+	if err != nil {
+		e.Err = err.Error()
+	}
+	a.filesEvents.add(e)
+	a.logger.Debug("[agg] files", "event", e.String(), "err", e.Err)
+}
+
+func decompressorsSize(ds ...*seg.Decompressor) (size uint64) {
+	for _, d := range ds {
+		if d != nil {
+			size += uint64(d.Size())
+		}
+	}
+	return size
+}
+
+// decompressorKeys returns the number of the keys in key-value files
+func decompressorKeys(ds ...*seg.Decompressor) (keys uint64) {
+	for _, d := range ds {
+		if d != nil {
+			keys += uint64(d.Count() / 2)
+		}
+	}
+	return keys
+}
+
+func filesItemsDecompressors(items ...[]*filesItem) (ds []*seg.Decompressor) {
+	for _, list := range items {
+		for _, item := range list {
+			if item != nil {
+				ds = append(ds, item.decompressor)
+			}
+		}
+	}
+	return ds
+}
+
+func filesItemsSize(items ...*filesItem) (size uint64) {
+	for _, item := range items {
+		if item != nil {
+			size += decompressorsSize(item.decompressor)
+		}
+	}
+	return size
+}
+
+func filesItemsKeys(items ...*filesItem) (keys uint64) {
+	for _, item := range items {
+		if item != nil {
+			keys += decompressorKeys(item.decompressor)
+		}
+	}
+	return keys
+}
+
+func (a *Aggregator) newMergeEvent(name string, r *MergeRange) FilesEvent {
+	return FilesEvent{Kind: FilesEventMerge, Name: name, FromStep: r.from / a.StepSize(), ToStep: r.to / a.StepSize(), StartedAt: time.Now()}
+}
+
+// mergeRange returns the widest of the ranges merged: values, history or its index
+func (r DomainRanges) mergeRange() *MergeRange {
+	switch {
+	case r.values.needMerge:
+		return &r.values
+	case r.history.history.needMerge:
+		return &r.history.history
+	default:
+		return &r.history.index
+	}
+}
@@ -168,6 +168,23 @@ func TestAggregatorV3_Merge(t *testing.T) {
 	dc.Close()
 
 	require.EqualValues(t, otherMaxWrite, binary.BigEndian.Uint64(v[:]))
+
+	built, merged := map[string]int{}, map[string]int{}
+	for _, e := range agg.FilesEvents() {
+		require.Empty(t, e.Err, e.String())
+		require.Less(t, e.FromStep, e.ToStep, e.String())
+		require.NotZero(t, e.OutputSize, e.String())
+		switch e.Kind {
+		case FilesEventBuild:
+			built[e.Name]++
+		case FilesEventMerge:
+			require.NotZero(t, e.InputSize, e.String())
+			require.LessOrEqual(t, e.OutputKeys, e.InputKeys, e.String())
+			merged[e.Name]++
+		}
+	}
+	require.NotZero(t, built[kv.AccountsDomain.String()])
+	require.NotZero(t, merged[kv.AccountsDomain.String()])
 }
 
 func TestAggregatorV3_MergeValTransform(t *testing.T) {
@@ -1260,6 +1277,77 @@ func Test_helper_decodeAccountv3Bytes(t *testing.T) {
 	fmt.Printf("input %x nonce %d balance %d codeHash %d\n", input, n, b.Uint64(), ch)
 }
 
+func TestAggregator_CheckBtreeAccessors(t *testing.T) {
+	if !dbgKVExistence {
+		t.Skip("existence filters disabled by AGG_KV_EXISTENCE")
+	}
+	_, agg := testDbAggregatorWithFiles(t, &testAggConfig{stepSize: 10})
+	ctx, domains := context.Background(), []kv.Domain{kv.AccountsDomain}
+
+	mismatches, err := agg.CheckBtreeAccessors(ctx, domains, false, false, 2)
+	require.NoError(t, err)
+	require.Empty(t, mismatches)
+
+	// damage accessors of one file: drop its .bt and replace its .kvei by an empty filter
+	d := agg.d[kv.AccountsDomain]
+	items := d.dirtyFilesWithDecompressor()
+	require.NotEmpty(t, items)
+	item := items[len(items)/2]
+	fromStep, toStep := item.startTxNum/d.aggregationStep, item.endTxNum/d.aggregationStep
+	item.bindex.Close()
+	item.bindex = nil
+	require.NoError(t, os.Remove(d.kvBtFilePath(fromStep, toStep)))
+	item.existence, err = NewExistenceFilter(uint64(item.decompressor.Count()/2), d.kvExistenceIdxFilePath(fromStep, toStep))
+	require.NoError(t, err)
+
+	mismatches, err = agg.CheckBtreeAccessors(ctx, domains, false, false, 2)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 2)
+	for _, m := range mismatches {
+		require.False(t, m.Rebuilt)
+		require.Contains(t, []string{filepath.Base(d.kvBtFilePath(fromStep, toStep)), filepath.Base(d.kvExistenceIdxFilePath(fromStep, toStep))}, m.File)
+	}
+
+	mismatches, err = agg.CheckBtreeAccessors(ctx, domains, true, false, 2)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 2)
+	for _, m := range mismatches {
+		require.True(t, m.Rebuilt)
+	}
+	require.NotNil(t, item.bindex)
+	// the accessors are built aside and renamed in place
+	tmpDirs, err := filepath.Glob(filepath.Join(filepath.Dir(d.kvBtFilePath(fromStep, toStep)), "rebuild-accessors-*"))
+	require.NoError(t, err)
+	require.Empty(t, tmpDirs)
+
+	// no workers means one
+	mismatches, err = agg.CheckBtreeAccessors(ctx, domains, false, false, 0)
+	require.NoError(t, err)
+	require.Empty(t, mismatches)
+}
+
+func TestAggregator_WarmupHotFiles(t *testing.T) {
+	_, agg := testDbAggregatorWithFiles(t, &testAggConfig{stepSize: 10})
+
+	at := agg.BeginFilesRo()
+	defer at.Close()
+	dt := at.d[kv.AccountsDomain]
+	require.Greater(t, len(dt.files), 1)
+	lastStep := dt.files[len(dt.files)-1].endTxNum / agg.StepSize()
+
+	hot := dt.hotFilePaths(1)
+	require.NotEmpty(t, hot)
+	for _, fPath := range hot {
+		require.Contains(t, filepath.Base(fPath), fmt.Sprintf("-%d.", lastStep))
+	}
+	require.Greater(t, len(dt.hotFilePaths(lastStep)), len(hot))
+
+	require.NoError(t, agg.WarmupHotFiles(context.Background(), 2, 2))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, agg.WarmupHotFiles(ctx, 2, 2), context.Canceled)
+}
+
 func TestAggregator_RebuildCommitmentBasedOnFiles(t *testing.T) {
 	db, agg := testDbAggregatorWithFiles(t, &testAggConfig{
 		stepSize:                         20,
@@ -0,0 +1,139 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	common2 "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/mmap"
+)
+
+// hotDomains are read by every block executed at the chain tip
+var hotDomains = []kv.Domain{kv.AccountsDomain, kv.StorageDomain, kv.CommitmentDomain}
+
+// WarmupHotFiles loads into the page cache the files of hot domains (and their accessors) which cover
+// the latest `steps` steps: the OS is advised to read them ahead, then they are read through.
+// After a restart the first blocks executed at the chain tip don't wait for the disk then.
+func (a *Aggregator) WarmupHotFiles(ctx context.Context, steps uint64, workers int) error {
+	if steps == 0 {
+		return nil
+	}
+	at := a.BeginFilesRo()
+	defer at.Close()
+
+	var paths []string
+	for _, name := range hotDomains {
+		paths = append(paths, at.d[name].hotFilePaths(steps)...)
+	}
+
+	t := time.Now()
+	var size atomic.Uint64
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+	for _, fPath := range paths {
+		fPath := fPath
+		g.Go(func() error {
+			n, err := warmupFile(ctx, fPath)
+			size.Add(n)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	a.logger.Info("[agg] warmed up hot files", "steps", steps, "files", len(paths), "size", common2.ByteCount(size.Load()), "took", time.Since(t))
+	return nil
+}
+
+func (a *Aggregator) WarmupHotFilesInBackground(steps uint64, workers int) {
+	if steps == 0 {
+		return
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.WarmupHotFiles(a.ctx, steps, workers); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, common2.ErrStopped) {
+				return
+			}
+			a.logger.Warn("[agg] WarmupHotFilesInBackground", "err", err)
+		}
+	}()
+}
+
+// hotFilePaths returns the paths of the visible files of the domain (values and accessors) which end in the latest `steps` steps
+func (dt *DomainRoTx) hotFilePaths(steps uint64) (paths []string) {
+	if len(dt.files) == 0 {
+		return nil
+	}
+	lastStep := dt.files[len(dt.files)-1].endTxNum / dt.d.aggregationStep
+	for _, f := range dt.files {
+		if f.endTxNum/dt.d.aggregationStep+steps <= lastStep {
+			continue
+		}
+		if f.src.decompressor != nil {
+			paths = append(paths, f.src.decompressor.FilePath())
+		}
+		if f.src.index != nil {
+			paths = append(paths, f.src.index.FilePath())
+		}
+		if f.src.bindex != nil {
+			paths = append(paths, f.src.bindex.FilePath())
+		}
+		if f.src.existence != nil {
+			paths = append(paths, f.src.existence.FilePath)
+		}
+	}
+	return paths
+}
+
+// warmupFile advises the OS to read the file into the page cache and reads it through, returns the amount of bytes read
+func warmupFile(ctx context.Context, fPath string) (uint64, error) {
+	f, err := os.Open(fPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if err := mmap.FadviseWillNeed(f); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1024*1024)
+	var read uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return read, err
+		}
+		n, err := f.Read(buf)
+		read += uint64(n)
+		if errors.Is(err, io.EOF) {
+			return read, nil
+		}
+		if err != nil {
+			return read, err
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spaolacci/murmur3"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/common/background"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/seg"
+)
+
+// AccessorMismatch is a .bt or .kvei file which doesn't match its .kv file.
+type AccessorMismatch struct {
+	File    string // accessor file name
+	Reason  string
+	Rebuilt bool // the accessor was rebuilt and matches its .kv file now
+}
+
+func (m AccessorMismatch) String() string {
+	if m.Rebuilt {
+		return fmt.Sprintf("%s: %s (rebuilt)", m.File, m.Reason)
+	}
+	return fmt.Sprintf("%s: %s", m.File, m.Reason)
+}
+
+// CheckBtreeAccessors verifies the .bt and .kvei files of the domains: every key of a .kv file must be
+// found by its .bt at the same offset with the same value, and must be contained by its .kvei.
+// With rebuild the mismatched accessors are rebuilt from the .kv file, with force all of them are.
+// Domains without .bt accessors are skipped, all domains are checked if none is passed.
+// Rebuilding replaces the accessors of the open files: nothing else may read the files meanwhile, the caller must
+// own the datadir (see datadir.Dirs.MustFlock).
+func (a *Aggregator) CheckBtreeAccessors(ctx context.Context, domains []kv.Domain, rebuild, force bool, workers int) ([]AccessorMismatch, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if len(domains) == 0 {
+		for name := kv.Domain(0); name < kv.DomainLen; name++ {
+			domains = append(domains, name)
+		}
+	}
+
+	var mu sync.Mutex
+	var mismatches []AccessorMismatch
+	report := func(m AccessorMismatch) {
+		a.logger.Warn("[agg] accessor mismatch", "file", m.File, "reason", m.Reason, "rebuilt", m.Rebuilt)
+		mu.Lock()
+		defer mu.Unlock()
+		mismatches = append(mismatches, m)
+	}
+
+	ps := background.NewProgressSet()
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+	go func() {
+		logEvery := time.NewTicker(20 * time.Second)
+		defer logEvery.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-logEvery.C:
+				a.logger.Info("[agg] checking accessors", "progress", ps.String())
+			}
+		}
+	}()
+	for _, name := range domains {
+		d := a.d[name]
+		if d.indexList&withBTree == 0 {
+			a.logger.Info("[agg] domain has no btree accessors, skip", "domain", name.String())
+			continue
+		}
+		for _, item := range d.dirtyFilesWithDecompressor() {
+			item := item
+			g.Go(func() error { return d.checkBtreeAccessors(ctx, item, rebuild, force, ps, report) })
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return mismatches, err
+	}
+	return mismatches, nil
+}
+
+func (d *Domain) dirtyFilesWithDecompressor() (l []*filesItem) {
+	d.dirtyFiles.Walk(func(items []*filesItem) bool {
+		for _, item := range items {
+			if item.decompressor != nil {
+				l = append(l, item)
+			}
+		}
+		return true
+	})
+	return l
+}
+
+// checkBtreeAccessors checks the .bt and .kvei of one .kv file. Not safe to call while the files are read:
+// rebuilt accessors replace the ones of the item.
+func (d *Domain) checkBtreeAccessors(ctx context.Context, item *filesItem, rebuild, force bool, ps *background.ProgressSet, report func(AccessorMismatch)) error {
+	fromStep, toStep := item.startTxNum/d.aggregationStep, item.endTxNum/d.aggregationStep
+	btPath, existencePath := d.kvBtFilePath(fromStep, toStep), d.kvExistenceIdxFilePath(fromStep, toStep)
+
+	btReason, existenceReason, err := d.verifyBtreeAccessors(ctx, item, ps)
+	if err != nil {
+		return err
+	}
+	if btReason == "" && existenceReason == "" && !force {
+		return nil
+	}
+	if !rebuild && !force {
+		if btReason != "" {
+			report(AccessorMismatch{File: filepath.Base(btPath), Reason: btReason})
+		}
+		if existenceReason != "" {
+			report(AccessorMismatch{File: filepath.Base(existencePath), Reason: existenceReason})
+		}
+		return nil
+	}
+
+	if item.refcount.Load() > 0 {
+		return fmt.Errorf("can't rebuild accessors of %s: the file is being read", item.decompressor.FileName())
+	}
+	// .bt and .kvei are built together, into a temporary directory next to the files: the accessors in place are
+	// replaced by a rename once the new ones are verified, a failed rebuild leaves them as they were
+	tmpDir, err := os.MkdirTemp(filepath.Dir(btPath), "rebuild-accessors-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpBtPath, tmpExistencePath := filepath.Join(tmpDir, filepath.Base(btPath)), filepath.Join(tmpDir, filepath.Base(existencePath))
+	if err := BuildBtreeIndexWithDecompressor(tmpBtPath, item.decompressor, d.compression, ps, d.dirs.Tmp, *d.salt, d.logger, d.noFsync); err != nil {
+		return fmt.Errorf("rebuild btree index for %s: %w", item.decompressor.FileName(), err)
+	}
+	rebuilt := &filesItem{decompressor: item.decompressor, startTxNum: item.startTxNum, endTxNum: item.endTxNum}
+	if err := d.openRebuiltAccessors(rebuilt, tmpBtPath, tmpExistencePath, toStep); err != nil {
+		closeAccessors(rebuilt)
+		return err
+	}
+	rebuiltBtReason, rebuiltExistenceReason, err := d.verifyBtreeAccessors(ctx, rebuilt, ps)
+	closeAccessors(rebuilt)
+	if err != nil {
+		return err
+	}
+	if rebuiltBtReason != "" || rebuiltExistenceReason != "" {
+		return fmt.Errorf("rebuilt accessors of %s don't match: %s %s", item.decompressor.FileName(), rebuiltBtReason, rebuiltExistenceReason)
+	}
+
+	closeAccessors(item)
+	if err := os.Rename(tmpBtPath, btPath); err != nil {
+		return err
+	}
+	if d.indexList&withExistence != 0 {
+		if err := os.Rename(tmpExistencePath, existencePath); err != nil {
+			return err
+		}
+	}
+	if err := d.openRebuiltAccessors(item, btPath, existencePath, toStep); err != nil {
+		return err
+	}
+	if btReason != "" {
+		report(AccessorMismatch{File: filepath.Base(btPath), Reason: btReason, Rebuilt: true})
+	}
+	if existenceReason != "" {
+		report(AccessorMismatch{File: filepath.Base(existencePath), Reason: existenceReason, Rebuilt: true})
+	}
+	return nil
+}
+
+func (d *Domain) openRebuiltAccessors(item *filesItem, btPath, existencePath string, toStep uint64) (err error) {
+	if item.bindex, err = OpenBtreeIndexWithDecompressor(btPath, d.btreeM(toStep), item.decompressor, d.compression); err != nil {
+		return err
+	}
+	if d.indexList&withExistence != 0 {
+		if item.existence, err = OpenExistenceFilter(existencePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func closeAccessors(item *filesItem) {
+	if item.bindex != nil {
+		item.bindex.Close()
+		item.bindex = nil
+	}
+	if item.existence != nil {
+		item.existence.Close()
+		item.existence = nil
+	}
+}
+
+// verifyBtreeAccessors returns the reasons the .bt and .kvei of the item don't match its .kv file, empty if they do
+func (d *Domain) verifyBtreeAccessors(ctx context.Context, item *filesItem, ps *background.ProgressSet) (btReason, existenceReason string, err error) {
+	useExistence := d.indexList&withExistence != 0
+	if item.bindex == nil {
+		btReason = "missing or can't be opened"
+	}
+	if useExistence && item.existence == nil {
+		existenceReason = "missing or can't be opened"
+	}
+	if item.bindex == nil && (!useExistence || item.existence == nil) {
+		return btReason, existenceReason, nil
+	}
+	if item.bindex != nil && item.bindex.KeyCount() != uint64(item.decompressor.Count()/2) {
+		btReason = fmt.Sprintf("has %d keys, .kv has %d", item.bindex.KeyCount(), item.decompressor.Count()/2)
+	}
+
+	p := ps.AddNew(item.decompressor.FileName(), uint64(item.decompressor.Count()/2))
+	defer ps.Delete(p)
+	defer item.decompressor.EnableReadAhead().DisableReadAhead()
+
+	getter := seg.NewReader(item.decompressor.MakeGetter(), d.compression)
+	btGetter := seg.NewReader(item.decompressor.MakeGetter(), d.compression)
+	var key, val []byte
+	var keyOffset, nextOffset uint64
+	for i := 0; getter.HasNext(); i++ {
+		if i%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", "", err
+			}
+		}
+		keyOffset = nextOffset
+		key, _ = getter.Next(key[:0])
+		val, nextOffset = getter.Next(val[:0])
+
+		if btReason == "" && item.bindex != nil {
+			_, v, offset, found, err := item.bindex.Get(key, btGetter)
+			switch {
+			case err != nil:
+				btReason = fmt.Sprintf("key %x: %s", key, err)
+			case !found:
+				btReason = fmt.Sprintf("key %x not found", key)
+			case !bytes.Equal(v, val):
+				btReason = fmt.Sprintf("key %x: value %x, .kv has %x", key, v, val)
+			case keyOffset != offset:
+				btReason = fmt.Sprintf("key %x: found at offset %d, .kv has it at %d", key, offset, keyOffset)
+			}
+		}
+		if existenceReason == "" && useExistence && item.existence != nil {
+			if hi, _ := murmur3.Sum128WithSeed(key, *d.salt); !item.existence.ContainsHash(hi) {
+				existenceReason = fmt.Sprintf("key %x not contained", key)
+			}
+		}
+		if btReason != "" && (existenceReason != "" || !useExistence) {
+			break
+		}
+		p.Processed.Add(1)
+	}
+	return btReason, existenceReason, nil
+}
+
+func (d *Domain) btreeM(toStep uint64) uint64 {
+	if toStep == 0 && d.filenameBase == "commitment" {
+		return 128
+	}
+	return DefaultBtreeM
+}
@@ -26,6 +26,7 @@ import (
 	"math"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -670,6 +671,8 @@ func (sd *SharedDomains) IndexAdd(table kv.InvertedIdx, key []byte) (err error)
 		err = sd.iiWriters[kv.TracesToIdxPos].Add(key)
 	case kv.TblTracesFromIdx:
 		err = sd.iiWriters[kv.TracesFromIdxPos].Add(key)
+	case kv.WithdrawalAddrIdx, kv.TblWithdrawalAddressIdx:
+		err = sd.iiWriters[kv.WithdrawalAddrIdxPos].Add(key)
 	default:
 		panic(fmt.Errorf("unknown shared index %s", table))
 	}
@@ -889,6 +892,10 @@ func (sd *SharedDomains) Close() {
 		for _, iiWriter := range sd.iiWriters {
 			iiWriter.close()
 		}
+		// the aggTx may be shared with other txs (e.g. a memory batch over sd.roTx), its cursors may be of sd.roTx
+		for _, d := range sd.aggTx.d {
+			d.closeValsCursor()
+		}
 	}
 
 	if sd.sdCtx != nil {
@@ -1121,9 +1128,55 @@ func NewSharedDomainsCommitmentContext(sd *SharedDomains, mode commitment.Mode,
 
 	ctx.patriciaTrie, ctx.updates = commitment.InitializeTrieAndUpdates(trieVariant, mode, sd.aggTx.a.tmpdir)
 	ctx.patriciaTrie.ResetContext(ctx)
+	if hph, ok := ctx.patriciaTrie.(*commitment.HexPatriciaHashed); ok && sd.aggTx.a.commitmentWarmup {
+		ctx.enableWarmup(hph)
+	}
 	return ctx
 }
 
+// commitmentWarmupWorkers is the number of workers reading ahead of the trie. Their reads are serialized
+// with the reads of the trie, so a single one keeps the trie fed while it hashes.
+const commitmentWarmupWorkers = 1
+
+// enableWarmup makes the trie read ahead of its fold/unfold loop. The warm-up workers and the trie share the
+// SharedDomains and their tx, neither of which is safe for concurrent use: they all read through a lock.
+func (sdc *SharedDomainsCommitmentContext) enableWarmup(hph *commitment.HexPatriciaHashed) {
+	locked := &lockedCommitmentContext{sdc: sdc}
+	hph.ResetContext(locked)
+	hph.SetWarmup(func() (commitment.PatriciaContext, func(), error) {
+		return locked, func() {}, nil
+	}, commitmentWarmupWorkers, sdc.sharedDomains.logger)
+}
+
+type lockedCommitmentContext struct {
+	mu  sync.Mutex
+	sdc *SharedDomainsCommitmentContext
+}
+
+func (lc *lockedCommitmentContext) Branch(prefix []byte) ([]byte, uint64, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.sdc.Branch(prefix)
+}
+
+func (lc *lockedCommitmentContext) PutBranch(prefix []byte, data []byte, prevData []byte, prevStep uint64) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.sdc.PutBranch(prefix, data, prevData, prevStep)
+}
+
+func (lc *lockedCommitmentContext) Account(plainKey []byte) (*commitment.Update, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.sdc.Account(plainKey)
+}
+
+func (lc *lockedCommitmentContext) Storage(plainKey []byte) (*commitment.Update, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.sdc.Storage(plainKey)
+}
+
 func (sdc *SharedDomainsCommitmentContext) Close() {
 	sdc.updates.Close()
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
 
+	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/length"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
@@ -108,6 +109,81 @@ func TestSharedDomain_CommitmentKeyReplacement(t *testing.T) {
 	require.Equal(t, expectedHash, resultHash)
 }
 
+func TestSharedDomain_CommitmentWarmup(t *testing.T) {
+	t.Parallel()
+
+	stepSize := uint64(100)
+	computeRoots := func(warmup bool) [][]byte {
+		db, agg := testDbAndAggregatorv3(t, stepSize)
+		agg.SetCommitmentWarmup(warmup)
+
+		ctx := context.Background()
+		rwTx, err := db.BeginRw(ctx)
+		require.NoError(t, err)
+		defer rwTx.Rollback()
+
+		ac := agg.BeginFilesRo()
+		defer ac.Close()
+
+		domains, err := NewSharedDomains(WrapTxWithCtx(rwTx, ac), log.New())
+		require.NoError(t, err)
+		defer domains.Close()
+
+		// the same updates in the same order for both runs
+		rnd := newRnd(4711)
+		var addrs [][]byte
+		var roots [][]byte
+		for txNum := uint64(1); txNum <= stepSize*4; txNum++ {
+			domains.SetTxNum(txNum)
+			for j := 0; j < 10; j++ {
+				var addr []byte
+				if len(addrs) > 0 && rnd.IntN(2) == 0 {
+					addr = addrs[rnd.IntN(len(addrs))]
+				} else {
+					addr = generateRandomKeyBytes(rnd, length.Addr)
+					addrs = append(addrs, addr)
+				}
+				prev, step, err := domains.GetLatest(kv.AccountsDomain, addr)
+				require.NoError(t, err)
+				switch rnd.IntN(4) {
+				case 0:
+					if prev != nil {
+						require.NoError(t, domains.DomainDel(kv.AccountsDomain, addr, nil, prev, step))
+						continue
+					}
+					fallthrough
+				case 1:
+					acc := types.EncodeAccountBytesV3(txNum, uint256.NewInt(txNum*100_000), nil, 0)
+					require.NoError(t, domains.DomainPut(kv.AccountsDomain, addr, nil, acc, prev, step))
+				default:
+					if prev == nil {
+						acc := types.EncodeAccountBytesV3(txNum, uint256.NewInt(txNum*100_000), nil, 0)
+						require.NoError(t, domains.DomainPut(kv.AccountsDomain, addr, nil, acc, prev, step))
+					}
+					for i := 0; i < 20; i++ {
+						loc := generateRandomKeyBytes(rnd, length.Hash)
+						prev, step, err := domains.GetLatest(kv.StorageDomain, append(common.Copy(addr), loc...))
+						require.NoError(t, err)
+						require.NoError(t, domains.DomainPut(kv.StorageDomain, addr, loc, uint256.NewInt(txNum).Bytes(), prev, step))
+					}
+				}
+			}
+			if txNum%10 == 0 {
+				root, err := domains.ComputeCommitment(ctx, true, txNum/10, "")
+				require.NoError(t, err)
+				roots = append(roots, root)
+			}
+			if txNum%stepSize == 0 {
+				// the following computations read the trie from the db
+				require.NoError(t, domains.Flush(ctx, rwTx))
+			}
+		}
+		return roots
+	}
+
+	require.Equal(t, computeRoots(false), computeRoots(true))
+}
+
 func TestSharedDomain_Unwind(t *testing.T) {
 	t.Parallel()
 
@@ -1519,6 +1519,57 @@ func TestDomain_GetAfterAggregation(t *testing.T) {
 	}
 }
 
+func TestDomain_GetLatestSkipsFilesByExistence(t *testing.T) {
+	if !dbgKVExistence {
+		t.Skip("existence filters disabled by AGG_KV_EXISTENCE")
+	}
+	db, d := testDbAndDomainOfStep(t, 25, log.New())
+	require := require.New(t)
+	require.NotZero(d.indexList & withExistence)
+
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(err)
+	defer tx.Rollback()
+
+	dc := d.BeginFilesRo()
+	defer d.Close()
+	writer := dc.NewWriter()
+	defer writer.close()
+
+	totalTx := uint64(200)
+	for txNum := uint64(1); txNum <= totalTx; txNum++ {
+		writer.SetTxNum(txNum)
+		k := []byte(fmt.Sprintf("key%d", txNum))
+		require.NoError(writer.PutWithPrev(k, nil, []byte(fmt.Sprintf("value%d", txNum)), nil, 0))
+	}
+	require.NoError(writer.Flush(context.Background(), tx))
+	collateAndMerge(t, db, tx, d, totalTx)
+	dc.Close()
+
+	dc = d.BeginFilesRo()
+	defer dc.Close()
+	require.NotEmpty(dc.files)
+	for _, f := range dc.files {
+		require.NotNil(f.src.existence)
+	}
+
+	v, _, ok, err := dc.GetLatest([]byte("key7"), tx)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal([]byte("value7"), v)
+
+	skipped := mxsKVExistenceSkip[kv.AccountsDomain].GetValueUint64()
+	misses := 0
+	for i := 0; i < 100; i++ {
+		_, _, ok, err := dc.GetLatest([]byte(fmt.Sprintf("missing%d", i)), tx)
+		require.NoError(err)
+		require.False(ok)
+		misses++
+	}
+	// every file is probed per miss, the filters must have skipped most of them
+	require.Greater(mxsKVExistenceSkip[kv.AccountsDomain].GetValueUint64()-skipped, uint64(misses*len(dc.files)/2))
+}
+
 func TestDomainRange(t *testing.T) {
 	db, d := testDbAndDomainOfStep(t, 25, log.New())
 	require, ctx := require.New(t), context.Background()
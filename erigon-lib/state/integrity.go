@@ -83,6 +83,11 @@ func (ac *AggregatorRoTx) IntegrityInvertedIndexAllValuesAreInRange(ctx context.
 		if err != nil {
 			return err
 		}
+	case kv.WithdrawalAddrIdx:
+		err := ac.iis[kv.WithdrawalAddrIdxPos].IntegrityInvertedIndexAllValuesAreInRange(ctx, failFast, fromStep)
+		if err != nil {
+			return err
+		}
 	default:
 		panic(fmt.Sprintf("unexpected: %s", name))
 	}
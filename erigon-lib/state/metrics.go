@@ -116,3 +116,22 @@ var (
 		},
 	}
 )
+
+var (
+	// mxsKVExistenceSkip counts the files GetLatest didn't read because their existence filter doesn't contain the key
+	mxsKVExistenceSkip = [kv.DomainLen]metrics.Counter{
+		kv.AccountsDomain:   metrics.GetOrCreateCounter(`kv_existence{result="skip",domain="account"}`),
+		kv.StorageDomain:    metrics.GetOrCreateCounter(`kv_existence{result="skip",domain="storage"}`),
+		kv.CodeDomain:       metrics.GetOrCreateCounter(`kv_existence{result="skip",domain="code"}`),
+		kv.CommitmentDomain: metrics.GetOrCreateCounter(`kv_existence{result="skip",domain="commitment"}`),
+		kv.ReceiptDomain:    metrics.GetOrCreateCounter(`kv_existence{result="skip",domain="receipt"}`),
+	}
+	// mxsKVExistenceFalsePositive counts the files read in vain: their existence filter contains the key, but the file doesn't
+	mxsKVExistenceFalsePositive = [kv.DomainLen]metrics.Counter{
+		kv.AccountsDomain:   metrics.GetOrCreateCounter(`kv_existence{result="false_positive",domain="account"}`),
+		kv.StorageDomain:    metrics.GetOrCreateCounter(`kv_existence{result="false_positive",domain="storage"}`),
+		kv.CodeDomain:       metrics.GetOrCreateCounter(`kv_existence{result="false_positive",domain="code"}`),
+		kv.CommitmentDomain: metrics.GetOrCreateCounter(`kv_existence{result="false_positive",domain="commitment"}`),
+		kv.ReceiptDomain:    metrics.GetOrCreateCounter(`kv_existence{result="false_positive",domain="receipt"}`),
+	}
+)
@@ -136,7 +136,7 @@ func (ac *AggregatorRoTx) SqueezeCommitmentFiles() error {
 				aggStep: ac.a.StepSize(),
 			},
 		},
-		invertedIndex: [4]*MergeRange{},
+		invertedIndex: [kv.StandaloneIdxLen]*MergeRange{},
 	}
 	sf, err := ac.staticFilesInRange(rng)
 	if err != nil {
@@ -336,7 +336,7 @@ func (a *Aggregator) RebuildCommitmentFiles(ctx context.Context, rwDb kv.RwDB, t
 				aggStep: a.StepSize(),
 			},
 		},
-		invertedIndex: [4]*MergeRange{},
+		invertedIndex: [kv.StandaloneIdxLen]*MergeRange{},
 	}
 	sf, err := acRo.staticFilesInRange(rng)
 	if err != nil {
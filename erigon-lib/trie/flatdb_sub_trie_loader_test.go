@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/stretchr/testify/assert"
 
@@ -56,8 +57,8 @@ func TestCreateLoadingPrefixes(t *testing.T) {
 	tr.Hash()
 
 	// Evict accounts only
-	tr.EvictNode(keybytesToHex(kAcc1))
-	tr.EvictNode(keybytesToHex(kAcc2))
+	tr.EvictNode(nibbles.KeybytesToHex(kAcc1))
+	tr.EvictNode(nibbles.KeybytesToHex(kAcc2))
 	rs := NewRetainList(0)
 	rs.AddKey(concat(concat(kAcc1, kInc...), ks1...))
 	rs.AddKey(concat(concat(kAcc2, kInc...), ks2...))
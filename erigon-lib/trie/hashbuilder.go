@@ -28,6 +28,7 @@ import (
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	length2 "github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/crypto"
 
 	"github.com/erigontech/erigon-lib/rlp"
@@ -129,7 +130,7 @@ func (hb *HashBuilder) leafHashWithKeyVal(key []byte, val rlphacks.RlpSerializab
 	var compactLen int
 	var ni int
 	var compact0 byte
-	if hasTerm(key) {
+	if nibbles.HasTerm(key) {
 		compactLen = (len(key)-1)/2 + 1
 		if len(key)&1 == 0 {
 			compact0 = 0x30 + key[0] // Odd: (3<<4) + first nibble
@@ -342,7 +343,7 @@ func (hb *HashBuilder) accountLeafHashWithKey(key []byte, popped int) error {
 	var compactLen int
 	var ni int
 	var compact0 byte
-	if hasTerm(key) {
+	if nibbles.HasTerm(key) {
 		compactLen = (len(key)-1)/2 + 1
 		if len(key)&1 == 0 {
 			compact0 = 48 + key[0] // Odd (1<<4) + first nibble
@@ -430,7 +431,7 @@ func (hb *HashBuilder) extensionHash(key []byte) error {
 	var ni int
 	var compact0 byte
 	// https://github.com/ethereum/wiki/wiki/Patricia-Tree#specification-compact-encoding-of-hex-sequence-with-optional-terminator
-	if hasTerm(key) {
+	if nibbles.HasTerm(key) {
 		compactLen = (len(key)-1)/2 + 1
 		if len(key)&1 == 0 {
 			compact0 = 0x30 + key[0] // Odd: (3<<4) + first nibble
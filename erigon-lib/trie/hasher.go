@@ -28,6 +28,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/length"
 	"golang.org/x/crypto/sha3"
 
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/rlphacks"
@@ -162,7 +163,7 @@ func (h *hasher) hashChildren(original Node, bufOffset int) ([]byte, error) {
 	case *ShortNode:
 		// Starting at position 3, to leave space for len prefix
 		// Encode key
-		compactKey := hexToCompact(n.Key)
+		compactKey := nibbles.HexToCompact(n.Key)
 		h.bw.Setup(buffer, pos)
 		written, err := rlphacks.EncodeByteArrayAsRlp(compactKey, h.bw, h.prefixBuf[:])
 		if err != nil {
@@ -24,6 +24,7 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types/accounts"
@@ -41,7 +42,7 @@ func (t *Trie) Prove(key []byte, fromLevel int, storage bool) ([][]byte, error)
 	hasher := newHasher(t.valueNodesRLPEncoded)
 	defer returnHasherToPool(hasher)
 	// Collect all nodes on the path to key.
-	key = keybytesToHex(key)
+	key = nibbles.KeybytesToHex(key)
 	key = key[:len(key)-1] // Remove terminator
 	tn := t.RootNode
 	for len(key) > 0 && tn != nil {
@@ -235,7 +236,7 @@ func proofMap(proof []hexutility.Bytes) (map[libcommon.Hash]Node, map[libcommon.
 
 func verifyProof(root libcommon.Hash, key []byte, proofs map[libcommon.Hash]Node, used map[libcommon.Hash]rawProofElement) ([]byte, error) {
 	nextIndex := 0
-	key = keybytesToHex(key)
+	key = nibbles.KeybytesToHex(key)
 	var node Node = HashNode{hash: root[:]}
 	for {
 		switch nt := node.(type) {
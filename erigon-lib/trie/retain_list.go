@@ -34,6 +34,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon-lib/common/length"
 
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/types/accounts"
 )
 
@@ -312,14 +313,10 @@ func (rl *RetainList) AddKey(key []byte) []byte {
 }
 
 func (rl *RetainList) AddKeyWithMarker(key []byte, marker bool) []byte {
-	var nibbles = make([]byte, 2*len(key))
-	for i, b := range key {
-		nibbles[i*2] = b / 16
-		nibbles[i*2+1] = b % 16
-	}
-	rl.AddHex(nibbles)
+	hex := nibbles.FromBytes(key)
+	rl.AddHex(hex)
 	rl.markers = append(rl.markers, marker)
-	return nibbles
+	return hex
 }
 
 // AddHex adds a new key (in HEX encoding) to the list
@@ -32,6 +32,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/crypto"
 
 	"github.com/erigontech/erigon-lib/common"
@@ -171,7 +172,7 @@ func TestV2Resolution(t *testing.T) {
 	}
 	// Check the availability of the resolved keys
 	for _, hex := range rl.hexes {
-		key := hexToKeybytes(hex)
+		key := nibbles.HexToKeybytes(hex)
 		_, found := tr1.Get(key)
 		if !found {
 			t.Errorf("Key %x was not resolved", hex)
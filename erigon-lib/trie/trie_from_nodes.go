@@ -0,0 +1,123 @@
+package trie
+
+import (
+	"fmt"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types/accounts"
+)
+
+// BuildTrieFromNodes builds the partial state trie rooted at root out of rlp encoded account and storage trie nodes,
+// as found in execution witnesses. Subtries whose nodes are not provided are kept as hash nodes, and the code of the
+// accounts is attached if it is among codes. The root of the resulting trie is checked against root.
+func BuildTrieFromNodes(root libcommon.Hash, nodes [][]byte, codes [][]byte) (*Trie, error) {
+	if root == EmptyRoot {
+		return New(EmptyRoot), nil
+	}
+	b := &nodesTrieBuilder{
+		nodes: make(map[libcommon.Hash][]byte, len(nodes)),
+		codes: make(map[libcommon.Hash][]byte, len(codes)),
+	}
+	for _, node := range nodes {
+		b.nodes[crypto.Keccak256Hash(node)] = node
+	}
+	for _, code := range codes {
+		b.codes[crypto.Keccak256Hash(code)] = code
+	}
+	if _, ok := b.nodes[root]; !ok {
+		return nil, fmt.Errorf("root node %x is missing", root)
+	}
+	rootNode, err := b.resolve(NewHashNode(libcommon.CopyBytes(root[:])), true)
+	if err != nil {
+		return nil, err
+	}
+	t := New(root)
+	t.RootNode = rootNode
+	if h := t.Hash(); h != root {
+		return nil, fmt.Errorf("trie built from nodes has root %x, expected %x", h, root)
+	}
+	return t, nil
+}
+
+type nodesTrieBuilder struct {
+	nodes map[libcommon.Hash][]byte
+	codes map[libcommon.Hash][]byte
+}
+
+func (b *nodesTrieBuilder) resolve(n Node, accountTrie bool) (Node, error) {
+	switch n := n.(type) {
+	case *HashNode:
+		encoded, ok := b.nodes[libcommon.BytesToHash(n.hash)]
+		if !ok {
+			// not accessed during the execution, only its hash is known
+			return n, nil
+		}
+		decoded, err := decodeNode(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return b.resolve(decoded, accountTrie)
+	case HashNode:
+		return b.resolve(&n, accountTrie)
+	case *FullNode:
+		for i := 0; i < 16; i++ {
+			if n.Children[i] == nil {
+				continue
+			}
+			child, err := b.resolve(n.Children[i], accountTrie)
+			if err != nil {
+				return nil, err
+			}
+			n.Children[i] = child
+		}
+		return n, nil
+	case *ShortNode:
+		if value, ok := n.Val.(ValueNode); ok {
+			if accountTrie {
+				account, err := b.account(value)
+				if err != nil {
+					return nil, err
+				}
+				n.Val = account
+				return n, nil
+			}
+			// storage values are rlp encoded in the leaves but kept raw in the trie
+			raw, _, err := rlp.SplitString(value)
+			if err != nil {
+				return nil, err
+			}
+			n.Val = ValueNode(raw)
+			return n, nil
+		}
+		child, err := b.resolve(n.Val, accountTrie)
+		if err != nil {
+			return nil, err
+		}
+		n.Val = child
+		return n, nil
+	default:
+		return nil, fmt.Errorf("unexpected node type %T", n)
+	}
+}
+
+func (b *nodesTrieBuilder) account(encoded []byte) (*AccountNode, error) {
+	var account accounts.Account
+	if err := account.DecodeForHashing(encoded); err != nil {
+		return nil, err
+	}
+	accountNode := &AccountNode{Account: account, RootCorrect: true, CodeSize: codeSizeUncached}
+	if account.Root != EmptyRoot {
+		storage, err := b.resolve(NewHashNode(libcommon.CopyBytes(account.Root[:])), false)
+		if err != nil {
+			return nil, err
+		}
+		accountNode.Storage = storage
+	}
+	if code, ok := b.codes[account.CodeHash]; ok {
+		accountNode.Code = code
+		accountNode.CodeSize = len(code)
+	}
+	return accountNode, nil
+}
@@ -0,0 +1,90 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv/dbutils"
+	"github.com/erigontech/erigon-lib/types/accounts"
+)
+
+func TestBuildTrieFromNodes(t *testing.T) {
+	code := []byte{0x60, 0x01, 0x60, 0x02}
+	codeHash := crypto.Keccak256Hash(code)
+
+	full := New(EmptyRoot)
+	var addrHashes []libcommon.Hash
+	for i := byte(1); i <= 32; i++ {
+		addrHash := crypto.Keccak256Hash([]byte{i})
+		addrHashes = append(addrHashes, addrHash)
+		acc := accounts.NewAccount()
+		acc.Nonce = uint64(i)
+		acc.Balance = *uint256.NewInt(uint64(i) * 1000)
+		full.UpdateAccount(addrHash[:], &acc)
+	}
+
+	// the first account is a contract with some storage
+	contract := addrHashes[0]
+	var slots []libcommon.Hash
+	for i := byte(1); i <= 8; i++ {
+		slot := crypto.Keccak256Hash([]byte{0xff, i})
+		slots = append(slots, slot)
+		full.Update(dbutils.GenerateCompositeTrieKey(contract, slot), []byte{i, i})
+	}
+	ok, storageRoot := full.DeepHash(contract[:])
+	require.True(t, ok)
+	acc, ok := full.GetAccount(contract[:])
+	require.True(t, ok)
+	acc.CodeHash = codeHash
+	acc.Root = storageRoot
+	full.UpdateAccount(contract[:], acc)
+	root := full.Hash()
+
+	// only the contract, one of its slots and another account are part of the witness
+	var nodes [][]byte
+	for _, proofKey := range [][]byte{contract[:], dbutils.GenerateCompositeTrieKey(contract, slots[3]), addrHashes[7][:]} {
+		proof, err := full.Prove(proofKey, 0, len(proofKey) > 32)
+		require.NoError(t, err)
+		nodes = append(nodes, proof...)
+	}
+
+	partial, err := BuildTrieFromNodes(root, nodes, [][]byte{code})
+	require.NoError(t, err)
+	require.Equal(t, root, partial.Hash())
+
+	got, ok := partial.GetAccount(addrHashes[7][:])
+	require.True(t, ok)
+	require.Equal(t, uint64(8), got.Nonce)
+	got, ok = partial.GetAccount(contract[:])
+	require.True(t, ok)
+	require.Equal(t, storageRoot, got.Root)
+	gotCode, ok := partial.GetAccountCode(contract[:])
+	require.True(t, ok)
+	require.Equal(t, code, []byte(gotCode))
+
+	value, ok := partial.Get(dbutils.GenerateCompositeTrieKey(contract, slots[3]))
+	require.True(t, ok)
+	require.Equal(t, []byte{4, 4}, value)
+
+	// accounts which are not part of the witness can not be read
+	_, ok = partial.GetAccount(addrHashes[20][:])
+	require.False(t, ok)
+
+	// modifying the partial trie gives the same root as modifying the full one
+	for _, tr := range []*Trie{full, partial} {
+		tr.Update(dbutils.GenerateCompositeTrieKey(contract, slots[3]), []byte{9})
+		_, storageRoot := tr.DeepHash(contract[:])
+		acc, _ := tr.GetAccount(contract[:])
+		acc.Root = storageRoot
+		acc.Nonce++
+		tr.UpdateAccount(contract[:], acc)
+	}
+	require.Equal(t, full.Hash(), partial.Hash())
+
+	_, err = BuildTrieFromNodes(libcommon.Hash{1}, nodes, nil)
+	require.Error(t, err)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types/accounts"
@@ -397,7 +398,7 @@ func TestCodeNodeGetHashedAccount(t *testing.T) {
 	fakeAccount := genRandomByteArrayOfLen(50)
 	fakeAccountHash := common.BytesToHash(crypto.Keccak256(fakeAccount))
 
-	hex := keybytesToHex(crypto.Keccak256(address[:]))
+	hex := nibbles.KeybytesToHex(crypto.Keccak256(address[:]))
 
 	_, trie.RootNode = trie.insert(trie.RootNode, hex, &HashNode{hash: fakeAccountHash[:]})
 
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/nibbles"
 	"github.com/ugorji/go/codec"
 )
 
@@ -67,7 +68,7 @@ func (l *OperatorUnmarshaller) ReadKey() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return keyBytesToNibbles(b), nil
+	return nibbles.WitnessKeyToHex(b), nil
 }
 
 func (l *OperatorUnmarshaller) ReadUInt64() (uint64, error) {
@@ -106,7 +107,7 @@ func (w *OperatorMarshaller) WriteOpCode(opcode OperatorKindCode) error {
 
 func (w *OperatorMarshaller) WriteKey(keyNibbles []byte) error {
 	w.WithColumn(ColumnLeafKeys)
-	return w.encoder.Encode(keyNibblesToBytes(keyNibbles))
+	return w.encoder.Encode(nibbles.HexToWitnessKey(keyNibbles))
 }
 
 func (w *OperatorMarshaller) WriteByteValue(value byte) error {
@@ -160,65 +161,3 @@ func (w *OperatorMarshaller) GetStats() *BlockWitnessStats {
 		stats:       w.stats,
 	}
 }
-
-func keyNibblesToBytes(nibbles []byte) []byte {
-	if len(nibbles) < 1 {
-		return []byte{}
-	}
-	if len(nibbles) < 2 {
-		return nibbles
-	}
-	hasTerminator := false
-	if nibbles[len(nibbles)-1] == 0x10 {
-		nibbles = nibbles[:len(nibbles)-1]
-		hasTerminator = true
-	}
-
-	targetLen := len(nibbles)/2 + len(nibbles)%2 + 1
-
-	result := make([]byte, targetLen)
-	nibbleIndex := 0
-	result[0] = byte(len(nibbles) % 2) // parity bit
-	for i := 1; i < len(result); i++ {
-		result[i] = nibbles[nibbleIndex] * 16
-		nibbleIndex++
-		if nibbleIndex < len(nibbles) {
-			result[i] += nibbles[nibbleIndex]
-			nibbleIndex++
-		}
-	}
-	if hasTerminator {
-		result[0] |= 1 << 1
-	}
-
-	return result
-}
-
-func keyBytesToNibbles(b []byte) []byte {
-	if len(b) < 1 {
-		return []byte{}
-	}
-	if len(b) < 2 {
-		return b
-	}
-
-	hasTerminator := b[0]&(1<<1) != 0
-
-	targetLen := (len(b)-1)*2 - int(b[0]&1)
-
-	nibbles := make([]byte, targetLen)
-
-	nibbleIndex := 0
-	for i := 1; i < len(b); i++ {
-		nibbles[nibbleIndex] = b[i] / 16
-		nibbleIndex++
-		if nibbleIndex < len(nibbles) {
-			nibbles[nibbleIndex] = b[i] % 16
-			nibbleIndex++
-		}
-	}
-	if hasTerminator {
-		return append(nibbles, 0x10)
-	}
-	return nibbles
-}
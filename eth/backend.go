@@ -45,6 +45,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/erigontech/erigon-lib/app"
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/chain/networkname"
 	"github.com/erigontech/erigon-lib/chain/snapcfg"
@@ -63,6 +64,7 @@ import (
 	"github.com/erigontech/erigon-lib/downloader/downloadercfg"
 	"github.com/erigontech/erigon-lib/downloader/downloadergrpc"
 	"github.com/erigontech/erigon-lib/downloader/snaptype"
+	"github.com/erigontech/erigon-lib/gointerfaces"
 	protodownloader "github.com/erigontech/erigon-lib/gointerfaces/downloaderproto"
 	"github.com/erigontech/erigon-lib/gointerfaces/grpcutil"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
@@ -83,6 +85,7 @@ import (
 	executionclient "github.com/erigontech/erigon/cl/phase1/execution_client"
 	"github.com/erigontech/erigon/cmd/caplin/caplin1"
 	"github.com/erigontech/erigon/cmd/rpcdaemon/cli"
+	"github.com/erigontech/erigon/cmd/state/exec3"
 	"github.com/erigontech/erigon/consensus"
 	"github.com/erigontech/erigon/consensus/clique"
 	"github.com/erigontech/erigon/consensus/ethash"
@@ -96,6 +99,7 @@ import (
 	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/eth/consensuschain"
 	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/eth/ethconfig/estimate"
 	"github.com/erigontech/erigon/eth/ethconsensusconfig"
 	"github.com/erigontech/erigon/eth/protocols/eth"
 	"github.com/erigontech/erigon/eth/stagedsync"
@@ -121,6 +125,7 @@ import (
 	"github.com/erigontech/erigon/turbo/engineapi"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_block_downloader"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_helpers"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
 	"github.com/erigontech/erigon/turbo/execution/eth1"
 	"github.com/erigontech/erigon/turbo/execution/eth1/eth1_chain_reader"
 	"github.com/erigontech/erigon/turbo/jsonrpc"
@@ -131,6 +136,7 @@ import (
 	stages2 "github.com/erigontech/erigon/turbo/stages"
 	"github.com/erigontech/erigon/turbo/stages/headerdownload"
 	"github.com/erigontech/erigon/txnprovider"
+	"github.com/erigontech/erigon/txnprovider/bundle"
 	"github.com/erigontech/erigon/txnprovider/shutter"
 	"github.com/erigontech/erigon/txnprovider/txpool"
 	"github.com/erigontech/erigon/txnprovider/txpool/txpoolcfg"
@@ -166,6 +172,7 @@ type Ethereum struct {
 
 	ethBackendRPC      *privateapi.EthBackendServer
 	engineBackendRPC   *engineapi.EngineServer
+	engineJournal      *engine_journal.Journal
 	miningRPC          txpoolproto.MiningServer
 	stateChangesClient txpool.StateChangesClient
 
@@ -200,6 +207,8 @@ type Ethereum struct {
 	txPoolSend              *txpool.Send
 	txPoolGrpcServer        txpoolproto.TxpoolServer
 	shutterPool             *shutter.Pool
+	bundlePool              *bundle.Pool
+	prefetcher              *exec3.Prefetcher
 	notifyMiningAboutNewTxs chan struct{}
 	forkValidator           *engine_helpers.ForkValidator
 	downloader              *downloader.Downloader
@@ -646,6 +655,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	}
 
 	var txnProvider txnprovider.TxnProvider
+	var txnsValidator txnprovider.BlockTxnsValidator
 	var miningRPC txpoolproto.MiningServer
 	stateDiffClient := direct.NewStateDiffClientDirect(kvRPC)
 	if config.TxPool.Disable {
@@ -659,13 +669,32 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			return nil, err
 		}
 		txnProvider = backend.txPool
+		if config.Sync.TxPoolPrefetch {
+			backend.prefetcher = exec3.NewPrefetcher(backend.chainDB, backend.txPool, config.Sync.ExecWorkerCount, logger)
+		}
 	}
 	if config.Shutter.Enabled {
 		if config.TxPool.Disable {
 			panic("can't enable shutter pool when devp2p txpool is disabled")
 		}
-		backend.shutterPool = shutter.NewPool(logger, config.Shutter, txnProvider)
+		// the decrypter follows the submissions to the sequencer contract through an in-process eth API
+		shutterLogsReader := jsonrpc.NewEthAPI(jsonrpc.NewBaseApi(nil, kvcache.NewDummy(), blockReader, false, 0, backend.engine, dirs, nil), backend.chainDB, nil, nil, nil, 0, 0, 0, false, 0, 0, logger)
+		backend.shutterPool = shutter.NewPool(
+			logger,
+			config.Shutter,
+			txnProvider,
+			shutter.NewKeyperNetworkSource(logger, config.Shutter),
+			shutter.NewSequencerDecrypter(logger, config.Shutter, chainConfig, shutterLogsReader),
+		)
 		txnProvider = backend.shutterPool
+		txnsValidator = backend.shutterPool
+	}
+	if config.Bundle.Enabled {
+		if config.TxPool.Disable {
+			panic("can't enable bundle pool when devp2p txpool is disabled")
+		}
+		backend.bundlePool = bundle.NewPool(logger, config.Bundle, txnProvider)
+		txnProvider = backend.bundlePool
 	}
 
 	backend.notifyMiningAboutNewTxs = make(chan struct{}, 1)
@@ -722,9 +751,10 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 				config.Genesis,
 				config.Sync,
 				stages2.SilkwormForExecutionStage(backend.silkworm, config),
+				nil,
 			),
 			stagedsync.StageSendersCfg(backend.chainDB, chainConfig, config.Sync, false, dirs.Tmp, config.Prune, blockReader, backend.sentriesClient.Hd),
-			stagedsync.StageMiningExecCfg(backend.chainDB, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, nil, 0, txnProvider, blockReader),
+			stagedsync.StageMiningExecCfg(backend.chainDB, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, nil, 0, txnProvider, txnsValidator, blockReader),
 			stagedsync.StageMiningFinishCfg(backend.chainDB, *backend.chainConfig, backend.engine, miner, backend.miningSealingQuit, backend.blockReader, latestBlockBuiltStore),
 		), stagedsync.MiningUnwindOrder, stagedsync.MiningPruneOrder,
 		logger, stages.ModeBlockProduction)
@@ -773,9 +803,10 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 					config.Genesis,
 					config.Sync,
 					stages2.SilkwormForExecutionStage(backend.silkworm, config),
+					nil,
 				),
 				stagedsync.StageSendersCfg(backend.chainDB, chainConfig, config.Sync, false, dirs.Tmp, config.Prune, blockReader, backend.sentriesClient.Hd),
-				stagedsync.StageMiningExecCfg(backend.chainDB, miningStatePos, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, interrupt, param.PayloadId, txnProvider, blockReader),
+				stagedsync.StageMiningExecCfg(backend.chainDB, miningStatePos, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, interrupt, param.PayloadId, txnProvider, txnsValidator, blockReader),
 				stagedsync.StageMiningFinishCfg(backend.chainDB, *backend.chainConfig, backend.engine, miningStatePos, backend.miningSealingQuit, backend.blockReader, latestBlockBuiltStore)), stagedsync.MiningUnwindOrder, stagedsync.MiningPruneOrder, logger, stages.ModeBlockProduction)
 		// We start the mining step
 		if err := stages2.MiningStep(ctx, backend.chainDB, proposingSync, tmpdir, logger); err != nil {
@@ -922,7 +953,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		backend.syncPruneOrder = stagedsync.PolygonSyncPruneOrder
 	} else {
 		backend.syncStages = stages2.NewDefaultStages(backend.sentryCtx, backend.chainDB, snapDb, p2pConfig, config, backend.sentriesClient, backend.notifications, backend.downloaderClient,
-			blockReader, blockRetire, backend.silkworm, backend.forkValidator, heimdallClient, heimdallStore, bridgeStore, recents, signatures, logger)
+			blockReader, blockRetire, backend.silkworm, backend.prefetcher, backend.forkValidator, heimdallClient, heimdallStore, bridgeStore, recents, signatures, logger)
 		backend.syncUnwindOrder = stagedsync.DefaultUnwindOrder
 		backend.syncPruneOrder = stagedsync.DefaultPruneOrder
 	}
@@ -954,17 +985,23 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	}
 
 	checkStateRoot := true
-	pipelineStages := stages2.NewPipelineStages(ctx, backend.chainDB, config, p2pConfig, backend.sentriesClient, backend.notifications, backend.downloaderClient, blockReader, blockRetire, backend.silkworm, backend.forkValidator, logger, checkStateRoot)
+	pipelineStages := stages2.NewPipelineStages(ctx, backend.chainDB, config, p2pConfig, backend.sentriesClient, backend.notifications, backend.downloaderClient, blockReader, blockRetire, backend.silkworm, backend.prefetcher, backend.forkValidator, logger, checkStateRoot)
 	backend.pipelineStagedSync = stagedsync.New(config.Sync, pipelineStages, stagedsync.PipelineUnwindOrder, stagedsync.PipelinePruneOrder, logger, stages.ModeApplyingBlocks)
 	backend.eth1ExecutionServer = eth1.NewEthereumExecutionModule(blockReader, backend.chainDB, backend.pipelineStagedSync, backend.forkValidator, chainConfig, assembleBlockPOS, hook, backend.notifications.Accumulator, backend.notifications.StateChangesConsumer, logger, backend.engine, config.Sync, ctx)
 	executionRpc := direct.NewExecutionClientDirect(backend.eth1ExecutionServer)
 
-	var executionEngine executionclient.ExecutionEngine
-
-	executionEngine, err = executionclient.NewExecutionClientDirect(eth1_chain_reader.NewChainReaderEth1(chainConfig, executionRpc, 1000))
+	executionEngine, err := executionclient.NewExecutionClientDirect(eth1_chain_reader.NewChainReaderEth1(chainConfig, executionRpc, 1000))
 	if err != nil {
 		return nil, err
 	}
+	// the embedded CL skips the engine API, its calls are journaled by the execution client it uses instead.
+	if config.EngineJournal.Enabled() {
+		backend.engineJournal, err = engine_journal.NewJournal(config.EngineJournal, logger)
+		if err != nil {
+			return nil, err
+		}
+		executionEngine.SetJournal(backend.engineJournal)
+	}
 
 	engineBackendRPC := engineapi.NewEngineServer(
 		logger,
@@ -979,6 +1016,12 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		false,
 		config.Miner.EnabledPOS)
 	backend.engineBackendRPC = engineBackendRPC
+	if backend.engineJournal != nil {
+		engineBackendRPC.SetJournal(backend.engineJournal)
+	}
+	if txnsValidator != nil {
+		engineBackendRPC.SetBlockTxnsValidator(txnsValidator)
+	}
 	// If we choose not to run a consensus layer, run our embedded.
 	if config.InternalCL && (clparams.EmbeddedSupported(config.NetworkID) || config.CaplinConfig.IsDevnet()) {
 		config.CaplinConfig.NetworkId = clparams.NetworkType(config.NetworkID)
@@ -1004,6 +1047,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			polygonBridge,
 			heimdallService,
 			backend.notifications,
+			config.BorUnsafeUnwind,
 		)
 
 		// we need to initiate download before the heimdall services start rather than
@@ -1085,7 +1129,7 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 
 	//eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
 	if config.Ethstats != "" {
-		var headCh chan [][]byte
+		var headCh <-chan [][]byte
 		headCh, s.unsubscribeEthstat = s.notifications.Events.AddHeaderSubscription()
 		if err := ethstats.New(stack, s.sentryServers, chainKv, s.blockReader, s.engine, config.Ethstats, s.networkID, ctx.Done(), headCh, txPoolRpcClient); err != nil {
 			return err
@@ -1093,6 +1137,9 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 	}
 
 	s.apiList = jsonrpc.APIList(chainKv, ethRpcClient, txPoolRpcClient, miningRpcClient, ff, stateCache, blockReader, &httpRpcCfg, s.engine, s.logger, s.polygonBridge, s.heimdallService)
+	if s.bundlePool != nil {
+		s.apiList = append(s.apiList, s.bundlePool.APIs(chainConfig.ChainID)...)
+	}
 
 	if config.SilkwormRpcDaemon && httpRpcCfg.Enabled {
 		interface_log_settings := silkworm.RpcInterfaceLogSettings{
@@ -1261,6 +1308,11 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, stateDiffClient
 		hasWork := true // Start mining immediately
 		errc := make(chan error, 1)
 
+		// Bor: the block is built shortly before the producer slot of the node rather than right on the parent, and
+		// not at all if the node isn't a producer of the block
+		var buildAt <-chan time.Time
+		var slotPending, slotDue bool
+
 		for {
 			// Only reset if some work was done previously as we'd like to rely
 			// on the `miner.recommit` as backup.
@@ -1278,6 +1330,21 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, stateDiffClient
 					// TODO - can do mining clean up here as we have previous
 					// block info in the state channel
 					hasWork = true
+					buildAt, slotPending = nil, false
+					if borcfg != nil {
+						delay, producer, err := s.borBuildDelay(ctx, db, borcfg, stateChanges, miner.MiningConfig.BuildLead)
+						switch {
+						case err != nil:
+							s.logger.Warn("[bor] Cannot find the producer slot, building the block now", "block", block+1, "err", err)
+						case !producer:
+							s.logger.Debug("[bor] Not a producer of the block", "block", block+1)
+							hasWork, slotPending = false, true
+						case delay > 0:
+							s.logger.Debug("[bor] Building the block before the producer slot", "block", block+1, "in", delay)
+							buildAt = time.After(delay)
+							hasWork, slotPending = false, true
+						}
+					}
 
 				case <-s.notifyMiningAboutNewTxs:
 					//log.Warn("[dbg] notifyMiningAboutNewTxs")
@@ -1292,10 +1359,15 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, stateDiffClient
 					if !(working || waiting.Load()) {
 						s.logger.Debug("Start mining based on miner.recommit", "duration", miner.MiningConfig.Recommit)
 					}
-					hasWork = !(working || waiting.Load())
+					hasWork = !(working || waiting.Load() || slotPending)
+				case <-buildAt:
+					s.logger.Debug("[bor] Start mining for the producer slot")
+					buildAt, slotPending = nil, false
+					// the step building on the previous block may still be running, the slot is not to be missed
+					hasWork, slotDue = true, true
 				case err := <-errc:
 					working = false
-					hasWork = false
+					hasWork = slotDue
 					if errors.Is(err, libcommon.ErrStopped) {
 						return
 					}
@@ -1310,6 +1382,7 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, stateDiffClient
 			if !working && hasWork {
 				working = true
 				hasWork = false
+				slotDue = false
 				mineEvery.Reset(miner.MiningConfig.Recommit)
 				go func() {
 					err = stages2.MiningStep(ctx, db, mining, tmpDir, logger)
@@ -1345,6 +1418,28 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, stateDiffClient
 	return nil
 }
 
+// borBuildDelay returns how long to wait before building the block on top of the given one, for the building to start
+// lead before the producer slot of the node. producer is false if the node is not a producer of the block.
+func (s *Ethereum) borBuildDelay(ctx context.Context, db kv.RoDB, engine *bor.Bor, change *remote.StateChange, lead time.Duration) (delay time.Duration, producer bool, err error) {
+	err = db.View(ctx, func(tx kv.Tx) error {
+		parent, err := s.blockReader.Header(ctx, tx, gointerfaces.ConvertH256ToHash(change.BlockHash), change.BlockHeight)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return fmt.Errorf("header %d not found", change.BlockHeight)
+		}
+		chainReader := stagedsync.ChainReader{Cfg: *s.chainConfig, Db: tx, BlockReader: s.blockReader, Logger: s.logger}
+		slot, _, ok, err := engine.ProducerSlot(chainReader, parent)
+		if err != nil || !ok {
+			return err
+		}
+		delay, producer = time.Until(slot.Add(-lead)), true
+		return nil
+	})
+	return delay, producer, err
+}
+
 func (s *Ethereum) IsMining() bool { return s.config.Miner.Enabled }
 
 func (s *Ethereum) ChainKV() kv.RwDB            { return s.chainDB }
@@ -1422,6 +1517,7 @@ func (s *Ethereum) setUpSnapDownloader(ctx context.Context, downloaderCfg *downl
 	s.chainDB.OnFreeze(func(frozenFileNames []string) {
 		events := s.notifications.Events
 		events.OnNewSnapshot()
+		app.PublishEvent(app.SnapshotBuilt{Files: frozenFileNames})
 		if s.downloaderClient != nil {
 			req := &protodownloader.AddRequest{Items: make([]*protodownloader.AddItem, 0, len(frozenFileNames))}
 			for _, fName := range frozenFileNames {
@@ -1470,6 +1566,7 @@ func setUpBlockReader(ctx context.Context, db kv.RwDB, dirs datadir.Dirs, snConf
 	}
 	agg.SetSnapshotBuildSema(blockSnapBuildSema)
 	agg.SetProduceMod(snConfig.Snapshot.ProduceE3)
+	agg.SetCommitmentWarmup(snConfig.Sync.CommitmentWarmup)
 
 	allSegmentsDownloadComplete, err := rawdb.AllSegmentsDownloadCompleteFromDB(db)
 	if err != nil {
@@ -1481,6 +1578,7 @@ func setUpBlockReader(ctx context.Context, db kv.RwDB, dirs datadir.Dirs, snConf
 			allBorSnapshots.OptimisticalyOpenFolder()
 		}
 		_ = agg.OpenFolder()
+		agg.WarmupHotFilesInBackground(snConfig.Sync.WarmupSteps, estimate.AlmostAllCPUs())
 	} else {
 		logger.Debug("[rpc] download of segments not complete yet. please wait StageSnapshots to finish")
 	}
@@ -1513,6 +1611,11 @@ func (s *Ethereum) AddPeer(ctx context.Context, req *remote.AddPeerRequest) (*re
 	return &remote.AddPeerReply{Success: true}, nil
 }
 
+// BlockPropagation returns the propagation latency of the blocks announced by the peers and the slowest peers
+func (s *Ethereum) BlockPropagation(limit int) *sentry_multi_client.BlockPropagationStats {
+	return s.sentriesClient.Propagation.Stats(limit)
+}
+
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
@@ -1526,6 +1629,7 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 // Start implements node.Lifecycle, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
 func (s *Ethereum) Start() error {
+	go app.RunEventMetrics(s.sentryCtx, app.DefaultEventBus())
 	s.sentriesClient.StartStreamLoops(s.sentryCtx)
 	time.Sleep(10 * time.Millisecond) // just to reduce logs order confusion
 
@@ -1602,6 +1706,10 @@ func (s *Ethereum) Start() error {
 		s.bgComponentsEg.Go(func() error { return s.shutterPool.Run(s.sentryCtx) })
 	}
 
+	if s.prefetcher != nil {
+		s.bgComponentsEg.Go(func() error { return s.prefetcher.Run(s.sentryCtx) })
+	}
+
 	return nil
 }
 
@@ -1643,6 +1751,9 @@ func (s *Ethereum) Stop() error {
 		s.txPoolDB.Close()
 	}
 	s.chainDB.Close()
+	if err := s.engineJournal.Close(); err != nil {
+		s.logger.Error("engineJournal.Close error", "err", err)
+	}
 
 	if s.silkwormRPCDaemonService != nil {
 		if err := s.silkwormRPCDaemonService.Stop(); err != nil {
@@ -43,6 +43,8 @@ import (
 	"github.com/erigontech/erigon/ethdb/prune"
 	"github.com/erigontech/erigon/params"
 	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
+	"github.com/erigontech/erigon/txnprovider/bundle"
 	"github.com/erigontech/erigon/txnprovider/shutter"
 	"github.com/erigontech/erigon/txnprovider/txpool/txpoolcfg"
 )
@@ -91,14 +93,17 @@ var Defaults = Config{
 	NetworkID: 1,
 	Prune:     prune.DefaultMode,
 	Miner: params.MiningConfig{
-		GasLimit: 36_000_000,
-		GasPrice: big.NewInt(params.GWei),
-		Recommit: 3 * time.Second,
+		GasLimit:  36_000_000,
+		GasPrice:  big.NewInt(params.GWei),
+		Recommit:  3 * time.Second,
+		BuildLead: time.Second,
 	},
-	TxPool:      txpoolcfg.DefaultConfig,
-	RPCGasCap:   50000000,
-	GPO:         FullNodeGPO,
-	RPCTxFeeCap: 1, // 1 ether
+	TxPool:        txpoolcfg.DefaultConfig,
+	Bundle:        bundle.DefaultConfig,
+	EngineJournal: engine_journal.DefaultConfig,
+	RPCGasCap:     50000000,
+	GPO:           FullNodeGPO,
+	RPCTxFeeCap:   1, // 1 ether
 
 	ImportMode: false,
 	Snapshot: BlocksFreezing{
@@ -213,6 +218,7 @@ type Config struct {
 	// Transaction pool options
 	TxPool  txpoolcfg.Config
 	Shutter shutter.Config
+	Bundle  bundle.Config
 
 	// Gas Price Oracle options
 	GPO gaspricecfg.Config
@@ -234,6 +240,8 @@ type Config struct {
 	WithHeimdallMilestones bool
 	// Heimdall waypoint recording active
 	WithHeimdallWaypointRecording bool
+	// Allow unwinding behind the latest verified milestone
+	BorUnsafeUnwind bool
 	// Use polygon checkpoint sync in preference to POW downloader
 	PolygonSync      bool
 	PolygonSyncStage bool
@@ -242,6 +250,8 @@ type Config struct {
 	Ethstats string
 	// Consensus layer
 	InternalCL bool
+	// Engine API requests journal
+	EngineJournal engine_journal.Config
 
 	OverridePragueTime *big.Int `toml:",omitempty"`
 
@@ -271,6 +281,11 @@ type Sync struct {
 	BreakAfterStage            string
 	LoopBlockLimit             uint
 	ParallelStateFlushing      bool
+	TxPoolPrefetch             bool   // warm the state read by the best txpool transactions while waiting for the next block
+	WarmupSteps                uint64 // load into the page cache the hot domain files of the latest steps at startup
+	CommitmentWarmup           bool   // read the trie ahead of the commitment computation
+	TxSenderIndex              bool   // index the transactions by sender, for erigon_getTransactionsBySender
+	BadBlocksKeep              uint   // number of the latest bad blocks quarantined into <datadir>/bad_blocks, 0 - disabled
 
 	UploadLocation   string
 	UploadFrom       rpc.BlockNumber
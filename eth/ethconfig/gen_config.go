@@ -16,6 +16,8 @@ import (
 	"github.com/erigontech/erigon/eth/gasprice/gaspricecfg"
 	"github.com/erigontech/erigon/ethdb/prune"
 	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
+	"github.com/erigontech/erigon/txnprovider/bundle"
 	"github.com/erigontech/erigon/txnprovider/shutter"
 	"github.com/erigontech/erigon/txnprovider/txpool/txpoolcfg"
 )
@@ -42,6 +44,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Aura                           chain.AuRaConfig
 		TxPool                         txpoolcfg.Config
 		Shutter                        shutter.Config
+		Bundle                         bundle.Config
 		GPO                            gaspricecfg.Config
 		RPCGasCap                      uint64  `toml:",omitempty"`
 		RPCTxFeeCap                    float64 `toml:",omitempty"`
@@ -54,6 +57,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		PolygonSyncStage               bool
 		Ethstats                       string
 		InternalCL                     bool
+		EngineJournal                  engine_journal.Config
 		OverridePragueTime             *big.Int `toml:",omitempty"`
 		SilkwormExecution              bool
 		SilkwormRpcDaemon              bool
@@ -88,6 +92,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Aura = c.Aura
 	enc.TxPool = c.TxPool
 	enc.Shutter = c.Shutter
+	enc.Bundle = c.Bundle
 	enc.GPO = c.GPO
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
@@ -100,6 +105,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.PolygonSyncStage = c.PolygonSyncStage
 	enc.Ethstats = c.Ethstats
 	enc.InternalCL = c.InternalCL
+	enc.EngineJournal = c.EngineJournal
 	enc.OverridePragueTime = c.OverridePragueTime
 	enc.SilkwormExecution = c.SilkwormExecution
 	enc.SilkwormRpcDaemon = c.SilkwormRpcDaemon
@@ -138,6 +144,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Aura                           *chain.AuRaConfig
 		TxPool                         *txpoolcfg.Config
 		Shutter                        *shutter.Config
+		Bundle                         *bundle.Config
 		GPO                            *gaspricecfg.Config
 		RPCGasCap                      *uint64  `toml:",omitempty"`
 		RPCTxFeeCap                    *float64 `toml:",omitempty"`
@@ -150,6 +157,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		PolygonSyncStage               *bool
 		Ethstats                       *string
 		InternalCL                     *bool
+		EngineJournal                  *engine_journal.Config
 		OverridePragueTime             *big.Int `toml:",omitempty"`
 		SilkwormExecution              *bool
 		SilkwormRpcDaemon              *bool
@@ -225,6 +233,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Shutter != nil {
 		c.Shutter = *dec.Shutter
 	}
+	if dec.Bundle != nil {
+		c.Bundle = *dec.Bundle
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -261,6 +272,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.InternalCL != nil {
 		c.InternalCL = *dec.InternalCL
 	}
+	if dec.EngineJournal != nil {
+		c.EngineJournal = *dec.EngineJournal
+	}
 	if dec.OverridePragueTime != nil {
 		c.OverridePragueTime = dec.OverridePragueTime
 	}
@@ -88,9 +88,12 @@ func MarshalReceipt(
 		fields["contractAddress"] = receipt.ContractAddress
 	}
 
-	// Set derived blob related fields
+	// Set blob related fields, derived from the header for the receipts which don't have them
 	numBlobs := len(txn.GetBlobHashes())
-	if numBlobs > 0 {
+	if receipt.BlobGasPrice != nil {
+		fields["blobGasPrice"] = (*hexutil.Big)(receipt.BlobGasPrice)
+		fields["blobGasUsed"] = hexutil.Uint64(receipt.BlobGasUsed)
+	} else if numBlobs > 0 {
 		if header.ExcessBlobGas == nil {
 			log.Warn("excess blob gas not set when trying to marshal blob tx")
 		} else {
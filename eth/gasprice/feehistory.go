@@ -53,7 +53,7 @@ type blockFees struct {
 	// set by the caller
 	blockNumber uint64
 	header      *types.Header
-	block       *types.Block // only set if reward percentiles are requested
+	block       *types.Block // only set if reward percentiles are requested or on bor chains
 	receipts    types.Receipts
 	// filled by processBlock
 	reward                       []*big.Int
@@ -61,6 +61,7 @@ type blockFees struct {
 	blobBaseFee, nextBlobBaseFee *big.Int
 	gasUsedRatio                 float64
 	blobGasUsedRatio             float64
+	priorityFeeFloor             *big.Int // bor only
 	err                          error
 }
 
@@ -120,6 +121,10 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 		bf.blobGasUsedRatio = float64(*blobGasUsed) / float64(chainconfig.GetMaxBlobGasPerBlock())
 	}
 
+	if chainconfig.Bor != nil && bf.block != nil {
+		bf.priorityFeeFloor = priorityFeeFloor(bf.block)
+	}
+
 	if len(percentiles) == 0 {
 		// rewards were not requested, return null
 		return
@@ -163,6 +168,25 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 	}
 }
 
+// priorityFeeFloor returns the lowest effective priority fee per gas the producer of the block has accepted,
+// zero for an empty block
+func priorityFeeFloor(block *types.Block) *big.Int {
+	baseFee := uint256.NewInt(0)
+	if block.BaseFee() != nil {
+		baseFee.SetFromBig(block.BaseFee())
+	}
+	var floor *uint256.Int
+	for _, txn := range block.Transactions() {
+		if tip := txn.GetEffectiveGasTip(baseFee); floor == nil || tip.Lt(floor) {
+			floor = tip
+		}
+	}
+	if floor == nil {
+		return new(big.Int)
+	}
+	return floor.ToBig()
+}
+
 // resolveBlockRange resolves the specified block range to absolute block numbers while also
 // enforcing backend specific limitations. The pending block and corresponding receipts are
 // also returned if requested and available.
@@ -230,31 +254,45 @@ func (oracle *Oracle) resolveBlockRange(ctx context.Context, lastBlock rpc.Block
 //     block, sorted in ascending order and weighted by gas used.
 //   - baseFee: base fee per gas in the given block
 //   - gasUsedRatio: gasUsed/gasLimit in the given block
+//   - blobBaseFee: blob base fee per gas in the given block
+//   - blobGasUsedRatio: blobGasUsed/maxBlobGasPerBlock in the given block
 //
-// Note: baseFee includes the next block after the newest of the returned range, because this
-// value can be derived from the newest block.
+// Note: baseFee and blobBaseFee include the next block after the newest of the returned range, because
+// this value can be derived from the newest block.
 func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error) {
+	oldest, reward, baseFee, gasUsedRatio, blobBaseFee, blobGasUsedRatio, _, err := oracle.feeHistory(ctx, blocks, unresolvedLastBlock, rewardPercentiles, false)
+	return oldest, reward, baseFee, gasUsedRatio, blobBaseFee, blobGasUsedRatio, err
+}
+
+// FeeHistoryWithPriorityFeeFloor is FeeHistory also returning, on bor chains only, the priority fee floor of
+// the processed blocks: the lowest effective priority fee per gas accepted by the producer of the given block,
+// zero for an empty block. It is nil on other chains.
+func (oracle *Oracle) FeeHistoryWithPriorityFeeFloor(ctx context.Context, blocks int, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, []*big.Int, error) {
+	return oracle.feeHistory(ctx, blocks, unresolvedLastBlock, rewardPercentiles, oracle.backend.ChainConfig().Bor != nil)
+}
+
+func (oracle *Oracle) feeHistory(ctx context.Context, blocks int, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64, withFeeFloor bool) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, []*big.Int, error) {
 	if blocks < 1 {
-		return libcommon.Big0, nil, nil, nil, nil, nil, nil // returning with no data and no error means there are no retrievable blocks
+		return libcommon.Big0, nil, nil, nil, nil, nil, nil, nil // returning with no data and no error means there are no retrievable blocks
 	}
 	if blocks > maxFeeHistory {
 		oracle.log.Warn("Sanitizing fee history length", "requested", blocks, "truncated", maxFeeHistory)
 		blocks = maxFeeHistory
 	}
 	if len(rewardPercentiles) > maxQueryLimit {
-		return libcommon.Big0, nil, nil, nil, nil, nil, fmt.Errorf("%w: over the query limit %d", ErrInvalidPercentile, maxQueryLimit)
+		return libcommon.Big0, nil, nil, nil, nil, nil, nil, fmt.Errorf("%w: over the query limit %d", ErrInvalidPercentile, maxQueryLimit)
 	}
 	for i, p := range rewardPercentiles {
 		if p < 0 || p > 100 {
-			return libcommon.Big0, nil, nil, nil, nil, nil, fmt.Errorf("%w: %f", ErrInvalidPercentile, p)
+			return libcommon.Big0, nil, nil, nil, nil, nil, nil, fmt.Errorf("%w: %f", ErrInvalidPercentile, p)
 		}
 		if i > 0 && p <= rewardPercentiles[i-1] {
-			return libcommon.Big0, nil, nil, nil, nil, nil, fmt.Errorf("%w: #%d:%f >= #%d:%f", ErrInvalidPercentile, i-1, rewardPercentiles[i-1], i, p)
+			return libcommon.Big0, nil, nil, nil, nil, nil, nil, fmt.Errorf("%w: #%d:%f >= #%d:%f", ErrInvalidPercentile, i-1, rewardPercentiles[i-1], i, p)
 		}
 	}
-	// Only process blocks if reward percentiles were requested
+	// Only process blocks if reward percentiles or the priority fee floor were requested
 	maxHistory := oracle.maxHeaderHistory
-	if len(rewardPercentiles) != 0 {
+	if len(rewardPercentiles) != 0 || withFeeFloor {
 		maxHistory = oracle.maxBlockHistory
 	}
 	var (
@@ -264,7 +302,7 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 	)
 	pendingBlock, pendingReceipts, lastBlock, blocks, err := oracle.resolveBlockRange(ctx, unresolvedLastBlock, blocks, maxHistory)
 	if err != nil || blocks == 0 {
-		return libcommon.Big0, nil, nil, nil, nil, nil, err
+		return libcommon.Big0, nil, nil, nil, nil, nil, nil, err
 	}
 	oldestBlock := lastBlock + 1 - uint64(blocks)
 
@@ -277,11 +315,15 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		gasUsedRatio     = make([]float64, blocks)
 		blobGasUsedRatio = make([]float64, blocks)
 		blobBaseFee      = make([]*big.Int, blocks+1)
+		feeFloor         []*big.Int
 		firstMissing     = blocks
 	)
+	if withFeeFloor {
+		feeFloor = make([]*big.Int, blocks)
+	}
 	for ; blocks > 0; blocks-- {
 		if err = libcommon.Stopped(ctx.Done()); err != nil {
-			return libcommon.Big0, nil, nil, nil, nil, nil, err
+			return libcommon.Big0, nil, nil, nil, nil, nil, nil, err
 		}
 		// Retrieve the next block number to fetch with this goroutine
 		blockNumber := atomic.AddUint64(&next, 1) - 1
@@ -298,6 +340,9 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 				if fees.block != nil && fees.err == nil {
 					fees.receipts, fees.err = oracle.backend.GetReceipts(ctx, fees.block)
 				}
+			} else if withFeeFloor {
+				// receipts are needed only for the rewards
+				fees.block, fees.err = oracle.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNumber))
 			} else {
 				fees.header, fees.err = oracle.backend.HeaderByNumber(ctx, rpc.BlockNumber(blockNumber))
 			}
@@ -310,12 +355,15 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		}
 
 		if fees.err != nil {
-			return libcommon.Big0, nil, nil, nil, nil, nil, fees.err
+			return libcommon.Big0, nil, nil, nil, nil, nil, nil, fees.err
 		}
 		i := int(fees.blockNumber - oldestBlock)
 		if fees.header != nil {
 			reward[i], baseFee[i], baseFee[i+1], gasUsedRatio[i] = fees.reward, fees.baseFee, fees.nextBaseFee, fees.gasUsedRatio
 			blobGasUsedRatio[i], blobBaseFee[i], blobBaseFee[i+1] = fees.blobGasUsedRatio, fees.blobBaseFee, fees.nextBlobBaseFee
+			if feeFloor != nil {
+				feeFloor[i] = fees.priorityFeeFloor
+			}
 		} else {
 			// getting no block and no error means we are requesting into the future (might happen because of a reorg)
 			if i < firstMissing {
@@ -324,7 +372,7 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		}
 	}
 	if firstMissing == 0 {
-		return libcommon.Big0, nil, nil, nil, nil, nil, nil
+		return libcommon.Big0, nil, nil, nil, nil, nil, nil, nil
 	}
 	if len(rewardPercentiles) != 0 {
 		reward = reward[:firstMissing]
@@ -332,5 +380,9 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		reward = nil
 	}
 	baseFee, gasUsedRatio = baseFee[:firstMissing+1], gasUsedRatio[:firstMissing]
-	return new(big.Int).SetUint64(oldestBlock), reward, baseFee, gasUsedRatio, blobBaseFee, blobGasUsedRatio, nil
+	blobBaseFee, blobGasUsedRatio = blobBaseFee[:firstMissing+1], blobGasUsedRatio[:firstMissing]
+	if feeFloor != nil {
+		feeFloor = feeFloor[:firstMissing]
+	}
+	return new(big.Int).SetUint64(oldestBlock), reward, baseFee, gasUsedRatio, blobBaseFee, blobGasUsedRatio, feeFloor, nil
 }
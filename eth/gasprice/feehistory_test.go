@@ -22,12 +22,20 @@ package gasprice_test
 import (
 	"context"
 	"errors"
+	"math/big"
 	"testing"
 
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/kv/kvcache"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/gasprice"
 	"github.com/erigontech/erigon/eth/gasprice/gaspricecfg"
+	"github.com/erigontech/erigon/params"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/turbo/jsonrpc"
@@ -118,3 +126,89 @@ func TestFeeHistory(t *testing.T) {
 		}()
 	}
 }
+
+// borTestBackend serves a chain of blocks on a bor chain from memory
+type borTestBackend struct {
+	config        *chain.Config
+	blocks        []*types.Block
+	blockRequests int
+}
+
+func (b *borTestBackend) HeaderByNumber(_ context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	block, err := b.block(number)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+func (b *borTestBackend) BlockByNumber(_ context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	b.blockRequests++
+	return b.block(number)
+}
+
+func (b *borTestBackend) block(number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(len(b.blocks) - 1)
+	}
+	if int(number) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *borTestBackend) ChainConfig() *chain.Config { return b.config }
+
+func (b *borTestBackend) GetReceipts(context.Context, *types.Block) (types.Receipts, error) {
+	return nil, nil
+}
+
+func (b *borTestBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) { return nil, nil }
+
+func TestFeeHistoryBorPriorityFeeFloor(t *testing.T) {
+	baseFee := uint256.NewInt(params.GWei)
+	to := libcommon.HexToAddress("deadbeef")
+	gwei := func(n uint64) *uint256.Int {
+		return new(uint256.Int).Mul(uint256.NewInt(n), uint256.NewInt(params.GWei))
+	}
+	dynamicFeeTx := func(tip, feeCap *uint256.Int) types.Transaction {
+		return types.NewEIP1559Transaction(*uint256.NewInt(1), 0, to, new(uint256.Int), 21000, nil, tip, feeCap, nil)
+	}
+	// the producer accepted:
+	//   - block 1: tips of 3 and 1 gwei
+	//   - block 2: a legacy txn paying 2 gwei over the base fee and a txn capped at 4 gwei by its fee cap
+	//   - block 3: nothing
+	txs := [][]types.Transaction{
+		nil,
+		{dynamicFeeTx(gwei(3), gwei(10)), dynamicFeeTx(gwei(1), gwei(10))},
+		{types.NewTransaction(0, to, new(uint256.Int), 21000, gwei(3), nil), dynamicFeeTx(gwei(10), gwei(5))},
+		nil,
+	}
+	backend := &borTestBackend{config: params.BorDevnetChainConfig}
+	for i, blockTxs := range txs {
+		header := &types.Header{Number: big.NewInt(int64(i)), GasLimit: 30_000_000, GasUsed: 21000 * uint64(len(blockTxs)), BaseFee: baseFee.ToBig()}
+		backend.blocks = append(backend.blocks, types.NewBlock(header, blockTxs, nil, nil, nil))
+	}
+	oracle := gasprice.NewOracle(backend, gaspricecfg.Config{}, jsonrpc.NewGasPriceCache(), log.New())
+
+	first, reward, fees, ratio, _, _, feeFloor, err := oracle.FeeHistoryWithPriorityFeeFloor(context.Background(), 3, rpc.LatestBlockNumber, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), first.Uint64())
+	require.Nil(t, reward)
+	require.Len(t, fees, 4)
+	require.Len(t, ratio, 3)
+	require.Equal(t, []*big.Int{gwei(1).ToBig(), gwei(2).ToBig(), new(big.Int)}, feeFloor)
+	require.Equal(t, 3, backend.blockRequests)
+
+	// eth_feeHistory of other callers and of the other chains stays on the headers
+	backend.blockRequests = 0
+	_, _, _, _, _, _, err = oracle.FeeHistory(context.Background(), 3, rpc.LatestBlockNumber, nil)
+	require.NoError(t, err)
+	require.Zero(t, backend.blockRequests)
+
+	backend.config = params.TestChainConfig
+	_, _, _, _, _, _, feeFloor, err = oracle.FeeHistoryWithPriorityFeeFloor(context.Background(), 3, rpc.LatestBlockNumber, nil)
+	require.NoError(t, err)
+	require.Nil(t, feeFloor)
+	require.Zero(t, backend.blockRequests)
+}
@@ -0,0 +1,401 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/diagnostics"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// Artifacts of a quarantined bad block, written into <datadir>/bad_blocks/<number>-<hash>
+const (
+	BadBlockInfoFile     = "info.json"
+	BadBlockRlpFile      = "block.rlp"
+	BadBlockReceiptsFile = "receipts.json"
+	BadBlockTimelineFile = "timeline.json"
+	BadBlockWitnessFile  = "witness.json"
+)
+
+const (
+	BadBlockReasonExecution = "execution failed"
+	BadBlockReasonStateRoot = "state root mismatch"
+)
+
+// the parent state of a bad block is reached by replaying the blocks between the state in the db and the bad block,
+// the witness is not captured if there are more of them
+const badBlockWitnessMaxReplay = 64
+
+type BadBlockInfo struct {
+	Number       uint64      `json:"number"`
+	Hash         common.Hash `json:"hash"`
+	ParentHash   common.Hash `json:"parentHash"`
+	Reason       string      `json:"reason"`
+	Error        string      `json:"error"`
+	Timestamp    time.Time   `json:"timestamp"`
+	WitnessError string      `json:"witnessError,omitempty"`
+}
+
+// ExecTimelineEntry is a task of the bad block as run by the executor, in the order of execution
+type ExecTimelineEntry struct {
+	TxIndex  int           `json:"txIndex"` // -1 for the block initialisation
+	TxNum    uint64        `json:"txNum"`
+	TxHash   *common.Hash  `json:"txHash,omitempty"`
+	Final    bool          `json:"final,omitempty"`
+	GasUsed  uint64        `json:"gasUsed"`
+	Duration time.Duration `json:"duration,omitempty"` // not known for the tasks run speculatively by the parallel workers
+	Error    string        `json:"error,omitempty"`
+}
+
+func newExecTimelineEntry(txTask *state.TxTask, duration time.Duration) ExecTimelineEntry {
+	entry := ExecTimelineEntry{
+		TxIndex:  txTask.TxIndex,
+		TxNum:    txTask.TxNum,
+		Final:    txTask.Final,
+		GasUsed:  txTask.UsedGas,
+		Duration: duration,
+	}
+	if txTask.Tx != nil {
+		txHash := txTask.Tx.Hash()
+		entry.TxHash = &txHash
+	}
+	if txTask.Error != nil {
+		entry.Error = txTask.Error.Error()
+	}
+	return entry
+}
+
+// quarantine captures a block the executor found bad, with its tasks run up to the failure
+func (te *txExecutor) quarantine(ctx context.Context, tx kv.Tx, txTask *state.TxTask, cause error, timeline []ExecTimelineEntry) {
+	if te.isMining {
+		return
+	}
+	quarantineBadBlock(ctx, tx, te.cfg, txTask.Header, BadBlockReasonExecution, cause, txTask.BlockReceipts, timeline, te.logger)
+}
+
+// quarantineBadBlock captures everything needed to reproduce the execution of a bad block into the quarantine
+// directory: the block, the receipts and the executor timeline up to the failure, and the witness of the parent state
+// read by the block. The bundle is announced to diagnostics. A block is quarantined once, capture failures are only
+// logged - they must not get in the way of the unwind. Capturing the witness may replay up to badBlockWitnessMaxReplay
+// blocks, so the quarantine is off unless --sync.bad-blocks-keep is set, and only that many of the latest bundles are
+// kept.
+func quarantineBadBlock(ctx context.Context, tx kv.Tx, cfg ExecuteBlockCfg, header *types.Header, reason string, cause error, receipts types.Receipts, timeline []ExecTimelineEntry, logger log.Logger) {
+	if cfg.syncCfg.BadBlocksKeep == 0 || cfg.dirs.BadBlocks == "" {
+		return
+	}
+	number, hash := header.Number.Uint64(), header.Hash()
+	dir := filepath.Join(cfg.dirs.BadBlocks, fmt.Sprintf("%d-%x", number, hash))
+	if _, err := os.Stat(dir); err == nil {
+		return
+	}
+	start := time.Now()
+	files, err := writeBadBlockArtifacts(ctx, tx, cfg, dir, header, reason, cause, receipts, timeline, logger)
+	if err != nil {
+		logger.Warn("[quarantine] capturing bad block artifacts", "block", number, "hash", hash, "err", err)
+		return
+	}
+	logger.Warn("[quarantine] bad block captured", "block", number, "hash", hash, "reason", reason, "dir", dir, "took", time.Since(start))
+	if err := pruneBadBlocks(cfg.dirs.BadBlocks, int(cfg.syncCfg.BadBlocksKeep)); err != nil {
+		logger.Warn("[quarantine] pruning bad blocks", "dir", cfg.dirs.BadBlocks, "err", err)
+	}
+	diagnostics.Send(diagnostics.BadBlockQuarantined{
+		Timestamp: time.Now(),
+		Number:    number,
+		Hash:      hash.Hex(),
+		Reason:    reason,
+		Error:     errString(cause),
+		Dir:       dir,
+		Files:     files,
+	})
+}
+
+// pruneBadBlocks removes the quarantined bundles but the keep latest ones
+func pruneBadBlocks(badBlocksDir string, keep int) error {
+	entries, err := os.ReadDir(badBlocksDir)
+	if err != nil {
+		return err
+	}
+	type bundle struct {
+		name    string
+		modTime time.Time
+	}
+	bundles := make([]bundle, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		bundles = append(bundles, bundle{name: entry.Name(), modTime: info.ModTime()})
+	}
+	if len(bundles) <= keep {
+		return nil
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		if !bundles[i].modTime.Equal(bundles[j].modTime) {
+			return bundles[i].modTime.After(bundles[j].modTime)
+		}
+		return bundles[i].name > bundles[j].name
+	})
+	for _, b := range bundles[keep:] {
+		if err := os.RemoveAll(filepath.Join(badBlocksDir, b.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBadBlockArtifacts writes the bundle into a temporary directory renamed to dir once complete
+func writeBadBlockArtifacts(ctx context.Context, tx kv.Tx, cfg ExecuteBlockCfg, dir string, header *types.Header, reason string, cause error, receipts types.Receipts, timeline []ExecTimelineEntry, logger log.Logger) (files []string, err error) {
+	tmpDir := dir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(tmpDir)
+		}
+	}()
+	write := func(name string, data []byte) error {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), data, 0644); err != nil {
+			return err
+		}
+		files = append(files, name)
+		return nil
+	}
+	writeJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return write(name, data)
+	}
+
+	info := BadBlockInfo{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Reason:     reason,
+		Error:      errString(cause),
+		Timestamp:  time.Now(),
+	}
+	block, _, err := cfg.blockReader.BlockWithSenders(ctx, tx, info.Hash, info.Number)
+	if err != nil {
+		return nil, err
+	}
+	if block != nil {
+		data, err := rlp.EncodeToBytes(block)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(BadBlockRlpFile, data); err != nil {
+			return nil, err
+		}
+		witness, err := captureParentStateWitness(ctx, tx, cfg, block, logger)
+		if err != nil {
+			info.WitnessError = err.Error()
+		} else if err := writeJSON(BadBlockWitnessFile, witness); err != nil {
+			return nil, err
+		}
+	} else {
+		info.WitnessError = "block not found"
+	}
+	var executed types.Receipts
+	for _, receipt := range receipts {
+		if receipt != nil {
+			executed = append(executed, receipt)
+		}
+	}
+	if len(executed) > 0 {
+		if err := writeJSON(BadBlockReceiptsFile, executed); err != nil {
+			return nil, err
+		}
+	}
+	if len(timeline) > 0 {
+		if err := writeJSON(BadBlockTimelineFile, timeline); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeJSON(BadBlockInfoFile, info); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// captureParentStateWitness executes the bad block on top of its parent state in a throwaway overlay of tx and
+// returns the accounts, code and storage it read, with their values before the block. The parent state is reached by
+// replaying the blocks after the state in the db. The execution of the bad block is expected to fail, everything it
+// read up to the failure is in the witness.
+// The witness only has the values read, not the trie nodes around them: it is enough to re-execute the block and
+// compare its receipts and gas, but not to recompute the state root, so a state root mismatch can't be reproduced from
+// the bundle alone - that needs the commitment of the parent state, e.g. via debug_executionWitness on a synced node.
+func captureParentStateWitness(ctx context.Context, tx kv.Tx, cfg ExecuteBlockCfg, block *types.Block, logger log.Logger) (types.GenesisAlloc, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis block has no parent state")
+	}
+	e, err := newDryRunExecutor(ctx, tx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer e.close()
+	parent := block.NumberU64() - 1
+	if e.doms.BlockNum() > parent {
+		return nil, fmt.Errorf("state is at block %d, past the parent %d", e.doms.BlockNum(), parent)
+	}
+	if parent-e.doms.BlockNum() > badBlockWitnessMaxReplay {
+		return nil, fmt.Errorf("state is at block %d, more than %d blocks behind the parent %d", e.doms.BlockNum(), badBlockWitnessMaxReplay, parent)
+	}
+
+	for blockNum := e.doms.BlockNum() + 1; blockNum <= parent; blockNum++ {
+		b, err := blockWithSenders(ctx, nil, e.applyTx, cfg.blockReader, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, fmt.Errorf("block %d not found", blockNum)
+		}
+		if err := e.executeBlock(ctx, b); err != nil {
+			return nil, fmt.Errorf("replaying block %d: %w", blockNum, err)
+		}
+	}
+
+	recorder := newPrestateRecorder(state.NewReaderV3(e.doms))
+	e.applyWorker.SetReader(recorder)
+	if err := e.executeBlock(ctx, block); err != nil {
+		logger.Debug("[quarantine] bad block execution", "block", block.NumberU64(), "err", err)
+	}
+	if recorder.err != nil {
+		return nil, recorder.err
+	}
+	return recorder.alloc, nil
+}
+
+// prestateRecorder is a state reader recording the first value read of every account, code and storage slot. Every
+// write of the execution is preceded by a read of the written value, so the first values are the state before it.
+type prestateRecorder struct {
+	state.ResettableStateReader
+	alloc   types.GenesisAlloc
+	missing map[common.Address]struct{}
+	err     error
+}
+
+func newPrestateRecorder(reader state.ResettableStateReader) *prestateRecorder {
+	return &prestateRecorder{
+		ResettableStateReader: reader,
+		alloc:                 types.GenesisAlloc{},
+		missing:               map[common.Address]struct{}{},
+	}
+}
+
+func (r *prestateRecorder) record(address common.Address) (*types.GenesisAccount, error) {
+	if account, ok := r.alloc[address]; ok {
+		return &account, nil
+	}
+	if _, ok := r.missing[address]; ok {
+		return nil, nil
+	}
+	account, err := r.ResettableStateReader.ReadAccountData(address)
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	if account == nil {
+		r.missing[address] = struct{}{}
+		return nil, nil
+	}
+	genesisAccount := types.GenesisAccount{Balance: account.Balance.ToBig(), Nonce: account.Nonce}
+	if !account.IsEmptyCodeHash() {
+		code, err := r.ResettableStateReader.ReadAccountCode(address, account.Incarnation)
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+		genesisAccount.Code = code
+	}
+	r.alloc[address] = genesisAccount
+	return &genesisAccount, nil
+}
+
+func (r *prestateRecorder) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	if _, err := r.record(address); err != nil {
+		return nil, err
+	}
+	return r.ResettableStateReader.ReadAccountData(address)
+}
+
+func (r *prestateRecorder) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	value, err := r.ResettableStateReader.ReadAccountStorage(address, incarnation, key)
+	if err != nil {
+		return nil, err
+	}
+	account, err := r.record(address)
+	if err != nil || account == nil {
+		return value, err
+	}
+	if account.Storage == nil {
+		account.Storage = map[common.Hash]common.Hash{}
+	}
+	if _, ok := account.Storage[*key]; !ok {
+		account.Storage[*key] = common.BytesToHash(value)
+		r.alloc[address] = *account
+	}
+	return value, nil
+}
+
+func (r *prestateRecorder) ReadAccountCode(address common.Address, incarnation uint64) ([]byte, error) {
+	if _, err := r.record(address); err != nil {
+		return nil, err
+	}
+	return r.ResettableStateReader.ReadAccountCode(address, incarnation)
+}
+
+func (r *prestateRecorder) ReadAccountCodeSize(address common.Address, incarnation uint64) (int, error) {
+	if _, err := r.record(address); err != nil {
+		return 0, err
+	}
+	return r.ResettableStateReader.ReadAccountCodeSize(address, incarnation)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
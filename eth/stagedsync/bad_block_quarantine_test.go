@@ -0,0 +1,58 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneBadBlocks(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"3-c", "1-a", "2-b", "4-d"} {
+		bundle := filepath.Join(dir, name)
+		require.NoError(t, os.Mkdir(bundle, 0755))
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(bundle, modTime, modTime))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "5-e.tmp"), 0755)) // being written
+
+	require.NoError(t, pruneBadBlocks(dir, 4))
+	require.Len(t, readDirNames(t, dir), 5)
+
+	// the latest quarantined are kept, not the highest
+	require.NoError(t, pruneBadBlocks(dir, 2))
+	require.Equal(t, []string{"2-b", "4-d", "5-e.tmp"}, readDirNames(t, dir))
+
+	require.NoError(t, pruneBadBlocks(dir, 1))
+	require.Equal(t, []string{"4-d", "5-e.tmp"}, readDirNames(t, dir))
+}
+
+func readDirNames(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
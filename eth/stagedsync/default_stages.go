@@ -36,6 +36,7 @@ func DefaultStages(ctx context.Context,
 	senders SendersCfg,
 	exec ExecuteBlockCfg,
 	txLookup TxLookupCfg,
+	txSenderIndex TxSenderIndexCfg,
 	finish FinishCfg,
 	test bool) []*Stage {
 	return []*Stage{
@@ -171,6 +172,20 @@ func DefaultStages(ctx context.Context,
 				return PruneTxLookup(p, tx, txLookup, ctx, logger)
 			},
 		},
+		{
+			ID:          stages.TxSenderIndex,
+			Description: "Generate txn by sender index",
+			Disabled:    !txSenderIndex.enabled || dbg.StagesOnlyBlocks,
+			Forward: func(badBlockUnwind bool, s *StageState, u Unwinder, txc wrap.TxContainer, logger log.Logger) error {
+				return SpawnTxSenderIndex(s, txc.Tx, 0 /* toBlock */, txSenderIndex, ctx, logger)
+			},
+			Unwind: func(u *UnwindState, s *StageState, txc wrap.TxContainer, logger log.Logger) error {
+				return UnwindTxSenderIndex(u, s, txc.Tx, txSenderIndex, ctx, logger)
+			},
+			Prune: func(p *PruneState, tx kv.RwTx, logger log.Logger) error {
+				return nil
+			},
+		},
 		{
 			ID:          stages.Finish,
 			Description: "Final: update current block for the RPC API",
@@ -578,6 +593,7 @@ var DefaultForwardOrder = UnwindOrder{
 	stages.Execution,
 	//stages.CustomTrace,
 	stages.TxLookup,
+	stages.TxSenderIndex,
 	stages.Finish,
 }
 
@@ -590,6 +606,7 @@ type PruneOrder []stages.SyncStage
 
 var DefaultUnwindOrder = UnwindOrder{
 	stages.Finish,
+	stages.TxSenderIndex,
 	stages.TxLookup,
 
 	//stages.CustomTrace,
@@ -630,6 +647,7 @@ var PolygonSyncUnwindOrder = UnwindOrder{
 
 var DefaultPruneOrder = PruneOrder{
 	stages.Finish,
+	stages.TxSenderIndex,
 	stages.TxLookup,
 
 	stages.Execution,
@@ -33,6 +33,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/dbg"
 	metrics2 "github.com/erigontech/erigon-lib/common/metrics"
 	"github.com/erigontech/erigon-lib/config3"
+	"github.com/erigontech/erigon-lib/diagnostics"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
@@ -40,6 +41,7 @@ import (
 	state2 "github.com/erigontech/erigon-lib/state"
 	"github.com/erigontech/erigon-lib/types/accounts"
 	"github.com/erigontech/erigon-lib/wrap"
+	"github.com/erigontech/erigon/cmd/state/exec3"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/rawdb/rawdbhelpers"
@@ -57,6 +59,7 @@ var (
 	mxExecStepsInDB    = metrics.NewGauge(`exec_steps_in_db`) //nolint
 	mxExecRepeats      = metrics.NewCounter(`exec_repeats`)   //nolint
 	mxExecTriggers     = metrics.NewCounter(`exec_triggers`)  //nolint
+	mxExecTaskRetries  = metrics.NewHistogram(`exec_task_retries`, []float64{0, 1, 2, 4, 8, 16, 32, 64})
 	mxExecTransactions = metrics.NewCounter(`exec_txns`)
 	mxExecGas          = metrics.NewCounter(`exec_gas`)
 	mxExecBlocks       = metrics.NewGauge("exec_blocks")
@@ -126,6 +129,13 @@ func (p *Progress) Log(suffix string, rs *state.StateV3, in *state.QueueWithRetr
 		"alloc", common.ByteCount(m.Alloc), "sys", common.ByteCount(m.Sys),
 	)
 
+	diagnostics.Send(diagnostics.SyncStageProgressUpdate{
+		Stage: string(stages.Execution),
+		Time:  currentTime,
+		Block: outputBlockNum,
+		Txs:   txCount,
+	})
+
 	p.prevTime = currentTime
 	p.prevTxCount = txCount
 	p.prevGasUsed = gas
@@ -212,6 +222,11 @@ func ExecV3(ctx context.Context,
 		panic("gnosis consensus doesn't support parallel exec yet: https://github.com/erigontech/erigon/issues/12054")
 	}
 
+	if cfg.prefetcher != nil {
+		// execution reads the state itself, warming it concurrently would only compete for IO
+		defer cfg.prefetcher.Pause()()
+	}
+
 	blockReader := cfg.blockReader
 	chainConfig := cfg.chainConfig
 	totalGasUsed := uint64(0)
@@ -486,10 +501,7 @@ Loop:
 			return fmt.Errorf("nil block %d", blockNum)
 		}
 		metrics2.UpdateBlockConsumerPreExecutionDelay(b.Time(), blockNum, logger)
-		txs := b.Transactions()
 		header := b.HeaderNoCopy()
-		skipAnalysis := core.SkipAnalysis(chainConfig, blockNum)
-		signer := *types.MakeSigner(chainConfig, blockNum, header.Time)
 
 		getHashFnMute := &sync.Mutex{}
 		getHashFn := core.GetHashFn(header, func(hash common.Hash, number uint64) (h *types.Header) {
@@ -498,7 +510,6 @@ Loop:
 			return executor.getHeader(ctx, hash, number)
 		})
 		totalGasUsed += b.GasUsed()
-		blockContext := core.NewEVMBlockContext(header, getHashFn, cfg.engine, cfg.author /* author */, chainConfig)
 		// print type of engine
 		if parallel {
 			if err := executor.status(ctx, commitThreshold); err != nil {
@@ -512,39 +523,19 @@ Loop:
 			accumulator.StartChange(b.NumberU64(), b.Hash(), txs, false)
 		}
 
-		rules := chainConfig.Rules(blockNum, b.Time())
-		blockReceipts := make(types.Receipts, len(txs))
+		blockTxTasks, err := exec3.BlockTxTasks(chainConfig, cfg.engine, cfg.author, b, inputTxNum, getHashFn)
+		if err != nil {
+			return err
+		}
 		// During the first block execution, we may have half-block data in the snapshots.
 		// Thus, we need to skip the first txs in the block, however, this causes the GasUsed to be incorrect.
 		// So we skip that check for the first block, if we find half-executed data.
 		skipPostEvaluation := false
 		var usedGas uint64
 		var txTasks []*state.TxTask
-		for txIndex := -1; txIndex <= len(txs); txIndex++ {
-			// Do not oversend, wait for the result heap to go under certain size
-			txTask := &state.TxTask{
-				BlockNum:        blockNum,
-				Header:          header,
-				Coinbase:        b.Coinbase(),
-				Uncles:          b.Uncles(),
-				Rules:           rules,
-				Txs:             txs,
-				TxNum:           inputTxNum,
-				TxIndex:         txIndex,
-				BlockHash:       b.Hash(),
-				SkipAnalysis:    skipAnalysis,
-				Final:           txIndex == len(txs),
-				GetHashFn:       getHashFn,
-				EvmBlockContext: blockContext,
-				Withdrawals:     b.Withdrawals(),
-
-				// use history reader instead of state reader to catch up to the tx where we left off
-				HistoryExecution: offsetFromBlockBeginning > 0 && txIndex < int(offsetFromBlockBeginning),
-
-				BlockReceipts: blockReceipts,
-
-				Config: chainConfig,
-			}
+		for _, txTask := range blockTxTasks {
+			// use history reader instead of state reader to catch up to the tx where we left off
+			txTask.HistoryExecution = offsetFromBlockBeginning > 0 && txTask.TxIndex < int(offsetFromBlockBeginning)
 			if txTask.HistoryExecution && usedGas == 0 {
 				usedGas, _, _, err = rawtemporaldb.ReceiptAsOf(executor.tx().(kv.TemporalTx), txTask.TxNum)
 				if err != nil {
@@ -564,19 +555,10 @@ Loop:
 			executor.domains().SetTxNum(txTask.TxNum)
 			executor.domains().SetBlockNum(txTask.BlockNum)
 
-			if txIndex >= 0 && txIndex < len(txs) {
-				txTask.Tx = txs[txIndex]
-				txTask.TxAsMessage, err = txTask.Tx.AsMessage(signer, header.BaseFee, txTask.Rules)
-				if err != nil {
-					return err
-				}
-			}
-
 			txTasks = append(txTasks, txTask)
 			stageProgress = blockNum
 			inputTxNum++
 		}
-
 		if parallel {
 			if _, err := executor.execute(ctx, txTasks); err != nil {
 				return err
@@ -840,6 +822,7 @@ func flushAndCheckCommitmentV3(ctx context.Context, header *types.Header, applyT
 		return true, nil
 	}
 	logger.Error(fmt.Sprintf("[%s] Wrong trie root of block %d: %x, expected (from header): %x. Block hash: %x", e.LogPrefix(), header.Number.Uint64(), rh, header.Root.Bytes(), header.Hash()))
+	quarantineBadBlock(ctx, applyTx, cfg, header, BadBlockReasonStateRoot, fmt.Errorf("%w: computed %x, header %x", ErrInvalidStateRootHash, rh, header.Root), nil, nil, logger)
 	if cfg.badBlockHalt {
 		return false, errors.New("wrong trie root")
 	}
@@ -0,0 +1,172 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/membatchwithdb"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+	libstate "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/cmd/state/exec3"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var mxExecDryRunBlock = metrics.NewGauge(`exec_dry_run_block`)
+
+// ExecDryRun is the dry-run mode of the execution stage: executes the blocks [from, to] on top of the state of block
+// from-1 the way ExecV3 does it and computes the state root after each of them. The state changes and the commitment
+// go into a throwaway overlay of tx, nothing is written to the database and the domains are never flushed. onBlock, if
+// not nil, is called with every block and its computed root. Stops at the first block whose root differs from its
+// header and returns its number, 0 if all the roots match.
+func ExecDryRun(ctx context.Context, tx kv.Tx, cfg ExecuteBlockCfg, from, to uint64, onBlock func(header *types.Header, root common.Hash), logger log.Logger) (uint64, error) {
+	if from == 0 || from > to {
+		return 0, fmt.Errorf("invalid block range [%d, %d]", from, to)
+	}
+	logPrefix := "ExecDryRun"
+
+	e, err := newDryRunExecutor(ctx, tx, cfg, logger)
+	if err != nil {
+		return 0, err
+	}
+	defer e.close()
+	if e.doms.BlockNum() != from-1 {
+		return 0, fmt.Errorf("state is at block %d, the dry run from block %d needs the state of block %d", e.doms.BlockNum(), from, from-1)
+	}
+
+	for blockNum := from; blockNum <= to; blockNum++ {
+		b, err := blockWithSenders(ctx, nil, e.applyTx, cfg.blockReader, blockNum)
+		if err != nil {
+			return 0, err
+		}
+		if b == nil {
+			return 0, fmt.Errorf("block %d not found", blockNum)
+		}
+		header := b.HeaderNoCopy()
+		if err := e.executeBlock(ctx, b); err != nil {
+			return 0, err
+		}
+
+		rh, err := e.doms.ComputeCommitment(ctx, true, blockNum, logPrefix)
+		if err != nil {
+			return 0, err
+		}
+		root := common.BytesToHash(rh)
+		if onBlock != nil {
+			onBlock(header, root)
+		}
+		if root != header.Root {
+			return blockNum, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+		}
+	}
+	return 0, nil
+}
+
+// dryRunExecutor is the serial executor of ExecV3 over a throwaway overlay of a tx: the state changes, the receipts
+// and the commitment go into the domains of the overlay, which are never flushed. Its executions have no side effects:
+// no notifications, no bad block reports and no quarantine.
+type dryRunExecutor struct {
+	serialExecutor
+	batch        *membatchwithdb.MemoryMutation
+	txNumsReader rawdbv3.TxNumsReader
+}
+
+func newDryRunExecutor(ctx context.Context, tx kv.Tx, cfg ExecuteBlockCfg, logger log.Logger) (*dryRunExecutor, error) {
+	batch := membatchwithdb.NewMemoryBatch(tx, cfg.dirs.Tmp, logger)
+	domains, err := libstate.NewSharedDomains(batch, logger)
+	if err != nil {
+		batch.Rollback()
+		return nil, err
+	}
+
+	cfg.notifications, cfg.hd = nil, nil
+	cfg.badBlockHalt = true
+	cfg.syncCfg.BadBlocksKeep = 0
+	cfg.syncCfg.ChaosMonkey = false
+	rs := state.NewStateV3(domains, logger)
+	applyWorker := exec3.NewWorker(nil, logger, ctx, false /* background */, cfg.db, nil, cfg.blockReader, cfg.chainConfig, cfg.genesis, nil, cfg.engine, cfg.dirs, false /* isMining */)
+	applyWorker.ResetState(rs, nil)
+	applyWorker.ResetTx(batch)
+
+	return &dryRunExecutor{
+		serialExecutor: serialExecutor{
+			txExecutor: txExecutor{
+				cfg:            cfg,
+				execStage:      &StageState{ID: stages.Execution},
+				rs:             rs,
+				doms:           domains,
+				inMemExec:      true,
+				applyTx:        batch,
+				applyWorker:    applyWorker,
+				outputTxNum:    &atomic.Uint64{},
+				outputBlockNum: mxExecDryRunBlock,
+				logger:         logger,
+			},
+		},
+		batch:        batch,
+		txNumsReader: rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, cfg.blockReader)),
+	}, nil
+}
+
+func (e *dryRunExecutor) close() {
+	e.doms.Close()
+	e.batch.Rollback()
+}
+
+// executeBlock executes the block on top of the domains with the tasks of ExecV3, reading the state through the
+// reader of the apply worker.
+func (e *dryRunExecutor) executeBlock(ctx context.Context, b *types.Block) error {
+	blockNum := b.NumberU64()
+	firstTxNum, err := e.txNumsReader.Min(e.applyTx, blockNum)
+	if err != nil {
+		return err
+	}
+	getHashFn := core.GetHashFn(b.HeaderNoCopy(), func(hash common.Hash, number uint64) *types.Header {
+		return e.getHeader(ctx, hash, number)
+	})
+	tasks, err := exec3.BlockTxTasks(e.cfg.chainConfig, e.cfg.engine, e.cfg.author, b, firstTxNum, getHashFn)
+	if err != nil {
+		return err
+	}
+	if e.cfg.genesis != nil {
+		for _, txTask := range tasks {
+			txTask.Config = e.cfg.genesis.Config
+		}
+	}
+
+	e.doms.SetBlockNum(blockNum)
+	e.doms.SetTxNum(tasks[len(tasks)-1].TxNum)
+	defer func() { e.usedGas, e.blobGasUsed = 0, 0 }()
+	_, err = e.execute(ctx, tasks)
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/rawdb/rawdbhelpers"
+	"github.com/erigontech/erigon/core/rawdb/rawtemporaldb"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/vm"
@@ -132,6 +133,52 @@ func (te *txExecutor) getHeader(ctx context.Context, hash common.Hash, number ui
 	return h
 }
 
+// finishTask accounts the gas of an executed task into the block's totals and creates its receipt. The block end
+// is finished as any other task: it post-validates the block against its header. Both executors finish tasks
+// here, so a block gets the same receipts and validation whether its tasks ran serially or in parallel.
+func (te *txExecutor) finishTask(txTask *state.TxTask, usedGas, blobGasUsed *uint64, postValidation bool) error {
+	*usedGas += txTask.UsedGas
+	if txTask.Tx != nil {
+		*blobGasUsed += txTask.Tx.GetBlobGas()
+	}
+
+	txTask.CreateReceipt(te.applyWorker.Tx())
+
+	if !txTask.Final {
+		return nil
+	}
+	if !te.isMining && !te.inMemExec && postValidation && !te.execStage.CurrentSyncCycle.IsInitialCycle {
+		// note this assumes the bloach reciepts is a fixed array shared by
+		// all tasks - if that changes this will need to change - robably need to
+		// add this to the executor
+		te.cfg.notifications.RecentLogs.Add(txTask.BlockReceipts)
+	}
+	checkReceipts := !te.cfg.vmConfig.StatelessExec && te.cfg.chainConfig.IsByzantium(txTask.BlockNum) && !te.cfg.vmConfig.NoReceipts && !te.isMining
+	if txTask.BlockNum > 0 && postValidation { //Disable check for genesis. Maybe need somehow improve it in future - to satisfy TestExecutionSpec
+		if err := core.BlockPostValidation(*usedGas, *blobGasUsed, checkReceipts, txTask.BlockReceipts, txTask.Header, te.isMining); err != nil {
+			return fmt.Errorf("%w, txnIdx=%d, %v", consensus.ErrInvalidBlock, txTask.TxIndex, err) //same as in stage_exec.go
+		}
+	}
+	return nil
+}
+
+// applyTask writes the results of a finished task at its txNum: the receipt, the state and the logs, traces and
+// withdrawals indices.
+func (te *txExecutor) applyTask(ctx context.Context, txTask *state.TxTask, blobGasUsed uint64) error {
+	if !txTask.Final {
+		var receipt *types.Receipt
+		if txTask.TxIndex >= 0 {
+			receipt = txTask.BlockReceipts[txTask.TxIndex]
+		}
+		if err := rawtemporaldb.AppendReceipt(te.doms, receipt, blobGasUsed); err != nil {
+			return err
+		}
+	}
+
+	// MA applystate
+	return te.rs.ApplyState4(ctx, txTask)
+}
+
 type parallelExecutor struct {
 	txExecutor
 	rwLoopErrCh              chan error
@@ -150,6 +197,13 @@ type parallelExecutor struct {
 	logEvery                 *time.Ticker
 	slowDownLimit            *time.Ticker
 	progress                 *Progress
+	// gas of the block being applied, its post-evaluation is skipped if the block was partially executed before
+	blockNum           uint64
+	usedGas            uint64
+	blobGasUsed        uint64
+	skipPostEvaluation bool
+	// tasks of the block being applied, captured into the quarantine if it turns out bad
+	timeline []ExecTimelineEntry
 }
 
 func (pe *parallelExecutor) applyLoop(ctx context.Context, maxTxNum uint64, blockComplete *atomic.Bool, errCh chan error) {
@@ -380,11 +434,28 @@ func (pe *parallelExecutor) processResultQueue(ctx context.Context, inputTxNum u
 	//defer fmt.Println("PRQ", "Done")
 
 	var i int
+	var txTask *state.TxTask
+	defer func() {
+		if errors.Is(err, consensus.ErrInvalidBlock) {
+			pe.quarantine(ctx, pe.applyWorker.Tx(), txTask, err, append(pe.timeline, newExecTimelineEntry(txTask, 0)))
+		}
+	}()
 	outputTxNum = inputTxNum
 	for rwsIt.HasNext(outputTxNum) {
-		txTask := rwsIt.PopNext()
+		txTask = rwsIt.PopNext()
 		//fmt.Println("PRQ", txTask.BlockNum, txTask.TxIndex, txTask.TxNum)
-		if txTask.Error != nil || !pe.rs.ReadsValid(txTask.ReadLists) {
+		if txTask.TxIndex == -1 || txTask.BlockNum != pe.blockNum { // first task of the block
+			pe.blockNum, pe.usedGas, pe.blobGasUsed = txTask.BlockNum, 0, 0
+			pe.skipPostEvaluation = txTask.TxIndex != -1
+			pe.timeline = pe.timeline[:0]
+		}
+		if errors.Is(txTask.Error, exec3.ErrBlockEndDeferred) {
+			// receipts of all transactions of the block are created by now
+			pe.applyWorker.RunTxTaskNoLock(txTask.Reset(), pe.isMining)
+			if txTask.Error != nil {
+				return outputTxNum, conflicts, triggers, processedBlockNum, false, fmt.Errorf("%w: %v", consensus.ErrInvalidBlock, txTask.Error)
+			}
+		} else if txTask.Error != nil || !pe.rs.ReadsValid(txTask.ReadLists) {
 			conflicts++
 			//fmt.Println(txTask.TxNum, txTask.Error)
 			if errors.Is(txTask.Error, vm.ErrIntraBlockStateFailed) ||
@@ -398,6 +469,7 @@ func (pe *parallelExecutor) processResultQueue(ctx context.Context, inputTxNum u
 			}
 
 			// resolve first conflict right here: it's faster and conflict-free
+			txTask.Incarnation++
 			pe.applyWorker.RunTxTaskNoLock(txTask.Reset(), pe.isMining)
 			if txTask.Error != nil {
 				//fmt.Println("RETRY", txTask.TxNum, txTask.Error)
@@ -410,17 +482,18 @@ func (pe *parallelExecutor) processResultQueue(ctx context.Context, inputTxNum u
 					return outputTxNum, conflicts, triggers, processedBlockNum, false, chaosErr
 				}
 			}
-			// TODO: post-validation of gasUsed and blobGasUsed
 			i++
 		}
 
+		if err := pe.finishTask(txTask, &pe.usedGas, &pe.blobGasUsed, !pe.skipPostEvaluation); err != nil {
+			return outputTxNum, conflicts, triggers, processedBlockNum, false, err
+		}
+		pe.rs.SetTxNum(txTask.TxNum, txTask.BlockNum)
+		if err := pe.applyTask(ctx, txTask, pe.blobGasUsed); err != nil {
+			return outputTxNum, conflicts, triggers, processedBlockNum, false, fmt.Errorf("StateV3.Apply: %w", err)
+		}
+		pe.timeline = append(pe.timeline, newExecTimelineEntry(txTask, 0))
 		if txTask.Final {
-			pe.rs.SetTxNum(txTask.TxNum, txTask.BlockNum)
-			err := pe.rs.ApplyState4(ctx, txTask)
-			if err != nil {
-				return outputTxNum, conflicts, triggers, processedBlockNum, false, fmt.Errorf("StateV3.Apply: %w", err)
-			}
-
 			if processedBlockNum > pe.lastBlockNum.Load() {
 				pe.outputBlockNum.SetUint64(processedBlockNum)
 				pe.lastBlockNum.Store(processedBlockNum)
@@ -430,6 +503,7 @@ func (pe *parallelExecutor) processResultQueue(ctx context.Context, inputTxNum u
 			//	return outputTxNum, conflicts, triggers, processedBlockNum, false, fmt.Errorf("block hashk mismatch: %x != %x bn =%d, txn= %d", rh, txTask.BlockRoot[:], txTask.BlockNum, txTask.TxNum)
 			//}
 		}
+		mxExecTaskRetries.Observe(float64(txTask.Incarnation))
 		triggers += pe.rs.CommitTxNum(txTask.Sender(), txTask.TxNum, pe.in)
 		outputTxNum++
 		if backPressure != nil {
@@ -438,9 +512,6 @@ func (pe *parallelExecutor) processResultQueue(ctx context.Context, inputTxNum u
 			default:
 			}
 		}
-		if err := pe.rs.ApplyLogsAndTraces4(txTask, pe.rs.Domains()); err != nil {
-			return outputTxNum, conflicts, triggers, processedBlockNum, false, fmt.Errorf("StateV3.Apply: %w", err)
-		}
 		processedBlockNum = txTask.BlockNum
 		if !stopedAtBlockEnd {
 			stopedAtBlockEnd = txTask.Final
@@ -11,15 +11,14 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	state2 "github.com/erigontech/erigon-lib/state"
 	"github.com/erigontech/erigon/consensus"
-	"github.com/erigontech/erigon/core"
-	"github.com/erigontech/erigon/core/rawdb/rawtemporaldb"
 	"github.com/erigontech/erigon/core/state"
-	"github.com/erigontech/erigon/core/types"
 )
 
 type serialExecutor struct {
 	txExecutor
 	skipPostEvaluation bool
+	// tasks of the block being executed, captured into the quarantine if it turns out bad
+	timeline []ExecTimelineEntry
 	// outputs
 	txCount     uint64
 	usedGas     uint64
@@ -40,7 +39,12 @@ func (se *serialExecutor) execute(ctx context.Context, tasks []*state.TxTask) (c
 			return false, nil
 		}
 
+		if txTask.TxIndex == -1 {
+			se.timeline = se.timeline[:0]
+		}
+		taskStart := time.Now()
 		se.applyWorker.RunTxTaskNoLock(txTask, se.isMining)
+		se.timeline = append(se.timeline, newExecTimelineEntry(txTask, time.Since(taskStart)))
 		if err := func() error {
 			if errors.Is(txTask.Error, context.Canceled) {
 				return txTask.Error
@@ -50,30 +54,13 @@ func (se *serialExecutor) execute(ctx context.Context, tasks []*state.TxTask) (c
 			}
 
 			se.txCount++
-			se.usedGas += txTask.UsedGas
 			mxExecGas.Add(float64(txTask.UsedGas))
 			mxExecTransactions.Add(1)
 
-			if txTask.Tx != nil {
-				se.blobGasUsed += txTask.Tx.GetBlobGas()
+			if err := se.finishTask(txTask, &se.usedGas, &se.blobGasUsed, !se.skipPostEvaluation); err != nil {
+				return err
 			}
-
-			txTask.CreateReceipt(se.applyTx)
-
 			if txTask.Final {
-				if !se.isMining && !se.inMemExec && !se.skipPostEvaluation && !se.execStage.CurrentSyncCycle.IsInitialCycle {
-					// note this assumes the bloach reciepts is a fixed array shared by
-					// all tasks - if that changes this will need to change - robably need to
-					// add this to the executor
-					se.cfg.notifications.RecentLogs.Add(txTask.BlockReceipts)
-				}
-				checkReceipts := !se.cfg.vmConfig.StatelessExec && se.cfg.chainConfig.IsByzantium(txTask.BlockNum) && !se.cfg.vmConfig.NoReceipts && !se.isMining
-				if txTask.BlockNum > 0 && !se.skipPostEvaluation { //Disable check for genesis. Maybe need somehow improve it in future - to satisfy TestExecutionSpec
-					if err := core.BlockPostValidation(se.usedGas, se.blobGasUsed, checkReceipts, txTask.BlockReceipts, txTask.Header, se.isMining); err != nil {
-						return fmt.Errorf("%w, txnIdx=%d, %v", consensus.ErrInvalidBlock, txTask.TxIndex, err) //same as in stage_exec.go
-					}
-				}
-
 				se.outputBlockNum.SetUint64(txTask.BlockNum)
 			}
 			if se.cfg.syncCfg.ChaosMonkey {
@@ -93,6 +80,9 @@ func (se *serialExecutor) execute(ctx context.Context, tasks []*state.TxTask) (c
 			if se.cfg.hd != nil && se.cfg.hd.POSSync() && errors.Is(err, consensus.ErrInvalidBlock) {
 				se.cfg.hd.ReportBadHeaderPoS(txTask.Header.Hash(), txTask.Header.ParentHash)
 			}
+			if errors.Is(err, consensus.ErrInvalidBlock) {
+				se.quarantine(ctx, se.applyTx, txTask, err, se.timeline)
+			}
 			if se.cfg.badBlockHalt {
 				return false, err
 			}
@@ -112,18 +102,7 @@ func (se *serialExecutor) execute(ctx context.Context, tasks []*state.TxTask) (c
 			return false, nil
 		}
 
-		if !txTask.Final {
-			var receipt *types.Receipt
-			if txTask.TxIndex >= 0 && !txTask.Final {
-				receipt = txTask.BlockReceipts[txTask.TxIndex]
-			}
-			if err := rawtemporaldb.AppendReceipt(se.doms, receipt, se.blobGasUsed); err != nil {
-				return false, err
-			}
-		}
-
-		// MA applystate
-		if err := se.rs.ApplyState4(ctx, txTask); err != nil {
+		if err := se.applyTask(ctx, txTask, se.blobGasUsed); err != nil {
 			return false, err
 		}
 
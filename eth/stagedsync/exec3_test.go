@@ -0,0 +1,440 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	state2 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/cmd/state/exec3"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/consensus/ethash"
+	"github.com/erigontech/erigon/consensus/merge"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/rawdb"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/polygon/heimdall"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var (
+	// emits a log with the caller as its topic
+	logCallerCode = []byte{byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1), byte(vm.STOP)}
+	// stores the block number: system contracts write the state at the block init and at the block end
+	storeNumberCode = []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)}
+)
+
+type testExecChain struct {
+	genesis *types.Genesis
+	engine  consensus.Engine
+	blocks  []*types.Block
+}
+
+// newTestExecChain generates PoS blocks with logs, withdrawals and system contracts called at the block init (EIP-4788)
+// and at the block end (EIP-7002, EIP-7251). Their headers are post-validated by the execution.
+func newTestExecChain(t *testing.T, blockCount int) *testExecChain {
+	config := *params.AllProtocolChanges
+	config.PragueTime = big.NewInt(0)
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	logContract := libcommon.HexToAddress("0x1000")
+	genesis := &types.Genesis{
+		Config:     &config,
+		GasLimit:   30_000_000,
+		Difficulty: big.NewInt(0),
+		Alloc: types.GenesisAlloc{
+			crypto.PubkeyToAddress(key1.PublicKey): {Balance: big.NewInt(params.Ether)},
+			crypto.PubkeyToAddress(key2.PublicKey): {Balance: big.NewInt(params.Ether)},
+			logContract:                            {Code: logCallerCode, Balance: big.NewInt(0)},
+			params.BeaconRootsAddress:              {Code: storeNumberCode, Balance: big.NewInt(0)},
+			params.WithdrawalRequestAddress:        {Code: storeNumberCode, Balance: big.NewInt(0)},
+			params.ConsolidationRequestAddress:     {Code: storeNumberCode, Balance: big.NewInt(0)},
+		},
+	}
+	genesisBlock, _, err := core.GenesisToBlock(genesis, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+
+	c := &testExecChain{genesis: genesis, engine: merge.New(ethash.NewFaker()), blocks: []*types.Block{genesisBlock}}
+	signer := types.LatestSignerForChainID(config.ChainID)
+	sign := func(key *ecdsa.PrivateKey, nonce uint64, to libcommon.Address, gas uint64) types.Transaction {
+		txn, err := types.SignTx(types.NewTransaction(nonce, to, uint256.NewInt(1), gas, uint256.NewInt(2*params.GWei), nil), *signer, key)
+		require.NoError(t, err)
+		return txn
+	}
+	// the headers of the blocks are copies, these ones don't have a cached hash
+	headers := make([]*types.Header, blockCount+1)
+	for i := 1; i <= blockCount; i++ {
+		parent := c.blocks[i-1]
+		header := &types.Header{
+			ParentHash:            parent.Hash(),
+			Coinbase:              libcommon.HexToAddress("0xc0"),
+			Number:                big.NewInt(int64(i)),
+			GasLimit:              parent.GasLimit(),
+			Time:                  parent.Time() + 12,
+			Difficulty:            big.NewInt(0),
+			BaseFee:               big.NewInt(params.InitialBaseFee),
+			BlobGasUsed:           new(uint64),
+			ExcessBlobGas:         new(uint64),
+			ParentBeaconBlockRoot: &libcommon.Hash{byte(i)},
+		}
+		txs := []types.Transaction{
+			sign(key1, uint64(2*(i-1)), logContract, 50_000),
+			sign(key2, uint64(i-1), libcommon.HexToAddress("0x2000"), params.TxGas),
+			sign(key1, uint64(2*(i-1)+1), logContract, 50_000),
+		}
+		withdrawals := []*types.Withdrawal{
+			{Index: uint64(2 * i), Validator: 1, Address: libcommon.HexToAddress("0x3000"), Amount: 1},
+			{Index: uint64(2*i + 1), Validator: 2, Address: libcommon.HexToAddress("0x3000"), Amount: 2},
+		}
+		headers[i] = header
+		c.blocks = append(c.blocks, types.NewBlock(header, txs, nil, nil, withdrawals))
+	}
+
+	// the gas used, the receipts root and the state root of the headers are taken from a run without post-validation
+	dryRun := c.executeSerial(t, false)
+	for i := 1; i <= blockCount; i++ {
+		receipts := dryRun.receipts[i]
+		for _, r := range receipts {
+			r.Bloom = types.CreateBloom(types.Receipts{r})
+		}
+		header := headers[i]
+		header.ParentHash = c.blocks[i-1].Hash()
+		header.GasUsed = receipts[len(receipts)-1].CumulativeGasUsed
+		header.Root = dryRun.roots[i]
+		c.blocks[i] = types.NewBlock(header, c.blocks[i].Transactions(), nil, receipts, c.blocks[i].Withdrawals())
+	}
+	return c
+}
+
+// tasks of the blocks, same as ExecV3 makes them
+func (c *testExecChain) tasks(t *testing.T) (tasks [][]*state.TxTask) {
+	var txNum uint64
+	for _, b := range c.blocks {
+		blockTasks, err := exec3.BlockTxTasks(c.genesis.Config, c.engine, nil, b, txNum, func(uint64) libcommon.Hash { return libcommon.Hash{} })
+		require.NoError(t, err)
+		tasks = append(tasks, blockTasks)
+		txNum += uint64(len(blockTasks))
+	}
+	return tasks
+}
+
+func (c *testExecChain) initTxExecutor(t *testing.T, te *txExecutor) {
+	ctx, logger := context.Background(), log.New()
+	dirs := datadir.New(t.TempDir())
+	db, agg := temporaltest.NewTestDB(t, dirs)
+	tx, err := db.BeginRw(ctx) //nolint:gocritic
+	require.NoError(t, err)
+	t.Cleanup(tx.Rollback)
+	doms, err := state2.NewSharedDomains(tx, logger)
+	require.NoError(t, err)
+	t.Cleanup(doms.Close)
+
+	// the blocks are in the db for the quarantine and the dry run
+	var maxTxNum uint64
+	for _, b := range c.blocks {
+		signer := types.MakeSigner(c.genesis.Config, b.NumberU64(), b.Time())
+		senders := make([]libcommon.Address, len(b.Transactions()))
+		for i, txn := range b.Transactions() {
+			senders[i], err = signer.Sender(txn)
+			require.NoError(t, err)
+		}
+		require.NoError(t, rawdb.WriteBlock(tx, b))
+		require.NoError(t, rawdb.WriteCanonicalHash(tx, b.Hash(), b.NumberU64()))
+		require.NoError(t, rawdb.WriteSenders(tx, b.Hash(), b.NumberU64(), senders))
+		maxTxNum += uint64(len(b.Transactions())) + 2
+		require.NoError(t, rawdbv3.TxNums.Append(tx, b.NumberU64(), maxTxNum-1))
+	}
+	freezingCfg := ethconfig.Defaults.Snapshot
+	blockReader := freezeblocks.NewBlockReader(freezeblocks.NewRoSnapshots(freezingCfg, dirs.Snap, 0, logger), heimdall.NewRoSnapshots(freezingCfg, dirs.Snap, 0, logger), nil, nil)
+
+	te.cfg = ExecuteBlockCfg{db: db, chainConfig: c.genesis.Config, engine: c.engine, vmConfig: &vm.Config{}, badBlockHalt: true, dirs: dirs, genesis: c.genesis, blockReader: blockReader}
+	te.execStage = &StageState{ID: stages.Execution, CurrentSyncCycle: CurrentSyncCycleInfo{IsInitialCycle: true}}
+	te.agg, te.applyTx, te.doms, te.rs = agg, tx, doms, state.NewStateV3(doms, logger)
+	te.applyWorker = exec3.NewWorker(nil, logger, ctx, false, db, nil, nil, c.genesis.Config, c.genesis, nil, c.engine, dirs, false)
+	te.applyWorker.ResetState(te.rs, nil)
+	te.applyWorker.ResetTx(tx)
+	te.outputTxNum, te.outputBlockNum, te.logger = &atomic.Uint64{}, stages.SyncMetrics[stages.Execution], logger
+}
+
+type testExecResults struct {
+	receipts []types.Receipts
+	roots    []libcommon.Hash // only computed by the serial execution
+	tasks    []*state.TxTask
+	tables   map[string][]string
+}
+
+func newTestExecResults(t *testing.T, te *txExecutor, tasks [][]*state.TxTask) *testExecResults {
+	require.NoError(t, te.doms.Flush(context.Background(), te.applyTx))
+	res := &testExecResults{tables: map[string][]string{}}
+	for _, blockTasks := range tasks {
+		res.receipts = append(res.receipts, blockTasks[0].BlockReceipts)
+		res.tasks = append(res.tasks, blockTasks...)
+	}
+	for _, table := range []string{kv.TblAccountVals, kv.TblStorageVals, kv.TblReceiptVals, kv.TblLogAddressIdx, kv.TblLogTopicsIdx,
+		kv.TblTracesFromIdx, kv.TblTracesToIdx, kv.TblWithdrawalAddressIdx} {
+		require.NoError(t, te.applyTx.ForEach(table, nil, func(k, v []byte) error {
+			res.tables[table] = append(res.tables[table], fmt.Sprintf("%x:%x", k, v))
+			return nil
+		}))
+	}
+	return res
+}
+
+func (c *testExecChain) executeSerial(t *testing.T, postValidation bool) *testExecResults {
+	se := &serialExecutor{skipPostEvaluation: !postValidation}
+	c.initTxExecutor(t, &se.txExecutor)
+	tasks := c.tasks(t)
+	var roots []libcommon.Hash
+	for _, blockTasks := range tasks {
+		se.doms.SetBlockNum(blockTasks[0].BlockNum)
+		cont, err := se.execute(context.Background(), blockTasks)
+		require.NoError(t, err)
+		require.True(t, cont)
+		se.usedGas, se.blobGasUsed = 0, 0
+		root, err := se.doms.ComputeCommitment(context.Background(), true, blockTasks[0].BlockNum, "")
+		require.NoError(t, err)
+		roots = append(roots, libcommon.BytesToHash(root))
+	}
+	res := newTestExecResults(t, &se.txExecutor, tasks)
+	res.roots = roots
+	return res
+}
+
+// executeParallel feeds the results queue of the parallel executor the way its workers do: out of order, transactions
+// failed on a dependency on their predecessors and block ends deferred by the background workers.
+func (c *testExecChain) executeParallel(t *testing.T) *testExecResults {
+	pe := &parallelExecutor{in: state.NewQueueWithRetry(16), rws: state.NewResultsQueue(16, 1)}
+	c.initTxExecutor(t, &pe.txExecutor)
+	tasks := c.tasks(t)
+	require.NoError(t, c.runParallel(t, pe, tasks))
+	return newTestExecResults(t, &pe.txExecutor, tasks)
+}
+
+func (c *testExecChain) runParallel(t *testing.T, pe *parallelExecutor, tasks [][]*state.TxTask) error {
+	ctx := context.Background()
+	worker := exec3.NewWorker(nil, pe.logger, ctx, true, pe.cfg.db, pe.in, nil, c.genesis.Config, c.genesis, pe.rws, c.engine, pe.cfg.dirs, false)
+	worker.ResetState(pe.rs, nil)
+	defer worker.ResetTx(nil)
+
+	errDependency := errors.New("read the state before its predecessor wrote it")
+	var txCount uint64
+	for _, blockTasks := range tasks {
+		for i := len(blockTasks) - 1; i >= 0; i-- {
+			txTask := blockTasks[i]
+			if txTask.Final {
+				worker.RunTxTaskNoLock(txTask, false)
+				if txTask.BlockNum > 0 {
+					require.ErrorIs(t, txTask.Error, exec3.ErrBlockEndDeferred)
+				}
+			} else {
+				txTask.Error = errDependency
+			}
+			pe.rws.Push(txTask)
+			txCount++
+		}
+	}
+
+	var outputTxNum uint64
+	for outputTxNum < txCount {
+		processedTxNum, _, _, _, _, err := pe.processResultQueue(ctx, outputTxNum, nil, false, false)
+		if err != nil {
+			return err
+		}
+		require.Greater(t, processedTxNum, outputTxNum)
+		outputTxNum = processedTxNum
+	}
+	return nil
+}
+
+func TestExecV3SerialAndParallelResults(t *testing.T) {
+	c := newTestExecChain(t, 3)
+	serial := c.executeSerial(t, true)
+	parallel := c.executeParallel(t)
+
+	require.Equal(t, serial.receipts, parallel.receipts)
+	require.Len(t, parallel.tasks, len(serial.tasks))
+	for i, txTask := range serial.tasks {
+		require.Equal(t, txTask.UsedGas, parallel.tasks[i].UsedGas, "txNum=%d", txTask.TxNum)
+		require.Equal(t, txTask.Logs, parallel.tasks[i].Logs, "txNum=%d", txTask.TxNum)
+		require.Equal(t, txTask.TraceFroms, parallel.tasks[i].TraceFroms, "txNum=%d", txTask.TxNum)
+		require.Equal(t, txTask.TraceTos, parallel.tasks[i].TraceTos, "txNum=%d", txTask.TxNum)
+	}
+	// withdrawals, logs, traces and the storage written by the system contracts are there
+	for table, rows := range serial.tables {
+		require.NotEmpty(t, rows, table)
+		require.Equal(t, rows, parallel.tables[table], table)
+	}
+}
+
+// The blocks recorded in a replay file are read back as they were written, and their replay leaves the same state as
+// the Execution stage
+func TestExecV3ReplayRoundTrip(t *testing.T) {
+	c := newTestExecChain(t, 3)
+	serial := c.executeSerial(t, true)
+
+	var buf bytes.Buffer
+	w, err := exec3.NewReplayWriter(&buf, "test", 1, []libcommon.Hash{c.blocks[0].Hash()})
+	require.NoError(t, err)
+	for _, b := range c.blocks[1:] {
+		signer := types.MakeSigner(c.genesis.Config, b.NumberU64(), b.Time())
+		for _, txn := range b.Transactions() {
+			_, err := txn.Sender(*signer)
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Append(b))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := exec3.NewReplayReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer r.Close()
+	require.Equal(t, "test", r.Header.ChainName)
+	require.Equal(t, uint64(1), r.Header.FirstBlock)
+	var replayed []*types.Block
+	for {
+		b, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		replayed = append(replayed, b)
+	}
+	require.Len(t, replayed, len(c.blocks)-1)
+	for i, b := range replayed {
+		require.Equal(t, c.blocks[i+1].Hash(), b.Hash())
+		require.Equal(t, c.blocks[i+1].Body().SendersFromTxs(), b.Body().SendersFromTxs())
+	}
+	require.Equal(t, c.blocks[1].Hash(), r.GetHash(1))
+	require.Equal(t, c.blocks[0].Hash(), r.GetHash(0))
+
+	// replay on top of the genesis state
+	se := &serialExecutor{}
+	c.initTxExecutor(t, &se.txExecutor)
+	genesisTasks := c.tasks(t)[0]
+	cont, err := se.execute(context.Background(), genesisTasks)
+	require.NoError(t, err)
+	require.True(t, cont)
+
+	r, err = exec3.NewReplayReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer r.Close()
+	stats, err := exec3.Replay(context.Background(), r, se.applyWorker, se.rs, se.applyTx, c.genesis.Config, c.engine, uint64(len(genesisTasks)), se.logger)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), stats.Blocks)
+	require.Equal(t, uint64(3), stats.LastBlock)
+
+	// the receipts and the indices aren't written by the replay
+	res := newTestExecResults(t, &se.txExecutor, nil)
+	for _, table := range []string{kv.TblAccountVals, kv.TblStorageVals} {
+		require.NotEmpty(t, serial.tables[table], table)
+		require.Equal(t, serial.tables[table], res.tables[table], table)
+	}
+}
+
+// The dry run executes the blocks in an overlay, with the state roots of a real execution, and leaves the db as it was
+func TestExecDryRun(t *testing.T) {
+	ctx := context.Background()
+	c := newTestExecChain(t, 3)
+	bad := c.blocks[3]
+	badHeader := bad.Header()
+	badHeader.Root = libcommon.Hash{1}
+	c.blocks[3] = bad.WithSeal(badHeader)
+
+	// the db has the state of the genesis block
+	se := &serialExecutor{}
+	c.initTxExecutor(t, &se.txExecutor)
+	cont, err := se.execute(ctx, c.tasks(t)[0])
+	require.NoError(t, err)
+	require.True(t, cont)
+	_, err = se.doms.ComputeCommitment(ctx, true, 0, "")
+	require.NoError(t, err)
+	require.NoError(t, se.doms.Flush(ctx, se.applyTx))
+	se.doms.Close()
+
+	var roots []libcommon.Hash
+	badBlockNum, err := ExecDryRun(ctx, se.applyTx, se.cfg, 1, 3, func(header *types.Header, root libcommon.Hash) {
+		roots = append(roots, root)
+	}, se.logger)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), badBlockNum)
+	require.Equal(t, []libcommon.Hash{c.blocks[1].Root(), c.blocks[2].Root(), bad.Root()}, roots)
+
+	doms, err := state2.NewSharedDomains(se.applyTx, se.logger)
+	require.NoError(t, err)
+	defer doms.Close()
+	require.Zero(t, doms.BlockNum())
+}
+
+// A block whose gas used doesn't match its header is quarantined by the parallel executor with the tasks applied up to
+// the failure
+func TestExecV3ParallelBadBlockQuarantine(t *testing.T) {
+	c := newTestExecChain(t, 2)
+	bad := c.blocks[2]
+	badHeader := bad.Header()
+	badHeader.GasUsed++
+	c.blocks[2] = bad.WithSeal(badHeader)
+
+	pe := &parallelExecutor{in: state.NewQueueWithRetry(16), rws: state.NewResultsQueue(16, 1)}
+	c.initTxExecutor(t, &pe.txExecutor)
+	pe.cfg.syncCfg.BadBlocksKeep = 1
+	require.ErrorIs(t, c.runParallel(t, pe, c.tasks(t)), consensus.ErrInvalidBlock)
+
+	dir := filepath.Join(pe.cfg.dirs.BadBlocks, fmt.Sprintf("%d-%x", badHeader.Number.Uint64(), badHeader.Hash()))
+	var info BadBlockInfo
+	data, err := os.ReadFile(filepath.Join(dir, BadBlockInfoFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &info))
+	require.Equal(t, badHeader.Hash(), info.Hash)
+	require.Equal(t, BadBlockReasonExecution, info.Reason)
+	require.Contains(t, info.Error, "gas used by execution")
+
+	var timeline []ExecTimelineEntry
+	data, err = os.ReadFile(filepath.Join(dir, BadBlockTimelineFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &timeline))
+	require.Len(t, timeline, len(bad.Transactions())+2) // block initialisation, the txns and the block finalisation
+	for i, entry := range timeline {
+		require.Equal(t, i-1, entry.TxIndex)
+	}
+	require.True(t, timeline[len(timeline)-1].Final)
+}
@@ -358,6 +358,13 @@ func logDownloadingBodies(logPrefix string, committed, remaining uint64, totalDe
 	var m runtime.MemStats
 	dbg.ReadMemStats(&m)
 
+	diagnostics.Send(diagnostics.SyncStageProgressUpdate{
+		Stage:  string(stages.Bodies),
+		Time:   time.Now(),
+		Block:  committed,
+		Target: committed + remaining,
+		Bytes:  uint64(deliveredCount),
+	})
 	diagnostics.Send(diagnostics.BodiesDownloadBlockUpdate{
 		BlockNumber:    committed,
 		DeliveryPerSec: uint64(speed),
@@ -388,6 +395,12 @@ func logWritingBodies(logPrefix string, committed, headerProgress uint64, logger
 	dbg.ReadMemStats(&m)
 	remaining := headerProgress - committed
 
+	diagnostics.Send(diagnostics.SyncStageProgressUpdate{
+		Stage:  string(stages.Bodies),
+		Time:   time.Now(),
+		Block:  committed,
+		Target: headerProgress,
+	})
 	diagnostics.Send(diagnostics.BodiesWriteBlockUpdate{
 		BlockNumber: committed,
 		Remaining:   remaining,
@@ -257,6 +257,14 @@ func BorHeimdallForward(
 	var commitBatchLimit = 1_000
 	var commitCnt int
 
+	// the validator sets are checked block by block below, the signers of a whole sprint are
+	// recovered ahead of that in parallel and found in the signatures cache by the checks
+	recoverSigners := errgroup.Group{}
+	recoverSigners.SetLimit(estimate.AlmostAllCPUs())
+	defer func() {
+		_ = recoverSigners.Wait() // goroutines used in this err group do not return err
+	}()
+
 	// newTx==true means a batch has been committed and should init a fresh new tx to handle next batch
 	newTx := false
 	for blockNum = lastBlockNum + 1; blockNum <= headNumber; blockNum++ {
@@ -298,6 +306,23 @@ func BorHeimdallForward(
 			return fmt.Errorf("header not found: %d", blockNum)
 		}
 
+		if blockNum == lastBlockNum+1 || cfg.borConfig.IsSprintStart(blockNum) {
+			sprintEnd := min(blockNum+cfg.borConfig.CalculateSprintLength(blockNum)-1, headNumber)
+			for sprintBlockNum := blockNum; sprintBlockNum <= sprintEnd; sprintBlockNum++ {
+				sprintHeader, err := cfg.blockReader.HeaderByNumber(ctx, tx, sprintBlockNum)
+				if err != nil {
+					return err
+				}
+				if sprintHeader == nil {
+					break
+				}
+				recoverSigners.Go(func() error {
+					_, _ = bor.Ecrecover(sprintHeader, signatures, cfg.borConfig)
+					return nil
+				})
+			}
+		}
+
 		// Whitelist whitelistService is called to check if the bor chainReader is
 		// on the canonical chainReader according to milestones
 		if whitelistService != nil && !whitelistService.IsValidChain(blockNum, []*types.Header{header}) {
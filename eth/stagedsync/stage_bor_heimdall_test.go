@@ -293,3 +293,40 @@ func TestBorHeimdallForwardDetectsUnauthorizedSignerError(t *testing.T) {
 	require.Equal(t, invalidHeader.Number.Uint64(), unauthorizedSignerErr.Number)
 	require.Equal(t, crypto.PubkeyToAddress(validatorKey1.PublicKey).Bytes(), unauthorizedSignerErr.Signer)
 }
+
+func TestBorVerifySprintHeaders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	numBlocks := 64
+	chainConfig := stagedsynctest.BorDevnetChainConfigWithNoBlockSealDelays()
+	testHarness := stagedsynctest.InitHarness(ctx, t, stagedsynctest.HarnessCfg{
+		ChainConfig:            chainConfig,
+		GenerateChainNumBlocks: numBlocks,
+		LogLvl:                 log.LvlError,
+	})
+
+	// a batch spanning several sprints
+	headers := make([]*types.Header, 0, numBlocks)
+	for num := uint64(1); num <= uint64(numBlocks); num++ {
+		header, err := testHarness.ReadHeaderByNumber(ctx, num)
+		require.NoError(t, err)
+		headers = append(headers, header)
+	}
+	require.NoError(t, testHarness.VerifySprintHeaders(t, headers))
+
+	// a header signed by an unknown validator
+	validatorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	invalidHeader := types.CopyHeader(headers[len(headers)-1])
+	sighash, err := crypto.Sign(crypto.Keccak256(bor.BorRLP(invalidHeader, testHarness.BorConfig())), validatorKey)
+	require.NoError(t, err)
+	copy(invalidHeader.Extra[len(invalidHeader.Extra)-types.ExtraSealLength:], sighash)
+	err = testHarness.VerifySprintHeaders(t, append(headers[:len(headers)-1:len(headers)-1], invalidHeader))
+	var unauthorizedSignerErr *valset.UnauthorizedSignerError
+	require.ErrorAs(t, err, &unauthorizedSignerErr)
+	require.Equal(t, invalidHeader.Number.Uint64(), unauthorizedSignerErr.Number)
+
+	// the headers of a batch must be consecutive
+	require.Error(t, testHarness.VerifySprintHeaders(t, []*types.Header{headers[0], headers[2]}))
+}
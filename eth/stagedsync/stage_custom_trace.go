@@ -18,6 +18,7 @@ package stagedsync
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"runtime"
 	"time"
@@ -26,12 +27,14 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/datadir"
 	"github.com/erigontech/erigon-lib/common/dbg"
+	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
 	state2 "github.com/erigontech/erigon-lib/state"
 	"github.com/erigontech/erigon/cmd/state/exec3"
 	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/rawdb/rawtemporaldb"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
@@ -165,7 +168,130 @@ func customTraceBatchProduce(ctx context.Context, cfg *exec3.ExecArgs, db kv.RwD
 	return nil
 }
 
+// customTraceBatch fills the receipts domain for the blocks [fromBlock, toBlock). Blocks whose receipts are kept in the
+// legacy tables are imported from them, once checked against the headers, the other blocks are re-executed.
 func customTraceBatch(ctx context.Context, cfg *exec3.ExecArgs, tx kv.TemporalRwTx, doms *state2.SharedDomains, fromBlock, toBlock uint64, logPrefix string, logger log.Logger) error {
+	for fromBlock < toBlock {
+		execTo, err := nextLegacyReceiptsBlock(tx, fromBlock, toBlock)
+		if err != nil {
+			return err
+		}
+		if execTo > fromBlock {
+			if err := customTraceBatchExec(ctx, cfg, tx, doms, fromBlock, execTo, logPrefix, logger); err != nil {
+				return err
+			}
+			fromBlock = execTo
+			continue
+		}
+		importedTo, err := customTraceImport(ctx, cfg, tx, doms, fromBlock, toBlock, logPrefix, logger)
+		if err != nil {
+			return err
+		}
+		if importedTo == fromBlock {
+			// the legacy receipts can't be trusted, re-executing the rest of the batch
+			return customTraceBatchExec(ctx, cfg, tx, doms, fromBlock, toBlock, logPrefix, logger)
+		}
+		fromBlock = importedTo
+	}
+	return nil
+}
+
+// nextLegacyReceiptsBlock returns the first block from fromBlock on with receipts in the legacy tables, toBlock if none
+func nextLegacyReceiptsBlock(tx kv.Tx, fromBlock, toBlock uint64) (uint64, error) {
+	c, err := tx.Cursor(kv.Receipts)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	k, _, err := c.Seek(hexutility.EncodeTs(fromBlock))
+	if err != nil {
+		return 0, err
+	}
+	if k == nil {
+		return toBlock, nil
+	}
+	return min(binary.BigEndian.Uint64(k), toBlock), nil
+}
+
+// customTraceImport fills the receipts domain from the receipts kept in the legacy tables, from fromBlock on, without
+// re-executing the blocks. It stops at the first block without legacy receipts, or whose legacy receipts don't match
+// its header, and returns the block it stopped at.
+func customTraceImport(ctx context.Context, cfg *exec3.ExecArgs, tx kv.TemporalRwTx, doms *state2.SharedDomains, fromBlock, toBlock uint64, logPrefix string, logger log.Logger) (uint64, error) {
+	logEvery := time.NewTicker(5 * time.Second)
+	defer logEvery.Stop()
+
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, cfg.BlockReader))
+	doms.SetTx(tx)
+	blockNum := fromBlock
+	for ; blockNum < toBlock; blockNum++ {
+		select {
+		case <-ctx.Done():
+			return blockNum, ctx.Err()
+		case <-logEvery.C:
+			logger.Info(fmt.Sprintf("[%s] Importing legacy receipts", logPrefix), "block", blockNum)
+		default:
+		}
+		block, err := cfg.BlockReader.BlockByNumber(ctx, tx, blockNum)
+		if err != nil {
+			return blockNum, err
+		}
+		if block == nil {
+			return blockNum, fmt.Errorf("nil block %d", blockNum)
+		}
+		receipts := rawdb.ReadRawReceipts(tx, blockNum)
+		if err := checkLegacyReceipts(block, receipts); err != nil {
+			logger.Debug(fmt.Sprintf("[%s] Legacy receipts not imported", logPrefix), "block", blockNum, "err", err)
+			return blockNum, nil
+		}
+
+		txNum, err := txNumsReader.Min(tx, blockNum)
+		if err != nil {
+			return blockNum, err
+		}
+		// the system transaction opening the block has no receipt
+		doms.SetTxNum(txNum)
+		if err := rawtemporaldb.AppendReceipt(doms, nil, 0); err != nil {
+			return blockNum, err
+		}
+		var cumulativeBlobGasUsedInBlock uint64
+		var firstLogIndexWithinBlock uint32
+		for i, txn := range block.Transactions() {
+			cumulativeBlobGasUsedInBlock += txn.GetBlobGas()
+			receipts[i].FirstLogIndexWithinBlock = firstLogIndexWithinBlock
+			firstLogIndexWithinBlock += uint32(len(receipts[i].Logs))
+			doms.SetTxNum(txNum + 1 + uint64(i))
+			if err := rawtemporaldb.AppendReceipt(doms, receipts[i], cumulativeBlobGasUsedInBlock); err != nil {
+				return blockNum, err
+			}
+		}
+	}
+	return blockNum, nil
+}
+
+// checkLegacyReceipts checks the receipts read from the legacy tables against the receipts root and the blob gas used of
+// the block header. The consensus fields not kept in the legacy tables are filled in.
+func checkLegacyReceipts(block *types.Block, receipts types.Receipts) error {
+	txs := block.Transactions()
+	if len(receipts) != len(txs) {
+		return fmt.Errorf("%d legacy receipts for %d transactions", len(receipts), len(txs))
+	}
+	var blobGasUsed uint64
+	for i, txn := range txs {
+		receipts[i].Type = txn.Type()
+		receipts[i].Bloom = types.CreateBloom(types.Receipts{receipts[i]})
+		blobGasUsed += txn.GetBlobGas()
+	}
+	header := block.HeaderNoCopy()
+	if root := types.DeriveSha(receipts); root != header.ReceiptHash {
+		return fmt.Errorf("receipts root mismatch: %x, header %x", root, header.ReceiptHash)
+	}
+	if header.BlobGasUsed != nil && *header.BlobGasUsed != blobGasUsed {
+		return fmt.Errorf("blob gas used mismatch: %d, header %d", blobGasUsed, *header.BlobGasUsed)
+	}
+	return nil
+}
+
+func customTraceBatchExec(ctx context.Context, cfg *exec3.ExecArgs, tx kv.TemporalRwTx, doms *state2.SharedDomains, fromBlock, toBlock uint64, logPrefix string, logger log.Logger) error {
 	const logPeriod = 5 * time.Second
 	logEvery := time.NewTicker(logPeriod)
 	defer logEvery.Stop()
@@ -0,0 +1,64 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/core/types"
+)
+
+func TestCheckLegacyReceipts(t *testing.T) {
+	chainID := uint256.NewInt(1)
+	txs := []types.Transaction{
+		types.NewTransaction(0, libcommon.Address{1}, uint256.NewInt(1), 21000, uint256.NewInt(1), nil),
+		types.NewEIP1559Transaction(*chainID, 1, libcommon.Address{1}, uint256.NewInt(1), 50000, uint256.NewInt(1), uint256.NewInt(1), uint256.NewInt(1), nil),
+	}
+	receipts := types.Receipts{
+		{Type: types.LegacyTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000},
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 60000, Logs: types.Logs{
+			{Address: libcommon.Address{2}, Topics: []libcommon.Hash{{3}}},
+		}},
+	}
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, txs, nil, receipts, nil)
+
+	// the legacy tables keep neither the type nor the bloom of the receipts
+	legacyReceipts := func() types.Receipts {
+		legacy := make(types.Receipts, len(receipts))
+		for i, r := range receipts {
+			legacy[i] = &types.Receipt{Status: r.Status, CumulativeGasUsed: r.CumulativeGasUsed, Logs: r.Logs}
+		}
+		return legacy
+	}
+	require.NoError(t, checkLegacyReceipts(block, legacyReceipts()))
+
+	legacy := legacyReceipts()
+	legacy[1].CumulativeGasUsed++
+	require.ErrorContains(t, checkLegacyReceipts(block, legacy), "receipts root mismatch")
+	require.ErrorContains(t, checkLegacyReceipts(block, legacyReceipts()[:1]), "1 legacy receipts for 2 transactions")
+
+	// blocks without transactions have no legacy receipts
+	require.NoError(t, checkLegacyReceipts(types.NewBlock(&types.Header{Number: big.NewInt(2)}, nil, nil, nil, nil), nil))
+}
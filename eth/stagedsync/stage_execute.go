@@ -91,6 +91,7 @@ type ExecuteBlockCfg struct {
 	blockProduction bool
 
 	applyWorker, applyWorkerMining *exec3.Worker
+	prefetcher                     *exec3.Prefetcher
 }
 
 func StageExecuteBlocksCfg(
@@ -110,6 +111,7 @@ func StageExecuteBlocksCfg(
 	genesis *types.Genesis,
 	syncCfg ethconfig.Sync,
 	silkworm *silkworm.Silkworm,
+	prefetcher *exec3.Prefetcher,
 ) ExecuteBlockCfg {
 	if dirs.SnapDomain == "" {
 		panic("empty `dirs` variable")
@@ -132,6 +134,7 @@ func StageExecuteBlocksCfg(
 		historyV3:         true,
 		syncCfg:           syncCfg,
 		silkworm:          silkworm,
+		prefetcher:        prefetcher,
 		applyWorker:       exec3.NewWorker(nil, log.Root(), context.Background(), false, db, nil, blockReader, chainConfig, genesis, nil, engine, dirs, false),
 		applyWorkerMining: exec3.NewWorker(nil, log.Root(), context.Background(), false, db, nil, blockReader, chainConfig, genesis, nil, engine, dirs, true),
 	}
@@ -379,7 +382,14 @@ func unwindExecutionStage(u *UnwindState, s *StageState, txc wrap.TxContainer, c
 		accumulator.StartChange(u.UnwindPoint, hash, txs, true)
 	}
 
-	return unwindExec3(u, s, txc, ctx, cfg.blockReader, accumulator, logger)
+	if err := unwindExec3(u, s, txc, ctx, cfg.blockReader, accumulator, logger); err != nil {
+		return err
+	}
+	// same conditions as of RecentLogs.Add: receipts of in-memory execution and of initial cycle are not there
+	if cfg.notifications != nil && cfg.notifications.RecentLogs != nil && txc.Doms == nil && !u.CurrentSyncCycle.IsInitialCycle {
+		cfg.notifications.RecentLogs.Unwind(u.UnwindPoint)
+	}
+	return nil
 }
 
 func PruneExecutionStage(s *PruneState, tx kv.RwTx, cfg ExecuteBlockCfg, ctx context.Context, logger log.Logger) (err error) {
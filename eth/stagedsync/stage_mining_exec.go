@@ -59,6 +59,8 @@ type MiningExecCfg struct {
 	interrupt   *int32
 	payloadId   uint64
 	txnProvider txnprovider.TxnProvider
+	// txnsValidator checks the txns of the built block against the ordering of the txn provider, nil if none
+	txnsValidator txnprovider.BlockTxnsValidator
 }
 
 func StageMiningExecCfg(
@@ -72,20 +74,22 @@ func StageMiningExecCfg(
 	interrupt *int32,
 	payloadId uint64,
 	txnProvider txnprovider.TxnProvider,
+	txnsValidator txnprovider.BlockTxnsValidator,
 	blockReader services.FullBlockReader,
 ) MiningExecCfg {
 	return MiningExecCfg{
-		db:          db,
-		miningState: miningState,
-		notifier:    notifier,
-		chainConfig: chainConfig,
-		engine:      engine,
-		blockReader: blockReader,
-		vmConfig:    vmConfig,
-		tmpdir:      tmpdir,
-		interrupt:   interrupt,
-		payloadId:   payloadId,
-		txnProvider: txnProvider,
+		db:            db,
+		miningState:   miningState,
+		notifier:      notifier,
+		chainConfig:   chainConfig,
+		engine:        engine,
+		blockReader:   blockReader,
+		vmConfig:      vmConfig,
+		tmpdir:        tmpdir,
+		interrupt:     interrupt,
+		payloadId:     payloadId,
+		txnProvider:   txnProvider,
+		txnsValidator: txnsValidator,
 	}
 }
 
@@ -124,7 +128,7 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 	if noempty {
 
 		if len(preparedTxns) > 0 {
-			logs, _, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, preparedTxns, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, logger)
+			logs, _, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, preparedTxns, nil, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, logger)
 			if err != nil {
 				return err
 			}
@@ -150,15 +154,17 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 				return err
 			}
 
+			// the bundles, if the txn provider yields any, are included as a whole or not at all
+			bundles, _ := cfg.txnProvider.(txnprovider.BundleIndex)
 			const amount = 50
 			for {
-				txns, err := getNextTransactions(ctx, cfg, chainID, current.Header, amount, executionAt, yielded, simStateReader, simStateWriter, logger)
+				txns, err := getNextTransactions(ctx, cfg, chainID, current.Header, amount, executionAt, yielded, bundles, simStateReader, simStateWriter, logger)
 				if err != nil {
 					return err
 				}
 
 				if len(txns) > 0 {
-					logs, stop, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, txns, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, logger)
+					logs, stop, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, txns, bundles, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, logger)
 					if err != nil {
 						return err
 					}
@@ -180,6 +186,12 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 		}
 	}
 
+	if cfg.txnsValidator != nil {
+		if err := cfg.txnsValidator.ValidateBlockTxns(ctx, current.Header.Time, current.Txns); err != nil {
+			logger.Warn(fmt.Sprintf("[%s] built block breaks the txn provider ordering", logPrefix), "block", current.Header.Number, "err", err)
+		}
+	}
+
 	logger.Debug("SpawnMiningExecStage", "block", current.Header.Number, "txn", current.Txns.Len(), "payload", cfg.payloadId)
 	if current.Uncles == nil {
 		current.Uncles = []*types.Header{}
@@ -259,6 +271,7 @@ func getNextTransactions(
 	amount int,
 	executionAt uint64,
 	alreadyYielded mapset.Set[[32]byte],
+	bundles txnprovider.BundleIndex,
 	simStateReader state.StateReader,
 	simStateWriter state.StateWriter,
 	logger log.Logger,
@@ -272,23 +285,42 @@ func getNextTransactions(
 	provideOpts := []txnprovider.ProvideOption{
 		txnprovider.WithAmount(amount),
 		txnprovider.WithParentBlockNum(executionAt),
+		txnprovider.WithBlockTime(header.Time),
 		txnprovider.WithGasTarget(remainingGas),
 		txnprovider.WithBlobGasTarget(remainingBlobGas),
 		txnprovider.WithTxnIdsFilter(alreadyYielded),
 	}
+	if header.BaseFee != nil {
+		baseFee, overflow := uint256.FromBig(header.BaseFee)
+		if overflow {
+			return nil, fmt.Errorf("bad baseFee %s", header.BaseFee)
+		}
+		provideOpts = append(provideOpts, txnprovider.WithBaseFee(baseFee))
+	}
 
 	txns, err := cfg.txnProvider.ProvideTxns(ctx, provideOpts...)
 	if err != nil {
 		return nil, err
 	}
 
+	// the bundles come first, they are checked by executing them as a whole as the filter could drop or reorder some
+	// of their transactions
+	var bundleTxns []types.Transaction
+	for bundles != nil && len(txns) > 0 {
+		if _, ok := bundles.BundleOf(txns[0].Hash()); !ok {
+			break
+		}
+		bundleTxns = append(bundleTxns, txns[0])
+		txns = txns[1:]
+	}
+
 	blockNum := executionAt + 1
 	txns, err = filterBadTransactions(txns, chainID, cfg.chainConfig, blockNum, header, simStateReader, simStateWriter, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	return txns, nil
+	return append(bundleTxns, txns...), nil
 }
 
 func filterBadTransactions(transactions []types.Transaction, chainID *uint256.Int, config chain.Config, blockNumber uint64, header *types.Header, simStateReader state.StateReader, simStateWriter state.StateWriter, logger log.Logger) ([]types.Transaction, error) {
@@ -438,6 +470,7 @@ func addTransactionsToMiningBlock(
 	getHeader func(hash libcommon.Hash, number uint64) *types.Header,
 	engine consensus.Engine,
 	txns types.Transactions,
+	bundles txnprovider.BundleIndex,
 	coinbase libcommon.Address,
 	ibs *state.IntraBlockState,
 	interrupt *int32,
@@ -472,6 +505,72 @@ func addTransactionsToMiningBlock(
 		return receipt.Logs, nil
 	}
 
+	// the state before the bundle being added, it's reverted to if any of the bundle's transactions fails. The journal
+	// of the state is cleared after each transaction, so the state is forked instead of taking a snapshot.
+	type bundleCheckpoint struct {
+		ibs          *state.IntraBlockState
+		gas, blobGas uint64
+		gasUsed      uint64
+		blobGasUsed  uint64
+		txns, logs   int
+		txnIdx       int
+		left         int // transactions of the bundle still to add
+	}
+	var bundle *bundleCheckpoint
+	revertBundle := func() {
+		*ibs = *bundle.ibs
+		gasPool = new(core.GasPool).AddGas(bundle.gas).AddBlobGas(bundle.blobGas)
+		header.GasUsed = bundle.gasUsed
+		if header.BlobGasUsed != nil {
+			*header.BlobGasUsed = bundle.blobGasUsed
+		}
+		current.Txns = current.Txns[:bundle.txns]
+		current.Receipts = current.Receipts[:bundle.txns]
+		coalescedLogs = coalescedLogs[:bundle.logs]
+		txnIdx = bundle.txnIdx
+	}
+
+	// addTxn returns true if the transaction has been added to the block
+	addTxn := func(txn types.Transaction) bool {
+		// We use the eip155 signer regardless of the env hf.
+		from, err := txn.Sender(*signer)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("[%s] Could not recover transaction sender", logPrefix), "hash", txn.Hash(), "err", err)
+			return false
+		}
+
+		// Check whether the txn is replay protected. If we're not in the EIP155 (Spurious Dragon) hf
+		// phase, start ignoring the sender until we do.
+		if txn.Protected() && !chainConfig.IsSpuriousDragon(header.Number.Uint64()) {
+			logger.Debug(fmt.Sprintf("[%s] Ignoring replay protected transaction", logPrefix), "hash", txn.Hash(), "eip155", chainConfig.SpuriousDragonBlock)
+			return false
+		}
+
+		// Start executing the transaction
+		logs, err := miningCommitTx(txn, coinbase, vmConfig, chainConfig, ibs, current)
+		if errors.Is(err, core.ErrGasLimitReached) {
+			// Skip the env out-of-gas transaction
+			logger.Debug(fmt.Sprintf("[%s] Gas limit exceeded for env block", logPrefix), "hash", txn.Hash(), "sender", from)
+		} else if errors.Is(err, core.ErrNonceTooLow) {
+			// New head notification data race between the transaction pool and miner, skip
+			logger.Debug(fmt.Sprintf("[%s] Skipping transaction with low nonce", logPrefix), "hash", txn.Hash(), "sender", from, "nonce", txn.GetNonce(), "err", err)
+		} else if errors.Is(err, core.ErrNonceTooHigh) {
+			// Reorg notification data race between the transaction pool and miner, skip
+			logger.Debug(fmt.Sprintf("[%s] Skipping transaction with high nonce", logPrefix), "hash", txn.Hash(), "sender", from, "nonce", txn.GetNonce())
+		} else if err == nil {
+			// Everything ok, collect the logs and proceed to the next transaction
+			logger.Trace(fmt.Sprintf("[%s] Added transaction", logPrefix), "hash", txn.Hash(), "sender", from, "nonce", txn.GetNonce(), "payload", payloadId)
+			coalescedLogs = append(coalescedLogs, logs...)
+			txnIdx++
+			return true
+		} else {
+			// Strange error, discard the transaction and get the next in line (note, the
+			// nonce-too-high clause will prevent us from executing in vain).
+			logger.Debug(fmt.Sprintf("[%s] Skipping transaction", logPrefix), "hash", txn.Hash(), "sender", from, "err", err)
+		}
+		return false
+	}
+
 	var stopped *time.Ticker
 	defer func() {
 		if stopped != nil {
@@ -482,7 +581,8 @@ func addTransactionsToMiningBlock(
 	done := false
 
 LOOP:
-	for _, txn := range txns {
+	for i := 0; i < len(txns); i++ {
+		txn := txns[i]
 		// see if we need to stop now
 		if stopped != nil {
 			select {
@@ -509,42 +609,34 @@ LOOP:
 			break
 		}
 
-		// We use the eip155 signer regardless of the env hf.
-		from, err := txn.Sender(*signer)
-		if err != nil {
-			logger.Warn(fmt.Sprintf("[%s] Could not recover transaction sender", logPrefix), "hash", txn.Hash(), "err", err)
-			continue
+		if bundle == nil && bundles != nil {
+			if bundleTxns, ok := bundles.BundleOf(txn.Hash()); ok {
+				bundle = &bundleCheckpoint{ibs: ibs.Fork(), gas: gasPool.Gas(), blobGas: gasPool.BlobGas(),
+					gasUsed: header.GasUsed, txns: len(current.Txns), logs: len(coalescedLogs), txnIdx: txnIdx, left: len(bundleTxns)}
+				if header.BlobGasUsed != nil {
+					bundle.blobGasUsed = *header.BlobGasUsed
+				}
+			}
 		}
 
-		// Check whether the txn is replay protected. If we're not in the EIP155 (Spurious Dragon) hf
-		// phase, start ignoring the sender until we do.
-		if txn.Protected() && !chainConfig.IsSpuriousDragon(header.Number.Uint64()) {
-			logger.Debug(fmt.Sprintf("[%s] Ignoring replay protected transaction", logPrefix), "hash", txn.Hash(), "eip155", chainConfig.SpuriousDragonBlock)
+		added := addTxn(txn)
+		if bundle == nil {
 			continue
 		}
-
-		// Start executing the transaction
-		logs, err := miningCommitTx(txn, coinbase, vmConfig, chainConfig, ibs, current)
-		if errors.Is(err, core.ErrGasLimitReached) {
-			// Skip the env out-of-gas transaction
-			logger.Debug(fmt.Sprintf("[%s] Gas limit exceeded for env block", logPrefix), "hash", txn.Hash(), "sender", from)
-		} else if errors.Is(err, core.ErrNonceTooLow) {
-			// New head notification data race between the transaction pool and miner, skip
-			logger.Debug(fmt.Sprintf("[%s] Skipping transaction with low nonce", logPrefix), "hash", txn.Hash(), "sender", from, "nonce", txn.GetNonce(), "err", err)
-		} else if errors.Is(err, core.ErrNonceTooHigh) {
-			// Reorg notification data race between the transaction pool and miner, skip
-			logger.Debug(fmt.Sprintf("[%s] Skipping transaction with high nonce", logPrefix), "hash", txn.Hash(), "sender", from, "nonce", txn.GetNonce())
-		} else if err == nil {
-			// Everything ok, collect the logs and proceed to the next transaction
-			logger.Trace(fmt.Sprintf("[%s] Added transaction", logPrefix), "hash", txn.Hash(), "sender", from, "nonce", txn.GetNonce(), "payload", payloadId)
-			coalescedLogs = append(coalescedLogs, logs...)
-			txnIdx++
-		} else {
-			// Strange error, discard the transaction and get the next in line (note, the
-			// nonce-too-high clause will prevent us from executing in vain).
-			logger.Debug(fmt.Sprintf("[%s] Skipping transaction", logPrefix), "hash", txn.Hash(), "sender", from, "err", err)
+		bundle.left--
+		if !added {
+			logger.Debug(fmt.Sprintf("[%s] Skipping bundle", logPrefix), "hash", txn.Hash(), "skipped", bundle.left)
+			revertBundle()
+			i += bundle.left
+			bundle = nil
+		} else if bundle.left == 0 {
+			bundle = nil
 		}
 	}
+	if bundle != nil {
+		// stopped in the middle of a bundle
+		revertBundle()
+	}
 
 	/*
 		// Notify resubmit loop to decrease resubmitting interval if env interval is larger
@@ -0,0 +1,119 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	state2 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/consensus/ethash"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/params"
+)
+
+type testBundleIndex map[libcommon.Hash][]types.Transaction
+
+func (idx testBundleIndex) BundleOf(txnHash libcommon.Hash) ([]types.Transaction, bool) {
+	txns, ok := idx[txnHash]
+	return txns, ok
+}
+
+func (idx testBundleIndex) add(txns ...types.Transaction) {
+	for _, txn := range txns {
+		idx[txn.Hash()] = txns
+	}
+}
+
+func TestAddTransactionsToMiningBlockBundles(t *testing.T) {
+	db, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	tx, err := db.BeginTemporalRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	domains, err := state2.NewSharedDomains(tx, log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	ibs := state.New(state.NewReaderV3(domains))
+	chainConfig := params.TestChainConfig
+	require.NoError(t, ibs.AddBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified))
+	require.NoError(t, ibs.FinalizeTx(chainConfig.Rules(0, 0), state.NewNoopWriter()))
+	signer := types.LatestSignerForChainID(chainConfig.ChainID)
+	transfer := func(nonce, value uint64) types.Transaction {
+		txn := types.NewTransaction(nonce, libcommon.Address{1}, uint256.NewInt(value), params.TxGas, uint256.NewInt(1), nil)
+		signed, err := types.SignTx(txn, *signer, key)
+		require.NoError(t, err)
+		return signed
+	}
+	// the second transaction of the broken bundle has a nonce gap, so the first one mustn't be included either
+	broken := []types.Transaction{transfer(0, 1), transfer(5, 1)}
+	single := transfer(0, 2)
+	valid := []types.Transaction{transfer(1, 3), transfer(2, 3)}
+	bundles := testBundleIndex{}
+	bundles.add(broken...)
+	bundles.add(valid...)
+
+	current := &MiningBlock{Header: &types.Header{Number: big.NewInt(1), GasLimit: 1_000_000, Difficulty: big.NewInt(1)}}
+	getHeader := func(libcommon.Hash, uint64) *types.Header { return nil }
+	txns := types.Transactions{broken[0], broken[1], single, valid[0], valid[1]}
+	_, _, err = addTransactionsToMiningBlock(context.Background(), "test", current, *chainConfig, &vm.Config{}, getHeader,
+		ethash.NewFaker(), txns, bundles, libcommon.Address{}, ibs, nil, 0, log.New())
+	require.NoError(t, err)
+
+	require.Equal(t, types.Transactions{single, valid[0], valid[1]}, current.Txns)
+	require.Len(t, current.Receipts, 3)
+	require.Equal(t, 3*params.TxGas, current.Header.GasUsed)
+	// no gaps left by the reverted bundle
+	for i, receipt := range current.Receipts {
+		require.Equal(t, current.Receipts[0].TransactionIndex+uint(i), receipt.TransactionIndex)
+	}
+	nonce, err := ibs.GetNonce(sender)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), nonce)
+	balance, err := ibs.GetBalance(libcommon.Address{1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), balance.Uint64())
+
+	// a bundle cut off by the end of the block is reverted as well
+	current = &MiningBlock{Header: &types.Header{Number: big.NewInt(1), GasLimit: 1_000_000, Difficulty: big.NewInt(1)}}
+	tooBig := []types.Transaction{transfer(3, 1), transfer(4, 1)}
+	bundles.add(tooBig...)
+	current.Header.GasLimit = 3 * params.TxGas / 2
+	_, _, err = addTransactionsToMiningBlock(context.Background(), "test", current, *chainConfig, &vm.Config{}, getHeader,
+		ethash.NewFaker(), tooBig, bundles, libcommon.Address{}, ibs, nil, 0, log.New())
+	require.NoError(t, err)
+	require.Empty(t, current.Txns)
+	require.Zero(t, current.Header.GasUsed)
+	nonce, err = ibs.GetNonce(sender)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), nonce)
+}
@@ -69,6 +69,7 @@ func NewPolygonSyncStageCfg(
 	blockLimit uint,
 	userUnwindTypeOverrides []string,
 	notifications *shards.Notifications,
+	unsafeUnwind bool,
 ) PolygonSyncStageCfg {
 	// using a buffered channel to preserve order of tx actions,
 	// do not expect to ever have more than 50 goroutines blocking on this channel
@@ -97,6 +98,10 @@ func NewPolygonSyncStageCfg(
 			spanStore:      heimdallStore.SpanBlockProducerSelections(),
 			txActionStream: txActionStream,
 		},
+		sprintBlockProducerSelections: &polygonSyncStageSprintSbpsStore{
+			sprintStore:    heimdallStore.SprintBlockProducerSelections(),
+			txActionStream: txActionStream,
+		},
 	}
 	stageBridgeStore := &polygonSyncStageBridgeStore{
 		eventStore:     bridgeStore,
@@ -150,6 +155,7 @@ func NewPolygonSyncStageCfg(
 		events.Events(),
 		notifications,
 		sync.NewWiggleCalculator(borConfig, signaturesCache, heimdallService),
+		unsafeUnwind,
 	)
 	syncService := &polygonSyncStageService{
 		logger:          logger,
@@ -408,16 +414,21 @@ func (s *polygonSyncStageService) runBgComponentsOnce(ctx context.Context) {
 }
 
 type polygonSyncStageHeimdallStore struct {
-	checkpoints                 *polygonSyncStageCheckpointStore
-	milestones                  *polygonSyncStageMilestoneStore
-	spans                       *polygonSyncStageSpanStore
-	spanBlockProducerSelections *polygonSyncStageSbpsStore
+	checkpoints                   *polygonSyncStageCheckpointStore
+	milestones                    *polygonSyncStageMilestoneStore
+	spans                         *polygonSyncStageSpanStore
+	spanBlockProducerSelections   *polygonSyncStageSbpsStore
+	sprintBlockProducerSelections *polygonSyncStageSprintSbpsStore
 }
 
 func (s polygonSyncStageHeimdallStore) SpanBlockProducerSelections() heimdall.EntityStore[*heimdall.SpanBlockProducerSelection] {
 	return s.spanBlockProducerSelections
 }
 
+func (s polygonSyncStageHeimdallStore) SprintBlockProducerSelections() heimdall.EntityStore[*heimdall.SprintBlockProducerSelection] {
+	return s.sprintBlockProducerSelections
+}
+
 func (s polygonSyncStageHeimdallStore) Checkpoints() heimdall.EntityStore[*heimdall.Checkpoint] {
 	return s.checkpoints
 }
@@ -923,6 +934,121 @@ func (s polygonSyncStageSbpsStore) Close() {
 	// no-op
 }
 
+// polygonSyncStageSprintSbpsStore is the store for heimdall.SprintBlockProducerSelection
+type polygonSyncStageSprintSbpsStore struct {
+	sprintStore    heimdall.EntityStore[*heimdall.SprintBlockProducerSelection]
+	txActionStream chan<- polygonSyncStageTxAction
+}
+
+func (s polygonSyncStageSprintSbpsStore) LastEntityId(ctx context.Context) (uint64, bool, error) {
+	type response struct {
+		id  uint64
+		ok  bool
+		err error
+	}
+
+	r, err := awaitTxAction(ctx, s.txActionStream, func(tx kv.RwTx, respond func(r response) error) error {
+		id, ok, err := s.sprintStore.(txStore[*heimdall.SprintBlockProducerSelection]).WithTx(tx).LastEntityId(ctx)
+		return respond(response{id: id, ok: ok, err: err})
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return r.id, r.ok, r.err
+}
+
+func (s polygonSyncStageSprintSbpsStore) SnapType() snaptype.Type {
+	return nil
+}
+
+func (s polygonSyncStageSprintSbpsStore) LastFrozenEntityId() uint64 {
+	return s.sprintStore.LastFrozenEntityId()
+}
+
+func (s polygonSyncStageSprintSbpsStore) LastEntity(ctx context.Context) (*heimdall.SprintBlockProducerSelection, bool, error) {
+	id, ok, err := s.LastEntityId(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	return s.Entity(ctx, id)
+}
+
+func (s polygonSyncStageSprintSbpsStore) Entity(ctx context.Context, id uint64) (*heimdall.SprintBlockProducerSelection, bool, error) {
+	type response struct {
+		v   *heimdall.SprintBlockProducerSelection
+		ok  bool
+		err error
+	}
+
+	r, err := awaitTxAction(ctx, s.txActionStream, func(tx kv.RwTx, respond func(r response) error) error {
+		v, ok, err := s.sprintStore.(txStore[*heimdall.SprintBlockProducerSelection]).WithTx(tx).Entity(ctx, id)
+		return respond(response{v: v, ok: ok, err: err})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return r.v, r.ok, r.err
+}
+
+func (s polygonSyncStageSprintSbpsStore) PutEntity(ctx context.Context, id uint64, entity *heimdall.SprintBlockProducerSelection) error {
+	type response struct {
+		err error
+	}
+
+	r, err := awaitTxAction(ctx, s.txActionStream, func(tx kv.RwTx, respond func(r response) error) error {
+		err := s.sprintStore.(txStore[*heimdall.SprintBlockProducerSelection]).WithTx(tx).PutEntity(ctx, id, entity)
+		return respond(response{err: err})
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.err
+}
+
+func (s polygonSyncStageSprintSbpsStore) RangeFromBlockNum(ctx context.Context, blockNum uint64) ([]*heimdall.SprintBlockProducerSelection, error) {
+	type response struct {
+		result []*heimdall.SprintBlockProducerSelection
+		err    error
+	}
+
+	r, err := awaitTxAction(ctx, s.txActionStream, func(tx kv.RwTx, respond func(r response) error) error {
+		r, err := s.sprintStore.(txStore[*heimdall.SprintBlockProducerSelection]).WithTx(tx).RangeFromBlockNum(ctx, blockNum)
+		return respond(response{result: r, err: err})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.result, r.err
+}
+
+func (s *polygonSyncStageSprintSbpsStore) EntityIdFromBlockNum(ctx context.Context, blockNum uint64) (uint64, bool, error) {
+	panic("polygonSyncStageSprintSbpsStore.EntityIdFromBlockNum not supported")
+}
+
+func (s *polygonSyncStageSprintSbpsStore) DeleteToBlockNum(ctx context.Context, unwindPoint uint64, limit int) (int, error) {
+	panic("polygonSyncStageSprintSbpsStore.DeleteToBlockNum not supported")
+}
+
+func (s *polygonSyncStageSprintSbpsStore) DeleteFromBlockNum(ctx context.Context, unwindPoint uint64) (int, error) {
+	panic("polygonSyncStageSprintSbpsStore.DeleteFromBlockNum not supported")
+}
+
+func (s polygonSyncStageSprintSbpsStore) Prepare(_ context.Context) error {
+	return nil
+}
+
+func (s polygonSyncStageSprintSbpsStore) Close() {
+	// no-op
+}
+
 type polygonSyncStageBridgeStore struct {
 	eventStore     bridge.Store
 	txActionStream chan<- polygonSyncStageTxAction
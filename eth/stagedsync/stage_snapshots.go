@@ -20,7 +20,9 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -730,6 +732,7 @@ type uploadState struct {
 	//remoteHash       string
 	local     bool
 	localHash string
+	checksum  string // sha256 of the file, published in checksums.txt
 }
 
 type snapshotUploader struct {
@@ -944,7 +947,101 @@ func (u *snapshotUploader) uploadManifest(ctx context.Context, remoteRefresh boo
 	_ = os.WriteFile(filepath.Join(u.cfg.dirs.Snap, manifestFile), manifestEntries.Bytes(), 0644)
 	defer os.Remove(filepath.Join(u.cfg.dirs.Snap, manifestFile))
 
-	return u.uploadSession.Upload(ctx, manifestFile)
+	checksums := map[string]string{}
+
+	for file, state := range u.files {
+		if state.remote && len(state.checksum) > 0 {
+			checksums[file] = state.checksum
+		}
+	}
+
+	_ = os.WriteFile(filepath.Join(u.cfg.dirs.Snap, checksumsFile), formatChecksums(checksums), 0644)
+	defer os.Remove(filepath.Join(u.cfg.dirs.Snap, checksumsFile))
+
+	return u.uploadSession.Upload(ctx, manifestFile, checksumsFile)
+}
+
+// checksumsFile - the sha256 of the uploaded segments, in the format of sha256sum: the mirror
+// operators can verify their copy with `sha256sum -c checksums.txt`
+const checksumsFile = "checksums.txt"
+
+func (u *snapshotUploader) downloadChecksums(ctx context.Context) error {
+	u.manifestMutex.Lock()
+	defer u.manifestMutex.Unlock()
+
+	reader, err := u.uploadSession.Cat(ctx, checksumsFile)
+
+	if err != nil {
+		return err
+	}
+
+	checksums, err := parseChecksums(reader)
+
+	if err != nil {
+		return err
+	}
+
+	for file, checksum := range checksums {
+		if state, ok := u.files[file]; ok && state.remote {
+			state.checksum = checksum
+		}
+	}
+
+	return nil
+}
+
+func formatChecksums(checksums map[string]string) []byte {
+	files := make([]string, 0, len(checksums))
+
+	for file := range checksums {
+		files = append(files, file)
+	}
+
+	sort.Strings(files)
+
+	entries := bytes.Buffer{}
+
+	for _, file := range files {
+		fmt.Fprintf(&entries, "%s  %s\n", checksums[file], file)
+	}
+
+	return entries.Bytes()
+}
+
+func parseChecksums(reader io.Reader) (map[string]string, error) {
+	checksums := map[string]string{}
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		checksum, file, ok := strings.Cut(scanner.Text(), "  ")
+
+		if !ok || len(checksum) != 2*sha256.Size {
+			return nil, fmt.Errorf("invalid %s line: %q", checksumsFile, scanner.Text())
+		}
+
+		checksums[file] = checksum
+	}
+
+	return checksums, scanner.Err()
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 func (u *snapshotUploader) refreshFromRemote(ctx context.Context) {
@@ -1177,6 +1274,10 @@ func (u *snapshotUploader) start(ctx context.Context, logger log.Logger) {
 			u.refreshFromRemote(ctx)
 		}
 
+		if err := u.downloadChecksums(ctx); err != nil {
+			logger.Debug("[snapshot uploader] no remote checksums", "err", err)
+		}
+
 		go u.uploadManifest(ctx, refreshFromRemote)
 
 		logger.Debug("[snapshot uploader] starting snapshot subscription...")
@@ -1478,6 +1579,14 @@ func (u *snapshotUploader) upload(ctx context.Context, logger log.Logger) {
 							state.Unlock()
 						}()
 
+						checksum, err := fileChecksum(filepath.Join(u.cfg.dirs.Snap, state.file))
+
+						if err != nil {
+							logger.Debug("[snapshot uploader] checksum failed", "file", state.file, "err", err)
+							f.Add(1)
+							return nil
+						}
+
 						if err := u.uploadSession.Upload(gctx, state.uploads...); err != nil {
 							f.Add(1)
 							return nil
@@ -1488,6 +1597,7 @@ func (u *snapshotUploader) upload(ctx context.Context, logger log.Logger) {
 						state.Lock()
 						state.remote = true
 						state.hasRemoteTorrent = true
+						state.checksum = checksum
 						state.Unlock()
 						return nil
 					})
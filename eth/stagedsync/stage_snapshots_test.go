@@ -0,0 +1,52 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploaderChecksums(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "v1-000000-000500-headers.seg")
+	require.NoError(t, os.WriteFile(path, []byte("headers"), 0644))
+
+	checksum, err := fileChecksum(path)
+	require.NoError(t, err)
+	// printf headers | sha256sum
+	require.Equal(t, "378f37b1fb33fa4dafba6519a48e06447ecaa58af290729cc3d357bcadd9926c", checksum)
+
+	checksums := map[string]string{
+		"v1-000500-001000-headers.seg": strings.Repeat("b", 64),
+		"v1-000000-000500-headers.seg": checksum,
+	}
+	formatted := formatChecksums(checksums)
+	require.Equal(t, checksum+"  v1-000000-000500-headers.seg\n"+strings.Repeat("b", 64)+"  v1-000500-001000-headers.seg\n", string(formatted))
+
+	parsed, err := parseChecksums(bytes.NewReader(formatted))
+	require.NoError(t, err)
+	require.Equal(t, checksums, parsed)
+
+	_, err = parseChecksums(strings.NewReader("abc v1-000000-000500-headers.seg\n"))
+	require.ErrorContains(t, err, "invalid checksums.txt line")
+}
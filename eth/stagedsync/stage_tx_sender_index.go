@@ -0,0 +1,176 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/etl"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// TxSenderIndexCfg - the stage writes kv.TxSenderIdx: txNums of the transactions of every sender. Unlike TxLookup, the
+// snapshots have no such index, so the frozen blocks are indexed as well: enabling the stage on a synced node indexes
+// the whole chain during the next sync cycle.
+type TxSenderIndexCfg struct {
+	db          kv.RwDB
+	enabled     bool
+	tmpdir      string
+	blockReader services.FullBlockReader
+}
+
+func StageTxSenderIndexCfg(db kv.RwDB, enabled bool, tmpdir string, blockReader services.FullBlockReader) TxSenderIndexCfg {
+	return TxSenderIndexCfg{
+		db:          db,
+		enabled:     enabled,
+		tmpdir:      tmpdir,
+		blockReader: blockReader,
+	}
+}
+
+func SpawnTxSenderIndex(s *StageState, tx kv.RwTx, toBlock uint64, cfg TxSenderIndexCfg, ctx context.Context, logger log.Logger) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+	endBlock, err := s.ExecutionAt(tx)
+	if err != nil {
+		return err
+	}
+	if toBlock > 0 {
+		endBlock = min(endBlock, toBlock)
+	}
+	if s.BlockNumber >= endBlock {
+		return nil
+	}
+
+	// etl.Transform uses ExtractEndKey as exclusive bound, therefore endBlock + 1
+	if err = txSenderIndexTransform(s.LogPrefix(), tx, s.BlockNumber+1, endBlock+1, ctx, cfg, logger); err != nil {
+		return fmt.Errorf("txSenderIndexTransform: %w", err)
+	}
+	if err = s.Update(tx, endBlock); err != nil {
+		return err
+	}
+
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txSenderIndexTransform - [blockFrom, blockTo)
+func txSenderIndexTransform(logPrefix string, tx kv.RwTx, blockFrom, blockTo uint64, ctx context.Context, cfg TxSenderIndexCfg, logger log.Logger) error {
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, cfg.blockReader))
+	return etl.Transform(logPrefix, tx, kv.HeaderCanonical, kv.TxSenderIdx, cfg.tmpdir, func(k, v []byte, next etl.ExtractNextFunc) error {
+		blockNum, blockHash := binary.BigEndian.Uint64(k), libcommon.CastToHash(v)
+		return forEachTxSender(ctx, tx, cfg, txNumsReader, blockNum, blockHash, func(sender libcommon.Address, txNum []byte) error {
+			return next(k, sender[:], txNum)
+		})
+	}, etl.IdentityLoadFunc, etl.TransformArgs{
+		Quit:            ctx.Done(),
+		ExtractStartKey: hexutility.EncodeTs(blockFrom),
+		ExtractEndKey:   hexutility.EncodeTs(blockTo),
+		LogDetailsExtract: func(k, v []byte) (additionalLogArguments []interface{}) {
+			return []interface{}{"block", binary.BigEndian.Uint64(k)}
+		},
+	}, logger)
+}
+
+func forEachTxSender(ctx context.Context, tx kv.Tx, cfg TxSenderIndexCfg, txNumsReader rawdbv3.TxNumsReader, blockNum uint64, blockHash libcommon.Hash, f func(sender libcommon.Address, txNum []byte) error) error {
+	block, senders, err := cfg.blockReader.BlockWithSenders(ctx, tx, blockHash, blockNum)
+	if err != nil {
+		return err
+	}
+	if block == nil || len(block.Transactions()) == 0 {
+		return nil
+	}
+	if len(senders) != len(block.Transactions()) {
+		return fmt.Errorf("block %d: %d senders for %d txs", blockNum, len(senders), len(block.Transactions()))
+	}
+	firstTxNumInBlock, err := txNumsReader.Min(tx, blockNum)
+	if err != nil {
+		return err
+	}
+	var txNum [8]byte
+	for i, sender := range senders {
+		// +1 - the system transaction opening the block
+		binary.BigEndian.PutUint64(txNum[:], firstTxNumInBlock+1+uint64(i))
+		if err := f(sender, txNum[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func UnwindTxSenderIndex(u *UnwindState, s *StageState, tx kv.RwTx, cfg TxSenderIndexCfg, ctx context.Context, logger log.Logger) (err error) {
+	if s.BlockNumber <= u.UnwindPoint {
+		return nil
+	}
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+
+	c, err := tx.RwCursorDupSort(kv.TxSenderIdx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, cfg.blockReader))
+	for blockNum := u.UnwindPoint + 1; blockNum <= s.BlockNumber; blockNum++ {
+		blockHash, ok, err := cfg.blockReader.CanonicalHash(ctx, tx, blockNum)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := forEachTxSender(ctx, tx, cfg, txNumsReader, blockNum, blockHash, func(sender libcommon.Address, txNum []byte) error {
+			return c.DeleteExact(sender[:], txNum)
+		}); err != nil {
+			return fmt.Errorf("unwind TxSenderIndex: block %d: %w", blockNum, err)
+		}
+	}
+
+	if err := u.Done(tx); err != nil {
+		return err
+	}
+	if !useExternalTx {
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
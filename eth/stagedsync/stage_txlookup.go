@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"math/big"
 	"time"
 
@@ -38,6 +39,7 @@ import (
 	"github.com/erigontech/erigon/polygon/bor/borcfg"
 	bortypes "github.com/erigontech/erigon/polygon/bor/types"
 	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 )
 
 type TxLookupCfg struct {
@@ -137,38 +139,12 @@ func SpawnTxLookup(s *StageState, tx kv.RwTx, toBlock uint64, cfg TxLookupCfg, c
 
 // txnLookupTransform - [startKey, endKey)
 func txnLookupTransform(logPrefix string, tx kv.RwTx, blockFrom, blockTo uint64, ctx context.Context, cfg TxLookupCfg, logger log.Logger) (err error) {
-	data := make([]byte, 16)
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, cfg.blockReader))
 	return etl.Transform(logPrefix, tx, kv.HeaderCanonical, kv.TxLookup, cfg.tmpdir, func(k, v []byte, next etl.ExtractNextFunc) error {
 		blocknum, blockHash := binary.BigEndian.Uint64(k), libcommon.CastToHash(v)
-		body, err := cfg.blockReader.BodyWithTransactions(ctx, tx, blockHash, blocknum)
-		if err != nil {
-			return err
-		}
-		if body == nil { // tolerate such an error, because likely it's corner-case - and not critical one
-			log.Warn(fmt.Sprintf("[%s] transform: empty block body %d, hash %x", logPrefix, blocknum, v))
-			return nil
-		}
-
-		firstTxNumInBlock, err := rawdbv3.TxNums.Min(tx, blocknum)
-		if err != nil {
-			return err
-		}
-
-		if firstTxNumInBlock == 0 {
-			log.Warn(fmt.Sprintf("[%s] transform: empty txnum %d, hash %x", logPrefix, firstTxNumInBlock, v))
-			return nil
-		}
-
-		binary.BigEndian.PutUint64(data[:8], blocknum)
-
-		for i, txn := range body.Transactions {
-			binary.BigEndian.PutUint64(data[8:], firstTxNumInBlock+uint64(i)+1)
-			if err := next(k, txn.Hash().Bytes(), data); err != nil {
-				return err
-			}
-		}
-
-		return nil
+		return forEachTxLookupEntry(ctx, tx, cfg, txNumsReader, logPrefix, blocknum, blockHash, func(txnHash, txNum []byte) error {
+			return next(k, txnHash, txNum)
+		})
 	}, etl.IdentityLoadFunc, etl.TransformArgs{
 		Quit:            ctx.Done(),
 		ExtractStartKey: hexutility.EncodeTs(blockFrom),
@@ -179,6 +155,40 @@ func txnLookupTransform(logPrefix string, tx kv.RwTx, blockFrom, blockTo uint64,
 	}, logger)
 }
 
+// forEachTxLookupEntry - kv.TxLookup entries of the block: the txNum of every transaction
+func forEachTxLookupEntry(ctx context.Context, tx kv.Tx, cfg TxLookupCfg, txNumsReader rawdbv3.TxNumsReader, logPrefix string, blockNum uint64, blockHash libcommon.Hash, f func(txnHash, txNum []byte) error) error {
+	body, err := cfg.blockReader.BodyWithTransactions(ctx, tx, blockHash, blockNum)
+	if err != nil {
+		return err
+	}
+	if body == nil { // tolerate such an error, because likely it's corner-case - and not critical one
+		log.Warn(fmt.Sprintf("[%s] transform: empty block body %d, hash %x", logPrefix, blockNum, blockHash))
+		return nil
+	}
+	if len(body.Transactions) == 0 {
+		return nil
+	}
+
+	firstTxNumInBlock, err := txNumsReader.Min(tx, blockNum)
+	if err != nil {
+		return err
+	}
+	if firstTxNumInBlock == 0 {
+		log.Warn(fmt.Sprintf("[%s] transform: empty txnum %d, hash %x", logPrefix, firstTxNumInBlock, blockHash))
+		return nil
+	}
+
+	var txNum [8]byte
+	for i, txn := range body.Transactions {
+		// +1 - the system transaction opening the block
+		binary.BigEndian.PutUint64(txNum[:], firstTxNumInBlock+1+uint64(i))
+		if err := f(txn.Hash().Bytes(), txNum[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // txnLookupTransform - [startKey, endKey)
 func borTxnLookupTransform(logPrefix string, tx kv.RwTx, blockFrom, blockTo uint64, quitCh <-chan struct{}, cfg TxLookupCfg, logger log.Logger) error {
 	bigNum := new(big.Int)
@@ -226,8 +236,14 @@ func UnwindTxLookup(u *UnwindState, s *StageState, tx kv.RwTx, cfg TxLookupCfg,
 	smallestInDB := cfg.blockReader.FrozenBlocks()
 	blockFrom, blockTo = max(blockFrom, smallestInDB), max(blockTo, smallestInDB)
 
+	// everything after the unwind point, as the domains are unwound
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, cfg.blockReader))
+	txNumFrom, err := txNumsReader.Min(tx, blockFrom)
+	if err != nil {
+		return err
+	}
 	// etl.Transform uses ExtractEndKey as exclusive bound, therefore blockTo + 1
-	if err := deleteTxLookupRange(tx, s.LogPrefix(), blockFrom, blockTo+1, ctx, cfg, logger); err != nil {
+	if err := deleteTxLookupRange(tx, s.LogPrefix(), blockFrom, blockTo+1, txNumFrom, math.MaxUint64, ctx, cfg, logger); err != nil {
 		return fmt.Errorf("unwind TxLookUp: %w", err)
 	}
 	if cfg.borConfig != nil {
@@ -291,6 +307,13 @@ func PruneTxLookup(s *PruneState, tx kv.RwTx, cfg TxLookupCfg, ctx context.Conte
 	}
 
 	if blockFrom < blockTo {
+		// everything before the prune point, as the domains are pruned
+		txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, cfg.blockReader))
+		txNumTo, err := txNumsReader.Min(tx, blockTo)
+		if err != nil {
+			return err
+		}
+
 		logEvery := time.NewTicker(logInterval)
 		defer logEvery.Stop()
 
@@ -303,7 +326,7 @@ func PruneTxLookup(s *PruneState, tx kv.RwTx, cfg TxLookupCfg, ctx context.Conte
 			default:
 			}
 
-			err = deleteTxLookupRange(tx, logPrefix, pruneBlockNum, pruneBlockNum+1, ctx, cfg, logger)
+			err = deleteTxLookupRange(tx, logPrefix, pruneBlockNum, pruneBlockNum+1, 0, txNumTo, ctx, cfg, logger)
 			if err != nil {
 				return fmt.Errorf("prune TxLookUp: %w", err)
 			}
@@ -331,9 +354,10 @@ func PruneTxLookup(s *PruneState, tx kv.RwTx, cfg TxLookupCfg, ctx context.Conte
 	return nil
 }
 
-// deleteTxLookupRange - [blockFrom, blockTo)
-func deleteTxLookupRange(tx kv.RwTx, logPrefix string, blockFrom, blockTo uint64, ctx context.Context, cfg TxLookupCfg, logger log.Logger) (err error) {
-	err = etl.Transform(logPrefix, tx, kv.HeaderCanonical, kv.TxLookup, cfg.tmpdir, func(k, v []byte, next etl.ExtractNextFunc) error {
+// deleteTxLookupRange - [blockFrom, blockTo): deletes the entries of the blocks' transactions pointing to [txNumFrom, txNumTo).
+// An entry pointing elsewhere was written for the same transaction included by another block - it's not deleted.
+func deleteTxLookupRange(tx kv.RwTx, logPrefix string, blockFrom, blockTo, txNumFrom, txNumTo uint64, ctx context.Context, cfg TxLookupCfg, logger log.Logger) (err error) {
+	return etl.Transform(logPrefix, tx, kv.HeaderCanonical, kv.TxLookup, cfg.tmpdir, func(k, v []byte, next etl.ExtractNextFunc) error {
 		blocknum, blockHash := binary.BigEndian.Uint64(k), libcommon.CastToHash(v)
 		body, err := cfg.blockReader.BodyWithTransactions(ctx, tx, blockHash, blocknum)
 		if err != nil {
@@ -351,7 +375,18 @@ func deleteTxLookupRange(tx kv.RwTx, logPrefix string, blockFrom, blockTo uint64
 		}
 
 		return nil
-	}, etl.IdentityLoadFunc, etl.TransformArgs{
+	}, func(k, _ []byte, table etl.CurrentTableReader, next etl.LoadNextFunc) error {
+		v, err := table.Get(k)
+		if err != nil {
+			return err
+		}
+		if len(v) == 8 {
+			if txNum := binary.BigEndian.Uint64(v); txNum < txNumFrom || txNum >= txNumTo {
+				return nil
+			}
+		}
+		return next(k, k, nil)
+	}, etl.TransformArgs{
 		Quit:            ctx.Done(),
 		ExtractStartKey: hexutility.EncodeTs(blockFrom),
 		ExtractEndKey:   hexutility.EncodeTs(blockTo),
@@ -359,10 +394,6 @@ func deleteTxLookupRange(tx kv.RwTx, logPrefix string, blockFrom, blockTo uint64
 			return []interface{}{"block", binary.BigEndian.Uint64(k)}
 		},
 	}, logger)
-	if err != nil {
-		return err
-	}
-	return nil
 }
 
 // deleteTxLookupRange - [blockFrom, blockTo)
@@ -86,7 +86,7 @@ func InitHarness(ctx context.Context, t *testing.T, cfg HarnessCfg) Harness {
 		false,
 		nil,
 	)
-	stateSyncStages := stagedsync.DefaultStages(ctx, stagedsync.SnapshotsCfg{}, stagedsync.HeadersCfg{}, bhCfg, stagedsync.BlockHashesCfg{}, stagedsync.BodiesCfg{}, stagedsync.SendersCfg{}, stagedsync.ExecuteBlockCfg{}, stagedsync.TxLookupCfg{}, stagedsync.FinishCfg{}, true)
+	stateSyncStages := stagedsync.DefaultStages(ctx, stagedsync.SnapshotsCfg{}, stagedsync.HeadersCfg{}, bhCfg, stagedsync.BlockHashesCfg{}, stagedsync.BodiesCfg{}, stagedsync.SendersCfg{}, stagedsync.ExecuteBlockCfg{}, stagedsync.TxLookupCfg{}, stagedsync.TxSenderIndexCfg{}, stagedsync.FinishCfg{}, true)
 	stateSync := stagedsync.New(
 		ethconfig.Defaults.Sync,
 		stateSyncStages,
@@ -394,6 +394,13 @@ func (h *Harness) ReadHeaderByNumber(ctx context.Context, number uint64) (header
 	return
 }
 
+// VerifySprintHeaders verifies a batch of the generated headers with a fresh bor engine.
+func (h *Harness) VerifySprintHeaders(t *testing.T, headers []*types.Header) error {
+	engine := h.consensusEngine(t, HarnessCfg{ChainConfig: h.chainConfig}).(*bor.Bor)
+	chainHR := dummySpanReader{h.mockChainHeaderReader(gomock.NewController(t))}
+	return engine.VerifySprintHeaders(chainHR, headers)
+}
+
 func createGenesisInitData(t *testing.T, chainConfig *chain.Config) *genesisInitData {
 	t.Helper()
 	accountPrivateKey, err := crypto.GenerateKey()
@@ -574,6 +581,12 @@ func (h *Harness) mockChainHeaderReader(ctrl *gomock.Controller) consensus.Chain
 		Return(uint64(0)).
 		AnyTimes()
 
+	mockChainHR.
+		EXPECT().
+		Config().
+		Return(h.chainConfig).
+		AnyTimes()
+
 	return mockChainHR
 }
 
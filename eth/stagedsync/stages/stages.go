@@ -44,8 +44,9 @@ var (
 	CustomTrace     SyncStage = "CustomTrace"     // Executing each block w/o building a trie
 	Translation     SyncStage = "Translation"     // Translation each marked for translation contract (from EVM to TEVM)
 	VerkleTrie      SyncStage = "VerkleTrie"
-	TxLookup        SyncStage = "TxLookup" // Generating transactions lookup index
-	Finish          SyncStage = "Finish"   // Nominal stage after all other stages
+	TxLookup        SyncStage = "TxLookup"      // Generating transactions lookup index
+	TxSenderIndex   SyncStage = "TxSenderIndex" // Optional index of the transactions by sender
+	Finish          SyncStage = "Finish"        // Nominal stage after all other stages
 
 	MiningCreateBlock SyncStage = "MiningCreateBlock"
 	MiningBorHeimdall SyncStage = "MiningBorHeimdall"
@@ -71,6 +72,7 @@ var AllStages = []SyncStage{
 	CustomTrace,
 	Translation,
 	TxLookup,
+	TxSenderIndex,
 	Finish,
 }
 
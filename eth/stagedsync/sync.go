@@ -25,8 +25,10 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/state"
 
+	"github.com/erigontech/erigon-lib/app"
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/dbg"
+	"github.com/erigontech/erigon-lib/diagnostics"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/wrap"
 
@@ -525,13 +527,37 @@ func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, initialC
 		return err
 	}
 
+	isDiagEnabled := diagnostics.TypeOf(diagnostics.SyncStageProgressUpdate{}).Enabled()
+	if isDiagEnabled {
+		// the target only feeds the ETA estimation, it must not fail the sync
+		target, err := s.stageTarget(stage, txc.Tx, db)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("[%s] Failed to read the stage target for the ETA", s.LogPrefix()), "err", err)
+		}
+		diagnostics.Send(diagnostics.SyncStageProgressUpdate{Stage: string(stage.ID), Time: start, Block: stageState.BlockNumber, Target: target, Start: true})
+	}
+
+	app.PublishEvent[app.StageEvent](app.StageStarted{Stage: string(stage.ID)})
 	if err = stage.Forward(badBlockUnwind, stageState, s, txc, s.logger); err != nil {
 		wrappedError := fmt.Errorf("[%s] %w", s.LogPrefix(), err)
 		s.logger.Debug("Error while executing stage", "err", wrappedError)
+		app.PublishEvent[app.StageEvent](app.StageFinished{Stage: string(stage.ID), Took: time.Since(start), Err: wrappedError})
 		return wrappedError
 	}
 
 	took := time.Since(start)
+	if app.HasSubscribers[app.StageEvent](app.DefaultEventBus()) || isDiagEnabled {
+		// the stage is done: failing to read its progress for the subscribers must not fail the sync
+		if progress, err := s.StageState(stage.ID, txc.Tx, db, initialCycle, firstCycle); err != nil {
+			s.logger.Warn(fmt.Sprintf("[%s] Failed to read the stage progress for the subscribers", s.LogPrefix()), "err", err)
+			app.PublishEvent[app.StageEvent](app.StageFinished{Stage: string(stage.ID), Took: took})
+		} else {
+			app.PublishEvent[app.StageEvent](app.StageFinished{Stage: string(stage.ID), BlockNum: progress.BlockNumber, Took: took})
+			if isDiagEnabled {
+				diagnostics.Send(diagnostics.SyncStageProgressUpdate{Stage: string(stage.ID), Time: time.Now(), Block: progress.BlockNumber})
+			}
+		}
+	}
 	logPrefix := s.LogPrefix()
 	if took > 60*time.Second {
 		s.logger.Info(fmt.Sprintf("[%s] DONE", logPrefix), "in", took, "block", stageState.BlockNumber)
@@ -542,6 +568,24 @@ func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, initialC
 	return nil
 }
 
+// stageTarget - the block the stage runs to: the progress of the previous stage. The stages downloading the chain
+// run to the tip of the network, which is not known here.
+func (s *Sync) stageTarget(stage *Stage, tx kv.Tx, db kv.RoDB) (uint64, error) {
+	if stage.ID == stages.Snapshots || stage.ID == stages.Headers {
+		return 0, nil
+	}
+	for i := int(s.currentStage) - 1; i >= 0; i-- {
+		if prev := s.stages[i]; !prev.Disabled && prev.Forward != nil {
+			prevState, err := s.StageState(prev.ID, tx, db, false, false)
+			if err != nil {
+				return 0, err
+			}
+			return prevState.BlockNumber, nil
+		}
+	}
+	return 0, nil
+}
+
 func (s *Sync) unwindStage(initialCycle bool, stage *Stage, db kv.RwDB, txc wrap.TxContainer) error {
 	start := time.Now()
 	stageState, err := s.StageState(stage.ID, txc.Tx, db, initialCycle, false)
@@ -560,12 +604,16 @@ func (s *Sync) unwindStage(initialCycle bool, stage *Stage, db kv.RwDB, txc wrap
 		return err
 	}
 
+	app.PublishEvent[app.StageEvent](app.StageStarted{Stage: string(stage.ID), Unwind: true})
 	err = stage.Unwind(unwind, stageState, txc, s.logger)
 	if err != nil {
-		return fmt.Errorf("[%s] %w", s.LogPrefix(), err)
+		err = fmt.Errorf("[%s] %w", s.LogPrefix(), err)
+		app.PublishEvent[app.StageEvent](app.StageUnwound{Stage: string(stage.ID), From: stageState.BlockNumber, To: unwind.UnwindPoint, Took: time.Since(start), Err: err})
+		return err
 	}
 
 	took := time.Since(start)
+	app.PublishEvent[app.StageEvent](app.StageUnwound{Stage: string(stage.ID), From: stageState.BlockNumber, To: unwind.UnwindPoint, Took: took})
 	if took > 60*time.Second {
 		logPrefix := s.LogPrefix()
 		s.logger.Info(fmt.Sprintf("[%s] Unwind done", logPrefix), "in", took)
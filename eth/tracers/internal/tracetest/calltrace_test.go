@@ -190,9 +190,7 @@ func testCallTracer(tracerName string, dirPath string, t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to marshal test: %v", err)
 			}
-			if string(want) != string(res) {
-				t.Fatalf("trace mismatch\n have: %v\n want: %v\n", string(res), string(want))
-			}
+			compareTrace(t, filepath.Join("testdata", dirPath, file.Name()), want, res)
 			// Sanity check: compare top call's gas used against vm result
 			type simpleResult struct {
 				GasUsed hexutil.Uint64
@@ -364,3 +362,67 @@ func TestZeroValueToNotExitCall(t *testing.T) {
 		t.Fatalf("trace mismatch\n have: %v\n want: %v\n", string(res), wantStr)
 	}
 }
+
+// Since Cancun, the SELFDESTRUCT of an account created before the transaction does not delete it: with itself as the
+// beneficiary, nothing changes at all
+func TestSelfdestructCancelled(t *testing.T) {
+	var to = libcommon.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	require.NoError(t, err)
+	signer := types.LatestSigner(params.MainnetChainConfig)
+	tx, err := types.SignNewTx(privkey, *signer, &types.LegacyTx{
+		GasPrice: uint256.NewInt(0),
+		CommonTx: types.CommonTx{
+			Gas: 50000,
+			To:  &to,
+		},
+	})
+	require.NoError(t, err)
+	origin, _ := signer.Sender(tx)
+	txContext := evmtypes.TxContext{
+		Origin:   origin,
+		GasPrice: uint256.NewInt(0),
+	}
+	context := evmtypes.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    consensus.Transfer,
+		Coinbase:    libcommon.Address{},
+		BlockNumber: 19426587,
+		Time:        1710338135,
+		Difficulty:  big.NewInt(0),
+		GasLimit:    uint64(30000000),
+		BaseFee:     uint256.NewInt(0),
+		BlobBaseFee: uint256.NewInt(1),
+	}
+	var alloc = types.GenesisAlloc{
+		to: types.GenesisAccount{
+			Nonce:   1,
+			Code:    []byte{byte(vm.ADDRESS), byte(vm.SELFDESTRUCT)},
+			Balance: big.NewInt(100),
+		},
+		origin: types.GenesisAccount{
+			Nonce:   0,
+			Balance: big.NewInt(500000000000000),
+		},
+	}
+	rules := params.MainnetChainConfig.Rules(context.BlockNumber, context.Time)
+	require.True(t, rules.IsCancun)
+	m := mock.Mock(t)
+	dbTx, err := m.DB.BeginRw(m.Ctx)
+	require.NoError(t, err)
+	defer dbTx.Rollback()
+
+	statedb, _ := tests.MakePreState(rules, dbTx, alloc, context.BlockNumber)
+	tracer, err := tracers.New("muxTracer", nil, json.RawMessage(`{"callTracer":{},"prestateTracer":{"diffMode":true,"disableCode":true}}`))
+	require.NoError(t, err)
+	evm := vm.NewEVM(context, txContext, statedb, params.MainnetChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(*signer, nil, rules)
+	require.NoError(t, err)
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.GetGas()).AddBlobGas(tx.GetBlobGas()))
+	_, err = st.TransitionDb(true /* refunds */, false /* gasBailout */)
+	require.NoError(t, err)
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	wantStr := `{"callTracer":{"from":"0x682a80a6f560eec50d54e63cbeda1c324c5f8d1b","gas":"0xc350","gasUsed":"0x6592","to":"0x00000000000000000000000000000000deadbeef","input":"0x","calls":[{"from":"0x00000000000000000000000000000000deadbeef","gas":"0x0","gasUsed":"0x0","to":"0x00000000000000000000000000000000deadbeef","input":"0x","value":"0x0","type":"SELFDESTRUCT"}],"value":"0x0","type":"CALL"},"prestateTracer":{"post":{"0x682a80a6f560eec50d54e63cbeda1c324c5f8d1b":{"nonce":1}},"pre":{"0x682a80a6f560eec50d54e63cbeda1c324c5f8d1b":{"balance":"0x1c6bf52634000"}}}}`
+	require.JSONEq(t, wantStr, string(res))
+}
@@ -0,0 +1,364 @@
+// Copyright 2024 The go-ethereum Authors
+// (original work)
+// Copyright 2024 The Erigon Authors
+// (modifications)
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+package tracetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Golden files: the tests read the transaction and the expected result of the tracer from the json files in testdata.
+// After a change of a tracer, the expected results are updated with
+//
+//	go test ./eth/tracers/internal/tracetest -regen
+//
+// which writes the actual results to the files of the failing cases, leaving everything else in them as is.
+var regen = flag.Bool("regen", false, "write the actual results of the tracers to the golden files instead of failing")
+
+// maxDiffs - differences printed for a mismatch, the rest is usually a consequence of the first ones
+const maxDiffs = 20
+
+// compareTrace fails the test with the paths of the differences between the expected and actual results of the
+// golden file, or writes the actual one to the file with -regen
+func compareTrace(t *testing.T, path string, want, have []byte) {
+	t.Helper()
+	if bytes.Equal(want, have) {
+		return
+	}
+	if *regen {
+		if err := regenGolden(path, have); err != nil {
+			t.Fatalf("regenerating %s: %v", path, err)
+		}
+		t.Logf("regenerated %s", path)
+		return
+	}
+
+	var wantV, haveV any
+	if err := json.Unmarshal(want, &wantV); err != nil {
+		t.Fatalf("parsing expected result: %v", err)
+	}
+	if err := json.Unmarshal(have, &haveV); err != nil {
+		t.Fatalf("parsing result: %v, %s", err, have)
+	}
+	diffs := jsonDiff("", wantV, haveV)
+	if len(diffs) > maxDiffs {
+		diffs = append(diffs[:maxDiffs], fmt.Sprintf("... and %d more", len(diffs)-maxDiffs))
+	}
+	t.Fatalf("trace mismatch with %s (run with -regen to update it):\n%s", path, strings.Join(diffs, "\n"))
+}
+
+// jsonDiff returns the differences between two decoded json values, one per line, as "path: want X, have Y". The
+// path of a field of a nested call is like ".calls[0].calls[2].gasUsed".
+func jsonDiff(path string, want, have any) []string {
+	switch w := want.(type) {
+	case map[string]any:
+		h, ok := have.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(h))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range h {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var diffs []string
+		for _, k := range keys {
+			wv, wok := w[k]
+			hv, hok := h[k]
+			switch {
+			case !hok:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: want %s, missing", path, k, jsonString(wv)))
+			case !wok:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: unexpected %s", path, k, jsonString(hv)))
+			default:
+				diffs = append(diffs, jsonDiff(path+"."+k, wv, hv)...)
+			}
+		}
+		return diffs
+	case []any:
+		h, ok := have.([]any)
+		if !ok {
+			break
+		}
+		var diffs []string
+		for i := 0; i < max(len(w), len(h)); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(h):
+				diffs = append(diffs, fmt.Sprintf("%s: want %s, missing", elemPath, jsonString(w[i])))
+			case i >= len(w):
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", elemPath, jsonString(h[i])))
+			default:
+				diffs = append(diffs, jsonDiff(elemPath, w[i], h[i])...)
+			}
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(want, have) {
+		return nil
+	}
+	if path == "" {
+		path = "."
+	}
+	return []string{fmt.Sprintf("%s: want %s, have %s", path, jsonString(want), jsonString(have))}
+}
+
+func jsonString(v any) string {
+	const maxLen = 100
+	b, _ := json.Marshal(v)
+	if len(b) > maxLen {
+		return string(b[:maxLen]) + "..."
+	}
+	return string(b)
+}
+
+// regenGolden replaces the result in the golden file. The other fields are kept as they are, and the fields of the
+// objects of the new result are in the order of the old one, so that only the values which changed show in the diff.
+func regenGolden(path string, result []byte) error {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	updated, err := replaceResult(blob, result)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, updated, 0644)
+}
+
+func replaceResult(blob []byte, result []byte) ([]byte, error) {
+	resultNode, err := parseJsonNode(json.NewDecoder(bytes.NewReader(result)))
+	if err != nil {
+		return nil, err
+	}
+	file, err := parseJsonNode(json.NewDecoder(bytes.NewReader(blob)))
+	if err != nil {
+		return nil, err
+	}
+	if file.kind != '{' {
+		return nil, errors.New("not a json object")
+	}
+
+	// the fields other than the result are copied verbatim
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteString("{")
+	replaced := false
+	for i := 0; dec.More(); i++ {
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		key := file.fields[i].key
+		if i > 0 {
+			out.WriteString(",")
+		}
+		fmt.Fprintf(&out, "\n  %q: ", key)
+		if key == "result" {
+			resultNode.write(&out, file.fields[i].value, "  ")
+			replaced = true
+			continue
+		}
+		out.Write(value)
+	}
+	if !replaced {
+		if len(file.fields) > 0 {
+			out.WriteString(",")
+		}
+		fmt.Fprintf(&out, "\n  %q: ", "result")
+		resultNode.write(&out, nil, "  ")
+	}
+	out.WriteString("\n}")
+	if bytes.HasSuffix(blob, []byte("\n")) {
+		out.WriteString("\n")
+	}
+	return out.Bytes(), nil
+}
+
+// jsonNode is a decoded json value which keeps the order of the fields of the objects
+type jsonNode struct {
+	kind     json.Delim // '{', '[' or 0 for the other values
+	fields   []jsonField
+	elements []*jsonNode
+	scalar   []byte
+}
+
+type jsonField struct {
+	key   string
+	value *jsonNode
+}
+
+func parseJsonNode(dec *json.Decoder) (*jsonNode, error) {
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		n := &jsonNode{kind: '{'}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseJsonNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.fields = append(n.fields, jsonField{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return n, err
+	case json.Delim('['):
+		n := &jsonNode{kind: '['}
+		for dec.More() {
+			element, err := parseJsonNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.elements = append(n.elements, element)
+		}
+		_, err := dec.Token()
+		return n, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(tok); err != nil {
+		return nil, err
+	}
+	return &jsonNode{scalar: bytes.TrimSuffix(buf.Bytes(), []byte("\n"))}, nil
+}
+
+// write writes the value indented, with the fields of the objects in the order of the fields of like (if any) first
+func (n *jsonNode) write(out *bytes.Buffer, like *jsonNode, indent string) {
+	switch n.kind {
+	case '{':
+		if len(n.fields) == 0 {
+			out.WriteString("{}")
+			return
+		}
+		fields := n.fields
+		if like != nil && like.kind == '{' {
+			fields = make([]jsonField, 0, len(n.fields))
+			for _, lf := range like.fields {
+				if f, ok := n.field(lf.key); ok {
+					fields = append(fields, jsonField{key: f.key, value: f.value})
+				}
+			}
+			for _, f := range n.fields {
+				if _, ok := like.field(f.key); !ok {
+					fields = append(fields, f)
+				}
+			}
+		}
+		out.WriteString("{")
+		for i, f := range fields {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			fmt.Fprintf(out, "\n%s  %q: ", indent, f.key)
+			var likeValue *jsonNode
+			if like != nil {
+				if lf, ok := like.field(f.key); ok {
+					likeValue = lf.value
+				}
+			}
+			f.value.write(out, likeValue, indent+"  ")
+		}
+		fmt.Fprintf(out, "\n%s}", indent)
+	case '[':
+		if len(n.elements) == 0 {
+			out.WriteString("[]")
+			return
+		}
+		out.WriteString("[")
+		for i, e := range n.elements {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			fmt.Fprintf(out, "\n%s  ", indent)
+			var likeElement *jsonNode
+			if like != nil && like.kind == '[' && i < len(like.elements) {
+				likeElement = like.elements[i]
+			}
+			e.write(out, likeElement, indent+"  ")
+		}
+		fmt.Fprintf(out, "\n%s]", indent)
+	default:
+		out.Write(n.scalar)
+	}
+}
+
+func (n *jsonNode) field(key string) (jsonField, bool) {
+	for _, f := range n.fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+func TestJsonDiff(t *testing.T) {
+	var want, have any
+	if err := json.Unmarshal([]byte(`{"gasUsed":"0x1","calls":[{"to":"0xa","calls":[{"gasUsed":"0x2"}]},{"to":"0xb"}]}`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"gasUsed":"0x1","calls":[{"to":"0xa","calls":[{"gasUsed":"0x3","error":"out of gas"}]}]}`), &have); err != nil {
+		t.Fatal(err)
+	}
+	diffs := jsonDiff("", want, have)
+	expected := []string{
+		`.calls[0].calls[0].error: unexpected "out of gas"`,
+		`.calls[0].calls[0].gasUsed: want "0x2", have "0x3"`,
+		`.calls[1]: want {"to":"0xb"}, missing`,
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Fatalf("diffs:\n%s\nexpected:\n%s", strings.Join(diffs, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestReplaceResult(t *testing.T) {
+	blob := []byte("{\n  \"genesis\": {\n    \"number\": \"1\"\n  },\n  \"input\": \"0x\",\n  \"result\": {\n    \"gas\": \"0x1\"\n  }\n}\n")
+	updated, err := replaceResult(blob, []byte(`{"gas":"0x2","calls":[{"to":"0xa"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n  \"genesis\": {\n    \"number\": \"1\"\n  },\n  \"input\": \"0x\",\n  \"result\": {\n    \"gas\": \"0x2\",\n    \"calls\": [\n      {\n        \"to\": \"0xa\"\n      }\n    ]\n  }\n}\n"
+	if string(updated) != expected {
+		t.Fatalf("updated:\n%s\nexpected:\n%s", updated, expected)
+	}
+}
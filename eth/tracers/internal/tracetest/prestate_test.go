@@ -156,9 +156,7 @@ func testPrestateTracer(tracerName string, dirPath string, t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to marshal test: %v", err)
 			}
-			if string(want) != string(res) {
-				t.Fatalf("trace mismatch\n have: %v\n want: %v\n", string(res), string(want))
-			}
+			compareTrace(t, filepath.Join("testdata", dirPath, file.Name()), want, res)
 		})
 	}
 }
@@ -0,0 +1,95 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/holiman/uint256"
+)
+
+// stepCapture applies the per step capture limits of a LogConfig, and remembers what was captured at the previous step
+// so that unchanged memory, stack and return data can be skipped in the OnlyOnChange mode. Each of its methods returns
+// the data to report for the step, with the limits applied, and whether it should be reported at all. The returned
+// slices alias the EVM state and must be copied to be kept.
+type stepCapture struct {
+	memory, returnData             []byte
+	stack                          []uint256.Int
+	hasMemory, hasStack, hasReturn bool
+}
+
+// limitBytes returns the first limit bytes of data, zero meaning unlimited.
+func limitBytes(data []byte, limit int) []byte {
+	if limit > 0 && len(data) > limit {
+		return data[:limit]
+	}
+	return data
+}
+
+// limitStack returns the topmost limit items of the stack, zero meaning unlimited.
+func limitStack(data []uint256.Int, limit int) []uint256.Int {
+	if limit > 0 && len(data) > limit {
+		return data[len(data)-limit:]
+	}
+	return data
+}
+
+func (c *stepCapture) captureMemory(cfg *LogConfig, memory []byte) ([]byte, bool) {
+	if cfg.DisableMemory {
+		return nil, false
+	}
+	memory = limitBytes(memory, cfg.MemoryLimit)
+	if !cfg.OnlyOnChange {
+		return memory, true
+	}
+	if c.hasMemory && bytes.Equal(memory, c.memory) {
+		return nil, false
+	}
+	c.memory, c.hasMemory = append(c.memory[:0], memory...), true
+	return memory, true
+}
+
+func (c *stepCapture) captureStack(cfg *LogConfig, stack []uint256.Int) ([]uint256.Int, bool) {
+	if cfg.DisableStack {
+		return nil, false
+	}
+	stack = limitStack(stack, cfg.StackLimit)
+	if !cfg.OnlyOnChange {
+		return stack, true
+	}
+	if c.hasStack && slices.Equal(stack, c.stack) {
+		return nil, false
+	}
+	c.stack, c.hasStack = append(c.stack[:0], stack...), true
+	return stack, true
+}
+
+func (c *stepCapture) captureReturnData(cfg *LogConfig, returnData []byte) ([]byte, bool) {
+	if cfg.DisableReturnData {
+		return nil, false
+	}
+	returnData = limitBytes(returnData, cfg.ReturnDataLimit)
+	if !cfg.OnlyOnChange {
+		return returnData, true
+	}
+	if c.hasReturn && bytes.Equal(returnData, c.returnData) {
+		return nil, false
+	}
+	c.returnData, c.hasReturn = append(c.returnData[:0], returnData...), true
+	return returnData, true
+}
@@ -37,6 +37,7 @@ import (
 type JsonStreamLogger struct {
 	ctx          context.Context
 	cfg          LogConfig
+	capture      stepCapture
 	stream       *jsoniter.Stream
 	hexEncodeBuf [128]byte
 	firstCapture bool
@@ -145,11 +146,11 @@ func (l *JsonStreamLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint6
 		l.stream.WriteObjectField("error")
 		l.stream.WriteString(err.Error())
 	}
-	if !l.cfg.DisableStack {
+	if stackData, ok := l.capture.captureStack(&l.cfg, stack.Data); ok {
 		l.stream.WriteMore()
 		l.stream.WriteObjectField("stack")
 		l.stream.WriteArrayStart()
-		for i, stackValue := range stack.Data {
+		for i, stackValue := range stackData {
 			if i > 0 {
 				l.stream.WriteMore()
 			}
@@ -157,8 +158,7 @@ func (l *JsonStreamLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint6
 		}
 		l.stream.WriteArrayEnd()
 	}
-	if !l.cfg.DisableMemory {
-		memData := memory.Data()
+	if memData, ok := l.capture.captureMemory(&l.cfg, memory.Data()); ok {
 		l.stream.WriteMore()
 		l.stream.WriteObjectField("memory")
 		l.stream.WriteArrayStart()
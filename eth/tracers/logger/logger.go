@@ -56,6 +56,10 @@ type LogConfig struct {
 	DisableReturnData bool // disable return data capture
 	Debug             bool // print output during capture end
 	Limit             int  // maximum length of output, but zero means unlimited
+	MemoryLimit       int  // maximum number of memory bytes captured per step, zero means unlimited
+	StackLimit        int  // maximum number of (topmost) stack items captured per step, zero means unlimited
+	ReturnDataLimit   int  // maximum number of return data bytes captured per step, zero means unlimited
+	OnlyOnChange      bool // capture memory, stack and return data only at the steps where they changed
 	// Chain overrides, can be used to execute a trace using future fork rules
 	Overrides *chain.Config `json:"overrides,omitempty"`
 }
@@ -123,7 +127,8 @@ type StructLogRes struct {
 // a track record of modified storage which is used in reporting snapshots of the
 // contract their storage.
 type StructLogger struct {
-	cfg LogConfig
+	cfg     LogConfig
+	capture stepCapture
 
 	storage map[libcommon.Address]Storage
 	logs    []StructLog
@@ -171,15 +176,15 @@ func (l *StructLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, s
 
 	// Copy a snapshot of the current memory state to a new buffer
 	var mem []byte
-	if !l.cfg.DisableMemory {
-		mem = make([]byte, len(memory.Data()))
-		copy(mem, memory.Data())
+	if m, ok := l.capture.captureMemory(&l.cfg, memory.Data()); ok {
+		mem = make([]byte, len(m))
+		copy(mem, m)
 	}
 	// Copy a snapshot of the current stack state to a new buffer
 	var stck []*big.Int
-	if !l.cfg.DisableStack {
-		stck = make([]*big.Int, len(stack.Data))
-		for i, item := range stack.Data {
+	if st, ok := l.capture.captureStack(&l.cfg, stack.Data); ok {
+		stck = make([]*big.Int, len(st))
+		for i, item := range st {
 			stck[i] = new(big.Int).Set(item.ToBig())
 		}
 	}
@@ -211,9 +216,9 @@ func (l *StructLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, s
 		storage = l.storage[contract.Address()].Copy()
 	}
 	var rdata []byte
-	if !l.cfg.DisableReturnData {
-		rdata = make([]byte, len(rData))
-		copy(rdata, rData)
+	if r, ok := l.capture.captureReturnData(&l.cfg, rData); ok {
+		rdata = make([]byte, len(r))
+		copy(rdata, r)
 	}
 	// create a new snapshot of the EVM.
 	log := StructLog{pc, op, gas, cost, mem, memory.Len(), stck, rdata, storage, depth, l.env.IntraBlockState().GetRefund(), err}
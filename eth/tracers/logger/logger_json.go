@@ -35,13 +35,14 @@ import (
 type JSONLogger struct {
 	encoder *json.Encoder
 	cfg     *LogConfig
+	capture stepCapture
 	env     *vm.EVM
 }
 
 // NewJSONLogger creates a new EVM tracer that prints execution steps as JSON objects
 // into the provided stream.
 func NewJSONLogger(cfg *LogConfig, writer io.Writer) *JSONLogger {
-	l := &JSONLogger{json.NewEncoder(writer), cfg, nil}
+	l := &JSONLogger{encoder: json.NewEncoder(writer), cfg: cfg}
 	if l.cfg == nil {
 		l.cfg = &LogConfig{}
 	}
@@ -75,13 +76,13 @@ func (l *JSONLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, sco
 		RefundCounter: l.env.IntraBlockState().GetRefund(),
 		Err:           err,
 	}
-	if !l.cfg.DisableMemory {
-		log.Memory = memory.Data()
+	if mem, ok := l.capture.captureMemory(l.cfg, memory.Data()); ok {
+		log.Memory = mem
 	}
-	if !l.cfg.DisableStack {
+	if stackData, ok := l.capture.captureStack(l.cfg, stack.Data); ok {
 		//TODO(@holiman) improve this
-		logstack := make([]*big.Int, len(stack.Data))
-		for i, item := range stack.Data {
+		logstack := make([]*big.Int, len(stackData))
+		for i, item := range stackData {
 			logstack[i] = item.ToBig()
 		}
 		log.Stack = logstack
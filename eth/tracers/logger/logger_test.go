@@ -84,3 +84,51 @@ func TestStoreCapture(t *testing.T) {
 		t.Errorf("expected %x, got %x", exp, logger.storage[contract.Address()][index])
 	}
 }
+
+func TestStructLoggerCaptureLimits(t *testing.T) {
+	c := vm.NewJumpDestCache()
+	var (
+		env      = vm.NewEVM(evmtypes.BlockContext{}, evmtypes.TxContext{}, &dummyStatedb{}, params.TestChainConfig, vm.Config{})
+		logger   = NewStructLogger(&LogConfig{MemoryLimit: 32, StackLimit: 2, ReturnDataLimit: 4, OnlyOnChange: true})
+		mem      = vm.NewMemory()
+		stack    = stack.New()
+		contract = vm.NewContract(&dummyContractRef{}, libcommon.Address{}, new(uint256.Int), 0, false /* skipAnalysis */, c)
+		scope    = &vm.ScopeContext{Memory: mem, Stack: stack, Contract: contract}
+		rData    = []byte{1, 2, 3, 4, 5, 6}
+	)
+	mem.Resize(96)
+	mem.Set(0, 1, []byte{0xff})
+	stack.Push(uint256.NewInt(1))
+	stack.Push(uint256.NewInt(2))
+	stack.Push(uint256.NewInt(3))
+	logger.CaptureStart(env, libcommon.Address{}, libcommon.Address{}, false, false, nil, 0, nil, nil)
+	logger.CaptureState(0, vm.PUSH1, 0, 0, scope, rData, 0, nil)
+	logger.CaptureState(1, vm.PUSH1, 0, 0, scope, rData, 0, nil)
+	stack.Push(uint256.NewInt(4))
+	mem.Set(64, 1, []byte{0xff}) // beyond the memory limit
+	logger.CaptureState(2, vm.POP, 0, 0, scope, rData, 0, nil)
+
+	logs := logger.StructLogs()
+	if len(logs) != 3 {
+		t.Fatalf("expected 3 logs, got %d", len(logs))
+	}
+	first := logs[0]
+	if len(first.Memory) != 32 || first.MemorySize != 96 {
+		t.Errorf("expected 32 of 96 memory bytes, got %d of %d", len(first.Memory), first.MemorySize)
+	}
+	if len(first.Stack) != 2 || first.Stack[0].Uint64() != 2 || first.Stack[1].Uint64() != 3 {
+		t.Errorf("expected the 2 topmost stack items, got %v", first.Stack)
+	}
+	if len(first.ReturnData) != 4 {
+		t.Errorf("expected 4 return data bytes, got %d", len(first.ReturnData))
+	}
+	if logs[1].Memory != nil || logs[1].Stack != nil || logs[1].ReturnData != nil {
+		t.Errorf("expected nothing captured for an unchanged step, got %+v", logs[1])
+	}
+	if logs[2].Memory != nil || logs[2].ReturnData != nil {
+		t.Errorf("expected memory and return data unchanged within the limits, got %+v", logs[2])
+	}
+	if len(logs[2].Stack) != 2 || logs[2].Stack[1].Uint64() != 4 {
+		t.Errorf("expected the changed stack, got %v", logs[2].Stack)
+	}
+}
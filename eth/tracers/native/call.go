@@ -277,6 +277,23 @@ func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
 }
 
+// OnSelfdestructCancelled implements vm.SelfdestructLogger. The SELFDESTRUCT of an account which is not deleted
+// and is its own beneficiary moves no value.
+func (t *callTracer) OnSelfdestructCancelled(addr libcommon.Address, beneficiary libcommon.Address, value *uint256.Int) {
+	if t.config.OnlyTopCall || addr != beneficiary {
+		return
+	}
+	calls := t.callstack[len(t.callstack)-1].Calls
+	if len(calls) == 0 || calls[len(calls)-1].Type != vm.SELFDESTRUCT {
+		return
+	}
+	calls[len(calls)-1].Value = new(big.Int)
+}
+
+// OnBalanceBurn implements vm.SelfdestructLogger, the value of the SELFDESTRUCT frame is what was burnt
+func (t *callTracer) OnBalanceBurn(addr libcommon.Address, amount *uint256.Int) {
+}
+
 func (t *callTracer) CaptureTxStart(gasLimit uint64) {
 	t.gasLimit = gasLimit
 	t.logIndex = 0
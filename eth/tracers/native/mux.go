@@ -106,6 +106,24 @@ func (t *muxTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	}
 }
 
+// OnSelfdestructCancelled implements vm.SelfdestructLogger.
+func (t *muxTracer) OnSelfdestructCancelled(addr libcommon.Address, beneficiary libcommon.Address, value *uint256.Int) {
+	for _, t := range t.tracers {
+		if t, ok := t.(vm.SelfdestructLogger); ok {
+			t.OnSelfdestructCancelled(addr, beneficiary, value)
+		}
+	}
+}
+
+// OnBalanceBurn implements vm.SelfdestructLogger.
+func (t *muxTracer) OnBalanceBurn(addr libcommon.Address, amount *uint256.Int) {
+	for _, t := range t.tracers {
+		if t, ok := t.(vm.SelfdestructLogger); ok {
+			t.OnBalanceBurn(addr, amount)
+		}
+	}
+}
+
 func (t *muxTracer) CaptureTxStart(gasLimit uint64) {
 	for _, t := range t.tracers {
 		t.CaptureTxStart(gasLimit)
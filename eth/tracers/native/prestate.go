@@ -187,6 +187,15 @@ func (t *prestateTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64,
 	}
 }
 
+// OnSelfdestructCancelled implements vm.SelfdestructLogger: the account stays, so its post state is reported
+func (t *prestateTracer) OnSelfdestructCancelled(addr libcommon.Address, beneficiary libcommon.Address, value *uint256.Int) {
+	delete(t.deleted, addr)
+}
+
+// OnBalanceBurn implements vm.SelfdestructLogger, the account is deleted anyway
+func (t *prestateTracer) OnBalanceBurn(addr libcommon.Address, amount *uint256.Int) {
+}
+
 func (t *prestateTracer) CaptureTxStart(gasLimit uint64) {
 	t.gasLimit = gasLimit
 }
@@ -47,7 +47,8 @@ import (
 // 3.1.0 - add Subscribe to logs
 // 3.2.0 - add EngineGetBlobsBundleV1
 // 3.3.0 - merge EngineGetBlobsBundleV1 into EngineGetPayload
-var EthBackendAPIVersion = &types2.VersionReply{Major: 3, Minor: 3, Patch: 0}
+// 4.0.0 - TxnLookup returns the txNum of the transaction itself, not the next one
+var EthBackendAPIVersion = &types2.VersionReply{Major: 4, Minor: 0, Patch: 0}
 
 type EthBackendServer struct {
 	remote.UnimplementedETHBACKENDServer // must be embedded to have forward compatible implementations.
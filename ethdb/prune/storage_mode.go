@@ -276,6 +276,33 @@ func setOnEmpty(db kv.GetPut, key []byte, blockAmount BlockAmount) error {
 	return nil
 }
 
+// HistoryStart - the txNum the history starts from, 0 if the prune command didn't drop any history files
+func HistoryStart(db kv.Getter) (uint64, error) {
+	v, err := db.GetOne(kv.DatabaseInfo, kv.PruneHistoryStart)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) != 8 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// SetHistoryStart - persists the txNum the history starts from after the older history files were dropped.
+// It never moves back: the dropped history doesn't come back.
+func SetHistoryStart(db kv.GetPut, txNum uint64) error {
+	start, err := HistoryStart(db)
+	if err != nil {
+		return err
+	}
+	if txNum <= start {
+		return nil
+	}
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, txNum)
+	return db.Put(kv.DatabaseInfo, kv.PruneHistoryStart, v)
+}
+
 func setMode(db kv.RwTx, key []byte, currentValue bool) error {
 	val := []byte{2}
 	if currentValue {
@@ -64,3 +64,16 @@ func TestDistancePruneTo(t *testing.T) {
 		})
 	}
 }
+
+func TestHistoryStart(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	start, err := HistoryStart(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), start)
+
+	assert.NoError(t, SetHistoryStart(tx, 2_000))
+	assert.NoError(t, SetHistoryStart(tx, 1_000)) // the dropped history doesn't come back
+	start, err = HistoryStart(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2_000), start)
+}
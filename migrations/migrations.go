@@ -56,6 +56,8 @@ var migrations = map[kv.Label][]Migration{
 		ProhibitNewDownloadsLock2,
 		ClearBorTables,
 		ResetStageTxnLookup,
+		ResetStageTxnLookupTxNum,
+		WithdrawalsIndexStart,
 	},
 	kv.TxPoolDB: {},
 	kv.SentryDB: {},
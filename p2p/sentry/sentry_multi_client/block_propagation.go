@@ -0,0 +1,234 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+const (
+	// propagationTrackedBlocks is the number of the most recently announced blocks kept to match the receipts with
+	propagationTrackedBlocks = 1024
+	// propagationWindow is the number of the latest latencies the percentiles are taken over
+	propagationWindow = 1024
+)
+
+var (
+	headerPropagationP50 = metrics.GetOrCreateGauge(`block_propagation_header_seconds{quantile="0.5"}`)
+	headerPropagationP95 = metrics.GetOrCreateGauge(`block_propagation_header_seconds{quantile="0.95"}`)
+	bodyPropagationP50   = metrics.GetOrCreateGauge(`block_propagation_body_seconds{quantile="0.5"}`)
+	bodyPropagationP95   = metrics.GetOrCreateGauge(`block_propagation_body_seconds{quantile="0.95"}`)
+)
+
+// blockArrival is what is known about the arrival of an announced block
+type blockArrival struct {
+	announced  time.Time             // First announcement of the block, by any peer
+	announcers map[[64]byte]struct{} // Peers which have announced the block
+	header     bool                  // Whether the header has been received
+	body       bool                  // Whether the body has been received
+}
+
+// peerPropagation is how fast a peer announces the blocks, compared to the first announcement of each block
+type peerPropagation struct {
+	announcements uint64
+	first         uint64
+	totalDelay    time.Duration
+	maxDelay      time.Duration
+}
+
+// latencyWindow keeps the latest latencies in a ring to take the percentiles over
+type latencyWindow struct {
+	latencies []time.Duration
+	next      int
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	if len(w.latencies) < propagationWindow {
+		w.latencies = append(w.latencies, latency)
+		return
+	}
+	w.latencies[w.next] = latency
+	w.next = (w.next + 1) % propagationWindow
+}
+
+// percentiles returns the latencies at the given fractions of the window, zeroes if the window is empty
+func (w *latencyWindow) percentiles(fractions ...float64) []time.Duration {
+	result := make([]time.Duration, len(fractions))
+	if len(w.latencies) == 0 {
+		return result
+	}
+	sorted := append([]time.Duration{}, w.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i, fraction := range fractions {
+		result[i] = sorted[int(fraction*float64(len(sorted)-1)+0.5)]
+	}
+	return result
+}
+
+// BlockPropagation tracks how fast the announced blocks reach the node: the latency from the first announcement of a
+// block, by NewBlockHashes or NewBlock, to the receipt of its header and of its body, and for every announcing peer,
+// how far behind the first announcement it announces the blocks. Peers are forgotten when they disconnect.
+type BlockPropagation struct {
+	lock    sync.Mutex
+	now     func() time.Time
+	blocks  *simplelru.LRU[libcommon.Hash, *blockArrival]
+	peers   map[[64]byte]*peerPropagation
+	headers latencyWindow
+	bodies  latencyWindow
+}
+
+func NewBlockPropagation() *BlockPropagation {
+	blocks, err := simplelru.NewLRU[libcommon.Hash, *blockArrival](propagationTrackedBlocks, nil)
+	if err != nil {
+		panic(err)
+	}
+	return &BlockPropagation{
+		now:    time.Now,
+		blocks: blocks,
+		peers:  map[[64]byte]*peerPropagation{},
+	}
+}
+
+// Announced records the announcement of the block by the peer
+func (bp *BlockPropagation) Announced(hash libcommon.Hash, peer [64]byte) {
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	now := bp.now()
+	stats, ok := bp.peers[peer]
+	if !ok {
+		stats = &peerPropagation{}
+		bp.peers[peer] = stats
+	}
+	arrival, ok := bp.blocks.Get(hash)
+	if !ok {
+		bp.blocks.Add(hash, &blockArrival{announced: now, announcers: map[[64]byte]struct{}{peer: {}}})
+		stats.announcements++
+		stats.first++
+		return
+	}
+	if _, ok := arrival.announcers[peer]; ok {
+		return
+	}
+	arrival.announcers[peer] = struct{}{}
+	delay := now.Sub(arrival.announced)
+	stats.announcements++
+	stats.totalDelay += delay
+	if delay > stats.maxDelay {
+		stats.maxDelay = delay
+	}
+}
+
+// HeaderReceived records the receipt of the header of the block, if the block has been announced
+func (bp *BlockPropagation) HeaderReceived(hash libcommon.Hash) {
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	arrival, ok := bp.blocks.Peek(hash)
+	if !ok || arrival.header {
+		return
+	}
+	arrival.header = true
+	bp.headers.add(bp.now().Sub(arrival.announced))
+	p := bp.headers.percentiles(0.5, 0.95)
+	headerPropagationP50.Set(p[0].Seconds())
+	headerPropagationP95.Set(p[1].Seconds())
+}
+
+// BodyReceived records the receipt of the body of the block, if the block has been announced
+func (bp *BlockPropagation) BodyReceived(hash libcommon.Hash) {
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	arrival, ok := bp.blocks.Peek(hash)
+	if !ok || arrival.body {
+		return
+	}
+	arrival.body = true
+	bp.bodies.add(bp.now().Sub(arrival.announced))
+	p := bp.bodies.percentiles(0.5, 0.95)
+	bodyPropagationP50.Set(p[0].Seconds())
+	bodyPropagationP95.Set(p[1].Seconds())
+}
+
+// PeerDisconnected forgets the peer
+func (bp *BlockPropagation) PeerDisconnected(peer [64]byte) {
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	delete(bp.peers, peer)
+}
+
+// PeerPropagationStats is how far behind the first announcement of the blocks a peer announces them
+type PeerPropagationStats struct {
+	PeerID        string `json:"peerId"`
+	Announcements uint64 `json:"announcements"`
+	First         uint64 `json:"first"`
+	AvgDelayMs    uint64 `json:"avgDelayMs"`
+	MaxDelayMs    uint64 `json:"maxDelayMs"`
+}
+
+// BlockPropagationStats is the latency from the first announcement of the recent blocks to the receipt of their
+// headers and bodies, and the announcing peers
+type BlockPropagationStats struct {
+	Blocks      uint64                 `json:"blocks"`
+	HeaderP50Ms uint64                 `json:"headerP50Ms"`
+	HeaderP95Ms uint64                 `json:"headerP95Ms"`
+	BodyP50Ms   uint64                 `json:"bodyP50Ms"`
+	BodyP95Ms   uint64                 `json:"bodyP95Ms"`
+	Peers       []PeerPropagationStats `json:"peers"`
+}
+
+// Stats returns the latency percentiles and the announcing peers, the slowest ones first, at most limit of them if
+// limit is not 0
+func (bp *BlockPropagation) Stats(limit int) *BlockPropagationStats {
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	headers := bp.headers.percentiles(0.5, 0.95)
+	bodies := bp.bodies.percentiles(0.5, 0.95)
+	stats := &BlockPropagationStats{
+		Blocks:      uint64(bp.blocks.Len()),
+		HeaderP50Ms: uint64(headers[0].Milliseconds()),
+		HeaderP95Ms: uint64(headers[1].Milliseconds()),
+		BodyP50Ms:   uint64(bodies[0].Milliseconds()),
+		BodyP95Ms:   uint64(bodies[1].Milliseconds()),
+		Peers:       make([]PeerPropagationStats, 0, len(bp.peers)),
+	}
+	for peer, peerStats := range bp.peers {
+		stats.Peers = append(stats.Peers, PeerPropagationStats{
+			PeerID:        hex.EncodeToString(peer[:]),
+			Announcements: peerStats.announcements,
+			First:         peerStats.first,
+			AvgDelayMs:    uint64((peerStats.totalDelay / time.Duration(peerStats.announcements)).Milliseconds()),
+			MaxDelayMs:    uint64(peerStats.maxDelay.Milliseconds()),
+		})
+	}
+	sort.Slice(stats.Peers, func(i, j int) bool {
+		if stats.Peers[i].AvgDelayMs != stats.Peers[j].AvgDelayMs {
+			return stats.Peers[i].AvgDelayMs > stats.Peers[j].AvgDelayMs
+		}
+		return stats.Peers[i].PeerID < stats.Peers[j].PeerID
+	})
+	if limit > 0 && len(stats.Peers) > limit {
+		stats.Peers = stats.Peers[:limit]
+	}
+	return stats
+}
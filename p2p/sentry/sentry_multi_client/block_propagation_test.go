@@ -0,0 +1,70 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+)
+
+func TestBlockPropagation(t *testing.T) {
+	bp := NewBlockPropagation()
+	now := time.Unix(1700000000, 0)
+	bp.now = func() time.Time { return now }
+	fast, slow := [64]byte{1}, [64]byte{2}
+
+	for i := 0; i < 10; i++ {
+		hash := libcommon.Hash{byte(i)}
+		bp.Announced(hash, fast)
+		now = now.Add(100 * time.Millisecond)
+		bp.Announced(hash, slow)
+		bp.Announced(hash, slow) // announced again in full, counted once
+		now = now.Add(time.Duration(i+1) * 10 * time.Millisecond)
+		bp.HeaderReceived(hash)
+		bp.HeaderReceived(hash) // delivered by another peer too
+		now = now.Add(200 * time.Millisecond)
+		bp.BodyReceived(hash)
+	}
+	bp.HeaderReceived(libcommon.Hash{0xff}) // requested, not announced
+
+	stats := bp.Stats(0)
+	require.Equal(t, uint64(10), stats.Blocks)
+	require.Equal(t, uint64(160), stats.HeaderP50Ms)
+	require.Equal(t, uint64(200), stats.HeaderP95Ms)
+	require.Equal(t, uint64(360), stats.BodyP50Ms)
+	require.Equal(t, uint64(400), stats.BodyP95Ms)
+	require.Len(t, stats.Peers, 2)
+	require.Equal(t, hex.EncodeToString(slow[:]), stats.Peers[0].PeerID)
+	require.Equal(t, uint64(10), stats.Peers[0].Announcements)
+	require.Equal(t, uint64(0), stats.Peers[0].First)
+	require.Equal(t, uint64(100), stats.Peers[0].AvgDelayMs)
+	require.Equal(t, uint64(100), stats.Peers[0].MaxDelayMs)
+	require.Equal(t, hex.EncodeToString(fast[:]), stats.Peers[1].PeerID)
+	require.Equal(t, uint64(10), stats.Peers[1].First)
+	require.Equal(t, uint64(0), stats.Peers[1].AvgDelayMs)
+
+	require.Len(t, bp.Stats(1).Peers, 1)
+	bp.PeerDisconnected(slow)
+	stats = bp.Stats(0)
+	require.Len(t, stats.Peers, 1)
+	require.Equal(t, hex.EncodeToString(fast[:]), stats.Peers[0].PeerID)
+}
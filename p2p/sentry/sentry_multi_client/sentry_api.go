@@ -54,23 +54,49 @@ func (cs *MultiClient) SetStatus(ctx context.Context) {
 }
 
 func (cs *MultiClient) SendBodyRequest(ctx context.Context, req *bodydownload.BodyRequest) (peerID [64]byte, ok bool) {
+	//log.Info(fmt.Sprintf("Sending body request for %v", req.BlockNums))
+	bytes, err := rlp.EncodeToBytes(&eth.GetBlockBodiesPacket66{
+		RequestId:            rand.Uint64(), // nolint: gosec
+		GetBlockBodiesPacket: req.Hashes,
+	})
+	if err != nil {
+		cs.logger.Error("Could not encode block bodies request", "err", err)
+		return [64]byte{}, false
+	}
+
+	// the request is sized for the preferred peer, only if it is gone is the request sent to another peer
+	if preferredPeer, ok := req.PreferredPeer(); ok {
+		outreq := proto_sentry.SendMessageByIdRequest{
+			PeerId: gointerfaces.ConvertHashToH512(preferredPeer),
+			Data: &proto_sentry.OutboundMessageData{
+				Id:   proto_sentry.MessageId_GET_BLOCK_BODIES_66,
+				Data: bytes,
+			},
+		}
+		for i, ok, next := cs.randSentryIndex(); ok; i, ok = next() {
+			if ready, ok := cs.sentries[i].(interface{ Ready() bool }); ok && !ready.Ready() {
+				continue
+			}
+			sentPeers, err1 := cs.sentries[i].SendMessageById(ctx, &outreq, &grpc.EmptyCallOption{})
+			if err1 != nil {
+				if !isPeerNotFoundErr(err1) {
+					cs.logger.Error("Could not send block bodies request", "err", err1)
+				}
+				continue
+			}
+			if sentPeers == nil || len(sentPeers.Peers) == 0 {
+				continue
+			}
+			return sentry.ConvertH512ToPeerID(sentPeers.Peers[0]), true
+		}
+	}
+
 	// if sentry not found peers to send such message, try next one. stop if found.
 	for i, ok, next := cs.randSentryIndex(); ok; i, ok = next() {
 		if ready, ok := cs.sentries[i].(interface{ Ready() bool }); ok && !ready.Ready() {
 			continue
 		}
 
-		//log.Info(fmt.Sprintf("Sending body request for %v", req.BlockNums))
-		var bytes []byte
-		var err error
-		bytes, err = rlp.EncodeToBytes(&eth.GetBlockBodiesPacket66{
-			RequestId:            rand.Uint64(), // nolint: gosec
-			GetBlockBodiesPacket: req.Hashes,
-		})
-		if err != nil {
-			cs.logger.Error("Could not encode block bodies request", "err", err)
-			return [64]byte{}, false
-		}
 		outreq := proto_sentry.SendMessageByMinBlockRequest{
 			MinBlock: req.BlockNums[len(req.BlockNums)-1],
 			Data: &proto_sentry.OutboundMessageData{
@@ -34,9 +34,11 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 
+	"github.com/erigontech/erigon-lib/app"
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common/dbg"
 	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
 	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
 	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
 	"github.com/erigontech/erigon-lib/kv"
@@ -141,6 +143,7 @@ func (cs *MultiClient) PeerEventsLoop(
 type MultiClient struct {
 	Hd                                *headerdownload.HeaderDownload
 	Bd                                *bodydownload.BodyDownload
+	Propagation                       *BlockPropagation
 	IsMock                            bool
 	sentries                          []proto_sentry.SentryClient
 	ChainConfig                       *chain.Config
@@ -213,6 +216,7 @@ func NewMultiClient(
 	cs := &MultiClient{
 		Hd:                                hd,
 		Bd:                                bd,
+		Propagation:                       NewBlockPropagation(),
 		sentries:                          sentries,
 		ChainConfig:                       chainConfig,
 		db:                                db,
@@ -246,7 +250,9 @@ func (cs *MultiClient) newBlockHashes66(ctx context.Context, req *proto_sentry.I
 	if err := rlp.DecodeBytes(req.Data, &request); err != nil {
 		return fmt.Errorf("decode NewBlockHashes66: %w", err)
 	}
+	peerID := gointerfaces.ConvertH512ToHash(req.PeerId)
 	for _, announce := range request {
+		cs.Propagation.Announced(announce.Hash, peerID)
 		cs.Hd.SaveExternalAnnounce(announce.Hash)
 		if cs.Hd.HasLink(announce.Hash) {
 			continue
@@ -329,10 +335,12 @@ func (cs *MultiClient) blockHeaders(ctx context.Context, pkt eth.BlockHeadersPac
 		if number > highestBlock {
 			highestBlock = number
 		}
+		hash := types.RawRlpHash(hRaw)
+		cs.Propagation.HeaderReceived(hash)
 		csHeaders = append(csHeaders, headerdownload.ChainSegmentHeader{
 			Header:    header,
 			HeaderRaw: hRaw,
-			Hash:      types.RawRlpHash(hRaw),
+			Hash:      hash,
 			Number:    number,
 		})
 		//blockNums = append(blockNums, int(number))
@@ -411,6 +419,11 @@ func (cs *MultiClient) newBlock66(ctx context.Context, inreq *proto_sentry.Inbou
 	if err := request.Block.HashCheck(true); err != nil {
 		return fmt.Errorf("newBlock66: %w", err)
 	}
+	// The block comes in full: announced, with the header and the body
+	hash := request.Block.Hash()
+	cs.Propagation.Announced(hash, sentry.ConvertH512ToPeerID(inreq.PeerId))
+	cs.Propagation.HeaderReceived(hash)
+	cs.Propagation.BodyReceived(hash)
 
 	if segments, penalty, err := cs.Hd.SingleHeaderAsSegment(headerRaw, request.Block.Header(), true /* penalizePoSBlocks */); err == nil {
 		if penalty == headerdownload.NoPenalty {
@@ -686,6 +699,14 @@ func (cs *MultiClient) HandlePeerEvent(ctx context.Context, event *proto_sentry.
 	peerID := sentry.ConvertH512ToPeerID(event.PeerId)
 	peerIDStr := hex.EncodeToString(peerID[:])
 
+	switch event.EventId {
+	case proto_sentry.PeerEvent_Connect:
+		app.PublishEvent(app.PeerConnected{PeerID: peerID})
+	case proto_sentry.PeerEvent_Disconnect:
+		app.PublishEvent(app.PeerDisconnected{PeerID: peerID})
+		cs.Propagation.PeerDisconnected(peerID)
+	}
+
 	if !cs.logPeerInfo {
 		cs.logger.Trace("[p2p] Sentry peer did", "eventID", eventID, "peer", peerIDStr)
 		return nil
@@ -23,7 +23,9 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
+	"os"
 	"path"
 
 	"github.com/erigontech/erigon-lib/chain"
@@ -44,23 +46,44 @@ func readChainSpec(filename string) *chain.Config {
 	}
 	defer f.Close()
 
-	decoder := json.NewDecoder(f)
-	spec := &chain.Config{}
-	err = decoder.Decode(&spec)
+	spec, err := decodeChainSpec(f)
 	if err != nil {
 		panic(fmt.Sprintf("Could not parse chainspec for %s: %v", filename, err))
 	}
+	return spec
+}
+
+// ReadChainSpecFile reads a chain spec of the same format as the embedded ones (see chainspecs dir)
+// from the file system, e.g. the one of a custom devnet.
+func ReadChainSpecFile(filename string) (*chain.Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	spec, err := decodeChainSpec(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse chainspec %s: %w", filename, err)
+	}
+	return spec, nil
+}
+
+func decodeChainSpec(r io.Reader) (*chain.Config, error) {
+	decoder := json.NewDecoder(r)
+	spec := &chain.Config{}
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, err
+	}
 
 	if spec.BorJSON != nil {
 		borConfig := &borcfg.BorConfig{}
-		err = json.Unmarshal(spec.BorJSON, borConfig)
-		if err != nil {
-			panic(fmt.Sprintf("Could not parse 'bor' chainspec for %s: %v", filename, err))
+		if err := json.Unmarshal(spec.BorJSON, borConfig); err != nil {
+			return nil, fmt.Errorf("'bor': %w", err)
 		}
 		spec.Bor = borConfig
 	}
-
-	return spec
+	return spec, nil
 }
 
 // Genesis hashes to enforce below configs on.
@@ -37,4 +37,5 @@ type MiningConfig struct {
 	GasLimit   uint64            // Target gas limit for mined blocks.
 	GasPrice   *big.Int          // Minimum gas price for mining a transaction
 	Recommit   time.Duration     // The time interval for miner to re-create mining work.
+	BuildLead  time.Duration     // Bor: how long before the producer slot of the node to start building the block.
 }
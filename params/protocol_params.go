@@ -182,6 +182,9 @@ const (
 
 	// EIP-7702
 	SetCodeMagicPrefix = byte(0x05)
+
+	// RIP-7560: Native Account Abstraction
+	TxAAGas uint64 = 15000 // Base intrinsic gas of an account abstraction transaction
 )
 
 var DelegatedDesignationPrefix = []byte{0xef, 0x01, 0x00}
@@ -207,3 +210,7 @@ var (
 	MinimumDifficulty      = big.NewInt(131072) // The minimum that the difficulty may ever be.
 	DurationLimit          = big.NewInt(13)     // The decision boundary on the blocktime duration used to determine whether difficulty should go up or not.
 )
+
+// RIP-7560: Native Account Abstraction
+var AAEntryPointAddress = common.HexToAddress("0x0000000000000000000000000000000000007560")
+var AASenderCreatorAddress = common.HexToAddress("0x00000000000000000000000000000000ffff7560")
@@ -63,6 +63,7 @@ import (
 	"github.com/erigontech/erigon/polygon/bor/finality/whitelist"
 	"github.com/erigontech/erigon/polygon/bor/statefull"
 	"github.com/erigontech/erigon/polygon/bor/valset"
+	"github.com/erigontech/erigon/polygon/bridge"
 	"github.com/erigontech/erigon/polygon/heimdall"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/services"
@@ -258,7 +259,7 @@ type spanReader interface {
 }
 
 type bridgeReader interface {
-	Events(ctx context.Context, blockNum uint64) ([]*types.Message, error)
+	bridge.EventInjector
 	EventTxnLookup(ctx context.Context, borTxHash libcommon.Hash) (uint64, bool, error)
 }
 
@@ -487,6 +488,83 @@ func (c *Bor) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.
 	return abort, results
 }
 
+// VerifySprintHeaders verifies a batch of consecutive headers, typically a whole sprint, including their seals.
+// The checks of the individual headers and the recovery of their signers are done in parallel, the signers
+// are then checked against the validator set sequentially, since the validator set of the next sprint is
+// derived from the last header of the current one.
+func (c *Bor) VerifySprintHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	if headers[0].Number.Uint64() == 0 {
+		return errUnknownBlock
+	}
+	for i := 1; i < len(headers); i++ {
+		if headers[i].Number.Uint64() != headers[i-1].Number.Uint64()+1 || headers[i].ParentHash != headers[i-1].Hash() {
+			return errOutOfRangeChain
+		}
+	}
+
+	// the first header is checked against its parent from the db, the rest only against the batch
+	if err := c.verifyHeader(chain, headers[0], nil); err != nil {
+		return err
+	}
+
+	g := errgroup.Group{}
+	g.SetLimit(estimate.AlmostAllCPUs())
+	for i, header := range headers {
+		g.Go(func() error {
+			if i > 0 {
+				if err := c.verifyHeader(chain, header, headers[:i]); err != nil {
+					return err
+				}
+			}
+			// warm up the signatures cache for the sequential checks below
+			_, err := Ecrecover(header, c.Signatures, c.config)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	first := headers[0]
+	parent := chain.GetHeader(first.ParentHash, first.Number.Uint64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+
+	if c.useSpanReader {
+		parents := append([]*types.Header{parent}, headers...)
+		for i, header := range headers {
+			validatorSet, err := c.spanReader.Producers(context.Background(), header.Number.Uint64())
+			if err != nil {
+				return err
+			}
+			if err := c.verifySeal(nil, header, parents[:i+1], validatorSet); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	snap, err := c.snapshot(chain.(ChainHeaderReader), first.Number.Uint64()-1, first.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	for _, header := range headers {
+		// checks the time and the difficulty of the header against the current validator set
+		// and moves on to the next validator set at the end of the sprint
+		if snap, err = snap.Apply(parent, []*types.Header{header}, c.logger); err != nil {
+			return err
+		}
+		parent = header
+	}
+	c.Recents.Add(snap.Hash, snap)
+
+	return nil
+}
+
 // verifyHeader checks whether a header conforms to the consensus rules.The
 // caller may optionally pass in a batch of parents (ascending order) to avoid
 // looking those up from the database. This is useful for concurrently verifying
@@ -1249,6 +1327,40 @@ func (c *Bor) Seal(chain consensus.ChainHeaderReader, blockWithReceipts *types.B
 	return nil
 }
 
+// ProducerSlot returns the earliest time the local signer may seal the block on top of the parent, and its succession
+// number in the producers of the span of the block, 0 for the primary producer. ok is false if the signer is not one of
+// the producers, the block isn't the signer's to seal then.
+func (c *Bor) ProducerSlot(chain consensus.ChainHeaderReader, parent *types.Header) (slot time.Time, succession int, ok bool, err error) {
+	number := parent.Number.Uint64() + 1
+	signer := c.authorizedSigner.Load().signer
+
+	var validatorSet *valset.ValidatorSet
+	if c.useSpanReader {
+		validatorSet, err = c.spanReader.Producers(context.Background(), number)
+		if err != nil {
+			return time.Time{}, 0, false, err
+		}
+	} else {
+		snap, err := c.snapshot(chain.(ChainHeaderReader), number-1, parent.Hash(), nil)
+		if err != nil {
+			return time.Time{}, 0, false, err
+		}
+
+		validatorSet = snap.ValidatorSet
+	}
+
+	if !validatorSet.HasAddress(signer) {
+		return time.Time{}, 0, false, nil
+	}
+
+	succession, err = validatorSet.GetSignerSuccessionNumber(signer, number)
+	if err != nil {
+		return time.Time{}, 0, false, err
+	}
+
+	return time.Unix(int64(MinNextBlockTime(parent, succession, c.config)), 0), succession, true, nil
+}
+
 // IsValidator returns true if this instance is the validator for this block
 func (c *Bor) IsValidator(header *types.Header) (bool, error) {
 	number := header.Number.Uint64()
@@ -389,6 +389,29 @@ func TestVerifyHeader(t *testing.T) {
 	}
 }
 
+func TestProducerSlot(t *testing.T) {
+	v := newValidator(t, newTestHeimdall(params.BorDevnetChainConfig), map[uint64]*types.Block{})
+
+	chain, err := v.generateChain(1)
+	require.NoError(t, err)
+
+	sealedBlocks, err := v.sealBlocks(chain.Blocks, chain.Receipts)
+	require.NoError(t, err)
+
+	parent := sealedBlocks[0].Header()
+	engine := v.Engine.(*bor.Bor)
+	slot, succession, ok, err := engine.ProducerSlot(headerReader{v}, parent)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 0, succession)
+	require.Equal(t, int64(parent.Time+v.heimdall.BorConfig().CalculatePeriod(parent.Number.Uint64()+1)), slot.Unix())
+
+	engine.Authorize(libcommon.Address{1}, nil)
+	_, _, ok, err = engine.ProducerSlot(headerReader{v}, parent)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
 func TestVerifyRun(t *testing.T) {
 	//testVerify(t, 5, 8)
 }
@@ -119,6 +119,9 @@ func UnwindHeimdall(ctx context.Context, heimdallStore heimdall.Store, bridgeSto
 		if err := UnwindSpanBlockProducerSelections(ctx, heimdallStore, tx, unwindPoint); err != nil {
 			return err
 		}
+		if err := UnwindSprintBlockProducerSelections(ctx, heimdallStore, tx, unwindPoint); err != nil {
+			return err
+		}
 	}
 
 	if heimdall.CheckpointsEnabled() && !unwindCfg.KeepCheckpoints {
@@ -152,6 +155,14 @@ func UnwindSpanBlockProducerSelections(ctx context.Context, heimdallStore heimda
 	return err
 }
 
+func UnwindSprintBlockProducerSelections(ctx context.Context, heimdallStore heimdall.Store, tx kv.RwTx, unwindPoint uint64) error {
+	_, err := heimdallStore.SprintBlockProducerSelections().(interface {
+		WithTx(kv.Tx) heimdall.EntityStore[*heimdall.SprintBlockProducerSelection]
+	}).WithTx(tx).DeleteFromBlockNum(ctx, unwindPoint)
+
+	return err
+}
+
 func UnwindCheckpoints(ctx context.Context, heimdallStore heimdall.Store, tx kv.RwTx, unwindPoint uint64) error {
 	_, err := heimdallStore.Checkpoints().(interface {
 		WithTx(kv.Tx) heimdall.EntityStore[*heimdall.Checkpoint]
@@ -220,6 +231,23 @@ func PruneHeimdall(ctx context.Context, heimdallStore heimdall.Store, bridgeStor
 		return deleted, err
 	}
 
+	sprintBPStore := heimdallStore.SprintBlockProducerSelections()
+
+	if tx != nil {
+		sprintBPStore = sprintBPStore.(interface {
+			WithTx(kv.Tx) heimdall.EntityStore[*heimdall.SprintBlockProducerSelection]
+		}).WithTx(tx)
+	}
+
+	sprintsDeleted, err := sprintBPStore.DeleteToBlockNum(ctx, blocksTo, blocksDeleteLimit)
+
+	if sprintsDeleted > deleted {
+		deleted = sprintsDeleted
+	}
+	if err != nil {
+		return deleted, err
+	}
+
 	if heimdall.CheckpointsEnabled() {
 		checkpointStore := heimdallStore.Checkpoints()
 
@@ -0,0 +1,63 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+var ErrBlockEventsMismatch = errors.New("block events mismatch")
+
+// EventInjector provides the execution with the state sync events to inject into the state at a (sprint start) block.
+// The events are exactly the ones recorded for the block, in id order, or an error is returned.
+type EventInjector interface {
+	Events(ctx context.Context, blockNum uint64) ([]*types.Message, error)
+}
+
+var (
+	_ EventInjector = (*Reader)(nil)
+	_ EventInjector = (*RemoteReader)(nil)
+)
+
+// validateBlockEvents checks that the events read for a block are the whole [start, end] range of event ids recorded
+// for it, in order.
+func validateBlockEvents(blockNum uint64, start, end uint64, events [][]byte) error {
+	if start == 0 { // the range of the first block with events may start at 0, while the event ids start at 1
+		start = 1
+	}
+	if end < start {
+		return fmt.Errorf("%w: block %d, invalid event ids range [%d, %d]", ErrBlockEventsMismatch, blockNum, start, end)
+	}
+	if uint64(len(events)) != end-start+1 {
+		return fmt.Errorf("%w: block %d, expected %d events in [%d, %d], got %d", ErrBlockEventsMismatch, blockNum, end-start+1, start, end, len(events))
+	}
+	for i, raw := range events {
+		var event heimdall.EventRecordWithTime
+		if err := event.UnmarshallBytes(raw); err != nil {
+			return fmt.Errorf("%w: block %d, event %d: %w", ErrBlockEventsMismatch, blockNum, start+uint64(i), err)
+		}
+		if event.ID != start+uint64(i) {
+			return fmt.Errorf("%w: block %d, expected event %d, got %d", ErrBlockEventsMismatch, blockNum, start+uint64(i), event.ID)
+		}
+	}
+	return nil
+}
@@ -64,7 +64,7 @@ type txStore struct {
 }
 
 func NewMdbxStore(dataDir string, logger log.Logger, accede bool, roTxLimit int64) *MdbxStore {
-	return &MdbxStore{db: polygoncommon.NewDatabase(dataDir, kv.PolygonBridgeDB, databaseTablesCfg, logger, accede, roTxLimit)}
+	return &MdbxStore{db: polygoncommon.NewDatabase(dataDir, kv.PolygonBridgeDB, databaseTablesCfg, databaseMigrations, logger, accede, roTxLimit)}
 }
 
 func NewDbStore(db kv.RoDB) *MdbxStore {
@@ -342,7 +342,12 @@ func (s *MdbxStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDele
 	}
 	defer tx.Rollback()
 
-	return txStore{tx}.PruneEvents(ctx, blocksTo, blocksDeleteLimit)
+	deleted, err = txStore{tx}.PruneEvents(ctx, blocksTo, blocksDeleteLimit)
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted, tx.Commit()
 }
 
 func NewTxStore(tx kv.Tx) txStore {
@@ -678,6 +683,30 @@ func (s txStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDeleteL
 		return deleted, err
 	}
 
+	// block num -> last event id, blockEventIdsRange of the first block left falls back to the last frozen event id
+	numsCursor, err := tx.RwCursor(kv.BorEventNums)
+	if err != nil {
+		return deleted, err
+	}
+	defer numsCursor.Close()
+	counter = blocksDeleteLimit
+	for k, _, err = numsCursor.First(); err == nil && k != nil && counter > 0; k, _, err = numsCursor.Next() {
+		blockNum := binary.BigEndian.Uint64(k)
+		if blockNum >= blocksTo {
+			break
+		}
+
+		if err = numsCursor.DeleteCurrent(); err != nil {
+			return deleted, err
+		}
+
+		deleted++
+		counter--
+	}
+	if err != nil {
+		return deleted, err
+	}
+
 	epbCursor, err := tx.RwCursor(kv.BorEventProcessedBlocks)
 	if err != nil {
 		return deleted, err
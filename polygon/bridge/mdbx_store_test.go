@@ -0,0 +1,69 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/polygon/heimdall"
+	"github.com/erigontech/erigon/turbo/testlog"
+)
+
+func TestMdbxStore_PruneEvents(t *testing.T) {
+	ctx := context.Background()
+	logger := testlog.Logger(t, log.LvlDebug)
+	store := NewMdbxStore(t.TempDir(), logger, false, 1)
+	require.NoError(t, store.Prepare(ctx))
+	t.Cleanup(store.Close)
+
+	events := make([]*heimdall.EventRecordWithTime, 0, 6)
+	for id := uint64(1); id <= 6; id++ {
+		events = append(events, &heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: id, ChainID: "80002"},
+			Time:        time.Unix(int64(id), 0),
+		})
+	}
+	require.NoError(t, store.PutEvents(ctx, events))
+	require.NoError(t, store.PutBlockNumToEventId(ctx, map[uint64]uint64{2: 2, 4: 4, 6: 6}))
+
+	deleted, err := store.PruneEvents(ctx, 5, 100)
+	require.NoError(t, err)
+	require.Equal(t, 6, deleted) // events 1-4 and the block nums of blocks 2 and 4
+
+	pruned, err := store.Events(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Empty(t, pruned)
+
+	kept, err := store.Events(ctx, 5, 7)
+	require.NoError(t, err)
+	require.Len(t, kept, 2)
+
+	_, _, ok, err := store.BlockEventIdsRange(ctx, 4)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	start, end, ok, err := store.blockEventIdsRange(ctx, 6, 4)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(5), start)
+	require.Equal(t, uint64(6), end)
+}
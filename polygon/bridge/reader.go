@@ -19,6 +19,7 @@ package bridge
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -32,6 +33,7 @@ import (
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/polygon/heimdall"
 )
 
 type Reader struct {
@@ -86,6 +88,9 @@ func (r *Reader) Events(ctx context.Context, blockNum uint64) ([]*types.Message,
 	if err != nil {
 		return nil, err
 	}
+	if err := validateBlockEvents(blockNum, start, end, events); err != nil {
+		return nil, err
+	}
 
 	if len(events) > 0 {
 		r.logger.Debug(bridgeLogPrefix("events for block"), "block", blockNum, "start", start, "end", end)
@@ -115,6 +120,35 @@ func (r *Reader) EventTxnLookup(ctx context.Context, borTxHash libcommon.Hash) (
 	return r.store.EventTxnToBlockNum(ctx, borTxHash)
 }
 
+// EventsByIdRange returns at most limit events with ids starting from fromId, ordered by id
+func (r *Reader) EventsByIdRange(ctx context.Context, fromId uint64, limit uint64) ([]*heimdall.EventRecordWithTime, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+
+	toId := fromId + limit
+	if toId < fromId { // the page runs past the last possible id
+		toId = math.MaxUint64
+	}
+
+	eventsRaw, err := r.store.Events(ctx, fromId, toId)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*heimdall.EventRecordWithTime, 0, len(eventsRaw))
+	for _, eventRaw := range eventsRaw {
+		var event heimdall.EventRecordWithTime
+		if err := event.UnmarshallBytes(eventRaw); err != nil {
+			return nil, err
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
 func (r *Reader) Close() {
 	r.store.Close()
 }
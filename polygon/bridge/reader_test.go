@@ -0,0 +1,104 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/polygon/heimdall"
+	"github.com/erigontech/erigon/turbo/testlog"
+)
+
+func TestReader_EventsByIdRange(t *testing.T) {
+	ctx := context.Background()
+	logger := testlog.Logger(t, log.LvlDebug)
+	store := NewMdbxStore(t.TempDir(), logger, false, 1)
+	require.NoError(t, store.Prepare(ctx))
+	t.Cleanup(store.Close)
+	reader := NewReader(store, logger, libcommon.HexToAddress("0x1001"))
+
+	events := make([]*heimdall.EventRecordWithTime, 0, 5)
+	for id := uint64(1); id <= 5; id++ {
+		events = append(events, &heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: id, ChainID: "80002", Data: []byte{byte(id)}},
+			Time:        time.Unix(int64(id), 0),
+		})
+	}
+	require.NoError(t, store.PutEvents(ctx, events))
+
+	page, err := reader.EventsByIdRange(ctx, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, events[1:3], page)
+
+	// the last page is shorter
+	page, err = reader.EventsByIdRange(ctx, 4, 10)
+	require.NoError(t, err)
+	require.Equal(t, events[3:], page)
+
+	page, err = reader.EventsByIdRange(ctx, 6, 10)
+	require.NoError(t, err)
+	require.Empty(t, page)
+
+	// fromId+limit overflows
+	page, err = reader.EventsByIdRange(ctx, 3, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, events[2:], page)
+}
+
+func TestReader_Events(t *testing.T) {
+	ctx := context.Background()
+	logger := testlog.Logger(t, log.LvlDebug)
+	store := NewMdbxStore(t.TempDir(), logger, false, 1)
+	require.NoError(t, store.Prepare(ctx))
+	t.Cleanup(store.Close)
+	reader := NewReader(store, logger, libcommon.HexToAddress("0x1001"))
+
+	var events []*heimdall.EventRecordWithTime
+	for _, id := range []uint64{1, 2, 3, 4, 5, 7} { // event 6 is missing
+		events = append(events, &heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: id, ChainID: "80002", Data: []byte{byte(id)}},
+			Time:        time.Unix(int64(id), 0),
+		})
+	}
+	require.NoError(t, store.PutEvents(ctx, events))
+	require.NoError(t, store.PutBlockNumToEventId(ctx, map[uint64]uint64{16: 3, 32: 5, 48: 7}))
+
+	msgs, err := reader.Events(ctx, 16)
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+
+	msgs, err = reader.Events(ctx, 32)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	var event heimdall.EventRecordWithTime
+	require.NoError(t, event.UnmarshallBytes(msgs[0].Data()))
+	require.Equal(t, uint64(4), event.ID)
+
+	msgs, err = reader.Events(ctx, 24)
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+
+	_, err = reader.Events(ctx, 48)
+	require.ErrorIs(t, err, ErrBlockEventsMismatch)
+}
@@ -448,6 +448,10 @@ func (s *Service) EventTxnLookup(ctx context.Context, borTxHash libcommon.Hash)
 	return s.reader.EventTxnLookup(ctx, borTxHash)
 }
 
+func (s *Service) EventsByIdRange(ctx context.Context, fromId uint64, limit uint64) ([]*heimdall.EventRecordWithTime, error) {
+	return s.reader.EventsByIdRange(ctx, fromId, limit)
+}
+
 func (s *Service) blockEventsTimeWindowEnd(last ProcessedBlockInfo, blockNum uint64, blockTime uint64) (uint64, error) {
 	if s.borConfig.IsIndore(blockNum) {
 		stateSyncDelay := s.borConfig.CalculateStateSyncDelay(blockNum)
@@ -310,6 +310,30 @@ func (s *SnapshotStore) Events(ctx context.Context, start, end uint64) ([][]byte
 	return result, nil
 }
 
+// PruneEvents deletes the events of blocks [1, blocksTo) from the db. The events which are not frozen yet are
+// kept regardless of blocksTo, so that the db and the snapshots together always have all the events.
+func (s *SnapshotStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDeleteLimit int) (deleted int, err error) {
+	if s.snapshots == nil {
+		return s.Store.PruneEvents(ctx, blocksTo, blocksDeleteLimit)
+	}
+
+	frozenBlocks := s.snapshots.VisibleBlocksAvailable(heimdall.Events.Enum())
+	if frozenBlocks == 0 {
+		return 0, nil
+	}
+
+	return s.Store.PruneEvents(ctx, min(blocksTo, frozenBlocks+1), blocksDeleteLimit)
+}
+
+func firstEventId(sn *snapshotsync.VisibleSegment) (uint64, bool) {
+	gg := sn.Src().MakeGetter()
+	if !gg.HasNext() {
+		return 0, false
+	}
+	buf, _ := gg.Next(nil)
+	return binary.BigEndian.Uint64(buf[length.Hash+length.BlockNum : length.Hash+length.BlockNum+8]), true
+}
+
 func (s *SnapshotStore) borBlockByEventHash(txnHash libcommon.Hash, segments []*snapshotsync.VisibleSegment, buf []byte) (blockNum uint64, ok bool, err error) {
 	for i := len(segments) - 1; i >= 0; i-- {
 		sn := segments[i]
@@ -377,7 +401,14 @@ func (s *SnapshotStore) EventsByIdFromSnapshot(from uint64, to time.Time, limit
 	var result []*heimdall.EventRecordWithTime
 	maxTime := false
 
-	for _, sn := range segments {
+	for i, sn := range segments {
+		// all events of the segment are below from if the next segment doesn't start after it
+		if i+1 < len(segments) {
+			if nextFirstEventId, ok := firstEventId(segments[i+1]); ok && nextFirstEventId <= from {
+				continue
+			}
+		}
+
 		idxBorTxnHash := sn.Src().Index()
 
 		if idxBorTxnHash == nil || idxBorTxnHash.KeyCount() == 0 {
@@ -252,7 +252,7 @@ func (c *HttpClient) FetchSpans(ctx context.Context, page uint64, limit uint64)
 		return nil, err
 	}
 
-	ctx = withRequestType(ctx, checkpointListRequest)
+	ctx = withRequestType(ctx, spanListRequest)
 
 	response, err := FetchWithRetry[SpanListResponse](ctx, c, url, c.logger)
 	if err != nil {
@@ -481,9 +481,13 @@ func FetchWithRetryEx[T any](
 
 	for attempt < client.maxRetries {
 		attempt++
+		if attempt > 1 {
+			observeRetry(ctx)
+		}
 
 		request := &HttpRequest{handler: client.handler, url: url, start: time.Now()}
 		result, err = Fetch[T](ctx, request, logger)
+		observeRequest(ctx, request.start, attempt, err)
 		if err == nil {
 			return result, nil
 		}
@@ -18,6 +18,8 @@ package heimdall
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"testing"
@@ -27,6 +29,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
 
 	"github.com/erigontech/erigon/turbo/testlog"
 )
@@ -66,6 +69,47 @@ func TestHeimdallClientFetchesTerminateUponTooManyErrors(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestHeimdallClientMetrics(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	requestHandler := NewMockhttpRequestHandler(ctrl)
+	requestHandler.EXPECT().
+		Do(gomock.Any()).
+		Return(&http.Response{
+			StatusCode: 404,
+			Body:       emptyBodyReadCloser{},
+		}, nil).
+		Times(3)
+	logger := testlog.Logger(t, log.LvlDebug)
+	heimdallClient := NewHttpClient(
+		"https://dummyheimdal.com",
+		logger,
+		WithHttpRequestHandler(requestHandler),
+		WithHttpRetryBackOff(10*time.Millisecond),
+		WithHttpMaxRetries(3),
+	)
+
+	counter := func(format string, args ...any) func() float64 {
+		c := metrics.GetOrCreateCounter(fmt.Sprintf(format, args...))
+		return c.GetValue
+	}
+	requests := counter(endpointRequestsMetric, spanListRequest)
+	retries := counter(endpointRetriesMetric, spanListRequest)
+	errs := counter(endpointErrorsMetric, spanListRequest, "not-successful")
+	requestsBefore, retriesBefore, errsBefore := requests(), retries(), errs()
+
+	_, err := heimdallClient.FetchSpans(ctx, 1, 10)
+	require.ErrorIs(t, err, ErrNotSuccessfulResponse)
+	require.Equal(t, float64(3), requests()-requestsBefore)
+	require.Equal(t, float64(2), retries()-retriesBefore)
+	require.Equal(t, float64(3), errs()-errsBefore)
+
+	require.Equal(t, "timeout", errorClass(fmt.Errorf("fetch: %w", context.DeadlineExceeded)))
+	require.Equal(t, "no-response", errorClass(ErrNoResponse))
+	require.Equal(t, "decode", errorClass(json.Unmarshal([]byte("{"), &SpanResponse{})))
+	require.Equal(t, "transport", errorClass(io.ErrUnexpectedEOF))
+}
+
 func TestHeimdallClientStateSyncEventsReturnsErrNoResponseWhenHttp200WithEmptyBody(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
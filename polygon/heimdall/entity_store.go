@@ -32,12 +32,13 @@ import (
 )
 
 var databaseTablesCfg = kv.TableCfg{
-	kv.BorCheckpoints:        {},
-	kv.BorCheckpointEnds:     {},
-	kv.BorMilestones:         {},
-	kv.BorMilestoneEnds:      {},
-	kv.BorSpans:              {},
-	kv.BorProducerSelections: {},
+	kv.BorCheckpoints:              {},
+	kv.BorCheckpointEnds:           {},
+	kv.BorMilestones:               {},
+	kv.BorMilestoneEnds:            {},
+	kv.BorSpans:                    {},
+	kv.BorProducerSelections:       {},
+	kv.BorSprintProducerSelections: {},
 }
 
 //go:generate mockgen -typed=true -source=./entity_store.go -destination=./entity_store_mock.go -package=heimdall
@@ -18,8 +18,12 @@ package heimdall
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/erigontech/erigon-lib/diagnostics"
 	"github.com/erigontech/erigon-lib/metrics"
 )
 
@@ -36,6 +40,7 @@ type (
 const (
 	stateSyncRequest          requestType = "state-sync"
 	spanRequest               requestType = "span"
+	spanListRequest           requestType = "span-list"
 	checkpointRequest         requestType = "checkpoint"
 	checkpointCountRequest    requestType = "checkpoint-count"
 	checkpointListRequest     requestType = "checkpoint-list"
@@ -113,3 +118,71 @@ func UpdateObservedWaypointCheckpointLength(length uint64) {
 func UpdateObservedWaypointMilestoneLength(length uint64) {
 	waypointMilestoneLength.SetUint64(length)
 }
+
+// metrics of every http request to heimdall, labeled by the endpoint (request type)
+const (
+	endpointRequestsMetric = `heimdall_client_requests{endpoint="%s"}`
+	endpointLatencyMetric  = `heimdall_client_request_duration_seconds{endpoint="%s"}`
+	endpointErrorsMetric   = `heimdall_client_errors{endpoint="%s",class="%s"}`
+	endpointRetriesMetric  = `heimdall_client_retries{endpoint="%s"}`
+
+	unknownRequest requestType = "unknown"
+)
+
+func endpoint(ctx context.Context) requestType {
+	if reqType, ok := getRequestType(ctx); ok {
+		return reqType
+	}
+	return unknownRequest
+}
+
+// errorClass groups the errors of the requests to heimdall for the metrics and diagnostics
+func errorClass(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrServiceUnavailable):
+		return "service-unavailable"
+	case errors.Is(err, ErrBadGateway):
+		return "bad-gateway"
+	case errors.Is(err, ErrNotSuccessfulResponse):
+		return "not-successful"
+	case errors.Is(err, ErrNoResponse):
+		return "no-response"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "decode"
+	default:
+		return "transport"
+	}
+}
+
+// observeRequest records a single attempt of a request, failures are also sent to the diagnostics
+func observeRequest(ctx context.Context, start time.Time, attempt int, err error) {
+	reqType := endpoint(ctx)
+	metrics.GetOrCreateCounter(fmt.Sprintf(endpointRequestsMetric, reqType)).Inc()
+	metrics.GetOrCreateHistogram(fmt.Sprintf(endpointLatencyMetric, reqType)).ObserveDuration(start)
+	if err == nil {
+		return
+	}
+
+	class := errorClass(err)
+	metrics.GetOrCreateCounter(fmt.Sprintf(endpointErrorsMetric, reqType, class)).Inc()
+	if class == "canceled" {
+		return
+	}
+	diagnostics.Send(diagnostics.HeimdallFetchFailure{
+		Timestamp: time.Now(),
+		Endpoint:  string(reqType),
+		Class:     class,
+		Attempt:   attempt,
+		Error:     err.Error(),
+	})
+}
+
+func observeRetry(ctx context.Context) {
+	metrics.GetOrCreateCounter(fmt.Sprintf(endpointRetriesMetric, endpoint(ctx))).Inc()
+}
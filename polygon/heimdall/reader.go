@@ -43,7 +43,7 @@ func NewReader(borConfig *borcfg.BorConfig, store Store, logger log.Logger) *Rea
 	return &Reader{
 		logger:                    logger,
 		store:                     store,
-		spanBlockProducersTracker: newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections()),
+		spanBlockProducersTracker: newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections(), store.SprintBlockProducerSelections()),
 	}
 }
 
@@ -25,6 +25,7 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/erigontech/erigon-lib/app"
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/polygon/bor/borcfg"
@@ -99,7 +100,7 @@ func NewService(config ServiceConfig) *Service {
 		checkpointScraper:         checkpointScraper,
 		milestoneScraper:          milestoneScraper,
 		spanScraper:               spanScraper,
-		spanBlockProducersTracker: newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections()),
+		spanBlockProducersTracker: newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections(), store.SprintBlockProducerSelections()),
 	}
 }
 
@@ -319,6 +320,12 @@ func (s *Service) Run(ctx context.Context) error {
 
 	milestoneObserver := s.RegisterMilestoneObserver(func(milestone *Milestone) {
 		UpdateObservedWaypointMilestoneLength(milestone.Length())
+		app.PublishEvent(app.MilestoneReceived{
+			ID:         uint64(milestone.Id),
+			StartBlock: milestone.StartBlock().Uint64(),
+			EndBlock:   milestone.EndBlock().Uint64(),
+			RootHash:   milestone.RootHash(),
+		})
 	})
 	defer milestoneObserver()
 
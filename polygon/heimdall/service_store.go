@@ -32,12 +32,13 @@ type Store interface {
 	Milestones() EntityStore[*Milestone]
 	Spans() EntityStore[*Span]
 	SpanBlockProducerSelections() EntityStore[*SpanBlockProducerSelection]
+	SprintBlockProducerSelections() EntityStore[*SprintBlockProducerSelection]
 	Prepare(ctx context.Context) error
 	Close()
 }
 
 func NewMdbxStore(logger log.Logger, dataDir string, roTxLimit int64) *MdbxStore {
-	return newMdbxStore(polygoncommon.NewDatabase(dataDir, kv.HeimdallDB, databaseTablesCfg, logger, false, roTxLimit))
+	return newMdbxStore(polygoncommon.NewDatabase(dataDir, kv.HeimdallDB, databaseTablesCfg, databaseMigrations, logger, false, roTxLimit))
 }
 
 func newMdbxStore(db *polygoncommon.Database) *MdbxStore {
@@ -46,6 +47,13 @@ func newMdbxStore(db *polygoncommon.Database) *MdbxStore {
 			return uint64(SpanIdAt(blockNum)), true, nil
 		})
 
+	// sprint selections are keyed by the first block of the sprint, the ones after a block num are found without
+	// knowing the sprint lengths
+	sprintIndex := RangeIndexFunc(
+		func(ctx context.Context, blockNum uint64) (uint64, bool, error) {
+			return blockNum, true, nil
+		})
+
 	return &MdbxStore{
 		db: db,
 		checkpoints: newMdbxEntityStore(
@@ -58,6 +66,8 @@ func newMdbxStore(db *polygoncommon.Database) *MdbxStore {
 			db, kv.BorSpans, Spans, generics.New[Span], spanIndex),
 		spanBlockProducerSelections: newMdbxEntityStore(
 			db, kv.BorProducerSelections, nil, generics.New[SpanBlockProducerSelection], spanIndex),
+		sprintBlockProducerSelections: newMdbxEntityStore(
+			db, kv.BorSprintProducerSelections, nil, generics.New[SprintBlockProducerSelection], sprintIndex),
 	}
 }
 
@@ -66,11 +76,12 @@ func NewDbStore(db kv.RoDB) *MdbxStore {
 }
 
 type MdbxStore struct {
-	db                          *polygoncommon.Database
-	checkpoints                 EntityStore[*Checkpoint]
-	milestones                  EntityStore[*Milestone]
-	spans                       EntityStore[*Span]
-	spanBlockProducerSelections EntityStore[*SpanBlockProducerSelection]
+	db                            *polygoncommon.Database
+	checkpoints                   EntityStore[*Checkpoint]
+	milestones                    EntityStore[*Milestone]
+	spans                         EntityStore[*Span]
+	spanBlockProducerSelections   EntityStore[*SpanBlockProducerSelection]
+	sprintBlockProducerSelections EntityStore[*SprintBlockProducerSelection]
 }
 
 func (s *MdbxStore) Checkpoints() EntityStore[*Checkpoint] {
@@ -89,12 +100,17 @@ func (s *MdbxStore) SpanBlockProducerSelections() EntityStore[*SpanBlockProducer
 	return s.spanBlockProducerSelections
 }
 
+func (s *MdbxStore) SprintBlockProducerSelections() EntityStore[*SprintBlockProducerSelection] {
+	return s.sprintBlockProducerSelections
+}
+
 func (s *MdbxStore) Prepare(ctx context.Context) error {
 	eg, ctx := errgroup.WithContext(ctx)
 	eg.Go(func() error { return s.checkpoints.Prepare(ctx) })
 	eg.Go(func() error { return s.milestones.Prepare(ctx) })
 	eg.Go(func() error { return s.spans.Prepare(ctx) })
 	eg.Go(func() error { return s.spanBlockProducerSelections.Prepare(ctx) })
+	eg.Go(func() error { return s.sprintBlockProducerSelections.Prepare(ctx) })
 	return eg.Wait()
 }
 
@@ -104,4 +120,5 @@ func (s *MdbxStore) Close() {
 	s.milestones.Close()
 	s.spans.Close()
 	s.spanBlockProducerSelections.Close()
+	s.sprintBlockProducerSelections.Close()
 }
@@ -18,20 +18,22 @@ import (
 
 func NewSnapshotStore(base Store, snapshots *RoSnapshots) *SnapshotStore {
 	return &SnapshotStore{
-		Store:                       base,
-		checkpoints:                 &checkpointSnapshotStore{base.Checkpoints(), snapshots},
-		milestones:                  &milestoneSnapshotStore{base.Milestones(), snapshots},
-		spans:                       NewSpanSnapshotStore(base.Spans(), snapshots),
-		spanBlockProducerSelections: base.SpanBlockProducerSelections(),
+		Store:                         base,
+		checkpoints:                   &checkpointSnapshotStore{base.Checkpoints(), snapshots},
+		milestones:                    &milestoneSnapshotStore{base.Milestones(), snapshots},
+		spans:                         NewSpanSnapshotStore(base.Spans(), snapshots),
+		spanBlockProducerSelections:   base.SpanBlockProducerSelections(),
+		sprintBlockProducerSelections: base.SprintBlockProducerSelections(),
 	}
 }
 
 type SnapshotStore struct {
 	Store
-	checkpoints                 EntityStore[*Checkpoint]
-	milestones                  EntityStore[*Milestone]
-	spans                       EntityStore[*Span]
-	spanBlockProducerSelections EntityStore[*SpanBlockProducerSelection]
+	checkpoints                   EntityStore[*Checkpoint]
+	milestones                    EntityStore[*Milestone]
+	spans                         EntityStore[*Span]
+	spanBlockProducerSelections   EntityStore[*SpanBlockProducerSelection]
+	sprintBlockProducerSelections EntityStore[*SprintBlockProducerSelection]
 }
 
 func (s *SnapshotStore) Checkpoints() EntityStore[*Checkpoint] {
@@ -50,12 +52,17 @@ func (s *SnapshotStore) SpanBlockProducerSelections() EntityStore[*SpanBlockProd
 	return s.spanBlockProducerSelections
 }
 
+func (s *SnapshotStore) SprintBlockProducerSelections() EntityStore[*SprintBlockProducerSelection] {
+	return s.sprintBlockProducerSelections
+}
+
 func (s *SnapshotStore) Prepare(ctx context.Context) error {
 	eg, ctx := errgroup.WithContext(ctx)
 	eg.Go(func() error { return s.checkpoints.Prepare(ctx) })
 	eg.Go(func() error { return s.milestones.Prepare(ctx) })
 	eg.Go(func() error { return s.spans.Prepare(ctx) })
 	eg.Go(func() error { return s.spanBlockProducerSelections.Prepare(ctx) })
+	eg.Go(func() error { return s.sprintBlockProducerSelections.Prepare(ctx) })
 	return eg.Wait()
 }
 
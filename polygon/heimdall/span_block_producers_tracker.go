@@ -30,12 +30,19 @@ import (
 	"github.com/erigontech/erigon/polygon/bor/valset"
 )
 
+// sprintCheckpointInterval is the number of blocks between the sprints whose producer selections are persisted when a
+// span is observed, a lookup only re-applies the priority increments from the closest one.
+const sprintCheckpointInterval = 1024
+
+// newSpanBlockProducersTracker creates a tracker of the producer selections of the spans in store. The selections of
+// checkpoint sprints are persisted in sprintStore, which is optional.
 func newSpanBlockProducersTracker(
 	logger log.Logger,
 	borConfig *borcfg.BorConfig,
 	store EntityStore[*SpanBlockProducerSelection],
+	sprintStore EntityStore[*SprintBlockProducerSelection],
 ) *spanBlockProducersTracker {
-	recentSelectionsLru, err := lru.New[uint64, SpanBlockProducerSelection](1024)
+	recentSelectionsLru, err := lru.New[uint64, SprintBlockProducerSelection](1024)
 	if err != nil {
 		panic(err)
 	}
@@ -44,6 +51,7 @@ func newSpanBlockProducersTracker(
 		logger:           logger,
 		borConfig:        borConfig,
 		store:            store,
+		sprintStore:      sprintStore,
 		recentSelections: recentSelectionsLru,
 		newSpans:         make(chan *Span),
 		idleSignal:       make(chan struct{}),
@@ -54,7 +62,8 @@ type spanBlockProducersTracker struct {
 	logger           log.Logger
 	borConfig        *borcfg.BorConfig
 	store            EntityStore[*SpanBlockProducerSelection]
-	recentSelections *lru.Cache[uint64, SpanBlockProducerSelection] // sprint number -> SpanBlockProducerSelection
+	sprintStore      EntityStore[*SprintBlockProducerSelection]
+	recentSelections *lru.Cache[uint64, SprintBlockProducerSelection] // sprint number -> SprintBlockProducerSelection
 	newSpans         chan *Span
 	queued           atomic.Int32
 	idleSignal       chan struct{}
@@ -131,7 +140,7 @@ func (t *spanBlockProducersTracker) ObserveSpan(ctx context.Context, newSpan *Sp
 			return err
 		}
 
-		return nil
+		return t.putSprintCheckpoints(ctx, newProducerSelection)
 	}
 
 	if newSpan.Id > lastProducerSelection.SpanId+1 {
@@ -176,6 +185,47 @@ func (t *spanBlockProducersTracker) ObserveSpan(ctx context.Context, newSpan *Sp
 		return err
 	}
 
+	return t.putSprintCheckpoints(ctx, newProducerSelection)
+}
+
+// putSprintCheckpoints persists the producer selections of the sprints of the span starting at the multiples of
+// sprintCheckpointInterval.
+func (t *spanBlockProducersTracker) putSprintCheckpoints(ctx context.Context, spanSelection *SpanBlockProducerSelection) error {
+	if t.sprintStore == nil {
+		return nil
+	}
+
+	producers := spanSelection.Producers.Copy()
+	producers.UpdateValidatorMap()
+	err := producers.UpdateTotalVotingPower()
+	if err != nil {
+		return err
+	}
+
+	sprintNum := t.borConfig.CalculateSprintNumber(spanSelection.StartBlock)
+	checkpoint := spanSelection.StartBlock - spanSelection.StartBlock%sprintCheckpointInterval + sprintCheckpointInterval
+	for ; checkpoint <= spanSelection.EndBlock; checkpoint += sprintCheckpointInterval {
+		if !t.borConfig.IsSprintStart(checkpoint) {
+			continue
+		}
+
+		checkpointSprintNum := t.borConfig.CalculateSprintNumber(checkpoint)
+		for ; sprintNum < checkpointSprintNum; sprintNum++ {
+			producers = valset.GetUpdatedValidatorSet(producers, producers.Validators, t.logger)
+			producers.IncrementProposerPriority(1)
+		}
+
+		selection := &SprintBlockProducerSelection{
+			SpanId:     spanSelection.SpanId,
+			StartBlock: checkpoint,
+			EndBlock:   checkpoint + t.borConfig.CalculateSprintLength(checkpoint) - 1,
+			Producers:  producers,
+		}
+		if err := t.sprintStore.PutEntity(ctx, checkpoint, selection); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -205,19 +255,59 @@ func (t *spanBlockProducersTracker) producers(ctx context.Context, blockNum uint
 		return selection.Producers.Copy(), 0, nil
 	}
 
-	// have we previously calculated the producers for the previous sprint num of the same span (chain tip optimisation)
+	producers, increments, err := t.calculateProducers(ctx, blockNum, currentSprintNum)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sprintLength := t.borConfig.CalculateSprintLength(blockNum)
+	sprintStartBlock := blockNum - blockNum%sprintLength
+	t.recentSelections.Add(currentSprintNum, SprintBlockProducerSelection{
+		SpanId:     SpanIdAt(blockNum),
+		StartBlock: sprintStartBlock,
+		EndBlock:   sprintStartBlock + sprintLength - 1,
+		Producers:  producers,
+	})
+	return producers.Copy(), increments, nil
+}
+
+// calculateProducers increments the proposer priorities of the producers of the span of blockNum up to its sprint,
+// from the closest earlier sprint of the span with a recent selection, else from the closest persisted checkpoint of
+// the span, or else from the start of the span
+func (t *spanBlockProducersTracker) calculateProducers(ctx context.Context, blockNum uint64, currentSprintNum uint64) (*valset.ValidatorSet, int, error) {
+	// have we previously calculated the producers for an earlier sprint num of the same span (chain tip and historical
+	// lookups optimisation), if so only increment the priorities from there instead of from the start of the span
 	spanId := SpanIdAt(blockNum)
-	var prevSprintNum uint64
-	if currentSprintNum > 0 {
-		prevSprintNum = currentSprintNum - 1
+	if selection, sprintNum, ok := t.recentSelectionInSpan(spanId, currentSprintNum); ok {
+		increments := int(currentSprintNum - sprintNum)
+		producers := selection.Producers.Copy()
+		for i := 0; i < increments; i++ {
+			producers.IncrementProposerPriority(1)
+		}
+		return producers, increments, nil
+	}
+
+	// have we persisted the producers of a checkpoint sprint of the same span (historical lookups and restarts
+	// optimisation), if so re-calculate from there
+	checkpoint, ok, err := t.sprintCheckpoint(ctx, spanId, blockNum)
+	if err != nil {
+		return nil, 0, err
 	}
-	if selection, ok := t.recentSelections.Get(prevSprintNum); ok && spanId == selection.SpanId {
-		producersCopy := selection.Producers.Copy()
-		producersCopy.IncrementProposerPriority(1)
-		selectionCopy := selection
-		selectionCopy.Producers = producersCopy
-		t.recentSelections.Add(currentSprintNum, selectionCopy)
-		return producersCopy, 1, nil
+	if ok {
+		producers := checkpoint.Producers
+		producers.UpdateValidatorMap()
+		err = producers.UpdateTotalVotingPower()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		increments := int(currentSprintNum - t.borConfig.CalculateSprintNumber(checkpoint.StartBlock))
+		for i := 0; i < increments; i++ {
+			producers = valset.GetUpdatedValidatorSet(producers, producers.Validators, t.logger)
+			producers.IncrementProposerPriority(1)
+		}
+
+		return producers, increments, nil
 	}
 
 	// no recent selection that we can easily use, re-calculate from DB
@@ -243,6 +333,38 @@ func (t *spanBlockProducersTracker) producers(ctx context.Context, blockNum uint
 		producers.IncrementProposerPriority(1)
 	}
 
-	t.recentSelections.Add(currentSprintNum, *producerSelection)
 	return producers, increments, nil
 }
+
+// sprintCheckpoint returns the persisted selection of the closest checkpoint sprint of the span at or before blockNum.
+func (t *spanBlockProducersTracker) sprintCheckpoint(ctx context.Context, spanId SpanId, blockNum uint64) (*SprintBlockProducerSelection, bool, error) {
+	if t.sprintStore == nil {
+		return nil, false, nil
+	}
+
+	selection, ok, err := t.sprintStore.Entity(ctx, blockNum-blockNum%sprintCheckpointInterval)
+	if err != nil || !ok || selection.SpanId != spanId {
+		return nil, false, err
+	}
+
+	return selection, true, nil
+}
+
+// recentSelectionInSpan returns the most recent selection cached for a sprint of the span before sprintNum, along with
+// its sprint number.
+func (t *spanBlockProducersTracker) recentSelectionInSpan(spanId SpanId, sprintNum uint64) (SprintBlockProducerSelection, uint64, bool) {
+	var spanStartSprintNum uint64
+	if spanId > 0 {
+		spanStartSprintNum = t.borConfig.CalculateSprintNumber(SpanEndBlockNum(spanId-1) + 1)
+	}
+	for sprintNum > spanStartSprintNum {
+		sprintNum--
+		if selection, ok := t.recentSelections.Peek(sprintNum); ok {
+			if selection.SpanId != spanId {
+				break
+			}
+			return selection, sprintNum, true
+		}
+	}
+	return SprintBlockProducerSelection{}, 0, false
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package heimdall
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/polygon/bor/borcfg"
+	"github.com/erigontech/erigon/polygon/bor/valset"
+)
+
+func TestSpanBlockProducersTrackerSprintSelections(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New()
+	borConfig := &borcfg.BorConfig{Sprint: map[string]uint64{"0": 16}}
+	dataDir := t.TempDir()
+
+	validators := []*valset.Validator{
+		valset.NewValidator(libcommon.Address{1}, 10),
+		valset.NewValidator(libcommon.Address{2}, 20),
+		valset.NewValidator(libcommon.Address{3}, 30),
+	}
+	span0 := &Span{Id: 0, StartBlock: 0, EndBlock: SpanEndBlockNum(0), ValidatorSet: *valset.NewValidatorSet(validators)}
+	span1 := &Span{Id: 1, StartBlock: SpanEndBlockNum(0) + 1, EndBlock: SpanEndBlockNum(1)}
+	for _, v := range validators[1:] {
+		span1.SelectedProducers = append(span1.SelectedProducers, *v.Copy())
+	}
+
+	store := NewMdbxStore(logger, dataDir, 1)
+	require.NoError(t, store.Prepare(ctx))
+	tracker := newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections(), store.SprintBlockProducerSelections())
+	require.NoError(t, tracker.ObserveSpan(ctx, span0))
+	require.NoError(t, tracker.ObserveSpan(ctx, span1))
+
+	defer store.Close()
+
+	// the checkpoints of span 1 are persisted when it's observed
+	checkpoints, err := store.SprintBlockProducerSelections().RangeFromBlockNum(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 6)
+	for i, checkpoint := range checkpoints {
+		require.Equal(t, SpanId(1), checkpoint.SpanId)
+		require.Equal(t, uint64(i+1)*sprintCheckpointInterval, checkpoint.StartBlock)
+		require.Equal(t, checkpoint.StartBlock+15, checkpoint.EndBlock)
+	}
+
+	// the 6th sprint after the 2nd checkpoint
+	blockNum := uint64(2*sprintCheckpointInterval + 5*16 + 3)
+	fromSpanStart, increments, err := newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections(), nil).producers(ctx, blockNum)
+	require.NoError(t, err)
+	require.Equal(t, int(blockNum-span1.StartBlock)/16, increments)
+
+	// a lookup, e.g. after a restart, is incremented from the closest checkpoint and doesn't persist anything
+	tracker = newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections(), store.SprintBlockProducerSelections())
+	producers, increments, err := tracker.producers(ctx, blockNum)
+	require.NoError(t, err)
+	require.Equal(t, 5, increments)
+	requireSameProducers(t, fromSpanStart, producers)
+	checkpoints, err = store.SprintBlockProducerSelections().RangeFromBlockNum(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 6)
+
+	// before the first checkpoint of the span it is incremented from the start of the span
+	_, increments, err = tracker.producers(ctx, span1.StartBlock+2*16)
+	require.NoError(t, err)
+	require.Equal(t, 2, increments)
+
+	// the checkpoints after an unwind point are deleted
+	deleted, err := store.SprintBlockProducerSelections().DeleteFromBlockNum(ctx, 2*sprintCheckpointInterval+15)
+	require.NoError(t, err)
+	require.Equal(t, 4, deleted)
+	_, ok, err := store.SprintBlockProducerSelections().Entity(ctx, 2*sprintCheckpointInterval)
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, err = store.SprintBlockProducerSelections().Entity(ctx, 3*sprintCheckpointInterval)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func requireSameProducers(t *testing.T, want, have *valset.ValidatorSet) {
+	t.Helper()
+	require.Equal(t, want.Validators, have.Validators)
+	require.Equal(t, want.Proposer, have.Proposer)
+}
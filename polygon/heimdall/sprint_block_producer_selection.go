@@ -0,0 +1,56 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package heimdall
+
+import (
+	"github.com/erigontech/erigon/polygon/bor/valset"
+)
+
+// SprintBlockProducerSelection represents the block producer selection at a sprint: the producers of the span the
+// sprint belongs to, with their ProposerPriority incremented once for every sprint of the span up to this one.
+//
+// It is derived from the SpanBlockProducerSelection of its span when the span is observed, for the sprints starting at
+// the multiples of sprintCheckpointInterval, so that looking up the producers of a block only re-applies the increments
+// from the closest of these checkpoints instead of from the start of its span, neither after a restart nor for
+// historical blocks. Its id is the number of the first block of the sprint.
+type SprintBlockProducerSelection struct {
+	SpanId     SpanId
+	StartBlock uint64
+	EndBlock   uint64
+	Producers  *valset.ValidatorSet
+}
+
+var _ Entity = (*SprintBlockProducerSelection)(nil)
+
+func (s *SprintBlockProducerSelection) RawId() uint64 {
+	return s.StartBlock
+}
+
+func (s *SprintBlockProducerSelection) BlockNumRange() ClosedRange {
+	return ClosedRange{
+		Start: s.StartBlock,
+		End:   s.EndBlock,
+	}
+}
+
+func (s *SprintBlockProducerSelection) SetRawId(id uint64) {
+	s.StartBlock = id
+}
+
+func (s *SprintBlockProducerSelection) CmpRange(n uint64) int {
+	return cmpBlockRange(s.StartBlock, s.EndBlock, n)
+}
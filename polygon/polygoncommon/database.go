@@ -31,24 +31,26 @@ import (
 )
 
 type Database struct {
-	db        kv.RoDB
-	dataDir   string
-	label     kv.Label
-	tableCfg  kv.TableCfg
-	openOnce  sync.Once
-	logger    log.Logger
-	accede    bool
-	roTxLimit int64
+	db         kv.RoDB
+	dataDir    string
+	label      kv.Label
+	tableCfg   kv.TableCfg
+	openOnce   sync.Once
+	logger     log.Logger
+	accede     bool
+	roTxLimit  int64
+	migrations []Migration
 }
 
-func NewDatabase(dataDir string, label kv.Label, tableCfg kv.TableCfg, logger log.Logger, accede bool, roTxLimit int64) *Database {
+func NewDatabase(dataDir string, label kv.Label, tableCfg kv.TableCfg, migrations []Migration, logger log.Logger, accede bool, roTxLimit int64) *Database {
 	return &Database{
-		dataDir:   dataDir,
-		label:     label,
-		tableCfg:  tableCfg,
-		logger:    logger,
-		accede:    accede,
-		roTxLimit: roTxLimit,
+		dataDir:    dataDir,
+		label:      label,
+		tableCfg:   tableCfg,
+		logger:     logger,
+		accede:     accede,
+		roTxLimit:  roTxLimit,
+		migrations: migrations,
 	}
 }
 
@@ -66,16 +68,29 @@ func (db *Database) open(ctx context.Context) error {
 		txLimiter = semaphore.NewWeighted(db.roTxLimit)
 	}
 
+	tableCfg := kv.TableCfg{kv.Migrations: {}}
+	for table, cfg := range db.tableCfg {
+		tableCfg[table] = cfg
+	}
+
 	var err error
 	db.db, err = mdbx.New(db.label, db.logger).
 		Path(dbPath).
-		WithTableCfg(func(_ kv.TableCfg) kv.TableCfg { return db.tableCfg }).
+		WithTableCfg(func(_ kv.TableCfg) kv.TableCfg { return tableCfg }).
 		MapSize(16 * datasize.GB).
 		GrowthStep(16 * datasize.MB).
 		RoTxsLimiter(txLimiter).
 		Accede(db.accede).
 		Open(ctx)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := db.applyMigrations(ctx); err != nil {
+		db.db.Close()
+		db.db = nil
+		return err
+	}
+	return nil
 }
 
 func (db *Database) OpenOnce(ctx context.Context) error {
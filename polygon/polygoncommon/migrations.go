@@ -0,0 +1,96 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package polygoncommon
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+// Migration is a forward-only change of the schema of a polygon database: a table rename, a re-encoding of the keys.
+// Migrations of a database apply in the order of their list, each of them once, in its own transaction; the database
+// records the applied ones so that a format change doesn't silently require a resync. New migrations are appended to
+// the list, the applied ones are never removed from it.
+type Migration struct {
+	Name string
+	Up   func(ctx context.Context, tx kv.RwTx) error
+}
+
+var (
+	ErrMigrationNonUniqueName = errors.New("non unique migration name")
+	ErrUnknownMigration       = errors.New("database is migrated by a newer version: unknown migration")
+	ErrPendingMigrations      = errors.New("database has pending migrations, they are applied by the process which owns the database")
+)
+
+// applyMigrations applies the migrations the database doesn't have yet, only checks that there are none in accede mode
+func (db *Database) applyMigrations(ctx context.Context) error {
+	names := make(map[string]struct{}, len(db.migrations))
+	for _, m := range db.migrations {
+		if _, ok := names[m.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrMigrationNonUniqueName, m.Name)
+		}
+		names[m.Name] = struct{}{}
+	}
+
+	applied := map[string]struct{}{}
+	if err := db.db.View(ctx, func(tx kv.Tx) error {
+		if bm, ok := tx.(kv.BucketMigrator); ok {
+			// in accede mode the table isn't created, the database may be owned by a version without migrations
+			if exists, err := bm.ExistsBucket(kv.Migrations); err != nil || !exists {
+				return err
+			}
+		}
+		return tx.ForEach(kv.Migrations, nil, func(k, _ []byte) error {
+			applied[string(k)] = struct{}{}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	for name := range applied {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("%w %s of %s", ErrUnknownMigration, name, db.label)
+		}
+	}
+
+	for _, m := range db.migrations {
+		if _, ok := applied[m.Name]; ok {
+			continue
+		}
+		if db.accede {
+			return fmt.Errorf("%w: %s of %s", ErrPendingMigrations, m.Name, db.label)
+		}
+		db.logger.Info("Apply migration", "label", db.label, "name", m.Name)
+		if err := db.RwDB().Update(ctx, func(tx kv.RwTx) error {
+			if err := m.Up(ctx, tx); err != nil {
+				return err
+			}
+			var appliedAt [8]byte
+			binary.BigEndian.PutUint64(appliedAt[:], uint64(time.Now().Unix()))
+			return tx.Put(kv.Migrations, []byte(m.Name), appliedAt[:])
+		}); err != nil {
+			return fmt.Errorf("migration %s of %s: %w", m.Name, db.label, err)
+		}
+		db.logger.Info("Applied migration", "label", db.label, "name", m.Name)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package polygoncommon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	tableCfg := kv.TableCfg{kv.BorEvents: {}}
+	var applied int
+	renameKey := Migration{
+		Name: "rename_key",
+		Up: func(ctx context.Context, tx kv.RwTx) error {
+			applied++
+			v, err := tx.GetOne(kv.BorEvents, []byte("old"))
+			if err != nil {
+				return err
+			}
+			if err := tx.Delete(kv.BorEvents, []byte("old")); err != nil {
+				return err
+			}
+			return tx.Put(kv.BorEvents, []byte("new"), v)
+		},
+	}
+	open := func(migrations []Migration, accede bool) (*Database, error) {
+		db := NewDatabase(dataDir, kv.PolygonBridgeDB, tableCfg, migrations, log.New(), accede, 0)
+		return db, db.OpenOnce(ctx)
+	}
+
+	db, err := open(nil, false)
+	require.NoError(t, err)
+	require.NoError(t, db.RwDB().Update(ctx, func(tx kv.RwTx) error {
+		return tx.Put(kv.BorEvents, []byte("old"), []byte("event"))
+	}))
+	db.Close()
+
+	_, err = open([]Migration{renameKey}, true)
+	require.ErrorIs(t, err, ErrPendingMigrations)
+
+	db, err = open([]Migration{renameKey}, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, applied)
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(kv.BorEvents, []byte("new"))
+		require.Equal(t, []byte("event"), v)
+		return err
+	}))
+	db.Close()
+
+	// applied once
+	db, err = open([]Migration{renameKey}, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, applied)
+	db.Close()
+
+	_, err = open(nil, false)
+	require.ErrorIs(t, err, ErrUnknownMigration)
+	_, err = open([]Migration{renameKey, renameKey}, false)
+	require.ErrorIs(t, err, ErrMigrationNonUniqueName)
+}
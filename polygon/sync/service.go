@@ -48,6 +48,7 @@ func NewService(
 	bridgeService *bridge.Service,
 	heimdallService *heimdall.Service,
 	notifications *shards.Notifications,
+	unsafeUnwind bool,
 ) *Service {
 	borConfig := chainConfig.Bor.(*borcfg.BorConfig)
 	checkpointVerifier := VerifyCheckpointHeaders
@@ -88,6 +89,7 @@ func NewService(
 		events.Events(),
 		notifications,
 		NewWiggleCalculator(borConfig, signaturesCache, heimdallService),
+		unsafeUnwind,
 	)
 	return &Service{
 		logger:          logger,
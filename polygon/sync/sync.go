@@ -25,6 +25,7 @@ import (
 	"github.com/hashicorp/golang-lru/v2/simplelru"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/diagnostics"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/polygon/heimdall"
@@ -64,6 +65,7 @@ func NewSync(
 	events <-chan Event,
 	notifications *shards.Notifications,
 	wiggleCalculator wiggleCalculator,
+	unsafeUnwind bool,
 ) *Sync {
 	badBlocksLru, err := simplelru.NewLRU[common.Hash, struct{}](1024, nil)
 	if err != nil {
@@ -85,6 +87,7 @@ func NewSync(
 		badBlocks:         badBlocksLru,
 		notifications:     notifications,
 		wiggleCalculator:  wiggleCalculator,
+		unsafeUnwind:      unsafeUnwind,
 	}
 }
 
@@ -103,6 +106,48 @@ type Sync struct {
 	badBlocks         *simplelru.LRU[common.Hash, struct{}]
 	notifications     *shards.Notifications
 	wiggleCalculator  wiggleCalculator
+	unsafeUnwind      bool
+	// latestMilestone is the latest milestone verified against the local chain, it is final
+	// so the chain is never unwound behind its end block unless unsafeUnwind is set
+	latestMilestone heimdall.Waypoint
+}
+
+var ErrUnwindBehindMilestone = errors.New("unwind behind the latest verified milestone")
+
+// checkUnwind guards the finality of the latest verified milestone: an unwind behind its end block means that
+// either the local chain or the chain served by the peers contradicts heimdall. Such unwinds are refused, unless
+// explicitly allowed with --bor.unsafe-unwind, and are reported as deep reorg alerts through the diagnostics.
+func (s *Sync) checkUnwind(unwindPoint uint64) error {
+	if s.latestMilestone == nil {
+		return nil
+	}
+	milestoneEnd := s.latestMilestone.EndBlock().Uint64()
+	if unwindPoint >= milestoneEnd {
+		return nil
+	}
+
+	diagnostics.Send(diagnostics.DeepReorgAlert{
+		Timestamp:    time.Now(),
+		UnwindPoint:  unwindPoint,
+		FinalizedNum: milestoneEnd,
+		WaypointId:   s.latestMilestone.RawId(),
+		Allowed:      s.unsafeUnwind,
+	})
+
+	if s.unsafeUnwind {
+		s.logger.Warn(
+			syncLogPrefix("unwinding behind the latest verified milestone"),
+			"unwindPoint", unwindPoint,
+			"milestoneId", s.latestMilestone.RawId(),
+			"milestoneEnd", milestoneEnd,
+		)
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: unwindPoint=%d, milestoneId=%d, milestoneEnd=%d",
+		ErrUnwindBehindMilestone, unwindPoint, s.latestMilestone.RawId(), milestoneEnd,
+	)
 }
 
 func (s *Sync) commitExecution(ctx context.Context, newTip *types.Header, finalizedHeader *types.Header) error {
@@ -149,6 +194,10 @@ func (s *Sync) handleMilestoneTipMismatch(ctx context.Context, ccb *CanonicalCha
 		"milestoneRootHash", event.RootHash(),
 	)
 
+	if err := s.checkUnwind(rootNum); err != nil {
+		return err
+	}
+
 	if err := s.bridgeSync.Unwind(ctx, rootNum); err != nil {
 		return err
 	}
@@ -171,6 +220,7 @@ func (s *Sync) handleMilestoneTipMismatch(ctx context.Context, ccb *CanonicalCha
 		return s.handleWaypointExecutionErr(ctx, ccb.Root(), err)
 	}
 
+	s.latestMilestone = event
 	ccb.Reset(newTip)
 	return nil
 }
@@ -194,6 +244,8 @@ func (s *Sync) applyNewMilestoneOnTip(ctx context.Context, event EventNewMilesto
 		return s.handleMilestoneTipMismatch(ctx, ccb, milestone)
 	}
 
+	s.latestMilestone = milestone
+
 	return ccb.PruneRoot(milestone.EndBlock().Uint64())
 }
 
@@ -503,6 +555,10 @@ func (s *Sync) reorganiseBridge(ctx context.Context, ccb *CanonicalChainBuilder,
 		return fmt.Errorf("unexpected newTipNum <= unwindPoint: %d < %d", newTipNum, unwindPoint)
 	}
 
+	if err := s.checkUnwind(unwindPoint); err != nil {
+		return err
+	}
+
 	// 1. Do the unwind from the old tip (on the old canonical fork) to the unwindPoint
 	if err := s.bridgeSync.Unwind(ctx, unwindPoint); err != nil {
 		return err
@@ -539,6 +595,10 @@ func (s *Sync) handleBridgeOnBlocksInsertAheadOfTip(ctx context.Context, tipNum,
 		"lastInsertedNum", lastInsertedNum,
 	)
 
+	if err := s.checkUnwind(tipNum); err != nil {
+		return err
+	}
+
 	// wait for the insert blocks flush
 	if err := s.store.Flush(ctx); err != nil {
 		return err
@@ -648,6 +708,9 @@ func (s *Sync) Run(ctx context.Context) error {
 		return err
 	}
 
+	// the tip has been synced up to the latest milestone
+	s.latestMilestone = result.latestWaypoint
+
 	ccBuilder, err := s.initialiseCcb(ctx, result)
 	if err != nil {
 		return err
@@ -0,0 +1,50 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sync
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/polygon/heimdall"
+	"github.com/erigontech/erigon/turbo/testlog"
+)
+
+func TestSyncCheckUnwind(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlCrit)
+	milestone := &heimdall.Milestone{
+		Id: 7,
+		Fields: heimdall.WaypointFields{
+			StartBlock: big.NewInt(100),
+			EndBlock:   big.NewInt(120),
+		},
+	}
+
+	s := &Sync{logger: logger}
+	require.NoError(t, s.checkUnwind(10)) // nothing verified yet
+
+	s.latestMilestone = milestone
+	require.NoError(t, s.checkUnwind(120))
+	require.NoError(t, s.checkUnwind(130))
+	require.ErrorIs(t, s.checkUnwind(119), ErrUnwindBehindMilestone)
+
+	s.unsafeUnwind = true
+	require.NoError(t, s.checkUnwind(119))
+}
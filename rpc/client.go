@@ -82,6 +82,8 @@ type Client struct {
 	services        *serviceRegistry
 	methodAllowList AllowList
 
+	heavyMethodLimits HeavyMethodLimits // of the server side of the connection
+
 	idCounter uint32
 
 	// This function, if non-nil, is called when the connection is lost.
@@ -117,7 +119,7 @@ type clientConn struct {
 func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.WithValue(context.Background(), clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
-	handler := newHandler(ctx, conn, c.idgen, c.services, c.methodAllowList, 50, false /* traceRequests */, c.logger, 0)
+	handler := newHandler(ctx, conn, c.idgen, c.services, c.methodAllowList, 50, false /* traceRequests */, c.logger, 0, c.heavyMethodLimits)
 	return &clientConn{conn, handler}
 }
 
@@ -202,28 +204,29 @@ func newClient(initctx context.Context, connect reconnectFunc, logger log.Logger
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), &serviceRegistry{logger: logger}, logger)
+	c := initClient(conn, randomIDGenerator(), &serviceRegistry{logger: logger}, HeavyMethodLimits{}, logger)
 	c.reconnectFunc = connect
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, logger log.Logger) *Client {
+func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, heavyMethodLimits HeavyMethodLimits, logger log.Logger) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
-		idgen:       idgen,
-		isHTTP:      isHTTP,
-		services:    services,
-		writeConn:   conn,
-		close:       make(chan struct{}),
-		closing:     make(chan struct{}),
-		didClose:    make(chan struct{}),
-		reconnected: make(chan ServerCodec),
-		readOp:      make(chan readOp),
-		readErr:     make(chan error),
-		reqInit:     make(chan *requestOp),
-		reqSent:     make(chan error, 1),
-		reqTimeout:  make(chan *requestOp),
-		logger:      logger,
+		idgen:             idgen,
+		isHTTP:            isHTTP,
+		services:          services,
+		heavyMethodLimits: heavyMethodLimits,
+		writeConn:         conn,
+		close:             make(chan struct{}),
+		closing:           make(chan struct{}),
+		didClose:          make(chan struct{}),
+		reconnected:       make(chan ServerCodec),
+		readOp:            make(chan readOp),
+		readErr:           make(chan error),
+		reqInit:           make(chan *requestOp),
+		reqSent:           make(chan error, 1),
+		reqTimeout:        make(chan *requestOp),
+		logger:            logger,
 	}
 	if !isHTTP {
 		go c.dispatch(conn)
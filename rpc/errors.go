@@ -19,7 +19,10 @@
 
 package rpc
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 var (
 	_ Error = new(methodNotFoundError)
@@ -29,6 +32,11 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(InvalidParamsError)
 	_ Error = new(CustomError)
+	_ Error = new(ResponseTooLargeError)
+	_ Error = new(RequestTimeoutError)
+
+	_ DataError = new(ResponseTooLargeError)
+	_ DataError = new(RequestTimeoutError)
 )
 
 const defaultErrorCode = -32000
@@ -92,3 +100,37 @@ type CustomError struct {
 func (e *CustomError) ErrorCode() int { return e.Code }
 
 func (e *CustomError) Error() string { return e.Message }
+
+const narrowRangeHint = "narrow the block range or the filter of the request"
+
+// the response of a heavy method exceeds the limit set by Server.SetHeavyMethodLimits
+type ResponseTooLargeError struct {
+	Method string
+	Limit  int
+}
+
+func (e *ResponseTooLargeError) ErrorCode() int { return -32005 }
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("result too large: response of %s exceeds %d bytes (--rpc.response.maxbytes), narrow the range", e.Method, e.Limit)
+}
+
+func (e *ResponseTooLargeError) ErrorData() interface{} {
+	return map[string]interface{}{"maxBytes": e.Limit, "hint": narrowRangeHint}
+}
+
+// a heavy method did not finish within the timeout set by Server.SetHeavyMethodLimits
+type RequestTimeoutError struct {
+	Method  string
+	Timeout time.Duration
+}
+
+func (e *RequestTimeoutError) ErrorCode() int { return -32002 }
+
+func (e *RequestTimeoutError) Error() string {
+	return fmt.Sprintf("request timed out: %s did not finish in %s (--rpc.heavy.timeout), narrow the range", e.Method, e.Timeout)
+}
+
+func (e *RequestTimeoutError) ErrorData() interface{} {
+	return map[string]interface{}{"timeout": e.Timeout.String(), "hint": narrowRangeHint}
+}
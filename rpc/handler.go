@@ -23,7 +23,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -80,6 +82,8 @@ type handler struct {
 	//slow requests
 	slowLogThreshold time.Duration
 	slowLogBlacklist []string
+
+	heavyMethodLimits HeavyMethodLimits
 }
 
 type callProc struct {
@@ -118,7 +122,7 @@ func HandleError(err error, stream *jsoniter.Stream) {
 	}
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, allowList AllowList, maxBatchConcurrency uint, traceRequests bool, logger log.Logger, rpcSlowLogThreshold time.Duration) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, allowList AllowList, maxBatchConcurrency uint, traceRequests bool, logger log.Logger, rpcSlowLogThreshold time.Duration, heavyMethodLimits HeavyMethodLimits) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
 	forbiddenList := newForbiddenList()
 
@@ -141,6 +145,8 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 
 		slowLogThreshold: rpcSlowLogThreshold,
 		slowLogBlacklist: rpccfg.SlowLogBlackList,
+
+		heavyMethodLimits: heavyMethodLimits,
 	}
 
 	if conn.remoteAddr() != "" {
@@ -481,7 +487,12 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage, stream *jsoniter
 		return msg.errorResponse(&InvalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args, stream)
+	var answer *jsonrpcMessage
+	if h.isHeavyMethod(msg.Method) {
+		answer = h.runHeavyMethod(cp.ctx, msg, callb, args, stream)
+	} else {
+		answer = h.runMethod(cp.ctx, msg, callb, args, stream)
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -528,12 +539,80 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage, stream *jso
 	return h.runMethod(ctx, msg, callb, args, stream)
 }
 
+func (h *handler) isHeavyMethod(method string) bool {
+	return h.heavyMethodLimits != (HeavyMethodLimits{}) && slices.Contains(rpccfg.HeavyMethods, method)
+}
+
+// runHeavyMethod runs a method of rpccfg.HeavyMethods within h.heavyMethodLimits. With a response size limit the
+// response is buffered up to the limit before being written to stream, so that it can still be replaced by an error.
+func (h *handler) runHeavyMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream *jsoniter.Stream) *jsonrpcMessage {
+	limits := h.heavyMethodLimits
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, limits.Timeout, &RequestTimeoutError{Method: msg.Method, Timeout: limits.Timeout})
+		defer cancel()
+	}
+	if limits.MaxResponseBytes <= 0 {
+		return h.runMethod(ctx, msg, callb, args, stream)
+	}
+
+	tooLarge := &ResponseTooLargeError{Method: msg.Method, Limit: limits.MaxResponseBytes}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	// the method is stopped as soon as its response is known to be too large
+	buf := &limitedBuffer{limit: limits.MaxResponseBytes, onExceeded: func() { cancel(tooLarge) }}
+	bufStream := jsoniter.NewStream(jsoniter.ConfigDefault, buf, 4096)
+	answer := h.runMethod(ctx, msg, callb, args, bufStream)
+	_ = bufStream.Flush()
+	if buf.exceeded || (answer != nil && len(answer.Result) > limits.MaxResponseBytes) {
+		return msg.errorResponse(tooLarge)
+	}
+	if timeoutErr := limitError(ctx, nil); timeoutErr != nil {
+		// the buffered part of a streamed response is dropped as well
+		return msg.errorResponse(timeoutErr)
+	}
+	if answer == nil {
+		stream.Write(buf.Bytes())
+	}
+	return answer
+}
+
+// limitedBuffer drops everything written to it once its limit is exceeded
+type limitedBuffer struct {
+	bytes.Buffer
+	limit      int
+	exceeded   bool
+	onExceeded func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.exceeded {
+		return len(p), nil
+	}
+	if b.Len()+len(p) > b.limit {
+		b.exceeded = true
+		b.Reset()
+		b.onExceeded()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// limitError replaces the error of a method stopped by its deadline, see runHeavyMethod
+func limitError(ctx context.Context, err error) error {
+	var timeoutErr *RequestTimeoutError
+	if errors.As(context.Cause(ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	return err
+}
+
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream *jsoniter.Stream) *jsonrpcMessage {
 	if !callb.streamable {
 		result, err := callb.call(ctx, msg.Method, args, stream)
 		if err != nil {
-			return msg.errorResponse(err)
+			return msg.errorResponse(limitError(ctx, err))
 		}
 		return msg.response(result)
 	}
@@ -552,7 +631,7 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	if err != nil {
 		writeNilIfNotPresent(stream)
 		stream.WriteMore()
-		HandleError(err, stream)
+		HandleError(limitError(ctx, err), stream)
 	}
 	stream.WriteObjectEnd()
 	stream.Flush()
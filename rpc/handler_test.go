@@ -19,9 +19,12 @@ package rpc
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
@@ -112,3 +115,76 @@ func TestHandlerDoesNotDoubleWriteNull(t *testing.T) {
 	}
 
 }
+
+func TestHandlerHeavyMethodLimits(t *testing.T) {
+	// streams n elements, stops when the request is canceled
+	streamed := func(ctx context.Context, n int, stream *jsoniter.Stream) error {
+		stream.WriteArrayStart()
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if i > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteString(strings.Repeat("a", 100))
+			_ = stream.Flush()
+		}
+		stream.WriteArrayEnd()
+		return nil
+	}
+	blocking := func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	var arg1 int
+	streamedCb := &callback{fn: reflect.ValueOf(streamed), argTypes: []reflect.Type{reflect.TypeOf(arg1)}, hasCtx: true, errPos: 0, streamable: true}
+	blockingCb := &callback{fn: reflect.ValueOf(blocking), hasCtx: true, errPos: 1}
+
+	tests := map[string]struct {
+		cb       *callback
+		params   []byte
+		expected string
+	}{
+		"below_limit": {
+			cb:       streamedCb,
+			params:   []byte("[2]"),
+			expected: `{"jsonrpc":"2.0","id":1,"result":["` + strings.Repeat("a", 100) + `","` + strings.Repeat("a", 100) + `"]}`,
+		},
+		"too_large": {
+			cb:       streamedCb,
+			params:   []byte("[1000000]"),
+			expected: `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"result too large: response of eth_getLogs exceeds 1000 bytes (--rpc.response.maxbytes), narrow the range","data":{"hint":"narrow the block range or the filter of the request","maxBytes":1000}}}`,
+		},
+		"timeout": {
+			cb:       blockingCb,
+			params:   []byte("[]"),
+			expected: `{"jsonrpc":"2.0","id":1,"error":{"code":-32002,"message":"request timed out: eth_getLogs did not finish in 10ms (--rpc.heavy.timeout), narrow the range","data":{"hint":"narrow the block range or the filter of the request","timeout":"10ms"}}}`,
+		},
+	}
+
+	for name, testParams := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := jsonrpcMessage{Version: "2.0", ID: []byte{49}, Method: "eth_getLogs", Params: testParams.params}
+			args, err := parsePositionalArguments(msg.Params, testParams.cb.argTypes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+
+			h := handler{heavyMethodLimits: HeavyMethodLimits{MaxResponseBytes: 1000, Timeout: 10 * time.Millisecond}}
+			assert.True(t, h.isHeavyMethod(msg.Method))
+			if answer := h.runHeavyMethod(context.Background(), &msg, testParams.cb, args, stream); answer != nil {
+				data, err := json.Marshal(answer)
+				if err != nil {
+					t.Fatal(err)
+				}
+				buf.Write(data)
+			}
+			_ = stream.Flush()
+			assert.Equal(t, testParams.expected, buf.String(), "expected output should match")
+		})
+	}
+}
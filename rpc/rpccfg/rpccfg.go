@@ -64,3 +64,12 @@ var SlowLogBlackList = []string{
 	"erigon_blockNumber", "erigon_getHeaderByNumber", "erigon_getHeaderByHash", "erigon_getBlockByTimestamp",
 	"eth_call",
 }
+
+// HeavyMethods - methods whose response and run time grow with the range of the request, bounded by
+// --rpc.response.maxbytes and --rpc.heavy.timeout
+var HeavyMethods = []string{
+	"eth_getLogs", "eth_getBlockReceipts",
+	"erigon_getLogs", "erigon_getLatestLogs", "erigon_getBlockReceiptsByBlockHash", "erigon_getBlockReceiptsRange",
+	"trace_filter", "trace_block", "trace_replayBlockTransactions", "trace_transaction", "trace_replayTransaction",
+	"debug_traceBlockByNumber", "debug_traceBlockByHash", "debug_traceTransaction", "debug_traceCall", "debug_traceCallMany",
+}
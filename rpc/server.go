@@ -62,6 +62,7 @@ type Server struct {
 	batchLimit          int  // Maximum number of requests in a batch
 	logger              log.Logger
 	rpcSlowLogThreshold time.Duration
+	heavyMethodLimits   HeavyMethodLimits
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -85,6 +86,17 @@ func (s *Server) SetBatchLimit(limit int) {
 	s.batchLimit = limit
 }
 
+// HeavyMethodLimits bounds the methods of rpccfg.HeavyMethods, zero values - no limit
+type HeavyMethodLimits struct {
+	MaxResponseBytes int           // the response is replaced by ResponseTooLargeError beyond it
+	Timeout          time.Duration // the request fails with RequestTimeoutError beyond it
+}
+
+// SetHeavyMethodLimits sets the limits of the heavy methods, on every transport of the server
+func (s *Server) SetHeavyMethodLimits(limits HeavyMethodLimits) {
+	s.heavyMethodLimits = limits
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -110,7 +122,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services, s.logger)
+	c := initClient(codec, s.idgen, &s.services, s.heavyMethodLimits, s.logger)
 	<-codec.closed()
 	c.Close()
 }
@@ -124,7 +136,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, stre
 		return
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services, s.methodAllowList, s.batchConcurrency, s.traceRequests, s.logger, s.rpcSlowLogThreshold)
+	h := newHandler(ctx, codec, s.idgen, &s.services, s.methodAllowList, s.batchConcurrency, s.traceRequests, s.logger, s.rpcSlowLogThreshold, s.heavyMethodLimits)
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -23,6 +23,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net"
 	"os"
@@ -181,3 +182,33 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+type heavyTestService struct{}
+
+func (s *heavyTestService) GetLogs(n int) string {
+	return strings.Repeat("a", n)
+}
+
+// The heavy method limits apply to the connections served by ServeCodec (websocket, IPC) as well as to HTTP.
+func TestServerHeavyMethodLimitsOverConn(t *testing.T) {
+	logger := log.New()
+	server := NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, false, logger, 100)
+	defer server.Stop()
+	if err := server.RegisterName("eth", new(heavyTestService)); err != nil {
+		t.Fatal(err)
+	}
+	server.SetHeavyMethodLimits(HeavyMethodLimits{MaxResponseBytes: 1000})
+
+	client := DialInProc(server, logger)
+	defer client.Close()
+
+	var result string
+	if err := client.Call(&result, "eth_getLogs", 10); err != nil {
+		t.Fatal(err)
+	}
+	err := client.Call(&result, "eth_getLogs", 2000)
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != new(ResponseTooLargeError).ErrorCode() {
+		t.Fatalf("expected a response too large error, got %v", err)
+	}
+}
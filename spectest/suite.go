@@ -1,8 +1,11 @@
 package spectest
 
 import (
+	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"runtime/debug"
 	"testing"
 
 	"github.com/erigontech/erigon/cl/transition/machine"
@@ -14,6 +17,15 @@ import (
 func RunCases(t *testing.T, app Appendix, machineImpl machine.Interface, root fs.FS) {
 	cases, err := ReadTestCases(root)
 	require.Nil(t, err, "reading cases")
+	summary := &Summary{}
+	if path := os.Getenv(SummaryFileEnv); path != "" {
+		// runs once all the parallel cases are done
+		t.Cleanup(func() {
+			if err := summary.WriteFile(path); err != nil {
+				t.Errorf("writing summary: %s", err)
+			}
+		})
+	}
 	// prepare for gore.....
 	type (
 		K1 = string
@@ -45,31 +57,39 @@ func RunCases(t *testing.T, app Appendix, machineImpl machine.Interface, root fs
 													return true
 												}
 												t.Run(key, func(t *testing.T) {
-													require.NotPanics(t, func() {
-														t.Parallel()
-														runner, ok := app[value.RunnerName]
-														if !ok {
-															t.Skipf("runner not found: %s", value.RunnerName)
-															return
-														}
-														handler, err := runner.GetHandler(value.HandlerName)
-														if err != nil {
-															t.Skipf("handler not found: %s/%s", value.RunnerName, value.HandlerName)
-															return
+													t.Parallel()
+													// every case runs on its own copy of the test case, a panic only fails the case itself
+													var err error
+													defer func() {
+														if r := recover(); r != nil {
+															err = fmt.Errorf("panic: %v", r)
+															t.Errorf("%s\n%s", err, debug.Stack())
 														}
-														subfs, err := fs.Sub(root, filepath.Join(
-															value.ConfigName,
-															value.ForkPhaseName,
-															value.RunnerName,
-															value.HandlerName,
-															value.SuiteName,
-															value.CaseName,
-														))
-														value.Machine = machineImpl
-														require.NoError(t, err)
-														err = handler.Run(t, subfs, value)
-														require.NoError(t, err)
-													})
+														summary.record(t, value, err)
+													}()
+													runner, ok := app[value.RunnerName]
+													if !ok {
+														t.Skipf("runner not found: %s", value.RunnerName)
+														return
+													}
+													handler, err := runner.GetHandler(value.HandlerName)
+													if err != nil {
+														err = nil
+														t.Skipf("handler not found: %s/%s", value.RunnerName, value.HandlerName)
+														return
+													}
+													subfs, err := fs.Sub(root, filepath.Join(
+														value.ConfigName,
+														value.ForkPhaseName,
+														value.RunnerName,
+														value.HandlerName,
+														value.SuiteName,
+														value.CaseName,
+													))
+													value.Machine = machineImpl
+													require.NoError(t, err)
+													err = handler.Run(t, subfs, value)
+													require.NoError(t, err)
 												})
 												return true
 											})
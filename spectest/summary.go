@@ -0,0 +1,101 @@
+package spectest
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// SummaryFileEnv is the environment variable holding the path the json summary of a run is written to.
+const SummaryFileEnv = "SPECTEST_SUMMARY"
+
+const (
+	CasePassed  = "passed"
+	CaseFailed  = "failed"
+	CaseSkipped = "skipped"
+)
+
+// CaseResult is the outcome of a single test case.
+type CaseResult struct {
+	Config  string `json:"config"`
+	Fork    string `json:"fork"`
+	Runner  string `json:"runner"`
+	Handler string `json:"handler"`
+	Suite   string `json:"suite"`
+	Case    string `json:"case"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Summary aggregates the outcome of all the cases of a run in a machine-readable form.
+type Summary struct {
+	mu sync.Mutex
+
+	Passed   int          `json:"passed"`
+	Failed   int          `json:"failed"`
+	Skipped  int          `json:"skipped"`
+	Failures []CaseResult `json:"failures"`
+}
+
+func (s *Summary) record(t *testing.T, c TestCase, err error) {
+	res := CaseResult{
+		Config:  c.ConfigName,
+		Fork:    c.ForkPhaseName,
+		Runner:  c.RunnerName,
+		Handler: c.HandlerName,
+		Suite:   c.SuiteName,
+		Case:    c.CaseName,
+	}
+	switch {
+	case t.Failed():
+		res.Status = CaseFailed
+	case t.Skipped():
+		res.Status = CaseSkipped
+	default:
+		res.Status = CasePassed
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch res.Status {
+	case CaseFailed:
+		s.Failed++
+		s.Failures = append(s.Failures, res)
+	case CaseSkipped:
+		s.Skipped++
+	default:
+		s.Passed++
+	}
+}
+
+// WriteFile writes the summary as json, failures are sorted so that runs can be diffed.
+func (s *Summary) WriteFile(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sort.Slice(s.Failures, func(i, j int) bool {
+		a, b := s.Failures[i], s.Failures[j]
+		if a.Fork != b.Fork {
+			return a.Fork < b.Fork
+		}
+		if a.Runner != b.Runner {
+			return a.Runner < b.Runner
+		}
+		if a.Handler != b.Handler {
+			return a.Handler < b.Handler
+		}
+		if a.Suite != b.Suite {
+			return a.Suite < b.Suite
+		}
+		return a.Case < b.Case
+	})
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+)
+
+// BlockOverrides is a set of header fields to override for a call. Besides the field names of eth_callMany, the ones
+// of geth - number, time, feeRecipient, baseFeePerGas - are accepted.
+type BlockOverrides struct {
+	BlockNumber *hexutil.Uint64
+	Coinbase    *libcommon.Address
+	Timestamp   *hexutil.Uint64
+	GasLimit    *hexutil.Uint
+	Difficulty  *hexutil.Uint
+	BaseFee     *uint256.Int
+	BlockHash   *map[uint64]libcommon.Hash
+	PrevRandao  *libcommon.Hash
+	BlobBaseFee *uint256.Int
+}
+
+func (overrides *BlockOverrides) UnmarshalJSON(input []byte) error {
+	type blockOverrides BlockOverrides
+	var dec struct {
+		blockOverrides
+		Number        *hexutil.Uint64
+		Time          *hexutil.Uint64
+		FeeRecipient  *libcommon.Address
+		BaseFeePerGas *uint256.Int
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*overrides = BlockOverrides(dec.blockOverrides)
+	if dec.Number != nil {
+		overrides.BlockNumber = dec.Number
+	}
+	if dec.Time != nil {
+		overrides.Timestamp = dec.Time
+	}
+	if dec.FeeRecipient != nil {
+		overrides.Coinbase = dec.FeeRecipient
+	}
+	if dec.BaseFeePerGas != nil {
+		overrides.BaseFee = dec.BaseFeePerGas
+	}
+	return nil
+}
+
+// Override applies the overrides to the block context, the overridden block hashes are added to overrideBlockHash.
+// The fork rules follow the overridden number and time.
+func (overrides *BlockOverrides) Override(blockCtx *evmtypes.BlockContext, overrideBlockHash map[uint64]libcommon.Hash) {
+	if overrides == nil {
+		return
+	}
+	if overrides.BlockNumber != nil {
+		blockCtx.BlockNumber = uint64(*overrides.BlockNumber)
+	}
+	if overrides.BaseFee != nil {
+		blockCtx.BaseFee = overrides.BaseFee
+	}
+	if overrides.Coinbase != nil {
+		blockCtx.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Difficulty != nil {
+		blockCtx.Difficulty = new(big.Int).SetUint64(uint64(*overrides.Difficulty))
+	}
+	if overrides.Timestamp != nil {
+		blockCtx.Time = uint64(*overrides.Timestamp)
+	}
+	if overrides.GasLimit != nil {
+		blockCtx.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.PrevRandao != nil {
+		prevRandao := *overrides.PrevRandao
+		blockCtx.PrevRanDao = &prevRandao
+	}
+	if overrides.BlobBaseFee != nil {
+		blockCtx.BlobBaseFee = overrides.BlobBaseFee
+	}
+	if overrides.BlockHash != nil {
+		for blockNum, hash := range *overrides.BlockHash {
+			overrideBlockHash[blockNum] = hash
+		}
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/common/dir"
+	"github.com/erigontech/erigon-lib/config3"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/utils"
+	"github.com/erigontech/erigon/ethdb/prune"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var (
+	pruneTargetSizeFlag = cli.StringFlag{
+		Name:     "target-size",
+		Usage:    "Size the datadir is pruned down to, e.g. 2TB",
+		Required: true,
+	}
+	pruneKeepStepsFlag = cli.Uint64Flag{
+		Name:  "keep-steps",
+		Usage: "Steps of the most recent history which are never pruned",
+		Value: config3.StepsInFrozenFile,
+	}
+	pruneDryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Only print what would be removed and the resulting RPC capabilities",
+	}
+)
+
+var pruneCommand = cli.Command{
+	Action: MigrateFlags(doPrune),
+	Name:   "prune",
+	Usage:  "Drop the oldest history files until the datadir fits into --target-size",
+	Flags: []cli.Flag{
+		&utils.DataDirFlag,
+		&pruneTargetSizeFlag,
+		&pruneKeepStepsFlag,
+		&pruneDryRunFlag,
+	},
+	Description: `
+Drops the history and inverted index files (and their accessors) of the oldest steps, until the size of the
+datadir is at most --target-size, never touching the last --keep-steps steps. The latest state and the blocks
+are kept. The files are removed oldest first, so an interrupted prune leaves a contiguous history behind.
+The node must be stopped. The new start of the history is stored in the db, the RPC queries of the blocks
+before it fail. Prints which historical RPC queries still work afterwards.`,
+}
+
+// historyFileRe matches the state files: version, name, step range, extension
+var historyFileRe = regexp.MustCompile(`^v\d+(?:\.\d+)?-([a-z]+)\.(\d+)-(\d+)\.([a-z]+)$`)
+
+type historyFile struct {
+	path     string
+	name     string // domain or inverted index, e.g. accounts or logaddrs
+	ext      string
+	from, to uint64 // steps
+	size     uint64 // including the .torrent file
+}
+
+// prunable - history and inverted index files and their accessors, the domain files hold the latest state
+func (f historyFile) prunable() bool {
+	return f.ext == "v" || f.ext == "ef" || f.ext == "vi" || f.ext == "efi"
+}
+
+// data - history or inverted index file, not an accessor
+func (f historyFile) data() bool { return f.ext == "v" || f.ext == "ef" }
+
+func listStateFiles(dirs datadir.Dirs) (files []historyFile, err error) {
+	for _, dirPath := range []string{dirs.SnapDomain, dirs.SnapHistory, dirs.SnapIdx, dirs.SnapAccessors} {
+		filePaths, err := dir.ListFiles(dirPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, filePath := range filePaths {
+			m := historyFileRe.FindStringSubmatch(filepath.Base(filePath))
+			if m == nil {
+				continue
+			}
+			f := historyFile{path: filePath, name: m[1], ext: m[4]}
+			if f.from, err = strconv.ParseUint(m[2], 10, 64); err != nil {
+				return nil, err
+			}
+			if f.to, err = strconv.ParseUint(m[3], 10, 64); err != nil {
+				return nil, err
+			}
+			for _, p := range []string{filePath, filePath + ".torrent"} {
+				if info, err := os.Stat(p); err == nil {
+					f.size += uint64(info.Size())
+				} else if !errors.Is(err, fs.ErrNotExist) {
+					return nil, err
+				}
+			}
+			files = append(files, f)
+		}
+	}
+	slices.SortFunc(files, func(a, b historyFile) int {
+		if c := cmp.Compare(a.to, b.to); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.from, b.from)
+	})
+	return files, nil
+}
+
+func dirSize(path string) (size uint64, err error) {
+	err = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		size += uint64(info.Size())
+		return nil
+	})
+	return size, err
+}
+
+// planPrune picks the lowest step the prunable files ending at or before it have to go for the datadir of
+// size used to fit into target, never beyond keepFrom. ok is false if the target can't be reached, then the
+// returned cut frees as much as allowed.
+func planPrune(files []historyFile, used, target, keepFrom uint64) (cut, freed uint64, ok bool) {
+	if used <= target {
+		return 0, 0, true
+	}
+	for _, f := range files { // sorted by the end step
+		if !f.prunable() || f.to > keepFrom {
+			continue
+		}
+		if f.to != cut && used-freed <= target {
+			return cut, freed, true
+		}
+		cut = f.to
+		freed += f.size
+	}
+	return cut, freed, used-freed <= target
+}
+
+// historyStarts returns the first step of the history left, per domain or inverted index
+func historyStarts(files []historyFile, cut uint64) map[string]uint64 {
+	starts := map[string]uint64{}
+	for _, f := range files {
+		if !f.data() || f.prunable() && f.to <= cut {
+			continue
+		}
+		if start, ok := starts[f.name]; !ok || f.from < start {
+			starts[f.name] = f.from
+		}
+	}
+	return starts
+}
+
+type rpcCapability struct {
+	queries string
+	start   uint64 // first step of the history the queries can be served from
+	ok      bool   // false if only the recent history in the db is left
+}
+
+// rpcCapabilities lists from which step the historical RPC queries still work, given the history starts
+func rpcCapabilities(starts map[string]uint64) []rpcCapability {
+	// the history of a group is there from the latest start of its members
+	groupStart := func(names ...string) (uint64, bool) {
+		var start uint64
+		var found bool
+		for _, name := range names {
+			if s, ok := starts[name]; ok {
+				start, found = max(start, s), true
+			}
+		}
+		return start, found
+	}
+	var caps []rpcCapability
+	for _, c := range []struct {
+		queries string
+		names   []string
+	}{
+		{"historical state: eth_getBalance/eth_call, debug_trace*, trace_* of old blocks",
+			[]string{kv.FileAccountDomain, kv.FileStorageDomain, kv.FileCodeDomain}},
+		{"receipts of old blocks: eth_getTransactionReceipt, eth_getBlockReceipts", []string{kv.ReceiptDomain.String()}},
+		{"logs: eth_getLogs, erigon_getLogs", []string{kv.FileLogAddressIdx, kv.FileLogTopicsIdx}},
+		{"traces index: trace_filter, ots_searchTransactions*", []string{kv.FileTracesFromIdx, kv.FileTracesToIdx}},
+	} {
+		start, ok := groupStart(c.names...)
+		caps = append(caps, rpcCapability{queries: c.queries, start: start, ok: ok})
+	}
+	return caps
+}
+
+func logRPCCapabilities(logger log.Logger, starts map[string]uint64) {
+	logger.Info("[prune] RPC capabilities after the prune", "step_size", config3.DefaultStepSize)
+	logger.Info("[prune] RPC capabilities after the prune", "queries", "latest state, blocks, transactions", "from", "all")
+	for _, c := range rpcCapabilities(starts) {
+		if c.ok {
+			logger.Info("[prune] RPC capabilities after the prune", "queries", c.queries, "from_step", c.start,
+				"from_txnum", c.start*config3.DefaultStepSize)
+		} else {
+			logger.Info("[prune] RPC capabilities after the prune", "queries", c.queries, "from", "only the recent history in the db")
+		}
+	}
+}
+
+func persistHistoryStart(ctx context.Context, dirs datadir.Dirs, txNum uint64) error {
+	db := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	defer db.Close()
+	return db.Update(ctx, func(tx kv.RwTx) error {
+		return prune.SetHistoryStart(tx, txNum)
+	})
+}
+
+func doPrune(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	var target datasize.ByteSize
+	if err := target.UnmarshalText([]byte(cliCtx.String(pruneTargetSizeFlag.Name))); err != nil {
+		return fmt.Errorf("failed to parse --%s: %w", pruneTargetSizeFlag.Name, err)
+	}
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	_, lock, err := dirs.MustFlock()
+	if err != nil {
+		return fmt.Errorf("the node must be stopped: %w", err)
+	}
+	defer lock.Unlock()
+
+	used, err := dirSize(dirs.DataDir)
+	if err != nil {
+		return err
+	}
+	files, err := listStateFiles(dirs)
+	if err != nil {
+		return err
+	}
+	var lastStep uint64
+	for _, f := range files {
+		lastStep = max(lastStep, f.to)
+	}
+	keepFrom := lastStep - min(lastStep, cliCtx.Uint64(pruneKeepStepsFlag.Name))
+
+	cut, freed, ok := planPrune(files, used, target.Bytes(), keepFrom)
+	logger.Info("[prune] plan", "used", datasize.ByteSize(used).HR(), "target", target.HR(), "drop_steps_before", cut,
+		"freed", datasize.ByteSize(freed).HR(), "keep_from_step", keepFrom)
+	if !ok {
+		return fmt.Errorf("can't fit into %s keeping the steps from %d, at most %s can be freed", target.HR(), keepFrom, datasize.ByteSize(freed).HR())
+	}
+	if freed == 0 {
+		logger.Info("[prune] the datadir already fits")
+		logRPCCapabilities(logger, historyStarts(files, 0))
+		return nil
+	}
+
+	var toRemove []historyFile
+	for _, f := range files {
+		if f.prunable() && f.to <= cut {
+			toRemove = append(toRemove, f)
+		}
+	}
+	if !cliCtx.Bool(pruneDryRunFlag.Name) {
+		// persisted before the files go: the RPC daemon rejects the queries below it instead of serving wrong data
+		if err := persistHistoryStart(cliCtx.Context, dirs, cut*config3.DefaultStepSize); err != nil {
+			return err
+		}
+	}
+	var removedSize uint64
+	for i, f := range toRemove {
+		if cliCtx.Bool(pruneDryRunFlag.Name) {
+			logger.Info("[prune] would remove", "file", f.path)
+			continue
+		}
+		for _, p := range []string{f.path + ".torrent", f.path} {
+			if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", p, err)
+			}
+		}
+		removedSize += f.size
+		logger.Info("[prune] removed", "file", filepath.Base(f.path), "progress", fmt.Sprintf("%d/%d", i+1, len(toRemove)),
+			"freed", datasize.ByteSize(removedSize).HR())
+	}
+	logRPCCapabilities(logger, historyStarts(files, cut))
+	return nil
+}
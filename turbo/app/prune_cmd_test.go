@@ -0,0 +1,139 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+// testStateFiles - two domains worth of files over three steps, sorted by the end step as listStateFiles does
+func testStateFiles() []historyFile {
+	return []historyFile{
+		{name: kv.FileAccountDomain, ext: "kv", from: 0, to: 1, size: 100},
+		{name: kv.FileAccountDomain, ext: "v", from: 0, to: 1, size: 10},
+		{name: kv.FileAccountDomain, ext: "vi", from: 0, to: 1, size: 1},
+		{name: kv.FileLogAddressIdx, ext: "ef", from: 0, to: 1, size: 5},
+		{name: kv.FileLogAddressIdx, ext: "efi", from: 0, to: 1, size: 1},
+		{name: kv.FileAccountDomain, ext: "v", from: 1, to: 2, size: 10},
+		{name: kv.FileLogAddressIdx, ext: "ef", from: 1, to: 2, size: 5},
+		{name: kv.FileAccountDomain, ext: "v", from: 2, to: 3, size: 10},
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   uint64
+		keepFrom uint64
+		cut      uint64
+		freed    uint64
+		ok       bool
+	}{
+		{"already fits", 200, 3, 0, 0, true},
+		{"first step is enough", 190, 3, 1, 17, true},
+		{"a step is dropped as a whole", 182, 3, 2, 32, true},
+		{"all steps", 160, 3, 3, 42, true},
+		{"limited by keep-steps", 150, 2, 2, 32, false},
+		{"nothing prunable", 150, 0, 0, 0, false},
+		{"domain files are kept", 100, 3, 3, 42, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cut, freed, ok := planPrune(testStateFiles(), 200, tt.target, tt.keepFrom)
+			require.Equal(t, tt.cut, cut)
+			require.Equal(t, tt.freed, freed)
+			require.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestHistoryStarts(t *testing.T) {
+	tests := []struct {
+		name string
+		cut  uint64
+		want map[string]uint64
+	}{
+		{"nothing pruned", 0, map[string]uint64{kv.FileAccountDomain: 0, kv.FileLogAddressIdx: 0}},
+		{"first step pruned", 1, map[string]uint64{kv.FileAccountDomain: 1, kv.FileLogAddressIdx: 1}},
+		{"inverted index gone", 2, map[string]uint64{kv.FileAccountDomain: 2}},
+		{"all history gone", 3, map[string]uint64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, historyStarts(testStateFiles(), tt.cut))
+		})
+	}
+}
+
+func TestRPCCapabilities(t *testing.T) {
+	caps := rpcCapabilities(map[string]uint64{
+		kv.FileAccountDomain: 2,
+		kv.FileStorageDomain: 1,
+		kv.FileCodeDomain:    3,
+		kv.FileLogAddressIdx: 1,
+	})
+	require.Len(t, caps, 4)
+	// the state is there from the latest start of the domains
+	require.Equal(t, uint64(3), caps[0].start)
+	require.True(t, caps[0].ok)
+	// no receipt history left
+	require.False(t, caps[1].ok)
+	// a group is served from the members left
+	require.Equal(t, uint64(1), caps[2].start)
+	require.True(t, caps[2].ok)
+	require.False(t, caps[3].ok)
+}
+
+func TestListStateFiles(t *testing.T) {
+	dirs := datadir.New(t.TempDir())
+	write := func(path string, size int) {
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+	}
+	write(filepath.Join(dirs.SnapHistory, "v1-accounts.1-2.v"), 10)
+	write(filepath.Join(dirs.SnapHistory, "v1-accounts.0-1.v"), 10)
+	write(filepath.Join(dirs.SnapHistory, "v1-accounts.0-1.v.torrent"), 3)
+	write(filepath.Join(dirs.SnapIdx, "v1-logaddrs.0-2.ef"), 5)
+	write(filepath.Join(dirs.SnapAccessors, "v1-accounts.0-1.vi"), 1)
+	write(filepath.Join(dirs.SnapDomain, "v1-accounts.0-2.kv"), 100)
+	write(filepath.Join(dirs.SnapDomain, "salt-state.txt"), 1)
+
+	files, err := listStateFiles(dirs)
+	require.NoError(t, err)
+	var got [][2]uint64
+	for _, f := range files {
+		got = append(got, [2]uint64{f.from, f.to})
+	}
+	// by the end step, then by the start step
+	require.Equal(t, [][2]uint64{{0, 1}, {0, 1}, {0, 2}, {0, 2}, {1, 2}}, got)
+	require.Equal(t, "v1-accounts.1-2.v", filepath.Base(files[4].path))
+	for _, f := range files {
+		if f.ext == "v" && f.from == 0 {
+
+			require.Equal(t, uint64(13), f.size, "the .torrent file counts")
+		}
+		if f.ext == "ef" {
+			require.Equal(t, kv.FileLogAddressIdx, f.name)
+		}
+	}
+}
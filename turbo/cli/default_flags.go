@@ -27,6 +27,9 @@ var DefaultFlags = []cli.Flag{
 	&utils.DataDirFlag,
 	&utils.EthashDatasetDirFlag,
 	&utils.ExternalConsensusFlag,
+	&utils.EngineJournalDirFlag,
+	&utils.EngineJournalMaxFileSizeFlag,
+	&utils.EngineJournalMaxFilesFlag,
 	&utils.TxPoolDisableFlag,
 	&utils.TxPoolPriceLimitFlag,
 	&utils.TxPoolPriceBumpFlag,
@@ -34,6 +37,10 @@ var DefaultFlags = []cli.Flag{
 	&utils.TxPoolAccountSlotsFlag,
 	&utils.TxPoolBlobSlotsFlag,
 	&utils.TxPoolTotalBlobPoolLimit,
+	&utils.TxPoolMaxAAValidationGasFlag,
+	&utils.TxPoolBroadcastMinTipFlag,
+	&utils.TxPoolBroadcastBandwidthFlag,
+	&utils.TxPoolNewPeerAnnounceLimitFlag,
 	&utils.TxPoolGlobalSlotsFlag,
 	&utils.TxPoolGlobalBaseFeeSlotsFlag,
 	&utils.TxPoolGlobalQueueFlag,
@@ -75,6 +82,7 @@ var DefaultFlags = []cli.Flag{
 	&utils.HTTPTraceFlag,
 	&utils.HTTPDebugSingleFlag,
 	&utils.StateCacheFlag,
+	&utils.StateCacheViewsFlag,
 	&utils.RpcBatchConcurrencyFlag,
 	&utils.RpcStreamingDisableFlag,
 	&utils.DBReadConcurrencyFlag,
@@ -83,6 +91,8 @@ var DefaultFlags = []cli.Flag{
 	&utils.RpcGasCapFlag,
 	&utils.RpcBatchLimit,
 	&utils.RpcReturnDataLimit,
+	&utils.RpcResponseMaxBytes,
+	&utils.RpcHeavyTimeout,
 	&utils.AllowUnprotectedTxs,
 	&utils.RpcMaxGetProofRewindBlockCount,
 	&utils.RPCGlobalTxFeeCapFlag,
@@ -154,6 +164,7 @@ var DefaultFlags = []cli.Flag{
 	&utils.MinerNoVerfiyFlag,
 	&utils.MinerSigningKeyFileFlag,
 	&utils.MinerRecommitIntervalFlag,
+	&utils.MinerBuildLeadFlag,
 	&utils.SentryAddrFlag,
 	&utils.SentryLogPeerInfoFlag,
 	&utils.DownloaderAddrFlag,
@@ -169,16 +180,22 @@ var DefaultFlags = []cli.Flag{
 	&utils.BorBlockSizeFlag,
 	&utils.WithHeimdallMilestones,
 	&utils.WithHeimdallWaypoints,
+	&utils.BorUnsafeUnwindFlag,
 	&utils.PolygonSyncFlag,
 	&utils.PolygonSyncStageFlag,
 	&utils.EthStatsURLFlag,
 	&utils.OverridePragueFlag,
+	&utils.ChainConfigFlag,
 
 	&utils.CaplinDiscoveryAddrFlag,
 	&utils.CaplinDiscoveryPortFlag,
 	&utils.CaplinDiscoveryTCPPortFlag,
 	&utils.CaplinCheckpointSyncUrlFlag,
+	&utils.CaplinCheckpointSyncPolicyFlag,
+	&utils.CaplinCheckpointSyncStateRootFlag,
 	&utils.CaplinSubscribeAllTopicsFlag,
+	&utils.CaplinAttestationSubnetBackboneFlag,
+	&utils.CaplinAttestationSubnetRotationEpochsFlag,
 	&utils.CaplinMaxPeerCount,
 	&utils.CaplinEnableUPNPlag,
 	&utils.CaplinMaxInboundTrafficPerPeerFlag,
@@ -216,11 +233,20 @@ var DefaultFlags = []cli.Flag{
 	&utils.CaplinBackfillingFlag,
 	&utils.CaplinBlobBackfillingFlag,
 	&utils.CaplinDisableBlobPruningFlag,
+	&utils.CaplinBlobsRetentionEpochsFlag,
+	&utils.CaplinHistoricalStatesCacheSizeFlag,
+	&utils.CaplinHotStatesEpochsFlag,
+	&utils.CaplinColdStatesSlotFrequencyFlag,
 	&utils.CaplinDisableCheckpointSyncFlag,
 	&utils.CaplinArchiveFlag,
 	&utils.CaplinEnableSnapshotGeneration,
 	&utils.CaplinMevRelayUrl,
 	&utils.CaplinValidatorMonitorFlag,
+	&utils.CaplinValidatorKeystoresFlag,
+	&utils.CaplinValidatorPasswordFileFlag,
+	&utils.CaplinValidatorWeb3SignerUrlFlag,
+	&utils.CaplinValidatorFeeRecipientFlag,
+	&utils.CaplinValidatorGraffitiFlag,
 	&utils.CaplinCustomConfigFlag,
 	&utils.CaplinCustomGenesisFlag,
 
@@ -231,9 +257,17 @@ var DefaultFlags = []cli.Flag{
 	&SyncLoopBlockLimitFlag,
 	&SyncLoopBreakAfterFlag,
 	&SyncParallelStateFlushing,
+	&SyncTxPoolPrefetch,
+	&SyncWarmupSteps,
+	&SyncCommitmentWarmup,
+	&SyncTxSenderIndex,
+	&SyncBadBlocksKeep,
 
 	&utils.ChaosMonkeyFlag,
 
 	&utils.ShutterEnabled,
 	&utils.ShutterKeyperBootnodes,
+
+	&utils.BundleEnabled,
+	&utils.BundleMaxBundles,
 }
@@ -156,9 +156,39 @@ var (
 		Value: true,
 	}
 
+	SyncTxPoolPrefetch = cli.BoolFlag{
+		Name:  "sync.txpool-prefetch",
+		Usage: "Warm the state (accounts, code, access list storage) of the best txpool transactions while waiting for the next block",
+		Value: false,
+	}
+
+	SyncWarmupSteps = cli.Uint64Flag{
+		Name:  "sync.warmup-steps",
+		Usage: "At startup, load into the page cache the account/storage/commitment files (with indexes) of the given number of latest steps. 0 - disabled",
+		Value: 0,
+	}
+
+	SyncCommitmentWarmup = cli.BoolFlag{
+		Name:  "sync.commitment-warmup",
+		Usage: "Read the trie branches, accounts and storage of the updated keys ahead of the commitment computation",
+		Value: false,
+	}
+
+	SyncTxSenderIndex = cli.BoolFlag{
+		Name:  "sync.tx-sender-index",
+		Usage: "Index the transactions by sender, for erigon_getTransactionsBySender. Enabling it on a synced node indexes the whole chain",
+		Value: false,
+	}
+
+	SyncBadBlocksKeep = cli.UintFlag{
+		Name:  "sync.bad-blocks-keep",
+		Usage: "Quarantine the bad blocks into <datadir>/bad_blocks with what is needed to reproduce their execution, keeping the given number of the latest ones. Capturing the parent state may replay up to 64 blocks. 0 - disabled",
+		Value: 0,
+	}
+
 	UploadLocationFlag = cli.StringFlag{
 		Name:  "upload.location",
-		Usage: "Location to upload snapshot segments to",
+		Usage: "rclone location (e.g. an S3, GCS or R2 bucket) to upload the snapshot segments, their .torrent files, manifest.txt and checksums.txt to",
 		Value: "",
 	}
 
@@ -351,6 +381,11 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		cfg.Sync.LoopBlockLimit = limit
 	}
 	cfg.Sync.ParallelStateFlushing = ctx.Bool(SyncParallelStateFlushing.Name)
+	cfg.Sync.TxPoolPrefetch = ctx.Bool(SyncTxPoolPrefetch.Name)
+	cfg.Sync.WarmupSteps = ctx.Uint64(SyncWarmupSteps.Name)
+	cfg.Sync.CommitmentWarmup = ctx.Bool(SyncCommitmentWarmup.Name)
+	cfg.Sync.TxSenderIndex = ctx.Bool(SyncTxSenderIndex.Name)
+	cfg.Sync.BadBlocksKeep = ctx.Uint(SyncBadBlocksKeep.Name)
 
 	if location := ctx.String(UploadLocationFlag.Name); len(location) > 0 {
 		cfg.Sync.UploadLocation = location
@@ -539,6 +574,8 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 		TraceCompatibility:          ctx.Bool(utils.RpcTraceCompatFlag.Name),
 		BatchLimit:                  ctx.Int(utils.RpcBatchLimit.Name),
 		ReturnDataLimit:             ctx.Int(utils.RpcReturnDataLimit.Name),
+		ResponseMaxBytes:            ctx.Int(utils.RpcResponseMaxBytes.Name),
+		HeavyTimeout:                ctx.Duration(utils.RpcHeavyTimeout.Name),
 		AllowUnprotectedTxs:         ctx.Bool(utils.AllowUnprotectedTxs.Name),
 		MaxGetProofRewindBlockCount: ctx.Int(utils.RpcMaxGetProofRewindBlockCount.Name),
 
@@ -574,6 +611,10 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 	if err != nil {
 		utils.Fatalf("Invalid state.cache value provided")
 	}
+	c.StateCache.KeepViews = ctx.Uint64(utils.StateCacheViewsFlag.Name)
+	if c.StateCache.KeepViews == 0 {
+		utils.Fatalf("Invalid state.cache.views value provided")
+	}
 
 	/*
 		rootCmd.PersistentFlags().BoolVar(&cfg.GRPCServerEnabled, "grpc", false, "Enable GRPC server")
@@ -58,7 +58,7 @@ type HandlerT struct {
 // Verbosity sets the log verbosity ceiling. The verbosity of individual packages
 // and source files can be raised using Vmodule.
 func (*HandlerT) Verbosity(level int) {
-	//glogger.Verbosity(log.Lvl(level))
+	log.SetLvl(log.Lvl(level))
 }
 
 // Vmodule sets the log verbosity pattern. See package log for details on the
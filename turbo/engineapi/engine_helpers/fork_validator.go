@@ -50,19 +50,37 @@ const (
 
 const timingsCacheSize = 16
 
+// the maximum amount of validated payload post-states kept in memory at once.
+const forkStatesCacheSize = 8
+
 // the maximum point from the current head, past which side forks are not validated anymore.
 const maxForkDepth = 32 // 32 slots is the duration of an epoch thus there cannot be side forks in PoS deeper than 32 blocks from head.
 
 type validatePayloadFunc func(wrap.TxContainer, *types.Header, *types.RawBody, uint64, []*types.Header, []*types.RawBody, *shards.Notifications) error
 
-type ForkValidator struct {
-	// current memory batch containing chain head that extend canonical fork.
+// forkState is the in-memory post-state of a validated payload.
+type forkState struct {
 	sharedDom *state.SharedDomains
-	// notifications accumulated for the extending fork
-	extendingForkNotifications *shards.Notifications
-	// hash of chain head that extend canonical fork.
-	extendingForkHeadHash libcommon.Hash
-	extendingForkNumber   uint64
+	// notifications accumulated for the fork
+	notifications *shards.Notifications
+	number        uint64
+	// canonical block the fork was executed on top of.
+	baseHash libcommon.Hash
+	// whether the canonical head had to be unwound to reach the fork.
+	unwound bool
+}
+
+func (f *forkState) close() {
+	if f.sharedDom != nil {
+		f.sharedDom.Close()
+	}
+	f.sharedDom = nil
+}
+
+type ForkValidator struct {
+	// post-states of recently validated payloads keyed by head hash, so that payloads built on top of them
+	// and forkchoice updates choosing them do not unwind and re-execute the fork from the canonical chain.
+	forks *lru.Cache[libcommon.Hash, *forkState]
 	// this is the function we use to perform payload validation.
 	validatePayload validatePayloadFunc
 	blockReader     services.FullBlockReader
@@ -90,6 +108,7 @@ func NewForkValidatorMock(currentHeight uint64) *ForkValidator {
 		panic(err)
 	}
 	return &ForkValidator{
+		forks:         newForkStatesCache(),
 		currentHeight: currentHeight,
 		validHashes:   validHashes,
 		timingsCache:  timingsCache,
@@ -107,6 +126,7 @@ func NewForkValidator(ctx context.Context, currentHeight uint64, validatePayload
 		panic(err)
 	}
 	return &ForkValidator{
+		forks:           newForkStatesCache(),
 		validatePayload: validatePayload,
 		currentHeight:   currentHeight,
 		tmpDir:          tmpDir,
@@ -117,11 +137,21 @@ func NewForkValidator(ctx context.Context, currentHeight uint64, validatePayload
 	}
 }
 
-// ExtendingForkHeadHash return the fork head hash of the fork that extends the canonical chain.
-func (fv *ForkValidator) ExtendingForkHeadHash() libcommon.Hash {
+func newForkStatesCache() *lru.Cache[libcommon.Hash, *forkState] {
+	forks, err := lru.NewWithEvict[libcommon.Hash, *forkState]("forkStates", forkStatesCacheSize, func(_ libcommon.Hash, f *forkState) {
+		f.close()
+	})
+	if err != nil {
+		panic(err)
+	}
+	return forks
+}
+
+// HasExtendingFork returns whether the post-state of the given chain head is kept in memory and can be flushed.
+func (fv *ForkValidator) HasExtendingFork(headHash libcommon.Hash) bool {
 	fv.lock.Lock()
 	defer fv.lock.Unlock()
-	return fv.extendingForkHeadHash
+	return fv.forks.Contains(headHash)
 }
 
 // NotifyCurrentHeight is to be called at the end of the stage cycle and represent the last processed block.
@@ -133,41 +163,33 @@ func (fv *ForkValidator) NotifyCurrentHeight(currentHeight uint64) {
 	}
 	fv.currentHeight = currentHeight
 	// If the head changed,e previous assumptions on head are incorrect now.
-	if fv.sharedDom != nil {
-		fv.sharedDom.Close()
-	}
-	fv.sharedDom = nil
-	fv.extendingForkNotifications = nil
-	fv.extendingForkNumber = 0
-	fv.extendingForkHeadHash = libcommon.Hash{}
+	fv.forks.Purge()
 }
 
-// FlushExtendingFork flush the current extending fork if fcu chooses its head hash as the its forkchoice.
-func (fv *ForkValidator) FlushExtendingFork(tx kv.RwTx, accumulator *shards.Accumulator) error {
+// FlushExtendingFork flush the in-memory fork with the given head hash if fcu chooses it as its forkchoice.
+func (fv *ForkValidator) FlushExtendingFork(tx kv.RwTx, headHash libcommon.Hash, accumulator *shards.Accumulator) error {
 	fv.lock.Lock()
 	defer fv.lock.Unlock()
+	fork, ok := fv.forks.Peek(headHash)
+	if !ok {
+		return fmt.Errorf("no in-memory state of fork head %x", headHash)
+	}
+	defer fv.forks.Remove(headHash)
 	start := time.Now()
 	// Flush changes to db.
-	if fv.sharedDom != nil {
-		fv.sharedDom.SetTx(tx)
-		if err := fv.sharedDom.Flush(fv.ctx, tx); err != nil {
+	if fork.sharedDom != nil {
+		fork.sharedDom.SetTx(tx)
+		if err := fork.sharedDom.Flush(fv.ctx, tx); err != nil {
 			return err
 		}
-		fv.sharedDom.Close()
-		if err := stages.SaveStageProgress(tx, stages.Execution, fv.extendingForkNumber); err != nil {
+		if err := stages.SaveStageProgress(tx, stages.Execution, fork.number); err != nil {
 			return err
 		}
 	}
-	timings, _ := fv.timingsCache.Get(fv.extendingForkHeadHash)
+	timings, _ := fv.timingsCache.Get(headHash)
 	timings[BlockTimingsFlushExtendingFork] = time.Since(start)
-	fv.timingsCache.Add(fv.extendingForkHeadHash, timings)
-	fv.extendingForkNotifications.Accumulator.CopyAndReset(accumulator)
-	// Clean extending fork data
-	fv.sharedDom = nil
-
-	fv.extendingForkHeadHash = libcommon.Hash{}
-	fv.extendingForkNumber = 0
-	fv.extendingForkNotifications = nil
+	fv.timingsCache.Add(headHash, timings)
+	fork.notifications.Accumulator.CopyAndReset(accumulator)
 	return nil
 }
 
@@ -176,7 +198,8 @@ type HasDiff interface {
 }
 
 // ValidatePayload returns whether a payload is valid or invalid, or if cannot be determined, it will be accepted.
-// if the payload extends the canonical chain, then we stack it in extendingFork without any unwind.
+// if the payload extends the canonical chain, then we stack it in an in-memory fork without any unwind.
+// if the payload extends such an in-memory fork, then only the payload itself is executed on top of its parent's post-state.
 // if the payload is a fork then we unwind to the point where the fork meets the canonical chain, and there we check whether it is valid.
 // if for any reason none of the actions above can be performed due to lack of information, we accept the payload and avoid validation.
 func (fv *ForkValidator) ValidatePayload(tx kv.RwTx, header *types.Header, body *types.RawBody, logger log.Logger) (status engine_types.EngineStatus, latestValidHash libcommon.Hash, validationError error, criticalError error) {
@@ -261,32 +284,39 @@ func (fv *ForkValidator) ValidatePayload(tx kv.RwTx, header *types.Header, body
 	if unwindPoint == fv.currentHeight {
 		unwindPoint = 0
 	}
-	if fv.sharedDom != nil {
-		fv.sharedDom.Close()
+	fork := &forkState{
+		number:   number,
+		baseHash: currentHash,
+		unwound:  unwindPoint > 0,
 	}
-	fv.sharedDom, criticalError = state.NewSharedDomains(tx, logger)
-	if criticalError != nil {
-		criticalError = fmt.Errorf("failed to create shared domains: %w", criticalError)
-		return
+	// The parent was validated on top of the current head and its post-state is still around, so take it over:
+	// the blocks below the payload are already executed in it and will be skipped by the execution stage.
+	if parent, ok := fv.forks.Peek(header.ParentHash); ok && !fork.unwound && !parent.unwound && parent.baseHash == fork.baseHash && parent.sharedDom != nil {
+		logger.Debug("Execution ForkValidator.ValidatePayload: extending in-memory fork", "parentHash", header.ParentHash, "number", number)
+		fork.sharedDom, fork.notifications = parent.sharedDom, parent.notifications
+		parent.sharedDom = nil
+		fv.forks.Remove(header.ParentHash)
+		fork.sharedDom.SetTx(tx)
+	} else {
+		fork.sharedDom, criticalError = state.NewSharedDomains(tx, logger)
+		if criticalError != nil {
+			criticalError = fmt.Errorf("failed to create shared domains: %w", criticalError)
+			return
+		}
+		fork.notifications = shards.NewNotifications(nil)
 	}
 	var txc wrap.TxContainer
 	txc.Tx = tx
-	txc.Doms = fv.sharedDom
+	txc.Doms = fork.sharedDom
 
-	fv.extendingForkNotifications = shards.NewNotifications(nil)
-	return fv.validateAndStorePayload(txc, header, body, unwindPoint, headersChain, bodiesChain, fv.extendingForkNotifications)
+	return fv.validateAndStorePayload(txc, header, body, unwindPoint, headersChain, bodiesChain, fork)
 }
 
-// Clear wipes out current extending fork data, this method is called after fcu is called,
+// Clear wipes out in-memory forks data, this method is called after fcu is called,
 // because fcu decides what the head is and after the call is done all the non-chosen forks are
 // to be considered obsolete.
 func (fv *ForkValidator) clear() {
-	fv.extendingForkHeadHash = libcommon.Hash{}
-	fv.extendingForkNumber = 0
-	if fv.sharedDom != nil {
-		fv.sharedDom.Close()
-	}
-	fv.sharedDom = nil
+	fv.forks.Purge()
 }
 
 // Clear wipes out current extending fork data.
@@ -298,12 +328,13 @@ func (fv *ForkValidator) ClearWithUnwind(accumulator *shards.Accumulator, c shar
 
 // validateAndStorePayload validate and store a payload fork chain if such chain results valid.
 func (fv *ForkValidator) validateAndStorePayload(txc wrap.TxContainer, header *types.Header, body *types.RawBody, unwindPoint uint64, headersChain []*types.Header, bodiesChain []*types.RawBody,
-	notifications *shards.Notifications) (status engine_types.EngineStatus, latestValidHash libcommon.Hash, validationError error, criticalError error) {
+	fork *forkState) (status engine_types.EngineStatus, latestValidHash libcommon.Hash, validationError error, criticalError error) {
 	start := time.Now()
-	if err := fv.validatePayload(txc, header, body, unwindPoint, headersChain, bodiesChain, notifications); err != nil {
+	if err := fv.validatePayload(txc, header, body, unwindPoint, headersChain, bodiesChain, fork.notifications); err != nil {
 		if errors.Is(err, consensus.ErrInvalidBlock) {
 			validationError = err
 		} else {
+			fork.close()
 			criticalError = fmt.Errorf("validateAndStorePayload: %w", err)
 			return
 		}
@@ -311,9 +342,8 @@ func (fv *ForkValidator) validateAndStorePayload(txc wrap.TxContainer, header *t
 	fv.timingsCache.Add(header.Hash(), BlockTimings{time.Since(start), 0})
 
 	latestValidHash = header.Hash()
-	fv.extendingForkHeadHash = header.Hash()
-	fv.extendingForkNumber = header.Number.Uint64()
 	if validationError != nil {
+		fork.close()
 		var latestValidNumber uint64
 		latestValidNumber, criticalError = stages.GetStageProgress(txc.Tx, stages.Execution)
 
@@ -330,15 +360,11 @@ func (fv *ForkValidator) validateAndStorePayload(txc wrap.TxContainer, header *t
 			return
 		}
 		status = engine_types.InvalidStatus
-		if fv.sharedDom != nil {
-			fv.sharedDom.Close()
-		}
-		fv.sharedDom = nil
-		fv.extendingForkHeadHash = libcommon.Hash{}
-		fv.extendingForkNumber = 0
 		return
 	}
 	fv.validHashes.Add(header.Hash(), true)
+	fv.forks.Remove(header.Hash())
+	fv.forks.Add(header.Hash(), fork)
 
 	// If we do not have the body we can recover it from the batch.
 	if body != nil {
@@ -0,0 +1,234 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engine_journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const (
+	filePrefix = "engine-"
+	fileSuffix = ".jsonl"
+)
+
+type Config struct {
+	// Dir is the directory the journal files are written to, the journal is disabled if empty.
+	Dir         string
+	MaxFileSize int64
+	MaxFiles    int
+}
+
+var DefaultConfig = Config{
+	MaxFileSize: 128 * 1024 * 1024,
+	MaxFiles:    8,
+}
+
+func (c Config) Enabled() bool {
+	return c.Dir != ""
+}
+
+// Entry is a single engine API call as it was received from the consensus layer, along with our response.
+type Entry struct {
+	Time   time.Time         `json:"time"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// Journal records engine API requests and responses into rotating files of json lines, so that CL/EL interop issues
+// can be reproduced by replaying them against another node.
+type Journal struct {
+	cfg    Config
+	logger log.Logger
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewJournal(cfg Config, logger log.Logger) (*Journal, error) {
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Journal{cfg: cfg, logger: logger}, nil
+}
+
+// Record appends a call to the journal. It is a no-op on a nil journal, and failures are only logged as recording
+// must never affect the engine API itself.
+func (j *Journal) Record(method string, params []any, result any, callErr error) {
+	if j == nil {
+		return
+	}
+	entry := Entry{Time: time.Now(), Method: method, Params: make([]json.RawMessage, len(params))}
+	for i, param := range params {
+		b, err := json.Marshal(param)
+		if err != nil {
+			j.logger.Warn("[EngineJournal] Failed to encode request", "method", method, "err", err)
+			return
+		}
+		entry.Params[i] = b
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	} else {
+		b, err := json.Marshal(result)
+		if err != nil {
+			j.logger.Warn("[EngineJournal] Failed to encode response", "method", method, "err", err)
+			return
+		}
+		entry.Result = b
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		j.logger.Warn("[EngineJournal] Failed to encode entry", "method", method, "err", err)
+		return
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.write(line); err != nil {
+		j.logger.Warn("[EngineJournal] Failed to write entry", "method", method, "err", err)
+	}
+}
+
+// write must be called with the lock held.
+func (j *Journal) write(line []byte) error {
+	if j.file == nil || (j.cfg.MaxFileSize > 0 && j.size+int64(len(line)) > j.cfg.MaxFileSize) {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	return err
+}
+
+// rotate must be called with the lock held.
+func (j *Journal) rotate() error {
+	if j.file != nil {
+		if err := j.file.Close(); err != nil {
+			return err
+		}
+		j.file = nil
+	}
+	name := filepath.Join(j.cfg.Dir, fmt.Sprintf("%s%020d%s", filePrefix, time.Now().UnixNano(), fileSuffix))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	j.file, j.size = f, 0
+
+	if j.cfg.MaxFiles <= 0 {
+		return nil
+	}
+	files, err := journalFiles(j.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > j.cfg.MaxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// journalFiles returns the journal files of dir, oldest first.
+func journalFiles(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range dirEntries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), filePrefix) || !strings.HasSuffix(e.Name(), fileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadEntries reads all the entries of the journal in dir, in the order they were recorded.
+func ReadEntries(dir string) ([]Entry, error) {
+	files, err := journalFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, file := range files {
+		if entries, err = readFile(file, entries); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func readFile(file string, entries []Entry) ([]Entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 256*1024*1024)
+	var truncated error
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if truncated != nil {
+			return nil, truncated
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// only the last line may be broken, when the node crashed while writing it
+			truncated = fmt.Errorf("%s:%d: %w", file, line, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engine_journal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
+)
+
+func TestJournalRotation(t *testing.T) {
+	dir := t.TempDir()
+	j, err := NewJournal(Config{Dir: dir, MaxFileSize: 200, MaxFiles: 2}, log.New())
+	require.NoError(t, err)
+	defer j.Close()
+
+	for i := 0; i < 10; i++ {
+		j.Record("engine_forkchoiceUpdatedV3", []any{&engine_types.ForkChoiceState{HeadHash: libcommon.Hash{byte(i)}}, nil}, &engine_types.ForkChoiceUpdatedResponse{
+			PayloadStatus: &engine_types.PayloadStatus{Status: engine_types.ValidStatus},
+		}, nil)
+	}
+	j.Record("engine_newPayloadV3", []any{1}, nil, errors.New("boom"))
+
+	files, err := journalFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	entries, err := ReadEntries(dir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	require.Less(t, len(entries), 11)
+	last := entries[len(entries)-1]
+	require.Equal(t, "engine_newPayloadV3", last.Method)
+	require.Equal(t, "boom", last.Error)
+	require.Empty(t, last.Result)
+
+	// a line cut short by a crash is ignored if it is the last one.
+	f, err := os.OpenFile(files[1], os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"method":"engine_newPa`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	truncated, err := ReadEntries(dir)
+	require.NoError(t, err)
+	require.Equal(t, entries, truncated)
+
+	var nilJournal *Journal
+	nilJournal.Record("engine_newPayloadV1", nil, nil, nil)
+	require.NoError(t, nilJournal.Close())
+}
+
+type testEngine struct {
+	status engine_types.EngineStatus
+}
+
+func (e *testEngine) NewPayloadV1(_ context.Context, _ *engine_types.ExecutionPayload) (*engine_types.PayloadStatus, error) {
+	return &engine_types.PayloadStatus{Status: e.status}, nil
+}
+
+func (e *testEngine) ForkchoiceUpdatedV1(_ context.Context, _ *engine_types.ForkChoiceState, _ *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
+	return &engine_types.ForkChoiceUpdatedResponse{PayloadStatus: &engine_types.PayloadStatus{Status: engine_types.ValidStatus}}, nil
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	j, err := NewJournal(Config{Dir: dir}, log.New())
+	require.NoError(t, err)
+	j.Record("engine_newPayloadV1", []any{&engine_types.ExecutionPayload{}}, &engine_types.PayloadStatus{Status: engine_types.ValidStatus}, nil)
+	j.Record("engine_forkchoiceUpdatedV1", []any{&engine_types.ForkChoiceState{}, nil}, &engine_types.ForkChoiceUpdatedResponse{
+		PayloadStatus: &engine_types.PayloadStatus{Status: engine_types.ValidStatus},
+	}, nil)
+	j.Record("engine_getPayloadV1", []any{"0x01"}, nil, errors.New("unknown payload"))
+	require.NoError(t, j.Close())
+	entries, err := ReadEntries(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	engine := &testEngine{status: engine_types.ValidStatus}
+	server := rpc.NewServer(1, false, false, true, log.New(), 0)
+	require.NoError(t, server.RegisterName("engine", engine))
+	client := rpc.DialInProc(server, log.New())
+	defer client.Close()
+
+	mismatches, err := Replay(context.Background(), client, entries, log.New())
+	require.NoError(t, err)
+	require.Empty(t, mismatches)
+
+	engine.status = engine_types.InvalidStatus
+	mismatches, err = Replay(context.Background(), client, entries, log.New())
+	require.NoError(t, err)
+	require.Equal(t, []Mismatch{{Index: 0, Method: "engine_newPayloadV1", Recorded: "VALID", Replayed: "INVALID"}}, mismatches)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engine_journal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// Mismatch is a replayed call whose outcome differs from the recorded one.
+type Mismatch struct {
+	Index    int
+	Method   string
+	Recorded string
+	Replayed string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("#%d %s: recorded %s, replayed %s", m.Index, m.Method, m.Recorded, m.Replayed)
+}
+
+// Replay sends the recorded newPayload and forkchoiceUpdated calls to the engine API behind client, in order, and
+// reports the calls whose validity outcome differs from the recorded one.
+func Replay(ctx context.Context, client *rpc.Client, entries []Entry, logger log.Logger) ([]Mismatch, error) {
+	var mismatches []Mismatch
+	for i, entry := range entries {
+		if !strings.HasPrefix(entry.Method, "engine_newPayload") && !strings.HasPrefix(entry.Method, "engine_forkchoiceUpdated") {
+			continue
+		}
+		params := make([]any, len(entry.Params))
+		for k := range entry.Params {
+			params[k] = entry.Params[k]
+		}
+		var result json.RawMessage
+		var replayed string
+		if err := client.CallContext(ctx, &result, entry.Method, params...); err != nil {
+			if ctx.Err() != nil {
+				return mismatches, ctx.Err()
+			}
+			replayed = "error: " + err.Error()
+		} else {
+			replayed = outcome(result)
+		}
+		recorded := "error: " + entry.Error
+		if entry.Error == "" {
+			recorded = outcome(entry.Result)
+		}
+		if recorded != replayed {
+			mismatch := Mismatch{Index: i, Method: entry.Method, Recorded: recorded, Replayed: replayed}
+			logger.Warn("[EngineJournal] Replay mismatch", "index", i, "method", entry.Method, "recorded", recorded, "replayed", replayed)
+			mismatches = append(mismatches, mismatch)
+		}
+		if i%100 == 0 {
+			logger.Info("[EngineJournal] Replaying", "progress", fmt.Sprintf("%d/%d", i, len(entries)), "mismatches", len(mismatches))
+		}
+	}
+	return mismatches, nil
+}
+
+// outcome extracts the payload status out of a newPayload or forkchoiceUpdated response.
+func outcome(result json.RawMessage) string {
+	var status struct {
+		Status        string `json:"status"`
+		PayloadStatus *struct {
+			Status string `json:"status"`
+		} `json:"payloadStatus"`
+	}
+	if err := json.Unmarshal(result, &status); err != nil {
+		return "malformed response"
+	}
+	if status.PayloadStatus != nil {
+		return status.PayloadStatus.Status
+	}
+	return status.Status
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_block_downloader"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_helpers"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_journal"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_logs_spammer"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
 	"github.com/erigontech/erigon/turbo/execution/eth1/eth1_chain_reader"
@@ -52,6 +53,7 @@ import (
 	"github.com/erigontech/erigon/turbo/rpchelper"
 	"github.com/erigontech/erigon/turbo/services"
 	"github.com/erigontech/erigon/turbo/stages/headerdownload"
+	"github.com/erigontech/erigon/txnprovider"
 )
 
 var caplinEnabledLog = "Caplin is enabled, so the engine API cannot be used. for external CL use --externalcl"
@@ -72,6 +74,9 @@ type EngineServer struct {
 	logger  log.Logger
 
 	engineLogSpamer *engine_logs_spammer.EngineLogsSpammer
+	journal         *engine_journal.Journal // nil if disabled
+
+	txnsValidator txnprovider.BlockTxnsValidator // nil if disabled
 }
 
 const fcuTimeout = 1000 // according to mathematics: 1000 millisecods = 1 second
@@ -93,6 +98,17 @@ func NewEngineServer(logger log.Logger, config *chain.Config, executionService e
 	}
 }
 
+// SetJournal makes the server record the newPayload and forkchoiceUpdated calls it receives into the journal.
+func (e *EngineServer) SetJournal(journal *engine_journal.Journal) {
+	e.journal = journal
+}
+
+// SetBlockTxnsValidator makes the server check the txns of the payloads it receives against the ordering of a txn
+// provider. The ordering is not a consensus rule: the payloads breaking it are logged, not rejected.
+func (e *EngineServer) SetBlockTxnsValidator(txnsValidator txnprovider.BlockTxnsValidator) {
+	e.txnsValidator = txnsValidator
+}
+
 func (e *EngineServer) Start(
 	ctx context.Context,
 	httpConfig *httpcfg.HttpCfg,
@@ -316,6 +332,11 @@ func (s *EngineServer) newPayload(ctx context.Context, req *engine_types.Executi
 
 	s.logger.Debug("[NewPayload] sending block", "height", header.Number, "hash", blockHash)
 	block := types.NewBlockFromStorage(blockHash, &header, transactions, nil /* uncles */, withdrawals)
+	if s.txnsValidator != nil {
+		if err := s.txnsValidator.ValidateBlockTxns(ctx, block.Time(), block.Transactions()); err != nil {
+			s.logger.Warn("[NewPayload] block breaks the txn provider ordering", "height", header.Number, "hash", blockHash, "err", err)
+		}
+	}
 
 	payloadStatus, err := s.HandleNewPayload(ctx, "NewPayload", block, expectedBlobHashes)
 	if err != nil {
@@ -42,6 +42,7 @@ import (
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/eth/stagedsync"
+	stages2 "github.com/erigontech/erigon/eth/stagedsync/stages"
 	"github.com/erigontech/erigon/turbo/builder"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_helpers"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
@@ -175,6 +176,14 @@ func (e *EthereumExecutionModule) unwindToCommonCanonical(tx kv.RwTx, header *ty
 			return fmt.Errorf("header %v not found", currentHeader.Hash())
 		}
 	}
+	executionAt, err := stages2.GetStageProgress(tx, stages2.Execution)
+	if err != nil {
+		return err
+	}
+	if currentHeader.Number.Uint64() < executionAt {
+		// in-memory forks were executed on top of the head we are unwinding from
+		e.forkValidator.ClearWithUnwind(e.accumulator, e.stateChangeConsumer)
+	}
 	if err := e.hook.BeforeRun(tx, true); err != nil {
 		return err
 	}
@@ -198,7 +207,6 @@ func (e *EthereumExecutionModule) ValidateChain(ctx context.Context, req *execut
 	defer e.semaphore.Release(1)
 
 	e.hook.LastNewBlockSeen(req.Number) // used by eth_syncing
-	e.forkValidator.ClearWithUnwind(e.accumulator, e.stateChangeConsumer)
 	blockHash := gointerfaces.ConvertH256ToHash(req.Hash)
 
 	var (
@@ -33,7 +33,9 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/state"
 	"github.com/erigontech/erigon-lib/wrap"
+	"github.com/erigontech/erigon/consensus"
 	"github.com/erigontech/erigon/core/rawdb"
+	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/consensuschain"
 	"github.com/erigontech/erigon/eth/stagedsync"
 	"github.com/erigontech/erigon/eth/stagedsync/stages"
@@ -42,6 +44,12 @@ import (
 
 const startPruneFrom = 1024
 
+// sprintHeadersVerifier is implemented by engines which verify a batch of consecutive headers faster than one by
+// one, e.g. bor recovers their signers in parallel
+type sprintHeadersVerifier interface {
+	VerifySprintHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) error
+}
+
 type forkchoiceOutcome struct {
 	receipt *execution.ForkChoiceReceipt
 	err     error
@@ -355,6 +363,25 @@ func (e *EthereumExecutionModule) updateForkChoice(ctx context.Context, original
 			sendForkchoiceErrorWithoutWaiting(e.logger, outcomeCh, err, false)
 			return
 		}
+		// Verify the headers of the new canonical segment in one batch if the engine supports it
+		verifier, headersVerified := e.engine.(sprintHeadersVerifier)
+		if headersVerified {
+			headers := make([]*types.Header, len(newCanonicals))
+			for i, canonicalSegment := range newCanonicals {
+				h := rawdb.ReadHeader(tx, canonicalSegment.hash, canonicalSegment.number)
+				if h == nil {
+					sendForkchoiceErrorWithoutWaiting(e.logger, outcomeCh, fmt.Errorf("unexpected chain cap: %d", canonicalSegment.number), false)
+					return
+				}
+				// newCanonicals are collected from the head down
+				headers[len(newCanonicals)-1-i] = h
+			}
+			chainReader := consensuschain.NewReader(e.config, tx, e.blockReader, e.logger)
+			if err := verifier.VerifySprintHeaders(chainReader, headers); err != nil {
+				sendForkchoiceErrorWithoutWaiting(e.logger, outcomeCh, err, false)
+				return
+			}
+		}
 		// Mark all new canonicals as canonicals
 		for _, canonicalSegment := range newCanonicals {
 			chainReader := consensuschain.NewReader(e.config, tx, e.blockReader, e.logger)
@@ -367,9 +394,11 @@ func (e *EthereumExecutionModule) updateForkChoice(ctx context.Context, original
 				return
 			}
 
-			if err := e.engine.VerifyHeader(chainReader, h, true); err != nil {
-				sendForkchoiceErrorWithoutWaiting(e.logger, outcomeCh, err, false)
-				return
+			if !headersVerified {
+				if err := e.engine.VerifyHeader(chainReader, h, true); err != nil {
+					sendForkchoiceErrorWithoutWaiting(e.logger, outcomeCh, err, false)
+					return
+				}
 			}
 
 			if err := e.engine.VerifyUncles(chainReader, h, b.Uncles); err != nil {
@@ -424,7 +453,7 @@ func (e *EthereumExecutionModule) updateForkChoice(ctx context.Context, original
 		return
 	}
 
-	flushExtendingFork := blockHash == e.forkValidator.ExtendingForkHeadHash()
+	flushExtendingFork := e.forkValidator.HasExtendingFork(blockHash)
 	stateFlushingInParallel := flushExtendingFork && e.syncCfg.ParallelStateFlushing
 	if flushExtendingFork {
 		e.logger.Debug("[updateForkchoice] Fork choice update: flushing in-memory state (built by previous newPayload)")
@@ -436,7 +465,7 @@ func (e *EthereumExecutionModule) updateForkChoice(ctx context.Context, original
 				ValidationError: validationError,
 			}, false)
 		}
-		if err := e.forkValidator.FlushExtendingFork(tx, e.accumulator); err != nil {
+		if err := e.forkValidator.FlushExtendingFork(tx, blockHash, e.accumulator); err != nil {
 			sendForkchoiceErrorWithoutWaiting(e.logger, outcomeCh, err, stateFlushingInParallel)
 			return
 		}
@@ -58,7 +58,6 @@ func (e *EthereumExecutionModule) InsertBlocks(ctx context.Context, req *executi
 		}, nil
 	}
 	defer e.semaphore.Release(1)
-	e.forkValidator.ClearWithUnwind(e.accumulator, e.stateChangeConsumer)
 	frozenBlocks := e.blockReader.FrozenBlocks()
 
 	tx, err := e.db.BeginRw(ctx)
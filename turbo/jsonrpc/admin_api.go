@@ -20,9 +20,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/downloader/snaptype"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/state"
 	"github.com/erigontech/erigon/p2p"
+	"github.com/erigontech/erigon/params"
 
 	"github.com/erigontech/erigon/turbo/rpchelper"
 )
@@ -38,17 +49,57 @@ type AdminAPI interface {
 
 	// AddPeer requests connecting to a remote node.
 	AddPeer(ctx context.Context, url string) (bool, error)
+
+	// FilesEvents returns the latest builds and merges of the state files, oldest first.
+	// Only the rpcdaemon running inside the node sees them: it is the node which builds and merges the files.
+	FilesEvents(ctx context.Context) ([]state.FilesEvent, error)
+
+	// SetLogLevel sets the verbosity of all the log outputs: console and files.
+	// Like SetLogTarget, it changes the logging of the process serving the RPC: the node only if the rpcdaemon runs inside it.
+	SetLogLevel(ctx context.Context, level string) (bool, error)
+
+	// SetLogTarget sets the verbosity of the records of a subsystem, e.g. "commitment" or "bor", whatever is the
+	// verbosity of the outputs. An empty level makes the subsystem logged with the verbosity of the outputs again.
+	// Returns the subsystems which have their own verbosity.
+	SetLogTarget(ctx context.Context, target string, level string) (map[string]string, error)
+
+	// BuildInfo returns the version, the build metadata and the compiled-in features of the binary.
+	// Like SetLogLevel, it describes the process serving the RPC: the node only if the rpcdaemon runs inside it.
+	BuildInfo(ctx context.Context) (*BuildInfo, error)
+}
+
+// BuildInfo describes how the binary serving the RPC was built, to check that it matches the datadir it is used with.
+type BuildInfo struct {
+	Version          string                      `json:"version"`
+	GitCommit        string                      `json:"gitCommit"`
+	GitBranch        string                      `json:"gitBranch"`
+	GitTag           string                      `json:"gitTag"`
+	GoVersion        string                      `json:"goVersion"`
+	Os               string                      `json:"os"`
+	Arch             string                      `json:"arch"`
+	BuildTags        []string                    `json:"buildTags"`
+	Features         map[string]bool             `json:"features"`
+	Chains           []string                    `json:"chains"`           // the chains with a compiled-in config
+	SnapshotVersions map[string]SnapshotVersions `json:"snapshotVersions"` // snapshot type -> versions
+}
+
+// SnapshotVersions are the versions of the files of a snapshot type which the binary builds and reads.
+type SnapshotVersions struct {
+	Current      string `json:"current"`
+	MinSupported string `json:"minSupported"`
 }
 
 // AdminAPIImpl data structure to store things needed for admin_* commands.
 type AdminAPIImpl struct {
 	ethBackend rpchelper.ApiBackend
+	db         kv.TemporalRoDB
 }
 
 // NewAdminAPI returns AdminAPIImpl instance.
-func NewAdminAPI(eth rpchelper.ApiBackend) *AdminAPIImpl {
+func NewAdminAPI(eth rpchelper.ApiBackend, db kv.TemporalRoDB) *AdminAPIImpl {
 	return &AdminAPIImpl{
 		ethBackend: eth,
+		db:         db,
 	}
 }
 
@@ -79,3 +130,92 @@ func (api *AdminAPIImpl) AddPeer(ctx context.Context, url string) (bool, error)
 	}
 	return result.Success, nil
 }
+
+func (api *AdminAPIImpl) FilesEvents(ctx context.Context) ([]state.FilesEvent, error) {
+	db, ok := api.db.(state.HasAgg)
+	if !ok {
+		return nil, errors.New("files events are not available on a remote db")
+	}
+	agg, ok := db.Agg().(*state.Aggregator)
+	if !ok || agg == nil {
+		return nil, errors.New("files events are not available on a remote db")
+	}
+	return agg.FilesEvents(), nil
+}
+
+func (api *AdminAPIImpl) SetLogLevel(ctx context.Context, level string) (bool, error) {
+	lvl, err := parseLogLevel(level)
+	if err != nil {
+		return false, err
+	}
+	log.SetLvl(lvl)
+	log.Info("[rpc] log level changed", "level", lvl)
+	return true, nil
+}
+
+func (api *AdminAPIImpl) SetLogTarget(ctx context.Context, target string, level string) (map[string]string, error) {
+	if target == "" {
+		return nil, errors.New("log target is required")
+	}
+	if level == "" {
+		log.ResetTargetLvl(target)
+	} else {
+		lvl, err := parseLogLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		log.SetTargetLvl(target, lvl)
+	}
+	targets := map[string]string{}
+	for t, lvl := range log.TargetLvls() {
+		targets[t] = lvl.String()
+	}
+	return targets, nil
+}
+
+// parseLogLevel accepts the names of the levels as well as their numbers, like the verbosity flags
+func parseLogLevel(level string) (log.Lvl, error) {
+	if lvl, err := log.LvlFromString(level); err == nil {
+		return lvl, nil
+	}
+	n, err := strconv.Atoi(level)
+	if err != nil || n < int(log.LvlCrit) || n > int(log.LvlTrace) {
+		return 0, fmt.Errorf("unknown log level: %s", level)
+	}
+	return log.Lvl(n), nil
+}
+
+func (api *AdminAPIImpl) BuildInfo(ctx context.Context) (*BuildInfo, error) {
+	info := &BuildInfo{
+		Version:   params.VersionWithMeta,
+		GitCommit: params.GitCommit,
+		GitBranch: params.GitBranch,
+		GitTag:    params.GitTag,
+		GoVersion: runtime.Version(),
+		Os:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		BuildTags: []string{},
+		Features: map[string]bool{
+			"caplin":   true, // the embedded consensus layer is always compiled in, it is enabled by --internalcl
+			"silkworm": params.SilkwormSupported,
+		},
+		Chains:           slices.Clone(networkname.All),
+		SnapshotVersions: map[string]SnapshotVersions{},
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			if setting.Key == "-tags" && setting.Value != "" {
+				info.BuildTags = strings.Split(setting.Value, ",")
+			}
+		}
+	}
+	info.Features["sqlite"] = !slices.Contains(info.BuildTags, "nosqlite")
+	info.Features["debug"] = slices.Contains(info.BuildTags, "debug")
+	for _, t := range snaptype.RegisteredTypes() {
+		info.SnapshotVersions[t.Name()] = SnapshotVersions{
+			Current:      t.Versions().Current.String(),
+			MinSupported: t.Versions().MinSupported.String(),
+		}
+	}
+	return info, nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/erigontech/erigon/consensus"
 	"github.com/erigontech/erigon/polygon/bor"
 	"github.com/erigontech/erigon/polygon/bor/valset"
+	"github.com/erigontech/erigon/polygon/heimdall"
 	"github.com/erigontech/erigon/rpc"
 )
 
@@ -42,6 +43,11 @@ type BorAPI interface {
 	GetSnapshotProposer(blockNrOrHash *rpc.BlockNumberOrHash) (common.Address, error)
 	GetSnapshotProposerSequence(blockNrOrHash *rpc.BlockNumberOrHash) (BlockSigners, error)
 	GetRootHash(start uint64, end uint64) (string, error)
+
+	// Bor state sync events (see ./bor_state_sync.go)
+	GetStateSyncEvents(ctx context.Context, fromId uint64, limit *uint64) ([]*heimdall.EventRecordWithTime, error)
+	GetStateSyncEventsByBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*heimdall.EventRecordWithTime, error)
+	GetStateSyncEventsByTxHash(ctx context.Context, txHash common.Hash) ([]*heimdall.EventRecordWithTime, error)
 }
 
 type spanProducersReader interface {
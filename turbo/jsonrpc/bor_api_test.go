@@ -22,7 +22,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/polygon/bor/valset"
+	"github.com/erigontech/erigon/polygon/heimdall"
 )
 
 func TestUseSpanProducersReader(t *testing.T) {
@@ -42,3 +46,48 @@ type mockSpanProducersReader struct{}
 func (m mockSpanProducersReader) Producers(context.Context, uint64) (*valset.ValidatorSet, error) {
 	panic("mock")
 }
+
+func TestGetStateSyncEventsPagination(t *testing.T) {
+	ctx := context.Background()
+	api := NewBorAPI(NewBaseApi(nil, nil, nil, false, 0, nil, datadir.Dirs{}, nil), nil, nil)
+	_, err := api.GetStateSyncEvents(ctx, 1, nil)
+	require.ErrorIs(t, err, errStateSyncEventsByIdUnsupported)
+
+	reader := &mockStateSyncEventsReader{}
+	api = NewBorAPI(NewBaseApi(nil, nil, nil, false, 0, nil, datadir.Dirs{}, reader), nil, nil)
+	events, err := api.GetStateSyncEvents(ctx, 7, nil)
+	require.NoError(t, err)
+	require.Len(t, events, defaultStateSyncEventsLimit)
+	require.Equal(t, uint64(7), events[0].ID)
+
+	limit := uint64(3)
+	events, err = api.GetStateSyncEvents(ctx, 7, &limit)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	for _, limit := range []uint64{0, maxStateSyncEventsLimit + 1} {
+		_, err = api.GetStateSyncEvents(ctx, 7, &limit)
+		require.Error(t, err)
+	}
+}
+
+var _ bridgeReader = &mockStateSyncEventsReader{}
+var _ stateSyncEventsByIdReader = &mockStateSyncEventsReader{}
+
+type mockStateSyncEventsReader struct{}
+
+func (m *mockStateSyncEventsReader) Events(context.Context, uint64) ([]*types.Message, error) {
+	panic("mock")
+}
+
+func (m *mockStateSyncEventsReader) EventTxnLookup(context.Context, common.Hash) (uint64, bool, error) {
+	panic("mock")
+}
+
+func (m *mockStateSyncEventsReader) EventsByIdRange(_ context.Context, fromId uint64, limit uint64) ([]*heimdall.EventRecordWithTime, error) {
+	events := make([]*heimdall.EventRecordWithTime, 0, limit)
+	for id := fromId; id < fromId+limit; id++ {
+		events = append(events, &heimdall.EventRecordWithTime{EventRecord: heimdall.EventRecord{ID: id}})
+	}
+	return events, nil
+}
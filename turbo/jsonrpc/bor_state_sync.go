@@ -0,0 +1,145 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/polygon/heimdall"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/rpchelper"
+)
+
+const (
+	defaultStateSyncEventsLimit = 50 // same as the heimdall event record list endpoint
+	maxStateSyncEventsLimit     = 1000
+)
+
+var errStateSyncEventsByIdUnsupported = errors.New("state sync events by id need the polygon bridge store of this node")
+
+// stateSyncEventsByIdReader is implemented by the bridge readers which have a store with all the events, the remote
+// bridge reader only serves the events of a block
+type stateSyncEventsByIdReader interface {
+	EventsByIdRange(ctx context.Context, fromId uint64, limit uint64) ([]*heimdall.EventRecordWithTime, error)
+}
+
+// GetStateSyncEvents returns at most limit state sync events with ids starting from fromId, ordered by id.
+// The next page starts from the id following the last returned one.
+func (api *BorImpl) GetStateSyncEvents(ctx context.Context, fromId uint64, limit *uint64) ([]*heimdall.EventRecordWithTime, error) {
+	pageSize := uint64(defaultStateSyncEventsLimit)
+	if limit != nil {
+		if *limit == 0 || *limit > maxStateSyncEventsLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxStateSyncEventsLimit)
+		}
+		pageSize = *limit
+	}
+
+	reader, ok := api.bridgeReader.(stateSyncEventsByIdReader)
+	if !api.useBridgeReader || !ok {
+		return nil, errStateSyncEventsByIdUnsupported
+	}
+
+	events, err := reader.EventsByIdRange(ctx, fromId, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*heimdall.EventRecordWithTime{}
+	}
+
+	return events, nil
+}
+
+// GetStateSyncEventsByBlock returns the state sync events committed in the given block
+func (api *BorImpl) GetStateSyncEventsByBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*heimdall.EventRecordWithTime, error) {
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, blockHash, _, err := rpchelper.GetCanonicalBlockNumber(ctx, blockNrOrHash, tx, api._blockReader, api.filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.blockStateSyncEvents(ctx, tx, blockHash, blockNum)
+}
+
+// GetStateSyncEventsByTxHash returns the state sync events committed by the given state sync transaction
+func (api *BorImpl) GetStateSyncEventsByTxHash(ctx context.Context, txHash common.Hash) ([]*heimdall.EventRecordWithTime, error) {
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var blockNum uint64
+	var ok bool
+	if api.useBridgeReader {
+		blockNum, ok, err = api.bridgeReader.EventTxnLookup(ctx, txHash)
+	} else {
+		blockNum, ok, err = api._blockReader.EventLookup(ctx, tx, txHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	blockHash, ok, err := api._blockReader.CanonicalHash(ctx, tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errUnknownBlock
+	}
+
+	return api.blockStateSyncEvents(ctx, tx, blockHash, blockNum)
+}
+
+func (api *BorImpl) blockStateSyncEvents(ctx context.Context, tx kv.Tx, blockHash common.Hash, blockNum uint64) ([]*heimdall.EventRecordWithTime, error) {
+	chainConfig, err := api.chainConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if chainConfig.Bor == nil {
+		return nil, errors.New("state sync events are only available on bor chains")
+	}
+
+	msgs, err := api.stateSyncEvents(ctx, tx, blockHash, blockNum, chainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*heimdall.EventRecordWithTime, 0, len(msgs))
+	for _, msg := range msgs {
+		var event heimdall.EventRecordWithTime
+		if err := event.UnmarshallBytes(msg.Data()); err != nil {
+			return nil, fmt.Errorf("block %d: %w", blockNum, err)
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
@@ -29,17 +29,22 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/kvcache"
 	"github.com/erigontech/erigon-lib/kv/order"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/kv/stream"
 	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
 	tracersConfig "github.com/erigontech/erigon/eth/tracers/config"
+	"github.com/erigontech/erigon/ethdb/prune"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/turbo/adapter/ethapi"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 )
 
 var dumper = spew.ConfigState{Indent: "    "}
@@ -71,7 +76,7 @@ func TestTraceBlockByNumber(t *testing.T) {
 	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
 	baseApi := NewBaseApi(nil, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil)
 	ethApi := NewEthAPI(baseApi, m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
-	api := NewPrivateDebugAPI(baseApi, m.DB, 0)
+	api := NewPrivateDebugAPI(baseApi, m.DB, 0, 0, log.New())
 	for _, tt := range debugTraceTransactionTests {
 		var buf bytes.Buffer
 		stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
@@ -119,10 +124,31 @@ func TestTraceBlockByNumber(t *testing.T) {
 	}
 }
 
+func TestTraceBlockByNumberPrunedHistory(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
+	// the prune command dropped the history before block 5
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(m.Ctx, m.BlockReader))
+	err := m.DB.Update(m.Ctx, func(tx kv.RwTx) error {
+		start, err := txNumsReader.Min(tx, 5)
+		if err != nil {
+			return err
+		}
+		return prune.SetHistoryStart(tx, start)
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+	err = api.TraceBlockByNumber(m.Ctx, rpc.BlockNumber(4), &tracersConfig.TraceConfig{}, stream)
+	require.ErrorContains(t, err, "history has been pruned")
+	require.NoError(t, api.TraceBlockByNumber(m.Ctx, rpc.BlockNumber(5), &tracersConfig.TraceConfig{}, stream))
+}
+
 func TestTraceBlockByHash(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
 	ethApi := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
-	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
 	for _, tt := range debugTraceTransactionTests {
 		var buf bytes.Buffer
 		stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
@@ -153,7 +179,7 @@ func TestTraceBlockByHash(t *testing.T) {
 
 func TestTraceTransaction(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
-	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
 	for _, tt := range debugTraceTransactionTests {
 		var buf bytes.Buffer
 		stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
@@ -182,7 +208,7 @@ func TestTraceTransaction(t *testing.T) {
 
 func TestTraceTransactionNoRefund(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
-	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
 	for _, tt := range debugTraceTransactionNoRefundTests {
 		var buf bytes.Buffer
 		stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
@@ -212,7 +238,7 @@ func TestTraceTransactionNoRefund(t *testing.T) {
 
 func TestStorageRangeAt(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
-	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
 	t.Run("invalid addr", func(t *testing.T) {
 		var block4 *types.Block
 		var err error
@@ -306,7 +332,7 @@ func TestStorageRangeAt(t *testing.T) {
 
 func TestAccountRange(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
-	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
 
 	t.Run("valid account", func(t *testing.T) {
 		addr := common.HexToAddress("0x537e697c7ab75a26f9ecf0ce810e3154dfcaaf55")
@@ -365,7 +391,7 @@ func TestAccountRange(t *testing.T) {
 
 func TestGetModifiedAccountsByNumber(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
-	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
 
 	t.Run("correct input", func(t *testing.T) {
 		n, n2 := rpc.BlockNumber(1), rpc.BlockNumber(2)
@@ -464,7 +490,7 @@ func TestMapTxNum2BlockNum(t *testing.T) {
 
 func TestAccountAt(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
-	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
 
 	var blockHash0, blockHash1, blockHash3, blockHash10, blockHash12 common.Hash
 	_ = m.DB.View(m.Ctx, func(tx kv.Tx) error {
@@ -524,3 +550,144 @@ func TestAccountAt(t *testing.T) {
 		require.Equal(0, int(results.Nonce))
 	})
 }
+
+func TestGetStateDiff(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
+
+	t.Run("block", func(t *testing.T) {
+		require := require.New(t)
+		n := rpc.BlockNumber(1)
+		modified, err := api.GetModifiedAccountsByNumber(m.Ctx, n, nil)
+		require.NoError(err)
+
+		result, err := api.GetStateDiff(m.Ctx, rpc.BlockNumberOrHashWithNumber(n), nil, nil, 0)
+		require.NoError(err)
+		require.Nil(result.Next)
+		var addrs []common.Address
+		for _, a := range result.Accounts {
+			addrs = append(addrs, a.Address)
+			require.NotEqual(a.Before, a.After)
+		}
+		require.ElementsMatch(modified, addrs)
+	})
+	t.Run("txn", func(t *testing.T) {
+		require := require.New(t)
+		n, txIndex := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(1)), hexutil.Uint64(0)
+		result, err := api.GetStateDiff(m.Ctx, n, &txIndex, nil, 0)
+		require.NoError(err)
+		require.NotEmpty(result.Accounts)
+		for _, a := range result.Accounts {
+			if a.Op == StateDiffUpdated {
+				require.NotNil(a.Before)
+				require.NotNil(a.After)
+			}
+		}
+
+		txIndex = 1024
+		_, err = api.GetStateDiff(m.Ctx, n, &txIndex, nil, 0)
+		require.Error(err)
+	})
+	t.Run("pagination", func(t *testing.T) {
+		require := require.New(t)
+		n := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(7))
+		full, err := api.GetStateDiff(m.Ctx, n, nil, nil, 0)
+		require.NoError(err)
+		require.Nil(full.Next)
+		require.NotEmpty(full.Storage)
+		require.NotEmpty(full.Code)
+
+		paged := &StateDiffResult{}
+		var start hexutility.Bytes
+		for pages := 0; ; pages++ {
+			result, err := api.GetStateDiff(m.Ctx, n, nil, start, 3)
+			require.NoError(err)
+			require.LessOrEqual(result.len(), 3)
+			paged.Accounts = append(paged.Accounts, result.Accounts...)
+			paged.Storage = append(paged.Storage, result.Storage...)
+			paged.Code = append(paged.Code, result.Code...)
+			if result.Next == nil {
+				require.Equal((full.len()+2)/3-1, pages)
+				break
+			}
+			start = result.Next
+		}
+		require.Equal(full.Accounts, paged.Accounts)
+		require.Equal(full.Storage, paged.Storage)
+		require.Equal(full.Code, paged.Code)
+	})
+}
+
+func TestDumpState(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
+
+	for _, n := range []rpc.BlockNumber{1, 7, rpc.LatestBlockNumber} {
+		require := require.New(t)
+		full, err := api.DumpState(m.Ctx, rpc.BlockNumberOrHashWithNumber(n), nil, 0, false)
+		require.NoError(err)
+		require.Nil(full.Next)
+		hasher := state.NewStateRootHasher(t.TempDir(), log.New())
+		defer hasher.Close()
+		for _, account := range full.Accounts {
+			require.NoError(hasher.Add(account))
+		}
+		root, err := hasher.Root()
+		require.NoError(err)
+		require.Equal(full.Root, root, "block %d", full.BlockNumber)
+
+		// pages split the storage of the accounts, hash to the same root
+		paged := state.NewStateRootHasher(t.TempDir(), log.New())
+		defer paged.Close()
+		var start hexutility.Bytes
+		for {
+			result, err := api.DumpState(m.Ctx, rpc.BlockNumberOrHashWithNumber(n), start, 3, true)
+			require.NoError(err)
+			var items int
+			for _, account := range result.Accounts {
+				items += len(account.Storage)
+				require.Empty(account.Code)
+				require.NoError(paged.Add(account))
+			}
+			require.LessOrEqual(items, 3)
+			if result.Next == nil {
+				break
+			}
+			start = result.Next
+		}
+		root, err = paged.Root()
+		require.NoError(err)
+		require.Equal(full.Root, root, "block %d", full.BlockNumber)
+	}
+}
+
+func TestTraceCallWithOverrides(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
+
+	// the geth and the eth_callMany names of the overridden fields
+	for _, blockOverrides := range []string{`{"time": "0x1000"}`, `{"timestamp": "0x1000"}`} {
+		// returns storage slot 1 + TIMESTAMP
+		var config tracersConfig.TraceConfig
+		err := json.Unmarshal([]byte(`{
+			"stateOverrides": {"0x000000000000000000000000000000000000c0de": {
+				"code": "0x600154420160005260206000f3",
+				"stateDiff": {"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000010"}
+			}},
+			"blockOverrides": `+blockOverrides+`
+		}`), &config)
+		require.NoError(t, err)
+
+		to := common.HexToAddress("0xc0de")
+		var buf bytes.Buffer
+		stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+		err = api.TraceCall(m.Ctx, ethapi.CallArgs{To: &to}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), &config, stream)
+		require.NoError(t, err)
+		require.NoError(t, stream.Flush())
+
+		var er ethapi.ExecutionResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &er))
+		require.False(t, er.Failed)
+		require.Equal(t, "0000000000000000000000000000000000000000000000000000000000001010", er.ReturnValue)
+	}
+}
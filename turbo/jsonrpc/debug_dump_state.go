@@ -0,0 +1,94 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/rpchelper"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// StateDumpMaxResults is the maximum number of accounts and storage slots returned by one debug_dumpState call
+const StateDumpMaxResults = 8192
+
+// StateDumpResult is the result of a debug_dumpState API call.
+type StateDumpResult struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// Root is the state root of the block, the complete dump hashes to it
+	Root     common.Hash            `json:"root"`
+	Accounts []*state.DumpedAccount `json:"accounts"`
+	// Next must be passed as `start` to get the next page of the dump, nil if the state is dumped completely
+	Next hexutility.Bytes `json:"next"`
+}
+
+// DumpState implements debug_dumpState. Returns the complete state as of the end of the block: the accounts ordered by
+// address, each of them with its code and its storage ordered by key. The state is returned in pages of `maxResults`
+// accounts and storage slots, the `next` of the result must be passed as `start` to get the next page. An account whose
+// storage spans several pages is returned in each of them, with the next part of its storage.
+func (api *PrivateDebugAPIImpl) DumpState(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, start hexutility.Bytes, maxResults int, excludeCode bool) (*StateDumpResult, error) {
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, _, _, err := rpchelper.GetBlockNumber(ctx, blockNrOrHash, tx, api._blockReader, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	header, err := api._blockReader.HeaderByNumber(ctx, tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", blockNum)
+	}
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
+	toTxNum, err := txNumsReader.Min(tx, blockNum+1)
+	if err != nil {
+		return nil, err
+	}
+	if historyStart := tx.HistoryStartFrom(kv.AccountsDomain); toTxNum < historyStart {
+		return nil, fmt.Errorf("state history of block %d is pruned: available from txNum %d", blockNum, historyStart)
+	}
+
+	if maxResults <= 0 || maxResults > StateDumpMaxResults {
+		maxResults = StateDumpMaxResults
+	}
+	dumper, err := state.NewStateDumper(tx, txNumsReader, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	result := &StateDumpResult{BlockNumber: hexutil.Uint64(blockNum), Root: header.Root, Accounts: []*state.DumpedAccount{}}
+	result.Next, err = dumper.Walk(start, maxResults, excludeCode, func(account *state.DumpedAccount) error {
+		result.Accounts = append(result.Accounts, account)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
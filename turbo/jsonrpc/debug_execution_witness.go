@@ -0,0 +1,112 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv/dbutils"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// ExecutionWitness implements debug_executionWitness. Returns the witness of the block, generated from the state of its parent.
+func (api *PrivateDebugAPIImpl) ExecutionWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.ExecutionWitness, error) {
+	var result *types.ExecutionWitness
+	err := api.withBlockWitness(ctx, api.db, blockNrOrHash, 0, true, api.maxGetProofRewindBlockCount, api.logger, func(w *blockWitness) error {
+		var err error
+		result, err = w.executionWitness()
+		return err
+	})
+	return result, err
+}
+
+func (w *blockWitness) executionWitness() (*types.ExecutionWitness, error) {
+	res := &types.ExecutionWitness{
+		State:   []hexutility.Bytes{},
+		Codes:   []hexutility.Bytes{},
+		Keys:    []hexutility.Bytes{},
+		Headers: []hexutility.Bytes{},
+	}
+	if w == nil {
+		return res, nil
+	}
+
+	seenNodes := make(map[libcommon.Hash]struct{})
+	for _, key := range w.touchedHashedKeys {
+		var proof [][]byte
+		var err error
+		if len(key) == length.Hash {
+			proof, err = w.trie.Prove(key, 0, false)
+		} else {
+			addrHash, _, storageHash := dbutils.ParseCompositeStorageKey(key)
+			proof, err = w.trie.Prove(append(addrHash[:], storageHash[:]...), 0, true)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("collecting trie nodes of %x: %w", key, err)
+		}
+		for _, node := range proof {
+			nodeHash := crypto.Keccak256Hash(node)
+			if _, ok := seenNodes[nodeHash]; ok {
+				continue
+			}
+			seenNodes[nodeHash] = struct{}{}
+			res.State = append(res.State, node)
+		}
+	}
+
+	codeHashes := make([]libcommon.Hash, 0, len(w.codeReads))
+	for codeHash := range w.codeReads {
+		codeHashes = append(codeHashes, codeHash)
+	}
+	sort.Slice(codeHashes, func(i, j int) bool { return bytes.Compare(codeHashes[i][:], codeHashes[j][:]) < 0 })
+	for _, codeHash := range codeHashes {
+		res.Codes = append(res.Codes, w.codeReads[codeHash].Code)
+	}
+
+	seenKeys := make(map[string]struct{})
+	addKey := func(key []byte) {
+		if _, ok := seenKeys[string(key)]; ok {
+			return
+		}
+		seenKeys[string(key)] = struct{}{}
+		res.Keys = append(res.Keys, libcommon.CopyBytes(key))
+	}
+	for _, key := range w.touchedPlainKeys {
+		addKey(key[:length.Addr])
+		if len(key) > length.Addr {
+			addKey(key[len(key)-length.Hash:])
+		}
+	}
+
+	for _, header := range w.ancestors {
+		encoded, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			return nil, err
+		}
+		res.Headers = append(res.Headers, encoded)
+	}
+	return res, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	witnesstypes "github.com/erigontech/erigon-lib/types/witness"
+	"github.com/erigontech/erigon/core/types"
+)
+
+func TestExecutionWitnessFormat(t *testing.T) {
+	genesis, err := (*blockWitness)(nil).executionWitness()
+	require.NoError(t, err)
+	require.Equal(t, &types.ExecutionWitness{State: []hexutility.Bytes{}, Codes: []hexutility.Bytes{}, Keys: []hexutility.Bytes{}, Headers: []hexutility.Bytes{}}, genesis)
+
+	tr := trie.New(libcommon.Hash{})
+	var hashedKeys [][]byte
+	var plainKeys [][]byte
+	for i := byte(1); i <= 16; i++ {
+		addr := libcommon.Address{i}
+		acc := accounts.NewAccount()
+		acc.Balance = *uint256.NewInt(uint64(i))
+		hashedAddr := crypto.Keccak256(addr[:])
+		tr.UpdateAccount(hashedAddr, &acc)
+		if i%4 == 0 {
+			hashedKeys = append(hashedKeys, hashedAddr)
+			plainKeys = append(plainKeys, addr[:])
+		}
+	}
+	slot := libcommon.Hash{7}
+	plainKeys = append(plainKeys, append(append([]byte{}, plainKeys[0]...), slot[:]...))
+
+	code := []byte{0x60, 0x00}
+	codeHash := crypto.Keccak256Hash(code)
+	parent := &types.Header{Number: big.NewInt(9)}
+	ancestor := &types.Header{Number: big.NewInt(8)}
+
+	w := &blockWitness{
+		trie:              tr,
+		touchedHashedKeys: hashedKeys,
+		touchedPlainKeys:  plainKeys,
+		codeReads:         map[libcommon.Hash]witnesstypes.CodeWithHash{codeHash: {Code: code, CodeHash: codeHash}},
+		ancestors:         []*types.Header{parent, ancestor},
+	}
+	res, err := w.executionWitness()
+	require.NoError(t, err)
+
+	// the root node comes first and every node is only listed once.
+	root := tr.Hash()
+	require.Equal(t, root, crypto.Keccak256Hash(res.State[0]))
+	seen := make(map[libcommon.Hash]struct{})
+	for _, node := range res.State {
+		h := crypto.Keccak256Hash(node)
+		require.NotContains(t, seen, h)
+		seen[h] = struct{}{}
+	}
+	require.Greater(t, len(res.State), 1)
+
+	require.Equal(t, []hexutility.Bytes{code}, res.Codes)
+	require.Equal(t, []hexutility.Bytes{plainKeys[0], plainKeys[1], plainKeys[2], plainKeys[3], slot[:]}, res.Keys)
+
+	encodedParent, err := rlp.EncodeToBytes(parent)
+	require.NoError(t, err)
+	require.Len(t, res.Headers, 2)
+	require.Equal(t, hexutility.Bytes(encodedParent), res.Headers[0])
+}
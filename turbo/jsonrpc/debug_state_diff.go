@@ -0,0 +1,247 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/rpchelper"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// StateDiffMaxResults is the maximum number of changes returned by one debug_getStateDiff call
+const StateDiffMaxResults = 8192
+
+const (
+	StateDiffCreated = "created"
+	StateDiffUpdated = "updated"
+	StateDiffDeleted = "deleted"
+)
+
+// stateDiffDomains - the order in which changes are returned (and paginated)
+var stateDiffDomains = []kv.Domain{kv.AccountsDomain, kv.StorageDomain, kv.CodeDomain}
+
+// StateDiffResult is the result of a debug_getStateDiff API call.
+type StateDiffResult struct {
+	Accounts []AccountDiff `json:"accounts"`
+	Storage  []StorageDiff `json:"storage"`
+	Code     []CodeDiff    `json:"code"`
+	// Next must be passed as `start` to get the next page of changes, nil if there are no more changes
+	Next hexutility.Bytes `json:"next"`
+}
+
+type AccountDiff struct {
+	Address common.Address    `json:"address"`
+	Op      string            `json:"op"`     // created, updated or deleted
+	Before  *AccountDiffState `json:"before"` // nil if the account was created
+	After   *AccountDiffState `json:"after"`  // nil if the account was deleted
+}
+
+type AccountDiffState struct {
+	Balance     hexutil.Big    `json:"balance"`
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	CodeHash    common.Hash    `json:"codeHash"`
+	Incarnation hexutil.Uint64 `json:"incarnation"`
+}
+
+type StorageDiff struct {
+	Address common.Address `json:"address"`
+	Slot    common.Hash    `json:"slot"`
+	Before  common.Hash    `json:"before"`
+	After   common.Hash    `json:"after"`
+}
+
+type CodeDiff struct {
+	Address common.Address   `json:"address"`
+	Op      string           `json:"op"` // created, updated or deleted
+	Before  hexutility.Bytes `json:"before"`
+	After   hexutility.Bytes `json:"after"`
+}
+
+func (r *StateDiffResult) len() int { return len(r.Accounts) + len(r.Storage) + len(r.Code) }
+
+// GetStateDiff implements debug_getStateDiff. Returns the write-set of the transaction `txIndex` of the block,
+// or of the whole block (including block rewards and system calls) if `txIndex` is nil: the values of accounts,
+// storage slots and code before and after it. The write-sets produced by the execution are persisted in the state
+// history, so nothing is re-executed.
+// Changes are returned in pages of `maxResults`, the `next` of the result must be passed as `start` to get the next page.
+func (api *PrivateDebugAPIImpl) GetStateDiff(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, txIndex *hexutil.Uint64, start hexutility.Bytes, maxResults int) (*StateDiffResult, error) {
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, _, _, err := rpchelper.GetBlockNumber(ctx, blockNrOrHash, tx, api._blockReader, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
+	minTxNum, err := txNumsReader.Min(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	maxTxNum, err := txNumsReader.Max(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	//[from, to)
+	fromTxNum, toTxNum := minTxNum, maxTxNum+1
+	if txIndex != nil {
+		txCount := maxTxNum - minTxNum - 1 // without system txs in the beginning and the end of block
+		if uint64(*txIndex) >= txCount {
+			return nil, fmt.Errorf("transaction index %d out of range: block %d has %d transactions", *txIndex, blockNum, txCount)
+		}
+		fromTxNum = minTxNum + uint64(*txIndex) + 1 //+1 for system txn in the beginning of block
+		toTxNum = fromTxNum + 1
+	}
+	if historyStart := tx.HistoryStartFrom(kv.AccountsDomain); fromTxNum < historyStart {
+		return nil, fmt.Errorf("state history of block %d is pruned: available from txNum %d", blockNum, historyStart)
+	}
+
+	if maxResults <= 0 || maxResults > StateDiffMaxResults {
+		maxResults = StateDiffMaxResults
+	}
+	return stateDiff(tx, fromTxNum, toTxNum, start, maxResults)
+}
+
+// stateDiff returns the changes made by txNums [fromTxNum, toTxNum) starting from the `start` cursor: the domain byte and the key
+func stateDiff(tx kv.TemporalTx, fromTxNum, toTxNum uint64, start []byte, maxResults int) (*StateDiffResult, error) {
+	result := &StateDiffResult{Accounts: []AccountDiff{}, Storage: []StorageDiff{}, Code: []CodeDiff{}}
+	for _, domain := range stateDiffDomains {
+		var startKey []byte
+		if len(start) > 0 {
+			if kv.Domain(start[0]) > domain {
+				continue
+			}
+			if kv.Domain(start[0]) == domain {
+				startKey = start[1:]
+			}
+		}
+
+		done, err := stateDiffOfDomain(tx, domain, fromTxNum, toTxNum, startKey, maxResults, result)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+	}
+	return result, nil
+}
+
+// stateDiffOfDomain appends the changes of the domain to the result, returns true if the result is full
+func stateDiffOfDomain(tx kv.TemporalTx, domain kv.Domain, fromTxNum, toTxNum uint64, startKey []byte, maxResults int, result *StateDiffResult) (done bool, err error) {
+	// history of [from, to) has the keys changed by these txNums and their values before `from`
+	it, err := tx.HistoryRange(domain, int(fromTxNum), int(toTxNum), order.Asc, kv.Unlim)
+	if err != nil {
+		return false, err
+	}
+	defer it.Close()
+	for it.HasNext() {
+		k, before, err := it.Next()
+		if err != nil {
+			return false, err
+		}
+		if bytes.Compare(k, startKey) < 0 {
+			continue
+		}
+		after, _, err := tx.GetAsOf(domain, k, toTxNum)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(before, after) { // changed and changed back
+			continue
+		}
+		if result.len() == maxResults {
+			result.Next = append([]byte{byte(domain)}, k...)
+			return true, nil
+		}
+		if err := result.add(domain, k, before, after); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (r *StateDiffResult) add(domain kv.Domain, k, before, after []byte) error {
+	switch domain {
+	case kv.AccountsDomain:
+		d := AccountDiff{Address: common.BytesToAddress(k), Op: stateDiffOp(before, after)}
+		var err error
+		if d.Before, err = accountDiffState(before); err != nil {
+			return err
+		}
+		if d.After, err = accountDiffState(after); err != nil {
+			return err
+		}
+		r.Accounts = append(r.Accounts, d)
+	case kv.StorageDomain:
+		var b, a uint256.Int
+		b.SetBytes(before)
+		a.SetBytes(after)
+		r.Storage = append(r.Storage, StorageDiff{
+			Address: common.BytesToAddress(k[:length.Addr]),
+			Slot:    common.BytesToHash(k[length.Addr:]),
+			Before:  b.Bytes32(),
+			After:   a.Bytes32(),
+		})
+	case kv.CodeDomain:
+		r.Code = append(r.Code, CodeDiff{Address: common.BytesToAddress(k), Op: stateDiffOp(before, after), Before: common.Copy(before), After: common.Copy(after)})
+	default:
+		return fmt.Errorf("unexpected domain %s", domain)
+	}
+	return nil
+}
+
+func stateDiffOp(before, after []byte) string {
+	switch {
+	case len(before) == 0:
+		return StateDiffCreated
+	case len(after) == 0:
+		return StateDiffDeleted
+	default:
+		return StateDiffUpdated
+	}
+}
+
+func accountDiffState(v []byte) (*AccountDiffState, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	var a accounts.Account
+	if err := accounts.DeserialiseV3(&a, v); err != nil {
+		return nil, err
+	}
+	s := &AccountDiffState{Nonce: hexutil.Uint64(a.Nonce), CodeHash: a.CodeHash, Incarnation: hexutil.Uint64(a.Incarnation)}
+	s.Balance.ToInt().Set(a.Balance.ToBig())
+	return s, nil
+}
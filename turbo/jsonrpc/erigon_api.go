@@ -21,6 +21,7 @@ import (
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/filters"
@@ -48,6 +49,13 @@ type ErigonAPI interface {
 	GetLatestLogs(ctx context.Context, crit filters.FilterCriteria, logOptions filters.LogFilterOptions) (types.ErigonLogs, error)
 	// Gets cannonical block receipt through hash. If the block is not cannonical returns error
 	GetBlockReceiptsByBlockHash(ctx context.Context, cannonicalBlockHash common.Hash) ([]map[string]interface{}, error)
+	GetBlockReceiptsRange(ctx context.Context, fromBlock rpc.BlockNumber, toBlock rpc.BlockNumber) ([][]map[string]interface{}, error)
+
+	// Transaction related (see ./erigon_transactions.go)
+	GetTransactionsBySender(ctx context.Context, sender common.Address, cursor *hexutil.Uint64, pageSize *uint64) (*TransactionsBySender, error)
+
+	// Storage related (see ./erigon_storage.go)
+	GetStorageRange(ctx context.Context, contract common.Address, startKey hexutility.Bytes, maxResults int, blockNrOrHash rpc.BlockNumberOrHash) (StorageRangeResult, error)
 
 	// NodeInfo returns a collection of metadata known about the host.
 	NodeInfo(ctx context.Context) ([]p2p.NodeInfo, error)
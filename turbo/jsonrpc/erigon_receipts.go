@@ -29,7 +29,6 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cmd/state/exec3"
 	"github.com/erigontech/erigon/core/types"
-	"github.com/erigontech/erigon/eth/ethutils"
 	"github.com/erigontech/erigon/eth/filters"
 	bortypes "github.com/erigontech/erigon/polygon/bor/types"
 	"github.com/erigontech/erigon/rpc"
@@ -361,33 +360,60 @@ func (api *ErigonImpl) GetBlockReceiptsByBlockHash(ctx context.Context, cannonic
 	if err != nil {
 		return nil, err
 	}
-	receipts, err := api.getReceipts(ctx, tx, block)
+	return api.marshalBlockReceipts(ctx, tx, chainConfig, block)
+}
+
+// maxBlockReceiptsRange is the max number of blocks of erigon_getBlockReceiptsRange, the receipts of every block are
+// generated by its execution
+const maxBlockReceiptsRange = 128
+
+// GetBlockReceiptsRange implements erigon_getBlockReceiptsRange. Returns the receipts of the canonical blocks
+// [fromBlock, toBlock], one array per block, the way eth_getBlockReceipts returns them.
+func (api *ErigonImpl) GetBlockReceiptsRange(ctx context.Context, fromBlock rpc.BlockNumber, toBlock rpc.BlockNumber) ([][]map[string]interface{}, error) {
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, _, _, err := rpchelper.GetBlockNumber(ctx, rpc.BlockNumberOrHashWithNumber(fromBlock), tx, api._blockReader, api.filters)
 	if err != nil {
-		return nil, fmt.Errorf("getReceipts error: %w", err)
+		return nil, err
+	}
+	to, _, _, err := rpchelper.GetBlockNumber(ctx, rpc.BlockNumberOrHashWithNumber(toBlock), tx, api._blockReader, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("fromBlock %d is after toBlock %d", from, to)
+	}
+	if to-from >= maxBlockReceiptsRange {
+		return nil, fmt.Errorf("range of %d blocks exceeds the limit of %d", to-from+1, maxBlockReceiptsRange)
 	}
 
-	result := make([]map[string]interface{}, 0, len(receipts))
-	for _, receipt := range receipts {
-		txn := block.Transactions()[receipt.TransactionIndex]
-		result = append(result, ethutils.MarshalReceipt(receipt, txn, chainConfig, block.HeaderNoCopy(), txn.Hash(), true))
+	chainConfig, err := api.chainConfig(ctx, tx)
+	if err != nil {
+		return nil, err
 	}
 
-	if chainConfig.Bor != nil {
-		events, err := api.stateSyncEvents(ctx, tx, block.Hash(), blockNum, chainConfig)
+	result := make([][]map[string]interface{}, 0, to-from+1)
+	for blockNum := from; blockNum <= to; blockNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := api.blockByNumberWithSenders(ctx, tx, blockNum)
 		if err != nil {
 			return nil, err
 		}
-
-		if len(events) != 0 {
-			borReceipt, err := api.borReceiptGenerator.GenerateBorReceipt(ctx, tx, block, events, chainConfig, receipts)
-			if err != nil {
-				return nil, err
-			}
-
-			result = append(result, ethutils.MarshalReceipt(borReceipt, bortypes.NewBorTransaction(), chainConfig, block.HeaderNoCopy(), borReceipt.TxHash, false))
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", blockNum)
 		}
+		receipts, err := api.marshalBlockReceipts(ctx, tx, chainConfig, block)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", blockNum, err)
+		}
+		result = append(result, receipts)
 	}
-
 	return result, nil
 }
 
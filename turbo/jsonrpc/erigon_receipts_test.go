@@ -235,6 +235,44 @@ func TestGetBlockReceiptsByBlockHash(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGetBlockReceiptsRange(t *testing.T) {
+	acc1Addr := libcommon.HexToAddress("0x703c4b2bd70c169f5717101caee543299fc946c7")
+	signer := types.LatestSignerForChainID(nil)
+	generator := func(i int, block *core.BlockGen) {
+		for j := 0; j <= i%3; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), acc1Addr, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, testKey)
+			block.AddTx(tx)
+		}
+	}
+	m := mockWithGenerator(t, 5, generator)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	ethApi := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+	ctx := context.Background()
+
+	receipts, err := api.GetBlockReceiptsRange(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Len(t, receipts, 5)
+	for i, blockReceipts := range receipts {
+		blockNum := rpc.BlockNumber(i + 1)
+		require.Len(t, blockReceipts, i%3+1)
+		expected, err := ethApi.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(blockNum))
+		require.NoError(t, err)
+		a, _ := json.Marshal(blockReceipts)
+		b, _ := json.Marshal(expected)
+		require.JSONEq(t, string(b), string(a))
+	}
+
+	receipts, err = api.GetBlockReceiptsRange(ctx, rpc.LatestBlockNumber, rpc.LatestBlockNumber)
+	require.NoError(t, err)
+	require.Len(t, receipts, 1)
+	require.Len(t, receipts[0], 2)
+
+	_, err = api.GetBlockReceiptsRange(ctx, 3, 2)
+	require.ErrorContains(t, err, "is after toBlock")
+	_, err = api.GetBlockReceiptsRange(ctx, 0, rpc.BlockNumber(maxBlockReceiptsRange))
+	require.ErrorContains(t, err, "exceeds the limit")
+}
+
 // newTestBackend creates a chain with a number of explicitly defined blocks and
 // wraps it into a mock backend.
 func mockWithGenerator(t *testing.T, blocks int, generator func(int, *core.BlockGen)) *mock.MockSentry {
@@ -0,0 +1,61 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/rpchelper"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// GetStorageRange implements erigon_getStorageRange. Returns up to maxResults storage slots of the contract,
+// starting from startKey, as they were at the end of the given block.
+func (api *ErigonImpl) GetStorageRange(ctx context.Context, contract common.Address, startKey hexutility.Bytes, maxResults int, blockNrOrHash rpc.BlockNumberOrHash) (StorageRangeResult, error) {
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		return StorageRangeResult{}, errors.New("getStorageRange for pending block not supported")
+	}
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return StorageRangeResult{}, err
+	}
+	defer tx.Rollback()
+
+	blockNumber, _, _, err := rpchelper.GetBlockNumber(ctx, blockNrOrHash, tx, api._blockReader, api.filters)
+	if err != nil {
+		return StorageRangeResult{}, err
+	}
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
+	maxTxNum, err := txNumsReader.Max(tx, blockNumber)
+	if err != nil {
+		return StorageRangeResult{}, err
+	}
+	// as of the txNum right after the last one of the block: the state the block left behind
+	txNum := maxTxNum + 1
+	historyReader := state.NewHistoryReaderV3()
+	historyReader.SetTx(tx)
+	if txNum < historyReader.StateHistoryStartFrom() {
+		return StorageRangeResult{}, state.PrunedError
+	}
+	return storageRangeAt(tx, contract, startKey, txNum, maxResults)
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/rpc"
+)
+
+func TestGetStorageRange(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	debugAPI := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0, 0, log.New())
+	addr := common.HexToAddress("0x537e697c7ab75a26f9ecf0ce810e3154dfcaaf44")
+
+	var latestBlock *types.Block
+	err := m.DB.View(m.Ctx, func(tx kv.Tx) (err error) {
+		latestBlock, err = m.BlockReader.CurrentBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	// the state at the end of a block is the one the first transaction of the next block starts from
+	for n := uint64(0); n < latestBlock.NumberU64(); n++ {
+		var next *types.Block
+		err := m.DB.View(m.Ctx, func(tx kv.Tx) (err error) {
+			next, err = m.BlockReader.BlockByNumber(m.Ctx, tx, n+1)
+			return err
+		})
+		require.NoError(t, err)
+		expect, err := debugAPI.StorageRangeAt(m.Ctx, next.Hash(), 0, addr, nil, 100)
+		require.NoError(t, err)
+		result, err := api.GetStorageRange(m.Ctx, addr, nil, 100, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(n)))
+		require.NoError(t, err)
+		require.Equal(t, expect, result, "block %d", n)
+	}
+
+	latest, err := api.GetStorageRange(m.Ctx, addr, nil, 100, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	require.NoError(t, err)
+	byHash, err := api.GetStorageRange(m.Ctx, addr, nil, 100, rpc.BlockNumberOrHashWithHash(latestBlock.Hash(), true))
+	require.NoError(t, err)
+	require.Equal(t, latest, byHash)
+	require.NotEmpty(t, latest.Storage)
+
+	// paging over the latest state visits the same slots
+	page, err := api.GetStorageRange(m.Ctx, addr, nil, 1, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	require.NoError(t, err)
+	paged := storageMap{}
+	for {
+		for k, v := range page.Storage {
+			paged[k] = v
+		}
+		if page.NextKey == nil {
+			break
+		}
+		page, err = api.GetStorageRange(m.Ctx, addr, page.NextKey.Bytes(), 1, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+		require.NoError(t, err)
+	}
+	require.Equal(t, latest.Storage, paged)
+
+	_, err = api.GetStorageRange(m.Ctx, addr, nil, 100, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber))
+	require.Error(t, err)
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+const (
+	defaultTxsBySenderPageSize = 25
+	maxTxsBySenderPageSize     = 1000
+)
+
+var errTxSenderIndexDisabled = errors.New("transactions by sender are not indexed by this node, see --sync.tx-sender-index")
+
+// TransactionsBySender is a page of the transactions of a sender, ordered by their position in the chain
+type TransactionsBySender struct {
+	Transactions []*RPCTransaction `json:"transactions"`
+	// Next is the cursor of the next page, nil after the last page
+	Next *hexutil.Uint64 `json:"next"`
+}
+
+// GetTransactionsBySender implements erigon_getTransactionsBySender. Returns at most pageSize transactions sent by
+// sender, the first page without a cursor, the next ones with the cursor returned by the previous page.
+func (api *ErigonImpl) GetTransactionsBySender(ctx context.Context, sender common.Address, cursor *hexutil.Uint64, pageSize *uint64) (*TransactionsBySender, error) {
+	limit := uint64(defaultTxsBySenderPageSize)
+	if pageSize != nil {
+		if *pageSize == 0 || *pageSize > maxTxsBySenderPageSize {
+			return nil, fmt.Errorf("pageSize must be between 1 and %d", maxTxsBySenderPageSize)
+		}
+		limit = *pageSize
+	}
+
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	indexProgress, err := stages.GetStageProgress(tx, stages.TxSenderIndex)
+	if err != nil {
+		return nil, err
+	}
+	if indexProgress == 0 {
+		return nil, errTxSenderIndexDisabled
+	}
+	chainConfig, err := api.chainConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
+
+	c, err := tx.CursorDupSort(kv.TxSenderIdx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var from [8]byte
+	if cursor != nil {
+		binary.BigEndian.PutUint64(from[:], uint64(*cursor))
+	}
+	page := &TransactionsBySender{Transactions: []*RPCTransaction{}}
+	var header *types.Header
+	for v, err := c.SeekBothRange(sender[:], from[:]); v != nil || err != nil; _, v, err = c.NextDup() {
+		if err != nil {
+			return nil, err
+		}
+		txNum := binary.BigEndian.Uint64(v)
+		if uint64(len(page.Transactions)) == limit {
+			next := hexutil.Uint64(txNum)
+			page.Next = &next
+			break
+		}
+
+		ok, blockNum, err := txNumsReader.FindBlockNum(tx, txNum)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || blockNum > indexProgress {
+			break
+		}
+		minTxNum, err := txNumsReader.Min(tx, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if txNum <= minTxNum {
+			continue
+		}
+		txIndex := int(txNum - minTxNum - 1)
+		if header == nil || header.Number.Uint64() != blockNum {
+			if header, err = api._blockReader.HeaderByNumber(ctx, tx, blockNum); err != nil {
+				return nil, err
+			}
+			if header == nil {
+				return nil, fmt.Errorf("header %d not found", blockNum)
+			}
+		}
+		txn, err := api._txnReader.TxnByIdxInBlock(ctx, tx, blockNum, txIndex)
+		if err != nil {
+			return nil, err
+		}
+		if txn == nil {
+			continue
+		}
+		// the index is not unwound while the stage is disabled, skip the transactions of the blocks reorged meanwhile
+		txnSender, err := txn.Sender(*types.MakeSigner(chainConfig, blockNum, header.Time))
+		if err != nil {
+			return nil, err
+		}
+		if txnSender != sender {
+			continue
+		}
+		page.Transactions = append(page.Transactions, NewRPCTransaction(txn, header.Hash(), blockNum, uint64(txIndex), header.BaseFee))
+	}
+	return page, nil
+}
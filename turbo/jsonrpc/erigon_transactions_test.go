@@ -0,0 +1,129 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+func TestGetTransactionsBySender(t *testing.T) {
+	acc1Key, _ := crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	acc1Addr := crypto.PubkeyToAddress(acc1Key.PublicKey)
+	acc2Addr := libcommon.Address{2}
+	signer := types.LatestSignerForChainID(nil)
+
+	m := mock.MockWithGenesis(t, &types.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000)}},
+	}, testKey, false, mock.WithTxSenderIndex())
+	// chain A: a transaction of testAddr in every block
+	chainA, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 5, func(i int, block *core.BlockGen) {
+		txn, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), acc1Addr, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, testKey)
+		block.AddTx(txn)
+	})
+	require.NoError(t, err)
+	// chain B, longer: one transaction of testAddr, then the ones of acc1
+	chainB, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 7, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			txn, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), acc1Addr, uint256.NewInt(10000), params.TxGas, nil, nil), *signer, testKey)
+			block.AddTx(txn)
+			return
+		}
+		txn, _ := types.SignTx(types.NewTransaction(block.TxNonce(acc1Addr), acc2Addr, uint256.NewInt(10), params.TxGas, nil, nil), *signer, acc1Key)
+		block.AddTx(txn)
+	})
+	require.NoError(t, err)
+
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	ctx := context.Background()
+	allBySender := func(sender libcommon.Address, pageSize uint64) (txs []*RPCTransaction, pages int) {
+		var cursor *hexutil.Uint64
+		for {
+			page, err := api.GetTransactionsBySender(ctx, sender, cursor, &pageSize)
+			require.NoError(t, err)
+			txs = append(txs, page.Transactions...)
+			pages++
+			if page.Next == nil {
+				return txs, pages
+			}
+			require.Len(t, page.Transactions, int(pageSize))
+			cursor = page.Next
+		}
+	}
+	requireNonces := func(txs []*RPCTransaction, sender libcommon.Address, n int) {
+		require.Len(t, txs, n)
+		for i, txn := range txs {
+			require.Equal(t, sender, txn.From)
+			require.Equal(t, hexutil.Uint64(i), txn.Nonce)
+		}
+	}
+
+	require.NoError(t, m.InsertChain(chainA))
+	txs, pages := allBySender(testAddr, 2)
+	requireNonces(txs, testAddr, 5)
+	require.Equal(t, 3, pages)
+	require.Equal(t, chainA.Blocks[4].Hash(), *txs[4].BlockHash)
+	txs, _ = allBySender(acc1Addr, 2)
+	require.Empty(t, txs)
+
+	// reorg: the transactions of chain A are removed from the index
+	require.NoError(t, m.InsertChain(chainB))
+	txs, _ = allBySender(testAddr, 2)
+	requireNonces(txs, testAddr, 1)
+	txs, pages = allBySender(acc1Addr, 10)
+	requireNonces(txs, acc1Addr, 6)
+	require.Equal(t, 1, pages)
+	err = m.DB.View(ctx, func(tx kv.Tx) error {
+		c, err := tx.CursorDupSort(kv.TxSenderIdx)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		if _, _, err = c.SeekExact(testAddr[:]); err != nil {
+			return err
+		}
+		count, err := c.CountDuplicates()
+		require.Equal(t, uint64(1), count)
+		return err
+	})
+	require.NoError(t, err)
+
+	var tooBig uint64 = maxTxsBySenderPageSize + 1
+	_, err = api.GetTransactionsBySender(ctx, testAddr, nil, &tooBig)
+	require.ErrorContains(t, err, "pageSize must be between")
+}
+
+func TestGetTransactionsBySenderDisabled(t *testing.T) {
+	m := mock.Mock(t)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	_, err := api.GetTransactionsBySender(context.Background(), m.Address, nil, nil)
+	require.ErrorIs(t, err, errTxSenderIndexDisabled)
+}
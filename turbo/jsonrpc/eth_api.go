@@ -38,6 +38,7 @@ import (
 	txpool "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/kvcache"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/types/accounts"
 	"github.com/erigontech/erigon/consensus"
@@ -54,6 +55,7 @@ import (
 	"github.com/erigontech/erigon/turbo/jsonrpc/receipts"
 	"github.com/erigontech/erigon/turbo/rpchelper"
 	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 )
 
 // EthAPI is a collection of functions that are exposed in the
@@ -78,6 +80,9 @@ type EthAPI interface {
 	GetLogs(ctx context.Context, crit ethFilters.FilterCriteria) (types.Logs, error)
 	GetBlockReceipts(ctx context.Context, numberOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error)
 
+	// Withdrawal related (see ./eth_withdrawals.go)
+	GetWithdrawalsByAddress(ctx context.Context, address common.Address, fromBlock *hexutil.Uint64, pageSize *uint64) (*WithdrawalsByAddress, error)
+
 	// Uncle related (see ./eth_uncles.go)
 	GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error)
 	GetUncleByBlockHashAndIndex(ctx context.Context, hash common.Hash, index hexutil.Uint) (map[string]interface{}, error)
@@ -105,6 +110,8 @@ type EthAPI interface {
 	ChainId(ctx context.Context) (hexutil.Uint64, error) /* called eth_protocolVersion elsewhere */
 	ProtocolVersion(_ context.Context) (hexutil.Uint, error)
 	GasPrice(_ context.Context) (*hexutil.Big, error)
+	BlobBaseFee(ctx context.Context) (*hexutil.Big, error)
+	EstimateBlobFee(ctx context.Context, blocks *hexutil.Uint64) (*BlobFeeEstimate, error)
 
 	// Sending related (see ./eth_call.go)
 	Call(ctx context.Context, args ethapi2.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutility.Bytes, error)
@@ -129,6 +136,7 @@ type BaseAPI struct {
 	// all caches are thread-safe
 	stateCache kvcache.Cache
 	blocksLRU  *lru.Cache[common.Hash, *types.Block]
+	headExec   *headExecCache
 
 	filters      *rpchelper.Filters
 	_chainConfig atomic.Pointer[chain.Config]
@@ -165,6 +173,7 @@ func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader serv
 		filters:             f,
 		stateCache:          stateCache,
 		blocksLRU:           blocksLRU,
+		headExec:            newHeadExecCache(),
 		_blockReader:        blockReader,
 		_txnReader:          blockReader,
 		evmCallTimeout:      evmCallTimeout,
@@ -334,6 +343,21 @@ func (api *BaseAPI) checkPruneHistory(ctx context.Context, tx kv.Tx, block uint6
 		// no prune info found
 		return nil
 	}
+	// the history files before it were dropped by the prune command
+	historyStart, err := prune.HistoryStart(tx)
+	if err != nil {
+		return err
+	}
+	if historyStart > 0 {
+		txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
+		blockStart, err := txNumsReader.Min(tx, block)
+		if err != nil {
+			return err
+		}
+		if blockStart < historyStart {
+			return errors.New("history has been pruned for this block")
+		}
+	}
 	if p.History.Enabled() {
 		latest, _, _, err := rpchelper.GetBlockNumber(ctx, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), tx, api._blockReader, api.filters)
 		if err != nil {
@@ -364,7 +388,7 @@ func (api *BaseAPI) pruneMode(tx kv.Tx) (*prune.Mode, error) {
 
 	api._pruneMode.Store(&mode)
 
-	return p, nil
+	return &mode, nil
 }
 
 type bridgeReader interface {
@@ -43,6 +43,7 @@ import (
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/types/accounts"
+	witnesstypes "github.com/erigontech/erigon-lib/types/witness"
 	"github.com/erigontech/erigon/consensus"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/state"
@@ -79,7 +80,7 @@ func (api *APIImpl) Call(ctx context.Context, args ethapi2.CallArgs, blockNrOrHa
 		args.Gas = (*hexutil.Uint64)(&api.GasCap)
 	}
 
-	blockNumber, hash, _, err := rpchelper.GetCanonicalBlockNumber(ctx, blockNrOrHash, tx, api._blockReader, api.filters) // DoCall cannot be executed on non-canonical blocks
+	blockNumber, hash, latest, err := rpchelper.GetCanonicalBlockNumber(ctx, blockNrOrHash, tx, api._blockReader, api.filters) // DoCall cannot be executed on non-canonical blocks
 	if err != nil {
 		return nil, err
 	}
@@ -91,10 +92,11 @@ func (api *APIImpl) Call(ctx context.Context, args ethapi2.CallArgs, blockNrOrHa
 		return nil, nil
 	}
 
-	stateReader, err := rpchelper.CreateStateReader(ctx, tx, api._blockReader, blockNrOrHash, 0, api.filters, api.stateCache, chainConfig.ChainName)
+	stateReader, err := rpchelper.CreatePinnedStateReader(ctx, tx, api._blockReader, blockNrOrHash, 0, api.filters, api.stateCache)
 	if err != nil {
 		return nil, err
 	}
+	stateReader = api.headStateReader(stateReader, hash, latest)
 	header := block.HeaderNoCopy()
 	result, err := transactions.DoCall(ctx, engine, args, tx, blockNrOrHash, header, overrides, api.GasCap, chainConfig, stateReader, api._blockReader, api.evmCallTimeout)
 	if err != nil {
@@ -136,6 +138,10 @@ func headerByNumberOrHash(ctx context.Context, tx kv.Tx, blockNrOrHash rpc.Block
 	return header, nil
 }
 
+// estimateGasErrorRatio is the amount of overestimation eth_estimateGas is allowed to produce in order to
+// converge faster
+const estimateGasErrorRatio = 0.015
+
 // EstimateGas implements eth_estimateGas. Returns an estimate of how much gas is necessary to allow the transaction to complete. The transaction will not be added to the blockchain.
 func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutil.Uint64, error) {
 	var args ethapi2.CallArgs
@@ -150,9 +156,8 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 	}
 	defer dbtx.Rollback()
 
-	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
-		lo     = params.TxGas - 1
+		lo     uint64
 		hi     uint64
 		gasCap uint64
 	)
@@ -271,10 +276,12 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 	}
 
 	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
-	stateReader, err := rpchelper.CreateStateReaderFromBlockNumber(ctx, dbtx, txNumsReader, latestCanBlockNumber, isLatest, 0, api.stateCache, chainConfig.ChainName)
+	// every iteration of the binary search reads the same state
+	stateReader, err := rpchelper.CreatePinnedStateReaderFromBlockNumber(ctx, dbtx, txNumsReader, latestCanBlockNumber, isLatest, 0, api.stateCache)
 	if err != nil {
 		return 0, err
 	}
+	stateReader = api.headStateReader(stateReader, latestCanHash, isLatest)
 	header := block.HeaderNoCopy()
 
 	caller, err := transactions.NewReusableCaller(engine, stateReader, overrides, header, args, api.GasCap, latestNumOrHash, dbtx, api._blockReader, chainConfig, api.evmCallTimeout)
@@ -297,38 +304,65 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 		return result.Failed(), result, nil
 	}
 
-	// Execute the binary search and hone in on an executable gas limit
-	for lo+1 < hi {
-		mid := (hi + lo) / 2
-		failed, _, err := executable(mid)
-		// If the error is not nil(consensus error), it means the provided message
-		// call or transaction will never be accepted no matter how much gas it is
-		// assigened. Return the error directly, don't struggle any more.
+	// Execute at the highest allowance first: if the call fails there, it fails with any allowance
+	failed, result, err := executable(hi)
+	if err != nil {
+		return 0, err
+	}
+	if failed {
+		if result != nil && !errors.Is(result.Err, vm.ErrOutOfGas) {
+			if len(result.Revert()) > 0 {
+				return 0, ethapi2.NewRevertError(result)
+			}
+			return 0, result.Err
+		}
+		// Otherwise, the specified gas cap is too low
+		return 0, fmt.Errorf("gas required exceeds allowance (%d)", gasCap)
+	}
+
+	// Nothing was executed beyond the base cost (e.g. plain transfer): exactly that much is needed
+	if result.UsedGas == params.TxGas && result.RefundedGas == 0 {
+		return hexutil.Uint64(params.TxGas), nil
+	}
+
+	// The gas used by the successful execution is the lower bound: the call can't succeed with less.
+	// Most calls need only the gas they used plus the refund and the stipend of the nested calls with the
+	// 63/64 rule applied on top of it - try it before falling back to the binary search.
+	lo = result.UsedGas - 1
+	optimisticGas := (result.UsedGas + result.RefundedGas + params.CallStipend) * 64 / 63
+	if optimisticGas < hi {
+		failed, _, err = executable(optimisticGas)
 		if err != nil {
 			return 0, err
 		}
 		if failed {
-			lo = mid
+			lo = optimisticGas
 		} else {
-			hi = mid
+			hi = optimisticGas
 		}
 	}
 
-	// Reject the transaction as invalid if it still fails at the highest allowance
-	if hi == gasCap {
-		failed, result, err := executable(hi)
+	// Binary search the rest, until the estimate is within estimateGasErrorRatio of the required gas
+	for lo+1 < hi {
+		if float64(hi-lo)/float64(hi) < estimateGasErrorRatio {
+			break
+		}
+		mid := (hi + lo) / 2
+		if mid > lo*2 {
+			// Most calls don't need much more gas than they use, so the search is skewed towards the lower bound
+			mid = lo * 2
+		}
+		failed, _, err = executable(mid)
+		// If the error is not nil(consensus error), it means the provided message
+		// call or transaction will never be accepted no matter how much gas it is
+		// assigened. Return the error directly, don't struggle any more.
 		if err != nil {
 			return 0, err
 		}
 		if failed {
-			if result != nil && !errors.Is(result.Err, vm.ErrOutOfGas) {
-				if len(result.Revert()) > 0 {
-					return 0, ethapi2.NewRevertError(result)
-				}
-				return 0, result.Err
-			}
-			// Otherwise, the specified gas cap is too low
-			return 0, fmt.Errorf("gas required exceeds allowance (%d)", gasCap)
+			lo = mid
+		} else {
+			hi = mid
 		}
 	}
 	return hexutil.Uint64(hi), nil
@@ -467,56 +501,74 @@ func verifyExecResult(execResult *core.EphemeralExecResult, block *types.Block)
 }
 
 func (api *BaseAPI) getWitness(ctx context.Context, db kv.RoDB, blockNrOrHash rpc.BlockNumberOrHash, txIndex hexutil.Uint, fullBlock bool, maxGetProofRewindBlockCount int, logger log.Logger) (hexutility.Bytes, error) {
+	var result hexutility.Bytes
+	err := api.withBlockWitness(ctx, db, blockNrOrHash, txIndex, fullBlock, maxGetProofRewindBlockCount, logger, func(w *blockWitness) error {
+		var err error
+		result, err = w.serialize(logger)
+		return err
+	})
+	return result, err
+}
+
+// blockWitness is what the execution of a block touched, loaded from the state of its parent.
+// A nil blockWitness stands for the genesis block, whose witness is empty.
+type blockWitness struct {
+	block             *types.Block
+	prevHeader        *types.Header
+	cfg               *stagedsync.WitnessCfg
+	store             *stagedsync.WitnessStore
+	trie              *trie.Trie
+	touchedPlainKeys  [][]byte
+	touchedHashedKeys [][]byte
+	codeReads         map[libcommon.Hash]witnesstypes.CodeWithHash
+	// ancestors are the headers from the parent down to the oldest one accessed by BLOCKHASH.
+	ancestors []*types.Header
+}
+
+// withBlockWitness executes the block and calls fn with its witness, fn is not called if the block is not found.
+func (api *BaseAPI) withBlockWitness(ctx context.Context, db kv.RoDB, blockNrOrHash rpc.BlockNumberOrHash, txIndex hexutil.Uint, fullBlock bool, maxGetProofRewindBlockCount int, logger log.Logger, fn func(w *blockWitness) error) error {
 	roTx, err := db.BeginRo(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer roTx.Rollback()
 
 	blockNr, hash, _, err := rpchelper.GetCanonicalBlockNumber(ctx, blockNrOrHash, roTx, api._blockReader, api.filters) // DoCall cannot be executed on non-canonical blocks
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Witness for genesis block is empty
 	if blockNr == 0 {
-		w := trie.NewWitness(make([]trie.WitnessOperator, 0))
-
-		var buf bytes.Buffer
-		_, err = w.WriteInto(&buf)
-		if err != nil {
-			return nil, err
-		}
-
-		return buf.Bytes(), nil
+		return fn(nil)
 	}
 
 	block, err := api.blockWithSenders(ctx, roTx, hash, blockNr)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if block == nil {
-		return nil, nil
+		return nil
 	}
 
 	if !fullBlock && int(txIndex) >= len(block.Transactions()) {
-		return nil, fmt.Errorf("transaction index out of bounds: %d", txIndex)
+		return fmt.Errorf("transaction index out of bounds: %d", txIndex)
 	}
 
 	latestBlock, err := rpchelper.GetLatestBlockNumber(roTx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if latestBlock < blockNr {
 		// shouldn't happen, but check anyway
-		return nil, fmt.Errorf("block number is in the future latest=%d requested=%d", latestBlock, blockNr)
+		return fmt.Errorf("block number is in the future latest=%d requested=%d", latestBlock, blockNr)
 	}
 
 	// Compute the witness if it's for a tx or it's not present in db
 	prevHeader, err := api._blockReader.HeaderByNumber(ctx, roTx, blockNr-1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	regenerateHash := false
@@ -526,12 +578,12 @@ func (api *BaseAPI) getWitness(ctx context.Context, db kv.RoDB, blockNrOrHash rp
 
 	engine, ok := api.engine().(consensus.Engine)
 	if !ok {
-		return nil, errors.New("engine is not consensus.Engine")
+		return errors.New("engine is not consensus.Engine")
 	}
 
 	roTx2, err := db.BeginRo(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer roTx2.Rollback()
 	txBatch2 := membatchwithdb.NewMemoryBatch(roTx2, "", logger)
@@ -540,36 +592,44 @@ func (api *BaseAPI) getWitness(ctx context.Context, db kv.RoDB, blockNrOrHash rp
 	// Prepare witness config
 	chainConfig, err := api.chainConfig(ctx, roTx2)
 	if err != nil {
-		return nil, fmt.Errorf("error loading chain config: %v", err)
+		return fmt.Errorf("error loading chain config: %v", err)
 	}
 
 	// Unwind to blockNr
 	cfg := stagedsync.StageWitnessCfg(true, 0, chainConfig, engine, api._blockReader, api.dirs)
 	err = stagedsync.RewindStagesForWitness(txBatch2, blockNr, latestBlock, &cfg, regenerateHash, ctx, logger)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	store, err := stagedsync.PrepareForWitness(txBatch2, block, prevHeader.Root, &cfg, ctx, logger)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	domains, err := libstate.NewSharedDomains(txBatch2, log.New())
 	if err != nil {
-		return nil, err
+		return err
 	}
 	sdCtx := libstate.NewSharedDomainsCommitmentContext(domains, commitment.ModeUpdate, commitment.VariantHexPatriciaTrie)
 	patricieTrie := sdCtx.Trie()
 	hph, ok := patricieTrie.(*commitment.HexPatriciaHashed)
 	if !ok {
-		return nil, errors.New("casting to HexPatriciaTrieHashed failed")
+		return errors.New("casting to HexPatriciaTrieHashed failed")
+	}
+
+	// record the block hashes accessed by BLOCKHASH, stateless execution needs the headers up to the oldest one.
+	blockHashReads := make(map[uint64]struct{})
+	getHashFn := store.GetHashFn
+	store.GetHashFn = func(n uint64) libcommon.Hash {
+		blockHashReads[n] = struct{}{}
+		return getHashFn(n)
 	}
 
 	// execute block #blockNr ephemerally. This will use TrieStateWriter to record touches of accounts and storage keys.
 	_, err = core.ExecuteBlockEphemerally(chainConfig, &vm.Config{}, store.GetHashFn, engine, block, store.Tds, store.TrieStateWriter, store.ChainReader, nil, logger)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// gather touched keys from ephemeral block execution
@@ -585,19 +645,60 @@ func (api *BaseAPI) getWitness(ctx context.Context, db kv.RoDB, blockNrOrHash rp
 
 	hph.SetTrace(false) // disable tracing to avoid mixing with trace from witness computation
 	// generate the block witness, this works by loading the merkle paths to the touched keys (they are loaded from the state at block #blockNr-1)
-	witnessTrie, witnessRootHash, err := hph.GenerateWitness(ctx, updates, codeReads, prevHeader.Root[:], "computeWitness")
+	witnessTrie, witnessRootHash, stats, err := hph.GenerateWitness(ctx, updates, codeReads, prevHeader.Root[:], "computeWitness", logger)
 	if err != nil {
-		return nil, err
+		logger.Debug("[computeWitness] witness generation failed", "block", blockNr, "keys", stats.Keys, "processed", stats.Processed, "took", stats.Took, "err", err)
+		return err
 	}
+	logger.Debug("[computeWitness] witness generated", "block", blockNr, "keys", stats.Keys, "accounts", stats.Accounts, "storage", stats.Storage, "took", stats.Took)
 
 	//
 	if !bytes.Equal(witnessRootHash, prevHeader.Root[:]) {
-		return nil, fmt.Errorf("witness root hash mismatch actual(%x)!=expected(%x)", witnessRootHash, prevHeader.Root[:])
+		return fmt.Errorf("witness root hash mismatch actual(%x)!=expected(%x)", witnessRootHash, prevHeader.Root[:])
+	}
+
+	ancestors := []*types.Header{prevHeader}
+	for n := range blockHashReads {
+		for oldest := ancestors[len(ancestors)-1].Number.Uint64(); n < oldest; oldest-- {
+			header, err := api._blockReader.HeaderByNumber(ctx, roTx, oldest-1)
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				return fmt.Errorf("header %d not found", oldest-1)
+			}
+			ancestors = append(ancestors, header)
+		}
+	}
+
+	return fn(&blockWitness{
+		block:             block,
+		prevHeader:        prevHeader,
+		cfg:               &cfg,
+		store:             store,
+		trie:              witnessTrie,
+		touchedPlainKeys:  touchedPlainKeys,
+		touchedHashedKeys: touchedHashedKeys,
+		codeReads:         codeReads,
+		ancestors:         ancestors,
+	})
+}
+
+// serialize encodes the witness in the erigon witness format, after verifying that the block executes statelessly on it.
+func (w *blockWitness) serialize(logger log.Logger) (hexutility.Bytes, error) {
+	if w == nil {
+		wit := trie.NewWitness(make([]trie.WitnessOperator, 0))
+
+		var buf bytes.Buffer
+		if _, err := wit.WriteInto(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
 	}
 
 	// retain list is need for the serialization of the trie.Trie into a witness
 	retainListBuilder := trie.NewRetainListBuilder()
-	for _, key := range touchedHashedKeys {
+	for _, key := range w.touchedHashedKeys {
 		if len(key) == 32 {
 			retainListBuilder.AddTouch(key)
 		} else {
@@ -607,14 +708,14 @@ func (api *BaseAPI) getWitness(ctx context.Context, db kv.RoDB, blockNrOrHash rp
 		}
 	}
 
-	for _, codeWithHash := range codeReads {
+	for _, codeWithHash := range w.codeReads {
 		retainListBuilder.ReadCode(codeWithHash.CodeHash, codeWithHash.Code)
 	}
 
 	retainList := retainListBuilder.Build(false)
 
 	// serialize witness trie
-	witness, err := witnessTrie.ExtractWitness(true, retainList)
+	witness, err := w.trie.ExtractWitness(true, retainList)
 	if err != nil {
 		return nil, err
 	}
@@ -627,13 +728,13 @@ func (api *BaseAPI) getWitness(ctx context.Context, db kv.RoDB, blockNrOrHash rp
 
 	// this is a verification step: we execute block #blockNr statelessly using the witness, and we expect to get the same state root as in the header
 	// otherwise something went wrong
-	store.Tds.SetTrie(witnessTrie)
-	newStateRoot, err := stagedsync.ExecuteBlockStatelessly(block, prevHeader, store.ChainReader, store.Tds, &cfg, &witnessBuffer, store.GetHashFn, logger)
+	w.store.Tds.SetTrie(w.trie)
+	newStateRoot, err := stagedsync.ExecuteBlockStatelessly(w.block, w.prevHeader, w.store.ChainReader, w.store.Tds, w.cfg, &witnessBuffer, w.store.GetHashFn, logger)
 	if err != nil {
 		return nil, err
 	}
-	if !bytes.Equal(newStateRoot.Bytes(), block.Root().Bytes()) {
-		fmt.Printf("state root mismatch after stateless execution actual(%x) != expected(%x)\n", newStateRoot.Bytes(), block.Root().Bytes())
+	if !bytes.Equal(newStateRoot.Bytes(), w.block.Root().Bytes()) {
+		fmt.Printf("state root mismatch after stateless execution actual(%x) != expected(%x)\n", newStateRoot.Bytes(), w.block.Root().Bytes())
 	}
 	witnessBufBytes := witnessBuffer.Bytes()
 	witnessBufBytesCopy := make([]byte, len(witnessBufBytes))
@@ -21,11 +21,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/big"
 	"time"
 
-	"github.com/holiman/uint256"
-
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/common/math"
@@ -39,21 +36,12 @@ import (
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/adapter/ethapi"
 	"github.com/erigontech/erigon/turbo/rpchelper"
+	"github.com/erigontech/erigon/turbo/shards"
 )
 
-type BlockOverrides struct {
-	BlockNumber *hexutil.Uint64
-	Coinbase    *common.Address
-	Timestamp   *hexutil.Uint64
-	GasLimit    *hexutil.Uint
-	Difficulty  *hexutil.Uint
-	BaseFee     *uint256.Int
-	BlockHash   *map[uint64]common.Hash
-}
-
 type Bundle struct {
 	Transactions  []ethapi.CallArgs
-	BlockOverride BlockOverrides
+	BlockOverride ethapi.BlockOverrides
 }
 
 type StateContext struct {
@@ -61,32 +49,6 @@ type StateContext struct {
 	TransactionIndex *int
 }
 
-func blockHeaderOverride(blockCtx *evmtypes.BlockContext, blockOverride BlockOverrides, overrideBlockHash map[uint64]common.Hash) {
-	if blockOverride.BlockNumber != nil {
-		blockCtx.BlockNumber = uint64(*blockOverride.BlockNumber)
-	}
-	if blockOverride.BaseFee != nil {
-		blockCtx.BaseFee = blockOverride.BaseFee
-	}
-	if blockOverride.Coinbase != nil {
-		blockCtx.Coinbase = *blockOverride.Coinbase
-	}
-	if blockOverride.Difficulty != nil {
-		blockCtx.Difficulty = new(big.Int).SetUint64(uint64(*blockOverride.Difficulty))
-	}
-	if blockOverride.Timestamp != nil {
-		blockCtx.Time = uint64(*blockOverride.Timestamp)
-	}
-	if blockOverride.GasLimit != nil {
-		blockCtx.GasLimit = uint64(*blockOverride.GasLimit)
-	}
-	if blockOverride.BlockHash != nil {
-		for blockNum, hash := range *blockOverride.BlockHash {
-			overrideBlockHash[blockNum] = hash
-		}
-	}
-}
-
 func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateContext StateContext, stateOverride *ethapi.StateOverrides, timeoutMilliSecondsPtr *int64) ([][]map[string]interface{}, error) {
 	var (
 		hash               common.Hash
@@ -123,7 +85,7 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 
 	defer func(start time.Time) { log.Trace("Executing EVM callMany finished", "runtime", time.Since(start)) }(time.Now())
 
-	blockNum, hash, _, err := rpchelper.GetBlockNumber(ctx, simulateContext.BlockNumber, tx, api._blockReader, api.filters)
+	blockNum, hash, latest, err := rpchelper.GetBlockNumber(ctx, simulateContext.BlockNumber, tx, api._blockReader, api.filters)
 	if err != nil {
 		return nil, err
 	}
@@ -153,6 +115,19 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 		return nil, err
 	}
 
+	// the changes of the replayed txns of the head block are cached: don't replay them again
+	var replayWriter state.StateWriter = state.NewNoopWriter()
+	var replayed *shards.StateCache
+	if latest && api.headExec != nil {
+		if overlay := api.headExec.get(hash, transactionIndex); overlay != nil {
+			stateReader = overlay.reader(stateReader)
+			replayTransactions = nil
+		} else {
+			replayed = newHeadExecOverlay()
+			replayWriter = state.NewCachedWriter(state.NewNoopWriter(), replayed)
+		}
+	}
+
 	st := state.New(stateReader)
 
 	header := block.Header()
@@ -229,6 +204,13 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
 		}
 	}
+	if replayed != nil {
+		// the values written relative to the state before the block: the overlay is on top of it
+		if err = st.MakeWriteSet(rules, replayWriter); err != nil {
+			return nil, err
+		}
+		api.headExec.put(hash, transactionIndex, replayed)
+	}
 
 	// after replaying the txns, we want to overload the state
 	// overload state
@@ -243,29 +225,7 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 
 	for _, bundle := range bundles {
 		// first change blockContext
-		if bundle.BlockOverride.BlockNumber != nil {
-			blockCtx.BlockNumber = uint64(*bundle.BlockOverride.BlockNumber)
-		}
-		if bundle.BlockOverride.BaseFee != nil {
-			blockCtx.BaseFee = bundle.BlockOverride.BaseFee
-		}
-		if bundle.BlockOverride.Coinbase != nil {
-			blockCtx.Coinbase = *bundle.BlockOverride.Coinbase
-		}
-		if bundle.BlockOverride.Difficulty != nil {
-			blockCtx.Difficulty = new(big.Int).SetUint64(uint64(*bundle.BlockOverride.Difficulty))
-		}
-		if bundle.BlockOverride.Timestamp != nil {
-			blockCtx.Time = uint64(*bundle.BlockOverride.Timestamp)
-		}
-		if bundle.BlockOverride.GasLimit != nil {
-			blockCtx.GasLimit = uint64(*bundle.BlockOverride.GasLimit)
-		}
-		if bundle.BlockOverride.BlockHash != nil {
-			for blockNum, hash := range *bundle.BlockOverride.BlockHash {
-				overrideBlockHash[blockNum] = hash
-			}
-		}
+		bundle.BlockOverride.Override(&blockCtx, overrideBlockHash)
 		results := []map[string]interface{}{}
 		for _, txn := range bundle.Transactions {
 			if txn.Gas == nil || *(txn.Gas) == 0 {
@@ -62,12 +62,72 @@ func TestEstimateGas(t *testing.T) {
 	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
 	var from = libcommon.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
 	var to = libcommon.HexToAddress("0x0d3ab14bbad3d99f4203bd7a11acb94882050e7e")
-	if _, err := api.EstimateGas(context.Background(), &ethapi.CallArgs{
+	gas, err := api.EstimateGas(context.Background(), &ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, nil, nil); err != nil {
+	}, nil, nil)
+	if err != nil {
 		t.Errorf("calling EstimateGas: %v", err)
 	}
+	if uint64(gas) != params.TxGas {
+		t.Errorf("expected %d gas for a plain transfer, got %d", params.TxGas, gas)
+	}
+}
+
+func TestEstimateGasWithRefund(t *testing.T) {
+	m, bankAddress, contractAddress := chainWithDeployedContract(t)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+
+	// clearing the slot is refunded, so the call needs more gas than it ends up using
+	callData := hexutility.Bytes(contractInvocationData(0))
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	gas, err := api.EstimateGas(context.Background(), &ethapi.CallArgs{
+		From: &bankAddress,
+		To:   &contractAddress,
+		Data: &callData,
+	}, &latest, nil)
+	require.NoError(t, err)
+
+	_, err = api.Call(context.Background(), ethapi.CallArgs{
+		From: &bankAddress,
+		To:   &contractAddress,
+		Data: &callData,
+		Gas:  &gas,
+	}, latest, nil)
+	require.NoError(t, err)
+
+	lessGas := gas * 9 / 10
+	_, err = api.Call(context.Background(), ethapi.CallArgs{
+		From: &bankAddress,
+		To:   &contractAddress,
+		Data: &callData,
+		Gas:  &lessGas,
+	}, latest, nil)
+	require.Error(t, err)
+}
+
+func TestEstimateGasWithOverrides(t *testing.T) {
+	m, bankAddress, _ := chainWithDeployedContract(t)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+
+	// PUSH1 1 PUSH1 0 SSTORE STOP: every iteration of the estimation has to see the overridden code
+	to := libcommon.HexToAddress("0x0d3ab14bbad3d99f4203bd7a11acb94882050e7e")
+	code := hexutility.Bytes{0x60, 0x01, 0x60, 0x00, 0x55, 0x00}
+	overrides := ethapi.StateOverrides{to: ethapi.Account{Code: &code}}
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	gas, err := api.EstimateGas(context.Background(), &ethapi.CallArgs{
+		From: &bankAddress,
+		To:   &to,
+	}, &latest, &overrides)
+	require.NoError(t, err)
+	require.Greater(t, uint64(gas), params.TxGas+params.SstoreSetGasEIP2200)
+
+	_, err = api.Call(context.Background(), ethapi.CallArgs{
+		From: &bankAddress,
+		To:   &to,
+		Gas:  &gas,
+	}, latest, &overrides)
+	require.NoError(t, err)
 }
 
 func TestEthCallNonCanonical(t *testing.T) {
@@ -106,6 +166,58 @@ func TestEthCallToPrunedBlock(t *testing.T) {
 	}
 }
 
+func TestEthCallHistorical(t *testing.T) {
+	m, bankAddress, contractAddress := chainWithDeployedContract(t)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+
+	// retrieve() returns the value stored by the transaction of the previous block
+	callData := hexutility.Bytes(hexutil.MustDecode("0x2e64cec1"))
+	for blockNum, expected := range map[rpc.BlockNumber]byte{1: 0, 2: 1, 3: 2, rpc.LatestBlockNumber: 2} {
+		result, err := api.Call(context.Background(), ethapi.CallArgs{
+			From: &bankAddress,
+			To:   &contractAddress,
+			Data: &callData,
+		}, rpc.BlockNumberOrHashWithNumber(blockNum), nil)
+		require.NoError(t, err, blockNum)
+		require.Equal(t, libcommon.BytesToHash([]byte{expected}).Bytes(), []byte(result), blockNum)
+	}
+}
+
+func TestHistoricalStateReaderFactory(t *testing.T) {
+	m, bankAddress, contractAddress := chainWithDeployedContract(t)
+
+	tx, err := m.DB.BeginTemporalRo(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	f, err := rpchelper.NewHistoricalStateReaderFactory(tx, rawdbv3.TxNums, 2, 0)
+	require.NoError(t, err)
+	expected, err := rpchelper.CreateHistoryStateReader(tx, rawdbv3.TxNums, 2, 0, "")
+	require.NoError(t, err)
+
+	// the second reader is served from the cache filled by the first one
+	for i := 0; i < 2; i++ {
+		r := f.NewReader()
+		for _, addr := range []libcommon.Address{bankAddress, contractAddress} {
+			acc, err := r.ReadAccountData(addr)
+			require.NoError(t, err)
+			expectedAcc, err := expected.ReadAccountData(addr)
+			require.NoError(t, err)
+			require.Equal(t, expectedAcc, acc)
+		}
+		code, err := r.ReadAccountCode(contractAddress, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, code)
+	}
+
+	f, err = rpchelper.NewHistoricalStateReaderFactory(tx, rawdbv3.TxNums, 1, 0)
+	require.NoError(t, err)
+	st := state.New(f.NewReader())
+	exist, err := st.Exist(contractAddress)
+	require.NoError(t, err)
+	require.False(t, exist, "Contract should not exist at block #1")
+}
+
 func TestGetProof(t *testing.T) {
 	var maxGetProofRewindBlockCount = 1 // Note, this is unsafe for parallel tests, but, this test is the only consumer for now
 
@@ -461,11 +461,11 @@ func (api *APIImpl) GetTransactionReceipt(ctx context.Context, txnHash common.Ha
 		return nil, err
 	}
 
-	if txNumMin+2 > txNum { //TODO: what a magic is this "2" and how to avoid it
+	if txNumMin+1 > txNum { // +1 because block has system-txn in the beginning of block
 		return nil, fmt.Errorf("uint underflow txnums error txNum: %d, txNumMin: %d, blockNum: %d", txNum, txNumMin, blockNum)
 	}
 
-	var txnIndex = int(txNum - txNumMin - 2)
+	var txnIndex = int(txNum - txNumMin - 1)
 
 	txn, err := api._blockReader.TxnByIdxInBlock(ctx, tx, header.Number.Uint64(), txnIndex)
 	if err != nil {
@@ -502,7 +502,7 @@ func (api *APIImpl) GetTransactionReceipt(ctx context.Context, txnHash common.Ha
 		return ethutils.MarshalReceipt(borReceipt, bortypes.NewBorTransaction(), chainConfig, block.HeaderNoCopy(), txnHash, false), nil
 	}
 
-	receipt, err := api.getReceipt(ctx, chainConfig, tx, header, txn, txnIndex, txNum)
+	receipt, err := api.getReceipt(ctx, chainConfig, tx, header, txn, txnIndex, txNum+1)
 	if err != nil {
 		return nil, fmt.Errorf("getReceipt error: %w", err)
 	}
@@ -532,18 +532,24 @@ func (api *APIImpl) GetBlockReceipts(ctx context.Context, numberOrHash rpc.Block
 	if err != nil {
 		return nil, err
 	}
+	return api.marshalBlockReceipts(ctx, tx, chainConfig, block)
+}
+
+// marshalBlockReceipts returns the receipts of all the transactions of the block, generated by one execution of the
+// block, followed by the receipt of the state sync transaction on bor chains
+func (api *BaseAPI) marshalBlockReceipts(ctx context.Context, tx kv.TemporalTx, chainConfig *chain.Config, block *types.Block) ([]map[string]interface{}, error) {
 	receipts, err := api.getReceipts(ctx, tx, block)
 	if err != nil {
 		return nil, fmt.Errorf("getReceipts error: %w", err)
 	}
-	result := make([]map[string]interface{}, 0, len(receipts))
+	result := make([]map[string]interface{}, 0, len(receipts)+1)
 	for _, receipt := range receipts {
 		txn := block.Transactions()[receipt.TransactionIndex]
 		result = append(result, ethutils.MarshalReceipt(receipt, txn, chainConfig, block.HeaderNoCopy(), txn.Hash(), true))
 	}
 
 	if chainConfig.Bor != nil {
-		events, err := api.stateSyncEvents(ctx, tx, block.Hash(), blockNum, chainConfig)
+		events, err := api.stateSyncEvents(ctx, tx, block.Hash(), block.NumberU64(), chainConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -18,11 +18,16 @@ package jsonrpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/holiman/uint256"
+
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
+	txpool_proto "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon/consensus/misc"
 	"github.com/erigontech/erigon/core/rawdb"
@@ -31,6 +36,7 @@ import (
 	"github.com/erigontech/erigon/eth/gasprice"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/rpchelper"
+	"github.com/erigontech/erigon/txnprovider/txpool"
 )
 
 // BlockNumber implements eth_blockNumber. Returns the block number of most recent block.
@@ -151,6 +157,7 @@ type feeHistoryResult struct {
 	GasUsedRatio     []float64        `json:"gasUsedRatio"`
 	BlobBaseFee      []*hexutil.Big   `json:"baseFeePerBlobGas,omitempty"`
 	BlobGasUsedRatio []float64        `json:"blobGasUsedRatio,omitempty"`
+	PriorityFeeFloor []*hexutil.Big   `json:"priorityFeeFloor,omitempty"` // bor only
 }
 
 func (api *APIImpl) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
@@ -161,7 +168,7 @@ func (api *APIImpl) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex,
 	defer tx.Rollback()
 	oracle := gasprice.NewOracle(NewGasPriceOracleBackend(tx, api.BaseAPI), ethconfig.Defaults.GPO, api.gasCache, api.logger.New("app", "gasPriceOracle"))
 
-	oldest, reward, baseFee, gasUsed, blobBaseFee, blobGasUsedRatio, err := oracle.FeeHistory(ctx, int(blockCount), lastBlock, rewardPercentiles)
+	oldest, reward, baseFee, gasUsed, blobBaseFee, blobGasUsedRatio, priorityFeeFloor, err := oracle.FeeHistoryWithPriorityFeeFloor(ctx, int(blockCount), lastBlock, rewardPercentiles)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +200,12 @@ func (api *APIImpl) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex,
 	if blobGasUsedRatio != nil {
 		results.BlobGasUsedRatio = blobGasUsedRatio
 	}
+	if priorityFeeFloor != nil {
+		results.PriorityFeeFloor = make([]*hexutil.Big, len(priorityFeeFloor))
+		for i, v := range priorityFeeFloor {
+			results.PriorityFeeFloor[i] = (*hexutil.Big)(v)
+		}
+	}
 	return results, nil
 }
 
@@ -222,6 +235,110 @@ func (api *APIImpl) BlobBaseFee(ctx context.Context) (*hexutil.Big, error) {
 	return (*hexutil.Big)(ret256.ToBig()), nil
 }
 
+const (
+	blobFeeEstimateBlocks    = 6    // horizon of eth_estimateBlobFee when not given
+	blobFeeEstimateMaxBlocks = 1024 // max horizon of eth_estimateBlobFee
+	blobFeeEstimateHistory   = 20   // recent blocks the blob gas usage trend is averaged over
+)
+
+// BlobFeeEstimate is the result of eth_estimateBlobFee.
+type BlobFeeEstimate struct {
+	BlobBaseFee       *hexutil.Big   `json:"blobBaseFee"`
+	MaxFeePerBlobGas  *hexutil.Big   `json:"maxFeePerBlobGas"`
+	PeakExcessBlobGas hexutil.Uint64 `json:"peakExcessBlobGas"`
+	PoolBlobGas       hexutil.Uint64 `json:"poolBlobGas"`
+}
+
+// EstimateBlobFee implements eth_estimateBlobFee. Recommends a maxFeePerBlobGas for a blob transaction to stay
+// includable for the next `blocks` blocks, projecting the excess blob gas from the blob transactions waiting in the
+// pool and the blob gas usage of the recent blocks.
+func (api *APIImpl) EstimateBlobFee(ctx context.Context, blocks *hexutil.Uint64) (*BlobFeeEstimate, error) {
+	horizon := uint64(blobFeeEstimateBlocks)
+	if blocks != nil {
+		horizon = uint64(*blocks)
+	}
+	if horizon == 0 || horizon > blobFeeEstimateMaxBlocks {
+		return nil, fmt.Errorf("blocks must be in [1, %d], got %d", blobFeeEstimateMaxBlocks, horizon)
+	}
+
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	header := rawdb.ReadCurrentHeader(tx)
+	if header == nil || header.BlobGasUsed == nil {
+		return nil, errors.New("blob transactions are not active at the head")
+	}
+	config, err := api.BaseAPI.chainConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the trend: average blob gas used by the recent blocks
+	var recentBlobGasUsed, recentBlocks uint64
+	for n := header.Number.Uint64(); recentBlocks < blobFeeEstimateHistory; n-- {
+		h, err := api._blockReader.HeaderByNumber(ctx, tx, n)
+		if err != nil {
+			return nil, err
+		}
+		if h == nil || h.BlobGasUsed == nil {
+			break
+		}
+		recentBlobGasUsed += *h.BlobGasUsed
+		recentBlocks++
+		if n == 0 {
+			break
+		}
+	}
+	if recentBlocks > 0 {
+		recentBlobGasUsed /= recentBlocks
+	}
+
+	// the pool composition: blob transactions that can be included, the queued ones can't yet
+	var pool []txpool.BlobFeeDemand
+	var poolBlobGas uint64
+	if api.txPool != nil {
+		reply, err := api.txPool.All(ctx, &txpool_proto.AllRequest{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pooled := range reply.Txs {
+			// only the blob txns are decoded, their type byte leads the envelope
+			if pooled.TxnType == txpool_proto.AllReply_QUEUED || len(pooled.RlpTx) == 0 || pooled.RlpTx[0] != types.BlobTxType {
+				continue
+			}
+			txn, err := types.DecodeWrappedTransaction(pooled.RlpTx)
+			if err != nil {
+				return nil, fmt.Errorf("decoding transaction from: %x: %w", pooled.RlpTx, err)
+			}
+			var feeCap *uint256.Int
+			switch t := txn.(type) {
+			case *types.BlobTxWrapper:
+				feeCap = t.Tx.MaxFeePerBlobGas
+			case *types.BlobTx:
+				feeCap = t.MaxFeePerBlobGas
+			}
+			if feeCap == nil {
+				continue
+			}
+			pool = append(pool, txpool.BlobFeeDemand{BlobGas: txn.GetBlobGas(), FeeCap: *feeCap})
+			poolBlobGas += txn.GetBlobGas()
+		}
+	}
+
+	est, err := txpool.EstimateBlobFee(config, misc.CalcExcessBlobGas(config, header), recentBlobGasUsed, pool, int(horizon))
+	if err != nil {
+		return nil, err
+	}
+	return &BlobFeeEstimate{
+		BlobBaseFee:       (*hexutil.Big)(est.BlobBaseFee.ToBig()),
+		MaxFeePerBlobGas:  (*hexutil.Big)(est.MaxFeePerBlobGas.ToBig()),
+		PeakExcessBlobGas: hexutil.Uint64(est.PeakExcessBlobGas),
+		PoolBlobGas:       hexutil.Uint64(poolBlobGas),
+	}, nil
+}
+
 // BaseFee returns the base fee at the current head.
 func (api *APIImpl) BaseFee(ctx context.Context) (*hexutil.Big, error) {
 	// read current header
@@ -18,19 +18,23 @@ package jsonrpc
 
 import (
 	"context"
+	"encoding/json"
 	"math"
 	"math/big"
 	"testing"
 
 	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/log/v3"
 
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/stages/mock"
 )
 
@@ -73,6 +77,24 @@ func TestGasPrice(t *testing.T) {
 
 }
 
+func TestFeeHistoryJSON(t *testing.T) {
+	m := createGasPriceTestKV(t, 5)
+	eth := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+	result, err := eth.FeeHistory(context.Background(), 3, rpc.LatestBlockNumber, []float64{50})
+	require.NoError(t, err)
+
+	// the priority fee floor is bor only: the result of the other chains is unchanged
+	enc, err := json.Marshal(result)
+	require.NoError(t, err)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(enc, &fields))
+	require.Len(t, fields, 6)
+	for _, field := range []string{"oldestBlock", "reward", "baseFeePerGas", "gasUsedRatio", "baseFeePerBlobGas", "blobGasUsedRatio"} {
+		require.Contains(t, fields, field)
+	}
+	require.JSONEq(t, `"0x3"`, string(fields["oldestBlock"]))
+}
+
 func createGasPriceTestKV(t *testing.T, chainSize int) *mock.MockSentry {
 	var (
 		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -104,3 +126,28 @@ func createGasPriceTestKV(t *testing.T, chainSize int) *mock.MockSentry {
 
 	return m
 }
+
+func TestEstimateBlobFee(t *testing.T) {
+	ctx := context.Background()
+
+	m := createGasPriceTestKV(t, 5)
+	eth := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+	_, err := eth.EstimateBlobFee(ctx, nil)
+	require.Error(t, err, "blob transactions are not active before Cancun")
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	m = mock.MockWithGenesis(t, &types.Genesis{Config: params.AllProtocolChanges}, key, false)
+	eth = NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+	blobBaseFee, err := eth.BlobBaseFee(ctx)
+	require.NoError(t, err)
+	est, err := eth.EstimateBlobFee(ctx, nil)
+	require.NoError(t, err)
+	// no blobs in the recent blocks nor in the pool: the fee stays where it is
+	require.Equal(t, blobBaseFee, est.BlobBaseFee)
+	require.Equal(t, blobBaseFee, est.MaxFeePerBlobGas)
+	require.Zero(t, est.PoolBlobGas)
+
+	blocks := hexutil.Uint64(0)
+	_, err = eth.EstimateBlobFee(ctx, &blocks)
+	require.Error(t, err)
+}
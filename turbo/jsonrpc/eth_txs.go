@@ -19,7 +19,6 @@ package jsonrpc
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"math/big"
 
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
@@ -53,13 +52,11 @@ func (api *APIImpl) GetTransactionByHash(ctx context.Context, txnHash common.Has
 	}
 
 	// https://infura.io/docs/ethereum/json-rpc/eth-getTransactionByHash
-	blockNum, txNum, ok, err := api.txnLookup(ctx, tx, txnHash)
+	txn, blockNum, txNum, ok, err := api._txnReader.TxnByHash(ctx, tx, txnHash)
 	if err != nil {
 		return nil, err
 	}
 
-	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
-
 	// Private API returns 0 if transaction is not found.
 	if blockNum == 0 && chainConfig.Bor != nil {
 		if api.useBridgeReader {
@@ -73,22 +70,6 @@ func (api *APIImpl) GetTransactionByHash(ctx context.Context, txnHash common.Has
 		}
 	}
 	if ok {
-		txNumMin, err := txNumsReader.Min(tx, blockNum)
-		if err != nil {
-			return nil, err
-		}
-
-		if txNumMin+2 > txNum { //TODO: what a magic is this "2" and how to avoid it
-			return nil, fmt.Errorf("uint underflow txnums error txNum: %d, txNumMin: %d, blockNum: %d", txNum, txNumMin, blockNum)
-		}
-
-		var txnIndex uint64 = txNum - txNumMin - 2
-
-		txn, err := api._txnReader.TxnByIdxInBlock(ctx, tx, blockNum, int(txnIndex))
-		if err != nil {
-			return nil, err
-		}
-
 		header, err := api._blockReader.HeaderByNumber(ctx, tx, blockNum)
 		if err != nil {
 			return nil, err
@@ -118,6 +99,14 @@ func (api *APIImpl) GetTransactionByHash(ctx context.Context, txnHash common.Has
 			return newRPCBorTransaction(borTx, txnHash, blockHash, blockNum, uint64(txCount), baseFee, chainConfig.ChainID), nil
 		}
 
+		txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
+		txNumMin, err := txNumsReader.Min(tx, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		// -1 because block has system-txn in the beginning of block
+		txnIndex := txNum - txNumMin - 1
+
 		return NewRPCTransaction(txn, blockHash, blockNum, txnIndex, baseFee), nil
 	}
 
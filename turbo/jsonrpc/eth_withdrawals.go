@@ -0,0 +1,152 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon/core/rawdb"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+const (
+	defaultWithdrawalsByAddressPageSize = 25
+	maxWithdrawalsByAddressPageSize     = 1000
+)
+
+// RPCWithdrawal is a withdrawal with the block including it
+type RPCWithdrawal struct {
+	BlockNumber    hexutil.Uint64 `json:"blockNumber"`
+	BlockHash      common.Hash    `json:"blockHash"`
+	Index          hexutil.Uint64 `json:"index"`
+	ValidatorIndex hexutil.Uint64 `json:"validatorIndex"`
+	Address        common.Address `json:"address"`
+	Amount         hexutil.Uint64 `json:"amount"` // in GWei
+}
+
+// WithdrawalsByAddress is a page of the withdrawals to an address, ordered by their position in the chain
+type WithdrawalsByAddress struct {
+	Withdrawals []*RPCWithdrawal `json:"withdrawals"`
+	// Next is the block to start the next page from, nil after the last page
+	Next *hexutil.Uint64 `json:"next"`
+}
+
+// GetWithdrawalsByAddress implements eth_getWithdrawalsByAddress. Returns the withdrawals to address of the blocks
+// starting at fromBlock, using the withdrawals index written by the execution. The withdrawals of a block are never
+// split across pages: a page ends with the first block reaching pageSize withdrawals.
+func (api *APIImpl) GetWithdrawalsByAddress(ctx context.Context, address common.Address, fromBlock *hexutil.Uint64, pageSize *uint64) (*WithdrawalsByAddress, error) {
+	limit := uint64(defaultWithdrawalsByAddressPageSize)
+	if pageSize != nil {
+		if *pageSize == 0 || *pageSize > maxWithdrawalsByAddressPageSize {
+			return nil, fmt.Errorf("pageSize must be between 1 and %d", maxWithdrawalsByAddressPageSize)
+		}
+		limit = *pageSize
+	}
+
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
+	fromTxNum := 0
+	if fromBlock != nil {
+		txNum, err := txNumsReader.Min(tx, uint64(*fromBlock))
+		if err != nil {
+			return nil, err
+		}
+		fromTxNum = int(txNum)
+	}
+	// the index misses the blocks executed before it existed: refuse a range it doesn't fully cover
+	indexFromBlock, err := withdrawalsIndexFromBlock(tx, txNumsReader)
+	if err != nil {
+		return nil, err
+	}
+	if indexFromBlock > 0 && (fromBlock == nil || uint64(*fromBlock) < indexFromBlock) {
+		return nil, fmt.Errorf("withdrawals index covers blocks from %d only, this node executed the earlier ones without it", indexFromBlock)
+	}
+	txNums, err := tx.IndexRange(kv.WithdrawalAddrIdx, address[:], fromTxNum, -1, order.Asc, kv.Unlim)
+	if err != nil {
+		return nil, err
+	}
+	it := rawdbv3.TxNums2BlockNums(tx, txNumsReader, txNums, order.Asc)
+	defer it.Close()
+
+	page := &WithdrawalsByAddress{Withdrawals: []*RPCWithdrawal{}}
+	for it.HasNext() {
+		_, blockNum, _, _, _, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(page.Withdrawals)) >= limit {
+			next := hexutil.Uint64(blockNum)
+			page.Next = &next
+			break
+		}
+
+		block, err := api._blockReader.BlockByNumber(ctx, tx, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", blockNum)
+		}
+		for _, w := range block.Withdrawals() {
+			if w.Address != address {
+				continue
+			}
+			page.Withdrawals = append(page.Withdrawals, &RPCWithdrawal{
+				BlockNumber:    hexutil.Uint64(blockNum),
+				BlockHash:      block.Hash(),
+				Index:          hexutil.Uint64(w.Index),
+				ValidatorIndex: hexutil.Uint64(w.Validator),
+				Address:        w.Address,
+				Amount:         hexutil.Uint64(w.Amount),
+			})
+		}
+	}
+	return page, nil
+}
+
+// withdrawalsIndexFromBlock returns the first block of the history fully covered by kv.WithdrawalAddrIdx: the index
+// has no files for the state files built before it existed, and nothing in the db for the blocks which were executed
+// before it existed.
+func withdrawalsIndexFromBlock(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader) (uint64, error) {
+	fromBlock, err := rawdb.ReadWithdrawalsIndexFromBlock(tx)
+	if err != nil {
+		return 0, err
+	}
+	fromTxNum := tx.IndexStartFrom(kv.WithdrawalAddrIdx)
+	if fromTxNum == 0 {
+		return fromBlock, nil
+	}
+	ok, filesFromBlock, err := txNumsReader.FindBlockNum(tx, fromTxNum)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("withdrawals index starts at txNum %d, after the known blocks", fromTxNum)
+	}
+	return max(fromBlock, filesFromBlock), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/rawdb"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+func TestGetWithdrawalsByAddressCoverage(t *testing.T) {
+	m := mock.Mock(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 5, func(i int, block *core.BlockGen) {})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+	ctx := context.Background()
+	from := func(blockNum uint64) *hexutil.Uint64 {
+		n := hexutil.Uint64(blockNum)
+		return &n
+	}
+
+	// all blocks executed with the index
+	page, err := api.GetWithdrawalsByAddress(ctx, common.Address{1}, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, page.Withdrawals)
+	require.Nil(t, page.Next)
+
+	// the blocks up to 2 were executed by a version without the index
+	require.NoError(t, m.DB.Update(ctx, func(tx kv.RwTx) error {
+		return rawdb.WriteWithdrawalsIndexFromBlock(tx, 3)
+	}))
+	_, err = api.GetWithdrawalsByAddress(ctx, common.Address{1}, nil, nil)
+	require.ErrorContains(t, err, "withdrawals index covers blocks from 3 only")
+	_, err = api.GetWithdrawalsByAddress(ctx, common.Address{1}, from(2), nil)
+	require.ErrorContains(t, err, "withdrawals index covers blocks from 3 only")
+	page, err = api.GetWithdrawalsByAddress(ctx, common.Address{1}, from(3), nil)
+	require.NoError(t, err)
+	require.Empty(t, page.Withdrawals)
+}
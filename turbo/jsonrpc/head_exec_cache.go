@@ -0,0 +1,213 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/turbo/shards"
+)
+
+const headExecCacheLimit = 64 * datasize.MB
+
+// headExecOverlaysLimit is the number of the overlays of the head block kept, the least recently used are dropped
+const headExecOverlaysLimit = 8
+
+// postState is the txn index of the post-state of a block: the state after all its txns, rewards and withdrawals
+const postState = -1
+
+// headExecCache keeps the state of the head block seen by eth_call, eth_estimateGas and eth_callMany, so
+// back-to-back calls on top of the head block neither replay its txns nor re-read the same state.
+// There is an overlay per state of the head block: the post-state for eth_call and eth_estimateGas and the state
+// after the first n txns for eth_callMany. The overlays of eth_callMany hold the writes of the replayed txns on top
+// of the state of the parent block, all of them collect the state read through them.
+// Only the overlays of the head block are kept: they are dropped as soon as a call sees another head (new payload
+// or unwind). At most headExecOverlaysLimit overlays of headExecCacheLimit each are kept, the least recently used
+// are dropped first. The receipts and logs of the head block are cached by the receipts generator, by block hash.
+type headExecCache struct {
+	mu       sync.Mutex
+	hash     common.Hash                       // block the overlays belong to
+	overlays *simplelru.LRU[int, *headOverlay] // by the number of applied txns, postState for the post-state
+
+	hits, misses atomic.Uint64
+}
+
+func newHeadExecCache() *headExecCache {
+	overlays, err := simplelru.NewLRU[int, *headOverlay](headExecOverlaysLimit, nil)
+	if err != nil {
+		panic(err)
+	}
+	return &headExecCache{overlays: overlays}
+}
+
+func newHeadExecOverlay() *shards.StateCache { return shards.NewStateCache(32, headExecCacheLimit) }
+
+// get returns the overlay of the state after the first txnIndex txns of the head block headHash, or nil.
+func (c *headExecCache) get(headHash common.Hash, txnIndex int) *headOverlay {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hash != headHash { // head has changed
+		c.hash = headHash
+		c.overlays.Purge()
+	}
+	o, ok := c.overlays.Get(txnIndex)
+	if !ok {
+		c.misses.Add(1)
+		return nil
+	}
+	c.hits.Add(1)
+	return o
+}
+
+// put stores the writes of the first txnIndex txns of the head block headHash as the overlay of the state after
+// them and returns it. The overlay stored by a concurrent call is kept.
+func (c *headExecCache) put(headHash common.Hash, txnIndex int, writes *shards.StateCache) *headOverlay {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hash != headHash {
+		c.hash = headHash
+		c.overlays.Purge()
+	}
+	if o, ok := c.overlays.Get(txnIndex); ok {
+		return o
+	}
+	o := &headOverlay{cache: writes}
+	c.overlays.Add(txnIndex, o)
+	return o
+}
+
+// postState returns the overlay of the post-state of the head block headHash: there is nothing to replay for it.
+func (c *headExecCache) postState(headHash common.Hash) *headOverlay {
+	if o := c.get(headHash, postState); o != nil {
+		return o
+	}
+	return c.put(headHash, postState, newHeadExecOverlay())
+}
+
+// headStateReader returns r reading through the cached post-state of the block blockHash when it is the head block.
+func (api *BaseAPI) headStateReader(r state.StateReader, blockHash common.Hash, latest bool) state.StateReader {
+	if !latest || api.headExec == nil {
+		return r
+	}
+	return api.headExec.postState(blockHash).reader(r)
+}
+
+// headOverlay is a shards.StateCache shared by concurrent calls, its entries are never modified once stored.
+type headOverlay struct {
+	mu    sync.Mutex
+	cache *shards.StateCache
+}
+
+// reader returns the reader of the state of the overlay, r is the reader of the state the overlay is on top of.
+func (o *headOverlay) reader(r state.StateReader) state.StateReader {
+	return &headOverlayReader{r: r, o: o}
+}
+
+// headOverlayReader is state.CachedReader over a headOverlay: r is read outside the lock.
+type headOverlayReader struct {
+	r state.StateReader
+	o *headOverlay
+}
+
+func (hr *headOverlayReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	addrBytes := address.Bytes()
+	hr.o.mu.Lock()
+	a, ok := hr.o.cache.GetAccount(addrBytes)
+	hr.o.mu.Unlock()
+	if ok {
+		return a, nil
+	}
+	a, err := hr.r.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	hr.o.mu.Lock()
+	defer hr.o.mu.Unlock()
+	if a == nil {
+		hr.o.cache.SetAccountAbsent(addrBytes)
+	} else {
+		hr.o.cache.SetAccountRead(addrBytes, a)
+	}
+	return a, nil
+}
+
+func (hr *headOverlayReader) ReadAccountDataForDebug(address common.Address) (*accounts.Account, error) {
+	return hr.ReadAccountData(address)
+}
+
+func (hr *headOverlayReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	addrBytes := address.Bytes()
+	hr.o.mu.Lock()
+	s, ok := hr.o.cache.GetStorage(addrBytes, incarnation, key.Bytes())
+	hr.o.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+	v, err := hr.r.ReadAccountStorage(address, incarnation, key)
+	if err != nil {
+		return nil, err
+	}
+	hr.o.mu.Lock()
+	defer hr.o.mu.Unlock()
+	if len(v) == 0 {
+		hr.o.cache.SetStorageAbsent(addrBytes, incarnation, key.Bytes())
+	} else {
+		hr.o.cache.SetStorageRead(addrBytes, incarnation, key.Bytes(), v)
+	}
+	return v, nil
+}
+
+func (hr *headOverlayReader) ReadAccountCode(address common.Address, incarnation uint64) ([]byte, error) {
+	hr.o.mu.Lock()
+	c, ok := hr.o.cache.GetCode(address.Bytes(), incarnation)
+	hr.o.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	c, err := hr.r.ReadAccountCode(address, incarnation)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) <= 1024 {
+		hr.o.mu.Lock()
+		hr.o.cache.SetCodeRead(address.Bytes(), incarnation, c)
+		hr.o.mu.Unlock()
+	}
+	return c, nil
+}
+
+func (hr *headOverlayReader) ReadAccountCodeSize(address common.Address, incarnation uint64) (int, error) {
+	c, err := hr.ReadAccountCode(address, incarnation)
+	return len(c), err
+}
+
+func (hr *headOverlayReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	hr.o.mu.Lock()
+	deleted := hr.o.cache.GetDeletedAccount(address.Bytes())
+	hr.o.mu.Unlock()
+	if deleted != nil {
+		return deleted.Incarnation, nil
+	}
+	return hr.r.ReadAccountIncarnation(address)
+}
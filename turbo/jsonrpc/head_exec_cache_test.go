@@ -0,0 +1,137 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/adapter/ethapi"
+)
+
+// countingReader counts the account reads reaching the underlying state
+type countingReader struct {
+	state.StateReader
+	accounts map[common.Address]*accounts.Account
+	reads    int
+}
+
+func (r *countingReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	r.reads++
+	return r.accounts[address], nil
+}
+
+func TestHeadExecCache(t *testing.T) {
+	c := newHeadExecCache()
+	head, next := common.HexToHash("0x01"), common.HexToHash("0x02")
+	addr, other := common.HexToAddress("0xaa"), common.HexToAddress("0xbb")
+	require.Nil(t, c.get(head, 1))
+
+	writes := newHeadExecOverlay()
+	writes.SetAccountWrite(addr.Bytes(), &accounts.Account{Nonce: 7})
+	c.put(head, 1, writes)
+
+	// the overlay is returned only for the same block and the same number of replayed txns
+	require.Nil(t, c.get(head, 2))
+	base := &countingReader{accounts: map[common.Address]*accounts.Account{other: {Nonce: 1}}}
+	r := c.get(head, 1).reader(base)
+	acc, err := r.ReadAccountData(addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), acc.Nonce)
+	require.Zero(t, base.reads)
+
+	// the state read through the overlay is shared by the next calls
+	acc, err = r.ReadAccountData(other)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), acc.Nonce)
+	acc, err = c.get(head, 1).reader(base).ReadAccountData(other)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), acc.Nonce)
+	require.Equal(t, 1, base.reads)
+
+	// the post-state of the block is there without replaying anything
+	require.Nil(t, c.get(head, postState))
+	require.Same(t, c.postState(head), c.postState(head))
+
+	// new head drops the overlays
+	require.Nil(t, c.get(next, 1))
+	require.Nil(t, c.get(head, 1))
+}
+
+func TestHeadExecCacheEviction(t *testing.T) {
+	c := newHeadExecCache()
+	head := common.HexToHash("0x01")
+	post := c.postState(head)
+	for txnIndex := 0; txnIndex < headExecOverlaysLimit; txnIndex++ {
+		c.put(head, txnIndex, newHeadExecOverlay())
+		// the post-state is used in between, the least recently used overlays are the first txn indexes
+		require.Same(t, post, c.postState(head))
+	}
+
+	// the overlays are capped, the least recently used one is dropped
+	require.Equal(t, headExecOverlaysLimit, c.overlays.Len())
+	require.Nil(t, c.get(head, 0))
+	require.NotNil(t, c.get(head, 1))
+	require.Same(t, post, c.get(head, postState))
+}
+
+func TestHeadExecCacheHits(t *testing.T) {
+	m, bankAddress, contractAddress := chainWithDeployedContract(t)
+	base := newBaseApiForTest(m)
+	api := NewEthAPI(base, m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+	ctx, latest := context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	// retrieve() returns the value stored by the transaction of the head block
+	callData := hexutility.Bytes(hexutil.MustDecode("0x2e64cec1"))
+	callArgs := ethapi.CallArgs{From: &bankAddress, To: &contractAddress, Data: &callData}
+	for i := 0; i < 2; i++ {
+		result, err := api.Call(ctx, callArgs, latest, nil)
+		require.NoError(t, err)
+		require.Equal(t, common.BytesToHash([]byte{2}).Bytes(), []byte(result))
+	}
+	require.Equal(t, uint64(1), base.headExec.misses.Load())
+	require.Equal(t, uint64(1), base.headExec.hits.Load())
+
+	// eth_estimateGas on the head block reads the same post-state
+	_, err := api.EstimateGas(ctx, &callArgs, &latest, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), base.headExec.misses.Load())
+	require.Equal(t, uint64(2), base.headExec.hits.Load())
+
+	// eth_callMany replays the txn of the head block once
+	txIndex := 1
+	bundles := []Bundle{{Transactions: []ethapi.CallArgs{{From: &bankAddress, To: &contractAddress, Data: &callData}}}}
+	var results [][][]map[string]interface{}
+	for i := 0; i < 2; i++ {
+		res, err := api.CallMany(ctx, bundles, StateContext{BlockNumber: latest, TransactionIndex: &txIndex}, nil, nil)
+		require.NoError(t, err)
+		results = append(results, res)
+	}
+	require.Equal(t, common.BytesToHash([]byte{2}).Hex()[2:], results[0][0][0]["value"])
+	require.Equal(t, results[0], results[1])
+	require.Equal(t, uint64(2), base.headExec.misses.Load())
+	require.Equal(t, uint64(3), base.headExec.hits.Load())
+}
@@ -24,7 +24,7 @@ import (
 	"github.com/erigontech/erigon-lib/kv/order"
 )
 
-// StorageRangeResult is the result of a debug_storageRangeAt API call.
+// StorageRangeResult is the result of a debug_storageRangeAt or erigon_getStorageRange API call.
 type StorageRangeResult struct {
 	Storage storageMap      `json:"storage"`
 	NextKey *libcommon.Hash `json:"nextKey"` // nil if Storage includes the last key in the trie.
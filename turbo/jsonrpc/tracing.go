@@ -416,20 +416,30 @@ func (api *PrivateDebugAPIImpl) TraceCall(ctx context.Context, args ethapi.CallA
 		}
 	}
 
-	var baseFee *uint256.Int
-	if header.BaseFee != nil {
-		var overflow bool
-		baseFee, overflow = uint256.FromBig(header.BaseFee)
-		if overflow {
-			return errors.New("header.BaseFee uint256 overflow")
+	blockCtx := transactions.NewEVMBlockContext(engine, header, blockNrOrHash.RequireCanonical, dbtx, api._blockReader, chainConfig)
+	if config != nil && config.BlockOverrides != nil {
+		overrideBlockHash := make(map[uint64]common.Hash)
+		config.BlockOverrides.Override(&blockCtx, overrideBlockHash)
+		if len(overrideBlockHash) > 0 {
+			getHash := blockCtx.GetHash
+			blockCtx.GetHash = func(i uint64) common.Hash {
+				if hash, ok := overrideBlockHash[i]; ok {
+					return hash
+				}
+				return getHash(i)
+			}
 		}
 	}
+
+	var baseFee *uint256.Int
+	if header.BaseFee != nil || (config != nil && config.BlockOverrides != nil && config.BlockOverrides.BaseFee != nil) {
+		baseFee = blockCtx.BaseFee
+	}
 	msg, err := args.ToMessage(api.GasCap, baseFee)
 	if err != nil {
 		return fmt.Errorf("convert args to msg: %v", err)
 	}
 
-	blockCtx := transactions.NewEVMBlockContext(engine, header, blockNrOrHash.RequireCanonical, dbtx, api._blockReader, chainConfig)
 	txCtx := core.NewEVMTxContext(msg)
 	// Trace the transaction and return
 	_, err = transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout)
@@ -556,7 +566,7 @@ func (api *PrivateDebugAPIImpl) TraceCallMany(ctx context.Context, bundles []Bun
 	for bundleIndex, bundle := range bundles {
 		stream.WriteArrayStart()
 		// first change blockContext
-		blockHeaderOverride(&blockCtx, bundle.BlockOverride, overrideBlockHash)
+		bundle.BlockOverride.Override(&blockCtx, overrideBlockHash)
 		ibs.Reset()
 		for txnIndex, txn := range bundle.Transactions {
 			if txn.Gas == nil || *(txn.Gas) == 0 {
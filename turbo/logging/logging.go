@@ -214,10 +214,11 @@ func initSeparatedLogging(
 
 	var consoleHandler log.Handler
 
+	// the levels can be changed at runtime through admin_setLogLevel and admin_setLogTarget
 	if consoleJson {
-		consoleHandler = log.LvlFilterHandler(consoleLevel, log.StreamHandler(os.Stderr, log.JsonFormat()))
+		consoleHandler = log.DynamicLvlFilterHandler(consoleLevel, log.StreamHandler(os.Stderr, log.JsonFormat()))
 	} else {
-		consoleHandler = log.LvlFilterHandler(consoleLevel, log.StderrHandler)
+		consoleHandler = log.DynamicLvlFilterHandler(consoleLevel, log.StderrHandler)
 	}
 	logger.SetHandler(consoleHandler)
 
@@ -245,7 +246,7 @@ func initSeparatedLogging(
 	}
 	userLog := log.StreamHandler(lumberjack, dirFormat)
 
-	mux := log.MultiHandler(consoleHandler, log.LvlFilterHandler(dirLevel, userLog))
+	mux := log.MultiHandler(consoleHandler, log.DynamicLvlFilterHandler(dirLevel, userLog))
 	logger.SetHandler(mux)
 	logger.Info("logging to file system", "log dir", dirPath, "file prefix", filePrefix, "log level", dirLevel, "json", dirJson)
 }
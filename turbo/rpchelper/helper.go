@@ -158,19 +158,77 @@ func CreateHistoryStateReader(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReade
 	r := state.NewHistoryReaderV3()
 	r.SetTx(tx)
 	//r.SetTrace(true)
+	txNum, err := historyTxNum(tx, txNumsReader, blockNumber, txnIndex)
+	if err != nil {
+		return r, err
+	}
+	r.SetTxNum(txNum)
+	return r, nil
+}
+
+// historyTxNum returns the txNum the state before txnIndex of blockNumber is read as of.
+func historyTxNum(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, blockNumber uint64, txnIndex int) (uint64, error) {
 	minTxNum, err := txNumsReader.Min(tx, blockNumber)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	txNum := uint64(int(minTxNum) + txnIndex + /* 1 system txNum in beginning of block */ 1)
-	earliestTxNum := r.StateHistoryStartFrom()
-	if txNum < earliestTxNum {
+	earliestTxNum := state.NewHistoryReaderV3()
+	earliestTxNum.SetTx(tx)
+	if txNum < earliestTxNum.StateHistoryStartFrom() {
 		// data available only starting from earliestTxNum, throw error to avoid unintended
 		// consequences of using this StateReader
-		return r, state.PrunedError
+		return txNum, state.PrunedError
 	}
-	r.SetTxNum(txNum)
-	return r, nil
+	return txNum, nil
+}
+
+// HistoricalStateReaderFactory pins the txNum of an RPC request, so all the state readers it creates
+// read the same historical state and share the accounts, storage and code already read by each other.
+// It is meant to live as long as the request and its tx: e.g. every iteration of eth_estimateGas
+// reads from the cache instead of the history files.
+type HistoricalStateReaderFactory struct {
+	tx    kv.TemporalTx
+	cache *state.HistoryReaderCache
+}
+
+func NewHistoricalStateReaderFactory(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, blockNumber uint64, txnIndex int) (*HistoricalStateReaderFactory, error) {
+	txNum, err := historyTxNum(tx, txNumsReader, blockNumber, txnIndex)
+	if err != nil {
+		return nil, err
+	}
+	return &HistoricalStateReaderFactory{tx: tx, cache: state.NewHistoryReaderCache(txNum)}, nil
+}
+
+func (f *HistoricalStateReaderFactory) TxNum() uint64 { return f.cache.TxNum() }
+
+func (f *HistoricalStateReaderFactory) NewReader() state.StateReader {
+	r := state.NewHistoryReaderV3()
+	r.SetTx(f.tx)
+	r.SetCache(f.cache)
+	return r
+}
+
+// CreatePinnedStateReader is CreateStateReader reading the historical state through a HistoricalStateReaderFactory
+func CreatePinnedStateReader(ctx context.Context, tx kv.TemporalTx, br services.FullBlockReader, blockNrOrHash rpc.BlockNumberOrHash, txnIndex int, filters *Filters, stateCache kvcache.Cache) (state.StateReader, error) {
+	blockNumber, _, latest, _, err := _GetBlockNumber(ctx, true, blockNrOrHash, tx, br, filters)
+	if err != nil {
+		return nil, err
+	}
+	return CreatePinnedStateReaderFromBlockNumber(ctx, tx, rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, br)), blockNumber, latest, txnIndex, stateCache)
+}
+
+// CreatePinnedStateReaderFromBlockNumber is CreateStateReaderFromBlockNumber for requests reading the same state
+// many times: the historical state is read through a HistoricalStateReaderFactory.
+func CreatePinnedStateReaderFromBlockNumber(ctx context.Context, tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, blockNumber uint64, latest bool, txnIndex int, stateCache kvcache.Cache) (state.StateReader, error) {
+	if latest {
+		return CreateStateReaderFromBlockNumber(ctx, tx, txNumsReader, blockNumber, latest, txnIndex, stateCache, "")
+	}
+	f, err := NewHistoricalStateReaderFactory(tx, txNumsReader, blockNumber+1, txnIndex)
+	if err != nil {
+		return nil, err
+	}
+	return f.NewReader(), nil
 }
 
 func NewLatestDomainStateReader(sd *state2.SharedDomains) state.StateReader {
@@ -96,7 +96,10 @@ type BodyReader interface {
 }
 
 type TxnReader interface {
-	TxnLookup(ctx context.Context, tx kv.Getter, txnHash common.Hash) (blockNum uint64, txNum uint64, ok bool, err error)
+	// TxnLookup - txNum is the txNum of the transaction itself (not of the system transaction opening its block)
+	TxnLookup(ctx context.Context, tx kv.Tx, txnHash common.Hash) (blockNum uint64, txNum uint64, ok bool, err error)
+	// TxnByHash - same as TxnLookup, also returning the transaction found by the lookup
+	TxnByHash(ctx context.Context, tx kv.Tx, txnHash common.Hash) (txn types.Transaction, blockNum uint64, txNum uint64, ok bool, err error)
 	TxnByIdxInBlock(ctx context.Context, tx kv.Getter, blockNum uint64, i int) (txn types.Transaction, err error)
 	RawTransactions(ctx context.Context, tx kv.Getter, fromBlock, toBlock uint64) (txs [][]byte, err error)
 	FirstTxnNumNotInSnapshots() uint64
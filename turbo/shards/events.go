@@ -17,9 +17,11 @@
 package shards
 
 import (
+	"slices"
 	"sync"
 	"sync/atomic"
 
+	"github.com/erigontech/erigon-lib/app"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
@@ -37,66 +39,36 @@ type PendingTxsSubscription func([]types.Transaction) error
 type LogsSubscription func([]*remote.SubscribeLogsReply) error
 
 // Events manages event subscriptions and dissimination. Thread-safe
+// New headers, logs and snapshots are published on the node's own event bus, separate from the lifecycle events of
+// app.DefaultEventBus: one process may run several nodes (in tests).
 type Events struct {
-	id                        int
-	headerSubscriptions       map[int]chan [][]byte
-	newSnapshotSubscription   map[int]chan struct{}
+	bus                       *app.EventBus
 	pendingLogsSubscriptions  map[int]PendingLogsSubscription
 	pendingBlockSubscriptions map[int]PendingBlockSubscription
 	pendingTxsSubscriptions   map[int]PendingTxsSubscription
-	logsSubscriptions         map[int]chan []*remote.SubscribeLogsReply
 	hasLogSubscriptions       bool
 	lock                      sync.RWMutex
 }
 
 func NewEvents() *Events {
 	return &Events{
-		headerSubscriptions:       map[int]chan [][]byte{},
+		bus:                       app.NewEventBus(),
 		pendingLogsSubscriptions:  map[int]PendingLogsSubscription{},
 		pendingBlockSubscriptions: map[int]PendingBlockSubscription{},
 		pendingTxsSubscriptions:   map[int]PendingTxsSubscription{},
-		logsSubscriptions:         map[int]chan []*remote.SubscribeLogsReply{},
-		newSnapshotSubscription:   map[int]chan struct{}{},
 	}
 }
 
-func (e *Events) AddHeaderSubscription() (chan [][]byte, func()) {
-	e.lock.Lock()
-	defer e.lock.Unlock()
-	ch := make(chan [][]byte, 8)
-	e.id++
-	id := e.id
-	e.headerSubscriptions[id] = ch
-	return ch, func() {
-		delete(e.headerSubscriptions, id)
-		close(ch)
-	}
+func (e *Events) AddHeaderSubscription() (<-chan [][]byte, func()) {
+	return app.Subscribe[[][]byte](e.bus, 8)
 }
 
-func (e *Events) AddNewSnapshotSubscription() (chan struct{}, func()) {
-	e.lock.Lock()
-	defer e.lock.Unlock()
-	ch := make(chan struct{}, 8)
-	e.id++
-	id := e.id
-	e.newSnapshotSubscription[id] = ch
-	return ch, func() {
-		delete(e.newSnapshotSubscription, id)
-		close(ch)
-	}
+func (e *Events) AddNewSnapshotSubscription() (<-chan struct{}, func()) {
+	return app.Subscribe[struct{}](e.bus, 8)
 }
 
-func (e *Events) AddLogsSubscription() (chan []*remote.SubscribeLogsReply, func()) {
-	e.lock.Lock()
-	defer e.lock.Unlock()
-	ch := make(chan []*remote.SubscribeLogsReply, 8)
-	e.id++
-	id := e.id
-	e.logsSubscriptions[id] = ch
-	return ch, func() {
-		delete(e.logsSubscriptions, id)
-		close(ch)
-	}
+func (e *Events) AddLogsSubscription() (<-chan []*remote.SubscribeLogsReply, func()) {
+	return app.Subscribe[[]*remote.SubscribeLogsReply](e.bus, 8)
 }
 
 func (e *Events) EmptyLogSubsctiption(empty bool) {
@@ -124,19 +96,11 @@ func (e *Events) AddPendingBlockSubscription(s PendingBlockSubscription) {
 }
 
 func (e *Events) OnNewSnapshot() {
-	e.lock.Lock()
-	defer e.lock.Unlock()
-	for _, ch := range e.newSnapshotSubscription {
-		common.PrioritizedSend(ch, struct{}{})
-	}
+	app.Publish(e.bus, struct{}{})
 }
 
 func (e *Events) OnNewHeader(newHeadersRlp [][]byte) {
-	e.lock.Lock()
-	defer e.lock.Unlock()
-	for _, ch := range e.headerSubscriptions {
-		common.PrioritizedSend(ch, newHeadersRlp)
-	}
+	app.Publish(e.bus, newHeadersRlp)
 }
 
 func (e *Events) OnNewPendingLogs(logs types.Logs) {
@@ -150,11 +114,7 @@ func (e *Events) OnNewPendingLogs(logs types.Logs) {
 }
 
 func (e *Events) OnLogs(logs []*remote.SubscribeLogsReply) {
-	e.lock.Lock()
-	defer e.lock.Unlock()
-	for _, ch := range e.logsSubscriptions {
-		common.PrioritizedSend(ch, logs)
-	}
+	app.Publish(e.bus, logs)
 }
 
 type Notifications struct {
@@ -184,66 +144,154 @@ func NewNotifications(StateChangesConsumer StateChangeConsumer) *Notifications {
 // - need send notification after `rwtx.Commit` (or user will recv notification, but can't request new data by RPC)
 type RecentLogs struct {
 	receipts map[uint64]types.Receipts
+	notified map[uint64]struct{} // blocks which logs were sent to subscribers
+	unwound  []types.Receipts    // receipts of the notified blocks undone by committed unwinds, not sent yet
 	limit    uint64
 	mu       sync.Mutex
+
+	// unwinds happen in the tx of the sync cycle, which may be rolled back: they are kept pending and resolved by Notify,
+	// after the commit, against the canonical chain
+	pendingUnwind *uint64                   // the lowest unwind point since previous Notify
+	replaced      map[uint64]types.Receipts // notified receipts replaced by Add of another block since previous Notify
 }
 
 func NewRecentLogs(limit uint64) *RecentLogs {
-	return &RecentLogs{receipts: make(map[uint64]types.Receipts, limit), limit: limit}
+	return &RecentLogs{receipts: make(map[uint64]types.Receipts, limit), notified: make(map[uint64]struct{}, limit), replaced: map[uint64]types.Receipts{}, limit: limit}
 }
 
-// [from,to)
-func (r *RecentLogs) Notify(n *Events, from, to uint64, isUnwind bool) {
-	if !n.HasLogSubsriptions() {
-		return
-	}
+// Notify sends logs of the blocks undone by unwinds since previous call with `removed=true`,
+// then logs of the blocks [from,to). Each of them is sent by 1 message: a slow subscriber
+// drops the oldest messages of the channel, it must not lose a part of a reorg.
+// Must be called after the commit of the sync cycle: canonicalHash reads the committed canonical chain, the blocks of
+// a rolled back unwind are still canonical and their logs are not removed.
+func (r *RecentLogs) Notify(n *Events, from, to uint64, canonicalHash func(blockNum uint64) (common.Hash, error)) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	for bn, receipts := range r.receipts {
+	if err := r.resolveUnwinds(canonicalHash); err != nil {
+		return err
+	}
+	unwound := r.unwound
+	r.unwound = nil
+	if !n.HasLogSubsriptions() {
+		return nil
+	}
+
+	// undo in reverse order: from the newest block to the oldest, from the last log of a block to the first
+	var removed []*remote.SubscribeLogsReply
+	for _, receipts := range unwound {
+		removed = appendLogsReplies(removed, receipts, true)
+	}
+	if len(removed) > 0 {
+		slices.Reverse(removed)
+		n.OnLogs(removed)
+	}
+
+	for bn := range r.receipts {
 		if bn+r.limit < from { //evict old
 			delete(r.receipts, bn)
+			delete(r.notified, bn)
+		}
+	}
+	var added []*remote.SubscribeLogsReply
+	for bn := from; bn < to; bn++ {
+		if receipts, ok := r.receipts[bn]; ok {
+			added = appendLogsReplies(added, receipts, false)
+			r.notified[bn] = struct{}{}
+		}
+	}
+	if len(added) > 0 {
+		n.OnLogs(added)
+	}
+	return nil
+}
+
+// resolveUnwinds queues the logs of the notified blocks which are not canonical any more after the pending unwinds and
+// replacements, and restores the replaced receipts of the blocks which still are
+func (r *RecentLogs) resolveUnwinds(canonicalHash func(blockNum uint64) (common.Hash, error)) error {
+	var blockNums []uint64
+	for bn := range r.replaced {
+		blockNums = append(blockNums, bn)
+	}
+	if r.pendingUnwind != nil {
+		for bn := range r.receipts {
+			if _, ok := r.replaced[bn]; !ok && bn > *r.pendingUnwind {
+				blockNums = append(blockNums, bn)
+			}
+		}
+	}
+	slices.Sort(blockNums)
+	for _, bn := range blockNums {
+		canonical, err := canonicalHash(bn)
+		if err != nil {
+			return err
+		}
+		if old, ok := r.replaced[bn]; ok {
+			if receiptsBlockHash(old) == canonical { // the replacing block was rolled back
+				r.receipts[bn] = old
+				r.notified[bn] = struct{}{}
+			} else {
+				r.unwound = append(r.unwound, old)
+			}
 			continue
 		}
-		if bn < from || bn >= to {
+		if receiptsBlockHash(r.receipts[bn]) == canonical { // the unwind was rolled back
 			continue
 		}
+		if _, ok := r.notified[bn]; ok {
+			r.unwound = append(r.unwound, r.receipts[bn])
+		}
+		delete(r.receipts, bn)
+		delete(r.notified, bn)
+	}
+	r.pendingUnwind = nil
+	clear(r.replaced)
+	return nil
+}
 
-		var blockNum uint64
-		reply := make([]*remote.SubscribeLogsReply, 0, len(receipts))
-		for _, receipt := range receipts {
-			if receipt == nil {
-				continue
-			}
+func appendLogsReplies(reply []*remote.SubscribeLogsReply, receipts types.Receipts, removed bool) []*remote.SubscribeLogsReply {
+	for _, receipt := range receipts {
+		if receipt == nil {
+			continue
+		}
+		blockNum := receipt.BlockNumber.Uint64()
+		//txIndex++
+		//// bor transactions are at the end of the bodies transactions (added manually but not actually part of the block)
+		//if txIndex == uint64(len(block.Transactions())) {
+		//	txHash = bortypes.ComputeBorTxHash(blockNum, block.Hash())
+		//} else {
+		//	txHash = block.Transactions()[txIndex].Hash()
+		//}
 
-			blockNum = receipt.BlockNumber.Uint64()
-			//txIndex++
-			//// bor transactions are at the end of the bodies transactions (added manually but not actually part of the block)
-			//if txIndex == uint64(len(block.Transactions())) {
-			//	txHash = bortypes.ComputeBorTxHash(blockNum, block.Hash())
-			//} else {
-			//	txHash = block.Transactions()[txIndex].Hash()
-			//}
-
-			for _, l := range receipt.Logs {
-				res := &remote.SubscribeLogsReply{
-					Address:          gointerfaces.ConvertAddressToH160(l.Address),
-					BlockHash:        gointerfaces.ConvertHashToH256(receipt.BlockHash),
-					BlockNumber:      blockNum,
-					Data:             l.Data,
-					LogIndex:         uint64(l.Index),
-					Topics:           make([]*types2.H256, 0, len(l.Topics)),
-					TransactionHash:  gointerfaces.ConvertHashToH256(receipt.TxHash),
-					TransactionIndex: uint64(l.TxIndex),
-					Removed:          isUnwind,
-				}
-				for _, topic := range l.Topics {
-					res.Topics = append(res.Topics, gointerfaces.ConvertHashToH256(topic))
-				}
-				reply = append(reply, res)
+		for _, l := range receipt.Logs {
+			res := &remote.SubscribeLogsReply{
+				Address:          gointerfaces.ConvertAddressToH160(l.Address),
+				BlockHash:        gointerfaces.ConvertHashToH256(receipt.BlockHash),
+				BlockNumber:      blockNum,
+				Data:             l.Data,
+				LogIndex:         uint64(l.Index),
+				Topics:           make([]*types2.H256, 0, len(l.Topics)),
+				TransactionHash:  gointerfaces.ConvertHashToH256(receipt.TxHash),
+				TransactionIndex: uint64(l.TxIndex),
+				Removed:          removed,
+			}
+			for _, topic := range l.Topics {
+				res.Topics = append(res.Topics, gointerfaces.ConvertHashToH256(topic))
 			}
+			reply = append(reply, res)
 		}
+	}
+	return reply
+}
 
-		n.OnLogs(reply)
+// Unwind - the blocks after `unwindPoint` are undone: once the unwind is committed, next Notify sends their logs
+// with `removed=true` (only if they were sent before).
+// Must be called by the unwind of execution - even if the unwind and the re-execution of the new blocks
+// happen in different sync cycles.
+func (r *RecentLogs) Unwind(unwindPoint uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pendingUnwind == nil || unwindPoint < *r.pendingUnwind {
+		r.pendingUnwind = &unwindPoint
 	}
 }
 
@@ -254,18 +302,24 @@ func (r *RecentLogs) Add(receipts types.Receipts) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	var blockNum uint64
+	var blockHash common.Hash
 	var ok bool
 	// find non-nil receipt
 	for _, receipt := range receipts {
 		if receipt != nil {
 			ok = true
-			blockNum = receipt.BlockNumber.Uint64()
+			blockNum, blockHash = receipt.BlockNumber.Uint64(), receipt.BlockHash
 			break
 		}
 	}
 	if !ok {
 		return
 	}
+	if _, notified := r.notified[blockNum]; notified && receiptsBlockHash(r.receipts[blockNum]) != blockHash {
+		// another block with the same number: the notified one is undone, unless the tx adding this one is rolled back
+		r.replaced[blockNum] = r.receipts[blockNum]
+		delete(r.notified, blockNum)
+	}
 	r.receipts[blockNum] = receipts
 
 	//enforce `limit`: drop all items older than `limit` blocks
@@ -275,6 +329,16 @@ func (r *RecentLogs) Add(receipts types.Receipts) {
 	for bn := range r.receipts {
 		if bn+r.limit < blockNum {
 			delete(r.receipts, bn)
+			delete(r.notified, bn)
+		}
+	}
+}
+
+func receiptsBlockHash(receipts types.Receipts) common.Hash {
+	for _, receipt := range receipts {
+		if receipt != nil {
+			return receipt.BlockHash
 		}
 	}
+	return common.Hash{}
 }
@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/erigontech/erigon-lib/common"
+	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/stretchr/testify/require"
 )
@@ -53,3 +55,209 @@ func TestRecentLogs(t *testing.T) {
 		require.Equal(t, 2, len(e.receipts))
 	})
 }
+
+func TestRecentLogsReorg(t *testing.T) {
+	t.Parallel()
+	subscribe := func(t *testing.T, e *Events) <-chan []*remote.SubscribeLogsReply {
+		ch, unsubscribe := e.AddLogsSubscription()
+		t.Cleanup(unsubscribe)
+		e.EmptyLogSubsctiption(false)
+		return ch
+	}
+	recv := func(t *testing.T, ch <-chan []*remote.SubscribeLogsReply) []*remote.SubscribeLogsReply {
+		select {
+		case logs := <-ch:
+			return logs
+		default:
+			t.Fatal("no logs notification")
+			return nil
+		}
+	}
+
+	// deep reorgs are seen on Polygon: 100+ blocks
+	const head, depth = uint64(300), uint64(160)
+	t.Run("deep reorg", func(t *testing.T) {
+		e, events := newReorgChain(), NewEvents()
+		ch := subscribe(t, events)
+		for bn := uint64(1); bn <= head; bn++ {
+			e.add(bn, 'a')
+		}
+		e.notify(t, events, 1, head+1)
+		require.Len(t, recv(t, ch), int(head)*4)
+
+		unwindPoint := head - depth
+		e.unwind(unwindPoint)
+		for bn := unwindPoint + 1; bn <= head+1; bn++ {
+			e.add(bn, 'b')
+		}
+		e.notify(t, events, unwindPoint+1, head+2)
+
+		removed := recv(t, ch)
+		require.Len(t, removed, int(depth)*4)
+		for i, l := range removed {
+			require.True(t, l.Removed)
+			require.Equal(t, byte('a'), l.Data[0])
+			require.Equal(t, head-uint64(i/4), l.BlockNumber) // the newest block first
+			require.Equal(t, uint64(3-i%4), l.LogIndex)       // the last log of block first
+		}
+		added := recv(t, ch)
+		require.Len(t, added, int(depth+1)*4)
+		for i, l := range added {
+			require.False(t, l.Removed)
+			require.Equal(t, byte('b'), l.Data[0])
+			require.Equal(t, unwindPoint+1+uint64(i/4), l.BlockNumber)
+		}
+		require.Empty(t, ch)
+	})
+	t.Run("unwind and new blocks in different cycles", func(t *testing.T) {
+		e, events := newReorgChain(), NewEvents()
+		ch := subscribe(t, events)
+		for bn := uint64(1); bn <= head; bn++ {
+			e.add(bn, 'a')
+		}
+		e.notify(t, events, 1, head+1)
+		recv(t, ch)
+
+		// cycle 1: unwind only, nothing new is executed
+		unwindPoint := head - depth
+		e.unwind(unwindPoint)
+		e.notify(t, events, unwindPoint+1, unwindPoint+1)
+		removed := recv(t, ch)
+		require.Len(t, removed, int(depth)*4)
+		require.Empty(t, ch)
+
+		// cycle 2: new fork, one more unwind inside of it - before its blocks were notified
+		for bn := unwindPoint + 1; bn <= head; bn++ {
+			e.add(bn, 'b')
+		}
+		e.unwind(head - 16)
+		for bn := head - 15; bn <= head; bn++ {
+			e.add(bn, 'c')
+		}
+		e.notify(t, events, unwindPoint+1, head+1)
+		added := recv(t, ch)
+		require.Len(t, added, int(depth)*4)
+		for i, l := range added {
+			require.False(t, l.Removed)
+			require.Equal(t, unwindPoint+1+uint64(i/4), l.BlockNumber)
+			if l.BlockNumber > head-16 {
+				require.Equal(t, byte('c'), l.Data[0])
+			} else {
+				require.Equal(t, byte('b'), l.Data[0])
+			}
+		}
+		require.Empty(t, ch)
+	})
+	t.Run("new block without unwind", func(t *testing.T) {
+		e, events := newReorgChain(), NewEvents()
+		ch := subscribe(t, events)
+		for bn := uint64(1); bn <= head; bn++ {
+			e.add(bn, 'a')
+		}
+		e.notify(t, events, 1, head+1)
+		recv(t, ch)
+
+		e.add(head, 'a') // same block again
+		e.add(head-1, 'b')
+		e.notify(t, events, head-1, head)
+		removed := recv(t, ch)
+		require.Len(t, removed, 4)
+		for _, l := range removed {
+			require.True(t, l.Removed)
+			require.Equal(t, head-1, l.BlockNumber)
+			require.Equal(t, byte('a'), l.Data[0])
+		}
+		added := recv(t, ch)
+		require.Len(t, added, 4)
+		require.Equal(t, byte('b'), added[0].Data[0])
+		require.Empty(t, ch)
+	})
+	t.Run("rolled back unwind", func(t *testing.T) {
+		e, events := newReorgChain(), NewEvents()
+		ch := subscribe(t, events)
+		for bn := uint64(1); bn <= head; bn++ {
+			e.add(bn, 'a')
+		}
+		e.notify(t, events, 1, head+1)
+		recv(t, ch)
+
+		// the cycle fails after the unwind and the new fork: its tx is rolled back, the canonical chain stays on `a`
+		unwindPoint := head - depth
+		e.logs.Unwind(unwindPoint)
+		for bn := unwindPoint + 1; bn <= head+1; bn++ {
+			e.logs.Add(reorgReceipts(bn, 'b'))
+		}
+
+		e.add(head+1, 'a')
+		e.notify(t, events, head+1, head+2)
+		added := recv(t, ch)
+		require.Len(t, added, 4)
+		for _, l := range added {
+			require.False(t, l.Removed)
+			require.Equal(t, head+1, l.BlockNumber)
+			require.Equal(t, byte('a'), l.Data[0])
+		}
+		require.Empty(t, ch)
+		require.Empty(t, e.logs.unwound)
+		require.Equal(t, reorgBlockHash(head, 'a'), e.logs.receipts[head][0].BlockHash)
+	})
+	t.Run("no subscriptions", func(t *testing.T) {
+		e, events := newReorgChain(), NewEvents()
+		for bn := uint64(1); bn <= head; bn++ {
+			e.add(bn, 'a')
+		}
+		e.unwind(head - depth)
+		e.notify(t, events, 1, head-depth+1)
+		require.Empty(t, e.logs.unwound)
+	})
+}
+
+// reorgChain is a RecentLogs fed by sync cycles which are committed: the canonical chain follows the blocks added and
+// unwound. 2 receipts with 2 logs each per block, logs of fork `f` have `f` as first byte of their data.
+type reorgChain struct {
+	logs      *RecentLogs
+	canonical map[uint64]byte // the fork of every committed canonical block
+}
+
+func newReorgChain() *reorgChain {
+	return &reorgChain{logs: NewRecentLogs(512), canonical: map[uint64]byte{}}
+}
+
+func reorgBlockHash(bn uint64, f byte) common.Hash { return common.Hash{f, byte(bn >> 8), byte(bn)} }
+
+func reorgReceipts(bn uint64, f byte) types.Receipts {
+	res := make(types.Receipts, 2)
+	for i := range res {
+		res[i] = &types.Receipt{BlockNumber: new(big.Int).SetUint64(bn), BlockHash: reorgBlockHash(bn, f), TxHash: common.Hash{f, byte(bn), byte(i)}}
+		for j := 0; j < 2; j++ {
+			res[i].Logs = append(res[i].Logs, &types.Log{Data: []byte{f, byte(bn >> 8), byte(bn)}, TxIndex: uint(i), Index: uint(i*2 + j)})
+		}
+	}
+	return res
+}
+
+func (c *reorgChain) add(bn uint64, f byte) {
+	c.logs.Add(reorgReceipts(bn, f))
+	c.canonical[bn] = f
+}
+
+func (c *reorgChain) unwind(unwindPoint uint64) {
+	c.logs.Unwind(unwindPoint)
+	for bn := range c.canonical {
+		if bn > unwindPoint {
+			delete(c.canonical, bn)
+		}
+	}
+}
+
+func (c *reorgChain) canonicalHash(bn uint64) (common.Hash, error) {
+	f, ok := c.canonical[bn]
+	if !ok {
+		return common.Hash{}, nil
+	}
+	return reorgBlockHash(bn, f), nil
+}
+
+func (c *reorgChain) notify(t *testing.T, events *Events, from, to uint64) {
+	require.NoError(t, c.logs.Notify(events, from, to, c.canonicalHash))
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package era1
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// e2store entries are a header of the type (2 bytes), the length of the value (4 bytes) and 2 reserved zero bytes,
+// all little endian, followed by the value
+const entryHeaderSize = 8
+
+// maxEntrySize protects the reader from allocating for corrupted lengths
+const maxEntrySize = 1 << 30
+
+type e2storeWriter struct {
+	w       io.Writer
+	written int64
+}
+
+// write appends an entry and returns its offset
+func (w *e2storeWriter) write(typ uint16, value []byte) (int64, error) {
+	var header [entryHeaderSize]byte
+	binary.LittleEndian.PutUint16(header[:2], typ)
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(value)))
+	offset := w.written
+	n, err := w.w.Write(header[:])
+	w.written += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	n, err = w.w.Write(value)
+	w.written += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// readEntryHeader reads the type and the length of the value of the entry at the offset
+func readEntryHeader(r io.ReaderAt, offset int64) (uint16, uint32, error) {
+	var header [entryHeaderSize]byte
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return 0, 0, err
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return 0, 0, fmt.Errorf("e2store entry at %d: reserved bytes not zero", offset)
+	}
+	return binary.LittleEndian.Uint16(header[:2]), binary.LittleEndian.Uint32(header[2:6]), nil
+}
+
+// readEntry reads the value of the entry at the offset, which must be of the given type, and returns the offset of the
+// next entry
+func readEntry(r io.ReaderAt, offset int64, typ uint16) ([]byte, int64, error) {
+	entryType, length, err := readEntryHeader(r, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entryType != typ {
+		return nil, 0, fmt.Errorf("e2store entry at %d: type %#04x, expected %#04x", offset, entryType, typ)
+	}
+	if length > maxEntrySize {
+		return nil, 0, errors.New("e2store entry too large")
+	}
+	value := make([]byte, length)
+	if _, err := r.ReadAt(value, offset+entryHeaderSize); err != nil {
+		return nil, 0, err
+	}
+	return value, offset + entryHeaderSize + int64(length), nil
+}
@@ -0,0 +1,461 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era1 reads and writes era1 files, the archive format of pre-merge blocks shared by execution clients.
+// An era1 file holds up to 8192 consecutive blocks with their receipts and total difficulties, an accumulator of
+// their hashes and total difficulties, and an index of the blocks. See https://github.com/eth-clients/e2store-format-specs
+package era1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/golang/snappy"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/core/types"
+)
+
+const (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeTotalDifficulty    uint16 = 0x06
+	TypeAccumulator        uint16 = 0x07
+	TypeBlockIndex         uint16 = 0x3266
+
+	// MaxBlocks is the number of blocks of an epoch, the most an era1 file holds
+	MaxBlocks = 8192
+)
+
+var filenameRe = regexp.MustCompile(`^(.+)-(\d{5})-([0-9a-f]{8})\.era1$`)
+
+// Filename returns the name of the era1 file of the epoch, made of the network name, the epoch and the first bytes of
+// the accumulator root
+func Filename(network string, epoch uint64, root libcommon.Hash) string {
+	return fmt.Sprintf("%s-%05d-%x.era1", network, epoch, root[:4])
+}
+
+// Files lists the era1 files of the network in the directory, ordered by epoch
+func Files(dir, network string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	epochs := map[string]uint64{}
+	var files []string
+	for _, entry := range entries {
+		m := filenameRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil || m[1] != network {
+			continue
+		}
+		epoch, err := strconv.ParseUint(m[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, entry.Name())
+		epochs[path] = epoch
+		files = append(files, path)
+	}
+	sort.Slice(files, func(i, j int) bool { return epochs[files[i]] < epochs[files[j]] })
+	return files, nil
+}
+
+// Builder writes an era1 file, blocks being added in order
+type Builder struct {
+	w       *e2storeWriter
+	start   uint64
+	offsets []int64
+	hashes  []libcommon.Hash
+	tds     []*big.Int
+	buf     bytes.Buffer
+	snappy  *snappy.Writer
+}
+
+func NewBuilder(w io.Writer) *Builder {
+	b := &Builder{w: &e2storeWriter{w: w}}
+	b.snappy = snappy.NewBufferedWriter(&b.buf)
+	return b
+}
+
+// Add appends a block with its receipts and total difficulty
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	header, err := rlp.EncodeToBytes(block.HeaderNoCopy())
+	if err != nil {
+		return err
+	}
+	body := block.Body()
+	if block.HeaderNoCopy().WithdrawalsHash == nil {
+		body.Withdrawals = nil
+	}
+	rawBody, err := rlp.EncodeToBytes(body)
+	if err != nil {
+		return err
+	}
+	rawReceipts, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return err
+	}
+	return b.AddRLP(block.NumberU64(), block.Hash(), header, rawBody, rawReceipts, td)
+}
+
+// AddRLP appends a block from its RLP encoded header, body and receipts
+func (b *Builder) AddRLP(number uint64, hash libcommon.Hash, header, body, receipts []byte, td *big.Int) error {
+	if len(b.offsets) == 0 {
+		b.start = number
+		if _, err := b.w.write(TypeVersion, nil); err != nil {
+			return err
+		}
+	}
+	if number != b.start+uint64(len(b.offsets)) {
+		return fmt.Errorf("block %d added after block %d", number, b.start+uint64(len(b.offsets))-1)
+	}
+	if len(b.offsets) == MaxBlocks {
+		return fmt.Errorf("era1 file full, block %d", number)
+	}
+	offset, err := b.writeCompressed(TypeCompressedHeader, header)
+	if err != nil {
+		return err
+	}
+	if _, err := b.writeCompressed(TypeCompressedBody, body); err != nil {
+		return err
+	}
+	if _, err := b.writeCompressed(TypeCompressedReceipts, receipts); err != nil {
+		return err
+	}
+	tdBytes := totalDifficultyBytes(td)
+	if _, err := b.w.write(TypeTotalDifficulty, tdBytes[:]); err != nil {
+		return err
+	}
+	b.offsets = append(b.offsets, offset)
+	b.hashes = append(b.hashes, hash)
+	b.tds = append(b.tds, new(big.Int).Set(td))
+	return nil
+}
+
+func (b *Builder) writeCompressed(typ uint16, data []byte) (int64, error) {
+	b.buf.Reset()
+	b.snappy.Reset(&b.buf)
+	if _, err := b.snappy.Write(data); err != nil {
+		return 0, err
+	}
+	if err := b.snappy.Flush(); err != nil {
+		return 0, err
+	}
+	return b.w.write(typ, b.buf.Bytes())
+}
+
+// Finalize writes the accumulator and the block index, and returns the accumulator root
+func (b *Builder) Finalize() (libcommon.Hash, error) {
+	if len(b.offsets) == 0 {
+		return libcommon.Hash{}, errors.New("era1 file without blocks")
+	}
+	root := ComputeAccumulator(b.hashes, b.tds)
+	if _, err := b.w.write(TypeAccumulator, root[:]); err != nil {
+		return libcommon.Hash{}, err
+	}
+	// the offsets of the blocks are relative to the index entry
+	base := b.w.written
+	index := make([]byte, 16+8*len(b.offsets))
+	binary.LittleEndian.PutUint64(index, b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8+8*i:], uint64(offset-base))
+	}
+	binary.LittleEndian.PutUint64(index[8+8*len(b.offsets):], uint64(len(b.offsets)))
+	if _, err := b.w.write(TypeBlockIndex, index); err != nil {
+		return libcommon.Hash{}, err
+	}
+	return root, nil
+}
+
+// Era is an open era1 file
+type Era struct {
+	f      *os.File
+	start  uint64
+	count  uint64
+	index  int64 // offset of the block index entry
+	length int64
+}
+
+func Open(path string) (*Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	e, err := newEra(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return e, nil
+}
+
+func newEra(f *os.File) (*Era, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	e := &Era{f: f, length: info.Size()}
+	if e.length < entryHeaderSize+24 {
+		return nil, errors.New("era1 file too short")
+	}
+	var buf [8]byte
+	if _, err := f.ReadAt(buf[:], e.length-8); err != nil {
+		return nil, err
+	}
+	e.count = binary.LittleEndian.Uint64(buf[:])
+	if e.count == 0 || e.count > MaxBlocks {
+		return nil, fmt.Errorf("era1 file with %d blocks", e.count)
+	}
+	e.index = e.length - entryHeaderSize - 16 - 8*int64(e.count)
+	if e.index < 0 {
+		return nil, errors.New("era1 block index out of the file")
+	}
+	index, _, err := readEntry(f, e.index, TypeBlockIndex)
+	if err != nil {
+		return nil, err
+	}
+	e.start = binary.LittleEndian.Uint64(index)
+	return e, nil
+}
+
+func (e *Era) Close() error { return e.f.Close() }
+
+// Start returns the number of the first block of the file
+func (e *Era) Start() uint64 { return e.start }
+
+// Count returns the number of blocks of the file
+func (e *Era) Count() uint64 { return e.count }
+
+func (e *Era) blockOffset(number uint64) (int64, error) {
+	if number < e.start || number >= e.start+e.count {
+		return 0, fmt.Errorf("block %d out of the era1 file, blocks %d-%d", number, e.start, e.start+e.count-1)
+	}
+	var buf [8]byte
+	if _, err := e.f.ReadAt(buf[:], e.index+entryHeaderSize+8+8*int64(number-e.start)); err != nil {
+		return 0, err
+	}
+	offset := e.index + int64(binary.LittleEndian.Uint64(buf[:]))
+	if offset < 0 || offset >= e.index {
+		return 0, fmt.Errorf("block %d offset out of the file", number)
+	}
+	return offset, nil
+}
+
+// RawBlock is a block of an era1 file, as stored
+type RawBlock struct {
+	Header, Body, Receipts []byte // RLP encoded
+	TotalDifficulty        *big.Int
+}
+
+// RawBlock reads the block with the given number
+func (e *Era) RawBlock(number uint64) (*RawBlock, error) {
+	offset, err := e.blockOffset(number)
+	if err != nil {
+		return nil, err
+	}
+	var raw RawBlock
+	if raw.Header, offset, err = readCompressed(e.f, offset, TypeCompressedHeader); err != nil {
+		return nil, err
+	}
+	if raw.Body, offset, err = readCompressed(e.f, offset, TypeCompressedBody); err != nil {
+		return nil, err
+	}
+	if raw.Receipts, offset, err = readCompressed(e.f, offset, TypeCompressedReceipts); err != nil {
+		return nil, err
+	}
+	td, _, err := readEntry(e.f, offset, TypeTotalDifficulty)
+	if err != nil {
+		return nil, err
+	}
+	if len(td) != 32 {
+		return nil, fmt.Errorf("total difficulty of %d bytes", len(td))
+	}
+	raw.TotalDifficulty = totalDifficultyFromBytes(td)
+	return &raw, nil
+}
+
+// Block reads and decodes the block with the given number
+func (e *Era) Block(number uint64) (*types.Block, types.Receipts, *big.Int, error) {
+	raw, err := e.RawBlock(number)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var header types.Header
+	if err := rlp.DecodeBytes(raw.Header, &header); err != nil {
+		return nil, nil, nil, fmt.Errorf("block %d header: %w", number, err)
+	}
+	var body types.Body
+	if err := rlp.DecodeBytes(raw.Body, &body); err != nil {
+		return nil, nil, nil, fmt.Errorf("block %d body: %w", number, err)
+	}
+	if header.WithdrawalsHash == nil {
+		body.Withdrawals = nil
+	}
+	var receipts types.Receipts
+	if err := rlp.DecodeBytes(raw.Receipts, &receipts); err != nil {
+		return nil, nil, nil, fmt.Errorf("block %d receipts: %w", number, err)
+	}
+	return types.NewBlockFromNetwork(&header, &body), receipts, raw.TotalDifficulty, nil
+}
+
+// Accumulator returns the accumulator root stored in the file
+func (e *Era) Accumulator() (libcommon.Hash, error) {
+	// the accumulator follows the total difficulty of the last block
+	offset, err := e.blockOffset(e.start + e.count - 1)
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	for _, typ := range []uint16{TypeCompressedHeader, TypeCompressedBody, TypeCompressedReceipts, TypeTotalDifficulty} {
+		if _, offset, err = readEntry(e.f, offset, typ); err != nil {
+			return libcommon.Hash{}, err
+		}
+	}
+	root, _, err := readEntry(e.f, offset, TypeAccumulator)
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	if len(root) != length.Hash {
+		return libcommon.Hash{}, fmt.Errorf("accumulator of %d bytes", len(root))
+	}
+	return libcommon.BytesToHash(root), nil
+}
+
+// Verify checks that the blocks of the file form a chain, that their bodies and receipts match their headers and that
+// their total difficulties add up, starting from the given parent. The accumulator root is checked against the blocks.
+// It returns the hash and the total difficulty of the last block. The parent hash is not checked if zero, which allows
+// verifying a file on its own, and the total difficulty of the first block is not checked if the parent one is nil.
+func (e *Era) Verify(parentHash libcommon.Hash, parentTd *big.Int) (libcommon.Hash, *big.Int, error) {
+	hashes := make([]libcommon.Hash, 0, e.count)
+	tds := make([]*big.Int, 0, e.count)
+	for number := e.start; number < e.start+e.count; number++ {
+		block, receipts, td, err := e.Block(number)
+		if err != nil {
+			return libcommon.Hash{}, nil, err
+		}
+		if err := VerifyBlock(block, receipts); err != nil {
+			return libcommon.Hash{}, nil, err
+		}
+		header := block.HeaderNoCopy()
+		if parentHash != (libcommon.Hash{}) && header.ParentHash != parentHash {
+			return libcommon.Hash{}, nil, fmt.Errorf("block %d: parent hash %x, expected %x", number, header.ParentHash, parentHash)
+		}
+		if parentTd != nil && td.Cmp(new(big.Int).Add(parentTd, header.Difficulty)) != 0 {
+			return libcommon.Hash{}, nil, fmt.Errorf("block %d: total difficulty %d, expected %d", number, td, new(big.Int).Add(parentTd, header.Difficulty))
+		}
+		parentHash, parentTd = block.Hash(), td
+		hashes = append(hashes, parentHash)
+		tds = append(tds, td)
+	}
+	root, err := e.Accumulator()
+	if err != nil {
+		return libcommon.Hash{}, nil, err
+	}
+	if computed := ComputeAccumulator(hashes, tds); computed != root {
+		return libcommon.Hash{}, nil, fmt.Errorf("accumulator root %x, computed %x", root, computed)
+	}
+	return parentHash, parentTd, nil
+}
+
+// VerifyBlock checks the body and the receipts of a pre-merge block against its header
+func VerifyBlock(block *types.Block, receipts types.Receipts) error {
+	header := block.HeaderNoCopy()
+	if header.Difficulty == nil || header.Difficulty.Sign() == 0 {
+		return fmt.Errorf("block %d: proof-of-stake blocks are not stored in era1 files", block.NumberU64())
+	}
+	if hash := types.DeriveSha(block.Transactions()); hash != header.TxHash {
+		return fmt.Errorf("block %d: transactions root %x, header %x", block.NumberU64(), hash, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("block %d: uncles hash %x, header %x", block.NumberU64(), hash, header.UncleHash)
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("block %d: %d receipts for %d transactions", block.NumberU64(), len(receipts), len(block.Transactions()))
+	}
+	if hash := types.DeriveSha(receipts); hash != header.ReceiptHash {
+		return fmt.Errorf("block %d: receipts root %x, header %x", block.NumberU64(), hash, header.ReceiptHash)
+	}
+	return nil
+}
+
+// ComputeAccumulator computes the SSZ root of the list of header records (block hash, total difficulty) of the blocks
+// of an epoch
+func ComputeAccumulator(hashes []libcommon.Hash, tds []*big.Int) libcommon.Hash {
+	layer := make([][length.Hash]byte, len(hashes))
+	for i := range hashes {
+		td := totalDifficultyBytes(tds[i])
+		layer[i] = sha256.Sum256(append(hashes[i].Bytes(), td[:]...))
+	}
+	// merkleize up to the limit of the list, padding with the roots of zero subtrees
+	var zero [length.Hash]byte
+	for depth := 0; 1<<depth < MaxBlocks; depth++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zero)
+		}
+		next := make([][length.Hash]byte, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+		zero = sha256.Sum256(append(zero[:], zero[:]...))
+	}
+	var mixIn [length.Hash]byte
+	binary.LittleEndian.PutUint64(mixIn[:], uint64(len(hashes)))
+	return sha256.Sum256(append(layer[0][:], mixIn[:]...))
+}
+
+// totalDifficultyBytes encodes the total difficulty as a little endian uint256
+func totalDifficultyBytes(td *big.Int) [length.Hash]byte {
+	var b [length.Hash]byte
+	td.FillBytes(b[:])
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+func totalDifficultyFromBytes(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func readCompressed(r io.ReaderAt, offset int64, typ uint16) ([]byte, int64, error) {
+	data, next, err := readEntry(r, offset, typ)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err = io.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("e2store entry at %d: %w", offset, err)
+	}
+	return data, next, nil
+}
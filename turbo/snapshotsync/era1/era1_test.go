@@ -0,0 +1,152 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package era1
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	"github.com/erigontech/erigon/core/types"
+)
+
+func testChain(t *testing.T, start uint64, count int) ([]*types.Block, []types.Receipts, []*big.Int) {
+	t.Helper()
+	var blocks []*types.Block
+	var receipts []types.Receipts
+	var tds []*big.Int
+	parentHash, td := libcommon.Hash{0xff}, big.NewInt(1000)
+	for i := 0; i < count; i++ {
+		var txs []types.Transaction
+		var blockReceipts types.Receipts
+		if i%2 == 1 {
+			txs = append(txs, types.NewTransaction(uint64(i), libcommon.Address{1}, uint256.NewInt(1), 21000, uint256.NewInt(1), nil))
+			receipt := &types.Receipt{Type: types.LegacyTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: types.Logs{
+				{Address: libcommon.Address{2}, Topics: []libcommon.Hash{{3}}, Data: []byte{4}},
+			}}
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+			blockReceipts = append(blockReceipts, receipt)
+		}
+		header := &types.Header{ParentHash: parentHash, Number: new(big.Int).SetUint64(start + uint64(i)), Difficulty: big.NewInt(int64(10 + i)), GasLimit: 30_000_000}
+		block := types.NewBlock(header, txs, nil, blockReceipts, nil)
+		td = new(big.Int).Add(td, header.Difficulty)
+		blocks, receipts, tds = append(blocks, block), append(receipts, blockReceipts), append(tds, td)
+		parentHash = block.Hash()
+	}
+	return blocks, receipts, tds
+}
+
+func writeEra(t *testing.T, dir string, blocks []*types.Block, receipts []types.Receipts, tds []*big.Int) (string, libcommon.Hash) {
+	t.Helper()
+	f, err := os.CreateTemp(dir, "era1")
+	require.NoError(t, err)
+	defer f.Close()
+	builder := NewBuilder(f)
+	for i := range blocks {
+		require.NoError(t, builder.Add(blocks[i], receipts[i], tds[i]))
+	}
+	root, err := builder.Finalize()
+	require.NoError(t, err)
+	path := filepath.Join(dir, Filename("mainnet", blocks[0].NumberU64()/MaxBlocks, root))
+	require.NoError(t, os.Rename(f.Name(), path))
+	return path, root
+}
+
+func TestEraRoundTrip(t *testing.T) {
+	blocks, receipts, tds := testChain(t, 2*MaxBlocks, 5)
+	path, root := writeEra(t, t.TempDir(), blocks, receipts, tds)
+	require.Equal(t, "mainnet-00002-", filepath.Base(path)[:len("mainnet-00002-")])
+
+	e, err := Open(path)
+	require.NoError(t, err)
+	defer e.Close()
+	require.Equal(t, uint64(2*MaxBlocks), e.Start())
+	require.Equal(t, uint64(5), e.Count())
+
+	for i, expected := range blocks {
+		block, blockReceipts, td, err := e.Block(expected.NumberU64())
+		require.NoError(t, err)
+		require.Equal(t, expected.Hash(), block.Hash())
+		require.Equal(t, len(expected.Transactions()), len(block.Transactions()))
+		require.Equal(t, types.DeriveSha(receipts[i]), types.DeriveSha(blockReceipts))
+		require.Equal(t, tds[i], td)
+	}
+	_, _, _, err = e.Block(2*MaxBlocks + 5)
+	require.Error(t, err)
+
+	stored, err := e.Accumulator()
+	require.NoError(t, err)
+	require.Equal(t, root, stored)
+
+	lastHash, lastTd, err := e.Verify(blocks[0].ParentHash(), big.NewInt(1000))
+	require.NoError(t, err)
+	require.Equal(t, blocks[4].Hash(), lastHash)
+	require.Equal(t, tds[4], lastTd)
+
+	_, _, err = e.Verify(libcommon.Hash{1}, nil)
+	require.ErrorContains(t, err, "parent hash")
+	_, _, err = e.Verify(libcommon.Hash{}, big.NewInt(999))
+	require.ErrorContains(t, err, "total difficulty")
+}
+
+func TestVerifyDetectsBadReceipts(t *testing.T) {
+	blocks, receipts, tds := testChain(t, 0, 2)
+	receipts[1][0].CumulativeGasUsed++
+	path, _ := writeEra(t, t.TempDir(), blocks, receipts, tds)
+	e, err := Open(path)
+	require.NoError(t, err)
+	defer e.Close()
+	_, _, err = e.Verify(libcommon.Hash{}, nil)
+	require.ErrorContains(t, err, "receipts root")
+}
+
+func TestComputeAccumulator(t *testing.T) {
+	_, _, tds := testChain(t, 0, 3)
+	hashes := []libcommon.Hash{{1}, {2}, {3}}
+
+	// hash_tree_root(List[HeaderRecord, 8192])
+	leaves := make([][32]byte, len(hashes))
+	for i := range hashes {
+		td := totalDifficultyBytes(tds[i])
+		leaves[i] = sha256.Sum256(append(hashes[i].Bytes(), td[:]...))
+	}
+	root, err := merkle_tree.MerkleizeVector(leaves, MaxBlocks)
+	require.NoError(t, err)
+	var mixIn [32]byte
+	binary.LittleEndian.PutUint64(mixIn[:], uint64(len(hashes)))
+	expected := sha256.Sum256(append(root[:], mixIn[:]...))
+
+	require.Equal(t, libcommon.Hash(expected), ComputeAccumulator(hashes, tds))
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"mainnet-00010-00000000.era1", "mainnet-00002-00000000.era1", "sepolia-00001-00000000.era1", "mainnet-00003.era"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	files, err := Files(dir, "mainnet")
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "mainnet-00002-00000000.era1"), filepath.Join(dir, "mainnet-00010-00000000.era1")}, files)
+}
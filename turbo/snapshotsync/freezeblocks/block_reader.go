@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"sort"
 	"time"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/recsplit"
 	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/seg"
 	"github.com/erigontech/erigon/core/rawdb"
 	coresnaptype "github.com/erigontech/erigon/core/snaptype"
 	"github.com/erigontech/erigon/core/types"
@@ -45,6 +47,12 @@ import (
 	"github.com/erigontech/erigon/turbo/snapshotsync"
 )
 
+var (
+	// the txs of the blocks having at least parallelTxsDecodeMinCount of them are decoded across workers
+	parallelTxsDecodeMinCount = dbg.EnvInt("PARALLEL_TXS_DECODE_MIN", 64)
+	parallelTxsDecodeWorkers  = dbg.EnvInt("PARALLEL_TXS_DECODE_WORKERS", max(runtime.GOMAXPROCS(-1)/2, 1))
+)
+
 type RemoteBlockReader struct {
 	client remote.ETHBACKENDClient
 }
@@ -164,7 +172,7 @@ func NewRemoteBlockReader(client remote.ETHBACKENDClient) *RemoteBlockReader {
 	return &RemoteBlockReader{client}
 }
 
-func (r *RemoteBlockReader) TxnLookup(ctx context.Context, tx kv.Getter, txnHash common.Hash) (uint64, uint64, bool, error) {
+func (r *RemoteBlockReader) TxnLookup(ctx context.Context, tx kv.Tx, txnHash common.Hash) (uint64, uint64, bool, error) {
 	reply, err := r.client.TxnLookup(ctx, &remote.TxnLookupRequest{TxnHash: gointerfaces.ConvertHashToH256(txnHash)})
 	if err != nil {
 		return 0, 0, false, err
@@ -175,6 +183,27 @@ func (r *RemoteBlockReader) TxnLookup(ctx context.Context, tx kv.Getter, txnHash
 	return reply.BlockNumber, reply.TxNumber, true, nil
 }
 
+func (r *RemoteBlockReader) TxnByHash(ctx context.Context, tx kv.Tx, txnHash common.Hash) (types.Transaction, uint64, uint64, bool, error) {
+	blockNum, txNum, ok, err := r.TxnLookup(ctx, tx, txnHash)
+	if err != nil || !ok || blockNum == 0 { // the remote returns 0 if transaction is not found
+		return nil, 0, 0, false, err
+	}
+	canonicalHash, ok, err := r.CanonicalHash(ctx, tx, blockNum)
+	if err != nil || !ok {
+		return nil, 0, 0, false, err
+	}
+	b, err := r.BodyWithTransactions(ctx, tx, canonicalHash, blockNum)
+	if err != nil || b == nil {
+		return nil, 0, 0, false, err
+	}
+	for _, txn := range b.Transactions {
+		if txn.Hash() == txnHash {
+			return txn, blockNum, txNum, true, nil
+		}
+	}
+	return nil, 0, 0, false, nil
+}
+
 func (r *RemoteBlockReader) TxnByIdxInBlock(ctx context.Context, tx kv.Getter, blockNum uint64, i int) (txn types.Transaction, err error) {
 	canonicalHash, ok, err := r.CanonicalHash(ctx, tx, blockNum)
 	if err != nil {
@@ -1047,6 +1076,9 @@ func (r *BlockReader) txsFromSnapshot(baseTxnID uint64, txCount uint32, txsSeg *
 	}
 	gg := txsSeg.Src().MakeGetter()
 	gg.Reset(txnOffset)
+	if int(txCount) >= parallelTxsDecodeMinCount && parallelTxsDecodeWorkers > 1 {
+		return r.txsFromSnapshotParallel(gg, txsSeg, senders)
+	}
 	for i := uint32(0); i < txCount; i++ {
 		if !gg.HasNext() {
 			return nil, nil, nil
@@ -1067,6 +1099,31 @@ func (r *BlockReader) txsFromSnapshot(baseTxnID uint64, txCount uint32, txsSeg *
 	return txs, senders, nil
 }
 
+// txsFromSnapshotParallel reads the records of the txs of a block sequentially, and decodes them across workers: the
+// decoding is the hot spot of the bulk bodies reads.
+func (r *BlockReader) txsFromSnapshotParallel(gg *seg.Getter, txsSeg *snapshotsync.VisibleSegment, senders []common.Address) ([]types.Transaction, []common.Address, error) {
+	txRlps := make([][]byte, len(senders))
+	for i := range senders {
+		if !gg.HasNext() {
+			return nil, nil, nil
+		}
+		buf, _ := gg.Next(nil)
+		if len(buf) < 1+20 {
+			return nil, nil, fmt.Errorf("segment %s has too short record: len(buf)=%d < 21", txsSeg.Src().FileName(), len(buf))
+		}
+		senders[i].SetBytes(buf[1 : 1+20])
+		txRlps[i] = buf[1+20:]
+	}
+	txs, err := types.DecodeTransactionsParallel(txRlps, parallelTxsDecodeWorkers)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, txn := range txs {
+		txn.SetSender(senders[i])
+	}
+	return txs, senders, nil
+}
+
 func (r *BlockReader) txnByID(txnID uint64, sn *snapshotsync.VisibleSegment, buf []byte) (txn types.Transaction, err error) {
 	idxTxnHash := sn.Src().Index(coresnaptype.Indexes.TxnHash)
 
@@ -1129,7 +1186,7 @@ func (r *BlockReader) txnByHash(txnHash common.Hash, segments []*snapshotsync.Vi
 
 		// final txnHash check  - completely avoid false-positives
 		if txn.Hash() == txnHash {
-			return txn, blockNum, idxTxnHash.BaseDataID() + txNumInFile + 1, true, nil
+			return txn, blockNum, idxTxnHash.BaseDataID() + txNumInFile, true, nil
 		}
 	}
 
@@ -1182,24 +1239,55 @@ func (r *BlockReader) TxnByIdxInBlock(ctx context.Context, tx kv.Getter, blockNu
 	return r.txnByID(b.BaseTxnID.At(txIdxInBlock), txnSeg, nil)
 }
 
-// TxnLookup - find blockNumber and txnID by txnHash
-func (r *BlockReader) TxnLookup(_ context.Context, tx kv.Getter, txnHash common.Hash) (blockNum uint64, txNum uint64, ok bool, err error) {
-	blockNumPointer, txNumPointer, err := rawdb.ReadTxLookupEntry(tx, txnHash)
+// TxnLookup - find blockNumber and txNum by txnHash
+func (r *BlockReader) TxnLookup(ctx context.Context, tx kv.Tx, txnHash common.Hash) (blockNum uint64, txNum uint64, ok bool, err error) {
+	_, blockNum, txNum, ok, err = r.TxnByHash(ctx, tx, txnHash)
+	return blockNum, txNum, ok, err
+}
+
+// TxnByHash - kv.TxLookup stores only txNums: an entry is served only if the canonical transaction at its txNum still
+// has the requested hash, then entries left behind by an unwind don't resolve. Frozen blocks are served by the indices
+// of the snapshots.
+func (r *BlockReader) TxnByHash(ctx context.Context, tx kv.Tx, txnHash common.Hash) (txn types.Transaction, blockNum uint64, txNum uint64, ok bool, err error) {
+	txNum, ok, err = rawdb.ReadTxLookupEntry(tx, txnHash)
 	if err != nil {
-		return 0, 0, false, err
+		return nil, 0, 0, false, err
 	}
-
-	if blockNumPointer != nil && txNumPointer != nil {
-		return *blockNumPointer, *txNumPointer, true, nil
+	if ok {
+		txn, blockNum, ok, err = r.txnByTxNum(ctx, tx, txNum)
+		if err != nil {
+			return nil, 0, 0, false, err
+		}
+		if ok && txn.Hash() == txnHash {
+			return txn, blockNum, txNum, true, nil
+		}
 	}
 
 	txns := r.sn.ViewType(coresnaptype.Transactions)
 	defer txns.Close()
-	_, blockNum, txNum, ok, err = r.txnByHash(txnHash, txns.Segments, nil)
+	return r.txnByHash(txnHash, txns.Segments, nil)
+}
+
+// txnByTxNum - the canonical non-system transaction at txNum
+func (r *BlockReader) txnByTxNum(ctx context.Context, tx kv.Tx, txNum uint64) (txn types.Transaction, blockNum uint64, ok bool, err error) {
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(ReadTxNumFuncFromBlockReader(ctx, r))
+	ok, blockNum, err = txNumsReader.FindBlockNum(tx, txNum)
+	if err != nil || !ok {
+		return nil, 0, false, err
+	}
+	txNumMin, err := txNumsReader.Min(tx, blockNum)
 	if err != nil {
-		return 0, 0, false, err
+		return nil, 0, false, err
+	}
+	if txNum <= txNumMin {
+		return nil, 0, false, nil
+	}
+	// -1 because block has system-txn in the beginning of block
+	txn, err = r.TxnByIdxInBlock(ctx, tx, blockNum, int(txNum-txNumMin-1))
+	if err != nil || txn == nil {
+		return nil, 0, false, err
 	}
-	return blockNum, txNum, ok, nil
+	return txn, blockNum, true, nil
 }
 
 func (r *BlockReader) FirstTxnNumNotInSnapshots() uint64 {
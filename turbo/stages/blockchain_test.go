@@ -22,11 +22,15 @@ package stages_test
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
@@ -38,9 +42,12 @@ import (
 	"github.com/erigontech/erigon-lib/common/length"
 	"github.com/erigontech/erigon-lib/common/u256"
 	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces/executionproto"
 	protosentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/bitmapdb"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon/consensus/ethash"
@@ -50,9 +57,11 @@ import (
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/eth/protocols/eth"
+	"github.com/erigontech/erigon/eth/stagedsync"
 	"github.com/erigontech/erigon/ethdb/prune"
 	"github.com/erigontech/erigon/p2p/sentry/sentry_multi_client"
 	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/execution/eth1/eth1_chain_reader"
 	"github.com/erigontech/erigon/turbo/stages/mock"
 )
 
@@ -544,7 +553,7 @@ func TestChainTxReorgs(t *testing.T) {
 	// removed tx
 	txs := types.Transactions{pastDrop, freshDrop}
 	for i, txn := range txs {
-		if bn, _, _ := rawdb.ReadTxLookupEntry(tx, txn.Hash()); bn != nil {
+		if _, found, _ := rawdb.ReadTxLookupEntry(tx, txn.Hash()); found {
 			t.Errorf("drop %d: tx %v found while shouldn't have been", i, txn)
 		}
 		if rcpt, _, _, _, _ := readReceipt(tx, txn.Hash(), m); rcpt != nil {
@@ -566,7 +575,7 @@ func TestChainTxReorgs(t *testing.T) {
 	// shared tx
 	txs = types.Transactions{postponed, swapped}
 	for i, txn := range txs {
-		if bn, _, _ := rawdb.ReadTxLookupEntry(tx, txn.Hash()); bn == nil {
+		if _, found, _ := rawdb.ReadTxLookupEntry(tx, txn.Hash()); !found {
 			t.Errorf("drop %d: tx %v found while shouldn't have been", i, txn)
 		}
 
@@ -576,23 +585,63 @@ func TestChainTxReorgs(t *testing.T) {
 	}
 }
 
+// kv.TxLookup entries are txNums: an entry left behind by an unwind points to a txNum which may be reused by another
+// transaction of the new chain, it must not resolve to that transaction.
+func TestTxLookupStaleEntry(t *testing.T) {
+	t.Parallel()
+	m := mock.Mock(t)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 1, func(i int, b *core.BlockGen) {
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(m.Address), libcommon.Address{1}, uint256.NewInt(1000), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, m.Key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	included := chain.Blocks[0].Transactions()[0]
+
+	dropped, err := types.SignTx(types.NewTransaction(0, libcommon.Address{2}, uint256.NewInt(1000), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, m.Key)
+	require.NoError(t, err)
+
+	tx, err := m.DB.BeginRw(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	blockNum, txNum, found, err := m.BlockReader.TxnLookup(m.Ctx, tx, included.Hash())
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(1), blockNum)
+	txNumMin, err := rawdbv3.TxNums.Min(tx, 1)
+	require.NoError(t, err)
+	require.Equal(t, txNumMin+1, txNum)
+
+	// the dropped transaction was at the same position in the unwound block
+	rawdb.WriteTxLookupEntries(tx, types.NewBlock(&types.Header{Number: big.NewInt(1)}, []types.Transaction{dropped}, nil, nil, nil), txNumMin)
+	_, found, err = rawdb.ReadTxLookupEntry(tx, dropped.Hash())
+	require.NoError(t, err)
+	require.True(t, found)
+	_, _, found, err = m.BlockReader.TxnLookup(m.Ctx, tx, dropped.Hash())
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
 func readReceipt(db kv.TemporalTx, txHash libcommon.Hash, m *mock.MockSentry) (*types.Receipt, libcommon.Hash, uint64, uint64, error) {
 	// Retrieve the context of the receipt based on the transaction hash
-	blockNumber, _, err := rawdb.ReadTxLookupEntry(db, txHash)
+	blockNumber, _, found, err := m.BlockReader.TxnLookup(context.Background(), db, txHash)
 	if err != nil {
 		return nil, libcommon.Hash{}, 0, 0, err
 	}
-	if blockNumber == nil {
+	if !found {
 		return nil, libcommon.Hash{}, 0, 0, nil
 	}
-	blockHash, _, err := m.BlockReader.CanonicalHash(context.Background(), db, *blockNumber)
+	blockHash, _, err := m.BlockReader.CanonicalHash(context.Background(), db, blockNumber)
 	if err != nil {
 		return nil, libcommon.Hash{}, 0, 0, err
 	}
 	if blockHash == (libcommon.Hash{}) {
 		return nil, libcommon.Hash{}, 0, 0, nil
 	}
-	b, _, err := m.BlockReader.BlockWithSenders(context.Background(), db, blockHash, *blockNumber)
+	b, _, err := m.BlockReader.BlockWithSenders(context.Background(), db, blockHash, blockNumber)
 	if err != nil {
 		return nil, libcommon.Hash{}, 0, 0, err
 	}
@@ -604,7 +653,7 @@ func readReceipt(db kv.TemporalTx, txHash libcommon.Hash, m *mock.MockSentry) (*
 	}
 	for receiptIndex, receipt := range receipts {
 		if receipt.TxHash == txHash {
-			return receipt, blockHash, *blockNumber, uint64(receiptIndex), nil
+			return receipt, blockHash, blockNumber, uint64(receiptIndex), nil
 		}
 	}
 	log.Error("Receipt not found", "number", blockNumber, "hash", blockHash, "txhash", txHash)
@@ -883,9 +932,9 @@ func doModesTest(t *testing.T, pm prune.Mode) error {
 		b, err := m.BlockReader.BlockByNumber(m.Ctx, tx, 1)
 		require.NoError(err)
 		for _, txn := range b.Transactions() {
-			found, _, err := rawdb.ReadTxLookupEntry(tx, txn.Hash())
+			_, found, err := rawdb.ReadTxLookupEntry(tx, txn.Hash())
 			require.NoError(err)
-			require.Nil(found)
+			require.False(found)
 		}
 	} else {
 		b, err := m.BlockReader.BlockByNumber(m.Ctx, tx, 1)
@@ -2314,6 +2363,127 @@ func TestEIP1559Transition(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// A block whose gas used doesn't match its header is quarantined with everything needed to reproduce its execution.
+func TestBadBlockQuarantine(t *testing.T) {
+	m := mock.Mock(t, mock.WithBadBlocksKeep(8))
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	recipient := libcommon.Address{1}
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(m.Address), recipient, uint256.NewInt(1000), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, m.Key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+
+	badHeader := types.CopyHeader(chain.Headers[1])
+	badHeader.GasUsed++
+	chain.Headers[1] = badHeader
+	chain.Blocks[1] = chain.Blocks[1].WithSeal(badHeader)
+	chain.TopBlock = chain.Blocks[1]
+	require.Error(t, m.InsertChain(chain))
+
+	dir := filepath.Join(m.Dirs.BadBlocks, fmt.Sprintf("%d-%x", badHeader.Number.Uint64(), badHeader.Hash()))
+	var info stagedsync.BadBlockInfo
+	data, err := os.ReadFile(filepath.Join(dir, stagedsync.BadBlockInfoFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &info))
+	require.Equal(t, badHeader.Hash(), info.Hash)
+	require.Equal(t, stagedsync.BadBlockReasonExecution, info.Reason)
+	require.Contains(t, info.Error, "gas used by execution")
+	require.Empty(t, info.WitnessError)
+
+	data, err = os.ReadFile(filepath.Join(dir, stagedsync.BadBlockRlpFile))
+	require.NoError(t, err)
+	var block types.Block
+	require.NoError(t, rlp.DecodeBytes(data, &block))
+	require.Equal(t, badHeader.Hash(), block.Hash())
+
+	var receipts []map[string]any
+	data, err = os.ReadFile(filepath.Join(dir, stagedsync.BadBlockReceiptsFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &receipts))
+	require.Len(t, receipts, 1)
+
+	var timeline []stagedsync.ExecTimelineEntry
+	data, err = os.ReadFile(filepath.Join(dir, stagedsync.BadBlockTimelineFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &timeline))
+	require.Len(t, timeline, 3) // block initialisation, the txn and the block finalisation
+
+	// the witness is the parent state: after the first block
+	var witness types.GenesisAlloc
+	data, err = os.ReadFile(filepath.Join(dir, stagedsync.BadBlockWitnessFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &witness))
+	require.Equal(t, uint64(1), witness[m.Address].Nonce)
+	require.Equal(t, int64(1000), witness[recipient].Balance.Int64())
+}
+
+// Payloads of competing forks validated one after another are executed on top of their parent's in-memory
+// post-state, and forkchoice updates flush it, with the state roots checked along the way.
+func TestValidateChainOnCompetingForks(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	config := *params.AllProtocolChanges
+	config.TerminalTotalDifficulty = libcommon.Big0
+	config.ShanghaiTime, config.CancunTime, config.PragueTime, config.OsakaTime = nil, nil, nil, nil
+	gspec := &types.Genesis{
+		Config: &config,
+		Alloc:  types.GenesisAlloc{crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(params.Ether)}},
+	}
+	m := mock.MockWithGenesis(t, gspec, key, true)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	makeFork := func(seed byte, n int) *core.ChainPack {
+		chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, n, func(i int, b *core.BlockGen) {
+			b.SetCoinbase(libcommon.Address{seed})
+			txn, err := types.SignTx(types.NewTransaction(b.TxNonce(m.Address), libcommon.Address{seed, byte(i)}, uint256.NewInt(1000), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, m.Key)
+			require.NoError(t, err)
+			b.AddTx(txn)
+		})
+		require.NoError(t, err)
+		return chain
+	}
+	forkA, forkB, forkC := makeFork(1, 4), makeFork(2, 4), makeFork(3, 5)
+
+	ctx := context.Background()
+	chainRW := eth1_chain_reader.NewChainReaderEth1(m.ChainConfig, direct.NewExecutionClientDirect(m.Eth1ExecutionService), uint64(time.Hour))
+	validate := func(block *types.Block) {
+		require.NoError(t, chainRW.InsertBlocksAndWait(ctx, []*types.Block{block}))
+		var (
+			status executionproto.ExecutionStatus
+			err    error
+		)
+		require.Eventually(t, func() bool {
+			status, _, _, err = chainRW.ValidateChain(ctx, block.Hash(), block.NumberU64())
+			return err != nil || status != executionproto.ExecutionStatus_Busy
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, err)
+		require.Equal(t, executionproto.ExecutionStatus_Success, status, "block %d", block.NumberU64())
+	}
+	updateForkChoice := func(head *types.Block) {
+		status, _, latestValidHash, err := chainRW.UpdateForkChoice(ctx, head.Hash(), head.Hash(), head.Hash())
+		require.NoError(t, err)
+		require.Equal(t, executionproto.ExecutionStatus_Success, status)
+		require.Equal(t, head.Hash(), latestValidHash)
+		// the receipt can be sent before the in-memory state is flushed and committed
+		require.Eventually(t, func() bool {
+			canonical, err := chainRW.IsCanonicalHash(ctx, head.Hash())
+			return err == nil && canonical
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	for i := 0; i < forkA.Length(); i++ {
+		validate(forkA.Blocks[i])
+		validate(forkB.Blocks[i])
+	}
+	updateForkChoice(forkA.TopBlock)
+
+	// a reorg from genesis
+	for _, block := range forkC.Blocks {
+		validate(block)
+	}
+	updateForkChoice(forkC.TopBlock)
+}
+
 func current(m *mock.MockSentry, tx kv.Tx) *types.Block {
 	if tx != nil {
 		b, err := m.BlockReader.CurrentBlock(tx)
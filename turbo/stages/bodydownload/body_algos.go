@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"time"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/dbg"
@@ -48,24 +49,30 @@ func (bd *BodyDownload) UpdateFromDb(db kv.Tx) (err error) {
 	bd.maxProgress = headerProgress + 1
 	// Resetting for requesting a new range of blocks
 	bd.requestedLow = bodyProgress + 1
-	bd.requestedMap = make(map[BodyHashes]uint64)
+	bd.requestedMap = make(map[BodyHashes][]libcommon.Hash)
+	bd.requestedNums = make(map[libcommon.Hash]uint64)
 	bd.delivered.Clear()
 	bd.deliveredCount = 0
 	bd.wastedCount = 0
 	clear(bd.deliveriesH)
 	clear(bd.requests)
 	clear(bd.peerMap)
+	for _, stats := range bd.peerStats {
+		stats.inflight = nil
+	}
 	bd.ClearBodyCache()
 	return nil
 }
 
-// RequestMoreBodies - returns nil if nothing to request
+// RequestMoreBodies - returns nil if nothing to request. The request is sized for an idle peer which served bodies
+// before, if any, and should be sent to that peer.
 func (bd *BodyDownload) RequestMoreBodies(tx kv.RwTx, blockReader services.FullBlockReader, currentTime uint64, blockPropagator adapter.BlockPropagator) (*BodyRequest, error) {
 	var bodyReq *BodyRequest
-	blockNums := make([]uint64, 0, bd.blockBufferSize)
-	hashes := make([]libcommon.Hash, 0, bd.blockBufferSize)
+	preferredPeer, size := bd.requestTarget(currentTime)
+	blockNums := make([]uint64, 0, size)
+	hashes := make([]libcommon.Hash, 0, size)
 
-	for blockNum := bd.requestedLow; len(blockNums) < bd.blockBufferSize && blockNum < bd.maxProgress; blockNum++ {
+	for blockNum := bd.requestedLow; len(blockNums) < size && blockNum < bd.maxProgress; blockNum++ {
 		if bd.delivered.Contains(blockNum) {
 			// Already delivered, no need to request
 			continue
@@ -76,6 +83,10 @@ func (bd *BodyDownload) RequestMoreBodies(tx kv.RwTx, blockReader services.FullB
 				continue
 			}
 			bd.peerMap[req.peerID]++
+			if !req.expired {
+				req.expired = true
+				bd.peerTimedOut(req.peerID, req)
+			}
 			dataflow.BlockBodyDownloadStates.AddChange(blockNum, dataflow.BlockBodyExpired)
 			delete(bd.requests, blockNum)
 		}
@@ -159,7 +170,10 @@ func (bd *BodyDownload) RequestMoreBodies(tx kv.RwTx, blockReader services.FullB
 			if header.WithdrawalsHash != nil {
 				copy(bodyHashes[2*length.Hash:], header.WithdrawalsHash.Bytes())
 			}
-			bd.requestedMap[bodyHashes] = blockNum
+			if _, ok := bd.requestedNums[hash]; !ok {
+				bd.requestedMap[bodyHashes] = append(bd.requestedMap[bodyHashes], hash)
+			}
+			bd.requestedNums[hash] = blockNum
 			blockNums = append(blockNums, blockNum)
 			hashes = append(hashes, hash)
 		} else {
@@ -168,7 +182,7 @@ func (bd *BodyDownload) RequestMoreBodies(tx kv.RwTx, blockReader services.FullB
 		}
 	}
 	if len(blockNums) > 0 {
-		bodyReq = &BodyRequest{BlockNums: blockNums, Hashes: hashes}
+		bodyReq = &BodyRequest{BlockNums: blockNums, Hashes: hashes, preferredPeer: preferredPeer}
 	}
 	return bodyReq, nil
 }
@@ -213,6 +227,12 @@ func (bd *BodyDownload) RequestSent(bodyReq *BodyRequest, timeWithTimeout uint64
 	}
 	bodyReq.waitUntil = timeWithTimeout
 	bodyReq.peerID = peer
+	bodyReq.sentAt = time.Now()
+	if preferredPeer, ok := bodyReq.PreferredPeer(); ok && preferredPeer != peer {
+		// the preferred peer could not be reached, most likely disconnected
+		delete(bd.peerStats, preferredPeer)
+	}
+	bd.peerRequestSent(peer, bodyReq)
 }
 
 // DeliverBodies takes the block body received from a peer and adds it to the various data structures
@@ -257,6 +277,8 @@ func (bd *BodyDownload) DeliverySize(delivered float64, wasted float64) {
 	bd.wastedCount += wasted
 }
 
+// GetDeliveries matches the delivered bodies to the requested headers by their hashes, whichever request and in
+// whichever order they come in. A body shared by several requested blocks completes all of them.
 func (bd *BodyDownload) GetDeliveries(tx kv.RwTx) (uint64, uint64, error) {
 	var delivered, undelivered int
 Loop:
@@ -285,6 +307,7 @@ Loop:
 
 		//var deliveredNums []uint64
 		toClean := map[uint64]struct{}{}
+		deliveredByRequest := map[*BodyRequest]int{}
 		txs, uncles, withdrawals, lenOfP2PMessage := delivery.txs, delivery.uncles, delivery.withdrawals, delivery.lenOfP2PMessage
 
 		for i := range txs {
@@ -300,23 +323,32 @@ Loop:
 
 			// Block numbers are added to the bd.delivered bitmap here, only for blocks for which the body has been received, and their double hashes are present in the bd.requestedMap
 			// Also, block numbers can be added to bd.delivered for empty blocks, above
-			blockNum, ok := bd.requestedMap[bodyHashes]
+			hashes, ok := bd.requestedMap[bodyHashes]
 			if !ok {
 				undelivered++
 				continue
 			}
-			//deliveredNums = append(deliveredNums, blockNum)
-			if req, ok := bd.requests[blockNum]; ok {
-				for _, blockNum := range req.BlockNums {
-					toClean[blockNum] = struct{}{}
-				}
-			}
 			delete(bd.requestedMap, bodyHashes) // Delivered, cleaning up
+			for _, hash := range hashes {
+				blockNum := bd.requestedNums[hash]
+				delete(bd.requestedNums, hash)
+				//deliveredNums = append(deliveredNums, blockNum)
+				if req, ok := bd.requests[blockNum]; ok {
+					for _, blockNum := range req.BlockNums {
+						toClean[blockNum] = struct{}{}
+					}
+					deliveredByRequest[req]++
+				}
 
-			bd.addBodyToCache(blockNum, &types.RawBody{Transactions: txs[i], Uncles: uncles[i], Withdrawals: withdrawals[i]})
-			bd.delivered.Add(blockNum)
-			delivered++
-			dataflow.BlockBodyDownloadStates.AddChange(blockNum, dataflow.BlockBodyReceived)
+				bd.addBodyToCache(blockNum, &types.RawBody{Transactions: txs[i], Uncles: uncles[i], Withdrawals: withdrawals[i]})
+				bd.delivered.Add(blockNum)
+				delivered++
+				dataflow.BlockBodyDownloadStates.AddChange(blockNum, dataflow.BlockBodyReceived)
+			}
+		}
+		now := time.Now()
+		for req, count := range deliveredByRequest {
+			bd.peerDelivered(delivery.peerID, req, count, now)
 		}
 		// Clean up the requests
 		//var clearedNums []uint64
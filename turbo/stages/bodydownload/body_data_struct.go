@@ -17,6 +17,8 @@
 package bodydownload
 
 import (
+	"time"
+
 	"github.com/RoaringBitmap/roaring/v2/roaring64"
 	"github.com/google/btree"
 
@@ -52,7 +54,9 @@ type BodyTreeItem struct {
 // BodyDownload represents the state of body downloading process
 type BodyDownload struct {
 	peerMap          map[[64]byte]int
-	requestedMap     map[BodyHashes]uint64
+	peerStats        map[[64]byte]*peerBodyStats
+	requestedMap     map[BodyHashes][]libcommon.Hash // Header hashes of the requested blocks with the given body
+	requestedNums    map[libcommon.Hash]uint64       // Block numbers of the requested header hashes
 	DeliveryNotify   chan struct{}
 	deliveryCh       chan Delivery
 	Engine           consensus.Engine
@@ -74,21 +78,31 @@ type BodyDownload struct {
 
 // BodyRequest is a sketch of the request for block bodies, meaning that access to the database is required to convert it to the actual BlockBodies request (look up hashes of canonical blocks)
 type BodyRequest struct {
-	BlockNums []uint64
-	Hashes    []libcommon.Hash
-	peerID    [64]byte
-	waitUntil uint64
+	BlockNums     []uint64
+	Hashes        []libcommon.Hash
+	preferredPeer [64]byte // Peer the request is sized for, if any
+	peerID        [64]byte
+	waitUntil     uint64
+	sentAt        time.Time
+	expired       bool
+}
+
+// PreferredPeer returns the peer the request is sized for. Requests without a preferred peer can be sent to any peer.
+func (r *BodyRequest) PreferredPeer() ([64]byte, bool) {
+	return r.preferredPeer, r.preferredPeer != [64]byte{}
 }
 
 // NewBodyDownload create a new body download state object
 func NewBodyDownload(engine consensus.Engine, blockBufferSize, bodyCacheLimit int, br services.FullBlockReader, logger log.Logger) *BodyDownload {
 	bd := &BodyDownload{
-		requestedMap:     make(map[BodyHashes]uint64),
+		requestedMap:     make(map[BodyHashes][]libcommon.Hash),
+		requestedNums:    make(map[libcommon.Hash]uint64),
 		bodyCacheLimit:   bodyCacheLimit,
 		delivered:        roaring64.New(),
 		deliveriesH:      make(map[uint64]*types.Header),
 		requests:         make(map[uint64]*BodyRequest),
 		peerMap:          make(map[[64]byte]int),
+		peerStats:        make(map[[64]byte]*peerBodyStats),
 		prefetchedBlocks: NewPrefetchedBlocks(),
 		// DeliveryNotify has capacity 1, and it is also used so that senders never block
 		// This makes this channel a mailbox with no more than one letter in it, meaning
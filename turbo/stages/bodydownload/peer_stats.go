@@ -0,0 +1,114 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bodydownload
+
+import (
+	"time"
+)
+
+const (
+	// MinBodiesInRequest is the smallest request a peer is sized down to. A peer timing out at that size is forgotten.
+	MinBodiesInRequest = 16
+	// targetBodiesResponseTime is the response latency above which the requests to a peer are shrunk
+	targetBodiesResponseTime = 2 * time.Second
+)
+
+// peerBodyStats keeps the observed behaviour of a peer serving block bodies, to size the requests sent to it
+type peerBodyStats struct {
+	limit    int           // Number of bodies to request from the peer at once
+	latency  time.Duration // Moving average of the time the peer takes to respond
+	inflight *BodyRequest  // Outstanding request to the peer, if any
+}
+
+// idle tells whether the peer can be sent a new request
+func (s *peerBodyStats) idle(currentTime uint64) bool {
+	return s.inflight == nil || currentTime >= s.inflight.waitUntil
+}
+
+// requestTarget picks the known peer with no outstanding request that takes the largest requests, the fastest one on a
+// tie, and returns the number of bodies to request from it. Without such a peer, the request is sized for any peer.
+func (bd *BodyDownload) requestTarget(currentTime uint64) (peer [64]byte, size int) {
+	var best *peerBodyStats
+	for p, stats := range bd.peerStats {
+		if !stats.idle(currentTime) {
+			continue
+		}
+		if best == nil || stats.limit > best.limit || (stats.limit == best.limit && stats.latency < best.latency) {
+			peer, best = p, stats
+		}
+	}
+	if best == nil {
+		return [64]byte{}, bd.blockBufferSize
+	}
+	return peer, best.limit
+}
+
+// peerRequestSent records the request as outstanding for the peer
+func (bd *BodyDownload) peerRequestSent(peer [64]byte, bodyReq *BodyRequest) {
+	stats, ok := bd.peerStats[peer]
+	if !ok {
+		stats = &peerBodyStats{limit: min(max(len(bodyReq.BlockNums), MinBodiesInRequest), MaxBodiesInRequest)}
+		bd.peerStats[peer] = stats
+	}
+	stats.inflight = bodyReq
+}
+
+// peerDelivered adapts the size of the requests to the peer from a response to one of them. A peer returning fewer
+// bodies than requested is limited to the number it returned, the size of its responses being capped. A peer
+// responding slowly gets smaller requests, and a peer responding in full and timely gets larger ones.
+func (bd *BodyDownload) peerDelivered(peer [64]byte, bodyReq *BodyRequest, delivered int, now time.Time) {
+	stats, ok := bd.peerStats[peer]
+	if !ok || bodyReq.peerID != peer {
+		// late delivery of a request which expired and was re-sent
+		return
+	}
+	latency := now.Sub(bodyReq.sentAt)
+	if stats.latency == 0 {
+		stats.latency = latency
+	} else {
+		stats.latency = (3*stats.latency + latency) / 4
+	}
+	switch {
+	case delivered < len(bodyReq.BlockNums):
+		stats.limit = delivered
+	case latency > targetBodiesResponseTime:
+		stats.limit /= 2
+	default:
+		stats.limit *= 2
+	}
+	stats.limit = min(max(stats.limit, MinBodiesInRequest), MaxBodiesInRequest)
+	if stats.inflight == bodyReq {
+		stats.inflight = nil
+	}
+}
+
+// peerTimedOut halves the size of the requests to a peer which did not respond in time, and forgets the peer if its
+// requests were already the smallest, until the peer is picked again for a request sent to any peer.
+func (bd *BodyDownload) peerTimedOut(peer [64]byte, bodyReq *BodyRequest) {
+	stats, ok := bd.peerStats[peer]
+	if !ok {
+		return
+	}
+	if stats.limit <= MinBodiesInRequest {
+		delete(bd.peerStats, peer)
+		return
+	}
+	stats.limit = max(stats.limit/2, MinBodiesInRequest)
+	if stats.inflight == bodyReq {
+		stats.inflight = nil
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bodydownload
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/consensus/ethash"
+	"github.com/erigontech/erigon/core/types"
+)
+
+func sentRequest(bd *BodyDownload, peer [64]byte, size int) *BodyRequest {
+	req := &BodyRequest{BlockNums: make([]uint64, size), Hashes: make([]libcommon.Hash, size)}
+	bd.RequestSent(req, 100, peer)
+	return req
+}
+
+func TestPeerRequestSizing(t *testing.T) {
+	bd := NewBodyDownload(ethash.NewFaker(), 128, 100, nil, log.New())
+	fast, slow := [64]byte{1}, [64]byte{2}
+
+	// unknown peers get requests of the default size, sent to any peer
+	peer, size := bd.requestTarget(0)
+	require.Equal(t, [64]byte{}, peer)
+	require.Equal(t, 128, size)
+
+	req := sentRequest(bd, fast, 128)
+	bd.peerDelivered(fast, req, 128, req.sentAt.Add(time.Second))
+	require.Equal(t, 256, bd.peerStats[fast].limit)
+
+	req = sentRequest(bd, slow, 128)
+	bd.peerDelivered(slow, req, 128, req.sentAt.Add(2*targetBodiesResponseTime))
+	require.Equal(t, 64, bd.peerStats[slow].limit)
+
+	// both are idle, the peer taking the largest requests is preferred
+	peer, size = bd.requestTarget(0)
+	require.Equal(t, fast, peer)
+	require.Equal(t, 256, size)
+
+	// a truncated response limits the requests to what the peer returned
+	req = sentRequest(bd, fast, 256)
+	peer, size = bd.requestTarget(0)
+	require.Equal(t, slow, peer)
+	require.Equal(t, 64, size)
+	bd.peerDelivered(fast, req, 100, req.sentAt.Add(time.Second))
+	require.Equal(t, 100, bd.peerStats[fast].limit)
+
+	// timeouts halve the requests, down to the smallest size, after which the peer is forgotten
+	for limit := 32; limit >= MinBodiesInRequest; limit /= 2 {
+		req = sentRequest(bd, slow, 64)
+		bd.peerTimedOut(slow, req)
+		require.Equal(t, limit, bd.peerStats[slow].limit)
+	}
+	bd.peerTimedOut(slow, sentRequest(bd, slow, MinBodiesInRequest))
+	require.NotContains(t, bd.peerStats, slow)
+}
+
+func TestDeliverSharedBody(t *testing.T) {
+	bd := NewBodyDownload(ethash.NewFaker(), 128, 1<<20, nil, log.New())
+	peer := [64]byte{1}
+
+	// two blocks with the same body, requested from the peer in different requests
+	var bodyHashes BodyHashes
+	copy(bodyHashes[:], types.EmptyUncleHash.Bytes())
+	copy(bodyHashes[length.Hash:], types.EmptyRootHash.Bytes())
+	for _, num := range []uint64{5, 3} {
+		hash := (&types.Header{Number: big.NewInt(int64(num))}).Hash()
+		bd.requestedMap[bodyHashes] = append(bd.requestedMap[bodyHashes], hash)
+		bd.requestedNums[hash] = num
+		req := &BodyRequest{BlockNums: []uint64{num}, Hashes: []libcommon.Hash{hash}}
+		bd.RequestSent(req, 100, peer)
+	}
+
+	bd.DeliverBodies([][][]byte{{}}, [][]*types.Header{{}}, []types.Withdrawals{nil}, 0, peer)
+	_, delivered, err := bd.GetDeliveries(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), delivered)
+	require.NotNil(t, bd.GetBodyFromCache(3, false))
+	require.NotNil(t, bd.GetBodyFromCache(5, false))
+	require.Empty(t, bd.requestedMap)
+	require.Empty(t, bd.requestedNums)
+	require.Empty(t, bd.requests)
+	require.Nil(t, bd.peerStats[peer].inflight)
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package headerdownload
+
+import (
+	"math/big"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/consensus/misc"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// Maximum number of links verified ahead of their insertion in one go
+const verifyAheadLimit = 1024
+
+// verifyAhead verifies the link and the links descending from it in parallel workers, so that InsertHeader finds
+// them verified. Only the seal and the header fields are checked ahead, the total difficulty and the transition to
+// proof-of-stake are still checked one header at a time when inserting. Links failing verification are left
+// unverified for InsertHeader to handle them. Only ethash headers, which only depend on their parent, are verified
+// this way. hd.lock must be held.
+func (hd *HeaderDownload) verifyAhead(link *Link, terminalTotalDifficulty *big.Int) {
+	if hd.consensusHeaderReader == nil || hd.engine.Type() != chain.EtHashConsensus || link.header == nil {
+		return
+	}
+	parent := hd.consensusHeaderReader.GetHeader(link.header.ParentHash, link.blockHeight-1)
+	if parent == nil {
+		return
+	}
+	parentTd := hd.consensusHeaderReader.GetTd(link.header.ParentHash, link.blockHeight-1)
+	if parentTd == nil {
+		return
+	}
+	reader := &verifyAheadReader{
+		config:  hd.consensusHeaderReader.Config(),
+		headers: map[libcommon.Hash]*types.Header{link.header.ParentHash: parent},
+		tds:     map[libcommon.Hash]*big.Int{link.header.ParentHash: parentTd},
+	}
+
+	// Walk the descendants parents first, accumulating their total difficulties
+	batch := make([]*Link, 0, verifyAheadLimit)
+	queue := []*Link{link}
+	for len(queue) > 0 && len(batch) < verifyAheadLimit {
+		l := queue[0]
+		queue = queue[1:]
+		if l.persisted || l.header == nil {
+			continue
+		}
+		parentTd, ok := reader.tds[l.header.ParentHash]
+		if !ok {
+			continue
+		}
+		// Past the terminal proof-of-work block, headers follow the proof-of-stake rules which depend on the total
+		// difficulty of the whole chain, so they and their descendants are verified one by one. A proof-of-stake
+		// header before the terminal total difficulty is invalid and is rejected when inserted.
+		if terminalTotalDifficulty != nil && parentTd.Cmp(terminalTotalDifficulty) >= 0 {
+			continue
+		}
+		if misc.IsPoSHeader(l.header) {
+			continue
+		}
+		reader.headers[l.hash] = l.header
+		reader.tds[l.hash] = new(big.Int).Add(parentTd, l.header.Difficulty)
+		if !l.verified {
+			batch = append(batch, l)
+		}
+		for child := l.fChild; child != nil; child = child.next {
+			queue = append(queue, child)
+		}
+	}
+	if len(batch) < 2 {
+		// nothing to gain over the verification in InsertHeader
+		return
+	}
+
+	errs := make([]error, len(batch))
+	var g errgroup.Group
+	g.SetLimit(runtime.NumCPU())
+	for i, l := range batch {
+		g.Go(func() error {
+			errs[i] = hd.engine.VerifyHeader(&verifyAheadHeaderReader{verifyAheadReader: reader, hash: l.hash}, l.header, true /* seal */)
+			return nil
+		})
+	}
+	g.Wait()
+	for i, l := range batch {
+		if errs[i] == nil {
+			l.verified = true
+		}
+	}
+}
+
+// verifyAheadReader serves the headers of the links being verified ahead, and their parent from the database. It never
+// reads the database, which is not safe from the verification workers.
+type verifyAheadReader struct {
+	config  *chain.Config
+	headers map[libcommon.Hash]*types.Header
+	tds     map[libcommon.Hash]*big.Int
+}
+
+// verifyAheadHeaderReader hides the header being verified, for the engine not to consider it already known
+type verifyAheadHeaderReader struct {
+	*verifyAheadReader
+	hash libcommon.Hash
+}
+
+var _ consensus.ChainHeaderReader = (*verifyAheadHeaderReader)(nil)
+
+func (r *verifyAheadHeaderReader) Config() *chain.Config                  { return r.config }
+func (r *verifyAheadHeaderReader) CurrentHeader() *types.Header           { return nil }
+func (r *verifyAheadHeaderReader) CurrentFinalizedHeader() *types.Header  { return nil }
+func (r *verifyAheadHeaderReader) CurrentSafeHeader() *types.Header       { return nil }
+func (r *verifyAheadHeaderReader) GetHeaderByNumber(uint64) *types.Header { return nil }
+func (r *verifyAheadHeaderReader) FrozenBlocks() uint64                   { return 0 }
+func (r *verifyAheadHeaderReader) FrozenBorBlocks() uint64                { return 0 }
+
+func (r *verifyAheadHeaderReader) GetHeader(hash libcommon.Hash, number uint64) *types.Header {
+	header := r.GetHeaderByHash(hash)
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+func (r *verifyAheadHeaderReader) GetHeaderByHash(hash libcommon.Hash) *types.Header {
+	if hash == r.hash {
+		return nil
+	}
+	return r.headers[hash]
+}
+
+func (r *verifyAheadHeaderReader) GetTd(hash libcommon.Hash, number uint64) *big.Int {
+	if r.GetHeader(hash, number) == nil {
+		return nil
+	}
+	return r.tds[hash]
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package headerdownload
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// parentCheckingEngine accepts any header whose parent it can read, except those marked bad
+type parentCheckingEngine struct {
+	consensus.Engine
+}
+
+func (parentCheckingEngine) Type() chain.ConsensusName { return chain.EtHashConsensus }
+
+func (parentCheckingEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	if chain.GetHeader(header.Hash(), header.Number.Uint64()) != nil {
+		return errors.New("header is already known")
+	}
+	if chain.GetHeader(header.ParentHash, header.Number.Uint64()-1) == nil || chain.GetTd(header.ParentHash, header.Number.Uint64()-1) == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	if bytes.Equal(header.Extra, []byte("bad")) {
+		return errors.New("bad header")
+	}
+	return nil
+}
+
+func newVerifyAheadTest(t *testing.T, length int, bad uint64) (*HeaderDownload, []*Link) {
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1)}
+	hd := NewHeaderDownload(16, 1024, parentCheckingEngine{}, nil, log.New())
+	// the database only has the genesis
+	hd.SetHeaderReader(&verifyAheadHeaderReader{verifyAheadReader: &verifyAheadReader{
+		config:  &chain.Config{},
+		headers: map[libcommon.Hash]*types.Header{genesis.Hash(): genesis},
+		tds:     map[libcommon.Hash]*big.Int{genesis.Hash(): big.NewInt(1)},
+	}})
+
+	links := make([]*Link, 0, length)
+	parentHash := genesis.Hash()
+	for i := 1; i <= length; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(1), ParentHash: parentHash}
+		if uint64(i) == bad {
+			header.Extra = []byte("bad")
+		}
+		link := hd.addHeaderAsLink(ChainSegmentHeader{Header: header, Hash: header.Hash(), Number: uint64(i)}, false)
+		if len(links) > 0 {
+			links[len(links)-1].fChild = link
+		}
+		links = append(links, link)
+		parentHash = link.hash
+	}
+	return hd, links
+}
+
+func TestVerifyAhead(t *testing.T) {
+	hd, links := newVerifyAheadTest(t, 10, 3)
+	hd.verifyAhead(links[0], nil)
+	for _, link := range links {
+		require.Equal(t, link.blockHeight != 3, link.verified, "header %d", link.blockHeight)
+	}
+}
+
+func TestVerifyAheadStopsAtTerminalBlock(t *testing.T) {
+	hd, links := newVerifyAheadTest(t, 10, 0)
+	// genesis has a total difficulty of 1, the terminal proof-of-work block is 5
+	hd.verifyAhead(links[0], big.NewInt(6))
+	for _, link := range links {
+		require.Equal(t, link.blockHeight <= 5, link.verified, "header %d", link.blockHeight)
+	}
+
+	// proof-of-stake headers before the terminal total difficulty are left for insertion to reject
+	hd, links = newVerifyAheadTest(t, 10, 0)
+	links[3].header.Difficulty = big.NewInt(0)
+	hd.verifyAhead(links[0], nil)
+	for _, link := range links {
+		require.Equal(t, link.blockHeight <= 3, link.verified, "header %d", link.blockHeight)
+	}
+}
@@ -146,9 +146,9 @@ func MakeHeaderGetter(requireCanonical bool, tx kv.Getter, headerReader services
 type ReusableCaller struct {
 	evm             *vm.EVM
 	intraBlockState *state.IntraBlockState
+	baseState       *state.IntraBlockState // pre-state with the overrides applied, forked for every call
 	gasCap          uint64
 	baseFee         *uint256.Int
-	stateReader     state.StateReader
 	callTimeout     time.Duration
 	message         *types.Message
 }
@@ -172,7 +172,7 @@ func (r *ReusableCaller) DoCallWithNewGas(
 
 	// reset the EVM so that we can continue to use it with the new context
 	txCtx := core.NewEVMTxContext(r.message)
-	r.intraBlockState = state.New(r.stateReader)
+	r.intraBlockState = r.baseState.Fork()
 	r.evm.Reset(txCtx, r.intraBlockState)
 
 	timedOut := false
@@ -216,6 +216,8 @@ func NewReusableCaller(
 			return nil, err
 		}
 	}
+	// every call starts from a fork of the overridden pre-state, which can only be forked between transactions
+	ibs.SoftFinalise()
 
 	var baseFee *uint256.Int
 	if header != nil && header.BaseFee != nil {
@@ -239,10 +241,10 @@ func NewReusableCaller(
 	return &ReusableCaller{
 		evm:             evm,
 		intraBlockState: ibs,
+		baseState:       ibs,
 		baseFee:         baseFee,
 		gasCap:          gasCap,
 		callTimeout:     callTimeout,
-		stateReader:     stateReader,
 		message:         &msg,
 	}, nil
 }
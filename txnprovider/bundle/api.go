@@ -0,0 +1,78 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bundle
+
+import (
+	"context"
+	"math/big"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// SendBundleArgs are the arguments of bundle_sendBundle.
+type SendBundleArgs struct {
+	Txs         []hexutility.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64     `json:"blockNumber"`
+}
+
+// API is the local RPC interface used by block builder plugins to submit bundles to the payload builder.
+type API struct {
+	pool   *Pool
+	signer *types.Signer
+}
+
+func NewAPI(pool *Pool, chainID *big.Int) *API {
+	return &API{pool: pool, signer: types.LatestSignerForChainID(chainID)}
+}
+
+// APIs returns the bundle namespace, it has to be enabled explicitly with --http.api.
+func (p *Pool) APIs(chainID *big.Int) []rpc.API {
+	return []rpc.API{{
+		Namespace: "bundle",
+		Public:    false,
+		Service:   NewAPI(p, chainID),
+		Version:   "1.0",
+	}}
+}
+
+// SendBundle implements bundle_sendBundle. The transactions are included back to back, in the given order, at the
+// top of block blockNumber if the bundle is among the most valuable ones fitting in the block.
+func (api *API) SendBundle(_ context.Context, args SendBundleArgs) (libcommon.Hash, error) {
+	txns := make([]types.Transaction, 0, len(args.Txs))
+	for _, encoded := range args.Txs {
+		txn, err := types.DecodeWrappedTransaction(encoded)
+		if err != nil {
+			return libcommon.Hash{}, err
+		}
+		sender, err := api.signer.Sender(txn)
+		if err != nil {
+			return libcommon.Hash{}, err
+		}
+		txn.SetSender(sender)
+		txns = append(txns, txn)
+	}
+	return api.pool.AddBundle(uint64(args.BlockNumber), txns)
+}
+
+// CancelBundle implements bundle_cancelBundle.
+func (api *API) CancelBundle(_ context.Context, hash libcommon.Hash) (bool, error) {
+	return api.pool.CancelBundle(hash), nil
+}
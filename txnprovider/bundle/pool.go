@@ -0,0 +1,251 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/txnprovider"
+)
+
+var (
+	ErrEmptyBundle    = errors.New("bundle has no transactions")
+	ErrStaleBundle    = errors.New("bundle targets an already built block")
+	ErrPoolFull       = errors.New("bundle pool is full")
+	ErrBundleConflict = errors.New("bundle contains a transaction which is already part of another bundle")
+)
+
+var (
+	_ txnprovider.TxnProvider = (*Pool)(nil)
+	_ txnprovider.BundleIndex = (*Pool)(nil)
+)
+
+// Bundle is an ordered list of transactions submitted by an external block builder plugin which must be included
+// as a whole, back to back and in the submitted order, in the block with number BlockNum.
+type Bundle struct {
+	Hash     libcommon.Hash
+	BlockNum uint64
+	Txns     []types.Transaction
+	Gas      uint64
+	BlobGas  uint64
+}
+
+func newBundle(blockNum uint64, txns []types.Transaction) *Bundle {
+	b := &Bundle{BlockNum: blockNum, Txns: txns}
+	hashes := make([]byte, 0, len(txns)*32)
+	for _, txn := range txns {
+		b.Gas += txn.GetGas()
+		b.BlobGas += txn.GetBlobGas()
+		txnHash := txn.Hash()
+		hashes = append(hashes, txnHash[:]...)
+	}
+	b.Hash = crypto.Keccak256Hash(hashes)
+	return b
+}
+
+// Score is the amount of priority fees the bundle pays at most in a block with the given base fee (nil if unknown),
+// bundles are included in descending score order.
+func (b *Bundle) Score(baseFee *uint256.Int) *uint256.Int {
+	score := new(uint256.Int)
+	for _, txn := range b.Txns {
+		fee := new(uint256.Int).Mul(txn.GetEffectiveGasTip(baseFee), uint256.NewInt(txn.GetGas()))
+		score.Add(score, fee)
+	}
+	return score
+}
+
+// payable returns false if a transaction of the bundle can't pay the given base fee, which makes the bundle invalid.
+func (b *Bundle) payable(baseFee *uint256.Int) bool {
+	if baseFee == nil {
+		return true
+	}
+	for _, txn := range b.Txns {
+		if txn.GetFeeCap().Lt(baseFee) {
+			return false
+		}
+	}
+	return true
+}
+
+// Pool is a TxnProvider which puts the most valuable bundles fitting in the block first and then fills the remaining
+// gas with the transactions of the secondary txn provider (devp2p txpool). The block builder finds the bundles among
+// the provided transactions with BundleOf, to include each of them as a whole or not at all.
+type Pool struct {
+	logger               log.Logger
+	config               Config
+	secondaryTxnProvider txnprovider.TxnProvider
+
+	mu      sync.Mutex
+	bundles map[libcommon.Hash]*Bundle
+	txns    map[libcommon.Hash]libcommon.Hash // txn hash -> bundle hash
+}
+
+func NewPool(logger log.Logger, config Config, secondaryTxnProvider txnprovider.TxnProvider) *Pool {
+	return &Pool{
+		logger:               logger.New("component", "bundle"),
+		config:               config,
+		secondaryTxnProvider: secondaryTxnProvider,
+		bundles:              make(map[libcommon.Hash]*Bundle),
+		txns:                 make(map[libcommon.Hash]libcommon.Hash),
+	}
+}
+
+// AddBundle adds a bundle of transactions targeting block blockNum. The senders of the transactions must already be set.
+func (p *Pool) AddBundle(blockNum uint64, txns []types.Transaction) (libcommon.Hash, error) {
+	if len(txns) == 0 {
+		return libcommon.Hash{}, ErrEmptyBundle
+	}
+	for _, txn := range txns {
+		if _, ok := txn.GetSender(); !ok {
+			return libcommon.Hash{}, fmt.Errorf("bundle transaction %x has no sender", txn.Hash())
+		}
+	}
+
+	b := newBundle(blockNum, txns)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.bundles[b.Hash]; ok {
+		return b.Hash, nil
+	}
+	if len(p.bundles) >= p.config.MaxBundles {
+		return libcommon.Hash{}, ErrPoolFull
+	}
+	for _, txn := range txns {
+		if _, ok := p.txns[txn.Hash()]; ok {
+			return libcommon.Hash{}, fmt.Errorf("%w: %x", ErrBundleConflict, txn.Hash())
+		}
+	}
+	p.bundles[b.Hash] = b
+	for _, txn := range txns {
+		p.txns[txn.Hash()] = b.Hash
+	}
+	p.logger.Debug("bundle added", "hash", b.Hash, "block", blockNum, "txns", len(txns), "gas", b.Gas)
+	return b.Hash, nil
+}
+
+// CancelBundle removes a pending bundle, it returns false if the bundle is not known.
+func (p *Pool) CancelBundle(hash libcommon.Hash) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b, ok := p.bundles[hash]
+	if ok {
+		p.removeBundle(b)
+	}
+	return ok
+}
+
+// removeBundle must be called with the lock held.
+func (p *Pool) removeBundle(b *Bundle) {
+	delete(p.bundles, b.Hash)
+	for _, txn := range b.Txns {
+		delete(p.txns, txn.Hash())
+	}
+}
+
+// BundleOf returns the transactions of the pending bundle the txn belongs to.
+func (p *Pool) BundleOf(txnHash libcommon.Hash) ([]types.Transaction, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	bundleHash, ok := p.txns[txnHash]
+	if !ok {
+		return nil, false
+	}
+	return p.bundles[bundleHash].Txns, true
+}
+
+// bestBundles returns the bundles targeting block blockNum which pay its base fee, most valuable first, and drops the
+// ones targeting blocks which have already been built.
+func (p *Pool) bestBundles(blockNum uint64, baseFee *uint256.Int) []*Bundle {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var best []*Bundle
+	scores := map[libcommon.Hash]*uint256.Int{}
+	for _, b := range p.bundles {
+		switch {
+		case b.BlockNum < blockNum:
+			p.removeBundle(b)
+		case b.BlockNum == blockNum && b.payable(baseFee):
+			best = append(best, b)
+			scores[b.Hash] = b.Score(baseFee)
+		}
+	}
+	sort.Slice(best, func(i, j int) bool {
+		if c := scores[best[i].Hash].Cmp(scores[best[j].Hash]); c != 0 {
+			return c > 0
+		}
+		return best[i].Hash.Cmp(best[j].Hash) < 0
+	})
+	return best
+}
+
+func (p *Pool) ProvideTxns(ctx context.Context, opts ...txnprovider.ProvideOption) ([]types.Transaction, error) {
+	provideOpts := txnprovider.ApplyProvideOptions(opts...)
+	gasTarget, blobGasTarget, amount := provideOpts.GasTarget, provideOpts.BlobGasTarget, provideOpts.Amount
+
+	var txns []types.Transaction
+	for _, b := range p.bestBundles(provideOpts.ParentBlockNum+1, provideOpts.BaseFee) {
+		if b.Gas > gasTarget || b.BlobGas > blobGasTarget || len(b.Txns) > amount || p.yielded(b, provideOpts) {
+			continue
+		}
+		for _, txn := range b.Txns {
+			provideOpts.TxnIdsFilter.Add(txn.Hash())
+		}
+		txns = append(txns, b.Txns...)
+		gasTarget -= b.Gas
+		blobGasTarget -= b.BlobGas
+		amount -= len(b.Txns)
+	}
+	if amount == 0 {
+		return txns, nil
+	}
+
+	remaining, err := p.secondaryTxnProvider.ProvideTxns(
+		ctx,
+		txnprovider.WithParentBlockNum(provideOpts.ParentBlockNum),
+		txnprovider.WithBlockTime(provideOpts.BlockTime),
+		txnprovider.WithAmount(amount),
+		txnprovider.WithGasTarget(gasTarget),
+		txnprovider.WithBlobGasTarget(blobGasTarget),
+		txnprovider.WithTxnIdsFilter(provideOpts.TxnIdsFilter),
+		txnprovider.WithBaseFee(provideOpts.BaseFee),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return append(txns, remaining...), nil
+}
+
+// yielded returns true if any transaction of the bundle has already been provided for the block being built, in which
+// case the bundle can no longer be included as a whole.
+func (p *Pool) yielded(b *Bundle, opts txnprovider.ProvideOptions) bool {
+	for _, txn := range b.Txns {
+		if opts.TxnIdsFilter.Contains(txn.Hash()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bundle
+
+import (
+	"context"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/txnprovider"
+)
+
+type recordingTxnProvider struct {
+	txns []types.Transaction
+	opts txnprovider.ProvideOptions
+}
+
+func (r *recordingTxnProvider) ProvideTxns(_ context.Context, opts ...txnprovider.ProvideOption) ([]types.Transaction, error) {
+	r.opts = txnprovider.ApplyProvideOptions(opts...)
+	return r.txns, nil
+}
+
+func newTestTxn(nonce, gas, gasPrice uint64) types.Transaction {
+	txn := types.NewTransaction(nonce, libcommon.Address{1}, uint256.NewInt(0), gas, uint256.NewInt(gasPrice), nil)
+	txn.SetSender(libcommon.Address{2})
+	return txn
+}
+
+func newTestDynamicFeeTxn(nonce, gas, tip, feeCap uint64) types.Transaction {
+	to := libcommon.Address{1}
+	txn := &types.DynamicFeeTransaction{
+		CommonTx: types.CommonTx{Nonce: nonce, Gas: gas, To: &to, Value: uint256.NewInt(0)},
+		ChainID:  uint256.NewInt(1),
+		Tip:      uint256.NewInt(tip),
+		FeeCap:   uint256.NewInt(feeCap),
+	}
+	txn.SetSender(libcommon.Address{2})
+	return txn
+}
+
+func TestProvideTxnsBundlesFirst(t *testing.T) {
+	secondaryTxn := newTestTxn(100, 21_000, 1)
+	secondary := &recordingTxnProvider{txns: []types.Transaction{secondaryTxn}}
+	pool := NewPool(log.New(), DefaultConfig, secondary)
+
+	low := []types.Transaction{newTestTxn(0, 21_000, 1), newTestTxn(1, 21_000, 1)}
+	high := []types.Transaction{newTestTxn(2, 30_000, 10)}
+	tooBig := []types.Transaction{newTestTxn(3, 100_000, 100)}
+	stale := []types.Transaction{newTestTxn(4, 21_000, 100)}
+	_, err := pool.AddBundle(11, low)
+	require.NoError(t, err)
+	_, err = pool.AddBundle(11, high)
+	require.NoError(t, err)
+	_, err = pool.AddBundle(11, tooBig)
+	require.NoError(t, err)
+	_, err = pool.AddBundle(10, stale)
+	require.NoError(t, err)
+
+	yielded := mapset.NewSet[[32]byte]()
+	txns, err := pool.ProvideTxns(
+		context.Background(),
+		txnprovider.WithParentBlockNum(10),
+		txnprovider.WithGasTarget(80_000),
+		txnprovider.WithAmount(10),
+		txnprovider.WithTxnIdsFilter(yielded),
+	)
+	require.NoError(t, err)
+	// the most valuable bundle goes first, the one exceeding the gas target is skipped.
+	require.Equal(t, []types.Transaction{high[0], low[0], low[1], secondaryTxn}, txns)
+	require.Equal(t, uint64(80_000-72_000), secondary.opts.GasTarget)
+	require.Equal(t, 7, secondary.opts.Amount)
+	require.Equal(t, uint64(10), secondary.opts.ParentBlockNum)
+	require.Equal(t, 3, yielded.Cardinality())
+
+	// bundles which have already been yielded are not provided again.
+	secondary.txns = nil
+	txns, err = pool.ProvideTxns(context.Background(), txnprovider.WithParentBlockNum(10), txnprovider.WithTxnIdsFilter(yielded))
+	require.NoError(t, err)
+	require.Equal(t, tooBig, txns)
+
+	// bundles targeting already built blocks are dropped.
+	_, err = pool.ProvideTxns(context.Background(), txnprovider.WithParentBlockNum(11))
+	require.NoError(t, err)
+	require.Empty(t, pool.bundles)
+	require.Empty(t, pool.txns)
+}
+
+func TestProvideTxnsEffectiveTip(t *testing.T) {
+	pool := NewPool(log.New(), DefaultConfig, &recordingTxnProvider{})
+	legacy := []types.Transaction{newTestTxn(0, 21_000, 10)}
+	dynamic := []types.Transaction{newTestDynamicFeeTxn(1, 21_000, 3, 20)}
+	underpriced := []types.Transaction{newTestDynamicFeeTxn(2, 21_000, 100, 7)}
+	for _, b := range [][]types.Transaction{legacy, dynamic, underpriced} {
+		_, err := pool.AddBundle(11, b)
+		require.NoError(t, err)
+	}
+
+	// without a base fee the tip caps are compared
+	txns, err := pool.ProvideTxns(context.Background(), txnprovider.WithParentBlockNum(10),
+		txnprovider.WithTxnIdsFilter(mapset.NewSet[[32]byte]()))
+	require.NoError(t, err)
+	require.Equal(t, []types.Transaction{underpriced[0], legacy[0], dynamic[0]}, txns)
+
+	// at a base fee of 8 the legacy txn tips 2 and the dynamic fee one 3, the underpriced bundle can't be included
+	txns, err = pool.ProvideTxns(context.Background(), txnprovider.WithParentBlockNum(10),
+		txnprovider.WithTxnIdsFilter(mapset.NewSet[[32]byte]()), txnprovider.WithBaseFee(uint256.NewInt(8)))
+	require.NoError(t, err)
+	require.Equal(t, []types.Transaction{dynamic[0], legacy[0]}, txns)
+}
+
+func TestBundleOf(t *testing.T) {
+	pool := NewPool(log.New(), DefaultConfig, &recordingTxnProvider{})
+	b := []types.Transaction{newTestTxn(0, 21_000, 1), newTestTxn(1, 21_000, 1)}
+	hash, err := pool.AddBundle(1, b)
+	require.NoError(t, err)
+
+	for _, txn := range b {
+		txns, ok := pool.BundleOf(txn.Hash())
+		require.True(t, ok)
+		require.Equal(t, b, txns)
+	}
+	_, ok := pool.BundleOf(newTestTxn(2, 21_000, 1).Hash())
+	require.False(t, ok)
+
+	require.True(t, pool.CancelBundle(hash))
+	_, ok = pool.BundleOf(b[0].Hash())
+	require.False(t, ok)
+}
+
+func TestAddBundle(t *testing.T) {
+	pool := NewPool(log.New(), Config{Enabled: true, MaxBundles: 1}, &recordingTxnProvider{})
+
+	_, err := pool.AddBundle(1, nil)
+	require.ErrorIs(t, err, ErrEmptyBundle)
+
+	noSender := types.NewTransaction(0, libcommon.Address{1}, uint256.NewInt(0), 21_000, uint256.NewInt(1), nil)
+	_, err = pool.AddBundle(1, []types.Transaction{noSender})
+	require.Error(t, err)
+
+	txn := newTestTxn(0, 21_000, 1)
+	hash, err := pool.AddBundle(1, []types.Transaction{txn})
+	require.NoError(t, err)
+	again, err := pool.AddBundle(1, []types.Transaction{txn})
+	require.NoError(t, err)
+	require.Equal(t, hash, again)
+
+	_, err = pool.AddBundle(1, []types.Transaction{newTestTxn(1, 21_000, 1)})
+	require.ErrorIs(t, err, ErrPoolFull)
+
+	pool.config.MaxBundles = 2
+	_, err = pool.AddBundle(1, []types.Transaction{newTestTxn(1, 21_000, 1), txn})
+	require.ErrorIs(t, err, ErrBundleConflict)
+	pool.config.MaxBundles = 1
+
+	require.True(t, pool.CancelBundle(hash))
+	require.False(t, pool.CancelBundle(hash))
+	_, err = pool.AddBundle(1, []types.Transaction{newTestTxn(1, 21_000, 1), txn})
+	require.NoError(t, err)
+	_, err = pool.AddBundle(2, []types.Transaction{newTestTxn(2, 21_000, 1)})
+	require.ErrorIs(t, err, ErrPoolFull)
+}
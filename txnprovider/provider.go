@@ -21,20 +21,37 @@ import (
 	"math"
 
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/holiman/uint256"
 
+	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon/core/types"
 )
 
 type TxnProvider interface {
 	// ProvideTxns provides transactions ready to be included in a block for block building. Available request options:
 	//   - WithParentBlockNum
+	//   - WithBlockTime
 	//   - WithAmount
 	//   - WithGasTarget
 	//   - WithBlobGasTarget
 	//   - WithTxnIdsFilter
+	//   - WithBaseFee
 	ProvideTxns(ctx context.Context, opts ...ProvideOption) ([]types.Transaction, error)
 }
 
+// BlockTxnsValidator checks the transactions of a block against the rules a txn provider puts them into blocks by,
+// e.g. the order of the decrypted shutter transactions.
+type BlockTxnsValidator interface {
+	ValidateBlockTxns(ctx context.Context, blockTime uint64, txns types.Transactions) error
+}
+
+// BundleIndex is implemented by the txn providers which yield bundles: transactions which have to be included back to
+// back, in the provided order, and either all of them or none.
+type BundleIndex interface {
+	// BundleOf returns the transactions of the bundle the txn belongs to, false if it isn't part of a bundle.
+	BundleOf(txnHash libcommon.Hash) ([]types.Transaction, bool)
+}
+
 type ProvideOption func(opt *ProvideOptions)
 
 func WithParentBlockNum(blockNum uint64) ProvideOption {
@@ -43,6 +60,12 @@ func WithParentBlockNum(blockNum uint64) ProvideOption {
 	}
 }
 
+func WithBlockTime(blockTime uint64) ProvideOption {
+	return func(opt *ProvideOptions) {
+		opt.BlockTime = blockTime
+	}
+}
+
 func WithAmount(amount int) ProvideOption {
 	return func(opt *ProvideOptions) {
 		opt.Amount = amount
@@ -67,26 +90,39 @@ func WithTxnIdsFilter(txnIdsFilter mapset.Set[[32]byte]) ProvideOption {
 	}
 }
 
+func WithBaseFee(baseFee *uint256.Int) ProvideOption {
+	return func(opt *ProvideOptions) {
+		opt.BaseFee = baseFee
+	}
+}
+
 type ProvideOptions struct {
 	ParentBlockNum uint64
+	BlockTime      uint64
 	Amount         int
 	GasTarget      uint64
 	BlobGasTarget  uint64
 	TxnIdsFilter   mapset.Set[[32]byte]
+	BaseFee        *uint256.Int
 }
 
 func ApplyProvideOptions(opts ...ProvideOption) ProvideOptions {
-	config := defaultProvideOptions
+	config := defaultProvideOptions()
 	for _, opt := range opts {
 		opt(&config)
 	}
 	return config
 }
 
-var defaultProvideOptions = ProvideOptions{
-	ParentBlockNum: 0,                         // no parent block to wait for by default
-	Amount:         math.MaxInt,               // all transactions by default
-	GasTarget:      math.MaxUint64,            // all transactions by default
-	BlobGasTarget:  math.MaxUint64,            // all transactions by default
-	TxnIdsFilter:   mapset.NewSet[[32]byte](), // no filter by default
+// defaultProvideOptions returns new defaults on each call, the filter is filled in by the providers so it can't be shared
+func defaultProvideOptions() ProvideOptions {
+	return ProvideOptions{
+		ParentBlockNum: 0,                         // no parent block to wait for by default
+		BlockTime:      0,                         // unknown block time by default
+		Amount:         math.MaxInt,               // all transactions by default
+		GasTarget:      math.MaxUint64,            // all transactions by default
+		BlobGasTarget:  math.MaxUint64,            // all transactions by default
+		TxnIdsFilter:   mapset.NewSet[[32]byte](), // no filter by default
+		BaseFee:        nil,                       // unknown base fee by default
+	}
 }
@@ -17,8 +17,9 @@
 package shutter
 
 import (
+	"time"
+
 	"github.com/erigontech/erigon-lib/chain/networkname"
-	"github.com/erigontech/erigon/params"
 )
 
 type Config struct {
@@ -29,6 +30,27 @@ type Config struct {
 	KeyBroadcastContractAddress      string   `json:"keyBroadcastContractAddress"`
 	KeyperSetManagerContractAddress  string   `json:"keyperSetManagerContractAddress"`
 	KeyperBootnodes                  []string `json:"keyperBootnodes"`
+	// P2pListenAddr is the multiaddr the node listens on in the keyper p2p network, any port if empty.
+	P2pListenAddr               string `json:"p2pListenAddr"`
+	BeaconChainGenesisTimestamp uint64 `json:"beaconChainGenesisTimestamp"`
+	SecondsPerSlot              uint64 `json:"secondsPerSlot"`
+	// EncryptedGasLimit is the maximum amount of gas the decrypted transactions may use in a block.
+	EncryptedGasLimit uint64 `json:"encryptedGasLimit"`
+	// MaxDecryptionKeysDelay is how long after the start of a slot the block building waits for its decryption keys.
+	MaxDecryptionKeysDelay time.Duration `json:"maxDecryptionKeysDelay"`
+}
+
+// SlotAt returns the beacon chain slot of the block with the given timestamp.
+func (c Config) SlotAt(timestamp uint64) uint64 {
+	if timestamp < c.BeaconChainGenesisTimestamp {
+		return 0
+	}
+	return (timestamp - c.BeaconChainGenesisTimestamp) / c.SecondsPerSlot
+}
+
+// SlotStart returns the start time of the beacon chain slot.
+func (c Config) SlotStart(slot uint64) time.Time {
+	return time.Unix(int64(c.BeaconChainGenesisTimestamp+slot*c.SecondsPerSlot), 0)
 }
 
 func ConfigByChainName(chainName string) Config {
@@ -45,7 +67,7 @@ func ConfigByChainName(chainName string) Config {
 var (
 	chiadoConfig = Config{
 		Enabled:                          true,
-		InstanceId:                       params.ChiadoChainConfig.ChainID.Uint64(),
+		InstanceId:                       102_000,
 		SequencerContractAddress:         "0x2aD8E2feB0ED5b2EC8e700edB725f120576994ed",
 		ValidatorRegistryContractAddress: "0xa9289A3Dd14FEBe10611119bE81E5d35eAaC3084",
 		KeyBroadcastContractAddress:      "0x9D31865BEffcE842FBd36CDA587aDDA8bef804B7",
@@ -54,11 +76,15 @@ var (
 			"/ip4/167.99.177.227/tcp/23005/p2p/12D3KooWSdm5guPBdn8DSaBphVBzUUgPLg9sZLnazEUrcbtLy254",
 			"/ip4/159.89.15.119/tcp/23005/p2p/12D3KooWPP6bp2PJQR8rUvG1SD4qNH4WFrKve6DMgWThyKxwNbbH",
 		},
+		BeaconChainGenesisTimestamp: 1665396300,
+		SecondsPerSlot:              5,
+		EncryptedGasLimit:           10_000_000,
+		MaxDecryptionKeysDelay:      1500 * time.Millisecond,
 	}
 
 	gnosisConfig = Config{
 		Enabled:                          true,
-		InstanceId:                       params.GnosisChainConfig.ChainID.Uint64(),
+		InstanceId:                       1_000,
 		SequencerContractAddress:         "0xc5C4b277277A1A8401E0F039dfC49151bA64DC2E",
 		ValidatorRegistryContractAddress: "0xefCC23E71f6bA9B22C4D28F7588141d44496A6D6",
 		KeyBroadcastContractAddress:      "0x626dB87f9a9aC47070016A50e802dd5974341301",
@@ -67,5 +93,9 @@ var (
 			"/ip4/167.99.177.227/tcp/23003/p2p/12D3KooWD35AESYCttDEi3J5WnQdTFuM5JNtmuXEb1x4eQ28gb1s",
 			"/ip4/159.89.15.119/tcp/23003/p2p/12D3KooWRzAhgPA16DiBQhiuYoasYzJaQSAbtc5i5FvgTi9ZDQtS",
 		},
+		BeaconChainGenesisTimestamp: 1638993340,
+		SecondsPerSlot:              5,
+		EncryptedGasLimit:           10_000_000,
+		MaxDecryptionKeysDelay:      1500 * time.Millisecond,
 	}
 )
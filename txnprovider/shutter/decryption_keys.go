@@ -0,0 +1,51 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package shutter
+
+import (
+	"context"
+
+	"github.com/erigontech/erigon/core/types"
+)
+
+// DecryptionKeys are the keys of the encrypted transactions of a slot, released by the keypers once the slot has
+// started. The keys are in the order of the transactions in the sequencer contract from TxnPointer on, which is the
+// order the decrypted transactions go into the block.
+type DecryptionKeys struct {
+	InstanceId uint64
+	Eon        uint64
+	Slot       uint64
+	TxnPointer uint64
+	Keys       []DecryptionKey
+}
+
+type DecryptionKey struct {
+	IdentityPreimage []byte
+	Key              []byte
+}
+
+// DecryptionKeysSource delivers the decryption keys released by the keypers, e.g. from the keyper p2p network, until
+// ctx is done.
+type DecryptionKeysSource interface {
+	Run(ctx context.Context, keys chan<- *DecryptionKeys) error
+}
+
+// TxnDecrypter decrypts the transactions submitted to the sequencer contract for the released keys, in the order of
+// the keys. The transactions which fail to decrypt or decode are left out, the senders of the others must be set.
+type TxnDecrypter interface {
+	DecryptTxns(ctx context.Context, keys *DecryptionKeys) ([]types.Transaction, error)
+}
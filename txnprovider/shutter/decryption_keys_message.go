@@ -0,0 +1,168 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package shutter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var ErrInvalidDecryptionKeysMessage = errors.New("invalid decryption keys message")
+
+// The protobuf messages of the keyper p2p network carrying the decryption keys:
+//
+//	message Envelope { string version = 1; google.protobuf.Any message = 2; }
+//	message DecryptionKeys { uint64 instance_id = 1; uint64 eon = 2; repeated Key keys = 3; GnosisDecryptionKeysExtra gnosis = 4; }
+//	message Key { bytes identity = 1; bytes key = 2; }
+//	message GnosisDecryptionKeysExtra { uint64 slot = 1; uint64 tx_pointer = 2; repeated uint64 signer_indices = 3; repeated bytes signatures = 4; }
+//
+// Only the fields the pool uses are decoded, the unknown ones are skipped.
+const (
+	envelopeMessageField = 2
+	anyTypeUrlField      = 1
+	anyValueField        = 2
+
+	decryptionKeysInstanceIdField = 1
+	decryptionKeysEonField        = 2
+	decryptionKeysKeysField       = 3
+	decryptionKeysGnosisField     = 4
+
+	keyIdentityField = 1
+	keyKeyField      = 2
+
+	gnosisExtraSlotField       = 1
+	gnosisExtraTxnPointerField = 2
+)
+
+const decryptionKeysTypeName = "DecryptionKeys"
+
+// decodeDecryptionKeysEnvelope decodes the decryption keys of a gossiped envelope.
+func decodeDecryptionKeysEnvelope(data []byte) (*DecryptionKeys, error) {
+	var anyMsg []byte
+	err := decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num == envelopeMessageField && typ == protowire.BytesType {
+			anyMsg = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if anyMsg == nil {
+		return nil, fmt.Errorf("%w: envelope without message", ErrInvalidDecryptionKeysMessage)
+	}
+
+	var typeUrl string
+	var msg []byte
+	err = decodeFields(anyMsg, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch {
+		case num == anyTypeUrlField && typ == protowire.BytesType:
+			typeUrl = string(value)
+		case num == anyValueField && typ == protowire.BytesType:
+			msg = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if typeUrl != decryptionKeysTypeName && !strings.HasSuffix(typeUrl, "."+decryptionKeysTypeName) && !strings.HasSuffix(typeUrl, "/"+decryptionKeysTypeName) {
+		return nil, fmt.Errorf("%w: unexpected message type %q", ErrInvalidDecryptionKeysMessage, typeUrl)
+	}
+	return decodeDecryptionKeys(msg)
+}
+
+func decodeDecryptionKeys(data []byte) (*DecryptionKeys, error) {
+	keys := &DecryptionKeys{}
+	var gnosis bool
+	err := decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == decryptionKeysInstanceIdField && typ == protowire.VarintType:
+			keys.InstanceId = varint
+		case num == decryptionKeysEonField && typ == protowire.VarintType:
+			keys.Eon = varint
+		case num == decryptionKeysKeysField && typ == protowire.BytesType:
+			var key DecryptionKey
+			err := decodeFields(value, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+				switch {
+				case num == keyIdentityField && typ == protowire.BytesType:
+					key.IdentityPreimage = value
+				case num == keyKeyField && typ == protowire.BytesType:
+					key.Key = value
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			keys.Keys = append(keys.Keys, key)
+		case num == decryptionKeysGnosisField && typ == protowire.BytesType:
+			gnosis = true
+			return decodeFields(value, func(num protowire.Number, typ protowire.Type, _ []byte, varint uint64) error {
+				switch {
+				case num == gnosisExtraSlotField && typ == protowire.VarintType:
+					keys.Slot = varint
+				case num == gnosisExtraTxnPointerField && typ == protowire.VarintType:
+					keys.TxnPointer = varint
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !gnosis {
+		return nil, fmt.Errorf("%w: no gnosis extra", ErrInvalidDecryptionKeysMessage)
+	}
+	return keys, nil
+}
+
+// decodeFields calls f with the fields of the message, the value of the length delimited fields or the varint.
+func decodeFields(data []byte, f func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("%w: %w", ErrInvalidDecryptionKeysMessage, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return fmt.Errorf("%w: %w", ErrInvalidDecryptionKeysMessage, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		if err := f(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package shutter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendBytesField(b []byte, num protowire.Number, value []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+func appendVarintField(b []byte, num protowire.Number, value uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func encodeDecryptionKeysEnvelope(typeUrl string, keys *DecryptionKeys, gnosis bool) []byte {
+	var msg []byte
+	msg = appendVarintField(msg, decryptionKeysInstanceIdField, keys.InstanceId)
+	msg = appendVarintField(msg, decryptionKeysEonField, keys.Eon)
+	for _, key := range keys.Keys {
+		var keyMsg []byte
+		keyMsg = appendBytesField(keyMsg, keyIdentityField, key.IdentityPreimage)
+		keyMsg = appendBytesField(keyMsg, keyKeyField, key.Key)
+		msg = appendBytesField(msg, decryptionKeysKeysField, keyMsg)
+	}
+	if gnosis {
+		var extra []byte
+		extra = appendVarintField(extra, gnosisExtraSlotField, keys.Slot)
+		extra = appendVarintField(extra, gnosisExtraTxnPointerField, keys.TxnPointer)
+		extra = appendVarintField(extra, 3, 7) // signer indices, skipped
+		msg = appendBytesField(msg, decryptionKeysGnosisField, extra)
+	}
+
+	var anyMsg []byte
+	anyMsg = appendBytesField(anyMsg, anyTypeUrlField, []byte(typeUrl))
+	anyMsg = appendBytesField(anyMsg, anyValueField, msg)
+
+	var envelope []byte
+	envelope = appendBytesField(envelope, 1, []byte("0.1.0")) // version, skipped
+	return appendBytesField(envelope, envelopeMessageField, anyMsg)
+}
+
+func TestDecodeDecryptionKeysEnvelope(t *testing.T) {
+	keys := &DecryptionKeys{
+		InstanceId: 102_000,
+		Eon:        3,
+		Slot:       12_345,
+		TxnPointer: 42,
+		Keys: []DecryptionKey{
+			{IdentityPreimage: []byte{0x1, 0x2}, Key: []byte{0x3}},
+			{IdentityPreimage: []byte{0x4}, Key: []byte{0x5, 0x6}},
+		},
+	}
+
+	decoded, err := decodeDecryptionKeysEnvelope(encodeDecryptionKeysEnvelope("type.googleapis.com/p2pmsg.DecryptionKeys", keys, true))
+	require.NoError(t, err)
+	require.Equal(t, keys, decoded)
+
+	_, err = decodeDecryptionKeysEnvelope(encodeDecryptionKeysEnvelope("type.googleapis.com/p2pmsg.DecryptionKeys", keys, false))
+	require.ErrorIs(t, err, ErrInvalidDecryptionKeysMessage)
+
+	_, err = decodeDecryptionKeysEnvelope(encodeDecryptionKeysEnvelope("type.googleapis.com/p2pmsg.DecryptionTrigger", keys, true))
+	require.ErrorIs(t, err, ErrInvalidDecryptionKeysMessage)
+
+	_, err = decodeDecryptionKeysEnvelope([]byte{0xff})
+	require.ErrorIs(t, err, ErrInvalidDecryptionKeysMessage)
+}
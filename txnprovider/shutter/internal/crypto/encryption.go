@@ -0,0 +1,252 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package crypto implements the threshold identity based encryption of the shutter transactions (shcrypto of the
+// shutter network): the transactions are encrypted for an identity with the public key of the keypers' eon, and the
+// keypers release the decryption key of the identity, a BLS12-381 G1 point, once the slot it is meant for has started.
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"golang.org/x/crypto/sha3"
+)
+
+const BlockSize = 32
+
+// VersionId is the optional first byte of the encoded encrypted messages.
+const VersionId = 0x03
+
+var (
+	ErrInvalidEncryptedMessage = errors.New("invalid encrypted message")
+	ErrInvalidPadding          = errors.New("invalid padding")
+	ErrInvalidC1               = errors.New("invalid C1 of the encrypted message")
+)
+
+// identityDST is the domain separation tag of the hashing of the identities onto G1.
+var identityDST = []byte("SHUTTER_V01_BLS12381G1_XMD:SHA-256_SSWU_RO_")
+
+type Block [BlockSize]byte
+
+// EncryptedMessage is a message encrypted for an identity: C1 commits to the randomness of the encryption, C2 is
+// sigma masked with the key derived from the pairing of the identity and the eon key, and C3 are the padded
+// message blocks masked with the keys derived from sigma.
+type EncryptedMessage struct {
+	C1 *bls12381.G2Affine
+	C2 Block
+	C3 []Block
+}
+
+// EpochSecretKey is the decryption key of an identity released by the keypers.
+type EpochSecretKey bls12381.G1Affine
+
+// EonPublicKey is the public key of the keypers' eon the messages are encrypted with.
+type EonPublicKey bls12381.G2Affine
+
+// Unmarshal decodes the encrypted message: [version id] | C1 (compressed G2) | C2 | C3 blocks.
+func (m *EncryptedMessage) Unmarshal(d []byte) error {
+	if len(d)%BlockSize == 1 {
+		if d[0] != VersionId {
+			return fmt.Errorf("%w: unknown version %d", ErrInvalidEncryptedMessage, d[0])
+		}
+		d = d[1:]
+	}
+	if len(d) < bls12381.SizeOfG2AffineCompressed+2*BlockSize || len(d)%BlockSize != 0 {
+		return fmt.Errorf("%w: length %d", ErrInvalidEncryptedMessage, len(d))
+	}
+
+	m.C1 = new(bls12381.G2Affine)
+	if _, err := m.C1.SetBytes(d[:bls12381.SizeOfG2AffineCompressed]); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEncryptedMessage, err)
+	}
+	d = d[bls12381.SizeOfG2AffineCompressed:]
+	copy(m.C2[:], d[:BlockSize])
+	d = d[BlockSize:]
+	m.C3 = make([]Block, len(d)/BlockSize)
+	for i := range m.C3 {
+		copy(m.C3[i][:], d[i*BlockSize:])
+	}
+	return nil
+}
+
+// Marshal encodes the encrypted message, with the version id.
+func (m *EncryptedMessage) Marshal() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(VersionId)
+	c1 := m.C1.Bytes()
+	buf.Write(c1[:])
+	buf.Write(m.C2[:])
+	for _, block := range m.C3 {
+		buf.Write(block[:])
+	}
+	return buf.Bytes()
+}
+
+// Unmarshal decodes the compressed G1 point of the key.
+func (k *EpochSecretKey) Unmarshal(d []byte) error {
+	if len(d) != bls12381.SizeOfG1AffineCompressed {
+		return fmt.Errorf("invalid epoch secret key length %d", len(d))
+	}
+	_, err := (*bls12381.G1Affine)(k).SetBytes(d)
+	return err
+}
+
+// Decrypt decrypts the message with the key of the identity it is encrypted for.
+func (m *EncryptedMessage) Decrypt(key *EpochSecretKey) ([]byte, error) {
+	p, err := bls12381.Pair([]bls12381.G1Affine{bls12381.G1Affine(*key)}, []bls12381.G2Affine{*m.C1})
+	if err != nil {
+		return nil, err
+	}
+	sigma := xorBlocks(m.C2, hash2(&p))
+
+	keys := blockKeys(sigma, len(m.C3))
+	blocks := make([]Block, len(m.C3))
+	for i := range m.C3 {
+		blocks[i] = xorBlocks(m.C3[i], keys[i])
+	}
+	message, err := unpad(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	var expectedC1 bls12381.G2Affine
+	_, _, _, g2 := bls12381.Generators()
+	expectedC1.ScalarMultiplication(&g2, computeR(sigma, message))
+	if !expectedC1.Equal(m.C1) {
+		return nil, ErrInvalidC1
+	}
+	return message, nil
+}
+
+// Encrypt encrypts the message for the identity, sigma is the randomness of the encryption.
+func Encrypt(message []byte, eonPublicKey *EonPublicKey, identity *bls12381.G1Affine, sigma Block) (*EncryptedMessage, error) {
+	r := computeR(sigma, message)
+
+	_, _, _, g2 := bls12381.Generators()
+	c1 := new(bls12381.G2Affine).ScalarMultiplication(&g2, r)
+
+	p, err := bls12381.Pair([]bls12381.G1Affine{*identity}, []bls12381.G2Affine{bls12381.G2Affine(*eonPublicKey)})
+	if err != nil {
+		return nil, err
+	}
+	p.Exp(p, r)
+
+	blocks := pad(message)
+	keys := blockKeys(sigma, len(blocks))
+	c3 := make([]Block, len(blocks))
+	for i := range blocks {
+		c3[i] = xorBlocks(blocks[i], keys[i])
+	}
+	return &EncryptedMessage{C1: c1, C2: xorBlocks(sigma, hash2(&p)), C3: c3}, nil
+}
+
+// IdentityPoint hashes the identity preimage onto G1.
+func IdentityPoint(identityPreimage []byte) (*bls12381.G1Affine, error) {
+	p, err := bls12381.HashToG1(append([]byte{0x1}, identityPreimage...), identityDST)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func computeR(sigma Block, message []byte) *big.Int {
+	messageHash := hash4(message)
+	return hash3(append(sigma[:], messageHash[:]...))
+}
+
+func blockKeys(sigma Block, n int) []Block {
+	keys := make([]Block, n)
+	for i := range keys {
+		keys[i] = hash4(append(sigma[:], big.NewInt(int64(i)).Bytes()...))
+	}
+	return keys
+}
+
+// hash2 derives the key masking sigma from the pairing, its bytes are in the order of the coefficients of blst.
+func hash2(gt *bls12381.GT) Block {
+	buf := make([]byte, 0, 1+bls12381.SizeOfGT)
+	buf = append(buf, 0x2)
+	for _, e2 := range []struct{ a0, a1 [48]byte }{
+		{gt.C0.B0.A0.Bytes(), gt.C0.B0.A1.Bytes()},
+		{gt.C1.B0.A0.Bytes(), gt.C1.B0.A1.Bytes()},
+		{gt.C0.B1.A0.Bytes(), gt.C0.B1.A1.Bytes()},
+		{gt.C1.B1.A0.Bytes(), gt.C1.B1.A1.Bytes()},
+		{gt.C0.B2.A0.Bytes(), gt.C0.B2.A1.Bytes()},
+		{gt.C1.B2.A0.Bytes(), gt.C1.B2.A1.Bytes()},
+	} {
+		buf = append(append(buf, e2.a0[:]...), e2.a1[:]...)
+	}
+	return keccak(buf)
+}
+
+func hash3(b []byte) *big.Int {
+	h := keccak(append([]byte{0x3}, b...))
+	return new(big.Int).Mod(new(big.Int).SetBytes(h[:]), fr.Modulus())
+}
+
+func hash4(b []byte) Block {
+	return keccak(append([]byte{0x4}, b...))
+}
+
+func keccak(b []byte) (h Block) {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(b)
+	hasher.Sum(h[:0])
+	return h
+}
+
+func xorBlocks(a, b Block) (c Block) {
+	for i := range c {
+		c[i] = a[i] ^ b[i]
+	}
+	return c
+}
+
+// pad pads the message to whole blocks (PKCS #7), a whole block of padding is added to the messages of whole blocks.
+func pad(message []byte) []Block {
+	n := BlockSize - len(message)%BlockSize
+	padded := append(append([]byte{}, message...), bytes.Repeat([]byte{byte(n)}, n)...)
+	blocks := make([]Block, len(padded)/BlockSize)
+	for i := range blocks {
+		copy(blocks[i][:], padded[i*BlockSize:])
+	}
+	return blocks
+}
+
+func unpad(blocks []Block) ([]byte, error) {
+	if len(blocks) == 0 {
+		return nil, ErrInvalidPadding
+	}
+	message := make([]byte, 0, len(blocks)*BlockSize)
+	for _, block := range blocks {
+		message = append(message, block[:]...)
+	}
+	n := int(message[len(message)-1])
+	if n == 0 || n > BlockSize {
+		return nil, ErrInvalidPadding
+	}
+	for _, b := range message[len(message)-n:] {
+		if int(b) != n {
+			return nil, ErrInvalidPadding
+		}
+	}
+	return message[:len(message)-n], nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package crypto
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/require"
+)
+
+func eonKeys(t *testing.T, secret int64, identityPreimage []byte) (*EonPublicKey, *EpochSecretKey) {
+	_, _, _, g2 := bls12381.Generators()
+	eonPublicKey := new(bls12381.G2Affine).ScalarMultiplication(&g2, big.NewInt(secret))
+
+	identity, err := IdentityPoint(identityPreimage)
+	require.NoError(t, err)
+	epochSecretKey := new(bls12381.G1Affine).ScalarMultiplication(identity, big.NewInt(secret))
+
+	return (*EonPublicKey)(eonPublicKey), (*EpochSecretKey)(epochSecretKey)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	identityPreimage := bytes.Repeat([]byte{0x11}, 52)
+	eonPublicKey, epochSecretKey := eonKeys(t, 123456789, identityPreimage)
+	identity, err := IdentityPoint(identityPreimage)
+	require.NoError(t, err)
+
+	for _, message := range [][]byte{{}, []byte("shutter"), bytes.Repeat([]byte{0xaa}, BlockSize), bytes.Repeat([]byte{0xbb}, 100)} {
+		var sigma Block
+		copy(sigma[:], "sigma of the encryption")
+
+		encrypted, err := Encrypt(message, eonPublicKey, identity, sigma)
+		require.NoError(t, err)
+
+		var decoded EncryptedMessage
+		require.NoError(t, decoded.Unmarshal(encrypted.Marshal()))
+		require.True(t, decoded.C1.Equal(encrypted.C1))
+
+		decrypted, err := decoded.Decrypt(epochSecretKey)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+
+		// the key of another identity
+		_, otherKey := eonKeys(t, 123456789, bytes.Repeat([]byte{0x22}, 52))
+		_, err = decoded.Decrypt(otherKey)
+		require.Error(t, err)
+	}
+}
+
+func TestEpochSecretKeyUnmarshal(t *testing.T) {
+	_, epochSecretKey := eonKeys(t, 42, []byte("identity"))
+	encoded := (*bls12381.G1Affine)(epochSecretKey).Bytes()
+
+	var decoded EpochSecretKey
+	require.NoError(t, decoded.Unmarshal(encoded[:]))
+	require.True(t, (*bls12381.G1Affine)(&decoded).Equal((*bls12381.G1Affine)(epochSecretKey)))
+
+	require.Error(t, decoded.Unmarshal(encoded[1:]))
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	var m EncryptedMessage
+	require.ErrorIs(t, m.Unmarshal(make([]byte, 96+BlockSize)), ErrInvalidEncryptedMessage)
+	require.ErrorIs(t, m.Unmarshal(append([]byte{0x07}, make([]byte, 96+2*BlockSize)...)), ErrInvalidEncryptedMessage)
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package shutter
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+// keysRetainedSlots is the number of the latest slots whose decryption keys are kept
+const keysRetainedSlots = 64
+
+// KeyListener collects the decryption keys released by the keypers for the slots, the first release of the keys of
+// a slot wins.
+type KeyListener struct {
+	logger log.Logger
+	config Config
+	source DecryptionKeysSource
+
+	mu      sync.Mutex
+	keys    map[uint64]*DecryptionKeys // slot -> keys
+	waiters map[uint64]chan struct{}   // slot -> closed when its keys arrive
+}
+
+func NewKeyListener(logger log.Logger, config Config, source DecryptionKeysSource) *KeyListener {
+	return &KeyListener{
+		logger:  logger,
+		config:  config,
+		source:  source,
+		keys:    make(map[uint64]*DecryptionKeys),
+		waiters: make(map[uint64]chan struct{}),
+	}
+}
+
+// Run listens to the source of the keys until ctx is done.
+func (kl *KeyListener) Run(ctx context.Context) error {
+	keysCh := make(chan *DecryptionKeys, 16)
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error { return kl.source.Run(ctx, keysCh) })
+	eg.Go(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case keys := <-keysCh:
+				kl.add(keys)
+			}
+		}
+	})
+	return eg.Wait()
+}
+
+func (kl *KeyListener) add(keys *DecryptionKeys) {
+	if keys.InstanceId != kl.config.InstanceId {
+		kl.logger.Debug("decryption keys of another instance", "instanceId", keys.InstanceId, "slot", keys.Slot)
+		return
+	}
+
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	if _, ok := kl.keys[keys.Slot]; ok {
+		return
+	}
+	kl.keys[keys.Slot] = keys
+	if waiter, ok := kl.waiters[keys.Slot]; ok {
+		close(waiter)
+		delete(kl.waiters, keys.Slot)
+	}
+	kl.logger.Debug("decryption keys received", "slot", keys.Slot, "eon", keys.Eon, "txnPointer", keys.TxnPointer, "keys", len(keys.Keys))
+
+	if keys.Slot < keysRetainedSlots {
+		return
+	}
+	for slot := range kl.keys {
+		if slot < keys.Slot-keysRetainedSlots {
+			delete(kl.keys, slot)
+		}
+	}
+	for slot, waiter := range kl.waiters {
+		if slot < keys.Slot-keysRetainedSlots {
+			close(waiter)
+			delete(kl.waiters, slot)
+		}
+	}
+}
+
+// Keys returns the decryption keys of the slot, if they have been released.
+func (kl *KeyListener) Keys(slot uint64) (*DecryptionKeys, bool) {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	keys, ok := kl.keys[slot]
+	return keys, ok
+}
+
+// Wait returns the decryption keys of the slot, waiting for their release until ctx is done. ErrNoDecryptionKeys is
+// returned if the slot falls out of the retained ones before its keys are released.
+func (kl *KeyListener) Wait(ctx context.Context, slot uint64) (*DecryptionKeys, error) {
+	kl.mu.Lock()
+	if keys, ok := kl.keys[slot]; ok {
+		kl.mu.Unlock()
+		return keys, nil
+	}
+	waiter, ok := kl.waiters[slot]
+	if !ok {
+		waiter = make(chan struct{})
+		kl.waiters[slot] = waiter
+	}
+	kl.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-waiter:
+		keys, ok := kl.Keys(slot)
+		if !ok {
+			return nil, ErrNoDecryptionKeys
+		}
+		return keys, nil
+	}
+}
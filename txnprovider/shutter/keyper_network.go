@@ -0,0 +1,139 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package shutter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+// DecryptionKeysTopic is the gossipsub topic the keypers release the decryption keys on.
+const DecryptionKeysTopic = "decryptionKeys"
+
+const (
+	defaultP2pListenAddr = "/ip4/0.0.0.0/tcp/0"
+
+	// bootnodesReconnectInterval is how often the lost connections to the keyper bootnodes are re-established
+	bootnodesReconnectInterval = time.Minute
+)
+
+var _ DecryptionKeysSource = (*KeyperNetworkSource)(nil)
+
+// KeyperNetworkSource receives the decryption keys gossiped on the keyper p2p network, joined through the keyper
+// bootnodes of the config. The messages are not checked against the keyper set of the eon (the signatures of the
+// gnosis extra), a forged release fails at the decryption of the transactions with its keys.
+type KeyperNetworkSource struct {
+	logger log.Logger
+	config Config
+}
+
+func NewKeyperNetworkSource(logger log.Logger, config Config) *KeyperNetworkSource {
+	return &KeyperNetworkSource{logger: logger, config: config}
+}
+
+func (s *KeyperNetworkSource) Run(ctx context.Context, keys chan<- *DecryptionKeys) error {
+	listenAddr := s.config.P2pListenAddr
+	if listenAddr == "" {
+		listenAddr = defaultP2pListenAddr
+	}
+	p2pHost, err := libp2p.New(libp2p.ListenAddrStrings(listenAddr), libp2p.UserAgent("erigon/shutter"), libp2p.Ping(false))
+	if err != nil {
+		return fmt.Errorf("creating the keyper network host: %w", err)
+	}
+	defer p2pHost.Close()
+
+	bootnodes := make([]peer.AddrInfo, 0, len(s.config.KeyperBootnodes))
+	for _, bootnode := range s.config.KeyperBootnodes {
+		addrInfo, err := peer.AddrInfoFromString(bootnode)
+		if err != nil {
+			return fmt.Errorf("invalid keyper bootnode %s: %w", bootnode, err)
+		}
+		bootnodes = append(bootnodes, *addrInfo)
+	}
+
+	gossip, err := pubsub.NewGossipSub(ctx, p2pHost)
+	if err != nil {
+		return fmt.Errorf("creating the keyper network gossip: %w", err)
+	}
+	topic, err := gossip.Join(DecryptionKeysTopic)
+	if err != nil {
+		return err
+	}
+	defer topic.Close()
+	sub, err := topic.Subscribe()
+	if err != nil {
+		return err
+	}
+	defer sub.Cancel()
+
+	s.logger.Info("listening to the keyper network", "peerId", p2pHost.ID(), "addrs", p2pHost.Addrs(), "bootnodes", len(bootnodes))
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		s.connectBootnodes(ctx, p2pHost, bootnodes)
+		return nil
+	})
+	eg.Go(func() error {
+		for {
+			msg, err := sub.Next(ctx)
+			if err != nil {
+				return err
+			}
+			decryptionKeys, err := decodeDecryptionKeysEnvelope(msg.Data)
+			if err != nil {
+				s.logger.Debug("invalid decryption keys message", "from", msg.ReceivedFrom, "err", err)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case keys <- decryptionKeys:
+			}
+		}
+	})
+	return eg.Wait()
+}
+
+// connectBootnodes keeps the host connected to the keyper bootnodes until ctx is done.
+func (s *KeyperNetworkSource) connectBootnodes(ctx context.Context, p2pHost host.Host, bootnodes []peer.AddrInfo) {
+	ticker := time.NewTicker(bootnodesReconnectInterval)
+	defer ticker.Stop()
+	for {
+		for _, bootnode := range bootnodes {
+			if len(p2pHost.Network().ConnsToPeer(bootnode.ID)) > 0 {
+				continue
+			}
+			if err := p2pHost.Connect(ctx, bootnode); err != nil {
+				s.logger.Debug("connecting to keyper bootnode failed", "peer", bootnode.ID, "err", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
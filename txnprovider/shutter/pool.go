@@ -18,43 +18,221 @@ package shutter
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
+	"golang.org/x/sync/errgroup"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/txnprovider"
 )
 
-var _ txnprovider.TxnProvider = (*Pool)(nil)
+var (
+	ErrNoDecryptionKeys   = errors.New("no decryption keys released for the slot")
+	ErrKeyReleaseMismatch = errors.New("block transactions don't match the decryption keys release")
+)
 
+var (
+	_ txnprovider.TxnProvider        = (*Pool)(nil)
+	_ txnprovider.BlockTxnsValidator = (*Pool)(nil)
+)
+
+// decrypterRunner is implemented by the decrypters following the chain, e.g. the submissions to the sequencer contract.
+type decrypterRunner interface {
+	Run(ctx context.Context) error
+}
+
+// Pool is a TxnProvider which puts the transactions decrypted with the keys released for the slot of the block first,
+// in the order of the keys and up to the encrypted gas limit, and then fills the remaining gas with the transactions of
+// the secondary txn provider (devp2p txpool). If no keys are released for the slot in time, no transactions are
+// provided. Without a source of the decryption keys or a decrypter the pool passes the secondary transactions through.
 type Pool struct {
 	logger               log.Logger
 	config               Config
 	secondaryTxnProvider txnprovider.TxnProvider
+	keyListener          *KeyListener
+	decrypter            TxnDecrypter
+
+	mu        sync.Mutex
+	decrypted map[uint64][]types.Transaction // slot -> decrypted txns, in the order of the keys
 }
 
-func NewPool(logger log.Logger, config Config, secondaryTxnProvider txnprovider.TxnProvider) *Pool {
+func NewPool(
+	logger log.Logger,
+	config Config,
+	secondaryTxnProvider txnprovider.TxnProvider,
+	keysSource DecryptionKeysSource,
+	decrypter TxnDecrypter,
+) *Pool {
+	logger = logger.New("component", "shutter")
+	var keyListener *KeyListener
+	if keysSource != nil && decrypter != nil {
+		keyListener = NewKeyListener(logger, config, keysSource)
+	}
 	return &Pool{
-		logger:               logger.New("component", "shutter"),
+		logger:               logger,
 		config:               config,
 		secondaryTxnProvider: secondaryTxnProvider,
+		keyListener:          keyListener,
+		decrypter:            decrypter,
+		decrypted:            make(map[uint64][]types.Transaction),
 	}
 }
 
-func (p Pool) Run(ctx context.Context) error {
+func (p *Pool) Run(ctx context.Context) error {
+	if p.keyListener == nil {
+		p.logger.Warn("no decryption keys source or decrypter, encrypted transactions are not included")
+		return nil
+	}
 	p.logger.Info("running pool")
-	//
-	// TODO - start pool, sentinel listeners for keyper decryption keys and other necessary background goroutines
-	//        blocks until all sub-components have shutdown or have error-ed
-	//
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error { return p.keyListener.Run(ctx) })
+	if runner, ok := p.decrypter.(decrypterRunner); ok {
+		eg.Go(func() error { return runner.Run(ctx) })
+	}
+	return eg.Wait()
+}
+
+func (p *Pool) ProvideTxns(ctx context.Context, opts ...txnprovider.ProvideOption) ([]types.Transaction, error) {
+	provideOpts := txnprovider.ApplyProvideOptions(opts...)
+	if p.keyListener == nil || provideOpts.BlockTime == 0 {
+		return p.secondaryTxnProvider.ProvideTxns(ctx, opts...)
+	}
+
+	slot := p.config.SlotAt(provideOpts.BlockTime)
+	decrypted, err := p.decryptedTxns(ctx, slot, true)
+	if errors.Is(err, ErrNoDecryptionKeys) {
+		p.logger.Warn("no decryption keys for the slot, providing no transactions", "slot", slot)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gasTarget, blobGasTarget, amount := provideOpts.GasTarget, provideOpts.BlobGasTarget, provideOpts.Amount
+	encryptedGasTarget := min(p.config.EncryptedGasLimit, gasTarget)
+	var txns []types.Transaction
+	for _, txn := range decrypted {
+		if amount == 0 {
+			break
+		}
+		if provideOpts.TxnIdsFilter.Contains(txn.Hash()) {
+			// a previous call for the same block has already provided it, so has it the gas
+			encryptedGasTarget -= min(txn.GetGas(), encryptedGasTarget)
+			continue
+		}
+		if txn.GetGas() > encryptedGasTarget || txn.GetBlobGas() > blobGasTarget {
+			continue
+		}
+		provideOpts.TxnIdsFilter.Add(txn.Hash())
+		txns = append(txns, txn)
+		encryptedGasTarget -= txn.GetGas()
+		gasTarget -= txn.GetGas()
+		blobGasTarget -= txn.GetBlobGas()
+		amount--
+	}
+	if amount == 0 {
+		return txns, nil
+	}
+
+	remaining, err := p.secondaryTxnProvider.ProvideTxns(
+		ctx,
+		txnprovider.WithParentBlockNum(provideOpts.ParentBlockNum),
+		txnprovider.WithBlockTime(provideOpts.BlockTime),
+		txnprovider.WithAmount(amount),
+		txnprovider.WithGasTarget(gasTarget),
+		txnprovider.WithBlobGasTarget(blobGasTarget),
+		txnprovider.WithTxnIdsFilter(provideOpts.TxnIdsFilter),
+		txnprovider.WithBaseFee(provideOpts.BaseFee),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return append(txns, remaining...), nil
+}
+
+// ValidateBlockTxns checks that the decrypted transactions included in the block with the given timestamp are the
+// ones of the decryption keys release for its slot, at the top of the block and in the order of the keys. Decrypted
+// transactions may be left out, e.g. when they are invalid. The blocks of the slots without known keys aren't checked.
+func (p *Pool) ValidateBlockTxns(ctx context.Context, blockTime uint64, txns types.Transactions) error {
+	if p.keyListener == nil {
+		return nil
+	}
+	slot := p.config.SlotAt(blockTime)
+	decrypted, err := p.decryptedTxns(ctx, slot, false)
+	if errors.Is(err, ErrNoDecryptionKeys) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	order := make(map[libcommon.Hash]int, len(decrypted))
+	for i, txn := range decrypted {
+		order[txn.Hash()] = i
+	}
+	next, top := 0, true
+	for i, txn := range txns {
+		idx, ok := order[txn.Hash()]
+		if !ok {
+			top = false
+			continue
+		}
+		if !top {
+			return fmt.Errorf("%w: decrypted transaction %x at %d follows a regular one", ErrKeyReleaseMismatch, txn.Hash(), i)
+		}
+		if idx < next {
+			return fmt.Errorf("%w: decrypted transaction %x at %d is out of the order of the keys", ErrKeyReleaseMismatch, txn.Hash(), i)
+		}
+		next = idx + 1
+	}
 	return nil
 }
 
-func (p Pool) ProvideTxns(ctx context.Context, opts ...txnprovider.ProvideOption) ([]types.Transaction, error) {
-	//
-	// TODO - implement shutter spec
-	//        1) fetch corresponding txns for current slot and fill the remaining gas
-	//           with the secondary txn provider (devp2p)
-	//        2) if no decryption keys arrive for current slot then return empty transactions
-	//
-	return p.secondaryTxnProvider.ProvideTxns(ctx, opts...)
+// decryptedTxns returns the transactions decrypted with the keys of the slot. If wait is set, the keys are waited for
+// until MaxDecryptionKeysDelay into the slot, otherwise ErrNoDecryptionKeys is returned if they are not known yet.
+func (p *Pool) decryptedTxns(ctx context.Context, slot uint64, wait bool) ([]types.Transaction, error) {
+	p.mu.Lock()
+	decrypted, ok := p.decrypted[slot]
+	p.mu.Unlock()
+	if ok {
+		return decrypted, nil
+	}
+
+	var keys *DecryptionKeys
+	if wait {
+		waitCtx, cancel := context.WithDeadline(ctx, p.config.SlotStart(slot).Add(p.config.MaxDecryptionKeysDelay))
+		defer cancel()
+		var err error
+		keys, err = p.keyListener.Wait(waitCtx, slot)
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, ErrNoDecryptionKeys
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if keys, ok = p.keyListener.Keys(slot); !ok {
+		return nil, ErrNoDecryptionKeys
+	}
+
+	start := time.Now()
+	decrypted, err := p.decrypter.DecryptTxns(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting the transactions of slot %d: %w", slot, err)
+	}
+	p.logger.Debug("decrypted transactions", "slot", slot, "keys", len(keys.Keys), "txns", len(decrypted), "took", time.Since(start))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.decrypted[slot] = decrypted
+	for s := range p.decrypted {
+		if s+keysRetainedSlots < slot {
+			delete(p.decrypted, s)
+		}
+	}
+	return decrypted, nil
 }
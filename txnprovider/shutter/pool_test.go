@@ -0,0 +1,137 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package shutter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/txnprovider"
+)
+
+type recordingTxnProvider struct {
+	txns []types.Transaction
+	opts txnprovider.ProvideOptions
+}
+
+func (r *recordingTxnProvider) ProvideTxns(_ context.Context, opts ...txnprovider.ProvideOption) ([]types.Transaction, error) {
+	r.opts = txnprovider.ApplyProvideOptions(opts...)
+	return r.txns, nil
+}
+
+type chanKeysSource chan *DecryptionKeys
+
+func (s chanKeysSource) Run(ctx context.Context, keys chan<- *DecryptionKeys) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case k := <-s:
+			keys <- k
+		}
+	}
+}
+
+type testDecrypter map[uint64][]types.Transaction
+
+func (d testDecrypter) DecryptTxns(_ context.Context, keys *DecryptionKeys) ([]types.Transaction, error) {
+	return d[keys.Slot], nil
+}
+
+func newTestTxn(nonce, gas uint64) types.Transaction {
+	txn := types.NewTransaction(nonce, libcommon.Address{1}, uint256.NewInt(0), gas, uint256.NewInt(1), nil)
+	txn.SetSender(libcommon.Address{2})
+	return txn
+}
+
+func newTestPool(secondary txnprovider.TxnProvider, decrypter testDecrypter) (*Pool, chanKeysSource) {
+	config := Config{
+		InstanceId:                  1,
+		BeaconChainGenesisTimestamp: uint64(time.Now().Unix()) - 1000,
+		SecondsPerSlot:              5,
+		EncryptedGasLimit:           50_000,
+		MaxDecryptionKeysDelay:      time.Minute,
+	}
+	source := make(chanKeysSource)
+	return NewPool(log.New(), config, secondary, source, decrypter), source
+}
+
+func TestProvideTxnsDecryptedFirst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockTime := uint64(time.Now().Unix())
+	secondaryTxn := newTestTxn(100, 21_000)
+	secondary := &recordingTxnProvider{txns: []types.Transaction{secondaryTxn}}
+	decrypted := []types.Transaction{newTestTxn(0, 21_000), newTestTxn(1, 40_000), newTestTxn(2, 21_000)}
+	decrypter := testDecrypter{}
+	pool, source := newTestPool(secondary, decrypter)
+	slot := pool.config.SlotAt(blockTime)
+	decrypter[slot] = decrypted
+	go pool.Run(ctx) //nolint:errcheck
+
+	// keys of another instance are ignored, the block building waits for the ones of the slot
+	source <- &DecryptionKeys{InstanceId: 2, Slot: slot}
+	source <- &DecryptionKeys{InstanceId: 1, Slot: slot, Keys: make([]DecryptionKey, len(decrypted))}
+	yielded := mapset.NewSet[[32]byte]()
+	txns, err := pool.ProvideTxns(
+		ctx,
+		txnprovider.WithBlockTime(blockTime),
+		txnprovider.WithGasTarget(100_000),
+		txnprovider.WithTxnIdsFilter(yielded),
+	)
+	require.NoError(t, err)
+	// in the order of the keys, the one exceeding the encrypted gas limit is skipped
+	require.Equal(t, []types.Transaction{decrypted[0], decrypted[2], secondaryTxn}, txns)
+	require.Equal(t, uint64(100_000-42_000), secondary.opts.GasTarget)
+	require.Equal(t, blockTime, secondary.opts.BlockTime)
+	require.Equal(t, 2, yielded.Cardinality())
+
+	require.NoError(t, pool.ValidateBlockTxns(ctx, blockTime, txns))
+	require.NoError(t, pool.ValidateBlockTxns(ctx, blockTime, types.Transactions{decrypted[1], secondaryTxn}))
+	require.ErrorIs(t, pool.ValidateBlockTxns(ctx, blockTime, types.Transactions{decrypted[2], decrypted[0]}), ErrKeyReleaseMismatch)
+	require.ErrorIs(t, pool.ValidateBlockTxns(ctx, blockTime, types.Transactions{secondaryTxn, decrypted[0]}), ErrKeyReleaseMismatch)
+	// the slots without known keys aren't checked
+	require.NoError(t, pool.ValidateBlockTxns(ctx, blockTime-100, types.Transactions{secondaryTxn, decrypted[0]}))
+}
+
+func TestProvideTxnsWithoutKeys(t *testing.T) {
+	secondary := &recordingTxnProvider{txns: []types.Transaction{newTestTxn(100, 21_000)}}
+	pool, _ := newTestPool(secondary, testDecrypter{})
+
+	// the keys of a slot long started aren't waited for, no transactions are provided without them
+	txns, err := pool.ProvideTxns(context.Background(), txnprovider.WithBlockTime(pool.config.BeaconChainGenesisTimestamp+5))
+	require.NoError(t, err)
+	require.Empty(t, txns)
+
+	// without the block time, or without a source of the keys, the secondary transactions are passed through
+	txns, err = pool.ProvideTxns(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, secondary.txns, txns)
+	pool = NewPool(log.New(), pool.config, secondary, nil, nil)
+	txns, err = pool.ProvideTxns(context.Background(), txnprovider.WithBlockTime(pool.config.BeaconChainGenesisTimestamp+5))
+	require.NoError(t, err)
+	require.Equal(t, secondary.txns, txns)
+}
@@ -0,0 +1,241 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package shutter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/accounts/abi"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/filters"
+	"github.com/erigontech/erigon/txnprovider/shutter/internal/crypto"
+)
+
+const sequencerABIJSON = `[{"anonymous":false,"inputs":[` +
+	`{"indexed":false,"internalType":"uint64","name":"eon","type":"uint64"},` +
+	`{"indexed":false,"internalType":"uint64","name":"txIndex","type":"uint64"},` +
+	`{"indexed":false,"internalType":"bytes32","name":"identityPrefix","type":"bytes32"},` +
+	`{"indexed":false,"internalType":"address","name":"sender","type":"address"},` +
+	`{"indexed":false,"internalType":"bytes","name":"encryptedTransaction","type":"bytes"},` +
+	`{"indexed":false,"internalType":"uint256","name":"gasLimit","type":"uint256"}` +
+	`],"name":"TransactionSubmitted","type":"event"}]`
+
+// submissionsRetainedBlocks is the number of the latest blocks whose sequencer contract submissions are kept
+const submissionsRetainedBlocks = 8192
+
+var transactionSubmittedEvent = func() abi.Event {
+	sequencerABI, err := abi.JSON(strings.NewReader(sequencerABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return sequencerABI.Events["TransactionSubmitted"]
+}()
+
+// LogsReader reads the logs of the chain, e.g. the eth API of the node.
+type LogsReader interface {
+	BlockNumber(ctx context.Context) (hexutil.Uint64, error)
+	GetLogs(ctx context.Context, crit filters.FilterCriteria) (types.Logs, error)
+}
+
+// submission is an encrypted transaction submitted to the sequencer contract.
+type submission struct {
+	blockNum             uint64
+	identityPreimage     []byte // identity prefix | sender
+	encryptedTransaction []byte
+	gasLimit             *big.Int
+}
+
+var _ TxnDecrypter = (*SequencerDecrypter)(nil)
+
+// SequencerDecrypter decrypts the transactions submitted to the sequencer contract. It follows the
+// TransactionSubmitted events of the contract and decrypts the submissions of the eon from the txn pointer of the
+// released keys on, each with the key of its identity, until a submission without a key.
+type SequencerDecrypter struct {
+	logger       log.Logger
+	config       Config
+	logs         LogsReader
+	signer       *types.Signer
+	contractAddr libcommon.Address
+
+	mu          sync.Mutex
+	submissions map[uint64]map[uint64]*submission // eon -> txn index -> submission
+	syncedTo    uint64                            // last block whose events are in submissions
+}
+
+func NewSequencerDecrypter(logger log.Logger, config Config, chainConfig *chain.Config, logs LogsReader) *SequencerDecrypter {
+	return &SequencerDecrypter{
+		logger:       logger,
+		config:       config,
+		logs:         logs,
+		signer:       types.LatestSignerForChainID(chainConfig.ChainID),
+		contractAddr: libcommon.HexToAddress(config.SequencerContractAddress),
+		submissions:  make(map[uint64]map[uint64]*submission),
+	}
+}
+
+// Run follows the submissions to the sequencer contract until ctx is done.
+func (d *SequencerDecrypter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(d.config.SecondsPerSlot) * time.Second)
+	defer ticker.Stop()
+	for {
+		if err := d.sync(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Warn("reading the sequencer contract submissions failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *SequencerDecrypter) DecryptTxns(ctx context.Context, keys *DecryptionKeys) ([]types.Transaction, error) {
+	// the keys are released at the start of the slot, the latest block may have submissions not synced yet
+	if err := d.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	keysByIdentity := make(map[string][]byte, len(keys.Keys))
+	for _, key := range keys.Keys {
+		keysByIdentity[string(key.IdentityPreimage)] = key.Key
+	}
+
+	var submissions []*submission
+	d.mu.Lock()
+	for txnIndex := keys.TxnPointer; txnIndex < keys.TxnPointer+uint64(len(keys.Keys)); txnIndex++ {
+		sub, ok := d.submissions[keys.Eon][txnIndex]
+		if !ok {
+			break
+		}
+		submissions = append(submissions, sub)
+	}
+	d.mu.Unlock()
+
+	var txns []types.Transaction
+	for i, sub := range submissions {
+		key, ok := keysByIdentity[string(sub.identityPreimage)]
+		if !ok {
+			break
+		}
+		txn, err := d.decrypt(sub, key)
+		if err != nil {
+			d.logger.Debug("leaving out the submission", "eon", keys.Eon, "txnIndex", keys.TxnPointer+uint64(i), "err", err)
+			continue
+		}
+		txns = append(txns, txn)
+	}
+	return txns, nil
+}
+
+func (d *SequencerDecrypter) decrypt(sub *submission, key []byte) (types.Transaction, error) {
+	var epochSecretKey crypto.EpochSecretKey
+	if err := epochSecretKey.Unmarshal(key); err != nil {
+		return nil, err
+	}
+	var encrypted crypto.EncryptedMessage
+	if err := encrypted.Unmarshal(sub.encryptedTransaction); err != nil {
+		return nil, err
+	}
+	decrypted, err := encrypted.Decrypt(&epochSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	txn, err := types.DecodeTransaction(decrypted)
+	if err != nil {
+		return nil, err
+	}
+	if new(big.Int).SetUint64(txn.GetGas()).Cmp(sub.gasLimit) > 0 {
+		return nil, fmt.Errorf("gas %d over the submitted limit %d", txn.GetGas(), sub.gasLimit)
+	}
+	sender, err := d.signer.Sender(txn)
+	if err != nil {
+		return nil, err
+	}
+	txn.SetSender(sender)
+	return txn, nil
+}
+
+// sync reads the submissions of the blocks after the last synced one.
+func (d *SequencerDecrypter) sync(ctx context.Context) error {
+	latest, err := d.logs.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	to := uint64(latest)
+	if to <= d.syncedTo {
+		return nil
+	}
+	from := d.syncedTo + 1
+	if to > submissionsRetainedBlocks && from < to-submissionsRetainedBlocks {
+		from = to - submissionsRetainedBlocks
+	}
+
+	logs, err := d.logs.GetLogs(ctx, filters.FilterCriteria{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []libcommon.Address{d.contractAddr},
+		Topics:    [][]libcommon.Hash{{transactionSubmittedEvent.ID}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, l := range logs {
+		values, err := transactionSubmittedEvent.Inputs.Unpack(l.Data)
+		if err != nil {
+			d.logger.Debug("invalid TransactionSubmitted event", "block", l.BlockNumber, "err", err)
+			continue
+		}
+		eon, txnIndex := values[0].(uint64), values[1].(uint64)
+		identityPrefix, sender := values[2].([32]byte), values[3].(libcommon.Address)
+		if _, ok := d.submissions[eon]; !ok {
+			d.submissions[eon] = make(map[uint64]*submission)
+		}
+		d.submissions[eon][txnIndex] = &submission{
+			blockNum:             l.BlockNumber,
+			identityPreimage:     append(identityPrefix[:], sender[:]...),
+			encryptedTransaction: values[4].([]byte),
+			gasLimit:             values[5].(*big.Int),
+		}
+	}
+	d.syncedTo = to
+
+	for eon, submissions := range d.submissions {
+		for txnIndex, sub := range submissions {
+			if sub.blockNum+submissionsRetainedBlocks < to {
+				delete(submissions, txnIndex)
+			}
+		}
+		if len(submissions) == 0 {
+			delete(d.submissions, eon)
+		}
+	}
+	return nil
+}
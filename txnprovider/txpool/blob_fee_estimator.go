@@ -0,0 +1,95 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon/consensus/misc"
+)
+
+// BlobFeeDemand is the blob gas an executable blob txn of the pool wants included, and the most it pays for it.
+type BlobFeeDemand struct {
+	BlobGas uint64
+	FeeCap  uint256.Int
+}
+
+type BlobFeeEstimate struct {
+	BlobBaseFee       uint256.Int // of the next block
+	MaxFeePerBlobGas  uint256.Int // enough to be includable in any block of the horizon
+	PeakExcessBlobGas uint64      // highest projected excess blob gas of the horizon
+}
+
+// EstimateBlobFee recommends a maxFeePerBlobGas for a blob txn to stay includable for the next `blocks` blocks.
+// The excess blob gas is projected forward from the one of the next block: every block takes the pool's blobs
+// that can pay its blob base fee, best paying first and up to the max blob gas per block, but no less than
+// recentBlobGasUsed (the trend of the recent blocks, for the demand the pool doesn't see). The recommendation
+// is the blob base fee at the peak of the projection.
+func EstimateBlobFee(cfg *chain.Config, excessBlobGas, recentBlobGasUsed uint64, pool []BlobFeeDemand, blocks int) (BlobFeeEstimate, error) {
+	var est BlobFeeEstimate
+	blobBaseFee, err := misc.GetBlobGasPrice(cfg, excessBlobGas)
+	if err != nil {
+		return est, err
+	}
+	est.BlobBaseFee.Set(blobBaseFee)
+
+	maxBlobGas, targetBlobGas := cfg.GetMaxBlobGasPerBlock(), cfg.GetTargetBlobGasPerBlock()
+	sort.SliceStable(pool, func(i, j int) bool { return pool[i].FeeCap.Gt(&pool[j].FeeCap) })
+
+	fee := blobBaseFee
+	for i := 0; i < blocks; i++ {
+		if i > 0 {
+			if fee, err = misc.GetBlobGasPrice(cfg, excessBlobGas); err != nil {
+				return est, err
+			}
+		}
+		est.PeakExcessBlobGas = max(est.PeakExcessBlobGas, excessBlobGas)
+
+		// a txn which doesn't fit the rest of the block waits for the next one, smaller ones may still fit
+		var used uint64
+		waiting := pool[:0]
+		for j := range pool {
+			if pool[j].FeeCap.Lt(fee) {
+				waiting = append(waiting, pool[j:]...)
+				break
+			}
+			if used+pool[j].BlobGas > maxBlobGas {
+				waiting = append(waiting, pool[j])
+				continue
+			}
+			used += pool[j].BlobGas
+		}
+		pool = waiting
+		used = max(used, min(recentBlobGasUsed, maxBlobGas))
+
+		if excessBlobGas+used < targetBlobGas {
+			excessBlobGas = 0
+		} else {
+			excessBlobGas = excessBlobGas + used - targetBlobGas
+		}
+	}
+
+	maxFee, err := misc.GetBlobGasPrice(cfg, est.PeakExcessBlobGas)
+	if err != nil {
+		return est, err
+	}
+	est.MaxFeePerBlobGas.Set(maxFee)
+	return est, nil
+}
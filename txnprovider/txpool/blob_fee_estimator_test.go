@@ -0,0 +1,83 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common/fixedgas"
+	"github.com/erigontech/erigon/consensus/misc"
+)
+
+func TestEstimateBlobFee(t *testing.T) {
+	cfg := &chain.Config{}
+	target, maxBlobGas := cfg.GetTargetBlobGasPerBlock(), cfg.GetMaxBlobGasPerBlock()
+	price := func(excess uint64) uint256.Int {
+		p, err := misc.GetBlobGasPrice(cfg, excess)
+		require.NoError(t, err)
+		return *p
+	}
+	demand := func(blobs int, feeCap uint64) BlobFeeDemand {
+		return BlobFeeDemand{BlobGas: uint64(blobs) * fixedgas.BlobGasPerBlob, FeeCap: *uint256.NewInt(feeCap)}
+	}
+
+	t.Run("idle", func(t *testing.T) {
+		est, err := EstimateBlobFee(cfg, 0, 0, nil, 6)
+		require.NoError(t, err)
+		require.Equal(t, price(0), est.BlobBaseFee)
+		require.Equal(t, price(0), est.MaxFeePerBlobGas)
+		require.Zero(t, est.PeakExcessBlobGas)
+	})
+	t.Run("full blocks trend", func(t *testing.T) {
+		est, err := EstimateBlobFee(cfg, 0, maxBlobGas, nil, 6)
+		require.NoError(t, err)
+		// every block adds max-target to the excess, the last block of the horizon is the 6th
+		require.Equal(t, 5*(maxBlobGas-target), est.PeakExcessBlobGas)
+		require.Equal(t, price(0), est.BlobBaseFee)
+		require.Equal(t, price(5*(maxBlobGas-target)), est.MaxFeePerBlobGas)
+	})
+	t.Run("pool demand", func(t *testing.T) {
+		// two full blocks of blobs waiting: the excess rises for two blocks and then drains
+		pool := []BlobFeeDemand{demand(6, 1_000_000), demand(3, 1_000_000), demand(3, 1_000_000)}
+		est, err := EstimateBlobFee(cfg, 0, 0, pool, 6)
+		require.NoError(t, err)
+		require.Equal(t, 2*(maxBlobGas-target), est.PeakExcessBlobGas)
+		require.Equal(t, price(2*(maxBlobGas-target)), est.MaxFeePerBlobGas)
+	})
+	t.Run("pool demand not fitting", func(t *testing.T) {
+		// the 3 blobs txn doesn't fit after the 4 blobs one, the 2 blobs one paying less still fills the block
+		pool := []BlobFeeDemand{demand(4, 3_000_000), demand(3, 2_000_000), demand(2, 1_000_000)}
+		est, err := EstimateBlobFee(cfg, 0, 0, pool, 2)
+		require.NoError(t, err)
+		require.Equal(t, maxBlobGas-target, est.PeakExcessBlobGas)
+	})
+	t.Run("pool demand below the fee", func(t *testing.T) {
+		excess := 20 * target
+		fee := price(excess)
+		require.True(t, fee.GtUint64(1))
+		// blobs not paying the blob base fee don't get in, the excess only drains
+		est, err := EstimateBlobFee(cfg, excess, 0, []BlobFeeDemand{demand(6, 1), demand(6, 1)}, 6)
+		require.NoError(t, err)
+		require.Equal(t, excess, est.PeakExcessBlobGas)
+		require.Equal(t, price(excess), est.BlobBaseFee)
+		require.Equal(t, est.BlobBaseFee, est.MaxFeePerBlobGas)
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/holiman/uint256"
+	"golang.org/x/time/rate"
+)
+
+// broadcastBudget caps the upstream bandwidth spent on broadcasting the full bodies of the remote txns: every
+// peer a body is sent to counts. The txns past the budget are only announced, peers wanting them fetch them.
+type broadcastBudget struct {
+	limiter *rate.Limiter
+}
+
+// newBroadcastBudget returns nil (unlimited) for a 0 budget.
+func newBroadcastBudget(bytesPerSec datasize.ByteSize) *broadcastBudget {
+	if bytesPerSec == 0 {
+		return nil
+	}
+	return &broadcastBudget{limiter: rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))}
+}
+
+// take returns the prefix of rlps which fits into the budget when sent to maxPeers peers.
+func (b *broadcastBudget) take(rlps [][]byte, maxPeers uint64) [][]byte {
+	if b == nil {
+		return rlps
+	}
+	now := time.Now()
+	for i, rlp := range rlps {
+		if !b.limiter.AllowN(now, len(rlp)*int(maxPeers)) {
+			return rlps[:i]
+		}
+	}
+	return rlps
+}
+
+// broadcastInFull tells for every announced txn if it pays enough to be worth broadcasting its body: its effective
+// tip at the pending base fee is at least BroadcastMinTip. Otherwise it's only announced. Only matters for the remote
+// txns. The whole batch is decided under one lock.
+func (p *TxPool) broadcastInFull(announcements Announcements) []bool {
+	inFull := make([]bool, announcements.Len())
+	if p.cfg.BroadcastMinTip == 0 {
+		for i := range inFull {
+			inFull[i] = true
+		}
+		return inFull
+	}
+	pendingBaseFee := uint256.NewInt(p.pendingBaseFee.Load())
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for i := range inFull {
+		_, _, idHash := announcements.At(i)
+		if mt, ok := p.byHash[string(idHash)]; ok {
+			inFull[i] = paysBroadcastTip(mt, pendingBaseFee, p.cfg.BroadcastMinTip)
+		}
+	}
+	return inFull
+}
+
+func paysBroadcastTip(mt *metaTxn, pendingBaseFee *uint256.Int, minTip uint64) bool {
+	if mt.minFeeCap.Lt(pendingBaseFee) {
+		return false
+	}
+	effectiveTip := uint256.NewInt(mt.minTip)
+	if headroom := new(uint256.Int).Sub(&mt.minFeeCap, pendingBaseFee); headroom.Lt(effectiveTip) {
+		effectiveTip = headroom
+	}
+	return !effectiveTip.LtUint64(minTip)
+}
+
+// limitAnnouncements keeps the first limit announcements (0 - unlimited).
+func limitAnnouncements(types []byte, sizes []uint32, hashes Hashes, limit int) ([]byte, []uint32, Hashes) {
+	if limit <= 0 || len(types) <= limit {
+		return types, sizes, hashes
+	}
+	return types[:limit], sizes[:limit], hashes[:32*limit]
+}
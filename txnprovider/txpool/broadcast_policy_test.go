@@ -0,0 +1,83 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon/txnprovider/txpool/txpoolcfg"
+)
+
+func TestBroadcastBudget(t *testing.T) {
+	rlps := [][]byte{make([]byte, 100), make([]byte, 100), make([]byte, 100)}
+
+	unlimited := newBroadcastBudget(0)
+	require.Len(t, unlimited.take(rlps, 10), 3)
+
+	// 1000 bytes per second: two txns of 100 bytes to 4 peers fit, the third one is only announced
+	budget := newBroadcastBudget(1000 * datasize.B)
+	require.Len(t, budget.take(rlps, 4), 2)
+	require.Empty(t, budget.take(rlps, 4))
+}
+
+func TestBroadcastInFull(t *testing.T) {
+	cfg := txpoolcfg.DefaultConfig
+	cfg.BroadcastMinTip = 2
+	p := &TxPool{lock: &sync.Mutex{}, byHash: map[string]*metaTxn{}, cfg: cfg}
+	p.pendingBaseFee.Store(10)
+
+	var announcements Announcements
+	add := func(hash byte, feeCap, tip uint64) {
+		h := bytes.Repeat([]byte{hash}, 32)
+		mt := &metaTxn{TxnSlot: &TxnSlot{}, minTip: tip}
+		mt.minFeeCap = *uint256.NewInt(feeCap)
+		p.byHash[string(h)] = mt
+		announcements.Append(0, 100, h)
+	}
+	add(1, 100, 5)
+	add(2, 12, 5) // effective tip 2
+	add(3, 11, 5) // effective tip 1
+	add(4, 100, 1)
+	add(5, 9, 5) // below the base fee
+	announcements.Append(0, 100, bytes.Repeat([]byte{6}, 32))
+	require.Equal(t, []bool{true, true, false, false, false, false}, p.broadcastInFull(announcements))
+
+	p.cfg.BroadcastMinTip = 0
+	require.Equal(t, []bool{true, true, true, true, true, true}, p.broadcastInFull(announcements))
+}
+
+func TestLimitAnnouncements(t *testing.T) {
+	types, sizes, hashes := []byte{1, 2, 3}, []uint32{10, 20, 30}, make(Hashes, 3*32)
+	hashes[32] = 1
+
+	lTypes, lSizes, lHashes := limitAnnouncements(types, sizes, hashes, 0)
+	require.Len(t, lTypes, 3)
+	require.Len(t, lSizes, 3)
+	require.Equal(t, 3, lHashes.Len())
+
+	lTypes, lSizes, lHashes = limitAnnouncements(types, sizes, hashes, 2)
+	require.Equal(t, []byte{1, 2}, lTypes)
+	require.Equal(t, []uint32{10, 20}, lSizes)
+	require.Equal(t, 2, lHashes.Len())
+	require.Equal(t, byte(1), lHashes.At(1)[0])
+}
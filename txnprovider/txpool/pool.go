@@ -144,6 +144,7 @@ type TxPool struct {
 	isPostPrague            atomic.Bool
 	maxBlobsPerBlock        uint64
 	feeCalculator           FeeCalculator
+	broadcastBudget         *broadcastBudget // nil - unlimited
 	logger                  log.Logger
 }
 
@@ -180,6 +181,7 @@ func New(
 		search:            &metaTxn{TxnSlot: &TxnSlot{}},
 		senderIDTxnCount:  map[uint64]int{},
 		senderIDBlobCount: map[uint64]uint64{},
+		paymasterTxnCount: map[common.Address]int{},
 	}
 	tracedSenders := make(map[common.Address]struct{})
 	for _, sender := range cfg.TracedSenders {
@@ -212,6 +214,7 @@ func New(
 		minedBlobTxnsByHash:     map[string]*metaTxn{},
 		maxBlobsPerBlock:        maxBlobsPerBlock,
 		feeCalculator:           feeCalculator,
+		broadcastBudget:         newBroadcastBudget(cfg.BroadcastBandwidth),
 		logger:                  logger,
 	}
 
@@ -879,6 +882,20 @@ func (p *TxPool) validateTx(txn *TxnSlot, isLocal bool, stateCache kvcache.Cache
 		}
 	}
 
+	if txn.Type == AccountAbstractionTxnType {
+		if !p.cfg.AllowAA {
+			return txpoolcfg.TypeNotActivated
+		}
+		// The validation frames run before anybody pays for an invalid transaction, so unlike the execution
+		// frame their gas is capped by the pool
+		if txn.AAValidationGas > p.cfg.MaxAAValidationGas {
+			if txn.Traced {
+				p.logger.Info(fmt.Sprintf("TX TRACING: validateTx validation gas too high idHash=%x gas=%d, limit=%d", txn.IDHash, txn.AAValidationGas, p.cfg.MaxAAValidationGas))
+			}
+			return txpoolcfg.AAValidationGasTooHigh
+		}
+	}
+
 	// Drop non-local transactions under our own minimal accepted gas price or tip
 	if !isLocal && uint256.NewInt(p.cfg.MinFeeCap).Cmp(&txn.FeeCap) == 1 {
 		if txn.Traced {
@@ -886,21 +903,24 @@ func (p *TxPool) validateTx(txn *TxnSlot, isLocal bool, stateCache kvcache.Cache
 		}
 		return txpoolcfg.UnderPriced
 	}
-	gas, reason := txpoolcfg.CalcIntrinsicGas(uint64(txn.DataLen), uint64(txn.DataNonZeroLen), uint64(authorizationLen), nil, txn.Creation, true, true, isShanghai)
-	if txn.Traced {
-		p.logger.Info(fmt.Sprintf("TX TRACING: validateTx intrinsic gas idHash=%x gas=%d", txn.IDHash, gas))
-	}
-	if reason != txpoolcfg.Success {
+	// The intrinsic gas of account abstraction transactions is added to their gas limit by the parser
+	if txn.Type != AccountAbstractionTxnType {
+		gas, reason := txpoolcfg.CalcIntrinsicGas(uint64(txn.DataLen), uint64(txn.DataNonZeroLen), uint64(authorizationLen), nil, txn.Creation, true, true, isShanghai)
 		if txn.Traced {
-			p.logger.Info(fmt.Sprintf("TX TRACING: validateTx intrinsic gas calculated failed idHash=%x reason=%s", txn.IDHash, reason))
+			p.logger.Info(fmt.Sprintf("TX TRACING: validateTx intrinsic gas idHash=%x gas=%d", txn.IDHash, gas))
 		}
-		return reason
-	}
-	if gas > txn.Gas {
-		if txn.Traced {
-			p.logger.Info(fmt.Sprintf("TX TRACING: validateTx intrinsic gas > txn.gas idHash=%x gas=%d, txn.gas=%d", txn.IDHash, gas, txn.Gas))
+		if reason != txpoolcfg.Success {
+			if txn.Traced {
+				p.logger.Info(fmt.Sprintf("TX TRACING: validateTx intrinsic gas calculated failed idHash=%x reason=%s", txn.IDHash, reason))
+			}
+			return reason
+		}
+		if gas > txn.Gas {
+			if txn.Traced {
+				p.logger.Info(fmt.Sprintf("TX TRACING: validateTx intrinsic gas > txn.gas idHash=%x gas=%d, txn.gas=%d", txn.IDHash, gas, txn.Gas))
+			}
+			return txpoolcfg.IntrinsicGas
 		}
-		return txpoolcfg.IntrinsicGas
 	}
 	if !isLocal && uint64(p.all.count(txn.SenderID)) > p.cfg.AccountSlots {
 		if txn.Traced {
@@ -925,6 +945,33 @@ func (p *TxPool) validateTx(txn *TxnSlot, isLocal bool, stateCache kvcache.Cache
 		}
 		return txpoolcfg.InsufficientFunds
 	}
+	if txn.AAHasPaymaster {
+		return p.validatePaymaster(txn, isLocal, stateCache)
+	}
+	return txpoolcfg.Success
+}
+
+// validatePaymaster checks that the paymaster of an account abstraction transaction can cover its maximum cost, and
+// caps the count of its sponsored transactions like the count of a sender's ones: the senders don't pay for them,
+// so nothing else stops anyone from flooding the pool on behalf of a paymaster.
+func (p *TxPool) validatePaymaster(txn *TxnSlot, isLocal bool, stateCache kvcache.CacheView) txpoolcfg.DiscardReason {
+	if !isLocal && uint64(p.all.paymasterCount(txn.AAPaymaster)) >= p.cfg.AccountSlots {
+		if txn.Traced {
+			p.logger.Info(fmt.Sprintf("TX TRACING: validateTx paymaster marked as spamming idHash=%x paymaster=%x slots=%d, limit=%d", txn.IDHash, txn.AAPaymaster, p.all.paymasterCount(txn.AAPaymaster), p.cfg.AccountSlots))
+		}
+		return txpoolcfg.Spammer
+	}
+	_, paymasterBalance, err := accountInfo(stateCache, txn.AAPaymaster)
+	if err != nil {
+		p.logger.Warn("[txpool] failed to read paymaster balance", "paymaster", txn.AAPaymaster, "err", err)
+		return txpoolcfg.InsufficientFunds
+	}
+	if total := maxCost(txn); paymasterBalance.Cmp(total) < 0 {
+		if txn.Traced {
+			p.logger.Info(fmt.Sprintf("TX TRACING: validateTx paymaster insufficient funds idHash=%x paymaster=%x balance in state=%d, max cost=%d", txn.IDHash, txn.AAPaymaster, paymasterBalance, total))
+		}
+		return txpoolcfg.InsufficientFunds
+	}
 	return txpoolcfg.Success
 }
 
@@ -933,6 +980,15 @@ var maxUint256 = new(uint256.Int).SetAllOne()
 // Sender should have enough balance for: gasLimit x feeCap + blobGas x blobFeeCap + transferred_value
 // See YP, Eq (61) in Section 6.2 "Execution"
 func requiredBalance(txn *TxnSlot) *uint256.Int {
+	if txn.AAHasPaymaster {
+		// RIP-7560: the paymaster pays instead of the sender, see validatePaymaster
+		return uint256.NewInt(0)
+	}
+	return maxCost(txn)
+}
+
+// maxCost is the most the payer of a transaction can be charged for it.
+func maxCost(txn *TxnSlot) *uint256.Int {
 	// See https://github.com/ethereum/EIPs/pull/3594
 	total := uint256.NewInt(txn.Gas)
 	_, overflow := total.MulOverflow(total, &txn.FeeCap)
@@ -1823,6 +1879,7 @@ func MainLoop(ctx context.Context, p *TxPool, newTxns chan Announcements, send *
 				var remoteTxnRlps [][]byte
 				var broadcastHashes Hashes
 				slotsRlp := make([][]byte, 0, announcements.Len())
+				broadcastInFull := p.broadcastInFull(announcements)
 
 				if err := p.poolDB.View(ctx, func(tx kv.Tx) error {
 					for i := 0; i < announcements.Len(); i++ {
@@ -1858,7 +1915,7 @@ func MainLoop(ctx context.Context, p *TxPool, newTxns chan Announcements, send *
 							remoteTxnHashes = append(remoteTxnHashes, hash...)
 
 							// "Nodes MUST NOT automatically broadcast blob transactions to their peers" - EIP-4844
-							if t != BlobTxnType && len(slotRlp) < txMaxBroadcastSize {
+							if t != BlobTxnType && len(slotRlp) < txMaxBroadcastSize && broadcastInFull[i] {
 								remoteTxnRlps = append(remoteTxnRlps, slotRlp)
 							}
 						}
@@ -1886,7 +1943,7 @@ func MainLoop(ctx context.Context, p *TxPool, newTxns chan Announcements, send *
 
 				// broadcast remote transactions
 				const remoteTxnsBroadcastMaxPeers uint64 = 3
-				send.BroadcastPooledTxns(remoteTxnRlps, remoteTxnsBroadcastMaxPeers)
+				send.BroadcastPooledTxns(p.broadcastBudget.take(remoteTxnRlps, remoteTxnsBroadcastMaxPeers), remoteTxnsBroadcastMaxPeers)
 				send.AnnouncePooledTxns(remoteTxnTypes, remoteTxnSizes, remoteTxnHashes, remoteTxnsBroadcastMaxPeers*2)
 			}()
 		case <-syncToNewPeersEvery.C: // new peer
@@ -1904,6 +1961,7 @@ func MainLoop(ctx context.Context, p *TxPool, newTxns chan Announcements, send *
 			var types []byte
 			var sizes []uint32
 			types, sizes, hashes = p.AppendAllAnnouncements(types, sizes, hashes[:0])
+			types, sizes, hashes = limitAnnouncements(types, sizes, hashes, p.cfg.NewPeerAnnounceLimit)
 			go send.PropagatePooledTxnsToPeersList(newPeers, types, sizes, hashes)
 			propagateToNewPeerTimer.ObserveDuration(t)
 		}
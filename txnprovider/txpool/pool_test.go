@@ -791,6 +791,63 @@ func TestSetCodeTxnValidationWithLargeAuthorizationValues(t *testing.T) {
 	assert.Equal(t, txpoolcfg.Success, result)
 }
 
+func TestPaymasterValidation(t *testing.T) {
+	ch := make(chan Announcements, 1)
+	coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	cfg := txpoolcfg.DefaultConfig
+	cfg.AllowAA = true
+	cache := &kvcache.DummyCache{}
+	logger := log.New()
+	pool, err := New(ch, nil, coreDB, cfg, cache, *u256.N1, common.Big0 /* shanghaiTime */, nil, /* agraBlock */
+		common.Big0 /* cancunTime */, common.Big0 /* pragueTime */, fixedgas.DefaultMaxBlobsPerBlock, nil, logger)
+	require.NoError(t, err)
+	ctx := context.Background()
+	tx, err := coreDB.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	putAccount := func(addr common.Address, balance uint64) {
+		acc := sender{nonce: 0, balance: *uint256.NewInt(balance)}
+		accBytes := make([]byte, EncodeSenderLengthForStorage(acc.nonce, acc.balance))
+		EncodeSender(acc.nonce, acc.balance, accBytes)
+		require.NoError(t, tx.Put(kv.PlainState, addr[:], accBytes))
+	}
+	senderAddr := common.Address{1}
+	poorPaymaster, richPaymaster := common.Address{2}, common.Address{3}
+	putAccount(senderAddr, 0)
+	putAccount(poorPaymaster, 21000*500000-1)
+	putAccount(richPaymaster, 21000*500000)
+
+	var senderID uint64
+	newTxn := func(paymaster common.Address) *TxnSlot {
+		return &TxnSlot{
+			SenderID:       senderID,
+			Type:           AccountAbstractionTxnType,
+			FeeCap:         *uint256.NewInt(21000),
+			Gas:            500000,
+			AAHasPaymaster: true,
+			AAPaymaster:    paymaster,
+		}
+	}
+	txns := TxnSlots{
+		Txns:    []*TxnSlot{newTxn(poorPaymaster)},
+		Senders: Addresses(senderAddr[:]),
+	}
+	require.NoError(t, pool.senders.registerNewSenders(&txns, logger))
+	senderID = txns.Txns[0].SenderID
+	view, err := cache.View(ctx, tx)
+	require.NoError(t, err)
+
+	// the sender doesn't pay, the paymaster has to cover the whole cost
+	assert.Equal(t, txpoolcfg.InsufficientFunds, pool.validateTx(txns.Txns[0], false /* isLocal */, view))
+	assert.Equal(t, txpoolcfg.Success, pool.validateTx(newTxn(richPaymaster), false /* isLocal */, view))
+
+	// a paymaster sponsors at most AccountSlots remote transactions
+	pool.all.paymasterTxnCount[richPaymaster] = int(cfg.AccountSlots)
+	assert.Equal(t, txpoolcfg.Spammer, pool.validateTx(newTxn(richPaymaster), false /* isLocal */, view))
+	assert.Equal(t, txpoolcfg.Success, pool.validateTx(newTxn(richPaymaster), true /* isLocal */, view))
+}
+
 // Blob gas price bump + other requirements to replace existing txns in the pool
 func TestBlobTxnReplacement(t *testing.T) {
 	assert, require := assert.New(t), require.New(t)
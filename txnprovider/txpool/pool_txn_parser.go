@@ -32,6 +32,7 @@ import (
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/fixedgas"
 	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/common/math"
 	"github.com/erigontech/erigon-lib/common/u256"
 	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/rlp"
@@ -43,6 +44,8 @@ const (
 	DynamicFeeTxnType byte = 2 // EIP-1559
 	BlobTxnType       byte = 3 // EIP-4844
 	SetCodeTxnType    byte = 4 // EIP-7702
+
+	AccountAbstractionTxnType byte = 5 // RIP-7560
 )
 
 var ErrParseTxn = fmt.Errorf("%w transaction", rlp.ErrParse)
@@ -153,7 +156,7 @@ func (ctx *TxnParseContext) ParseTransaction(payload []byte, pos int, slot *TxnS
 	// If it is non-legacy transaction, the transaction type follows, and then the list
 	if !legacy {
 		slot.Type = payload[p]
-		if slot.Type > SetCodeTxnType {
+		if slot.Type > AccountAbstractionTxnType {
 			return 0, fmt.Errorf("%w: unknown transaction type: %d", ErrParseTxn, slot.Type)
 		}
 		p++
@@ -182,7 +185,11 @@ func (ctx *TxnParseContext) ParseTransaction(payload []byte, pos int, slot *TxnS
 		slot.Rlp = payload[pos : dataPos+dataLen]
 	}
 
-	p, err = ctx.parseTransactionBody(payload, pos, p, slot, sender, validateHash)
+	if slot.Type == AccountAbstractionTxnType {
+		p, err = ctx.parseAATransactionBody(payload, p, slot, sender, validateHash)
+	} else {
+		p, err = ctx.parseTransactionBody(payload, pos, p, slot, sender, validateHash)
+	}
 	if err != nil {
 		return p, err
 	}
@@ -308,6 +315,163 @@ func parseSignature(payload []byte, pos int, legacy bool, cfgChainId *uint256.In
 	return p, yParity, nil
 }
 
+func (ctx *TxnParseContext) parseChainID(payload []byte, pos int) (p int, err error) {
+	p, err = rlp.ParseU256(payload, pos, &ctx.ChainID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: chainId len: %s", ErrParseTxn, err) //nolint
+	}
+	if ctx.ChainID.IsZero() { // zero indicates that the chain ID was not specified in the tx.
+		if ctx.chainIDRequired {
+			return 0, fmt.Errorf("%w: chainID is required", ErrParseTxn)
+		}
+		ctx.ChainID.Set(&ctx.cfg.ChainID)
+	}
+	if !ctx.ChainID.Eq(&ctx.cfg.ChainID) {
+		return 0, fmt.Errorf("%w: %s, %d (expected %d)", ErrParseTxn, "invalid chainID", ctx.ChainID.Uint64(), ctx.cfg.ChainID.Uint64())
+	}
+	return p, nil
+}
+
+// parseAATransactionBody parses a RIP-7560 account abstraction transaction. It has its own layout and no
+// signature: the sender is part of the transaction and the gas limit is split between the validation and
+// the execution frames.
+func (ctx *TxnParseContext) parseAATransactionBody(payload []byte, p0 int, slot *TxnSlot, sender []byte, validateHash func([]byte) error) (p int, err error) {
+	dataPos, dataLen, err := rlp.ParseList(payload, p0)
+	if err != nil {
+		return 0, fmt.Errorf("%w: envelope Prefix: %s", ErrParseTxn, err) //nolint
+	}
+	ctx.Keccak1.Reset()
+	if _, err = ctx.Keccak1.Write([]byte{slot.Type}); err != nil {
+		return 0, fmt.Errorf("%w: computing IdHash (hashing type Prefix): %s", ErrParseTxn, err) //nolint
+	}
+	if _, err = ctx.Keccak1.Write(payload[p0 : dataPos+dataLen]); err != nil {
+		return 0, fmt.Errorf("%w: computing IdHash (hashing the envelope): %s", ErrParseTxn, err) //nolint
+	}
+	if ctx.validateRlp != nil {
+		if err := ctx.validateRlp(slot.Rlp); err != nil {
+			return p0, err
+		}
+	}
+	if p, err = ctx.parseChainID(payload, dataPos); err != nil {
+		return 0, err
+	}
+	var nonceKey uint256.Int
+	if p, err = rlp.ParseU256(payload, p, &nonceKey); err != nil {
+		return 0, fmt.Errorf("%w: nonce key: %s", ErrParseTxn, err) //nolint
+	}
+	if p, slot.Nonce, err = rlp.ParseU64(payload, p); err != nil {
+		return 0, fmt.Errorf("%w: nonce: %s", ErrParseTxn, err) //nolint
+	}
+	if p, err = rlp.StringOfLen(payload, p, 20); err != nil {
+		return 0, fmt.Errorf("%w: sender: %s", ErrParseTxn, err) //nolint
+	}
+	if ctx.withSender {
+		copy(sender, payload[p:p+20])
+	}
+	p += 20
+
+	// All the frames pay for their calldata
+	slot.DataLen, slot.DataNonZeroLen = 0, 0
+	parseData := func(name string) error {
+		dataPos, dataLen, err := rlp.ParseString(payload, p)
+		if err != nil {
+			return fmt.Errorf("%w: %s len: %s", ErrParseTxn, name, err) //nolint
+		}
+		slot.DataLen += dataLen
+		for _, byt := range payload[dataPos : dataPos+dataLen] {
+			if byt != 0 {
+				slot.DataNonZeroLen++
+			}
+		}
+		p = dataPos + dataLen
+		return nil
+	}
+	parseOptionalAddress := func(name string, addr *common.Address) (bool, error) {
+		dataPos, dataLen, err := rlp.ParseString(payload, p)
+		if err != nil {
+			return false, fmt.Errorf("%w: %s len: %s", ErrParseTxn, name, err) //nolint
+		}
+		if dataLen != 0 && dataLen != 20 {
+			return false, fmt.Errorf("%w: unexpected length of %s field: %d", ErrParseTxn, name, dataLen)
+		}
+		if addr != nil {
+			copy(addr[:], payload[dataPos:dataPos+dataLen])
+		}
+		p = dataPos + dataLen
+		return dataLen != 0, nil
+	}
+	if err = parseData("sender validation data"); err != nil {
+		return 0, err
+	}
+	if _, err = parseOptionalAddress("deployer", nil); err != nil {
+		return 0, err
+	}
+	if err = parseData("deployer data"); err != nil {
+		return 0, err
+	}
+	if slot.AAHasPaymaster, err = parseOptionalAddress("paymaster", &slot.AAPaymaster); err != nil {
+		return 0, err
+	}
+	if err = parseData("paymaster data"); err != nil {
+		return 0, err
+	}
+	if err = parseData("execution data"); err != nil {
+		return 0, err
+	}
+	// The builder fee is paid on top of the gas, like the value of regular transactions
+	if p, err = rlp.ParseU256(payload, p, &slot.Value); err != nil {
+		return 0, fmt.Errorf("%w: builder fee: %s", ErrParseTxn, err) //nolint
+	}
+	if p, err = rlp.ParseU256(payload, p, &slot.Tip); err != nil {
+		return 0, fmt.Errorf("%w: tip: %s", ErrParseTxn, err) //nolint
+	}
+	if p, err = rlp.ParseU256(payload, p, &slot.FeeCap); err != nil {
+		return 0, fmt.Errorf("%w: feeCap: %s", ErrParseTxn, err) //nolint
+	}
+	var validationGas, paymasterValidationGas, postOpGas, callGas uint64
+	if p, validationGas, err = rlp.ParseU64(payload, p); err != nil {
+		return 0, fmt.Errorf("%w: validation gas: %s", ErrParseTxn, err) //nolint
+	}
+	if p, paymasterValidationGas, err = rlp.ParseU64(payload, p); err != nil {
+		return 0, fmt.Errorf("%w: paymaster validation gas: %s", ErrParseTxn, err) //nolint
+	}
+	if p, postOpGas, err = rlp.ParseU64(payload, p); err != nil {
+		return 0, fmt.Errorf("%w: post op gas: %s", ErrParseTxn, err) //nolint
+	}
+	if p, callGas, err = rlp.ParseU64(payload, p); err != nil {
+		return 0, fmt.Errorf("%w: gas: %s", ErrParseTxn, err) //nolint
+	}
+	if p, err = parseAccessList(payload, p, slot); err != nil {
+		return 0, err
+	}
+	if p != dataPos+dataLen {
+		return 0, fmt.Errorf("%w: unexpected leftover after account abstraction txn body", ErrParseTxn)
+	}
+
+	intrinsicGas := fixedgas.TxAAGas +
+		uint64(slot.AlAddrCount)*fixedgas.TxAccessListAddressGas + uint64(slot.AlStorCount)*fixedgas.TxAccessListStorageKeyGas +
+		uint64(slot.DataNonZeroLen)*fixedgas.TxDataNonZeroGasEIP2028 + uint64(slot.DataLen-slot.DataNonZeroLen)*fixedgas.TxDataZeroGas
+	var overflow bool
+	slot.AAValidationGas, overflow = math.SafeAdd(validationGas, paymasterValidationGas)
+	if overflow {
+		return 0, fmt.Errorf("%w: validation gas overflow", ErrParseTxn)
+	}
+	slot.Gas = intrinsicGas
+	for _, gas := range []uint64{slot.AAValidationGas, postOpGas, callGas} {
+		if slot.Gas, overflow = math.SafeAdd(slot.Gas, gas); overflow {
+			return 0, fmt.Errorf("%w: gas overflow", ErrParseTxn)
+		}
+	}
+
+	_, _ = ctx.Keccak1.(io.Reader).Read(slot.IDHash[:32])
+	if validateHash != nil {
+		if err := validateHash(slot.IDHash[:32]); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
 func (ctx *TxnParseContext) parseTransactionBody(payload []byte, pos, p0 int, slot *TxnSlot, sender []byte, validateHash func([]byte) error) (p int, err error) {
 	p = p0
 	legacy := slot.Type == LegacyTxnType
@@ -343,18 +507,8 @@ func (ctx *TxnParseContext) parseTransactionBody(payload []byte, pos, p0 int, sl
 	// Remember where signing hash data begins (it will need to be wrapped in an RLP list)
 	sigHashPos := p
 	if !legacy {
-		p, err = rlp.ParseU256(payload, p, &ctx.ChainID)
-		if err != nil {
-			return 0, fmt.Errorf("%w: chainId len: %s", ErrParseTxn, err) //nolint
-		}
-		if ctx.ChainID.IsZero() { // zero indicates that the chain ID was not specified in the tx.
-			if ctx.chainIDRequired {
-				return 0, fmt.Errorf("%w: chainID is required", ErrParseTxn)
-			}
-			ctx.ChainID.Set(&ctx.cfg.ChainID)
-		}
-		if !ctx.ChainID.Eq(&ctx.cfg.ChainID) {
-			return 0, fmt.Errorf("%w: %s, %d (expected %d)", ErrParseTxn, "invalid chainID", ctx.ChainID.Uint64(), ctx.cfg.ChainID.Uint64())
+		if p, err = ctx.parseChainID(payload, p); err != nil {
+			return 0, err
 		}
 	}
 	// Next follows the nonce, which we need to parse
@@ -418,49 +572,10 @@ func (ctx *TxnParseContext) parseTransactionBody(payload []byte, pos, p0 int, sl
 
 	// Next follows access list for non-legacy transactions, we are only interesting in number of addresses and storage keys
 	if !legacy {
-		dataPos, dataLen, err = rlp.ParseList(payload, p)
+		p, err = parseAccessList(payload, p, slot)
 		if err != nil {
-			return 0, fmt.Errorf("%w: access list len: %s", ErrParseTxn, err) //nolint
+			return 0, err
 		}
-		tuplePos := dataPos
-		for tuplePos < dataPos+dataLen {
-			var tupleLen int
-			tuplePos, tupleLen, err = rlp.ParseList(payload, tuplePos)
-			if err != nil {
-				return 0, fmt.Errorf("%w: tuple len: %s", ErrParseTxn, err) //nolint
-			}
-			var addrPos int
-			addrPos, err = rlp.StringOfLen(payload, tuplePos, 20)
-			if err != nil {
-				return 0, fmt.Errorf("%w: tuple addr len: %s", ErrParseTxn, err) //nolint
-			}
-			slot.AlAddrCount++
-			var storagePos, storageLen int
-			storagePos, storageLen, err = rlp.ParseList(payload, addrPos+20)
-			if err != nil {
-				return 0, fmt.Errorf("%w: storage key list len: %s", ErrParseTxn, err) //nolint
-			}
-			sKeyPos := storagePos
-			for sKeyPos < storagePos+storageLen {
-				sKeyPos, err = rlp.StringOfLen(payload, sKeyPos, 32)
-				if err != nil {
-					return 0, fmt.Errorf("%w: tuple storage key len: %s", ErrParseTxn, err) //nolint
-				}
-				slot.AlStorCount++
-				sKeyPos += 32
-			}
-			if sKeyPos != storagePos+storageLen {
-				return 0, fmt.Errorf("%w: unexpected storage key items", ErrParseTxn)
-			}
-			tuplePos += tupleLen
-			if tuplePos != sKeyPos {
-				return 0, fmt.Errorf("%w: extraneous space in the tuple after storage key list", ErrParseTxn)
-			}
-		}
-		if tuplePos != dataPos+dataLen {
-			return 0, fmt.Errorf("%w: extraneous space in the access list after all tuples", ErrParseTxn)
-		}
-		p = dataPos + dataLen
 	}
 	if slot.Type == SetCodeTxnType {
 		dataPos, dataLen, err = rlp.ParseList(payload, p)
@@ -656,6 +771,53 @@ func (ctx *TxnParseContext) parseTransactionBody(payload []byte, pos, p0 int, sl
 	return p, nil
 }
 
+// parseAccessList parses the access list at position p, we are only interesting in number of addresses and storage keys
+func parseAccessList(payload []byte, p int, slot *TxnSlot) (int, error) {
+	dataPos, dataLen, err := rlp.ParseList(payload, p)
+	if err != nil {
+		return 0, fmt.Errorf("%w: access list len: %s", ErrParseTxn, err) //nolint
+	}
+	tuplePos := dataPos
+	for tuplePos < dataPos+dataLen {
+		var tupleLen int
+		tuplePos, tupleLen, err = rlp.ParseList(payload, tuplePos)
+		if err != nil {
+			return 0, fmt.Errorf("%w: tuple len: %s", ErrParseTxn, err) //nolint
+		}
+		var addrPos int
+		addrPos, err = rlp.StringOfLen(payload, tuplePos, 20)
+		if err != nil {
+			return 0, fmt.Errorf("%w: tuple addr len: %s", ErrParseTxn, err) //nolint
+		}
+		slot.AlAddrCount++
+		var storagePos, storageLen int
+		storagePos, storageLen, err = rlp.ParseList(payload, addrPos+20)
+		if err != nil {
+			return 0, fmt.Errorf("%w: storage key list len: %s", ErrParseTxn, err) //nolint
+		}
+		sKeyPos := storagePos
+		for sKeyPos < storagePos+storageLen {
+			sKeyPos, err = rlp.StringOfLen(payload, sKeyPos, 32)
+			if err != nil {
+				return 0, fmt.Errorf("%w: tuple storage key len: %s", ErrParseTxn, err) //nolint
+			}
+			slot.AlStorCount++
+			sKeyPos += 32
+		}
+		if sKeyPos != storagePos+storageLen {
+			return 0, fmt.Errorf("%w: unexpected storage key items", ErrParseTxn)
+		}
+		tuplePos += tupleLen
+		if tuplePos != sKeyPos {
+			return 0, fmt.Errorf("%w: extraneous space in the tuple after storage key list", ErrParseTxn)
+		}
+	}
+	if tuplePos != dataPos+dataLen {
+		return 0, fmt.Errorf("%w: extraneous space in the access list after all tuples", ErrParseTxn)
+	}
+	return dataPos + dataLen, nil
+}
+
 // TxnSlot contains information extracted from an Ethereum transaction, which is enough to manage it inside the transaction.
 // Also, it contains some auxiliary information, like ephemeral fields, and indices within priority queues
 type TxnSlot struct {
@@ -685,6 +847,11 @@ type TxnSlot struct {
 
 	// EIP-7702: set code tx
 	Authorizations []Signature
+
+	// RIP-7560: account abstraction tx
+	AAValidationGas uint64         // Gas limit of the validation frames, spent before anybody is charged for a failing transaction
+	AAHasPaymaster  bool           // The gas is paid by a paymaster rather than the sender
+	AAPaymaster     common.Address // Set if AAHasPaymaster
 }
 
 // nolint
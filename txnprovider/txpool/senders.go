@@ -43,8 +43,9 @@ import (
 type BySenderAndNonce struct {
 	tree              *btree.BTreeG[*metaTxn]
 	search            *metaTxn
-	senderIDTxnCount  map[uint64]int    // count of sender's txns in the pool - may differ from nonce
-	senderIDBlobCount map[uint64]uint64 // count of sender's total number of blobs in the pool
+	senderIDTxnCount  map[uint64]int         // count of sender's txns in the pool - may differ from nonce
+	senderIDBlobCount map[uint64]uint64      // count of sender's total number of blobs in the pool
+	paymasterTxnCount map[common.Address]int // count of the txns sponsored by an account abstraction paymaster
 }
 
 func (b *BySenderAndNonce) nonce(senderID uint64) (nonce uint64, ok bool) {
@@ -96,6 +97,10 @@ func (b *BySenderAndNonce) count(senderID uint64) int {
 	return b.senderIDTxnCount[senderID]
 }
 
+func (b *BySenderAndNonce) paymasterCount(paymaster common.Address) int {
+	return b.paymasterTxnCount[paymaster]
+}
+
 func (b *BySenderAndNonce) blobCount(senderID uint64) uint64 {
 	return b.senderIDBlobCount[senderID]
 }
@@ -138,6 +143,14 @@ func (b *BySenderAndNonce) delete(mt *metaTxn, reason txpoolcfg.DiscardReason, l
 			delete(b.senderIDTxnCount, senderID)
 		}
 
+		if mt.TxnSlot.AAHasPaymaster {
+			if count := b.paymasterTxnCount[mt.TxnSlot.AAPaymaster]; count > 1 {
+				b.paymasterTxnCount[mt.TxnSlot.AAPaymaster] = count - 1
+			} else {
+				delete(b.paymasterTxnCount, mt.TxnSlot.AAPaymaster)
+			}
+		}
+
 		if mt.TxnSlot.Type == BlobTxnType && mt.TxnSlot.Blobs != nil {
 			accBlobCount := b.senderIDBlobCount[senderID]
 			txnBlobCount := len(mt.TxnSlot.Blobs)
@@ -165,6 +178,9 @@ func (b *BySenderAndNonce) replaceOrInsert(mt *metaTxn, logger log.Logger) *meta
 	}
 
 	b.senderIDTxnCount[mt.TxnSlot.SenderID]++
+	if mt.TxnSlot.AAHasPaymaster {
+		b.paymasterTxnCount[mt.TxnSlot.AAPaymaster]++
+	}
 	if mt.TxnSlot.Type == BlobTxnType && mt.TxnSlot.Blobs != nil {
 		b.senderIDBlobCount[mt.TxnSlot.SenderID] += uint64(len(mt.TxnSlot.Blobs))
 	}
@@ -222,6 +238,11 @@ func (sc *sendersBatch) info(cacheView kvcache.CacheView, id uint64) (nonce uint
 	if !ok {
 		panic("must not happen")
 	}
+	return accountInfo(cacheView, addr)
+}
+
+// accountInfo reads the nonce and balance of an account which doesn't need to be a sender, e.g. a paymaster
+func accountInfo(cacheView kvcache.CacheView, addr common.Address) (nonce uint64, balance uint256.Int, err error) {
 	encoded, err := cacheView.Get(addr.Bytes())
 	if err != nil {
 		return 0, uint256.Int{}, err
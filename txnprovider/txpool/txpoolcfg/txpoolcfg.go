@@ -48,6 +48,10 @@ type Config struct {
 	BlobPriceBump       uint64 //Price bump percentage to replace an existing 4844 blob txn (type-3)
 	OverridePragueTime  *big.Int
 
+	// RIP-7560: account abstraction transactions are accepted when the chain config allows them
+	AllowAA            bool
+	MaxAAValidationGas uint64 // Max gas of the validation frames of an account abstraction transaction
+
 	// regular batch tasks processing
 	SyncToNewPeersEvery    time.Duration
 	ProcessRemoteTxnsEvery time.Duration
@@ -61,6 +65,11 @@ type Config struct {
 	MdbxWriteMap    bool
 
 	NoGossip bool // this mode doesn't broadcast any txns, and if receive remote-txn - skip it
+
+	// outbound gossip throttling, to cut the upstream bandwidth of well connected nodes
+	BroadcastMinTip      uint64            // remote txns with a lower effective tip at the pending base fee are only announced
+	BroadcastBandwidth   datasize.ByteSize // per second, for the full bodies of remote txns counting every peer they're sent to. 0 - unlimited
+	NewPeerAnnounceLimit int               // max txns announced to a newly connected peer, local ones first. 0 - unlimited
 }
 
 var DefaultConfig = Config{
@@ -80,6 +89,8 @@ var DefaultConfig = Config{
 	PriceBump:          10,  // Price bump percentage to replace an already existing transaction
 	BlobPriceBump:      100,
 
+	MaxAAValidationGas: 500_000,
+
 	NoGossip:     false,
 	MdbxWriteMap: false,
 }
@@ -120,6 +131,8 @@ const (
 	BlobTxReplace       DiscardReason = 30 // Cannot replace type-3 blob txn with another type of txn
 	BlobPoolOverflow    DiscardReason = 31 // The total number of blobs (through blob txns) in the pool has reached its limit
 	NoAuthorizations    DiscardReason = 32 // EIP-7702 transactions with an empty authorization list are invalid
+
+	AAValidationGasTooHigh DiscardReason = 33 // RIP-7560 transactions whose validation frames may use more gas than the pool accepts
 )
 
 func (r DiscardReason) String() string {
@@ -184,6 +197,8 @@ func (r DiscardReason) String() string {
 		return "blobs limit in txpool is full"
 	case NoAuthorizations:
 		return "EIP-7702 transactions with an empty authorization list are invalid"
+	case AAValidationGasTooHigh:
+		return "RIP-7560 transaction validation gas is too high"
 	default:
 		panic(fmt.Sprintf("discard reason: %d", r))
 	}
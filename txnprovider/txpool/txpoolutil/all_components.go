@@ -144,6 +144,7 @@ func AllComponents(ctx context.Context, cfg txpoolcfg.Config, cache kvcache.Cach
 	if cfg.OverridePragueTime != nil {
 		pragueTime = cfg.OverridePragueTime
 	}
+	cfg.AllowAA = chainConfig.AllowAA
 
 	txPool, err := txpool.New(
 		newTxns,